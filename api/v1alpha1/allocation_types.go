@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// AllocationNodeNameLabel is the label of an Allocation holding the node the value is allocated on.
+	AllocationNodeNameLabel = "networking.metalnet.ironcore.dev/node-name"
+	// AllocationPoolLabel is the label of an Allocation holding the pool the value is allocated from.
+	AllocationPoolLabel = "networking.metalnet.ironcore.dev/pool"
+	// AllocationOwnerUIDLabel is the label of an Allocation holding the uid of the owner of the value.
+	AllocationOwnerUIDLabel = "networking.metalnet.ironcore.dev/owner-uid"
+)
+
+// AllocationSpec defines the allocated value and its owner.
+type AllocationSpec struct {
+	// NodeName is the name of the node the value is allocated on.
+	// +kubebuilder:validation:MinLength=1
+	NodeName string `json:"nodeName"`
+	// Pool is the name of the pool the value is allocated from, e.g. netfns for the devices of
+	// NetworkInterfaces.
+	// +kubebuilder:validation:MinLength=1
+	Pool string `json:"pool"`
+	// OwnerUID is the uid of the object the value is allocated to.
+	OwnerUID types.UID `json:"ownerUID"`
+	// Value is the allocated value, e.g. the pci address of a device.
+	// +kubebuilder:validation:MinLength=1
+	Value string `json:"value"`
+}
+
+//+kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Node",type=string,description="Node the value is allocated on.",JSONPath=`.spec.nodeName`,priority=0
+// +kubebuilder:printcolumn:name="Pool",type=string,description="Pool the value is allocated from.",JSONPath=`.spec.pool`,priority=0
+// +kubebuilder:printcolumn:name="Value",type=string,description="Allocated value.",JSONPath=`.spec.value`,priority=0
+// +kubebuilder:printcolumn:name="Owner",type=string,description="UID of the owner of the value.",JSONPath=`.spec.ownerUID`,priority=10
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the allocation.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// Allocation is the Schema for the allocations API.
+// It persists a value metalnet allocated on a node, e.g. the device of a NetworkInterface. The name of an
+// Allocation is derived from the node, pool and value, so a value can only be allocated once.
+type Allocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the allocated value and its owner.
+	// +kubebuilder:validation:Required
+	Spec AllocationSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+
+// AllocationList contains a list of Allocation
+type AllocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of Allocation.
+	Items []Allocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Allocation{}, &AllocationList{})
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterNetworkUseVerb is the verb of the permission on a ClusterNetwork that is required to connect
+// NetworkInterfaces to it.
+const ClusterNetworkUseVerb = "use"
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=cnet
+// +kubebuilder:printcolumn:name="Handle",type=integer,description="ID of the network.",JSONPath=`.spec.id`,priority=10
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the network.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// ClusterNetwork is the Schema for the clusternetworks API.
+// It is a Network shared by all namespaces, e.g. an infrastructure network like the storage network.
+// NetworkInterfaces of any namespace reference it by their ClusterNetworkRef, provided that whoever creates
+// them is allowed to use it.
+type ClusterNetwork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec   NetworkSpec   `json:"spec"`
+	Status NetworkStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterNetworkList contains a list of ClusterNetwork
+type ClusterNetworkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNetwork `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterNetwork{}, &ClusterNetworkList{})
+}
@@ -5,13 +5,119 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/netip"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 )
 
+const (
+	// MaintenanceAnnotation puts the metalnet instance of the annotated node into maintenance if set to "true".
+	MaintenanceAnnotation = "networking.metalnet.ironcore.dev/maintenance"
+
+	// NodeInMaintenance is set on the NetworkInterfaces and LoadBalancers of a node in maintenance.
+	// Their dpservice state is kept, but none of their routes are announced.
+	NodeInMaintenance = "NodeInMaintenance"
+
+	// MaintenanceReasonAnnouncementsWithdrawn is used when the routes of the node are withdrawn for maintenance.
+	MaintenanceReasonAnnouncementsWithdrawn = "AnnouncementsWithdrawn"
+)
+
+const (
+	// UpdateThrottled is set on NetworkInterfaces and LoadBalancers whose updates are rate limited.
+	// Their latest generation is applied once the rate limit allows it.
+	UpdateThrottled = "UpdateThrottled"
+
+	// ThrottleReasonRateLimited is used when an object is updated more often than the rate limit allows.
+	ThrottleReasonRateLimited = "RateLimited"
+)
+
+const (
+	// CapacityExceeded is set on NetworkInterfaces and LoadBalancers that cannot be fully programmed because a dpservice table
+	// is full. They are retried at a reduced rate until capacity is freed.
+	CapacityExceeded = "CapacityExceeded"
+
+	// CapacityReasonTableFull is used when dpservice refused a write because one of its tables is full.
+	CapacityReasonTableFull = "TableFull"
+	// CapacityReasonAvailable is used on a Node once all its objects could be programmed again.
+	CapacityReasonAvailable = "CapacityAvailable"
+
+	// NodeNetworkCapacityExceeded is the condition of a Node on which metalnet cannot program objects because
+	// a dpservice table is full.
+	NodeNetworkCapacityExceeded corev1.NodeConditionType = "NetworkCapacityExceeded"
+
+	// NetworkCapacityExceededTaint is the key of the NoSchedule taint placed on a Node whose dpservice tables are
+	// full, so no new machines are scheduled to it.
+	NetworkCapacityExceededTaint = "networking.metalnet.ironcore.dev/capacity-exceeded"
+)
+
+const (
+	// AnnouncementDenied is set on NetworkInterfaces and LoadBalancers with routes the announcement policy of
+	// the node does not allow to announce. They are not retried until their spec changes.
+	AnnouncementDenied = "AnnouncementDenied"
+
+	// AnnouncementReasonDeniedByPolicy is used when the announcement policy denied announcing a route.
+	AnnouncementReasonDeniedByPolicy = "DeniedByPolicy"
+)
+
+const (
+	// NodeNetworkDataplaneUnavailable is the condition of a Node whose dpservice is down or which lost all its
+	// metalbond peers, so network objects of the node are neither programmed nor reachable.
+	NodeNetworkDataplaneUnavailable corev1.NodeConditionType = "NetworkDataplaneUnavailable"
+
+	// DataplaneReasonAvailable is used on a Node whose dpservice is up and connected to a metalbond peer.
+	DataplaneReasonAvailable = "DataplaneAvailable"
+	// DataplaneReasonDPServiceDown is used on a Node whose dpservice is down or restarted.
+	DataplaneReasonDPServiceDown = "DPServiceDown"
+	// DataplaneReasonMetalbondDown is used on a Node that is not connected to any of its metalbond peers.
+	DataplaneReasonMetalbondDown = "MetalbondDown"
+
+	// NetworkDataplaneUnavailableTaint is the key of the NoSchedule taint placed on a Node whose dataplane is
+	// unavailable, so no new machines depending on the network are scheduled to it.
+	NetworkDataplaneUnavailableTaint = "networking.metalnet.ironcore.dev/dataplane-unavailable"
+)
+
+const (
+	// PausedAnnotation pauses the reconciliation of the annotated object if set to "true". Its dpservice state
+	// and routes are left untouched until the annotation is removed.
+	PausedAnnotation = "networking.metalnet.ironcore.dev/paused"
+
+	// ReconciliationPaused is set on NetworkInterfaces, LoadBalancers and InternetGateways whose reconciliation
+	// is paused.
+	ReconciliationPaused = "ReconciliationPaused"
+
+	// PausedReasonAnnotated is used when the reconciliation is paused by the paused annotation.
+	PausedReasonAnnotated = "PausedByAnnotation"
+)
+
+const (
+	// ForceDeleteAnnotation removes the finalizer of a deleted NetworkInterface or LoadBalancer if set to "true",
+	// even if its dpservice state or routes could not be removed. metalnet keeps retrying the removal in the
+	// background until the dataplane confirms it.
+	ForceDeleteAnnotation = "networking.metalnet.ironcore.dev/force-delete"
+)
+
+// ReconcileTimeline records when a NetworkInterface or LoadBalancer was last synced on its node and how often
+// syncing it failed, so objects not synced for a while can be alerted on. The timestamps are refreshed at most
+// once a minute.
+type ReconcileTimeline struct {
+	// LastProgrammedTime is the last time the object was programmed into dpservice.
+	// +optional
+	LastProgrammedTime *metav1.Time `json:"lastProgrammedTime,omitempty"`
+	// LastAnnouncedTime is the last time all routes of the object were announced.
+	// +optional
+	LastAnnouncedTime *metav1.Time `json:"lastAnnouncedTime,omitempty"`
+	// ReconcileErrors is the number of failed reconciliations of the object.
+	// +optional
+	ReconcileErrors int64 `json:"reconcileErrors,omitempty"`
+	// ConsecutiveReconcileErrors is the number of reconciliations failed since the last successful one.
+	// +optional
+	ConsecutiveReconcileErrors int32 `json:"consecutiveReconcileErrors,omitempty"`
+}
+
 // LocalUIDReference is a reference to another entity including its UID
 type LocalUIDReference struct {
 	// Name is the name of the referenced entity.
@@ -23,6 +129,7 @@ type LocalUIDReference struct {
 
 // LBPort consists of port and protocol
 type LBPort struct {
+	// Protocol is the protocol of the port, one of TCP, UDP or SCTP.
 	// +kubebuilder:validation:Required
 	Protocol string `json:"protocol"`
 	// +kubebuilder:validation:Required
@@ -31,6 +138,13 @@ type LBPort struct {
 	Port int32 `json:"port"`
 }
 
+// Protocols of LBPorts supported by dpservice.
+const (
+	LBPortProtocolTCP  = "TCP"
+	LBPortProtocolUDP  = "UDP"
+	LBPortProtocolSCTP = "SCTP"
+)
+
 // LBPort consists of port and protocol
 type NATDetails struct {
 	// +kubebuilder:validation:Required
@@ -45,8 +159,11 @@ type NATDetails struct {
 	EndPort int32 `json:"endPort"`
 }
 
-// IP is an IP address.
+// IP is an IP address without zone. IPv4 addresses are written in dotted decimal, IPv6 addresses in any of
+// their textual forms.
 // +kubebuilder:validation:Type=string
+// +kubebuilder:validation:MaxLength=45
+// +kubebuilder:validation:Pattern=`^(((25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])[.]){3}(25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])|[0-9a-fA-F.]*:[0-9a-fA-F:.]*)$`
 type IP struct {
 	netip.Addr `json:"-"`
 }
@@ -56,6 +173,9 @@ func (in *IP) DeepCopyInto(out *IP) {
 }
 
 func (in *IP) DeepCopy() *IP {
+	if in == nil {
+		return nil
+	}
 	return &IP{in.Addr}
 }
 
@@ -79,6 +199,9 @@ func (i *IP) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
+	if p.Zone() != "" {
+		return fmt.Errorf("ip %q must not have a zone", str)
+	}
 
 	i.Addr = p
 	return nil
@@ -164,8 +287,11 @@ func EqualIPs(a, b IP) bool {
 	return a == b
 }
 
-// IPPrefix represents a network prefix.
+// IPPrefix represents a network prefix in CIDR notation. Prefixes are decoded in their canonical form, with
+// the host bits cleared, so e.g. 10.0.0.1/24 is read as 10.0.0.0/24 and FD00::/64 as fd00::/64.
 // +kubebuilder:validation:Type=string
+// +kubebuilder:validation:MaxLength=49
+// +kubebuilder:validation:Pattern=`^(((25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])[.]){3}(25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])/(3[0-2]|[12]?[0-9])|[0-9a-fA-F.]*:[0-9a-fA-F:.]*/(12[0-8]|1[01][0-9]|[1-9]?[0-9]))$`
 // +nullable
 type IPPrefix struct {
 	netip.Prefix `json:"-"`
@@ -196,7 +322,7 @@ func (i *IPPrefix) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	i.Prefix = p
+	i.Prefix = p.Masked()
 	return nil
 }
 
@@ -220,6 +346,9 @@ func (in *IPPrefix) DeepCopyInto(out *IPPrefix) {
 }
 
 func (in *IPPrefix) DeepCopy() *IPPrefix {
+	if in == nil {
+		return nil
+	}
 	return &IPPrefix{in.Prefix}
 }
 
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1_test
+
+import (
+	"encoding/json"
+
+	. "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IP types", func() {
+	It("should round-trip ips and prefixes through JSON", func() {
+		type object struct {
+			IP       *IP       `json:"ip"`
+			IPPrefix *IPPrefix `json:"ipPrefix"`
+		}
+		in := object{IP: MustParseNewIP("2001:db8::1"), IPPrefix: MustParseNewIPPrefix("10.0.0.0/8")}
+		data, err := json.Marshal(in)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"ip":"2001:db8::1","ipPrefix":"10.0.0.0/8"}`))
+
+		var out object
+		Expect(json.Unmarshal(data, &out)).To(Succeed())
+		Expect(out).To(Equal(in))
+	})
+
+	DescribeTable("should decode prefixes in canonical form",
+		func(data, expected string) {
+			var prefix IPPrefix
+			Expect(json.Unmarshal([]byte(data), &prefix)).To(Succeed())
+			Expect(prefix).To(Equal(MustParseIPPrefix(expected)))
+			Expect(prefix.String()).To(Equal(expected))
+		},
+		Entry("host bits", `"10.0.0.1/24"`, "10.0.0.0/24"),
+		Entry("upper case", `"FD00:0:0:0::/64"`, "fd00::/64"),
+		Entry("upper case with host bits", `"2001:DB8::1:1/112"`, "2001:db8::1:0/112"),
+	)
+
+	DescribeTable("should reject malformed ips",
+		func(data string) {
+			var ip IP
+			Expect(json.Unmarshal([]byte(data), &ip)).NotTo(Succeed())
+		},
+		Entry("truncated", `"10.0.0"`),
+		Entry("with zone", `"fe80::1%eth0"`),
+		Entry("prefix", `"10.0.0.0/8"`),
+		Entry("not a string", `42`),
+	)
+
+	DescribeTable("should reject malformed prefixes",
+		func(data string) {
+			var prefix IPPrefix
+			Expect(json.Unmarshal([]byte(data), &prefix)).NotTo(Succeed())
+		},
+		Entry("without length", `"10.0.0.0"`),
+		Entry("too long", `"10.0.0.0/33"`),
+		Entry("not a string", `42`),
+	)
+
+	It("should deep copy nil ips and prefixes to nil", func() {
+		var ip *IP
+		Expect(ip.DeepCopy()).To(BeNil())
+		var prefix *IPPrefix
+		Expect(prefix.DeepCopy()).To(BeNil())
+	})
+})
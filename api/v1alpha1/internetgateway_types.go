@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// InternetGatewayMinPort is the first port handed out in port blocks of an InternetGateway.
+	InternetGatewayMinPort = 1024
+	// InternetGatewayMaxPort is the last port handed out in port blocks of an InternetGateway.
+	InternetGatewayMaxPort = 65535
+)
+
+// InternetGatewaySpec defines the desired state of InternetGateway
+type InternetGatewaySpec struct {
+	// IPs are the public IPs of the NAT pool shared by the NetworkInterfaces using this InternetGateway.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	IPs []IP `json:"ips"`
+	// PortsPerNetworkInterface is the number of ports of the port block allocated to each NetworkInterface.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64512
+	// +kubebuilder:default=1024
+	PortsPerNetworkInterface int32 `json:"portsPerNetworkInterface,omitempty"`
+}
+
+// InternetGatewayStatus defines the observed state of InternetGateway
+type InternetGatewayStatus struct {
+	// Allocations are the port blocks allocated to NetworkInterfaces.
+	// +optional
+	// +listType=map
+	// +listMapKey=networkInterfaceName
+	Allocations []InternetGatewayAllocation `json:"allocations,omitempty"`
+	// Capacity is the number of port blocks of the NAT pool.
+	Capacity int32 `json:"capacity,omitempty"`
+	// Pending is the number of NetworkInterfaces waiting for a port block because the NAT pool is exhausted.
+	Pending int32 `json:"pending,omitempty"`
+	// IPs is the port block utilization of the public IPs of the NAT pool.
+	// +optional
+	// +listType=map
+	// +listMapKey=ip
+	IPs []InternetGatewayIPStatus `json:"ips,omitempty"`
+	// Conditions are the conditions of the InternetGateway.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// InternetGatewayIPStatus is the port block utilization of a public IP of the NAT pool.
+type InternetGatewayIPStatus struct {
+	// IP is the public IP.
+	IP IP `json:"ip"`
+	// Capacity is the number of port blocks of the IP.
+	Capacity int32 `json:"capacity"`
+	// Allocated is the number of port blocks of the IP allocated to NetworkInterfaces.
+	Allocated int32 `json:"allocated"`
+}
+
+const (
+	// InternetGatewayPortsExhausted reports whether all port blocks of the NAT pool are allocated, so further
+	// NetworkInterfaces cannot egress through the InternetGateway until IPs are added to the pool.
+	InternetGatewayPortsExhausted = "PortsExhausted"
+)
+
+const (
+	// PortsExhaustedReasonPortBlocksAvailable is used when port blocks of the NAT pool are free.
+	PortsExhaustedReasonPortBlocksAvailable = "PortBlocksAvailable"
+	// PortsExhaustedReasonNoFreePortBlocks is used when all port blocks of the NAT pool are allocated.
+	PortsExhaustedReasonNoFreePortBlocks = "NoFreePortBlocks"
+)
+
+// InternetGatewayAllocation is a port block of the NAT pool allocated to a NetworkInterface.
+type InternetGatewayAllocation struct {
+	// NetworkInterfaceName is the name of the NetworkInterface the port block is allocated to.
+	NetworkInterfaceName string `json:"networkInterfaceName"`
+	// IP is the public IP of the port block.
+	IP IP `json:"ip"`
+	// Port is the first port of the port block.
+	Port int32 `json:"port"`
+	// EndPort is the last port of the port block.
+	EndPort int32 `json:"endPort"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=igw
+// +kubebuilder:printcolumn:name="Capacity",type=integer,description="Number of port blocks of the NAT pool.",JSONPath=`.status.capacity`,priority=0
+// +kubebuilder:printcolumn:name="Pending",type=integer,description="Number of network interfaces waiting for a port block.",JSONPath=`.status.pending`,priority=0
+// +kubebuilder:printcolumn:name="Exhausted",type=string,description="Whether all port blocks are allocated.",JSONPath=`.status.conditions[?(@.type=="PortsExhausted")].status`,priority=0
+// +kubebuilder:printcolumn:name="IPS",type=string,description="IP Addresses of the NAT pool.",JSONPath=`.spec.ips`,priority=10
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the internet gateway.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// InternetGateway is the Schema for the internetgateways API.
+// It provides internet egress for NetworkInterfaces without a virtual ip by sharing a NAT pool on the public VNI.
+type InternetGateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of InternetGateway.
+	// +kubebuilder:validation:Required
+	Spec InternetGatewaySpec `json:"spec"`
+	// Status defines the observed state of InternetGateway.
+	Status InternetGatewayStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// InternetGatewayList contains a list of InternetGateway
+type InternetGatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of InternetGateway.
+	Items []InternetGateway `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InternetGateway{}, &InternetGatewayList{})
+}
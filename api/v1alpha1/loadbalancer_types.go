@@ -9,6 +9,9 @@ import (
 )
 
 // LoadBalancerSpec defines the desired state of LoadBalancer
+// +kubebuilder:validation:XValidation:rule="!has(self.ip) || size(self.ipFamily) == 0 || self.ip.contains(':') == (self.ipFamily == 'IPv6')",message="ip must be of the ipFamily"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodeSelector) || !has(self.nodeName)",message="nodeName and nodeSelector are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.nodeSelector) || has(self.ip)",message="ip is required with nodeSelector"
 type LoadBalancerSpec struct {
 	// NetworkRef is the Network this LoadBalancer is connected to
 	// +kubebuilder:validation:Required
@@ -19,20 +22,59 @@ type LoadBalancerSpec struct {
 	LBtype LoadBalancerType `json:"type"`
 	// IPFamily defines which IPFamily this LoadBalancer is supporting
 	IPFamily corev1.IPFamily `json:"ipFamily"`
-	// IP is the provided IP which should be loadbalanced by this LoadBalancer
-	// +kubebuilder:validation:Required
-	IP IP `json:"ip"`
+	// IP is the provided IP which should be loadbalanced by this LoadBalancer.
+	// If unset, an IP is allocated from a matching LoadBalancerIPPool.
+	// +optional
+	IP IP `json:"ip,omitempty"`
+	// IPPoolRef restricts the allocation of an unset IP to the referenced LoadBalancerIPPool.
+	// If unset, the IP is allocated from any matching pool.
+	// +optional
+	IPPoolRef *corev1.LocalObjectReference `json:"ipPoolRef,omitempty"`
 	// Ports are the provided ports
 	// +kubebuilder:validation:MinItems=1
 	Ports []LBPort `json:"ports"`
 	// NodeName is the name of the node on which the LoadBalancer should be created.
 	NodeName *string `json:"nodeName,omitempty"`
+	// NodeSelector schedules the LoadBalancer to all nodes whose labels match the selector instead of a
+	// single node. Every node programs the LoadBalancer and announces its ip, so the fabric balances the
+	// traffic across the nodes (ECMP). Requires the IP to be set.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// EndpointSliceTargets sources targets of the LoadBalancer from the ready endpoints of a Service in the
+	// workload cluster metalnet is connected to. The NetworkInterfaces in the Network of the LoadBalancer
+	// whose ips or prefixes contain an endpoint address become targets, in addition to the NetworkInterfaces
+	// listing the LoadBalancer in their LoadBalancerTargets.
+	// +optional
+	EndpointSliceTargets *EndpointSliceTargets `json:"endpointSliceTargets,omitempty"`
+}
+
+// EndpointSliceTargets selects the EndpointSlices of a Service in the workload cluster.
+type EndpointSliceTargets struct {
+	// Namespace is the namespace of the Service.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// ServiceName is the name of the Service.
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
 }
 
 // LoadBalancerStatus defines the observed state of LoadBalancer
 type LoadBalancerStatus struct {
 	// State is the LoadBalancerState of the LoadBalancer.
 	State LoadBalancerState `json:"state,omitempty"`
+	// Nodes are the nodes a LoadBalancer with a NodeSelector is programmed on.
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
+	ReconcileTimeline `json:",inline"`
+
+	// Conditions are the conditions of the LoadBalancer.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // LoadBalancerType is the type of a LoadBalancer.
@@ -57,6 +99,20 @@ const (
 	LoadBalancerStateError LoadBalancerState = "Error"
 )
 
+const (
+	// LoadBalancerIPAllocated reports whether the IP of a LoadBalancer without one was allocated from a LoadBalancerIPPool.
+	LoadBalancerIPAllocated = "IPAllocated"
+)
+
+const (
+	// IPAllocatedReasonAllocated is used when the IP was allocated from a pool.
+	IPAllocatedReasonAllocated = "Allocated"
+	// IPAllocatedReasonNoMatchingPool is used when no pool matches the LoadBalancer.
+	IPAllocatedReasonNoMatchingPool = "NoMatchingPool"
+	// IPAllocatedReasonPoolExhausted is used when all matching pools are exhausted.
+	IPAllocatedReasonPoolExhausted = "PoolExhausted"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Status",type=string,description="Status of the loadbalancer.",JSONPath=`.status.state`,priority=0
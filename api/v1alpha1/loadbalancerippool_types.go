@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LoadBalancerIPPoolSpec defines the desired state of LoadBalancerIPPool
+type LoadBalancerIPPoolSpec struct {
+	// CIDRs are the ranges the IPs of LoadBalancers are allocated from.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	CIDRs []IPPrefix `json:"cidrs"`
+	// NamespaceSelector selects the namespaces whose LoadBalancers may allocate from this pool.
+	// If unset, LoadBalancers of all namespaces may allocate from this pool.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Type restricts the pool to LoadBalancers of the given type. If unset, LoadBalancers of all types
+	// may allocate from this pool.
+	// +optional
+	// +kubebuilder:validation:Enum=Internal;Public
+	Type *LoadBalancerType `json:"type,omitempty"`
+}
+
+// LoadBalancerIPPoolStatus defines the observed state of LoadBalancerIPPool
+type LoadBalancerIPPoolStatus struct {
+	// Allocations are the IPs allocated to LoadBalancers.
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=loadBalancerName
+	Allocations []LoadBalancerIPPoolAllocation `json:"allocations,omitempty"`
+}
+
+// LoadBalancerIPPoolAllocation is an IP of the pool allocated to a LoadBalancer.
+type LoadBalancerIPPoolAllocation struct {
+	// Namespace is the namespace of the LoadBalancer the IP is allocated to.
+	Namespace string `json:"namespace"`
+	// LoadBalancerName is the name of the LoadBalancer the IP is allocated to.
+	LoadBalancerName string `json:"loadBalancerName"`
+	// IP is the allocated IP.
+	IP IP `json:"ip"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=lbippool
+// +kubebuilder:printcolumn:name="CIDRs",type=string,description="Ranges of the pool.",JSONPath=`.spec.cidrs`,priority=0
+// +kubebuilder:printcolumn:name="Type",type=string,description="Type of the loadbalancers allocating from the pool.",JSONPath=`.spec.type`,priority=10
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the loadbalancer ip pool.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// LoadBalancerIPPool is the Schema for the loadbalancerippools API.
+// It provides the IPs of LoadBalancers created without one.
+type LoadBalancerIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of LoadBalancerIPPool.
+	// +kubebuilder:validation:Required
+	Spec LoadBalancerIPPoolSpec `json:"spec"`
+	// Status defines the observed state of LoadBalancerIPPool.
+	Status LoadBalancerIPPoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LoadBalancerIPPoolList contains a list of LoadBalancerIPPool
+type LoadBalancerIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of LoadBalancerIPPool.
+	Items []LoadBalancerIPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancerIPPool{}, &LoadBalancerIPPoolList{})
+}
@@ -27,14 +27,83 @@ type NetworkSpec struct {
 	// +listType=map
 	// +listMapKey=id
 	PeeredPrefixes []PeeredPrefix `json:"peeredPrefixes,omitempty" patchStrategy:"merge" patchMergeKey:"peeredPrefixes"`
+
+	// VirtualIPAnnouncementScope is where the virtual ips of the NetworkInterfaces in the Network are announced.
+	// Defaults to Public.
+	// +optional
+	VirtualIPAnnouncementScope AnnouncementScope `json:"virtualIPAnnouncementScope,omitempty"`
+
+	// DefaultFirewallRules are the firewall rules of all NetworkInterfaces in the Network. A firewall rule of a
+	// NetworkInterface with the same firewallRuleID overrides the default rule for that NetworkInterface.
+	// +optional
+	DefaultFirewallRules []FirewallRule `json:"defaultFirewallRules,omitempty"`
+
+	// DefaultRoute is the default route of the NetworkInterfaces in the Network. Defaults to the default router
+	// of the public VNI.
+	// +optional
+	DefaultRoute *DefaultRoute `json:"defaultRoute,omitempty"`
 }
 
+// DefaultRoute sends the traffic of a Network without a more specific route to a gateway.
+type DefaultRoute struct {
+	// NextHopVNI is the VNI the traffic is sent into. It has to be the ID of the Network or of one of its
+	// peered Networks, otherwise the default router of the public VNI is used.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16777215
+	NextHopVNI int32 `json:"nextHopVNI"`
+	// Gateway is the underlay address of the gateway the traffic is sent to.
+	Gateway IP `json:"gateway"`
+}
+
+// AnnouncementScope defines where a virtual ip is announced.
+// +kubebuilder:validation:Enum=Public;Private
+type AnnouncementScope string
+
+const (
+	// AnnouncementScopePublic announces the virtual ip into the public VNI.
+	AnnouncementScopePublic AnnouncementScope = "Public"
+	// AnnouncementScopePrivate does not announce the virtual ip into the public VNI, so it is not reachable
+	// from the internet. Egress traffic of the NetworkInterface is still translated to the virtual ip.
+	AnnouncementScopePrivate AnnouncementScope = "Private"
+)
+
 // PeeredPrefix contains information of the peered networks and their allowed CIDRs.
 type PeeredPrefix struct {
 	// +kubebuilder:validation:Maximum=16777215
 	// +kubebuilder:validation:Minimum=1
-	ID       int32      `json:"id"`
-	Prefixes []IPPrefix `json:"prefixes"`
+	ID int32 `json:"id"`
+	// Prefixes are the allowed CIDRs of the peered network.
+	// +optional
+	Prefixes []IPPrefix `json:"prefixes,omitempty"`
+	// ExportPrefixes restricts the routes of this Network installed into the peered network to the ones
+	// within the prefixes. If unset, all routes are exported.
+	// +optional
+	ExportPrefixes []IPPrefix `json:"exportPrefixes,omitempty"`
+	// ImportPrefixes restricts the routes of the peered network installed into this Network to the ones
+	// within the prefixes. If unset, all routes are imported.
+	// +optional
+	ImportPrefixes []IPPrefix `json:"importPrefixes,omitempty"`
+}
+
+// NetworkStatus defines the observed state of Network
+type NetworkStatus struct {
+	// Nodes are the VNIs the Network is programmed with on the nodes.
+	// +optional
+	// +listType=map
+	// +listMapKey=nodeName
+	Nodes []NetworkNodeStatus `json:"nodes,omitempty"`
+}
+
+// NetworkNodeStatus are the VNIs a Network is programmed with on a node.
+type NetworkNodeStatus struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName"`
+	// VNI is the VNI the Network was last reconciled with on the node.
+	VNI int32 `json:"vni"`
+	// PreviousVNIs are the VNIs the Network had before its VNI changed. Their state is kept on the node
+	// until no local object uses them anymore.
+	// +optional
+	PreviousVNIs []int32 `json:"previousVNIs,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -48,7 +117,8 @@ type Network struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	// +kubebuilder:validation:Required
-	Spec NetworkSpec `json:"spec"`
+	Spec   NetworkSpec   `json:"spec"`
+	Status NetworkStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
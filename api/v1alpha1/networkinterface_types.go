@@ -10,10 +10,17 @@ import (
 )
 
 // NetworkInterfaceSpec defines the desired state of NetworkInterface
+// +kubebuilder:validation:XValidation:rule="!has(self.ips) || self.ips.all(ip, (ip.contains(':') ? 'IPv6' : 'IPv4') in self.ipFamilies)",message="ips must be of the ipFamilies"
+// +kubebuilder:validation:XValidation:rule="has(self.clusterNetworkRef) != (has(self.networkRef) && has(self.networkRef.name) && size(self.networkRef.name) > 0)",message="exactly one of networkRef and clusterNetworkRef must be set"
 type NetworkInterfaceSpec struct {
-	// NetworkRef is the Network this NetworkInterface is connected to
-	// +kubebuilder:validation:Required
-	NetworkRef corev1.LocalObjectReference `json:"networkRef"`
+	// NetworkRef is the Network this NetworkInterface is connected to. It must not be set together with
+	// ClusterNetworkRef.
+	// +optional
+	NetworkRef corev1.LocalObjectReference `json:"networkRef,omitempty"`
+	// ClusterNetworkRef is the ClusterNetwork this NetworkInterface is connected to instead of a Network of
+	// its namespace.
+	// +optional
+	ClusterNetworkRef *corev1.LocalObjectReference `json:"clusterNetworkRef,omitempty"`
 	// IPFamilies defines which IPFamilies this NetworkInterface is supporting
 	// Only one IP supported at the moment.
 	// +kubebuilder:validation:Required
@@ -21,37 +28,61 @@ type NetworkInterfaceSpec struct {
 	// +kubebuilder:validation:MaxItems=2
 	IPFamilies []corev1.IPFamily `json:"ipFamilies"`
 	// IPs are the provided IPs or EphemeralIPs which should be assigned to this NetworkInterface
-	// Only one IP supported at the moment.
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:MinItems=1
+	// Only one IP supported at the moment. If empty, an IP per IP family is allocated by the IPAM of metalnet.
+	// +optional
 	// +kubebuilder:validation:MaxItems=2
-	IPs []IP `json:"ips"`
+	IPs []IP `json:"ips,omitempty"`
 	// Virtual IP
 	VirtualIP *IP `json:"virtualIP,omitempty"`
+	// VirtualIPAnnouncementScope overrides the VirtualIPAnnouncementScope of the Network for the virtual ip.
+	VirtualIPAnnouncementScope *AnnouncementScope `json:"virtualIPAnnouncementScope,omitempty"`
 	// Prefixes are the provided Prefix
 	Prefixes []IPPrefix `json:"prefixes,omitempty"`
 	// Loadbalancer Targets are the provided Prefix
 	LoadBalancerTargets []IPPrefix `json:"loadBalancerTargets,omitempty"`
+	// LoadBalancerTargetPolicy controls how load balancers hand new connections to this NetworkInterface.
+	LoadBalancerTargetPolicy *LoadBalancerTargetPolicy `json:"loadBalancerTargetPolicy,omitempty"`
 	// NATInfo is detailed information about the NAT on this interface
 	NAT *NATDetails `json:"nat,omitempty"`
+	// InternetGatewayRef is the InternetGateway this NetworkInterface egresses through.
+	// Only used if the NetworkInterface has neither a virtual ip nor a NAT.
+	InternetGatewayRef *corev1.LocalObjectReference `json:"internetGatewayRef,omitempty"`
 	// NodeName is the name of the node on which the interface should be created.
 	NodeName *string `json:"nodeName,omitempty"`
 	// FirewallRules are the firewall rules to be applied to this interface.
 	FirewallRules []FirewallRule `json:"firewallRules,omitempty"`
 	// MeteringRate are the metering parameters to be applied to this interface.
 	MeteringRate *MeteringParameters `json:"meteringRate,omitempty"`
+	// DeviceClaim references the device a device plugin allocated to a pod. If set, the NetworkInterface is
+	// programmed on that device instead of a device of the device pool of metalnet. It is immutable.
+	DeviceClaim *DeviceClaimReference `json:"deviceClaim,omitempty"`
+	// HostInterface programs the NetworkInterface on the host port of dpservice instead of a device of the device
+	// pool of metalnet, so services of the node itself take part in the Network. It is immutable.
+	// +optional
+	HostInterface bool `json:"hostInterface,omitempty"`
 }
 
 // NetworkInterfaceStatus defines the observed state of NetworkInterface
 type NetworkInterfaceStatus struct {
 	PCIAddress *PCIAddress `json:"pciAddress,omitempty"`
 
+	// Device are the details of the device the NetworkInterface is programmed on.
+	Device *DeviceStatus `json:"device,omitempty"`
+
 	// VirtualIP is any virtual ip assigned to the NetworkInterface.
 	VirtualIP *IP `json:"virtualIP,omitempty"`
 
 	// NatIP is detailed information about the NAT on this interface
 	NatIP *NATDetails `json:"natIP,omitempty"`
 
+	// NATPortBlocks are the port blocks of the NatIP announced for the return traffic of the NetworkInterface,
+	// one per VNI the NatIP is announced into.
+	NATPortBlocks []NATPortBlock `json:"natPortBlocks,omitempty"`
+
+	// ReplacedInterfaces are the dpservice interfaces replaced to change the primary ips or the VNI of the
+	// NetworkInterface whose announcements are not withdrawn yet.
+	ReplacedInterfaces []ReplacedInterface `json:"replacedInterfaces,omitempty"`
+
 	// Prefixes are the Prefixes reserved for this NetworkInterface
 	Prefixes []IPPrefix `json:"prefixes,omitempty"`
 
@@ -60,8 +91,136 @@ type NetworkInterfaceStatus struct {
 
 	// State is the NetworkInterfaceState of the NetworkInterface.
 	State NetworkInterfaceState `json:"state,omitempty"`
+
+	// Capture is the state of the last packet capture requested for the NetworkInterface.
+	Capture *PacketCaptureStatus `json:"capture,omitempty"`
+
+	ReconcileTimeline `json:",inline"`
+
+	// Conditions are the conditions of the NetworkInterface.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NATPortBlock is a port block of a NAT ip announced for the return traffic of a NetworkInterface.
+type NATPortBlock struct {
+	// VNI is the VNI the port block is announced into.
+	VNI int32 `json:"vni"`
+	// IP is the NAT ip.
+	IP IP `json:"ip"`
+	// Port is the first port of the port block.
+	Port int32 `json:"port"`
+	// EndPort is the last port of the port block.
+	EndPort int32 `json:"endPort"`
+}
+
+// ReplacedInterface is what was announced for a dpservice interface of a NetworkInterface that was replaced.
+type ReplacedInterface struct {
+	// VNI is the VNI of the replaced interface.
+	VNI int32 `json:"vni"`
+	// IPs are the primary ips of the replaced interface.
+	IPs []IP `json:"ips,omitempty"`
+	// UnderlayRoute is the underlay route of the replaced interface.
+	UnderlayRoute IP `json:"underlayRoute"`
+	// VirtualIP is the virtual ip of the replaced interface.
+	VirtualIP *ReplacedVirtualIP `json:"virtualIP,omitempty"`
+	// NAT is the nat ip of the replaced interface.
+	NAT *ReplacedNAT `json:"nat,omitempty"`
+	// LoadBalancerTargets are the load balancer targets of the replaced interface.
+	LoadBalancerTargets []ReplacedLoadBalancerTarget `json:"loadBalancerTargets,omitempty"`
+	// Prefixes are the alias prefixes announced in the VNI of the replaced interface. They are only recorded
+	// if the VNI changed, otherwise the replacement announces them in the same VNI again.
+	Prefixes []IPPrefix `json:"prefixes,omitempty"`
+}
+
+// ReplacedVirtualIP is the virtual ip of a replaced interface.
+type ReplacedVirtualIP struct {
+	// IP is the virtual ip.
+	IP IP `json:"ip"`
+	// UnderlayRoute is the underlay route the virtual ip was announced with.
+	UnderlayRoute IP `json:"underlayRoute"`
+}
+
+// ReplacedNAT is the nat ip of a replaced interface.
+type ReplacedNAT struct {
+	// IP is the nat ip.
+	IP IP `json:"ip"`
+	// Port is the first port of the port block.
+	Port int32 `json:"port"`
+	// EndPort is the last port of the port block.
+	EndPort int32 `json:"endPort"`
+	// UnderlayRoute is the underlay route the nat ip was announced with.
+	UnderlayRoute IP `json:"underlayRoute"`
 }
 
+// ReplacedLoadBalancerTarget is a load balancer target of a replaced interface.
+type ReplacedLoadBalancerTarget struct {
+	// Prefix is the prefix of the load balancer target.
+	Prefix IPPrefix `json:"prefix"`
+	// UnderlayRoute is the underlay route the load balancer target was announced with.
+	UnderlayRoute IP `json:"underlayRoute"`
+}
+
+// LoadBalancerTargetPolicy defines how a NetworkInterface takes part in load balancing.
+// Weighting the targets is not supported, dpservice has no weights for load balancer targets.
+type LoadBalancerTargetPolicy struct {
+	// Draining withdraws the load balancer target routes of the NetworkInterface, so load balancers
+	// stop handing it new connections.
+	Draining bool `json:"draining,omitempty"`
+}
+
+// DeviceClaimReference references a device of an extended resource a device plugin allocated to a pod, as
+// reported by the pod resources API of the kubelet.
+type DeviceClaimReference struct {
+	// PodRef is the pod in the namespace of the NetworkInterface the device is allocated to.
+	PodRef corev1.LocalObjectReference `json:"podRef"`
+	// ContainerName is the container of the pod the device is allocated to. If empty, the devices of all
+	// containers of the pod are considered.
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+	// ResourceName is the extended resource of the device plugin, e.g. example.com/sriov-vf. Its device IDs
+	// have to be PCI addresses.
+	ResourceName string `json:"resourceName"`
+	// Index selects one of several devices of the resource allocated to the pod, ordered by their PCI address.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Index int32 `json:"index,omitempty"`
+}
+
+// PacketCaptureStatus is the state of a packet capture of a NetworkInterface.
+type PacketCaptureStatus struct {
+	// State is the PacketCaptureState of the capture.
+	State PacketCaptureState `json:"state"`
+	// Path is the location of the pcap file on the node of the NetworkInterface.
+	Path string `json:"path,omitempty"`
+	// Packets is the number of captured packets.
+	Packets int32 `json:"packets,omitempty"`
+	// StartTime is the time the capture was started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the capture was stopped.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is a human-readable description of the state.
+	Message string `json:"message,omitempty"`
+}
+
+// PacketCaptureState is the state of a packet capture.
+type PacketCaptureState string
+
+const (
+	// PacketCaptureStatePending is used while the capture waits for another capture on the node to finish.
+	PacketCaptureStatePending PacketCaptureState = "Pending"
+	// PacketCaptureStateRunning is used while packets are captured.
+	PacketCaptureStateRunning PacketCaptureState = "Running"
+	// PacketCaptureStateCompleted is used when the capture finished and the pcap file is complete.
+	PacketCaptureStateCompleted PacketCaptureState = "Completed"
+	// PacketCaptureStateFailed is used when the capture could not be started or finished.
+	PacketCaptureStateFailed PacketCaptureState = "Failed"
+)
+
 // PCIAddress is a PCI address.
 type PCIAddress struct {
 	Domain   string `json:"domain,omitempty"`
@@ -70,6 +229,20 @@ type PCIAddress struct {
 	Function string `json:"function,omitempty"`
 }
 
+// DeviceStatus are the details of the device a NetworkInterface is programmed on, as published by the
+// device allocator of the node.
+type DeviceStatus struct {
+	// Name is the name dpservice knows the device by.
+	Name string `json:"name,omitempty"`
+	// VFIndex is the index of the device among the virtual functions of its physical function.
+	// Unset if the device is no virtual function.
+	VFIndex *int32 `json:"vfIndex,omitempty"`
+	// Driver is the name of the kernel driver bound to the device.
+	Driver string `json:"driver,omitempty"`
+	// NUMANode is the NUMA node the device is attached to. Unset if the device is not associated with a NUMA node.
+	NUMANode *int32 `json:"numaNode,omitempty"`
+}
+
 // NetworkInterfaceState is the binding state of a NetworkInterface.
 type NetworkInterfaceState string
 
@@ -82,7 +255,103 @@ const (
 	NetworkInterfaceStateError NetworkInterfaceState = "Error"
 )
 
+const (
+	// CaptureAnnotation requests a packet capture of the NetworkInterface. The value is the duration
+	// of the capture (e.g. "30s"). The annotation is removed once the capture is finished and its
+	// result is reported in the status.
+	CaptureAnnotation = "networking.metalnet.ironcore.dev/capture"
+)
+
+const (
+	// AttachedFinalizer is placed on a NetworkInterface by the component attaching it to a machine, as long as
+	// the machine uses the device of the NetworkInterface. The dataplane of a deleted NetworkInterface is only
+	// torn down once the finalizer is removed, i.e. the machine was detached.
+	AttachedFinalizer = "networking.metalnet.ironcore.dev/attached"
+)
+
+const (
+	// NetworkInterfaceVirtualIPReady reports whether the virtual ip in the status is programmed
+	// and announced by the node of the NetworkInterface.
+	NetworkInterfaceVirtualIPReady = "VirtualIPReady"
+)
+
+const (
+	// NetworkInterfaceDetachPending reports whether the teardown of a deleted NetworkInterface waits for the
+	// machine using it to be detached, see AttachedFinalizer.
+	NetworkInterfaceDetachPending = "DetachPending"
+)
+
+const (
+	// DetachPendingReasonAttached is used when the deleted NetworkInterface still carries the AttachedFinalizer.
+	DetachPendingReasonAttached = "Attached"
+)
+
+const (
+	// NetworkInterfaceDeviceReady reports whether the device of the NetworkInterface is ready to be programmed.
+	NetworkInterfaceDeviceReady = "DeviceReady"
+)
+
+const (
+	// DeviceReasonReady is used when the device is ready and the interface is programmed on it.
+	DeviceReasonReady = "Ready"
+	// DeviceReasonNotReady is used when the interface is not programmed because the device is not ready yet.
+	DeviceReasonNotReady = "NotReady"
+)
+
+const (
+	// NetworkInterfaceIPsAllocated reports whether the IPs of a NetworkInterface created without IPs were
+	// allocated by the IPAM of metalnet. Its IPs are released when it is deleted.
+	NetworkInterfaceIPsAllocated = "IPsAllocated"
+)
+
+const (
+	// IPAllocatedReasonNoIPAM is used when metalnet is configured without IPAM.
+	IPAllocatedReasonNoIPAM = "NoIPAM"
+	// IPAllocatedReasonFailed is used when the IPAM failed to allocate the IPs.
+	IPAllocatedReasonFailed = "AllocationFailed"
+)
+
+const (
+	// NetworkInterfacePrefixConflict reports whether the ips or prefixes of the NetworkInterface overlap with
+	// those of another NetworkInterface in the same Network on the same node.
+	NetworkInterfacePrefixConflict = "PrefixConflict"
+)
+
+const (
+	// PrefixConflictReasonConflict is used when the NetworkInterface is not programmed because its ips or
+	// prefixes overlap with those of an older NetworkInterface.
+	PrefixConflictReasonConflict = "Conflict"
+	// PrefixConflictReasonNoConflict is used when the ips and prefixes of the NetworkInterface do not overlap.
+	PrefixConflictReasonNoConflict = "NoConflict"
+)
+
+const (
+	// NetworkInterfaceVNIMigration reports the progress of moving the NetworkInterface to the new VNI of its
+	// Network. It is only set once the VNI of the Network changed.
+	NetworkInterfaceVNIMigration = "VNIMigration"
+)
+
+const (
+	// VNIMigrationReasonMigrating is used when the NetworkInterface is programmed and announced in the new VNI
+	// but the announcements in the old VNI are not withdrawn yet.
+	VNIMigrationReasonMigrating = "Migrating"
+	// VNIMigrationReasonMigrated is used when the announcements in the old VNI are withdrawn.
+	VNIMigrationReasonMigrated = "Migrated"
+)
+
+const (
+	// VirtualIPReasonAnnounced is used when the virtual ip is programmed and announced.
+	VirtualIPReasonAnnounced = "Announced"
+	// VirtualIPReasonHandoverPending is used when the virtual ip was removed from the NetworkInterface
+	// but is kept announced until the NetworkInterface taking it over announces it as well.
+	VirtualIPReasonHandoverPending = "HandoverPending"
+	// VirtualIPReasonPrivate is used when the virtual ip is programmed but not announced into the public VNI.
+	VirtualIPReasonPrivate = "Private"
+)
+
 // FirewallRule defines the desired state of FirewallRule
+// +kubebuilder:validation:XValidation:rule="size(self.ipFamily) == 0 || !has(self.sourcePrefix) || self.sourcePrefix.contains(':') == (self.ipFamily == 'IPv6')",message="sourcePrefix must be of the ipFamily"
+// +kubebuilder:validation:XValidation:rule="size(self.ipFamily) == 0 || !has(self.destinationPrefix) || self.destinationPrefix.contains(':') == (self.ipFamily == 'IPv6')",message="destinationPrefix must be of the ipFamily"
 type FirewallRule struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Type=string
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkInterfaceTemplateLabel is the label of a NetworkInterface holding the name of the
+// NetworkInterfaceTemplate it was stamped out from.
+const NetworkInterfaceTemplateLabel = "networking.metalnet.ironcore.dev/network-interface-template"
+
+// NetworkInterfaceTemplateSpec defines the desired state of NetworkInterfaceTemplate
+type NetworkInterfaceTemplateSpec struct {
+	// Template is the template of the stamped NetworkInterfaces.
+	// +kubebuilder:validation:Required
+	Template NetworkInterfaceTemplateObject `json:"template"`
+	// Instances are the NetworkInterfaces stamped out from the template.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Instances []NetworkInterfaceTemplateInstance `json:"instances,omitempty"`
+	// IPPool is the pool the ips of instances without ips are allocated from.
+	// +optional
+	IPPool *NetworkInterfaceTemplateIPPool `json:"ipPool,omitempty"`
+}
+
+// NetworkInterfaceTemplateObject is the template of the NetworkInterfaces of a NetworkInterfaceTemplate.
+type NetworkInterfaceTemplateObject struct {
+	// Labels are added to the labels of the stamped NetworkInterfaces.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the annotations of the stamped NetworkInterfaces.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Spec is the spec of the stamped NetworkInterfaces. Their ips and node are set per instance.
+	// +kubebuilder:validation:Required
+	Spec NetworkInterfaceTemplateInterfaceSpec `json:"spec"`
+}
+
+// NetworkInterfaceTemplateInterfaceSpec is the part of the NetworkInterfaceSpec shared by all instances of a
+// NetworkInterfaceTemplate.
+type NetworkInterfaceTemplateInterfaceSpec struct {
+	// NetworkRef is the Network the NetworkInterfaces are connected to
+	// +kubebuilder:validation:Required
+	NetworkRef corev1.LocalObjectReference `json:"networkRef"`
+	// IPFamilies defines which IPFamilies the NetworkInterfaces are supporting
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=2
+	IPFamilies []corev1.IPFamily `json:"ipFamilies"`
+	// Loadbalancer Targets are the provided Prefix
+	LoadBalancerTargets []IPPrefix `json:"loadBalancerTargets,omitempty"`
+	// LoadBalancerTargetPolicy controls how load balancers hand new connections to the NetworkInterfaces.
+	LoadBalancerTargetPolicy *LoadBalancerTargetPolicy `json:"loadBalancerTargetPolicy,omitempty"`
+	// InternetGatewayRef is the InternetGateway the NetworkInterfaces egress through.
+	InternetGatewayRef *corev1.LocalObjectReference `json:"internetGatewayRef,omitempty"`
+	// FirewallRules are the firewall rules to be applied to the interfaces.
+	FirewallRules []FirewallRule `json:"firewallRules,omitempty"`
+	// MeteringRate are the metering parameters to be applied to the interfaces.
+	MeteringRate *MeteringParameters `json:"meteringRate,omitempty"`
+}
+
+// NetworkInterfaceTemplateInstance is a NetworkInterface stamped out from a NetworkInterfaceTemplate.
+type NetworkInterfaceTemplateInstance struct {
+	// Name is the name of the instance. The stamped NetworkInterface is named <template>-<name>.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// NodeName is the name of the node on which the interface should be created.
+	// +kubebuilder:validation:MinLength=1
+	NodeName string `json:"nodeName"`
+	// IPs are the ips of the interface. If unset, an ip per ip family is allocated from the ip pool.
+	// +optional
+	// +kubebuilder:validation:MaxItems=2
+	IPs []IP `json:"ips,omitempty"`
+}
+
+// NetworkInterfaceTemplateIPPool is the pool the ips of the instances of a NetworkInterfaceTemplate are
+// allocated from.
+type NetworkInterfaceTemplateIPPool struct {
+	// CIDRs are the ranges the ips are allocated from.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	CIDRs []IPPrefix `json:"cidrs"`
+}
+
+// NetworkInterfaceTemplateStatus defines the observed state of NetworkInterfaceTemplate
+type NetworkInterfaceTemplateStatus struct {
+	// Allocations are the ips allocated to instances from the ip pool.
+	// +optional
+	// +listType=map
+	// +listMapKey=instance
+	Allocations []NetworkInterfaceTemplateAllocation `json:"allocations,omitempty"`
+}
+
+// NetworkInterfaceTemplateAllocation are the ips of the ip pool allocated to an instance.
+type NetworkInterfaceTemplateAllocation struct {
+	// Instance is the name of the instance the ips are allocated to.
+	Instance string `json:"instance"`
+	// IPs are the allocated ips.
+	IPs []IP `json:"ips"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=nictemplate
+// +kubebuilder:printcolumn:name="Network",type=string,description="Network of the stamped network interfaces.",JSONPath=`.spec.template.spec.networkRef.name`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the network interface template.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// NetworkInterfaceTemplate is the Schema for the networkinterfacetemplates API.
+// It stamps out identical NetworkInterfaces on a list of nodes.
+type NetworkInterfaceTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of NetworkInterfaceTemplate.
+	// +kubebuilder:validation:Required
+	Spec NetworkInterfaceTemplateSpec `json:"spec"`
+	// Status defines the observed state of NetworkInterfaceTemplate.
+	Status NetworkInterfaceTemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkInterfaceTemplateList contains a list of NetworkInterfaceTemplate
+type NetworkInterfaceTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of NetworkInterfaceTemplate.
+	Items []NetworkInterfaceTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkInterfaceTemplate{}, &NetworkInterfaceTemplateList{})
+}
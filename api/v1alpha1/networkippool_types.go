@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkIPPoolSpec defines the desired state of NetworkIPPool
+type NetworkIPPoolSpec struct {
+	// NetworkRef is the Network of the namespace whose NetworkInterfaces allocate their IPs from this pool.
+	// +kubebuilder:validation:Required
+	NetworkRef corev1.LocalObjectReference `json:"networkRef"`
+	// CIDRs are the ranges the IPs of NetworkInterfaces are allocated from.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	CIDRs []IPPrefix `json:"cidrs"`
+}
+
+// NetworkIPPoolStatus defines the observed state of NetworkIPPool
+type NetworkIPPoolStatus struct {
+	// Allocations are the IPs allocated to NetworkInterfaces.
+	// +optional
+	// +listType=map
+	// +listMapKey=networkInterfaceName
+	Allocations []NetworkIPPoolAllocation `json:"allocations,omitempty"`
+}
+
+// NetworkIPPoolAllocation are the IPs of the pool allocated to a NetworkInterface.
+type NetworkIPPoolAllocation struct {
+	// NetworkInterfaceName is the name of the NetworkInterface the IPs are allocated to.
+	NetworkInterfaceName string `json:"networkInterfaceName"`
+	// IPs are the allocated IPs, one per IP family of the NetworkInterface.
+	IPs []IP `json:"ips"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=nipp
+// +kubebuilder:printcolumn:name="Network",type=string,description="Network of the pool.",JSONPath=`.spec.networkRef.name`,priority=0
+// +kubebuilder:printcolumn:name="CIDRs",type=string,description="Ranges of the pool.",JSONPath=`.spec.cidrs`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the network ip pool.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// NetworkIPPool is the Schema for the networkippools API.
+// It provides the IPs of the NetworkInterfaces of a Network created without IPs.
+type NetworkIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of NetworkIPPool.
+	// +kubebuilder:validation:Required
+	Spec NetworkIPPoolSpec `json:"spec"`
+	// Status defines the observed state of NetworkIPPool.
+	Status NetworkIPPoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkIPPoolList contains a list of NetworkIPPool
+type NetworkIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of NetworkIPPool.
+	Items []NetworkIPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkIPPool{}, &NetworkIPPoolList{})
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceChainSpec defines the desired state of ServiceChain
+type ServiceChainSpec struct {
+	// NetworkRef is the Network whose traffic to the prefixes is steered through the hops.
+	// +kubebuilder:validation:Required
+	NetworkRef corev1.LocalObjectReference `json:"networkRef"`
+	// Prefixes are the destination prefixes steered through the hops.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Prefixes []IPPrefix `json:"prefixes"`
+	// Hops are the middleboxes the traffic passes in order. The traffic leaves the last hop through the routes
+	// of its VNI.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	Hops []ServiceChainHop `json:"hops"`
+}
+
+// ServiceChainHop is a middlebox of a ServiceChain.
+type ServiceChainHop struct {
+	// VNI is the VNI of the middlebox interface. It must differ from the VNI of the Network and of the other hops.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=16777215
+	VNI int32 `json:"vni"`
+	// UnderlayAddress is the underlay address of the middlebox interface.
+	UnderlayAddress IP `json:"underlayAddress"`
+}
+
+// ServiceChainStatus defines the observed state of ServiceChain
+type ServiceChainStatus struct {
+	// Nodes are the route entries programmed on the nodes.
+	// +optional
+	// +listType=map
+	// +listMapKey=nodeName
+	Nodes []ServiceChainNodeStatus `json:"nodes,omitempty"`
+}
+
+// ServiceChainNodeStatus are the route entries of a ServiceChain programmed on a node.
+type ServiceChainNodeStatus struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName"`
+	// Routes are the programmed route entries, in the order of the chain.
+	// +optional
+	Routes []ServiceChainRoute `json:"routes,omitempty"`
+}
+
+// ServiceChainRoute is a route entry of a ServiceChain.
+type ServiceChainRoute struct {
+	// VNI is the VNI the route entry is programmed in.
+	VNI int32 `json:"vni"`
+	// Prefix is the destination prefix of the route entry.
+	Prefix IPPrefix `json:"prefix"`
+	// NextHopVNI is the VNI of the next hop.
+	NextHopVNI int32 `json:"nextHopVNI"`
+	// NextHopAddress is the underlay address of the next hop.
+	NextHopAddress IP `json:"nextHopAddress"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sc
+// +kubebuilder:printcolumn:name="Network",type=string,description="Network whose traffic is steered.",JSONPath=`.spec.networkRef.name`,priority=0
+// +kubebuilder:printcolumn:name="Prefixes",type=string,description="Steered destination prefixes.",JSONPath=`.spec.prefixes`,priority=0
+// +kubebuilder:printcolumn:name="Hops",type=string,description="VNIs of the hops.",JSONPath=`.spec.hops[*].vni`,priority=10
+// +kubebuilder:printcolumn:name="Age",type=date,description="Age of the service chain.",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// ServiceChain is the Schema for the servicechains API.
+// It steers the traffic of a Network to prefixes through a chain of middleboxes by rewriting the next hop of
+// their routes.
+type ServiceChain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of ServiceChain.
+	// +kubebuilder:validation:Required
+	Spec ServiceChainSpec `json:"spec"`
+	// Status defines the observed state of ServiceChain.
+	Status ServiceChainStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ServiceChainList contains a list of ServiceChain
+type ServiceChainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of ServiceChain.
+	Items []ServiceChain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceChain{}, &ServiceChainList{})
+}
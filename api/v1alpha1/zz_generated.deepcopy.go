@@ -8,104 +8,105 @@
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FirewallRule) DeepCopyInto(out *FirewallRule) {
+func (in *Allocation) DeepCopyInto(out *Allocation) {
 	*out = *in
-	if in.Priority != nil {
-		in, out := &in.Priority, &out.Priority
-		*out = new(int32)
-		**out = **in
-	}
-	if in.SourcePrefix != nil {
-		in, out := &in.SourcePrefix, &out.SourcePrefix
-		*out = (*in).DeepCopy()
-	}
-	if in.DestinationPrefix != nil {
-		in, out := &in.DestinationPrefix, &out.DestinationPrefix
-		*out = (*in).DeepCopy()
-	}
-	if in.ProtocolMatch != nil {
-		in, out := &in.ProtocolMatch, &out.ProtocolMatch
-		*out = new(ProtocolMatch)
-		(*in).DeepCopyInto(*out)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallRule.
-func (in *FirewallRule) DeepCopy() *FirewallRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Allocation.
+func (in *Allocation) DeepCopy() *Allocation {
 	if in == nil {
 		return nil
 	}
-	out := new(FirewallRule)
+	out := new(Allocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Allocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ICMPMatch) DeepCopyInto(out *ICMPMatch) {
+func (in *AllocationList) DeepCopyInto(out *AllocationList) {
 	*out = *in
-	if in.IcmpType != nil {
-		in, out := &in.IcmpType, &out.IcmpType
-		*out = new(int32)
-		**out = **in
-	}
-	if in.IcmpCode != nil {
-		in, out := &in.IcmpCode, &out.IcmpCode
-		*out = new(int32)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Allocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ICMPMatch.
-func (in *ICMPMatch) DeepCopy() *ICMPMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationList.
+func (in *AllocationList) DeepCopy() *AllocationList {
 	if in == nil {
 		return nil
 	}
-	out := new(ICMPMatch)
+	out := new(AllocationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AllocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LBPort) DeepCopyInto(out *LBPort) {
+func (in *AllocationSpec) DeepCopyInto(out *AllocationSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LBPort.
-func (in *LBPort) DeepCopy() *LBPort {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationSpec.
+func (in *AllocationSpec) DeepCopy() *AllocationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LBPort)
+	out := new(AllocationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
+func (in *ClusterNetwork) DeepCopyInto(out *ClusterNetwork) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancer.
-func (in *LoadBalancer) DeepCopy() *LoadBalancer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetwork.
+func (in *ClusterNetwork) DeepCopy() *ClusterNetwork {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadBalancer)
+	out := new(ClusterNetwork)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LoadBalancer) DeepCopyObject() runtime.Object {
+func (in *ClusterNetwork) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -113,31 +114,31 @@ func (in *LoadBalancer) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadBalancerList) DeepCopyInto(out *LoadBalancerList) {
+func (in *ClusterNetworkList) DeepCopyInto(out *ClusterNetworkList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LoadBalancer, len(*in))
+		*out = make([]ClusterNetwork, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerList.
-func (in *LoadBalancerList) DeepCopy() *LoadBalancerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetworkList.
+func (in *ClusterNetworkList) DeepCopy() *ClusterNetworkList {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadBalancerList)
+	out := new(ClusterNetworkList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LoadBalancerList) DeepCopyObject() runtime.Object {
+func (in *ClusterNetworkList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -145,126 +146,156 @@ func (in *LoadBalancerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadBalancerSpec) DeepCopyInto(out *LoadBalancerSpec) {
+func (in *DefaultRoute) DeepCopyInto(out *DefaultRoute) {
 	*out = *in
-	out.NetworkRef = in.NetworkRef
-	in.IP.DeepCopyInto(&out.IP)
-	if in.Ports != nil {
-		in, out := &in.Ports, &out.Ports
-		*out = make([]LBPort, len(*in))
-		copy(*out, *in)
-	}
-	if in.NodeName != nil {
-		in, out := &in.NodeName, &out.NodeName
-		*out = new(string)
-		**out = **in
+	in.Gateway.DeepCopyInto(&out.Gateway)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultRoute.
+func (in *DefaultRoute) DeepCopy() *DefaultRoute {
+	if in == nil {
+		return nil
 	}
+	out := new(DefaultRoute)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerSpec.
-func (in *LoadBalancerSpec) DeepCopy() *LoadBalancerSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClaimReference) DeepCopyInto(out *DeviceClaimReference) {
+	*out = *in
+	out.PodRef = in.PodRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClaimReference.
+func (in *DeviceClaimReference) DeepCopy() *DeviceClaimReference {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadBalancerSpec)
+	out := new(DeviceClaimReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadBalancerStatus) DeepCopyInto(out *LoadBalancerStatus) {
+func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
 	*out = *in
+	if in.VFIndex != nil {
+		in, out := &in.VFIndex, &out.VFIndex
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NUMANode != nil {
+		in, out := &in.NUMANode, &out.NUMANode
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStatus.
-func (in *LoadBalancerStatus) DeepCopy() *LoadBalancerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceStatus.
+func (in *DeviceStatus) DeepCopy() *DeviceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadBalancerStatus)
+	out := new(DeviceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalUIDReference) DeepCopyInto(out *LocalUIDReference) {
+func (in *EndpointSliceTargets) DeepCopyInto(out *EndpointSliceTargets) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalUIDReference.
-func (in *LocalUIDReference) DeepCopy() *LocalUIDReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceTargets.
+func (in *EndpointSliceTargets) DeepCopy() *EndpointSliceTargets {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalUIDReference)
+	out := new(EndpointSliceTargets)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MeteringParameters) DeepCopyInto(out *MeteringParameters) {
+func (in *FirewallRule) DeepCopyInto(out *FirewallRule) {
 	*out = *in
-	if in.TotalRate != nil {
-		in, out := &in.TotalRate, &out.TotalRate
-		*out = new(uint64)
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
 		**out = **in
 	}
-	if in.PublicRate != nil {
-		in, out := &in.PublicRate, &out.PublicRate
-		*out = new(uint64)
-		**out = **in
+	if in.SourcePrefix != nil {
+		in, out := &in.SourcePrefix, &out.SourcePrefix
+		*out = (*in).DeepCopy()
+	}
+	if in.DestinationPrefix != nil {
+		in, out := &in.DestinationPrefix, &out.DestinationPrefix
+		*out = (*in).DeepCopy()
+	}
+	if in.ProtocolMatch != nil {
+		in, out := &in.ProtocolMatch, &out.ProtocolMatch
+		*out = new(ProtocolMatch)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringParameters.
-func (in *MeteringParameters) DeepCopy() *MeteringParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallRule.
+func (in *FirewallRule) DeepCopy() *FirewallRule {
 	if in == nil {
 		return nil
 	}
-	out := new(MeteringParameters)
+	out := new(FirewallRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NATDetails) DeepCopyInto(out *NATDetails) {
+func (in *ICMPMatch) DeepCopyInto(out *ICMPMatch) {
 	*out = *in
-	if in.IP != nil {
-		in, out := &in.IP, &out.IP
-		*out = (*in).DeepCopy()
+	if in.IcmpType != nil {
+		in, out := &in.IcmpType, &out.IcmpType
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IcmpCode != nil {
+		in, out := &in.IcmpCode, &out.IcmpCode
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATDetails.
-func (in *NATDetails) DeepCopy() *NATDetails {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ICMPMatch.
+func (in *ICMPMatch) DeepCopy() *ICMPMatch {
 	if in == nil {
 		return nil
 	}
-	out := new(NATDetails)
+	out := new(ICMPMatch)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Network) DeepCopyInto(out *Network) {
+func (in *InternetGateway) DeepCopyInto(out *InternetGateway) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
-func (in *Network) DeepCopy() *Network {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternetGateway.
+func (in *InternetGateway) DeepCopy() *InternetGateway {
 	if in == nil {
 		return nil
 	}
-	out := new(Network)
+	out := new(InternetGateway)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Network) DeepCopyObject() runtime.Object {
+func (in *InternetGateway) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -272,58 +303,63 @@ func (in *Network) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+func (in *InternetGatewayAllocation) DeepCopyInto(out *InternetGatewayAllocation) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	in.IP.DeepCopyInto(&out.IP)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
-func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternetGatewayAllocation.
+func (in *InternetGatewayAllocation) DeepCopy() *InternetGatewayAllocation {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkInterface)
+	out := new(InternetGatewayAllocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkInterface) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternetGatewayIPStatus) DeepCopyInto(out *InternetGatewayIPStatus) {
+	*out = *in
+	in.IP.DeepCopyInto(&out.IP)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternetGatewayIPStatus.
+func (in *InternetGatewayIPStatus) DeepCopy() *InternetGatewayIPStatus {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(InternetGatewayIPStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkInterfaceList) DeepCopyInto(out *NetworkInterfaceList) {
+func (in *InternetGatewayList) DeepCopyInto(out *InternetGatewayList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NetworkInterface, len(*in))
+		*out = make([]InternetGateway, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceList.
-func (in *NetworkInterfaceList) DeepCopy() *NetworkInterfaceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternetGatewayList.
+func (in *InternetGatewayList) DeepCopy() *InternetGatewayList {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkInterfaceList)
+	out := new(InternetGatewayList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkInterfaceList) DeepCopyObject() runtime.Object {
+func (in *InternetGatewayList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -331,14 +367,8 @@ func (in *NetworkInterfaceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkInterfaceSpec) DeepCopyInto(out *NetworkInterfaceSpec) {
+func (in *InternetGatewaySpec) DeepCopyInto(out *InternetGatewaySpec) {
 	*out = *in
-	out.NetworkRef = in.NetworkRef
-	if in.IPFamilies != nil {
-		in, out := &in.IPFamilies, &out.IPFamilies
-		*out = make([]v1.IPFamily, len(*in))
-		copy(*out, *in)
-	}
 	if in.IPs != nil {
 		in, out := &in.IPs, &out.IPs
 		*out = make([]IP, len(*in))
@@ -346,127 +376,251 @@ func (in *NetworkInterfaceSpec) DeepCopyInto(out *NetworkInterfaceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.VirtualIP != nil {
-		in, out := &in.VirtualIP, &out.VirtualIP
-		*out = (*in).DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternetGatewaySpec.
+func (in *InternetGatewaySpec) DeepCopy() *InternetGatewaySpec {
+	if in == nil {
+		return nil
 	}
-	if in.Prefixes != nil {
-		in, out := &in.Prefixes, &out.Prefixes
-		*out = make([]IPPrefix, len(*in))
+	out := new(InternetGatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternetGatewayStatus) DeepCopyInto(out *InternetGatewayStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]InternetGatewayAllocation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LoadBalancerTargets != nil {
-		in, out := &in.LoadBalancerTargets, &out.LoadBalancerTargets
-		*out = make([]IPPrefix, len(*in))
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]InternetGatewayIPStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.NAT != nil {
-		in, out := &in.NAT, &out.NAT
-		*out = new(NATDetails)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.NodeName != nil {
-		in, out := &in.NodeName, &out.NodeName
-		*out = new(string)
-		**out = **in
-	}
-	if in.FirewallRules != nil {
-		in, out := &in.FirewallRules, &out.FirewallRules
-		*out = make([]FirewallRule, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.MeteringRate != nil {
-		in, out := &in.MeteringRate, &out.MeteringRate
-		*out = new(MeteringParameters)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceSpec.
-func (in *NetworkInterfaceSpec) DeepCopy() *NetworkInterfaceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternetGatewayStatus.
+func (in *InternetGatewayStatus) DeepCopy() *InternetGatewayStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkInterfaceSpec)
+	out := new(InternetGatewayStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkInterfaceStatus) DeepCopyInto(out *NetworkInterfaceStatus) {
+func (in *LBPort) DeepCopyInto(out *LBPort) {
 	*out = *in
-	if in.PCIAddress != nil {
-		in, out := &in.PCIAddress, &out.PCIAddress
-		*out = new(PCIAddress)
-		**out = **in
-	}
-	if in.VirtualIP != nil {
-		in, out := &in.VirtualIP, &out.VirtualIP
-		*out = (*in).DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LBPort.
+func (in *LBPort) DeepCopy() *LBPort {
+	if in == nil {
+		return nil
 	}
-	if in.NatIP != nil {
-		in, out := &in.NatIP, &out.NatIP
-		*out = new(NATDetails)
-		(*in).DeepCopyInto(*out)
+	out := new(LBPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancer.
+func (in *LoadBalancer) DeepCopy() *LoadBalancer {
+	if in == nil {
+		return nil
 	}
-	if in.Prefixes != nil {
-		in, out := &in.Prefixes, &out.Prefixes
-		*out = make([]IPPrefix, len(*in))
+	out := new(LoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerIPPool) DeepCopyInto(out *LoadBalancerIPPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerIPPool.
+func (in *LoadBalancerIPPool) DeepCopy() *LoadBalancerIPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerIPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerIPPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerIPPoolAllocation) DeepCopyInto(out *LoadBalancerIPPoolAllocation) {
+	*out = *in
+	in.IP.DeepCopyInto(&out.IP)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerIPPoolAllocation.
+func (in *LoadBalancerIPPoolAllocation) DeepCopy() *LoadBalancerIPPoolAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerIPPoolAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerIPPoolList) DeepCopyInto(out *LoadBalancerIPPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancerIPPool, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LoadBalancerTargets != nil {
-		in, out := &in.LoadBalancerTargets, &out.LoadBalancerTargets
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerIPPoolList.
+func (in *LoadBalancerIPPoolList) DeepCopy() *LoadBalancerIPPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerIPPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerIPPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerIPPoolSpec) DeepCopyInto(out *LoadBalancerIPPoolSpec) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
 		*out = make([]IPPrefix, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(LoadBalancerType)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceStatus.
-func (in *NetworkInterfaceStatus) DeepCopy() *NetworkInterfaceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerIPPoolSpec.
+func (in *LoadBalancerIPPoolSpec) DeepCopy() *LoadBalancerIPPoolSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkInterfaceStatus)
+	out := new(LoadBalancerIPPoolSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkList) DeepCopyInto(out *NetworkList) {
+func (in *LoadBalancerIPPoolStatus) DeepCopyInto(out *LoadBalancerIPPoolStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]LoadBalancerIPPoolAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerIPPoolStatus.
+func (in *LoadBalancerIPPoolStatus) DeepCopy() *LoadBalancerIPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerIPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerList) DeepCopyInto(out *LoadBalancerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Network, len(*in))
+		*out = make([]LoadBalancer, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkList.
-func (in *NetworkList) DeepCopy() *NetworkList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerList.
+func (in *LoadBalancerList) DeepCopy() *LoadBalancerList {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkList)
+	out := new(LoadBalancerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkList) DeepCopyObject() runtime.Object {
+func (in *LoadBalancerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -474,120 +628,1325 @@ func (in *NetworkList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+func (in *LoadBalancerSpec) DeepCopyInto(out *LoadBalancerSpec) {
 	*out = *in
-	if in.PeeredIDs != nil {
-		in, out := &in.PeeredIDs, &out.PeeredIDs
-		*out = make([]int32, len(*in))
+	out.NetworkRef = in.NetworkRef
+	in.IP.DeepCopyInto(&out.IP)
+	if in.IPPoolRef != nil {
+		in, out := &in.IPPoolRef, &out.IPPoolRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]LBPort, len(*in))
 		copy(*out, *in)
 	}
-	if in.PeeredPrefixes != nil {
-		in, out := &in.PeeredPrefixes, &out.PeeredPrefixes
-		*out = make([]PeeredPrefix, len(*in))
+	if in.NodeName != nil {
+		in, out := &in.NodeName, &out.NodeName
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EndpointSliceTargets != nil {
+		in, out := &in.EndpointSliceTargets, &out.EndpointSliceTargets
+		*out = new(EndpointSliceTargets)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerSpec.
+func (in *LoadBalancerSpec) DeepCopy() *LoadBalancerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStatus) DeepCopyInto(out *LoadBalancerStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ReconcileTimeline.DeepCopyInto(&out.ReconcileTimeline)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
-func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStatus.
+func (in *LoadBalancerStatus) DeepCopy() *LoadBalancerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkSpec)
+	out := new(LoadBalancerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCIAddress) DeepCopyInto(out *PCIAddress) {
+func (in *LoadBalancerTargetPolicy) DeepCopyInto(out *LoadBalancerTargetPolicy) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIAddress.
-func (in *PCIAddress) DeepCopy() *PCIAddress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerTargetPolicy.
+func (in *LoadBalancerTargetPolicy) DeepCopy() *LoadBalancerTargetPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(PCIAddress)
+	out := new(LoadBalancerTargetPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PeeredPrefix) DeepCopyInto(out *PeeredPrefix) {
+func (in *LocalUIDReference) DeepCopyInto(out *LocalUIDReference) {
 	*out = *in
-	if in.Prefixes != nil {
-		in, out := &in.Prefixes, &out.Prefixes
-		*out = make([]IPPrefix, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PeeredPrefix.
-func (in *PeeredPrefix) DeepCopy() *PeeredPrefix {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalUIDReference.
+func (in *LocalUIDReference) DeepCopy() *LocalUIDReference {
 	if in == nil {
 		return nil
 	}
-	out := new(PeeredPrefix)
+	out := new(LocalUIDReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PortMatch) DeepCopyInto(out *PortMatch) {
+func (in *MeteringParameters) DeepCopyInto(out *MeteringParameters) {
 	*out = *in
-	if in.SrcPort != nil {
-		in, out := &in.SrcPort, &out.SrcPort
-		*out = new(int32)
+	if in.TotalRate != nil {
+		in, out := &in.TotalRate, &out.TotalRate
+		*out = new(uint64)
 		**out = **in
 	}
-	if in.DstPort != nil {
-		in, out := &in.DstPort, &out.DstPort
-		*out = new(int32)
+	if in.PublicRate != nil {
+		in, out := &in.PublicRate, &out.PublicRate
+		*out = new(uint64)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortMatch.
-func (in *PortMatch) DeepCopy() *PortMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeteringParameters.
+func (in *MeteringParameters) DeepCopy() *MeteringParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(PortMatch)
+	out := new(MeteringParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProtocolMatch) DeepCopyInto(out *ProtocolMatch) {
+func (in *NATDetails) DeepCopyInto(out *NATDetails) {
 	*out = *in
-	if in.ProtocolType != nil {
-		in, out := &in.ProtocolType, &out.ProtocolType
-		*out = new(ProtocolType)
-		**out = **in
-	}
-	if in.ICMP != nil {
-		in, out := &in.ICMP, &out.ICMP
-		*out = new(ICMPMatch)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PortRange != nil {
-		in, out := &in.PortRange, &out.PortRange
-		*out = new(PortMatch)
-		(*in).DeepCopyInto(*out)
+	if in.IP != nil {
+		in, out := &in.IP, &out.IP
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtocolMatch.
-func (in *ProtocolMatch) DeepCopy() *ProtocolMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATDetails.
+func (in *NATDetails) DeepCopy() *NATDetails {
 	if in == nil {
 		return nil
 	}
-	out := new(ProtocolMatch)
+	out := new(NATDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATPortBlock) DeepCopyInto(out *NATPortBlock) {
+	*out = *in
+	in.IP.DeepCopyInto(&out.IP)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATPortBlock.
+func (in *NATPortBlock) DeepCopy() *NATPortBlock {
+	if in == nil {
+		return nil
+	}
+	out := new(NATPortBlock)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Network) DeepCopyInto(out *Network) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
+func (in *Network) DeepCopy() *Network {
+	if in == nil {
+		return nil
+	}
+	out := new(Network)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Network) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIPPool) DeepCopyInto(out *NetworkIPPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIPPool.
+func (in *NetworkIPPool) DeepCopy() *NetworkIPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkIPPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIPPoolAllocation) DeepCopyInto(out *NetworkIPPoolAllocation) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]IP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIPPoolAllocation.
+func (in *NetworkIPPoolAllocation) DeepCopy() *NetworkIPPoolAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIPPoolAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIPPoolList) DeepCopyInto(out *NetworkIPPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkIPPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIPPoolList.
+func (in *NetworkIPPoolList) DeepCopy() *NetworkIPPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIPPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkIPPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIPPoolSpec) DeepCopyInto(out *NetworkIPPoolSpec) {
+	*out = *in
+	out.NetworkRef = in.NetworkRef
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIPPoolSpec.
+func (in *NetworkIPPoolSpec) DeepCopy() *NetworkIPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIPPoolStatus) DeepCopyInto(out *NetworkIPPoolStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]NetworkIPPoolAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIPPoolStatus.
+func (in *NetworkIPPoolStatus) DeepCopy() *NetworkIPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
+func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkInterface) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceList) DeepCopyInto(out *NetworkInterfaceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceList.
+func (in *NetworkInterfaceList) DeepCopy() *NetworkInterfaceList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkInterfaceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceSpec) DeepCopyInto(out *NetworkInterfaceSpec) {
+	*out = *in
+	out.NetworkRef = in.NetworkRef
+	if in.ClusterNetworkRef != nil {
+		in, out := &in.ClusterNetworkRef, &out.ClusterNetworkRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]IP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VirtualIP != nil {
+		in, out := &in.VirtualIP, &out.VirtualIP
+		*out = (*in).DeepCopy()
+	}
+	if in.VirtualIPAnnouncementScope != nil {
+		in, out := &in.VirtualIPAnnouncementScope, &out.VirtualIPAnnouncementScope
+		*out = new(AnnouncementScope)
+		**out = **in
+	}
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerTargets != nil {
+		in, out := &in.LoadBalancerTargets, &out.LoadBalancerTargets
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerTargetPolicy != nil {
+		in, out := &in.LoadBalancerTargetPolicy, &out.LoadBalancerTargetPolicy
+		*out = new(LoadBalancerTargetPolicy)
+		**out = **in
+	}
+	if in.NAT != nil {
+		in, out := &in.NAT, &out.NAT
+		*out = new(NATDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InternetGatewayRef != nil {
+		in, out := &in.InternetGatewayRef, &out.InternetGatewayRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.NodeName != nil {
+		in, out := &in.NodeName, &out.NodeName
+		*out = new(string)
+		**out = **in
+	}
+	if in.FirewallRules != nil {
+		in, out := &in.FirewallRules, &out.FirewallRules
+		*out = make([]FirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MeteringRate != nil {
+		in, out := &in.MeteringRate, &out.MeteringRate
+		*out = new(MeteringParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeviceClaim != nil {
+		in, out := &in.DeviceClaim, &out.DeviceClaim
+		*out = new(DeviceClaimReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceSpec.
+func (in *NetworkInterfaceSpec) DeepCopy() *NetworkInterfaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceStatus) DeepCopyInto(out *NetworkInterfaceStatus) {
+	*out = *in
+	if in.PCIAddress != nil {
+		in, out := &in.PCIAddress, &out.PCIAddress
+		*out = new(PCIAddress)
+		**out = **in
+	}
+	if in.Device != nil {
+		in, out := &in.Device, &out.Device
+		*out = new(DeviceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualIP != nil {
+		in, out := &in.VirtualIP, &out.VirtualIP
+		*out = (*in).DeepCopy()
+	}
+	if in.NatIP != nil {
+		in, out := &in.NatIP, &out.NatIP
+		*out = new(NATDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NATPortBlocks != nil {
+		in, out := &in.NATPortBlocks, &out.NATPortBlocks
+		*out = make([]NATPortBlock, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReplacedInterfaces != nil {
+		in, out := &in.ReplacedInterfaces, &out.ReplacedInterfaces
+		*out = make([]ReplacedInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerTargets != nil {
+		in, out := &in.LoadBalancerTargets, &out.LoadBalancerTargets
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Capture != nil {
+		in, out := &in.Capture, &out.Capture
+		*out = new(PacketCaptureStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ReconcileTimeline.DeepCopyInto(&out.ReconcileTimeline)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceStatus.
+func (in *NetworkInterfaceStatus) DeepCopy() *NetworkInterfaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplate) DeepCopyInto(out *NetworkInterfaceTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplate.
+func (in *NetworkInterfaceTemplate) DeepCopy() *NetworkInterfaceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkInterfaceTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateAllocation) DeepCopyInto(out *NetworkInterfaceTemplateAllocation) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]IP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateAllocation.
+func (in *NetworkInterfaceTemplateAllocation) DeepCopy() *NetworkInterfaceTemplateAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateIPPool) DeepCopyInto(out *NetworkInterfaceTemplateIPPool) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateIPPool.
+func (in *NetworkInterfaceTemplateIPPool) DeepCopy() *NetworkInterfaceTemplateIPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateIPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateInstance) DeepCopyInto(out *NetworkInterfaceTemplateInstance) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]IP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateInstance.
+func (in *NetworkInterfaceTemplateInstance) DeepCopy() *NetworkInterfaceTemplateInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateInterfaceSpec) DeepCopyInto(out *NetworkInterfaceTemplateInterfaceSpec) {
+	*out = *in
+	out.NetworkRef = in.NetworkRef
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadBalancerTargets != nil {
+		in, out := &in.LoadBalancerTargets, &out.LoadBalancerTargets
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerTargetPolicy != nil {
+		in, out := &in.LoadBalancerTargetPolicy, &out.LoadBalancerTargetPolicy
+		*out = new(LoadBalancerTargetPolicy)
+		**out = **in
+	}
+	if in.InternetGatewayRef != nil {
+		in, out := &in.InternetGatewayRef, &out.InternetGatewayRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.FirewallRules != nil {
+		in, out := &in.FirewallRules, &out.FirewallRules
+		*out = make([]FirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MeteringRate != nil {
+		in, out := &in.MeteringRate, &out.MeteringRate
+		*out = new(MeteringParameters)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateInterfaceSpec.
+func (in *NetworkInterfaceTemplateInterfaceSpec) DeepCopy() *NetworkInterfaceTemplateInterfaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateInterfaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateList) DeepCopyInto(out *NetworkInterfaceTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkInterfaceTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateList.
+func (in *NetworkInterfaceTemplateList) DeepCopy() *NetworkInterfaceTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkInterfaceTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateObject) DeepCopyInto(out *NetworkInterfaceTemplateObject) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateObject.
+func (in *NetworkInterfaceTemplateObject) DeepCopy() *NetworkInterfaceTemplateObject {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateSpec) DeepCopyInto(out *NetworkInterfaceTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]NetworkInterfaceTemplateInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IPPool != nil {
+		in, out := &in.IPPool, &out.IPPool
+		*out = new(NetworkInterfaceTemplateIPPool)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateSpec.
+func (in *NetworkInterfaceTemplateSpec) DeepCopy() *NetworkInterfaceTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterfaceTemplateStatus) DeepCopyInto(out *NetworkInterfaceTemplateStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]NetworkInterfaceTemplateAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterfaceTemplateStatus.
+func (in *NetworkInterfaceTemplateStatus) DeepCopy() *NetworkInterfaceTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterfaceTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkList) DeepCopyInto(out *NetworkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Network, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkList.
+func (in *NetworkList) DeepCopy() *NetworkList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNodeStatus) DeepCopyInto(out *NetworkNodeStatus) {
+	*out = *in
+	if in.PreviousVNIs != nil {
+		in, out := &in.PreviousVNIs, &out.PreviousVNIs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNodeStatus.
+func (in *NetworkNodeStatus) DeepCopy() *NetworkNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+	*out = *in
+	if in.PeeredIDs != nil {
+		in, out := &in.PeeredIDs, &out.PeeredIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.PeeredPrefixes != nil {
+		in, out := &in.PeeredPrefixes, &out.PeeredPrefixes
+		*out = make([]PeeredPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultFirewallRules != nil {
+		in, out := &in.DefaultFirewallRules, &out.DefaultFirewallRules
+		*out = make([]FirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultRoute != nil {
+		in, out := &in.DefaultRoute, &out.DefaultRoute
+		*out = new(DefaultRoute)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
+func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkStatus) DeepCopyInto(out *NetworkStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NetworkNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkStatus.
+func (in *NetworkStatus) DeepCopy() *NetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PCIAddress) DeepCopyInto(out *PCIAddress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCIAddress.
+func (in *PCIAddress) DeepCopy() *PCIAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(PCIAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketCaptureStatus) DeepCopyInto(out *PacketCaptureStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCaptureStatus.
+func (in *PacketCaptureStatus) DeepCopy() *PacketCaptureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCaptureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PeeredPrefix) DeepCopyInto(out *PeeredPrefix) {
+	*out = *in
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExportPrefixes != nil {
+		in, out := &in.ExportPrefixes, &out.ExportPrefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImportPrefixes != nil {
+		in, out := &in.ImportPrefixes, &out.ImportPrefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PeeredPrefix.
+func (in *PeeredPrefix) DeepCopy() *PeeredPrefix {
+	if in == nil {
+		return nil
+	}
+	out := new(PeeredPrefix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortMatch) DeepCopyInto(out *PortMatch) {
+	*out = *in
+	if in.SrcPort != nil {
+		in, out := &in.SrcPort, &out.SrcPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DstPort != nil {
+		in, out := &in.DstPort, &out.DstPort
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortMatch.
+func (in *PortMatch) DeepCopy() *PortMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(PortMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtocolMatch) DeepCopyInto(out *ProtocolMatch) {
+	*out = *in
+	if in.ProtocolType != nil {
+		in, out := &in.ProtocolType, &out.ProtocolType
+		*out = new(ProtocolType)
+		**out = **in
+	}
+	if in.ICMP != nil {
+		in, out := &in.ICMP, &out.ICMP
+		*out = new(ICMPMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PortRange != nil {
+		in, out := &in.PortRange, &out.PortRange
+		*out = new(PortMatch)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtocolMatch.
+func (in *ProtocolMatch) DeepCopy() *ProtocolMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtocolMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileTimeline) DeepCopyInto(out *ReconcileTimeline) {
+	*out = *in
+	if in.LastProgrammedTime != nil {
+		in, out := &in.LastProgrammedTime, &out.LastProgrammedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAnnouncedTime != nil {
+		in, out := &in.LastAnnouncedTime, &out.LastAnnouncedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileTimeline.
+func (in *ReconcileTimeline) DeepCopy() *ReconcileTimeline {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileTimeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacedInterface) DeepCopyInto(out *ReplacedInterface) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]IP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.UnderlayRoute.DeepCopyInto(&out.UnderlayRoute)
+	if in.VirtualIP != nil {
+		in, out := &in.VirtualIP, &out.VirtualIP
+		*out = new(ReplacedVirtualIP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NAT != nil {
+		in, out := &in.NAT, &out.NAT
+		*out = new(ReplacedNAT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoadBalancerTargets != nil {
+		in, out := &in.LoadBalancerTargets, &out.LoadBalancerTargets
+		*out = make([]ReplacedLoadBalancerTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacedInterface.
+func (in *ReplacedInterface) DeepCopy() *ReplacedInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacedInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacedLoadBalancerTarget) DeepCopyInto(out *ReplacedLoadBalancerTarget) {
+	*out = *in
+	in.Prefix.DeepCopyInto(&out.Prefix)
+	in.UnderlayRoute.DeepCopyInto(&out.UnderlayRoute)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacedLoadBalancerTarget.
+func (in *ReplacedLoadBalancerTarget) DeepCopy() *ReplacedLoadBalancerTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacedLoadBalancerTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacedNAT) DeepCopyInto(out *ReplacedNAT) {
+	*out = *in
+	in.IP.DeepCopyInto(&out.IP)
+	in.UnderlayRoute.DeepCopyInto(&out.UnderlayRoute)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacedNAT.
+func (in *ReplacedNAT) DeepCopy() *ReplacedNAT {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacedNAT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacedVirtualIP) DeepCopyInto(out *ReplacedVirtualIP) {
+	*out = *in
+	in.IP.DeepCopyInto(&out.IP)
+	in.UnderlayRoute.DeepCopyInto(&out.UnderlayRoute)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacedVirtualIP.
+func (in *ReplacedVirtualIP) DeepCopy() *ReplacedVirtualIP {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacedVirtualIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChain) DeepCopyInto(out *ServiceChain) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChain.
+func (in *ServiceChain) DeepCopy() *ServiceChain {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServiceChain) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChainHop) DeepCopyInto(out *ServiceChainHop) {
+	*out = *in
+	in.UnderlayAddress.DeepCopyInto(&out.UnderlayAddress)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChainHop.
+func (in *ServiceChainHop) DeepCopy() *ServiceChainHop {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChainHop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChainList) DeepCopyInto(out *ServiceChainList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ServiceChain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChainList.
+func (in *ServiceChainList) DeepCopy() *ServiceChainList {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChainList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServiceChainList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChainNodeStatus) DeepCopyInto(out *ServiceChainNodeStatus) {
+	*out = *in
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]ServiceChainRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChainNodeStatus.
+func (in *ServiceChainNodeStatus) DeepCopy() *ServiceChainNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChainNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChainRoute) DeepCopyInto(out *ServiceChainRoute) {
+	*out = *in
+	in.Prefix.DeepCopyInto(&out.Prefix)
+	in.NextHopAddress.DeepCopyInto(&out.NextHopAddress)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChainRoute.
+func (in *ServiceChainRoute) DeepCopy() *ServiceChainRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChainRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChainSpec) DeepCopyInto(out *ServiceChainSpec) {
+	*out = *in
+	out.NetworkRef = in.NetworkRef
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]IPPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hops != nil {
+		in, out := &in.Hops, &out.Hops
+		*out = make([]ServiceChainHop, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChainSpec.
+func (in *ServiceChainSpec) DeepCopy() *ServiceChainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceChainStatus) DeepCopyInto(out *ServiceChainStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]ServiceChainNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceChainStatus.
+func (in *ServiceChainStatus) DeepCopy() *ServiceChainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceChainStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,482 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package app sets up and runs metalnet.
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"github.com/ironcore-dev/metalnet/chaos"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/controllers"
+	"github.com/ironcore-dev/metalnet/crdcheck"
+	"github.com/ironcore-dev/metalnet/diagnostics"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/eventbus"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/standalone"
+	"github.com/ironcore-dev/metalnet/sysfs"
+	"github.com/ironcore-dev/metalnet/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	networkingv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	//+kubebuilder:scaffold:imports
+)
+
+const bluefieldSuffix = "-bluefield"
+
+// publicVNISubscriptionHolder holds the metalbond subscriptions of the public VNIs for the lifetime of metalnet.
+const publicVNISubscriptionHolder = "public VNI"
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	utilruntime.Must(networkingv1alpha1.AddToScheme(scheme))
+	//+kubebuilder:scaffold:scheme
+}
+
+// controllerHost runs the controllers, either a controller manager or, in standalone mode, a standalone runner.
+type controllerHost interface {
+	GetClient() client.Client
+	GetEventRecorderFor(name string) record.EventRecorder
+	Add(manager.Runnable) error
+	AddHealthzCheck(name string, check healthz.Checker) error
+	AddReadyzCheck(name string, check healthz.Checker) error
+	Start(ctx context.Context) error
+}
+
+// components are the parts of metalnet shared while setting up its controllers.
+type components struct {
+	host controllerHost
+	// mgr is the controller manager, nil in standalone mode.
+	mgr ctrl.Manager
+	// runner is the standalone runner, nil unless in standalone mode.
+	runner           *standalone.Runner
+	apiReader        client.Reader
+	allocationClient client.Client
+
+	// nodeName is the name of the node without the bluefield suffix.
+	nodeName          string
+	bluefieldDetected bool
+	vniRange          metalbond.VNIRange
+
+	restoreGate     *metalnetdpdk.RestoreGate
+	dpdkProtoClient dpdkproto.DPDKironcoreClient
+	dpdkClient      dpdkclient.Client
+	dpdkUUID        string
+	snapshotter     *metalnetdpdk.Snapshotter
+	// checkDPService returns an error if dpservice is down or restarted.
+	checkDPService func(ctx context.Context) error
+
+	metalnetCache     *internal.MetalnetCache
+	defaultRouterAddr *metalbond.DefaultRouterAddress
+	routing           *routing
+
+	deviceAllocator     netfns.DeviceAllocator
+	deviceClaims        *netfns.PodResourcesResolver
+	nodeUnderlayAddress netip.Addr
+
+	initialSync       *controllers.InitialSync
+	standby           *controllers.Standby
+	resync            *controllers.Resync
+	tombstones        *controllers.Tombstones
+	underlayValidator *controllers.UnderlayValidator
+	eventBus          *eventbus.Bus
+}
+
+// Run sets up metalnet with the given options and runs it until the context is done.
+func Run(ctx context.Context, opts Options) error {
+	logger := ctrl.Log
+	if opts.Metalbond.Debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	if opts.PublicVNIIPv6 == 0 {
+		opts.PublicVNIIPv6 = opts.PublicVNI
+	}
+	if opts.Devices.TAPDeviceMod {
+		opts.Devices.Allocator = deviceAllocatorNetdev
+	}
+
+	c := &components{
+		nodeName:          opts.NodeName,
+		defaultRouterAddr: &metalbond.DefaultRouterAddress{PublicVNI: uint32(opts.PublicVNI), PublicVNIIPv6: uint32(opts.PublicVNIIPv6)},
+	}
+	var err error
+	c.vniRange, err = metalbond.ParseVNIRange(opts.VNIRange)
+	if err != nil {
+		return err
+	}
+	if !c.vniRange.IsZero() && (c.vniRange.Contains(metalbond.VNI(opts.PublicVNI)) || c.vniRange.Contains(metalbond.VNI(opts.PublicVNIIPv6))) {
+		return fmt.Errorf("invalid VNI range: VNI range %s contains the public VNI", c.vniRange)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Endpoint:    opts.Tracing.Endpoint,
+		Insecure:    opts.Tracing.Insecure,
+		SampleRatio: opts.Tracing.SampleRatio,
+		NodeName:    opts.NodeName,
+		Version:     opts.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to shut down tracing")
+		}
+	}()
+
+	sysFS, err := sysfs.NewDefaultFS()
+	if err != nil {
+		return fmt.Errorf("error creating sysfs: %w", err)
+	}
+
+	c.metalnetCache = internal.NewBoundedMetalnetCache(&logger, opts.Cache)
+	// The snapshotter and the subscriptions are set up before the metrics endpoint is served.
+	metricsExtraHandlers := map[string]http.Handler{
+		"/debug/metalnet-cache": c.metalnetCache.Handler(),
+		"/debug/metalbond-subscriptions": http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			c.routing.subscriptions.Handler().ServeHTTP(w, req)
+		}),
+		"/debug/dpservice-snapshot": http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			c.snapshotter.ServeHTTP(w, req)
+		}),
+	}
+	if err := c.setUpHost(ctx, opts, metricsExtraHandlers); err != nil {
+		return err
+	}
+
+	newClaimStore, err := newDeviceClaimStoreFunc(opts.Devices.ClaimStore, filepath.Join(opts.MetalnetDir, "netfns", "claims"), c.allocationClient, opts.NodeName)
+	if err != nil {
+		return fmt.Errorf("unable to create device claim store %s: %w", opts.Devices.ClaimStore, err)
+	}
+	c.deviceAllocator, err = newDeviceAllocator(ctx, c.apiReader, opts.Devices, newClaimStore, sysFS, opts.NodeName)
+	if err != nil {
+		return fmt.Errorf("unable to create device allocator %s: %w", opts.Devices.Allocator, err)
+	}
+	if opts.Devices.PodResourcesSocket != "" {
+		podResources, err := netfns.DialPodResources(opts.Devices.PodResourcesSocket)
+		if err != nil {
+			return fmt.Errorf("unable to connect to pod resources api: %w", err)
+		}
+		c.deviceClaims = netfns.NewPodResourcesResolver(podResources, sysFS, pfToVfOffset)
+	}
+
+	if opts.Underlay.Address != "" || opts.Underlay.Interface != "" {
+		c.nodeUnderlayAddress, err = underlayAddressFor(opts.Underlay.Address, opts.Underlay.Interface)
+		if err != nil {
+			return fmt.Errorf("unable to determine underlay address: %w", err)
+		}
+	}
+
+	var chaosInjector *chaos.Injector
+	if opts.Chaos.Enabled() {
+		chaosInjector, err = chaos.NewInjector(opts.Chaos)
+		if err != nil {
+			return fmt.Errorf("invalid fault injection options: %w", err)
+		}
+		setupLog.Info("Injecting faults into the calls to dpservice and metalbond, do not use in production",
+			"ErrorRate", opts.Chaos.ErrorRate, "MaxDelay", opts.Chaos.MaxDelay, "Seed", opts.Chaos.Seed)
+	}
+
+	if opts.Diagnostics.DumpStacksOnSIGQUIT {
+		diagnostics.DumpStacksOnSignal(ctx, setupLog, os.Stderr, syscall.SIGQUIT)
+	}
+
+	// setup dpservice client
+	c.restoreGate = &metalnetdpdk.RestoreGate{}
+	conn, err := dialDPService(ctx, opts, c.restoreGate, chaosInjector)
+	if err != nil {
+		return fmt.Errorf("unable create dpdk client: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			setupLog.Error(err, "unable to close dpdk connection")
+		}
+	}()
+	// An idle connection is only closed on purpose if a maximum idle time is set.
+	go metalnetdpdk.WatchConnection(ctx, ctrl.Log.WithName("dpservice"), conn, opts.DPService.Keepalive.MaxIdle == 0)
+
+	c.dpdkProtoClient = dpdkproto.NewDPDKironcoreClient(conn)
+	c.dpdkClient = metalnetdpdk.NewCapacityClient(metalnetdpdk.NewListClient(dpdkclient.NewClient(c.dpdkProtoClient)))
+
+	c.routing, err = setUpMetalbond(ctx, &logger, opts, c.dpdkClient, c.metalnetCache, c.defaultRouterAddr,
+		c.vniRange, c.nodeUnderlayAddress, chaosInjector)
+	if err != nil {
+		return err
+	}
+
+	c.dpdkUUID, err = initializeDPService(ctx, c.dpdkProtoClient)
+	if err != nil {
+		return err
+	}
+	if err := checkDPServiceVersion(ctx, c.dpdkClient, opts); err != nil {
+		return err
+	}
+
+	// The standalone runner sets up the field indexes of its in-memory client itself.
+	if c.mgr != nil {
+		if err := setUpFieldIndexers(ctx, c.mgr); err != nil {
+			return err
+		}
+	}
+
+	if err := c.setUpDefaultRouterAddress(ctx, opts); err != nil {
+		return err
+	}
+
+	if strings.Contains(c.nodeName, bluefieldSuffix) {
+		c.bluefieldDetected = true
+		// In case string "-bluefield" in the node name, remove it
+		c.nodeName = strings.Replace(c.nodeName, bluefieldSuffix, "", 1)
+	}
+
+	if err := c.addRunnables(opts); err != nil {
+		return err
+	}
+	if err := c.setUpControllers(ctx, opts); err != nil {
+		return err
+	}
+	if err := c.addChecks(); err != nil {
+		return err
+	}
+
+	setupLog.Info("starting manager")
+	if err := c.host.Start(ctx); err != nil {
+		return fmt.Errorf("problem running manager: %w", err)
+	}
+	return nil
+}
+
+// setUpHost creates the controller manager or, in standalone mode, the standalone runner.
+func (c *components) setUpHost(ctx context.Context, opts Options, metricsExtraHandlers map[string]http.Handler) error {
+	if opts.Standalone.Dir != "" {
+		runner, err := standalone.NewRunner(scheme, standalone.Options{
+			Dir:                    opts.Standalone.Dir,
+			StatusDir:              opts.Standalone.StatusDir,
+			MetricsBindAddress:     opts.MetricsAddr,
+			HealthProbeBindAddress: opts.ProbeAddr,
+			ResyncPeriod:           opts.Reconcile.ResyncPeriod,
+			MetricsExtraHandlers:   metricsExtraHandlers,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create standalone runner: %w", err)
+		}
+		c.runner, c.host, c.apiReader = runner, runner, runner.GetClient()
+		return nil
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	if opts.Tracing.Endpoint != "" {
+		restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return otelhttp.NewTransport(rt)
+		})
+	}
+
+	readOnly, err := checkCRDs(ctx, restConfig, crdcheck.Mode(opts.CRDCompatibility), opts.CRDManifests, opts.Version)
+	if err != nil {
+		return fmt.Errorf("incompatible custom resource definitions: %w", err)
+	}
+	newClient := client.New
+	if readOnly {
+		setupLog.Info("Running in read-only compatibility mode, no objects are written until the custom resource definitions are upgraded")
+		newClient = func(config *rest.Config, options client.Options) (client.Client, error) {
+			c, err := client.New(config, options)
+			if err != nil {
+				return nil, err
+			}
+			return metalnetclient.NewReadOnlyClient(c), nil
+		}
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:    scheme,
+		NewClient: newClient,
+		Metrics: metricsserver.Options{
+			BindAddress:   opts.MetricsAddr,
+			ExtraHandlers: metricsExtraHandlers,
+		},
+		HealthProbeBindAddress: opts.ProbeAddr,
+		LeaderElection:         opts.LeaderElection.Enabled,
+		LeaderElectionID:       fmt.Sprintf("%s.metalnet.ironcore.dev", opts.NodeName),
+		LeaseDuration:          &opts.LeaderElection.LeaseDuration,
+		RenewDeadline:          &opts.LeaderElection.RenewDeadline,
+		RetryPeriod:            &opts.LeaderElection.RetryPeriod,
+		// The standby instance takes over right away when the active instance shuts down.
+		LeaderElectionReleaseOnCancel: true,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to start manager: %w", err)
+	}
+	c.mgr, c.host, c.apiReader = mgr, mgr, mgr.GetAPIReader()
+
+	if opts.Devices.ClaimStore == deviceClaimStoreAllocation {
+		c.allocationClient, err = client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("unable to create allocation client: %w", err)
+		}
+	}
+	return nil
+}
+
+// setUpFieldIndexers sets up the field indexes the controllers list objects by.
+func setUpFieldIndexers(ctx context.Context, mgr ctrl.Manager) error {
+	if err := metalnetclient.SetupNetworkInterfaceNetworkRefNameFieldIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("unable to set up field indexer %s: %w", metalnetclient.NetworkInterfaceNetworkRefNameField, err)
+	}
+	if err := metalnetclient.SetupNetworkInterfaceClusterNetworkRefNameFieldIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("unable to set up field indexer %s: %w", metalnetclient.NetworkInterfaceClusterNetworkRefNameField, err)
+	}
+	if err := metalnetclient.SetupNetworkInterfaceInternetGatewayRefNameFieldIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("unable to set up field indexer %s: %w", metalnetclient.NetworkInterfaceInternetGatewayRefNameField, err)
+	}
+	if err := metalnetclient.SetupLoadBalancerNetworkRefNameFieldIndexer(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("unable to set up field indexer %s: %w", metalnetclient.LoadBalancerNetworkRefNameField, err)
+	}
+	return nil
+}
+
+// setUpDefaultRouterAddress subscribes to the public VNIs and waits for the default router address to be
+// announced, falling back to the --router-address flag.
+func (c *components) setUpDefaultRouterAddress(ctx context.Context, opts Options) error {
+	if err := c.routing.subscriptions.Acquire(ctx, publicVNISubscriptionHolder, metalbond.VNI(opts.PublicVNI)); err != nil {
+		return fmt.Errorf("unable to subscribe to metalbond's public VNI: %w", err)
+	}
+	if opts.PublicVNIIPv6 != opts.PublicVNI {
+		if err := c.routing.subscriptions.Acquire(ctx, publicVNISubscriptionHolder, metalbond.VNI(opts.PublicVNIIPv6)); err != nil {
+			return fmt.Errorf("unable to subscribe to metalbond's public IPv6 VNI: %w", err)
+		}
+	}
+
+	// wait using backoff for default router address to be set by subscription
+	for i := 1; i <= 3; i++ {
+		if c.defaultRouterAddr.SetBySubsciption {
+			break
+		}
+		time.Sleep(time.Duration(100*i) * time.Millisecond)
+	}
+
+	c.defaultRouterAddr.RWMutex.Lock()
+	defer c.defaultRouterAddr.RWMutex.Unlock()
+	if c.defaultRouterAddr.SetBySubsciption {
+		if c.defaultRouterAddr.RouterAddress.Compare(netip.MustParseAddr(opts.RouterAddress.String())) != 0 {
+			setupLog.Info("--router-address flag's value does not match the default router address set by subscription, using the latter")
+		}
+	} else if opts.RouterAddress.Equal(net.IP{}) {
+		return fmt.Errorf("must specify --router-address or obtain default router address via metalbond subscription")
+	} else {
+		c.defaultRouterAddr.RouterAddress = netip.MustParseAddr(opts.RouterAddress.String())
+		setupLog.Info("Couldn't obtain default router address via metalbond subscription, using --router-address flag's value")
+	}
+	return nil
+}
+
+// addChecks adds the health and ready checks.
+func (c *components) addChecks() error {
+	var dpChecker healthz.Checker = func(_ *http.Request) error {
+		return c.checkDPService(context.Background())
+	}
+	if err := c.host.AddHealthzCheck("healthz", dpChecker); err != nil {
+		return fmt.Errorf("unable to set up health check: %w", err)
+	}
+	if err := c.host.AddReadyzCheck("readyz", c.standby.Checker(c.initialSync.Checker)); err != nil {
+		return fmt.Errorf("unable to set up ready check: %w", err)
+	}
+	if c.underlayValidator != nil {
+		if err := c.host.AddReadyzCheck("underlay", c.underlayValidator.Checker); err != nil {
+			return fmt.Errorf("unable to set up underlay ready check: %w", err)
+		}
+	}
+	if c.routing.headless != nil {
+		if err := c.host.AddReadyzCheck("metalbond", c.routing.headless.Checker); err != nil {
+			return fmt.Errorf("unable to set up metalbond ready check: %w", err)
+		}
+	}
+	return nil
+}
+
+// newWorkloadCluster creates the cluster the EndpointSlices of load balancer targets are read from.
+func newWorkloadCluster(kubeconfig string) (cluster.Cluster, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error loading workload kubeconfig: %w", err)
+	}
+	workloadCluster, err := cluster.New(restConfig, func(o *cluster.Options) {
+		o.Scheme = scheme
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating workload cluster: %w", err)
+	}
+	return workloadCluster, nil
+}
+
+// underlayAddressFor returns the underlay address of this node, either the given address or the global
+// unicast IPv6 address of the given interface.
+func underlayAddressFor(address, ifaceName string) (netip.Addr, error) {
+	var addr netip.Addr
+	if address != "" {
+		var err error
+		addr, err = netip.ParseAddr(address)
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("invalid underlay address: %w", err)
+		}
+		if !metalbond.IsUnderlayAddress(addr) {
+			return netip.Addr{}, fmt.Errorf("underlay address %s is no IPv6 address", addr)
+		}
+	} else {
+		iface, err := net.InterfaceByName(ifaceName)
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("error getting underlay interface %s: %w", ifaceName, err)
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("error listing addresses of underlay interface %s: %w", ifaceName, err)
+		}
+		for _, ifaceAddr := range ifaceAddrs {
+			ipNet, ok := ifaceAddr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if a, ok := netip.AddrFromSlice(ipNet.IP); ok && metalbond.IsUnderlayAddress(a) && a.IsGlobalUnicast() {
+				addr = a
+				break
+			}
+		}
+		if !addr.IsValid() {
+			return netip.Addr{}, fmt.Errorf("underlay interface %s has no global unicast IPv6 address", ifaceName)
+		}
+	}
+	return addr, nil
+}
@@ -0,0 +1,449 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"path/filepath"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	"github.com/ironcore-dev/metalnet/capture"
+	"github.com/ironcore-dev/metalnet/controllers"
+	"github.com/ironcore-dev/metalnet/diagnostics"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/eventbus"
+	"github.com/ironcore-dev/metalnet/introspection"
+	"github.com/ironcore-dev/metalnet/ipam"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/webhooks"
+	"golang.org/x/time/rate"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	networkingv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+// addRunnables adds the runnables besides the controllers to the host and registers the metrics collectors.
+func (c *components) addRunnables(opts Options) error {
+	c.initialSync = controllers.NewInitialSync(c.host.GetClient(), c.nodeName, opts.Reconcile.InitialSyncTimeout)
+	if c.mgr != nil && opts.LeaderElection.Enabled {
+		c.standby = controllers.NewStandby(c.mgr.Elected())
+		if err := c.host.Add(c.standby); err != nil {
+			return fmt.Errorf("unable to set up standby: %w", err)
+		}
+	}
+	if err := c.host.Add(c.initialSync); err != nil {
+		return fmt.Errorf("unable to set up initial sync: %w", err)
+	}
+
+	if c.nodeUnderlayAddress.IsValid() {
+		underlayPrefix, err := c.nodeUnderlayAddress.Prefix(opts.Underlay.PrefixLength)
+		if err != nil {
+			return fmt.Errorf("invalid underlay prefix length: %w", err)
+		}
+		setupLog.Info("Validating underlay routes of dpservice", "UnderlayPrefix", underlayPrefix)
+		c.underlayValidator = controllers.NewUnderlayValidator(c.dpdkClient, c.host.GetEventRecorderFor("underlay-validation"), c.nodeName,
+			controllers.UnderlayValidatorOptions{
+				Prefix:   underlayPrefix,
+				Interval: opts.Underlay.ValidationInterval,
+			})
+		if err := c.host.Add(c.underlayValidator); err != nil {
+			return fmt.Errorf("unable to set up underlay validation: %w", err)
+		}
+	}
+
+	if opts.EventBus.NATSURL != "" {
+		sink, err := eventbus.NewNATSSink(opts.EventBus.NATSURL)
+		if err != nil {
+			return fmt.Errorf("unable to create event bus: %w", err)
+		}
+		c.eventBus = eventbus.NewBus(sink, eventbus.Options{
+			NodeName:      c.nodeName,
+			SubjectPrefix: opts.EventBus.SubjectPrefix,
+		})
+		if err := c.host.Add(c.eventBus); err != nil {
+			return fmt.Errorf("unable to set up event bus: %w", err)
+		}
+	}
+
+	if opts.Diagnostics.PprofAddr != "" {
+		if err := c.host.Add(diagnostics.NewPprofServer(opts.Diagnostics.PprofAddr)); err != nil {
+			return fmt.Errorf("unable to set up pprof server: %w", err)
+		}
+	}
+	if opts.Diagnostics.RuntimeStatsInterval > 0 {
+		if err := c.host.Add(diagnostics.NewRuntimeStatsLogger(metrics.Registry, opts.Diagnostics.RuntimeStatsInterval)); err != nil {
+			return fmt.Errorf("unable to set up runtime stats logging: %w", err)
+		}
+	}
+
+	if opts.Diagnostics.IntrospectionSocket != "" {
+		if err := c.host.Add(introspection.NewServer(c.host.GetClient(), c.dpdkClient, introspection.Options{
+			SocketPath: opts.Diagnostics.IntrospectionSocket,
+			NodeName:   c.nodeName,
+		})); err != nil {
+			return fmt.Errorf("unable to set up introspection api: %w", err)
+		}
+	}
+
+	if opts.Reconcile.IsolationAuditInterval > 0 {
+		if err := c.host.Add(controllers.NewIsolationAudit(c.host.GetClient(), c.routing.client, opts.Reconcile.IsolationAuditInterval)); err != nil {
+			return fmt.Errorf("unable to set up isolation audit: %w", err)
+		}
+	}
+
+	metrics.Registry.MustRegister(controllers.NewNATPortCollector(c.host.GetClient(), c.dpdkClient, c.nodeName))
+	metrics.Registry.MustRegister(controllers.NewObjectCollector(c.host.GetClient(), c.dpdkClient, c.nodeName))
+
+	if len(opts.Metadata.Labels) > 0 || len(opts.Metadata.Annotations) > 0 {
+		interfaceMetadata, err := controllers.NewInterfaceMetadata(opts.Metadata.Labels, opts.Metadata.Annotations)
+		if err != nil {
+			return fmt.Errorf("invalid network interface metadata: %w", err)
+		}
+		metrics.Registry.MustRegister(controllers.NewNetworkInterfaceInfoCollector(c.host.GetClient(), c.nodeName, interfaceMetadata))
+	}
+
+	var err error
+	c.tombstones, err = controllers.NewTombstones(filepath.Join(opts.MetalnetDir, "tombstones"), c.host.GetClient(), opts.Reconcile.TombstonePurgeInterval)
+	if err != nil {
+		return fmt.Errorf("unable to set up tombstones: %w", err)
+	}
+	if err := c.host.Add(c.tombstones); err != nil {
+		return fmt.Errorf("unable to set up tombstones: %w", err)
+	}
+
+	// The standalone runner reconciles all objects periodically by itself.
+	if opts.Reconcile.ResyncPeriod > 0 && c.mgr != nil {
+		c.resync = controllers.NewResync(c.mgr.GetClient(), opts.Reconcile.ResyncPeriod)
+		if err := c.mgr.Add(c.resync); err != nil {
+			return fmt.Errorf("unable to set up resync: %w", err)
+		}
+	}
+	return nil
+}
+
+// setupController registers a reconciler with the standalone runner or, if not running standalone,
+// sets it up with the manager.
+func (c *components) setupController(name string, obj client.Object, r reconcile.Reconciler, setupWithManager func() error) error {
+	var err error
+	if c.runner != nil {
+		err = c.runner.Register(obj, r)
+	} else {
+		err = setupWithManager()
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create controller %s: %w", name, err)
+	}
+	return nil
+}
+
+// setUpControllers sets up the controllers, the webhooks and the dataplane state they share.
+func (c *components) setUpControllers(ctx context.Context, opts Options) error {
+	networkReconciler := &controllers.NetworkReconciler{
+		Client:            c.host.GetClient(),
+		Scheme:            scheme,
+		DPDK:              c.dpdkClient,
+		RouteUtil:         c.routing.routeUtil,
+		Subscriptions:     c.routing.subscriptions,
+		MetalnetCache:     c.metalnetCache,
+		MetalnetMBClient:  c.routing.client,
+		DefaultRouterAddr: c.defaultRouterAddr,
+		NodeName:          c.nodeName,
+		EnableIPv6Support: opts.EnableIPv6Support,
+		InitialSync:       c.initialSync,
+		Resync:            c.resync,
+
+		MaxConcurrentReconciles: opts.Reconcile.MaxConcurrentReconciles,
+	}
+	if err := c.setupController("Network", &networkingv1alpha1.Network{}, networkReconciler, func() error {
+		return networkReconciler.SetupWithManager(c.mgr, c.mgr.GetCache())
+	}); err != nil {
+		return err
+	}
+	if c.runner != nil {
+		// With a manager, the Network controller watches the ClusterNetworks itself.
+		if err := c.runner.Register(&networkingv1alpha1.ClusterNetwork{}, networkReconciler); err != nil {
+			return fmt.Errorf("unable to create controller ClusterNetwork: %w", err)
+		}
+	}
+	var reconcilerDPDK dpdkclient.Client = metalnetdpdk.NewIdempotentClient(metalnetdpdk.NewCapacityClient(metalnetdpdk.NewListClient(dpdkclient.NewClient(c.dpdkProtoClient))))
+	var dpdkCache *metalnetdpdk.CachingClient
+	if opts.DPService.CacheTTL > 0 {
+		dpdkCache = metalnetdpdk.NewCachingClient(reconcilerDPDK, metalnetdpdk.CachingClientOptions{TTL: opts.DPService.CacheTTL})
+		reconcilerDPDK = dpdkCache
+	}
+	c.snapshotter = metalnetdpdk.NewSnapshotter(c.dpdkClient, c.restoreGate, metalnetdpdk.SnapshotOptions{
+		LoadBalancerIDs: func(ctx context.Context) ([]string, error) {
+			lbList := &networkingv1alpha1.LoadBalancerList{}
+			if err := c.host.GetClient().List(ctx, lbList); err != nil {
+				return nil, err
+			}
+			nodeLabels, err := controllers.NodeLabels(ctx, c.host.GetClient(), c.nodeName)
+			if err != nil {
+				return nil, err
+			}
+			var ids []string
+			for i := range lbList.Items {
+				lb := &lbList.Items[i]
+				if controllers.IsLoadBalancerOnNode(lb, c.nodeName, nodeLabels) {
+					ids = append(ids, string(lb.UID))
+				}
+			}
+			return ids, nil
+		},
+		VNIs:         []uint32{uint32(opts.PublicVNI), uint32(opts.PublicVNIIPv6)},
+		AllowRestore: opts.DPService.EnableRestore,
+		OnRestored: func() {
+			if dpdkCache != nil {
+				dpdkCache.Invalidate()
+			}
+		},
+	})
+
+	var objectRateLimiter *controllers.ObjectRateLimiter
+	if opts.Reconcile.ObjectUpdateRate > 0 {
+		if opts.Reconcile.ObjectUpdateBurst < 1 {
+			return fmt.Errorf("invalid object update burst: burst %d is less than 1", opts.Reconcile.ObjectUpdateBurst)
+		}
+		objectRateLimiter = controllers.NewObjectRateLimiter(rate.Limit(opts.Reconcile.ObjectUpdateRate), opts.Reconcile.ObjectUpdateBurst)
+	}
+
+	var endpointSliceTargetReconciler *controllers.EndpointSliceTargetReconciler
+	if opts.WorkloadKubeconfig != "" {
+		if c.mgr == nil {
+			return fmt.Errorf("unable to set up endpoint slice targets: workload cluster requires kubernetes")
+		}
+		workloadCluster, err := newWorkloadCluster(opts.WorkloadKubeconfig)
+		if err != nil {
+			return fmt.Errorf("unable to set up workload cluster: %w", err)
+		}
+		if err := c.mgr.Add(workloadCluster); err != nil {
+			return fmt.Errorf("unable to add workload cluster: %w", err)
+		}
+		endpointSliceTargetReconciler = &controllers.EndpointSliceTargetReconciler{
+			Client:   c.mgr.GetClient(),
+			Workload: workloadCluster.GetClient(),
+			NodeName: c.nodeName,
+		}
+		if err := endpointSliceTargetReconciler.SetupWithManager(c.mgr, workloadCluster); err != nil {
+			return fmt.Errorf("unable to create controller EndpointSliceTarget: %w", err)
+		}
+	}
+
+	var capacityFeedback *controllers.CapacityFeedback
+	switch mode := controllers.CapacityFeedbackMode(opts.NodeFeedback.Capacity); mode {
+	case controllers.CapacityFeedbackNone:
+	case controllers.CapacityFeedbackCondition, controllers.CapacityFeedbackTaint:
+		if c.mgr == nil {
+			return fmt.Errorf("unable to set up capacity feedback: capacity node feedback requires kubernetes")
+		}
+		capacityFeedback = controllers.NewCapacityFeedback(c.mgr.GetClient(), c.nodeName, mode)
+		if err := c.mgr.Add(capacityFeedback); err != nil {
+			return fmt.Errorf("unable to set up capacity feedback: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid capacity node feedback: unknown mode %q", opts.NodeFeedback.Capacity)
+	}
+
+	dpserviceRestartDetector := metalnetdpdk.NewRestartDetector(c.dpdkProtoClient, c.dpdkUUID, 0, func() {
+		// Everything cached was programmed into the previous dpservice instance.
+		if dpdkCache != nil {
+			dpdkCache.Invalidate()
+		}
+	})
+	if err := c.host.Add(dpserviceRestartDetector); err != nil {
+		return fmt.Errorf("unable to set up dpservice restart detection: %w", err)
+	}
+	c.checkDPService = dpserviceRestartDetector.Check
+	switch mode := controllers.DataplaneFeedbackMode(opts.NodeFeedback.Dataplane); mode {
+	case controllers.DataplaneFeedbackNone:
+	case controllers.DataplaneFeedbackCondition, controllers.DataplaneFeedbackTaint:
+		if c.mgr == nil {
+			return fmt.Errorf("unable to set up dataplane feedback: dataplane node feedback requires kubernetes")
+		}
+		dataplaneFeedback := controllers.NewDataplaneFeedback(c.mgr.GetClient(), c.nodeName, mode, controllers.DataplaneFeedbackOptions{
+			DPService:          c.checkDPService,
+			MetalbondConnected: c.routing.peerManager.Connected,
+			GracePeriod:        opts.NodeFeedback.DataplaneGracePeriod,
+		})
+		if err := c.mgr.Add(dataplaneFeedback); err != nil {
+			return fmt.Errorf("unable to set up dataplane feedback: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid dataplane node feedback: unknown mode %q", opts.NodeFeedback.Dataplane)
+	}
+
+	var networkInterfaceIPAM ipam.IPAM
+	switch opts.IPAM.Mode {
+	case "static":
+		networkInterfaceIPAM = ipam.Static{}
+	case "pool":
+		networkInterfaceIPAM = ipam.NewPool(c.host.GetClient())
+	case "webhook":
+		if opts.IPAM.WebhookURL == "" {
+			return fmt.Errorf("invalid ipam: --ipam=webhook requires --ipam-webhook-url")
+		}
+		networkInterfaceIPAM = ipam.NewWebhook(opts.IPAM.WebhookURL, &http.Client{Timeout: opts.IPAM.WebhookTimeout})
+	default:
+		return fmt.Errorf("invalid ipam: unknown ipam %q", opts.IPAM.Mode)
+	}
+
+	networkInterfaceReconciler := &controllers.NetworkInterfaceReconciler{
+		Client:                      c.host.GetClient(),
+		EventRecorder:               c.host.GetEventRecorderFor("networkinterface"),
+		Scheme:                      scheme,
+		DPDK:                        reconcilerDPDK,
+		RouteUtil:                   c.routing.routeUtil,
+		AliasPrefixAnnouncer:        metalbond.NewAliasPrefixAnnouncer(c.routing.routeUtil),
+		DeviceAllocator:             c.deviceAllocator,
+		DeviceClaims:                c.deviceClaims,
+		HostInterfaceDevice:         opts.Devices.HostInterfaceDevice,
+		NodeName:                    c.nodeName,
+		PublicVNI:                   opts.PublicVNI,
+		PublicVNIIPv6:               opts.PublicVNIIPv6,
+		EnableIPv6Support:           opts.EnableIPv6Support,
+		BluefieldDetected:           c.bluefieldDetected,
+		BluefieldHostDefaultBusAddr: bluefieldHostDefaultBusAddr,
+		RateLimiter:                 objectRateLimiter,
+		InitialSync:                 c.initialSync,
+		Resync:                      c.resync,
+		VirtualIPHandoverTimeout:    opts.Reconcile.VirtualIPHandoverTimeout,
+		EndpointSliceTargets:        endpointSliceTargetReconciler,
+		CapacityFeedback:            capacityFeedback,
+		EventBus:                    c.eventBus,
+		Convergence:                 controllers.NewConvergenceTracker("NetworkInterface"),
+
+		MaxConcurrentReconciles: opts.Reconcile.MaxConcurrentReconciles,
+		Tombstones:              c.tombstones,
+		IPAM:                    networkInterfaceIPAM,
+	}
+	c.tombstones.Register(networkInterfaceReconciler.TombstoneKind())
+	if err := c.setupController("NetworkInterface", &networkingv1alpha1.NetworkInterface{}, networkInterfaceReconciler, func() error {
+		return networkInterfaceReconciler.SetupWithManager(c.mgr, c.mgr.GetCache())
+	}); err != nil {
+		return err
+	}
+
+	loadBalancerReconciler := &controllers.LoadBalancerReconciler{
+		Client:            c.host.GetClient(),
+		Scheme:            scheme,
+		EventRecorder:     c.host.GetEventRecorderFor("loadbalancer"),
+		DPDK:              reconcilerDPDK,
+		RouteUtil:         c.routing.routeUtil,
+		MetalnetCache:     c.metalnetCache,
+		NodeName:          c.nodeName,
+		PublicVNI:         opts.PublicVNI,
+		PublicVNIIPv6:     opts.PublicVNIIPv6,
+		EnableIPv6Support: opts.EnableIPv6Support,
+		RateLimiter:       objectRateLimiter,
+		InitialSync:       c.initialSync,
+		Resync:            c.resync,
+		Convergence:       controllers.NewConvergenceTracker("LoadBalancer"),
+		Tombstones:        c.tombstones,
+
+		MaxConcurrentReconciles: opts.Reconcile.MaxConcurrentReconciles,
+	}
+	c.tombstones.Register(loadBalancerReconciler.TombstoneKind())
+	if err := c.setupController("LoadBalancer", &networkingv1alpha1.LoadBalancer{}, loadBalancerReconciler, func() error {
+		return loadBalancerReconciler.SetupWithManager(c.mgr, c.mgr.GetCache())
+	}); err != nil {
+		return err
+	}
+
+	internetGatewayReconciler := &controllers.InternetGatewayReconciler{
+		Client: c.host.GetClient(),
+		Scheme: scheme,
+	}
+	if err := c.setupController("InternetGateway", &networkingv1alpha1.InternetGateway{}, internetGatewayReconciler, func() error {
+		return internetGatewayReconciler.SetupWithManager(c.mgr)
+	}); err != nil {
+		return err
+	}
+	serviceChainReconciler := &controllers.ServiceChainReconciler{
+		Client:        c.host.GetClient(),
+		EventRecorder: c.host.GetEventRecorderFor("servicechain"),
+		Scheme:        scheme,
+		DPDK:          reconcilerDPDK,
+		MetalnetCache: c.metalnetCache,
+		NodeName:      c.nodeName,
+	}
+	if err := c.setupController("ServiceChain", &networkingv1alpha1.ServiceChain{}, serviceChainReconciler, func() error {
+		return serviceChainReconciler.SetupWithManager(c.mgr)
+	}); err != nil {
+		return err
+	}
+	// In standalone mode, the network interfaces stamped out from templates would be removed with the next
+	// change of the spec directory, so templates are only supported with Kubernetes.
+	if c.mgr != nil {
+		if err := (&controllers.NetworkInterfaceTemplateReconciler{
+			Client:        c.mgr.GetClient(),
+			EventRecorder: c.mgr.GetEventRecorderFor("networkinterfacetemplate"),
+			Scheme:        scheme,
+			NodeName:      c.nodeName,
+		}).SetupWithManager(c.mgr); err != nil {
+			return fmt.Errorf("unable to create controller NetworkInterfaceTemplate: %w", err)
+		}
+	}
+	var captures *capture.Manager
+	if opts.Capture.SinkAddress != "" {
+		sinkAddress, err := netip.ParseAddr(opts.Capture.SinkAddress)
+		if err == nil && !metalbond.IsUnderlayAddress(sinkAddress) {
+			err = fmt.Errorf("%s is no IPv6 underlay address", sinkAddress)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid capture sink address: %w", err)
+		}
+		captureDir := opts.Capture.Dir
+		if captureDir == "" {
+			captureDir = filepath.Join(opts.MetalnetDir, "captures")
+		}
+		captures = capture.NewManager(ctrl.Log.WithName("capture"), c.dpdkClient, capture.Options{
+			Dir:         captureDir,
+			SinkAddress: sinkAddress,
+			Port:        opts.Capture.UDPPort,
+			MaxDuration: opts.Capture.MaxDuration,
+		})
+		if err := captures.StopStale(ctx); err != nil {
+			return fmt.Errorf("unable to stop stale packet capture: %w", err)
+		}
+	}
+	packetCaptureReconciler := &controllers.PacketCaptureReconciler{
+		Client:   c.host.GetClient(),
+		DPDK:     reconcilerDPDK,
+		Captures: captures,
+		NodeName: c.nodeName,
+	}
+	if err := c.setupController("PacketCapture", &networkingv1alpha1.NetworkInterface{}, packetCaptureReconciler, func() error {
+		return packetCaptureReconciler.SetupWithManager(c.mgr)
+	}); err != nil {
+		return err
+	}
+	// Maintenance is requested via the Node object, which only exists with Kubernetes.
+	// In standalone mode, only the --maintenance flag applies.
+	if c.mgr != nil {
+		if err := (&controllers.MaintenanceReconciler{
+			Client:    c.mgr.GetClient(),
+			RouteUtil: c.routing.maintenance,
+			NodeName:  c.nodeName,
+			Forced:    opts.Maintenance,
+		}).SetupWithManager(c.mgr); err != nil {
+			return fmt.Errorf("unable to create controller Maintenance: %w", err)
+		}
+	}
+	if opts.Webhooks.Enabled && c.mgr != nil {
+		if err := webhooks.SetupWithManager(c.mgr, webhooks.Options{
+			BlockAttachedNetworkInterfaceDeletion: opts.Webhooks.BlockAttachedNetworkInterfaceDeletion,
+			VNIRange:                              c.vniRange,
+		}); err != nil {
+			return fmt.Errorf("unable to create webhooks: %w", err)
+		}
+	}
+	//+kubebuilder:scaffold:builder
+	return nil
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/ironcore-dev/metalnet/crdcheck"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkCRDs compares the installed CustomResourceDefinitions with the given ones metalnet was built with and
+// handles incompatibilities according to the given mode. It returns whether metalnet has to run read-only.
+func checkCRDs(ctx context.Context, restConfig *rest.Config, mode crdcheck.Mode, manifests fs.FS, version string) (bool, error) {
+	switch mode {
+	case crdcheck.ModeWarn, crdcheck.ModeStrict, crdcheck.ModeReadOnly:
+	default:
+		return false, fmt.Errorf("unknown crd compatibility mode %q", mode)
+	}
+
+	expected, err := crdcheck.LoadCRDs(manifests, "config/crd/bases/*.yaml")
+	if err != nil {
+		return false, fmt.Errorf("error loading custom resource definitions: %w", err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return false, fmt.Errorf("error creating discovery client: %w", err)
+	}
+	crdScheme := runtime.NewScheme()
+	utilruntime.Must(apiextensionsv1.AddToScheme(crdScheme))
+	crdReader, err := client.New(restConfig, client.Options{Scheme: crdScheme})
+	if err != nil {
+		return false, fmt.Errorf("error creating client: %w", err)
+	}
+
+	checker := &crdcheck.Checker{Discovery: discoveryClient, Reader: crdReader}
+	incompatibilities, err := checker.Check(ctx, expected)
+	if err != nil {
+		if mode == crdcheck.ModeStrict {
+			return false, fmt.Errorf("error checking custom resource definitions: %w", err)
+		}
+		setupLog.Error(err, "unable to check custom resource definitions")
+		return false, nil
+	}
+	if len(incompatibilities) == 0 {
+		return false, nil
+	}
+
+	descriptions := make([]string, len(incompatibilities))
+	for i, incompatibility := range incompatibilities {
+		descriptions[i] = incompatibility.String()
+	}
+	switch mode {
+	case crdcheck.ModeStrict:
+		return false, fmt.Errorf("the installed custom resource definitions do not match metalnet %s, upgrade them first: %s",
+			version, strings.Join(descriptions, "; "))
+	case crdcheck.ModeReadOnly:
+		setupLog.Info("Installed custom resource definitions do not match this version", "Incompatibilities", descriptions)
+		return true, nil
+	default:
+		setupLog.Info("Installed custom resource definitions do not match this version, fields may be dropped",
+			"Incompatibilities", descriptions)
+		return false, nil
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/sysfs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultPFBaseAddr           = "0000:03:00.0"
+	bluefieldHostDefaultBusAddr = "06"
+	numOfVFs                    = 126
+	pfToVfOffset                = 3
+)
+
+func newDeviceAllocator(
+	ctx context.Context,
+	c client.Reader,
+	opts DeviceOptions,
+	newClaimStore func(isTAPStore bool) (netfns.ClaimStore, error),
+	sysFS sysfs.FS,
+	nodeName string,
+) (netfns.DeviceAllocator, error) {
+	claimStore, err := newClaimStore(opts.Allocator != deviceAllocatorPCI)
+	if err != nil {
+		return nil, fmt.Errorf("error creating claim store: %w", err)
+	}
+
+	switch opts.Allocator {
+	case deviceAllocatorPCI:
+		initAvailable, err := netfns.CollectVirtualFunctions(sysFS)
+		if err != nil {
+			return nil, fmt.Errorf("error collecting virtual functions: %w", err)
+		}
+		if len(initAvailable) == 0 {
+			initAvailable, err = netfns.GenerateVirtualFunctions(opts.PFBaseAddr, numOfVFs, pfToVfOffset)
+			if err != nil {
+				return nil, fmt.Errorf("error generating virtual functions of pf address %s: %w", opts.PFBaseAddr, err)
+			}
+		}
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		if err != nil {
+			return nil, fmt.Errorf("error creating netfns manager: %w", err)
+		}
+		return netfns.NewPCIAllocator(netFnsManager, sysFS, pfToVfOffset), nil
+	case deviceAllocatorNetdev:
+		initAvailable, err := netfns.CollectTAPFunctions(opts.NetdevNames)
+		if err != nil {
+			return nil, fmt.Errorf("error collecting netdevs: %w", err)
+		}
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		if err != nil {
+			return nil, fmt.Errorf("error creating netfns manager: %w", err)
+		}
+		return netfns.NewNetdevAllocator(netFnsManager), nil
+	case deviceAllocatorConfigMap:
+		namespace, name, ok := strings.Cut(opts.ConfigMap, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("invalid device config map %q, expected <namespace>/<name>", opts.ConfigMap)
+		}
+		devices, err := netfns.LoadConfigMapDevices(ctx, c, client.ObjectKey{Namespace: namespace, Name: name}, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		initAvailable, err := netfns.CollectTAPFunctions(netfns.DeviceNames(devices))
+		if err != nil {
+			return nil, fmt.Errorf("error collecting configured devices: %w", err)
+		}
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		if err != nil {
+			return nil, fmt.Errorf("error creating netfns manager: %w", err)
+		}
+		return netfns.NewStaticAllocator(netFnsManager, sysFS, devices), nil
+	default:
+		return nil, fmt.Errorf("unknown device allocator %q", opts.Allocator)
+	}
+}
+
+// newDeviceClaimStoreFunc returns a function creating the claim store of the given type. Claims of the file
+// claim store are moved to the allocation claim store.
+func newDeviceClaimStoreFunc(
+	storeType string,
+	claimsDir string,
+	allocationClient client.Client,
+	nodeName string,
+) (func(isTAPStore bool) (netfns.ClaimStore, error), error) {
+	switch storeType {
+	case deviceClaimStoreFile:
+		return func(isTAPStore bool) (netfns.ClaimStore, error) {
+			return netfns.NewFileClaimStore(claimsDir, isTAPStore)
+		}, nil
+	case deviceClaimStoreAllocation:
+		if allocationClient == nil {
+			return nil, fmt.Errorf("device claim store %q requires kubernetes", storeType)
+		}
+		return func(isTAPStore bool) (netfns.ClaimStore, error) {
+			fileStore, err := netfns.NewFileClaimStore(claimsDir, isTAPStore)
+			if err != nil {
+				return nil, err
+			}
+			store := netfns.NewAllocationClaimStore(allocationClient, nodeName, netfns.AllocationPool, isTAPStore)
+			if err := netfns.MigrateClaims(fileStore, store); err != nil {
+				return nil, fmt.Errorf("error migrating file claims: %w", err)
+			}
+			// Stale file claims would double-assign devices when switching back to the file claim store.
+			if err := fileStore.DeleteAll(); err != nil {
+				return nil, fmt.Errorf("error deleting migrated file claims: %w", err)
+			}
+			return store, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown device claim store %q", storeType)
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"github.com/ironcore-dev/metalnet/chaos"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+const dpserviceIPv6SupportVersionStr = "v0.3.1"
+
+// dialDPService connects to dpservice, retrying while it is not reachable yet. The calls of the connection are
+// held back by the given gate during restores of snapshots.
+func dialDPService(ctx context.Context, opts Options, restoreGate *metalnetdpdk.RestoreGate, chaosInjector *chaos.Injector) (*grpc.ClientConn, error) {
+	dialOpts := []grpc.DialOption{grpc.WithChainUnaryInterceptor(restoreGate.UnaryClientInterceptor())}
+	dialOpts = append(dialOpts, metalnetdpdk.KeepaliveDialOptions(opts.DPService.Keepalive)...)
+	dialOpts = append(dialOpts, metalnetdpdk.MaxMessageSizeDialOption(opts.DPService.MaxMessageSize))
+	if opts.Tracing.Endpoint != "" {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+	if chaosInjector != nil {
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(metalnetdpdk.ChaosUnaryClientInterceptor(chaosInjector)))
+	}
+	return metalnetdpdk.DialWithRetry(ctx, opts.DPService.Address, metalnetdpdk.DialRetryOptions{
+		Timeout:  opts.DPService.DialTimeout,
+		Retries:  opts.DPService.DialRetries,
+		Interval: opts.DPService.DialRetryInterval,
+		OnRetry: func(attempt int, err error) {
+			setupLog.Info("Waiting for dpservice", "Address", opts.DPService.Address, "Attempt", attempt, "Error", err.Error())
+		},
+	}, dialOpts...)
+}
+
+// initializeDPService initializes dpservice unless it already is and returns the id of its instance.
+func initializeDPService(ctx context.Context, c dpdkproto.DPDKironcoreClient) (string, error) {
+	res, err := c.CheckInitialized(ctx, &dpdkproto.CheckInitializedRequest{})
+	if err != nil {
+		if _, err := c.Initialize(ctx, &dpdkproto.InitializeRequest{}); err != nil {
+			return "", fmt.Errorf("dp-service can not be initialized: %w", err)
+		}
+
+		res, err = c.CheckInitialized(ctx, &dpdkproto.CheckInitializedRequest{})
+		if err != nil {
+			return "", fmt.Errorf("dp-service down: %w", err)
+		}
+	}
+	return res.GetUuid(), nil
+}
+
+// checkDPServiceVersion logs the versions of dpservice and metalnet and checks that dpservice supports IPv6
+// if IPv6 support is enabled.
+func checkDPServiceVersion(ctx context.Context, c dpdkclient.Client, opts Options) error {
+	hostName, _ := os.Hostname()
+	protoVersion, err := c.GetVersion(ctx, &dpdk.Version{
+		TypeMeta: dpdk.TypeMeta{Kind: dpdk.VersionKind},
+		VersionMeta: dpdk.VersionMeta{
+			ClientName:    fmt.Sprintf("metalnet-%s", hostName),
+			ClientVersion: opts.Version,
+		},
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to get proto version")
+	}
+	setupLog.Info("protobuf versions",
+		"dpserviceProtocol", protoVersion.Spec.ServiceProtocol,
+		"dpserviceVersion", protoVersion.Spec.ServiceVersion,
+		"metalnetName", protoVersion.ClientName,
+		"metalnetProtocol", protoVersion.ClientProtocol,
+		"metalnetVersion", protoVersion.ClientVersion)
+
+	if !opts.EnableIPv6Support {
+		return nil
+	}
+	parsedIPv6SupportVersionStr, err := version.NewVersion(strings.TrimPrefix(dpserviceIPv6SupportVersionStr, "v"))
+	if err != nil {
+		return fmt.Errorf("error parsing defined dpservice version: %w", err)
+	}
+	// Remove 'v' prefix and split at '-' to ignore build metadata if present
+	verParts := strings.Split(strings.TrimPrefix(protoVersion.Spec.ServiceVersion, "v"), "-")
+	ver, err := version.NewVersion(verParts[0])
+	if err != nil {
+		return fmt.Errorf("unable to parse received version string %s: %w", protoVersion.Spec.ServiceVersion, err)
+	}
+	if ver.LessThan(parsedIPv6SupportVersionStr) {
+		return fmt.Errorf("dpservice %s doesnt support IPv6 and metalnet ipv6 support is enabled", protoVersion.Spec.ServiceVersion)
+	}
+	return nil
+}
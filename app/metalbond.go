@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalnet/chaos"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+)
+
+// routing holds the metalbond components the routes are exchanged with.
+type routing struct {
+	// client programs the received routes into dpservice.
+	client      *metalbond.MetalnetClient
+	peerManager *metalbond.PeerManager
+	// routeUtil announces the routes of this node.
+	routeUtil     metalbond.RouteUtil
+	maintenance   *metalbond.MaintenanceRouteUtil
+	headless      *metalbond.HeadlessRouteUtil
+	subscriptions *metalbond.Subscriptions
+}
+
+// setUpMetalbond creates the metalbond instance of this node, connects it to the metalbond peers and wraps the
+// programming of received routes and the announcement of routes as configured by the options.
+func setUpMetalbond(
+	ctx context.Context,
+	logger *logr.Logger,
+	opts Options,
+	dpdkClient dpdkclient.Client,
+	metalnetCache *internal.MetalnetCache,
+	defaultRouterAddr *metalbond.DefaultRouterAddress,
+	vniRange metalbond.VNIRange,
+	nodeUnderlayAddress netip.Addr,
+	chaosInjector *chaos.Injector,
+) (*routing, error) {
+	var preferredNetwork netip.Prefix
+	if len(opts.PreferNetwork) > 0 {
+		var err error
+		preferredNetwork, err = netip.ParsePrefix(opts.PreferNetwork)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefer network address %s: %w", opts.PreferNetwork, err)
+		}
+		preferredNetwork = preferredNetwork.Masked()
+	}
+
+	metalnetMBClient := metalbond.NewMetalnetClient(logger, dpdkClient, metalnetCache, defaultRouterAddr,
+		metalbond.ClientOptions{
+			IPv4Only:         !opts.EnableIPv6Support,
+			PreferredNetwork: preferredNetwork,
+		})
+	routeClient, err := newRouteClient(ctx, logger, opts, metalnetMBClient, nodeUnderlayAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	routeIngester := metalbond.NewRouteIngester(logger, routeClient, metalbond.RouteIngesterOptions{
+		Workers: opts.Metalbond.RouteWorkers,
+	})
+	go func() {
+		if err := routeIngester.Start(ctx); err != nil {
+			setupLog.Error(err, "problem running metalbond route ingester")
+			os.Exit(1)
+		}
+	}()
+
+	config := mb.Config{
+		KeepaliveInterval: 3,
+	}
+	mbInstance := mb.NewMetalBond(config, routeIngester)
+	var peerLocalAddress netip.Addr
+	if opts.Metalbond.LocalAddress != "" {
+		peerLocalAddress, err = netip.ParseAddr(opts.Metalbond.LocalAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metalbond local address: %w", err)
+		}
+	}
+	peerManager := metalbond.NewPeerManager(logger, mbInstance, metalbond.PeerManagerOptions{
+		SyncPeriod:   opts.Metalbond.PeerSyncPeriod,
+		LocalAddress: peerLocalAddress,
+	})
+
+	r := &routing{client: metalnetMBClient, peerManager: peerManager}
+	if err := r.setUpRouteUtil(ctx, logger, opts, mbInstance, vniRange, chaosInjector); err != nil {
+		return nil, err
+	}
+
+	peers := opts.Metalbond.Peers
+	if opts.Metalbond.PeersFile != "" {
+		peers, err = metalbond.LoadPeersFile(opts.Metalbond.PeersFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load metalbond peers of %s: %w", opts.Metalbond.PeersFile, err)
+		}
+	}
+	if err := peerManager.SetPeers(ctx, peers); err != nil {
+		return nil, fmt.Errorf("failed to add metalbond peers %v: %w", peers, err)
+	}
+	if opts.Metalbond.PeersFile != "" {
+		go func() {
+			if err := peerManager.WatchPeersFile(ctx, opts.Metalbond.PeersFile); err != nil {
+				setupLog.Error(err, "problem watching metalbond peers file")
+			}
+		}()
+	}
+
+	metalnetMBClient.SetMetalBond(mbInstance)
+	return r, nil
+}
+
+// newRouteClient wraps the programming of the received routes into dpservice.
+func newRouteClient(
+	ctx context.Context,
+	logger *logr.Logger,
+	opts Options,
+	metalnetMBClient *metalbond.MetalnetClient,
+	nodeUnderlayAddress netip.Addr,
+) (mb.Client, error) {
+	// Destinations announced by several nodes, e.g. the ips of active-active load balancers, are programmed
+	// with a single next hop, as dpservice holds a single route per destination.
+	var routeClient mb.Client = metalbond.NewAnycastRouteClient(logger, metalnetMBClient, opts.NodeName)
+	// Removing the routes of an unreachable next hop lets another next hop of the same destination take over.
+	if opts.Metalbond.NextHopProbeInterval > 0 {
+		if !nodeUnderlayAddress.IsValid() {
+			return nil, fmt.Errorf("--metalbond-next-hop-probe-interval requires --underlay-address or --underlay-interface")
+		}
+		nextHopTrackingClient := metalbond.NewNextHopTrackingClient(logger, routeClient, metalbond.NextHopTrackingOptions{
+			UnderlayAddress:      nodeUnderlayAddress,
+			UnderlayPrefixLength: opts.Underlay.PrefixLength,
+			Interval:             opts.Metalbond.NextHopProbeInterval,
+			FailureThreshold:     opts.Metalbond.NextHopProbeFailureThreshold,
+			Port:                 opts.Metalbond.NextHopProbePort,
+		})
+		go func() {
+			_ = nextHopTrackingClient.Start(ctx)
+		}()
+		routeClient = nextHopTrackingClient
+	}
+	if opts.Metalbond.FlapDampingThreshold > 0 {
+		routeClient = metalbond.NewFlapDampingClient(logger, routeClient, metalbond.FlapDampingOptions{
+			Threshold: opts.Metalbond.FlapDampingThreshold,
+			Window:    opts.Metalbond.FlapDampingWindow,
+			Penalty:   opts.Metalbond.FlapDampingPenalty,
+		})
+	}
+
+	// The underlay is IPv6 only, so routes with other next hops are rejected even without allowed ranges.
+	allowedUnderlayPrefixes := make([]netip.Prefix, len(opts.Metalbond.AllowedUnderlayCIDRs))
+	for i, cidr := range opts.Metalbond.AllowedUnderlayCIDRs {
+		var err error
+		allowedUnderlayPrefixes[i], err = netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed underlay cidr: %w", err)
+		}
+	}
+	routeClient = metalbond.NewNextHopValidationClient(logger, routeClient, allowedUnderlayPrefixes)
+
+	if opts.Metalbond.ClusterID != 0 {
+		peerClusters := make([]uint16, len(opts.Metalbond.PeerClusterIDs))
+		for i, id := range opts.Metalbond.PeerClusterIDs {
+			if id == 0 || id > math.MaxUint16 {
+				return nil, fmt.Errorf("peer cluster id %d out of range", id)
+			}
+			peerClusters[i] = uint16(id)
+		}
+		routeClient = metalbond.NewClusterRouteClient(logger, routeClient, metalbond.ClusterRouteOptions{
+			ClusterID:      opts.Metalbond.ClusterID,
+			PeerClusterIDs: peerClusters,
+		})
+	}
+	return routeClient, nil
+}
+
+// setUpRouteUtil wraps the announcement of the routes of this node via the given metalbond instance.
+func (r *routing) setUpRouteUtil(
+	ctx context.Context,
+	logger *logr.Logger,
+	opts Options,
+	mbInstance *mb.MetalBond,
+	vniRange metalbond.VNIRange,
+	chaosInjector *chaos.Injector,
+) error {
+	var routeUtil metalbond.RouteUtil = metalbond.NewMBRouteUtil(mbInstance)
+	convergenceRouteUtil := metalbond.NewConvergenceRouteUtil(routeUtil)
+	r.client.SetConvergenceRouteUtil(convergenceRouteUtil)
+	routeUtil = convergenceRouteUtil
+	if opts.Metalbond.Headless {
+		r.headless = metalbond.NewHeadlessRouteUtil(logger, routeUtil, r.peerManager.Connected)
+		routeUtil = r.headless
+		go func() {
+			_ = r.headless.Start(ctx)
+		}()
+	}
+	if chaosInjector != nil {
+		routeUtil = metalbond.NewChaosRouteUtil(routeUtil, chaosInjector)
+	}
+	if opts.Metalbond.ClusterID != 0 {
+		routeUtil = metalbond.NewClusterRouteUtil(routeUtil, opts.Metalbond.ClusterID)
+	}
+	var announcementPolicy *metalbond.AnnouncementPolicy
+	if opts.Metalbond.AnnouncementPolicyFile != "" {
+		var err error
+		announcementPolicy, err = metalbond.LoadAnnouncementPolicy(opts.Metalbond.AnnouncementPolicyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load announcement policy of %s: %w", opts.Metalbond.AnnouncementPolicyFile, err)
+		}
+		// Check the routes actually announced, including the aggregates.
+		routeUtil = metalbond.NewPolicyRouteUtil(routeUtil, announcementPolicy)
+	}
+	if len(opts.Metalbond.AggregateRoutesVNIs) > 0 {
+		vnis := make([]metalbond.VNI, len(opts.Metalbond.AggregateRoutesVNIs))
+		for i, vni := range opts.Metalbond.AggregateRoutesVNIs {
+			vnis[i] = metalbond.VNI(vni)
+		}
+		routeUtil = metalbond.NewAggregatingRouteUtil(routeUtil, vnis)
+		if announcementPolicy != nil {
+			// Deny routes before aggregating them, so that no aggregate covers a denied route.
+			routeUtil = metalbond.NewPolicyRouteUtil(routeUtil, announcementPolicy)
+		}
+	}
+	if !vniRange.IsZero() {
+		routeUtil = metalbond.NewVNIRangeRouteUtil(routeUtil, vniRange,
+			metalbond.VNI(opts.PublicVNI), metalbond.VNI(opts.PublicVNIIPv6))
+	}
+	r.maintenance = metalbond.NewMaintenanceRouteUtil(routeUtil)
+	if err := r.maintenance.SetInMaintenance(ctx, opts.Maintenance); err != nil {
+		return fmt.Errorf("unable to enter maintenance: %w", err)
+	}
+	routeUtil = r.maintenance
+	if opts.Tracing.Endpoint != "" {
+		routeUtil = metalbond.NewTracingRouteUtil(routeUtil)
+	}
+	r.routeUtil = routeUtil
+	r.subscriptions = metalbond.NewSubscriptions(routeUtil)
+	return nil
+}
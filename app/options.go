@@ -0,0 +1,461 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ironcore-dev/metalnet/chaos"
+	"github.com/ironcore-dev/metalnet/controllers"
+	"github.com/ironcore-dev/metalnet/crdcheck"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/eventbus"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/netfns"
+	flag "github.com/spf13/pflag"
+
+	networkingv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+const (
+	deviceAllocatorPCI       = "pci"
+	deviceAllocatorNetdev    = "netdev"
+	deviceAllocatorConfigMap = "configmap"
+)
+
+const (
+	deviceClaimStoreFile       = "file"
+	deviceClaimStoreAllocation = "allocation"
+)
+
+// Options are the options metalnet is run with.
+type Options struct {
+	// Version is the version of metalnet reported to dpservice and in traces.
+	Version string
+	// CRDManifests holds the CustomResourceDefinitions metalnet was built with in config/crd/bases, see crdcheck.
+	CRDManifests fs.FS
+
+	MetricsAddr string
+	ProbeAddr   string
+	NodeName    string
+	MetalnetDir string
+
+	EnableIPv6Support bool
+	PublicVNI         int
+	PublicVNIIPv6     int
+	VNIRange          string
+	RouterAddress     net.IP
+	PreferNetwork     string
+
+	Maintenance        bool
+	WorkloadKubeconfig string
+	CRDCompatibility   string
+
+	LeaderElection LeaderElectionOptions
+	Standalone     StandaloneOptions
+	DPService      DPServiceOptions
+	Metalbond      MetalbondOptions
+	Devices        DeviceOptions
+	Underlay       UnderlayOptions
+	Reconcile      ReconcileOptions
+	IPAM           IPAMOptions
+	Capture        CaptureOptions
+	Tracing        TracingOptions
+	EventBus       EventBusOptions
+	NodeFeedback   NodeFeedbackOptions
+	Metadata       MetadataOptions
+	Webhooks       WebhookOptions
+	Diagnostics    DiagnosticsOptions
+	Cache          internal.MetalnetCacheOptions
+	Chaos          chaos.Options
+}
+
+// LeaderElectionOptions configure the leader election between the metalnet instances of a node.
+type LeaderElectionOptions struct {
+	Enabled       bool
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// StandaloneOptions configure running without Kubernetes.
+type StandaloneOptions struct {
+	Dir       string
+	StatusDir string
+}
+
+// DPServiceOptions configure the connection to dpservice.
+type DPServiceOptions struct {
+	Address           string
+	DialTimeout       time.Duration
+	DialRetries       int
+	DialRetryInterval time.Duration
+	Keepalive         metalnetdpdk.KeepaliveOptions
+	MaxMessageSize    int
+	CacheTTL          time.Duration
+	EnableRestore     bool
+}
+
+// MetalbondOptions configure the metalbond peers and the exchange of routes with them.
+type MetalbondOptions struct {
+	Peers          []string
+	PeersFile      string
+	PeerSyncPeriod time.Duration
+	LocalAddress   string
+	Debug          bool
+	Headless       bool
+
+	RouteWorkers         int
+	FlapDampingThreshold int
+	FlapDampingWindow    time.Duration
+	FlapDampingPenalty   time.Duration
+
+	NextHopProbeInterval         time.Duration
+	NextHopProbePort             uint16
+	NextHopProbeFailureThreshold int
+	AllowedUnderlayCIDRs         []string
+
+	ClusterID      uint16
+	PeerClusterIDs []uint
+
+	AggregateRoutesVNIs    []uint
+	AnnouncementPolicyFile string
+}
+
+// DeviceOptions configure how devices are handed out to network interfaces.
+type DeviceOptions struct {
+	TAPDeviceMod        bool
+	Allocator           string
+	PFBaseAddr          string
+	NetdevNames         []string
+	ConfigMap           string
+	ClaimStore          string
+	PodResourcesSocket  string
+	HostInterfaceDevice string
+}
+
+// UnderlayOptions configure the underlay address of the node and the validation of the underlay routes.
+type UnderlayOptions struct {
+	Address            string
+	Interface          string
+	PrefixLength       int
+	ValidationInterval time.Duration
+}
+
+// ReconcileOptions configure how often and how fast the objects are reconciled.
+type ReconcileOptions struct {
+	MaxConcurrentReconciles  int
+	ObjectUpdateRate         float64
+	ObjectUpdateBurst        int
+	ResyncPeriod             time.Duration
+	InitialSyncTimeout       time.Duration
+	VirtualIPHandoverTimeout time.Duration
+	TombstonePurgeInterval   time.Duration
+	IsolationAuditInterval   time.Duration
+}
+
+// IPAMOptions configure the allocation of the ips of network interfaces.
+type IPAMOptions struct {
+	Mode           string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+}
+
+// CaptureOptions configure packet captures.
+type CaptureOptions struct {
+	Dir         string
+	SinkAddress string
+	UDPPort     uint16
+	MaxDuration time.Duration
+}
+
+// TracingOptions configure the export of traces.
+type TracingOptions struct {
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+// EventBusOptions configure the publishing of programming events.
+type EventBusOptions struct {
+	NATSURL       string
+	SubjectPrefix string
+}
+
+// NodeFeedbackOptions configure how the node is marked while the dataplane is degraded.
+type NodeFeedbackOptions struct {
+	Capacity             string
+	Dataplane            string
+	DataplaneGracePeriod time.Duration
+}
+
+// MetadataOptions configure the network interface metadata propagated to metrics.
+type MetadataOptions struct {
+	Labels      []string
+	Annotations []string
+}
+
+// WebhookOptions configure the webhooks of the metalnet API.
+type WebhookOptions struct {
+	Enabled                               bool
+	BlockAttachedNetworkInterfaceDeletion bool
+}
+
+// DiagnosticsOptions configure the endpoints and logs inspecting a running metalnet.
+type DiagnosticsOptions struct {
+	PprofAddr            string
+	RuntimeStatsInterval time.Duration
+	DumpStacksOnSIGQUIT  bool
+	IntrospectionSocket  string
+}
+
+// AddFlags adds the flags of the options to the given flag set.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	hostName, _ := os.Hostname()
+
+	fs.StringVar(&o.MetricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	fs.StringVar(&o.ProbeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	fs.StringVar(&o.NodeName, "node-name", hostName, "The node name to react to when reconciling network interfaces.")
+	fs.StringVar(&o.MetalnetDir, "metalnet-dir", "/var/lib/metalnet", "Directory to store metalnet data at.")
+	fs.BoolVar(&o.EnableIPv6Support, "enable-ipv6", false, "Enable IPv6 support")
+	fs.IntVar(&o.PublicVNI, "public-vni", 100, "Virtual network identifier used for public routing announcements.")
+	fs.IntVar(&o.PublicVNIIPv6, "public-vni-ipv6", 0,
+		"Virtual network identifier used for public routing announcements of IPv6 addresses. Defaults to --public-vni.")
+	fs.StringVar(&o.VNIRange, "vni-range", "",
+		"Range (<min>-<max>) the VNIs of networks have to be in. Networks outside of it are rejected and not subscribed to. "+
+			"Must not contain the public VNIs. All VNIs are allowed if empty.")
+	fs.IPVar(&o.RouterAddress, "router-address", net.IP{}, "The address of the next router.")
+	fs.StringVar(&o.PreferNetwork, "prefer-network", "", "Prefer network routes (e.g. 2001:db8::1/52)")
+	fs.BoolVar(&o.Maintenance, "maintenance", false,
+		"Start in maintenance: withdraw all announcements but keep the dpservice state. "+
+			"Without this flag, maintenance is controlled by the "+networkingv1alpha1.MaintenanceAnnotation+" node annotation.")
+	fs.StringVar(&o.WorkloadKubeconfig, "workload-kubeconfig", "",
+		"Kubeconfig of the workload cluster whose EndpointSlices provide load balancer targets. Empty disables the discovery.")
+	fs.StringVar(&o.CRDCompatibility, "crd-compatibility", string(crdcheck.ModeWarn),
+		"How installed custom resource definitions not matching this version are handled. One of warn (log and start), "+
+			"strict (refuse to start) or read-only (start without writing any objects).")
+
+	o.LeaderElection.AddFlags(fs)
+	o.Standalone.AddFlags(fs)
+	o.DPService.AddFlags(fs)
+	o.Metalbond.AddFlags(fs)
+	o.Devices.AddFlags(fs)
+	o.Underlay.AddFlags(fs)
+	o.Reconcile.AddFlags(fs)
+	o.IPAM.AddFlags(fs)
+	o.Capture.AddFlags(fs)
+	o.Tracing.AddFlags(fs)
+	o.EventBus.AddFlags(fs)
+	o.NodeFeedback.AddFlags(fs)
+	o.Metadata.AddFlags(fs)
+	o.Webhooks.AddFlags(fs)
+	o.Diagnostics.AddFlags(fs)
+
+	fs.IntVar(&o.Cache.MaxLoadBalancerServers, "cache-max-load-balancer-servers", 0,
+		"Maximum number of load balancers in the internal cache. Zero means unbounded.")
+	fs.IntVar(&o.Cache.MaxPeeredVNIs, "cache-max-peered-vnis", 0,
+		"Maximum number of network peerings in the internal cache. Zero means unbounded.")
+	fs.Float64Var(&o.Chaos.ErrorRate, "chaos-error-rate", 0,
+		"Probability of failing a call to dpservice or metalbond, for testing only. Half of the failed calls are executed before failing.")
+	fs.DurationVar(&o.Chaos.MaxDelay, "chaos-max-delay", 0, "Maximum random delay added to the calls to dpservice and metalbond, for testing only.")
+	fs.Int64Var(&o.Chaos.Seed, "chaos-seed", 0, "Seed of the injected faults. Zero uses a random seed.")
+}
+
+func (o *LeaderElectionOptions) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "leader-elect", false,
+		"Enable leader election on the lease of the node (<node-name>.metalnet.ironcore.dev). "+
+			"A second metalnet instance on the node stands by with warm caches until the active instance releases the lease.")
+	fs.DurationVar(&o.LeaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"Duration the standby instance waits before taking over a lease that was not renewed.")
+	fs.DurationVar(&o.RenewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"Duration the active instance retries renewing its lease before giving up leadership.")
+	fs.DurationVar(&o.RetryPeriod, "leader-election-retry-period", 2*time.Second,
+		"Interval the instances try to acquire or renew the lease at.")
+}
+
+func (o *StandaloneOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Dir, "standalone-dir", "",
+		"Run without Kubernetes, reading the metalnet objects from the YAML files in this directory.")
+	fs.StringVar(&o.StatusDir, "standalone-status-dir", "",
+		"Directory the objects including their status are written to in standalone mode.")
+}
+
+func (o *DPServiceOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Address, "dp-service-address", "127.0.0.1:1337", "The address of dpservice. Either host:port or the absolute path of a unix domain socket prefixed with "+metalnetdpdk.UnixAddressPrefix+".")
+	fs.DurationVar(&o.DialTimeout, "dp-service-dial-timeout", time.Second, "Timeout of a single attempt to connect to dpservice.")
+	fs.IntVar(&o.DialRetries, "dp-service-dial-retries", -1,
+		"Number of retries to connect to dpservice before exiting, e.g. while dpservice is starting. Negative retries forever.")
+	fs.DurationVar(&o.DialRetryInterval, "dp-service-dial-retry-interval", 2*time.Second, "Interval between two attempts to connect to dpservice.")
+	fs.DurationVar(&o.Keepalive.Time, "dp-service-keepalive-time", 10*time.Second,
+		"Interval of the keepalive pings sent to dpservice on an idle connection. Zero disables keepalive pings.")
+	fs.DurationVar(&o.Keepalive.Timeout, "dp-service-keepalive-timeout", 5*time.Second,
+		"Time to wait for the acknowledgement of a keepalive ping before the connection to dpservice is closed.")
+	fs.DurationVar(&o.Keepalive.MaxIdle, "dp-service-max-idle", 0,
+		"Time without calls after which the connection to dpservice is closed until the next call. Zero keeps it open.")
+	fs.IntVar(&o.MaxMessageSize, "dp-service-max-message-size", metalnetdpdk.DefaultMaxListMessageSize,
+		"Maximum size in bytes of a response received from dpservice. Bounds the memory of listing e.g. the routes of a VNI, larger lists fail.")
+	fs.DurationVar(&o.CacheTTL, "dpservice-cache-ttl", time.Minute,
+		"Maximum age of dpservice state cached between reconciles. Zero disables the cache.")
+	fs.BoolVar(&o.EnableRestore, "enable-dpservice-restore", false,
+		"Allow restoring dpservice snapshots by posting them to /debug/dpservice-snapshot of the metrics endpoint.")
+}
+
+func (o *MetalbondOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringSliceVar(&o.Peers, "metalbond-peer", nil, "The addresses of the metalbond peers.")
+	fs.StringVar(&o.PeersFile, "metalbond-peers-file", "",
+		"File listing the addresses of the metalbond peers, one per line. Overrides --metalbond-peer. Changes are applied without a restart.")
+	fs.BoolVar(&o.Headless, "metalbond-headless", false,
+		"Keep programming dpservice while no metalbond peer session is established instead of exiting. "+
+			"Route updates are queued and sent once a session is established; readiness fails meanwhile.")
+	fs.StringVar(&o.LocalAddress, "metalbond-local-address", "",
+		"Address the metalbond peer sessions are dialed from, e.g. the IPv6 loopback address of the node. Chosen by the kernel if empty.")
+	fs.DurationVar(&o.PeerSyncPeriod, "metalbond-peer-sync-period", 10*time.Second,
+		"Time given to a new metalbond peer session to sync its routes before removed peers are drained.")
+	fs.BoolVar(&o.Debug, "metalbond-debug", false, "Enable metalbond debug.")
+	fs.IntVar(&o.RouteWorkers, "metalbond-route-workers", 4, "Number of metalbond routes programmed into dpservice in parallel.")
+	fs.IntVar(&o.FlapDampingThreshold, "metalbond-flap-damping-threshold", 0,
+		"Number of updates of a metalbond route within the flap damping window after which the route is suppressed. 0 disables flap damping.")
+	fs.DurationVar(&o.FlapDampingWindow, "metalbond-flap-damping-window", time.Minute,
+		"Period the updates of a metalbond route are counted in for flap damping.")
+	fs.DurationVar(&o.FlapDampingPenalty, "metalbond-flap-damping-penalty", 5*time.Minute,
+		"Period a flapping metalbond route is not added again for. Its withdrawals are programmed right away.")
+	fs.DurationVar(&o.NextHopProbeInterval, "metalbond-next-hop-probe-interval", 0,
+		"Interval the hosts announcing the next hops of the received metalbond routes are probed at. The routes of unreachable hosts are removed "+
+			"until they recover. Requires --underlay-address or --underlay-interface. Zero disables probing.")
+	fs.Uint16Var(&o.NextHopProbePort, "metalbond-next-hop-probe-port", 4711,
+		"TCP port the probes of the hosts announcing next hops connect to. A refused connection counts as reachable.")
+	fs.IntVar(&o.NextHopProbeFailureThreshold, "metalbond-next-hop-probe-failure-threshold", 3,
+		"Number of consecutive failed probes after which the routes of the next hops of a host are removed.")
+	fs.StringSliceVar(&o.AllowedUnderlayCIDRs, "metalbond-allowed-underlay-cidr", nil,
+		"Underlay ranges the next hops of received metalbond routes have to be in. Routes with other next hops are rejected. Empty allows all IPv6 next hops.")
+	fs.Uint16Var(&o.ClusterID, "cluster-id", 0,
+		"Id of the cluster tagged on the announced metalbond routes, to exchange the routes of a VNI with other clusters. "+
+			"Of the routes of several clusters for the same destination only those of the most preferred cluster are programmed. 0 disables cluster tagging.")
+	fs.UintSliceVar(&o.PeerClusterIDs, "peer-cluster-id", nil,
+		"Ids of the remote clusters whose metalbond routes are accepted if --cluster-id is set. Empty accepts the routes of all clusters.")
+	fs.UintSliceVar(&o.AggregateRoutesVNIs, "aggregate-routes-vni", nil,
+		"VNIs whose announced routes with the same next hop are aggregated into summarizing prefixes.")
+	fs.StringVar(&o.AnnouncementPolicyFile, "announcement-policy", "",
+		"Path to a file with allow / deny prefix lists per VNI applied to all routes announced via metalbond.")
+}
+
+func (o *DeviceOptions) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.TAPDeviceMod, "tapdevice-mod", false, "Enable TAP device support. Shorthand for --device-allocator="+deviceAllocatorNetdev+".")
+	fs.StringVar(&o.Allocator, "device-allocator", deviceAllocatorPCI,
+		fmt.Sprintf("How devices are handed out to network interfaces. One of %s (virtual functions of the PCI devices), "+
+			"%s (the netdevs of --netdev-names) or %s (the devices of this node listed in --device-configmap).",
+			deviceAllocatorPCI, deviceAllocatorNetdev, deviceAllocatorConfigMap))
+	fs.StringVar(&o.PFBaseAddr, "pf-pci-base-addr", defaultPFBaseAddr, "Physical Function(pf) PCI base address used for VF address calculation")
+	fs.StringSliceVar(&o.NetdevNames, "netdev-names", []string{"net_tap3", "net_tap4", "net_tap5"},
+		"Names of the netdevs handed out by the "+deviceAllocatorNetdev+" device allocator.")
+	fs.StringVar(&o.ConfigMap, "device-configmap", "",
+		"Namespace and name (<namespace>/<name>) of the config map listing the devices per node for the "+deviceAllocatorConfigMap+" device allocator.")
+	fs.StringVar(&o.ClaimStore, "device-claim-store", deviceClaimStoreFile,
+		"Store of the device claims. One of "+deviceClaimStoreFile+" (files in the metalnet dir) or "+deviceClaimStoreAllocation+
+			" (Allocation objects, survive the loss of the metalnet dir). Claims of the file store are moved to the allocation store.")
+	fs.StringVar(&o.PodResourcesSocket, "pod-resources-socket", "",
+		"Socket of the pod resources API of the kubelet, usually "+netfns.DefaultPodResourcesSocket+". If set, network interfaces "+
+			"referencing a device claim are programmed on the device a device plugin allocated to their pod. Empty disables device claims.")
+	fs.StringVar(&o.HostInterfaceDevice, "host-interface-device", "",
+		"Name dpservice knows the host port by. If set, network interfaces with hostInterface are programmed on it, "+
+			"so services of the node take part in their networks. Empty disables host interfaces.")
+}
+
+func (o *UnderlayOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Address, "underlay-address", "",
+		"Underlay address of this node the underlay routes of dpservice are validated against and the hosts of metalbond next hops are derived from. Overrides --underlay-interface.")
+	fs.StringVar(&o.Interface, "underlay-interface", "",
+		"Interface (e.g. the loopback) the underlay address of this node is discovered from. Underlay validation is disabled if neither this nor --underlay-address is set.")
+	fs.IntVar(&o.PrefixLength, "underlay-prefix-length", 64,
+		"Length of the underlay prefix of the nodes dpservice derives its underlay routes from.")
+	fs.DurationVar(&o.ValidationInterval, "underlay-validation-interval", time.Minute,
+		"Interval the underlay routes of dpservice are validated at.")
+}
+
+func (o *ReconcileOptions) AddFlags(fs *flag.FlagSet) {
+	fs.IntVar(&o.MaxConcurrentReconciles, "max-concurrent-reconciles", 4,
+		"Number of networks, network interfaces and loadbalancers each reconciled in parallel.")
+	fs.Float64Var(&o.ObjectUpdateRate, "object-update-rate", 1,
+		"Updates per second applied to a single network interface or loadbalancer. Zero disables the rate limit.")
+	fs.IntVar(&o.ObjectUpdateBurst, "object-update-burst", 10, "Updates applied to a single network interface or loadbalancer in a burst.")
+	fs.DurationVar(&o.ResyncPeriod, "resync-period", 0,
+		"Period all networks, network interfaces and load balancers are reconciled at (plus up to 10% jitter), even without changes. Zero disables periodic reconciles.")
+	fs.DurationVar(&o.InitialSyncTimeout, "initial-sync-timeout", 5*time.Minute,
+		"Maximum time to wait for the local objects to be reconciled after startup before reporting ready. Zero waits forever.")
+	fs.DurationVar(&o.VirtualIPHandoverTimeout, "virtual-ip-handover-timeout", 30*time.Second,
+		"Maximum time a removed virtual ip stays announced while waiting for its new owner. Zero waits forever.")
+	fs.DurationVar(&o.TombstonePurgeInterval, "tombstone-purge-interval", time.Minute,
+		"Interval the dataplane state of deleted network interfaces and load balancers whose finalizer was removed "+
+			"before their cleanup completed is purged at.")
+	fs.DurationVar(&o.IsolationAuditInterval, "isolation-audit-interval", 5*time.Minute,
+		"Interval routes into not peered VNIs are searched for and removed at. 0 disables the audit.")
+}
+
+func (o *IPAMOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Mode, "ipam", "static",
+		"How the ips of network interfaces created without ips are allocated. One of static (not allocated), "+
+			"pool (from the NetworkIPPools of their network) or webhook (by the external IPAM at --ipam-webhook-url).")
+	fs.StringVar(&o.WebhookURL, "ipam-webhook-url", "", "URL of the external IPAM used by --ipam=webhook.")
+	fs.DurationVar(&o.WebhookTimeout, "ipam-webhook-timeout", 10*time.Second, "Timeout of the calls to the external IPAM.")
+}
+
+func (o *CaptureOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Dir, "capture-dir", "", "Directory to store packet captures at. Defaults to the captures directory in the metalnet dir.")
+	fs.StringVar(&o.SinkAddress, "capture-sink-address", "",
+		"Underlay address of this node dpservice mirrors captured packets to. Packet capture is disabled if empty.")
+	fs.Uint16Var(&o.UDPPort, "capture-udp-port", 3010, "UDP port captured packets are received on.")
+	fs.DurationVar(&o.MaxDuration, "capture-max-duration", time.Minute, "Maximum duration of a packet capture.")
+}
+
+func (o *TracingOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "tracing-endpoint", "",
+		"The host:port of the OTLP gRPC collector traces are exported to. Tracing is disabled if empty.")
+	fs.BoolVar(&o.Insecure, "tracing-insecure", false, "Export traces without TLS.")
+	fs.Float64Var(&o.SampleRatio, "tracing-sample-ratio", 1, "Ratio of the reconciles traced.")
+}
+
+func (o *EventBusOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.NATSURL, "event-bus-nats-url", "",
+		"Comma-separated urls of the NATS servers programming events are published to. Publishing is disabled if empty.")
+	fs.StringVar(&o.SubjectPrefix, "event-bus-subject-prefix", eventbus.DefaultSubjectPrefix,
+		"Prefix of the subjects programming events are published to. The subject of an event is <prefix>.<node>.<type>.")
+}
+
+func (o *NodeFeedbackOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Capacity, "capacity-node-feedback", string(controllers.CapacityFeedbackNone),
+		"How the node is marked while dpservice tables are full. One of none, condition (NetworkCapacityExceeded node condition) or taint (condition and NoSchedule taint).")
+	fs.StringVar(&o.Dataplane, "dataplane-node-feedback", string(controllers.DataplaneFeedbackNone),
+		"How the node is marked while dpservice is down or no metalbond peer is connected. One of none, condition (NetworkDataplaneUnavailable node condition) or taint (condition and NoSchedule taint).")
+	fs.DurationVar(&o.DataplaneGracePeriod, "dataplane-unavailable-grace-period", 30*time.Second,
+		"Time dpservice or all metalbond peers have to be down before the node is marked as dataplane unavailable.")
+}
+
+func (o *MetadataOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringSliceVar(&o.Labels, "network-interface-metadata-label", nil,
+		"Keys of the network interface labels propagated to the metalnet_network_interface_info metric.")
+	fs.StringSliceVar(&o.Annotations, "network-interface-metadata-annotation", nil,
+		"Keys of the network interface annotations propagated to the metalnet_network_interface_info metric.")
+}
+
+func (o *WebhookOptions) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "enable-webhooks", false, "Serve the defaulting and validating webhooks of the metalnet API.")
+	fs.BoolVar(&o.BlockAttachedNetworkInterfaceDeletion, "block-attached-network-interface-deletion", false,
+		"Reject deleting network interfaces that are attached to a machine. Requires --enable-webhooks. "+
+			"Otherwise their deletion is accepted and the teardown waits for the detachment.")
+}
+
+func (o *DiagnosticsOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.PprofAddr, "pprof-bind-address", "",
+		"The address the pprof endpoints bind to. The reconciles carry the pprof label reconciler. Empty disables pprof.")
+	fs.DurationVar(&o.RuntimeStatsInterval, "runtime-stats-interval", 0,
+		"Interval the goroutine count, heap usage and reconcile queue lengths are logged at. Zero disables the logging.")
+	fs.BoolVar(&o.DumpStacksOnSIGQUIT, "dump-stacks-on-sigquit", false,
+		"Dump the stacks of all goroutines to stderr on SIGQUIT and keep running, instead of exiting.")
+	fs.StringVar(&o.IntrospectionSocket, "introspection-socket", "",
+		"Unix socket the programming state of the network interfaces of this node is served on to other node agents. Empty disables the introspection API.")
+}
@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package capture records packet captures of dpservice interfaces into pcap files.
+//
+// dpservice mirrors the packets of the captured interfaces as UDP datagrams to a sink address.
+// The Manager makes the local node the sink, writes the received frames into a pcap file and
+// stops the capture after the requested duration.
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+)
+
+const (
+	// The captured packets may contain sensitive data of the tenants, so only metalnet may read them.
+	perm     = 0700
+	filePerm = 0600
+
+	// maxDatagramSize is the maximum size of a mirrored packet.
+	maxDatagramSize = 65535
+)
+
+var (
+	// ErrBusy is returned if a capture is already running. dpservice supports one capture at a time.
+	ErrBusy = errors.New("another capture is running")
+	// ErrNotFound is returned if no capture was started for an interface.
+	ErrNotFound = errors.New("capture not found")
+)
+
+type Options struct {
+	// Dir is the directory the pcap files are stored in.
+	Dir string
+	// SinkAddress is the underlay address of this node dpservice mirrors the packets to.
+	SinkAddress netip.Addr
+	// Port is the UDP port the mirrored packets are received on. Zero picks a free port.
+	Port uint16
+	// MaxDuration limits the duration of a capture. Defaults to 1 minute.
+	MaxDuration time.Duration
+}
+
+// Result is the state of a capture.
+type Result struct {
+	// Path is the pcap file of the capture.
+	Path string
+	// StartTime is the time the capture was started.
+	StartTime time.Time
+	// CompletionTime is the time the capture was stopped. Zero while the capture is running.
+	CompletionTime time.Time
+	// Packets is the number of captured packets.
+	Packets int
+	// Err is the error the capture failed with, if any.
+	Err error
+}
+
+// Done reports whether the capture stopped.
+func (r *Result) Done() bool {
+	return !r.CompletionTime.IsZero()
+}
+
+type Manager struct {
+	dpdk dpdkclient.Client
+	opts Options
+	log  logr.Logger
+
+	mu      sync.Mutex
+	running string
+	results map[string]*Result
+}
+
+func NewManager(log logr.Logger, dpdk dpdkclient.Client, opts Options) *Manager {
+	if opts.MaxDuration <= 0 {
+		opts.MaxDuration = time.Minute
+	}
+	return &Manager{
+		dpdk:    dpdk,
+		opts:    opts,
+		log:     log,
+		results: make(map[string]*Result),
+	}
+}
+
+// StopStale stops a capture dpservice still runs for a previous metalnet process, whose pcap file is not written
+// anymore. It must be called before the first capture is started.
+func (m *Manager) StopStale(ctx context.Context) error {
+	res, err := m.dpdk.CaptureStop(ctx, []uint32{dpdkerrors.NOT_ACTIVE})
+	if err != nil {
+		return fmt.Errorf("error stopping dpservice capture: %w", err)
+	}
+	if res.Spec.InterfaceCount > 0 {
+		m.log.Info("Stopped stale capture", "Interfaces", res.Spec.InterfaceCount)
+	}
+	return nil
+}
+
+// MaxDuration is the maximum duration of a capture.
+func (m *Manager) MaxDuration() time.Duration {
+	return m.opts.MaxDuration
+}
+
+// Start captures the packets of the virtual function vfName for the given duration. The capture is
+// tracked under the given ID, which replaces any finished capture tracked under the same ID.
+func (m *Manager) Start(ctx context.Context, id, vfName string, duration time.Duration) (*Result, error) {
+	if duration <= 0 || duration > m.opts.MaxDuration {
+		duration = m.opts.MaxDuration
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running != "" {
+		return nil, ErrBusy
+	}
+
+	if err := os.MkdirAll(m.opts.Dir, perm); err != nil {
+		return nil, fmt.Errorf("error creating capture directory: %w", err)
+	}
+	startTime := time.Now()
+	path := filepath.Join(m.opts.Dir, fmt.Sprintf("%s-%s.pcap", id, startTime.UTC().Format("20060102T150405Z")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pcap file: %w", err)
+	}
+	w, err := newPcapWriter(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("error writing pcap header: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: int(m.opts.Port)})
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("error listening for mirrored packets: %w", err)
+	}
+	port := uint32(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	sinkAddress := m.opts.SinkAddress
+	if _, err := m.dpdk.CaptureStart(ctx, &dpdk.CaptureStart{
+		TypeMeta: dpdk.TypeMeta{Kind: dpdk.CaptureStartKind},
+		CaptureStartMeta: dpdk.CaptureStartMeta{
+			Config: &dpdk.CaptureConfig{
+				SinkNodeIP: &sinkAddress,
+				UdpSrcPort: port,
+				UdpDstPort: port,
+			},
+		},
+		Spec: dpdk.CaptureStartSpec{
+			Interfaces: []dpdk.CaptureInterface{{InterfaceType: "vf", InterfaceInfo: vfName}},
+		},
+	}); err != nil {
+		_ = conn.Close()
+		_ = f.Close()
+		return nil, fmt.Errorf("error starting dpservice capture: %w", err)
+	}
+
+	result := &Result{Path: path, StartTime: startTime}
+	m.results[id] = result
+	m.running = id
+
+	go m.record(id, conn, f, w, duration)
+	return copyResult(result), nil
+}
+
+func (m *Manager) record(id string, conn *net.UDPConn, f *os.File, w *pcapWriter, duration time.Duration) {
+	log := m.log.WithValues("ID", id)
+	_ = conn.SetReadDeadline(time.Now().Add(duration))
+
+	var (
+		packets int
+		errs    []error
+		buf     = make([]byte, maxDatagramSize)
+	)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				errs = append(errs, fmt.Errorf("error receiving mirrored packet: %w", err))
+			}
+			break
+		}
+		if err := w.writePacket(time.Now(), buf[:n]); err != nil {
+			errs = append(errs, fmt.Errorf("error writing packet: %w", err))
+			break
+		}
+		packets++
+	}
+
+	// The capture has to be stopped even if the context of the request that started it is gone.
+	stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := m.dpdk.CaptureStop(stopCtx); err != nil {
+		errs = append(errs, fmt.Errorf("error stopping dpservice capture: %w", err))
+	}
+	if err := conn.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("error closing capture listener: %w", err))
+	}
+	if err := f.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("error closing pcap file: %w", err))
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		log.Error(err, "Capture failed")
+	} else {
+		log.V(1).Info("Capture completed", "Packets", packets)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := m.results[id]
+	result.CompletionTime = time.Now()
+	result.Packets = packets
+	result.Err = err
+	m.running = ""
+}
+
+// Get returns the state of the capture tracked under the given ID.
+func (m *Manager) Get(id string) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result, ok := m.results[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyResult(result), nil
+}
+
+// Forget stops tracking the finished capture under the given ID. The pcap file is kept.
+func (m *Manager) Forget(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running != id {
+		delete(m.results, id)
+	}
+}
+
+func copyResult(result *Result) *Result {
+	res := *result
+	return &res
+}
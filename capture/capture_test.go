@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package capture_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	"github.com/ironcore-dev/metalnet/capture"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeDPDK records the started and stopped captures. Calling any other method panics.
+type fakeDPDK struct {
+	dpdkclient.Client
+
+	mu      sync.Mutex
+	started []*dpdk.CaptureStart
+	stopped int
+}
+
+func (c *fakeDPDK) CaptureStart(_ context.Context, capture *dpdk.CaptureStart, _ ...[]uint32) (*dpdk.CaptureStart, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = append(c.started, capture)
+	return capture, nil
+}
+
+func (c *fakeDPDK) CaptureStop(_ context.Context, _ ...[]uint32) (*dpdk.CaptureStop, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped++
+	return &dpdk.CaptureStop{}, nil
+}
+
+func (c *fakeDPDK) lastStarted() *dpdk.CaptureStart {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.started[len(c.started)-1]
+}
+
+func (c *fakeDPDK) stoppedCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopped
+}
+
+func sendPackets(port uint32, packets ...string) {
+	conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	Expect(err).NotTo(HaveOccurred())
+	defer func() { _ = conn.Close() }()
+	for _, packet := range packets {
+		_, err := conn.Write([]byte(packet))
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
+
+var _ = Describe("Manager", func() {
+	var (
+		dpdkClient *fakeDPDK
+		manager    *capture.Manager
+	)
+
+	BeforeEach(func() {
+		dpdkClient = &fakeDPDK{}
+		manager = capture.NewManager(logr.Discard(), dpdkClient, capture.Options{
+			Dir:         filepath.Join(GinkgoT().TempDir(), "captures"),
+			SinkAddress: netip.MustParseAddr("fd00::1"),
+			MaxDuration: 10 * time.Second,
+		})
+	})
+
+	It("should write the mirrored packets into a pcap file", func() {
+		result, err := manager.Start(context.TODO(), "foo", "net_tap3", 500*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Done()).To(BeFalse())
+
+		started := dpdkClient.lastStarted()
+		Expect(*started.CaptureStartMeta.Config.SinkNodeIP).To(Equal(netip.MustParseAddr("fd00::1")))
+		Expect(started.Spec.Interfaces).To(Equal([]dpdk.CaptureInterface{{InterfaceType: "vf", InterfaceInfo: "net_tap3"}}))
+		sendPackets(started.CaptureStartMeta.Config.UdpDstPort, "first", "second")
+
+		Eventually(func(g Gomega) {
+			result, err = manager.Get("foo")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.Done()).To(BeTrue())
+		}).Should(Succeed())
+		Expect(result.Err).NotTo(HaveOccurred())
+		Expect(result.Packets).To(Equal(2))
+		Expect(dpdkClient.stoppedCount()).To(Equal(1))
+
+		data, err := os.ReadFile(result.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(binary.LittleEndian.Uint32(data[0:4])).To(Equal(uint32(0xa1b2c3d4)))
+		Expect(data).To(HaveLen(24 + 16 + len("first") + 16 + len("second")))
+		Expect(binary.LittleEndian.Uint32(data[32:36])).To(Equal(uint32(len("first"))))
+		Expect(string(data[40:45])).To(Equal("first"))
+		Expect(string(data[61:])).To(Equal("second"))
+
+		info, err := os.Stat(result.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		info, err = os.Stat(filepath.Dir(result.Path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+	})
+
+	It("should stop a stale capture of dpservice", func() {
+		Expect(manager.StopStale(context.TODO())).To(Succeed())
+		Expect(dpdkClient.stoppedCount()).To(Equal(1))
+	})
+
+	It("should run one capture at a time", func() {
+		_, err := manager.Start(context.TODO(), "foo", "net_tap3", 200*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = manager.Start(context.TODO(), "bar", "net_tap4", 200*time.Millisecond)
+		Expect(err).To(MatchError(capture.ErrBusy))
+
+		Eventually(func(g Gomega) {
+			result, err := manager.Get("foo")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.Done()).To(BeTrue())
+		}).Should(Succeed())
+		_, err = manager.Start(context.TODO(), "bar", "net_tap4", 200*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should forget finished captures only", func() {
+		_, err := manager.Start(context.TODO(), "foo", "net_tap3", 200*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+
+		manager.Forget("foo")
+		Expect(manager.Get("foo")).NotTo(BeNil())
+
+		Eventually(func(g Gomega) {
+			result, err := manager.Get("foo")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.Done()).To(BeTrue())
+		}).Should(Succeed())
+		manager.Forget("foo")
+		_, err = manager.Get("foo")
+		Expect(err).To(MatchError(capture.ErrNotFound))
+	})
+})
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package chaos injects faults into the calls of metalnet to dpservice and metalbond, to exercise the
+// idempotency of the reconcilers against half-programmed states in CI and staging. It must not be enabled
+// in production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var injectedFaults = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "metalnet_chaos_injected_faults_total",
+	Help: "Number of faults injected into the calls to dpservice and metalbond, by target and fault.",
+}, []string{"target", "fault"})
+
+func init() {
+	metrics.Registry.MustRegister(injectedFaults)
+}
+
+// ErrInjected is returned by calls failed by an Injector.
+var ErrInjected = errors.New("injected fault")
+
+// Fault is a fault injected into a call.
+type Fault int
+
+const (
+	// FaultNone executes the call unchanged.
+	FaultNone Fault = iota
+	// FaultBefore fails the call without executing it.
+	FaultBefore
+	// FaultAfter executes the call but reports it as failed, as if the response was lost.
+	FaultAfter
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultBefore:
+		return "before"
+	case FaultAfter:
+		return "after"
+	default:
+		return "none"
+	}
+}
+
+// Options are the options of an Injector.
+type Options struct {
+	// ErrorRate is the probability of a call failing, between 0 and 1. Half of the failing calls fail after
+	// they were executed.
+	ErrorRate float64
+	// MaxDelay is the maximum random delay added to every call.
+	MaxDelay time.Duration
+	// Seed seeds the random faults, so failing runs can be reproduced. Zero uses a random seed.
+	Seed int64
+}
+
+// Enabled reports whether the options inject any faults.
+func (o Options) Enabled() bool {
+	return o.ErrorRate > 0 || o.MaxDelay > 0
+}
+
+// Injector randomly delays and fails calls.
+type Injector struct {
+	opts Options
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewInjector creates an Injector with the given options.
+func NewInjector(opts Options) (*Injector, error) {
+	if opts.ErrorRate < 0 || opts.ErrorRate > 1 {
+		return nil, fmt.Errorf("error rate %v is not between 0 and 1", opts.ErrorRate)
+	}
+	if opts.MaxDelay < 0 {
+		return nil, fmt.Errorf("max delay %s is negative", opts.MaxDelay)
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{
+		opts: opts,
+		rand: rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+func (i *Injector) next() (Fault, time.Duration) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var delay time.Duration
+	if i.opts.MaxDelay > 0 {
+		delay = time.Duration(i.rand.Int63n(int64(i.opts.MaxDelay)))
+	}
+	if i.rand.Float64() >= i.opts.ErrorRate {
+		return FaultNone, delay
+	}
+	if i.rand.Intn(2) == 0 {
+		return FaultBefore, delay
+	}
+	return FaultAfter, delay
+}
+
+// Do runs the given call to the target with a random delay and fault injected. Injected faults are returned
+// as errors wrapping ErrInjected.
+func (i *Injector) Do(ctx context.Context, target, op string, call func(context.Context) error) error {
+	fault, delay := i.next()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if fault == FaultBefore {
+		injectedFaults.WithLabelValues(target, fault.String()).Inc()
+		return fmt.Errorf("%s %s: %w", target, op, ErrInjected)
+	}
+	if err := call(ctx); err != nil {
+		return err
+	}
+	if fault == FaultAfter {
+		injectedFaults.WithLabelValues(target, fault.String()).Inc()
+		return fmt.Errorf("%s %s (executed): %w", target, op, ErrInjected)
+	}
+	return nil
+}
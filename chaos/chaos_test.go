@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package chaos_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ironcore-dev/metalnet/chaos"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Injector", func() {
+	It("should reject invalid options", func() {
+		_, err := chaos.NewInjector(chaos.Options{ErrorRate: 1.5})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not inject faults with a zero error rate", func(ctx SpecContext) {
+		injector, err := chaos.NewInjector(chaos.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		calls := 0
+		for i := 0; i < 100; i++ {
+			Expect(injector.Do(ctx, "test", "Call", func(context.Context) error {
+				calls++
+				return nil
+			})).To(Succeed())
+		}
+		Expect(calls).To(Equal(100))
+	})
+
+	It("should fail calls before and after executing them", func(ctx SpecContext) {
+		injector, err := chaos.NewInjector(chaos.Options{ErrorRate: 1, Seed: 42})
+		Expect(err).NotTo(HaveOccurred())
+
+		var executedAndFailed, notExecuted int
+		for i := 0; i < 100; i++ {
+			executed := false
+			err := injector.Do(ctx, "test", "Call", func(context.Context) error {
+				executed = true
+				return nil
+			})
+			Expect(err).To(MatchError(chaos.ErrInjected))
+			if executed {
+				executedAndFailed++
+			} else {
+				notExecuted++
+			}
+		}
+		Expect(executedAndFailed).To(BeNumerically(">", 0))
+		Expect(notExecuted).To(BeNumerically(">", 0))
+	})
+
+	It("should return the errors of executed calls", func(ctx SpecContext) {
+		injector, err := chaos.NewInjector(chaos.Options{ErrorRate: 1, Seed: 42})
+		Expect(err).NotTo(HaveOccurred())
+
+		callErr := errors.New("boom")
+		for i := 0; i < 20; i++ {
+			err := injector.Do(ctx, "test", "Call", func(context.Context) error { return callErr })
+			Expect(errors.Is(err, callErr) || errors.Is(err, chaos.ErrInjected)).To(BeTrue())
+		}
+	})
+})
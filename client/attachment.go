@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"slices"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NetworkAttachment is a Network together with the NetworkInterfaces attached to it, including their
+// virtual ips and prefixes. It lets the compute layer provision the networking of a machine as one unit.
+type NetworkAttachment struct {
+	Network           *metalnetv1alpha1.Network
+	NetworkInterfaces []*metalnetv1alpha1.NetworkInterface
+}
+
+// Default puts NetworkInterfaces without namespace or network reference into the namespace of the Network
+// and references the Network.
+func (a *NetworkAttachment) Default() {
+	if a.Network == nil {
+		return
+	}
+	for _, nic := range a.NetworkInterfaces {
+		if nic.Namespace == "" {
+			nic.Namespace = a.Network.Namespace
+		}
+		if nic.Spec.NetworkRef.Name == "" {
+			nic.Spec.NetworkRef.Name = a.Network.Name
+		}
+	}
+}
+
+// Validate checks the cross-references of the objects of the attachment: all NetworkInterfaces have to
+// reference the Network of the attachment, their ips have to match their ip families, and their
+// virtual ips, ips and prefixes must not conflict with each other on the same node.
+func (a *NetworkAttachment) Validate() error {
+	var allErrs field.ErrorList
+
+	networkPath := field.NewPath("network")
+	if a.Network == nil {
+		return field.ErrorList{field.Required(networkPath, "")}.ToAggregate()
+	}
+	if a.Network.Name == "" {
+		allErrs = append(allErrs, field.Required(networkPath.Child("metadata", "name"), ""))
+	}
+
+	names := make(map[string]struct{}, len(a.NetworkInterfaces))
+	virtualIPs := make(map[netip.Addr]string)
+	for i, nic := range a.NetworkInterfaces {
+		nicPath := field.NewPath("networkInterfaces").Index(i)
+		if nic.Name == "" {
+			allErrs = append(allErrs, field.Required(nicPath.Child("metadata", "name"), ""))
+		} else if _, ok := names[nic.Name]; ok {
+			allErrs = append(allErrs, field.Duplicate(nicPath.Child("metadata", "name"), nic.Name))
+		}
+		names[nic.Name] = struct{}{}
+
+		if nic.Namespace != a.Network.Namespace {
+			allErrs = append(allErrs, field.Invalid(nicPath.Child("metadata", "namespace"), nic.Namespace,
+				fmt.Sprintf("must be the namespace %q of the network", a.Network.Namespace)))
+		}
+		if nic.Spec.NetworkRef.Name != a.Network.Name {
+			allErrs = append(allErrs, field.Invalid(nicPath.Child("spec", "networkRef", "name"), nic.Spec.NetworkRef.Name,
+				fmt.Sprintf("must reference the network %q", a.Network.Name)))
+		}
+
+		if len(nic.Spec.IPs) == 0 {
+			allErrs = append(allErrs, field.Required(nicPath.Child("spec", "ips"), ""))
+		}
+		if len(nic.Spec.IPFamilies) > 0 {
+			for j, ip := range nic.Spec.IPs {
+				if !slices.Contains(nic.Spec.IPFamilies, ip.Family()) {
+					allErrs = append(allErrs, field.Invalid(nicPath.Child("spec", "ips").Index(j), ip.String(),
+						fmt.Sprintf("ip family %s is not in the ip families of the network interface", ip.Family())))
+				}
+			}
+		}
+
+		if nic.Spec.VirtualIP != nil {
+			if other, ok := virtualIPs[nic.Spec.VirtualIP.Addr]; ok {
+				allErrs = append(allErrs, field.Duplicate(nicPath.Child("spec", "virtualIP"),
+					fmt.Sprintf("%s (used by network interface %s)", nic.Spec.VirtualIP, other)))
+			} else {
+				virtualIPs[nic.Spec.VirtualIP.Addr] = nic.Name
+			}
+		}
+
+		for _, other := range a.NetworkInterfaces[:i] {
+			if nic.Spec.NodeName == nil || other.Spec.NodeName == nil || *nic.Spec.NodeName != *other.Spec.NodeName {
+				continue
+			}
+			if err := internal.FindAddressConflict(networkInterfaceAddresses(nic), networkInterfaceAddresses(other)); err != nil {
+				allErrs = append(allErrs, field.Forbidden(nicPath.Child("spec"),
+					fmt.Sprintf("conflicts with network interface %s: %v", other.Name, err)))
+			}
+		}
+	}
+	return allErrs.ToAggregate()
+}
+
+func (a *NetworkAttachment) objects() []client.Object {
+	objs := []client.Object{a.Network}
+	for _, nic := range a.NetworkInterfaces {
+		objs = append(objs, nic)
+	}
+	return objs
+}
+
+func networkInterfaceAddresses(nic *metalnetv1alpha1.NetworkInterface) internal.InterfaceAddresses {
+	var addrs internal.InterfaceAddresses
+	for _, ip := range nic.Spec.IPs {
+		addrs.IPs = append(addrs.IPs, ip.Addr)
+	}
+	for _, prefix := range nic.Spec.Prefixes {
+		addrs.Prefixes = append(addrs.Prefixes, prefix.Prefix)
+	}
+	return addrs
+}
+
+// ApplyNetworkAttachment creates or updates the objects of the given attachment as a set.
+//
+// The attachment is validated as a whole, and every write is first sent as a dry run, so objects rejected
+// by the apiserver or an admission webhook abort the apply before anything is written. The Network is
+// written before its NetworkInterfaces. If a write fails nonetheless, e.g. because of a concurrent change,
+// the objects created by the apply are deleted again and the updated ones are reset to their previous
+// spec, labels and annotations.
+//
+// Existing objects get the spec of the attachment; their labels and annotations are merged with those of
+// the attachment.
+func ApplyNetworkAttachment(ctx context.Context, c client.Client, attachment *NetworkAttachment) error {
+	attachment.Default()
+	if err := attachment.Validate(); err != nil {
+		return fmt.Errorf("invalid network attachment: %w", err)
+	}
+
+	objs := attachment.objects()
+	for _, obj := range objs {
+		if _, err := applyObject(ctx, c, obj, true); err != nil {
+			return fmt.Errorf("error validating %T %s: %w", obj, client.ObjectKeyFromObject(obj), err)
+		}
+	}
+
+	var applied []appliedObject
+	for _, obj := range objs {
+		res, err := applyObject(ctx, c, obj, false)
+		if err != nil {
+			err = fmt.Errorf("error applying %T %s: %w", obj, client.ObjectKeyFromObject(obj), err)
+			if rollbackErr := rollbackObjects(ctx, c, applied); rollbackErr != nil {
+				return errors.Join(err, fmt.Errorf("error rolling back network attachment: %w", rollbackErr))
+			}
+			return err
+		}
+		applied = append(applied, res)
+	}
+	return nil
+}
+
+// appliedObject is an object written by ApplyNetworkAttachment. previous is nil if the object was created.
+type appliedObject struct {
+	current  client.Object
+	previous client.Object
+}
+
+func applyObject(ctx context.Context, c client.Client, desired client.Object, dryRun bool) (appliedObject, error) {
+	var (
+		createOpts []client.CreateOption
+		updateOpts []client.UpdateOption
+	)
+	if dryRun {
+		createOpts = append(createOpts, client.DryRunAll)
+		updateOpts = append(updateOpts, client.DryRunAll)
+	}
+
+	existing := desired.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return appliedObject{}, err
+		}
+
+		obj := desired.DeepCopyObject().(client.Object)
+		obj.SetResourceVersion("")
+		if err := c.Create(ctx, obj, createOpts...); err != nil {
+			return appliedObject{}, err
+		}
+		return appliedObject{current: obj}, nil
+	}
+
+	previous := existing.DeepCopyObject().(client.Object)
+	existing.SetLabels(mergeMaps(existing.GetLabels(), desired.GetLabels()))
+	existing.SetAnnotations(mergeMaps(existing.GetAnnotations(), desired.GetAnnotations()))
+	if err := setSpec(existing, desired); err != nil {
+		return appliedObject{}, err
+	}
+	if reflect.DeepEqual(previous, existing) {
+		return appliedObject{current: existing, previous: previous}, nil
+	}
+	if err := c.Update(ctx, existing, updateOpts...); err != nil {
+		return appliedObject{}, err
+	}
+	return appliedObject{current: existing, previous: previous}, nil
+}
+
+func rollbackObjects(ctx context.Context, c client.Client, applied []appliedObject) error {
+	var errs []error
+	for i := len(applied) - 1; i >= 0; i-- {
+		obj := applied[i]
+		key := client.ObjectKeyFromObject(obj.current)
+
+		if obj.previous == nil {
+			uid := obj.current.GetUID()
+			if err := c.Delete(ctx, obj.current, client.Preconditions{UID: &uid}); client.IgnoreNotFound(err) != nil {
+				errs = append(errs, fmt.Errorf("error deleting %T %s: %w", obj.current, key, err))
+			}
+			continue
+		}
+
+		latest := obj.current.DeepCopyObject().(client.Object)
+		if err := c.Get(ctx, key, latest); err != nil {
+			errs = append(errs, fmt.Errorf("error getting %T %s: %w", obj.current, key, err))
+			continue
+		}
+		latest.SetLabels(obj.previous.GetLabels())
+		latest.SetAnnotations(obj.previous.GetAnnotations())
+		if err := setSpec(latest, obj.previous); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := c.Update(ctx, latest); err != nil {
+			errs = append(errs, fmt.Errorf("error resetting %T %s: %w", obj.current, key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setSpec(dst, src client.Object) error {
+	switch dst := dst.(type) {
+	case *metalnetv1alpha1.Network:
+		dst.Spec = *src.(*metalnetv1alpha1.Network).Spec.DeepCopy()
+	case *metalnetv1alpha1.NetworkInterface:
+		dst.Spec = *src.(*metalnetv1alpha1.NetworkInterface).Spec.DeepCopy()
+	default:
+		return fmt.Errorf("unsupported object %T", dst)
+	}
+	return nil
+}
+
+func mergeMaps(existing, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return existing
+	}
+	res := make(map[string]string, len(existing)+len(desired))
+	for k, v := range existing {
+		res[k] = v
+	}
+	for k, v := range desired {
+		res[k] = v
+	}
+	return res
+}
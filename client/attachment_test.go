@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"context"
+	"errors"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("NetworkAttachment", func() {
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	newNetworkInterface := func(name, ip string) *metalnetv1alpha1.NetworkInterface {
+		return &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP(ip)},
+				NodeName:   ptr.To("node"),
+			},
+		}
+	}
+
+	newAttachment := func(nics ...*metalnetv1alpha1.NetworkInterface) *metalnetclient.NetworkAttachment {
+		return &metalnetclient.NetworkAttachment{
+			Network: &metalnetv1alpha1.Network{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network"},
+				Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+			},
+			NetworkInterfaces: nics,
+		}
+	}
+
+	It("should validate the cross-references of the attachment together", func() {
+		attachment := newAttachment(newNetworkInterface("nic-1", "10.0.0.1"), newNetworkInterface("nic-2", "10.0.0.2"))
+		attachment.Default()
+		Expect(attachment.Validate()).To(Succeed())
+		Expect(attachment.NetworkInterfaces[0].Namespace).To(Equal("default"))
+		Expect(attachment.NetworkInterfaces[0].Spec.NetworkRef.Name).To(Equal("network"))
+
+		By("referencing another network")
+		attachment.NetworkInterfaces[1].Spec.NetworkRef.Name = "other"
+		Expect(attachment.Validate()).To(MatchError(ContainSubstring("networkInterfaces[1].spec.networkRef.name")))
+
+		By("overlapping a prefix with an ip of another interface on the same node")
+		attachment.NetworkInterfaces[1].Spec.NetworkRef.Name = "network"
+		attachment.NetworkInterfaces[1].Spec.Prefixes = []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.0.0/24")}
+		Expect(attachment.Validate()).To(MatchError(ContainSubstring("conflicts with network interface nic-1")))
+
+		By("using the same virtual ip twice")
+		attachment.NetworkInterfaces[1].Spec.Prefixes = nil
+		attachment.NetworkInterfaces[0].Spec.VirtualIP = metalnetv1alpha1.MustParseNewIP("192.168.0.1")
+		attachment.NetworkInterfaces[1].Spec.VirtualIP = metalnetv1alpha1.MustParseNewIP("192.168.0.1")
+		Expect(attachment.Validate()).To(MatchError(ContainSubstring("networkInterfaces[1].spec.virtualIP")))
+	})
+
+	It("should create and update the objects of the attachment", func(ctx SpecContext) {
+		existing := newNetworkInterface("nic-1", "10.0.0.1")
+		existing.Namespace = "default"
+		existing.Spec.NetworkRef.Name = "network"
+		existing.Labels = map[string]string{"foo": "bar"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+
+		nic := newNetworkInterface("nic-1", "10.0.0.3")
+		nic.Labels = map[string]string{"baz": "qux"}
+		Expect(metalnetclient.ApplyNetworkAttachment(ctx, c, newAttachment(nic, newNetworkInterface("nic-2", "10.0.0.2")))).To(Succeed())
+
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "network"}, &metalnetv1alpha1.Network{})).To(Succeed())
+		updated := &metalnetv1alpha1.NetworkInterface{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "nic-1"}, updated)).To(Succeed())
+		Expect(updated.Spec.IPs).To(Equal([]metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.3")}))
+		Expect(updated.Labels).To(Equal(map[string]string{"foo": "bar", "baz": "qux"}))
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "nic-2"}, &metalnetv1alpha1.NetworkInterface{})).To(Succeed())
+	})
+
+	It("should roll back the attachment if a write fails", func(ctx SpecContext) {
+		existing := newNetworkInterface("nic-1", "10.0.0.1")
+		existing.Namespace = "default"
+		existing.Spec.NetworkRef.Name = "network"
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == "nic-2" && len(opts) == 0 {
+					return errors.New("injected error")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
+		err := metalnetclient.ApplyNetworkAttachment(ctx, c, newAttachment(newNetworkInterface("nic-1", "10.0.0.3"), newNetworkInterface("nic-2", "10.0.0.2")))
+		Expect(err).To(MatchError(ContainSubstring("injected error")))
+
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "network"}, &metalnetv1alpha1.Network{})).To(Satisfy(func(err error) bool {
+			return client.IgnoreNotFound(err) == nil && err != nil
+		}))
+		nic := &metalnetv1alpha1.NetworkInterface{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "nic-1"}, nic)).To(Succeed())
+		Expect(nic.Spec.IPs).To(Equal([]metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")}))
+	})
+})
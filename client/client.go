@@ -11,8 +11,10 @@ import (
 )
 
 const (
-	NetworkInterfaceNetworkRefNameField = ".spec.networkRef.name"
-	LoadBalancerNetworkRefNameField     = ".spec.networkRef.name"
+	NetworkInterfaceNetworkRefNameField         = ".spec.networkRef.name"
+	NetworkInterfaceClusterNetworkRefNameField  = ".spec.clusterNetworkRef.name"
+	NetworkInterfaceInternetGatewayRefNameField = ".spec.internetGatewayRef.name"
+	LoadBalancerNetworkRefNameField             = ".spec.networkRef.name"
 )
 
 func SetupNetworkInterfaceNetworkRefNameFieldIndexer(ctx context.Context, indexer client.FieldIndexer) error {
@@ -22,6 +24,26 @@ func SetupNetworkInterfaceNetworkRefNameFieldIndexer(ctx context.Context, indexe
 	})
 }
 
+func SetupNetworkInterfaceClusterNetworkRefNameFieldIndexer(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &metalnetv1alpha1.NetworkInterface{}, NetworkInterfaceClusterNetworkRefNameField, func(obj client.Object) []string {
+		nic := obj.(*metalnetv1alpha1.NetworkInterface)
+		if nic.Spec.ClusterNetworkRef == nil {
+			return nil
+		}
+		return []string{nic.Spec.ClusterNetworkRef.Name}
+	})
+}
+
+func SetupNetworkInterfaceInternetGatewayRefNameFieldIndexer(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &metalnetv1alpha1.NetworkInterface{}, NetworkInterfaceInternetGatewayRefNameField, func(obj client.Object) []string {
+		nic := obj.(*metalnetv1alpha1.NetworkInterface)
+		if nic.Spec.InternetGatewayRef == nil {
+			return nil
+		}
+		return []string{nic.Spec.InternetGatewayRef.Name}
+	})
+}
+
 func SetupLoadBalancerNetworkRefNameFieldIndexer(ctx context.Context, indexer client.FieldIndexer) error {
 	return indexer.IndexField(ctx, &metalnetv1alpha1.LoadBalancer{}, LoadBalancerNetworkRefNameField, func(obj client.Object) []string {
 		lb := obj.(*metalnetv1alpha1.LoadBalancer)
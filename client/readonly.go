@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrReadOnly is returned for the writes refused by a read-only client.
+var ErrReadOnly = errors.New("metalnet runs in read-only compatibility mode")
+
+// NewReadOnlyClient returns a client refusing all writes with ErrReadOnly, e.g. while the installed
+// CustomResourceDefinitions are incompatible, so no object is written with fields the API server would drop.
+// Patches that do not change anything succeed, so objects that are up to date are still reconciled.
+func NewReadOnlyClient(c client.Client) client.Client {
+	return &readOnlyClient{Client: c}
+}
+
+type readOnlyClient struct {
+	client.Client
+}
+
+func (c *readOnlyClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	return refuse("create", obj)
+}
+
+func (c *readOnlyClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return refuse("update", obj)
+}
+
+func (c *readOnlyClient) Patch(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+	return refusePatch(obj, patch)
+}
+
+func (c *readOnlyClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	return refuse("delete", obj)
+}
+
+func (c *readOnlyClient) DeleteAllOf(_ context.Context, obj client.Object, _ ...client.DeleteAllOfOption) error {
+	return refuse("delete", obj)
+}
+
+func (c *readOnlyClient) Status() client.SubResourceWriter {
+	return readOnlySubResourceWriter{}
+}
+
+func (c *readOnlyClient) SubResource(subResource string) client.SubResourceClient {
+	return readOnlySubResourceClient{SubResourceClient: c.Client.SubResource(subResource)}
+}
+
+type readOnlySubResourceClient struct {
+	client.SubResourceClient
+	readOnlySubResourceWriter
+}
+
+func (c readOnlySubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return c.readOnlySubResourceWriter.Create(ctx, obj, subResource, opts...)
+}
+
+func (c readOnlySubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return c.readOnlySubResourceWriter.Update(ctx, obj, opts...)
+}
+
+func (c readOnlySubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return c.readOnlySubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+type readOnlySubResourceWriter struct{}
+
+func (readOnlySubResourceWriter) Create(_ context.Context, obj, _ client.Object, _ ...client.SubResourceCreateOption) error {
+	return refuse("create subresource of", obj)
+}
+
+func (readOnlySubResourceWriter) Update(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+	return refuse("update status of", obj)
+}
+
+func (readOnlySubResourceWriter) Patch(_ context.Context, obj client.Object, patch client.Patch, _ ...client.SubResourcePatchOption) error {
+	return refusePatch(obj, patch)
+}
+
+func refuse(verb string, obj client.Object) error {
+	return fmt.Errorf("refusing to %s %T %s: %w", verb, obj, client.ObjectKeyFromObject(obj), ErrReadOnly)
+}
+
+// refusePatch refuses the patch unless it does not change anything but the resource version it is based on.
+func refusePatch(obj client.Object, patch client.Patch) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	var fields map[string]any
+	if json.Unmarshal(data, &fields) == nil {
+		if metadata, ok := fields["metadata"].(map[string]any); ok {
+			delete(metadata, "resourceVersion")
+			if len(metadata) == 0 {
+				delete(fields, "metadata")
+			}
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+	}
+	return refuse("patch", obj)
+}
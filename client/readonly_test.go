@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ReadOnlyClient", func() {
+	var (
+		c       client.Client
+		network *metalnetv1alpha1.Network
+	)
+
+	BeforeEach(func() {
+		network = &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c = metalnetclient.NewReadOnlyClient(fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.Network{}).
+			WithObjects(network).
+			Build())
+	})
+
+	It("should read objects", func(ctx SpecContext) {
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(network), network)).To(Succeed())
+		Expect(network.Spec.ID).To(Equal(int32(100)))
+	})
+
+	It("should refuse writes", func(ctx SpecContext) {
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(network), network)).To(Succeed())
+
+		Expect(c.Create(ctx, &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+		})).To(MatchError(metalnetclient.ErrReadOnly))
+		Expect(c.Delete(ctx, network)).To(MatchError(metalnetclient.ErrReadOnly))
+
+		base := network.DeepCopy()
+		network.Spec.ID = 101
+		Expect(c.Update(ctx, network)).To(MatchError(metalnetclient.ErrReadOnly))
+		Expect(c.Patch(ctx, network, client.MergeFrom(base))).To(MatchError(metalnetclient.ErrReadOnly))
+		Expect(c.Status().Update(ctx, network)).To(MatchError(metalnetclient.ErrReadOnly))
+	})
+
+	It("should accept patches without changes", func(ctx SpecContext) {
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(network), network)).To(Succeed())
+
+		Expect(c.Patch(ctx, network, client.MergeFrom(network.DeepCopy()))).To(Succeed())
+		Expect(c.Status().Patch(ctx, network, client.MergeFromWithOptions(network.DeepCopy(), client.MergeFromWithOptimisticLock{}))).To(Succeed())
+	})
+})
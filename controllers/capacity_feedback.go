@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var capacityBlockedObjects = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "metalnet_capacity_blocked_objects",
+	Help: "Number of objects of the node that cannot be fully programmed because a dpservice table is full.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(capacityBlockedObjects)
+}
+
+// capacityRequeueInterval is the interval an object that could not be programmed because a dpservice table is
+// full is retried at, instead of backing off from the usual error retries.
+const capacityRequeueInterval = time.Minute
+
+// capacityError returns the first of the given errors caused by a full dpservice table and whether all
+// errors are caused by full tables.
+func capacityError(errs []error) (error, bool) {
+	var capacityErr error
+	only := len(errs) > 0
+	for _, err := range errs {
+		if !metalnetdpdk.IsCapacityError(err) {
+			only = false
+			continue
+		}
+		if capacityErr == nil {
+			capacityErr = err
+		}
+	}
+	return capacityErr, only
+}
+
+// CapacityFeedbackMode defines how a node whose dpservice tables are full is marked for other components.
+type CapacityFeedbackMode string
+
+const (
+	// CapacityFeedbackNone only reports the capacity on the objects and in the metrics.
+	CapacityFeedbackNone CapacityFeedbackMode = "none"
+	// CapacityFeedbackCondition sets the NetworkCapacityExceeded condition of the Node.
+	CapacityFeedbackCondition CapacityFeedbackMode = "condition"
+	// CapacityFeedbackTaint sets the NetworkCapacityExceeded condition and the capacity exceeded taint of the Node.
+	CapacityFeedbackTaint CapacityFeedbackMode = "taint"
+)
+
+// CapacityFeedback tracks the objects of the node that cannot be fully programmed because a dpservice table
+// is full, and marks the Node of this node while there are any, so no further machines are placed on it.
+type CapacityFeedback struct {
+	client   client.Client
+	nodeName string
+	mode     CapacityFeedbackMode
+	log      logr.Logger
+
+	mu      sync.Mutex
+	blocked map[types.UID]struct{}
+	changed chan struct{}
+}
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups="",resources=nodes/status,verbs=get;patch
+
+func NewCapacityFeedback(c client.Client, nodeName string, mode CapacityFeedbackMode) *CapacityFeedback {
+	return &CapacityFeedback{
+		client:   c,
+		nodeName: nodeName,
+		mode:     mode,
+		log:      ctrl.Log.WithName("capacity-feedback"),
+		blocked:  make(map[types.UID]struct{}),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Report records whether the object with the given UID is blocked by a full dpservice table.
+func (f *CapacityFeedback) Report(uid types.UID, exceeded bool) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, wasBlocked := f.blocked[uid]
+	if exceeded == wasBlocked {
+		return
+	}
+	if exceeded {
+		f.blocked[uid] = struct{}{}
+	} else {
+		delete(f.blocked, uid)
+	}
+	capacityBlockedObjects.Set(float64(len(f.blocked)))
+
+	select {
+	case f.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Exceeded reports whether any object of the node is blocked by a full dpservice table.
+func (f *CapacityFeedback) Exceeded() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.blocked) > 0
+}
+
+// Start updates the Node whenever the capacity state changes and resyncs it periodically. It implements
+// manager.Runnable.
+func (f *CapacityFeedback) Start(ctx context.Context) error {
+	if f.mode == CapacityFeedbackNone {
+		return nil
+	}
+
+	ticker := time.NewTicker(capacityRequeueInterval)
+	defer ticker.Stop()
+	for {
+		if err := f.syncNode(ctx); err != nil {
+			f.log.Error(err, "Error updating node capacity state")
+		}
+
+		select {
+		case <-f.changed:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The lease is per node, so the active metalnet
+// instance of every node marks its node.
+func (f *CapacityFeedback) NeedLeaderElection() bool {
+	return true
+}
+
+func (f *CapacityFeedback) syncNode(ctx context.Context) error {
+	exceeded := f.Exceeded()
+
+	node := &corev1.Node{}
+	if err := f.client.Get(ctx, client.ObjectKey{Name: f.nodeName}, node); err != nil {
+		return fmt.Errorf("error getting node %s: %w", f.nodeName, err)
+	}
+
+	if condition, changed := capacityNodeCondition(node, exceeded); changed {
+		base := node.DeepCopy()
+		setNodeCondition(&node.Status.Conditions, condition)
+		if err := f.client.Status().Patch(ctx, node, client.StrategicMergeFrom(base)); err != nil {
+			return fmt.Errorf("error patching node status: %w", err)
+		}
+		f.log.Info("Updated node capacity condition", "Exceeded", exceeded)
+	}
+
+	if f.mode != CapacityFeedbackTaint {
+		return nil
+	}
+	base := node.DeepCopy()
+	if !setNodeTaint(node, metalnetv1alpha1.NetworkCapacityExceededTaint, exceeded) {
+		return nil
+	}
+	if err := f.client.Patch(ctx, node, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("error patching node taints: %w", err)
+	}
+	f.log.Info("Updated node capacity taint", "Exceeded", exceeded)
+	return nil
+}
+
+// capacityNodeCondition returns the capacity condition of the node and whether it differs from the current one.
+func capacityNodeCondition(node *corev1.Node, exceeded bool) (corev1.NodeCondition, bool) {
+	condition := corev1.NodeCondition{
+		Type:    metalnetv1alpha1.NodeNetworkCapacityExceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  metalnetv1alpha1.CapacityReasonAvailable,
+		Message: "All network objects of the node are programmed",
+	}
+	if exceeded {
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = metalnetv1alpha1.CapacityReasonTableFull
+		condition.Message = "A dpservice table of the node is full, network objects cannot be programmed"
+	}
+	for _, existing := range node.Status.Conditions {
+		if existing.Type == condition.Type {
+			return condition, existing.Status != condition.Status || existing.Reason != condition.Reason
+		}
+	}
+	return condition, true
+}
+
+func setNodeCondition(conditions *[]corev1.NodeCondition, condition corev1.NodeCondition) {
+	now := metav1.Now()
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+	for i, existing := range *conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		(*conditions)[i] = condition
+		return
+	}
+	*conditions = append(*conditions, condition)
+}
+
+// setNodeTaint adds or removes the NoSchedule taint with the given key and reports whether the taints changed.
+func setNodeTaint(node *corev1.Node, key string, present bool) bool {
+	for i, taint := range node.Spec.Taints {
+		if taint.Key != key {
+			continue
+		}
+		if present {
+			return false
+		}
+		node.Spec.Taints = append(node.Spec.Taints[:i], node.Spec.Taints[i+1:]...)
+		return true
+	}
+	if !present {
+		return false
+	}
+	node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+		Key:    key,
+		Effect: corev1.TaintEffectNoSchedule,
+	})
+	return true
+}
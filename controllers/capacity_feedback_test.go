@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Capacity feedback", func() {
+	tableFull := fmt.Errorf("error creating route: %w", dpdkerrors.NewStatusError(dpdkerrors.LIMIT_REACHED, "limit reached"))
+
+	It("should tell capacity errors apart from other errors", func() {
+		err, only := capacityError(nil)
+		Expect(err).To(BeNil())
+		Expect(only).To(BeFalse())
+
+		err, only = capacityError([]error{errors.New("boom"), tableFull})
+		Expect(err).To(Equal(tableFull))
+		Expect(only).To(BeFalse())
+
+		err, only = capacityError([]error{tableFull, fmt.Errorf("[prefix 10.0.0.0/24] %w", dpdkerrors.NewStatusError(dpdkerrors.OUT_OF_MEMORY, "no memory"))})
+		Expect(err).To(Equal(tableFull))
+		Expect(only).To(BeTrue())
+	})
+
+	It("should taint the node while network interfaces are blocked by full tables", func(ctx SpecContext) {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&corev1.Node{}).WithObjects(node).Build()
+
+		feedback := NewCapacityFeedback(c, "node", CapacityFeedbackTaint)
+		feedbackCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(feedback.Start(feedbackCtx)).To(Succeed())
+		}()
+
+		capacityReason := func() string {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == metalnetv1alpha1.NodeNetworkCapacityExceeded {
+					return condition.Reason
+				}
+			}
+			return ""
+		}
+		capacityTaints := func() []corev1.Taint {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			return node.Spec.Taints
+		}
+		capacityTaint := corev1.Taint{
+			Key:    metalnetv1alpha1.NetworkCapacityExceededTaint,
+			Effect: corev1.TaintEffectNoSchedule,
+		}
+
+		By("reporting available capacity initially")
+		Eventually(capacityReason).Should(Equal(metalnetv1alpha1.CapacityReasonAvailable))
+		Expect(capacityTaints()).To(BeEmpty())
+
+		By("blocking two network interfaces")
+		feedback.Report("nic-1", true)
+		feedback.Report("nic-2", true)
+		Eventually(capacityReason).Should(Equal(metalnetv1alpha1.CapacityReasonTableFull))
+		Eventually(capacityTaints).Should(ConsistOf(capacityTaint))
+
+		By("unblocking one of them")
+		feedback.Report("nic-1", false)
+		Consistently(capacityTaints).Should(ConsistOf(capacityTaint))
+
+		By("unblocking the other one")
+		feedback.Report("nic-2", false)
+		Eventually(capacityTaints).Should(BeEmpty())
+		Expect(capacityReason()).To(Equal(metalnetv1alpha1.CapacityReasonAvailable))
+	})
+})
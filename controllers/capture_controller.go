@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/capture"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// capturePendingRequeueInterval is the interval a requested capture is retried at while another
+// capture is running on the node.
+const capturePendingRequeueInterval = 5 * time.Second
+
+// PacketCaptureReconciler captures the packets of local NetworkInterfaces carrying the capture annotation
+// and reports the resulting pcap file in their status.
+type PacketCaptureReconciler struct {
+	client.Client
+
+	DPDK dpdkclient.Client
+	// Captures runs the captures. If nil, requested captures are reported as failed.
+	Captures *capture.Manager
+
+	NodeName string
+}
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces/status,verbs=get;update;patch
+
+func (r *PacketCaptureReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	id := fmt.Sprintf("%s_%s", req.Namespace, req.Name)
+
+	nic := &metalnetv1alpha1.NetworkInterface{}
+	if err := r.Get(ctx, req.NamespacedName, nic); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.forget(id)
+		return ctrl.Result{}, nil
+	}
+
+	value, ok := nic.Annotations[metalnetv1alpha1.CaptureAnnotation]
+	if !ok || nic.Spec.NodeName == nil || *nic.Spec.NodeName != r.NodeName || !nic.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return r.reconcileCapture(ctx, log, nic, id, value)
+}
+
+func (r *PacketCaptureReconciler) forget(id string) {
+	if r.Captures != nil {
+		r.Captures.Forget(id)
+	}
+}
+
+func (r *PacketCaptureReconciler) reconcileCapture(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, id, value string) (ctrl.Result, error) {
+	if r.Captures == nil {
+		return ctrl.Result{}, r.finishCapture(ctx, nic, id, &metalnetv1alpha1.PacketCaptureStatus{
+			State:   metalnetv1alpha1.PacketCaptureStateFailed,
+			Message: "Packet capture is not configured on this node",
+		})
+	}
+
+	result, err := r.Captures.Get(id)
+	if err != nil {
+		if !errors.Is(err, capture.ErrNotFound) {
+			return ctrl.Result{}, fmt.Errorf("error getting capture: %w", err)
+		}
+		if status := nic.Status.Capture; status != nil && status.State == metalnetv1alpha1.PacketCaptureStateRunning {
+			log.V(1).Info("Capture was interrupted by a restart, reporting failure")
+			return ctrl.Result{}, r.finishCapture(ctx, nic, id, &metalnetv1alpha1.PacketCaptureStatus{
+				State:     metalnetv1alpha1.PacketCaptureStateFailed,
+				Path:      status.Path,
+				StartTime: status.StartTime,
+				Message:   "Capture was interrupted by a restart of metalnet",
+			})
+		}
+
+		return r.startCapture(ctx, log, nic, id, value)
+	}
+
+	if !result.Done() {
+		log.V(1).Info("Capture is running")
+		return ctrl.Result{RequeueAfter: time.Until(result.StartTime.Add(r.Captures.MaxDuration()))}, nil
+	}
+
+	log.V(1).Info("Capture is done, reporting result")
+	status := &metalnetv1alpha1.PacketCaptureStatus{
+		State:          metalnetv1alpha1.PacketCaptureStateCompleted,
+		Path:           result.Path,
+		Packets:        int32(result.Packets),
+		StartTime:      &metav1.Time{Time: result.StartTime},
+		CompletionTime: &metav1.Time{Time: result.CompletionTime},
+	}
+	if result.Err != nil {
+		status.State = metalnetv1alpha1.PacketCaptureStateFailed
+		status.Message = result.Err.Error()
+	}
+	if err := r.finishCapture(ctx, nic, id, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.V(1).Info("Reported capture result")
+	return ctrl.Result{}, nil
+}
+
+func (r *PacketCaptureReconciler) startCapture(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, id, value string) (ctrl.Result, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return ctrl.Result{}, r.finishCapture(ctx, nic, id, &metalnetv1alpha1.PacketCaptureStatus{
+			State:   metalnetv1alpha1.PacketCaptureStateFailed,
+			Message: fmt.Sprintf("Invalid capture duration %q", value),
+		})
+	}
+	if maxDuration := r.Captures.MaxDuration(); duration > maxDuration {
+		duration = maxDuration
+	}
+
+	log.V(1).Info("Getting dpdk interface")
+	iface, err := r.DPDK.GetInterface(ctx, string(nic.UID))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error getting dpdk interface: %w", err)
+	}
+
+	log.V(1).Info("Starting capture", "VirtualFunction", iface.Spec.VirtualFunction.Name, "Duration", duration)
+	result, err := r.Captures.Start(ctx, id, iface.Spec.VirtualFunction.Name, duration)
+	if err != nil {
+		if !errors.Is(err, capture.ErrBusy) {
+			return ctrl.Result{}, fmt.Errorf("error starting capture: %w", err)
+		}
+
+		log.V(1).Info("Another capture is running, retrying later")
+		if err := r.patchCaptureStatus(ctx, nic, &metalnetv1alpha1.PacketCaptureStatus{
+			State:   metalnetv1alpha1.PacketCaptureStatePending,
+			Message: "Waiting for another capture on the node to finish",
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: capturePendingRequeueInterval}, nil
+	}
+	log.V(1).Info("Started capture", "Path", result.Path)
+
+	if err := r.patchCaptureStatus(ctx, nic, &metalnetv1alpha1.PacketCaptureStatus{
+		State:     metalnetv1alpha1.PacketCaptureStateRunning,
+		Path:      result.Path,
+		StartTime: &metav1.Time{Time: result.StartTime},
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: duration}, nil
+}
+
+// finishCapture reports the capture status and removes the capture annotation so a new capture can be requested.
+func (r *PacketCaptureReconciler) finishCapture(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, id string, status *metalnetv1alpha1.PacketCaptureStatus) error {
+	if err := r.patchCaptureStatus(ctx, nic, status); err != nil {
+		return err
+	}
+
+	base := nic.DeepCopy()
+	delete(nic.Annotations, metalnetv1alpha1.CaptureAnnotation)
+	if err := r.Patch(ctx, nic, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("error removing capture annotation: %w", err)
+	}
+
+	r.forget(id)
+	return nil
+}
+
+func (r *PacketCaptureReconciler) patchCaptureStatus(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, status *metalnetv1alpha1.PacketCaptureStatus) error {
+	base := nic.DeepCopy()
+	nic.Status.Capture = status
+	if err := r.Status().Patch(ctx, nic, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("error patching capture status: %w", err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PacketCaptureReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("packetcapture").
+		For(
+			&metalnetv1alpha1.NetworkInterface{},
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				_, ok := obj.GetAnnotations()[metalnetv1alpha1.CaptureAnnotation]
+				return ok
+			})),
+		).
+		Complete(r)
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/capture"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Packet capture", Label("network-interface"), func() {
+	It("should report a capture interrupted by a restart as failed", func(ctx SpecContext) {
+		startTime := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "nic",
+				Annotations: map[string]string{metalnetv1alpha1.CaptureAnnotation: "30s"},
+			},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{NodeName: ptr.To("node")},
+			Status: metalnetv1alpha1.NetworkInterfaceStatus{
+				Capture: &metalnetv1alpha1.PacketCaptureStatus{
+					State:     metalnetv1alpha1.PacketCaptureStateRunning,
+					Path:      "/captures/default_nic.pcap",
+					StartTime: &startTime,
+				},
+			},
+		}
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(nic).
+			Build()
+
+		// The manager of the restarted process does not know the capture.
+		r := &PacketCaptureReconciler{
+			Client: c,
+			Captures: capture.NewManager(logr.Discard(), nil, capture.Options{
+				Dir:         GinkgoT().TempDir(),
+				SinkAddress: netip.MustParseAddr("fd00::1"),
+			}),
+			NodeName: "node",
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Annotations).NotTo(HaveKey(metalnetv1alpha1.CaptureAnnotation))
+		Expect(nic.Status.Capture).To(Equal(&metalnetv1alpha1.PacketCaptureStatus{
+			State:     metalnetv1alpha1.PacketCaptureStateFailed,
+			Path:      "/captures/default_nic.pcap",
+			StartTime: &startTime,
+			Message:   "Capture was interrupted by a restart of metalnet",
+		}))
+	})
+})
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterNetworks are reconciled and consumed as Networks without namespace: the key of a ClusterNetwork is
+// a Network key with an empty namespace.
+
+// networkInterfaceNetworkKey returns the key of the Network or ClusterNetwork the NetworkInterface is
+// connected to.
+func networkInterfaceNetworkKey(nic *metalnetv1alpha1.NetworkInterface) client.ObjectKey {
+	if ref := nic.Spec.ClusterNetworkRef; ref != nil {
+		return client.ObjectKey{Name: ref.Name}
+	}
+	return client.ObjectKey{Namespace: nic.Namespace, Name: nic.Spec.NetworkRef.Name}
+}
+
+// newNetworkObject returns an empty ClusterNetwork for a key without namespace and an empty Network otherwise.
+func newNetworkObject(key client.ObjectKey) client.Object {
+	if key.Namespace == "" {
+		return &metalnetv1alpha1.ClusterNetwork{}
+	}
+	return &metalnetv1alpha1.Network{}
+}
+
+// networkOf returns the given Network or ClusterNetwork as Network. The Network of a ClusterNetwork shares
+// its metadata and spec and has no namespace.
+func networkOf(obj client.Object) *metalnetv1alpha1.Network {
+	switch obj := obj.(type) {
+	case *metalnetv1alpha1.Network:
+		return obj
+	case *metalnetv1alpha1.ClusterNetwork:
+		return &metalnetv1alpha1.Network{
+			ObjectMeta: obj.ObjectMeta,
+			Spec:       obj.Spec,
+			Status:     obj.Status,
+		}
+	default:
+		panic(fmt.Sprintf("unexpected network object %T", obj))
+	}
+}
+
+// networkStatusOf returns the status of the given Network or ClusterNetwork.
+func networkStatusOf(obj client.Object) *metalnetv1alpha1.NetworkStatus {
+	switch obj := obj.(type) {
+	case *metalnetv1alpha1.Network:
+		return &obj.Status
+	case *metalnetv1alpha1.ClusterNetwork:
+		return &obj.Status
+	default:
+		panic(fmt.Sprintf("unexpected network object %T", obj))
+	}
+}
+
+// getNetwork gets the Network or, for a key without namespace, the ClusterNetwork with the given key.
+func getNetwork(ctx context.Context, c client.Reader, key client.ObjectKey) (*metalnetv1alpha1.Network, error) {
+	obj := newNetworkObject(key)
+	if err := c.Get(ctx, key, obj); err != nil {
+		return nil, err
+	}
+	return networkOf(obj), nil
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ClusterNetwork", func() {
+	It("should get the network of network interfaces in namespaced and cluster networks", func(ctx SpecContext) {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			&metalnetv1alpha1.Network{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "storage"},
+				Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+			},
+			&metalnetv1alpha1.ClusterNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+				Spec:       metalnetv1alpha1.NetworkSpec{ID: 500},
+			},
+		).Build()
+
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "nic"},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "storage"},
+			},
+		}
+		Expect(networkInterfaceNetworkKey(nic)).To(Equal(client.ObjectKey{Namespace: "tenant", Name: "storage"}))
+		network, err := getNetwork(ctx, c, networkInterfaceNetworkKey(nic))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(network.Spec.ID).To(Equal(int32(100)))
+
+		By("connecting the network interface to the cluster network")
+		nic.Spec.NetworkRef = corev1.LocalObjectReference{}
+		nic.Spec.ClusterNetworkRef = &corev1.LocalObjectReference{Name: "storage"}
+		Expect(networkInterfaceNetworkKey(nic)).To(Equal(client.ObjectKey{Name: "storage"}))
+		network, err = getNetwork(ctx, c, networkInterfaceNetworkKey(nic))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(network.Namespace).To(BeEmpty())
+		Expect(network.Name).To(Equal("storage"))
+		Expect(network.Spec.ID).To(Equal(int32(500)))
+
+		_, err = getNetwork(ctx, c, client.ObjectKey{Name: "missing"})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
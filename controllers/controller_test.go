@@ -11,6 +11,7 @@ import (
 	. "github.com/ironcore-dev/ironcore/utils/testing"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -20,6 +21,7 @@ import (
 	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
 	dpdk "github.com/ironcore-dev/dpservice-go/proto"
 	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/netfns"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -295,6 +297,8 @@ var _ = Describe("Network Interface and LoadBalancer Controller", func() {
 
 				Expect(updatedIface.Spec.VirtualIP.Addr.String()).To(Equal("10.10.10.10"))
 				Expect(updatedIface.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+				Expect(updatedIface.Status.VirtualIP.Addr.String()).To(Equal("10.10.10.10"))
+				Expect(meta.IsStatusConditionTrue(updatedIface.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)).To(BeTrue())
 
 				// Fetch the dpservice interface object
 				iface, err := dpdkClient.GetInterface(ctx, string(networkInterface.ObjectMeta.UID))
@@ -338,6 +342,8 @@ var _ = Describe("Network Interface and LoadBalancer Controller", func() {
 
 				Expect(updatedIface.Spec.VirtualIP).To(BeNil())
 				Expect(updatedIface.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+				Expect(updatedIface.Status.VirtualIP).To(BeNil())
+				Expect(meta.FindStatusCondition(updatedIface.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)).To(BeNil())
 
 				// Fetch the dpservice interface object
 				iface, err = dpdkClient.GetInterface(ctx, string(networkInterface.ObjectMeta.UID))
@@ -1688,6 +1694,7 @@ func networkReconcile(ctx context.Context, network metalnetv1alpha1.Network) err
 		Client:            k8sClient,
 		DPDK:              dpdkClient,
 		RouteUtil:         metalbondRouteUtil,
+		Subscriptions:     subscriptions,
 		MetalnetCache:     metalnetCache,
 		MetalnetMBClient:  metalnetMBClient,
 		DefaultRouterAddr: &defaultRouterAddr,
@@ -1721,14 +1728,15 @@ func ifaceReconcile(ctx context.Context, networkInterface metalnetv1alpha1.Netwo
 
 	// Create and initialize Network Interface reconciler
 	reconciler := &NetworkInterfaceReconciler{
-		Client:            k8sClient,
-		EventRecorder:     &record.FakeRecorder{},
-		DPDK:              dpdkClient,
-		RouteUtil:         metalbondRouteUtil,
-		NodeName:          testNode,
-		NetFnsManager:     netFnsManager,
-		PublicVNI:         int(defaultRouterAddr.PublicVNI),
-		EnableIPv6Support: enableIPv6Support,
+		Client:               k8sClient,
+		EventRecorder:        &record.FakeRecorder{},
+		DPDK:                 dpdkClient,
+		RouteUtil:            metalbondRouteUtil,
+		AliasPrefixAnnouncer: aliasPrefixes,
+		NodeName:             testNode,
+		DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+		PublicVNI:            int(defaultRouterAddr.PublicVNI),
+		EnableIPv6Support:    enableIPv6Support,
 	}
 
 	// Loop the reconciler until Requeue is false or error occurs
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type objectConvergence struct {
+	uid        types.UID
+	generation int64
+	since      time.Time
+	programmed bool
+}
+
+// ConvergenceTracker measures the time from the change of an object to its routes being announced and its
+// dataplane being programmed. The first generation of an object changes at its creation, later generations
+// when they are first reconciled. Objects created before the tracker are measured from their first reconcile.
+//
+// A nil ConvergenceTracker does not measure.
+type ConvergenceTracker struct {
+	kind    string
+	created time.Time
+
+	mu      sync.Mutex
+	objects map[client.ObjectKey]*objectConvergence
+}
+
+// NewConvergenceTracker measures the convergence of the objects of the given kind.
+func NewConvergenceTracker(kind string) *ConvergenceTracker {
+	return &ConvergenceTracker{
+		kind:    kind,
+		created: time.Now(),
+		objects: make(map[client.ObjectKey]*objectConvergence),
+	}
+}
+
+// Start returns a context measuring the route announcements of the current generation of the object, unless
+// it is already programmed.
+func (t *ConvergenceTracker) Start(ctx context.Context, obj client.Object) context.Context {
+	if t == nil {
+		return ctx
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := client.ObjectKeyFromObject(obj)
+	o, ok := t.objects[key]
+	if !ok || o.uid != obj.GetUID() || o.generation != obj.GetGeneration() {
+		since := time.Now()
+		if created := obj.GetCreationTimestamp().Time; obj.GetGeneration() == 1 && created.After(t.created) {
+			since = created
+		}
+		o = &objectConvergence{uid: obj.GetUID(), generation: obj.GetGeneration(), since: since}
+		t.objects[key] = o
+	}
+	if o.programmed {
+		return ctx
+	}
+	return metalbond.WithConvergenceStart(ctx, t.kind, o.since)
+}
+
+// Programmed records that the current generation of the object is programmed. Only the first call per
+// generation is measured.
+func (t *ConvergenceTracker) Programmed(obj client.Object) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.objects[client.ObjectKeyFromObject(obj)]
+	if !ok || o.programmed || o.uid != obj.GetUID() || o.generation != obj.GetGeneration() {
+		return
+	}
+	o.programmed = true
+	metalbond.ObserveConvergence(t.kind, metalbond.ConvergenceStageProgrammed, o.since)
+}
+
+// Forget drops the object with the given key.
+func (t *ConvergenceTracker) Forget(key client.ObjectKey) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.objects, key)
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var dataplaneAvailable = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "metalnet_dataplane_available",
+	Help: "Whether dpservice is up and connected to a metalbond peer (1) or not (0), as reported on the node.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(dataplaneAvailable)
+}
+
+// dataplaneResyncInterval is the interval the dataplane state of the Node is resynced at, even without changes.
+const dataplaneResyncInterval = time.Minute
+
+// DataplaneFeedbackMode defines how a node whose dataplane is unavailable is marked for other components.
+type DataplaneFeedbackMode string
+
+const (
+	// DataplaneFeedbackNone does not mark the Node.
+	DataplaneFeedbackNone DataplaneFeedbackMode = "none"
+	// DataplaneFeedbackCondition sets the NetworkDataplaneUnavailable condition of the Node.
+	DataplaneFeedbackCondition DataplaneFeedbackMode = "condition"
+	// DataplaneFeedbackTaint sets the NetworkDataplaneUnavailable condition and the dataplane unavailable taint of
+	// the Node.
+	DataplaneFeedbackTaint DataplaneFeedbackMode = "taint"
+)
+
+// DataplaneFeedbackOptions are the options of a DataplaneFeedback.
+type DataplaneFeedbackOptions struct {
+	// DPService returns an error if dpservice is down.
+	DPService func(ctx context.Context) error
+	// MetalbondConnected reports whether a session to any of the metalbond peers is established.
+	MetalbondConnected func() bool
+	// ProbeInterval is the interval the dataplane is probed at. Defaults to ten seconds.
+	ProbeInterval time.Duration
+	// GracePeriod is the time the dataplane has to be down before the Node is marked, so short outages, e.g.
+	// restarts of dpservice or of a metalbond peer, do not mark it. The mark is removed as soon as the dataplane
+	// is up again.
+	GracePeriod time.Duration
+}
+
+// DataplaneFeedback probes dpservice and the metalbond sessions and marks the Node of this node while its
+// dataplane is unavailable, so no further machines depending on the network are placed on it.
+type DataplaneFeedback struct {
+	client   client.Client
+	nodeName string
+	mode     DataplaneFeedbackMode
+	opts     DataplaneFeedbackOptions
+	log      logr.Logger
+}
+
+func NewDataplaneFeedback(c client.Client, nodeName string, mode DataplaneFeedbackMode, opts DataplaneFeedbackOptions) *DataplaneFeedback {
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = 10 * time.Second
+	}
+	return &DataplaneFeedback{
+		client:   c,
+		nodeName: nodeName,
+		mode:     mode,
+		opts:     opts,
+		log:      ctrl.Log.WithName("dataplane-feedback"),
+	}
+}
+
+// dataplaneState is the state of the dataplane as reported on the Node.
+type dataplaneState struct {
+	reason  string
+	message string
+}
+
+func (s dataplaneState) available() bool {
+	return s.reason == metalnetv1alpha1.DataplaneReasonAvailable
+}
+
+// Start probes the dataplane and updates the Node whenever its state changes. It implements manager.Runnable.
+func (f *DataplaneFeedback) Start(ctx context.Context) error {
+	if f.mode == DataplaneFeedbackNone {
+		return nil
+	}
+
+	ticker := time.NewTicker(f.opts.ProbeInterval)
+	defer ticker.Stop()
+
+	var (
+		downSince  time.Time
+		synced     *dataplaneState
+		lastSynced time.Time
+	)
+	for {
+		state := f.probe(ctx)
+		if state.available() {
+			downSince = time.Time{}
+		} else if downSince.IsZero() {
+			downSince = time.Now()
+			f.log.Info("Dataplane is down", "Reason", state.reason, "Message", state.message)
+		}
+
+		// Keep the reported state during the grace period. Nothing is reported before the grace period
+		// passed, so metalbond can connect after a restart.
+		sync := true
+		if !state.available() && time.Since(downSince) < f.opts.GracePeriod {
+			if synced == nil {
+				sync = false
+			} else {
+				state = *synced
+			}
+		}
+		if sync && (synced == nil || state.reason != synced.reason || time.Since(lastSynced) >= dataplaneResyncInterval) {
+			if err := f.syncNode(ctx, state); err != nil {
+				f.log.Error(err, "Error updating node dataplane state")
+			} else {
+				synced = &state
+				lastSynced = time.Now()
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The lease is per node, so the active metalnet
+// instance of every node marks its node.
+func (f *DataplaneFeedback) NeedLeaderElection() bool {
+	return true
+}
+
+func (f *DataplaneFeedback) probe(ctx context.Context) dataplaneState {
+	if err := f.opts.DPService(ctx); err != nil {
+		return dataplaneState{
+			reason:  metalnetv1alpha1.DataplaneReasonDPServiceDown,
+			message: fmt.Sprintf("dpservice of the node is unavailable: %v", err),
+		}
+	}
+	if !f.opts.MetalbondConnected() {
+		return dataplaneState{
+			reason:  metalnetv1alpha1.DataplaneReasonMetalbondDown,
+			message: "The node is not connected to any of its metalbond peers",
+		}
+	}
+	return dataplaneState{
+		reason:  metalnetv1alpha1.DataplaneReasonAvailable,
+		message: "dpservice of the node is up and connected to a metalbond peer",
+	}
+}
+
+func (f *DataplaneFeedback) syncNode(ctx context.Context, state dataplaneState) error {
+	if state.available() {
+		dataplaneAvailable.Set(1)
+	} else {
+		dataplaneAvailable.Set(0)
+	}
+
+	node := &corev1.Node{}
+	if err := f.client.Get(ctx, client.ObjectKey{Name: f.nodeName}, node); err != nil {
+		return fmt.Errorf("error getting node %s: %w", f.nodeName, err)
+	}
+
+	if condition, changed := dataplaneNodeCondition(node, state); changed {
+		base := node.DeepCopy()
+		setNodeCondition(&node.Status.Conditions, condition)
+		if err := f.client.Status().Patch(ctx, node, client.StrategicMergeFrom(base)); err != nil {
+			return fmt.Errorf("error patching node status: %w", err)
+		}
+		f.log.Info("Updated node dataplane condition", "Reason", state.reason)
+	}
+
+	if f.mode != DataplaneFeedbackTaint {
+		return nil
+	}
+	base := node.DeepCopy()
+	if !setNodeTaint(node, metalnetv1alpha1.NetworkDataplaneUnavailableTaint, !state.available()) {
+		return nil
+	}
+	if err := f.client.Patch(ctx, node, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("error patching node taints: %w", err)
+	}
+	f.log.Info("Updated node dataplane taint", "Available", state.available())
+	return nil
+}
+
+// dataplaneNodeCondition returns the dataplane condition of the node and whether it differs from the current one.
+func dataplaneNodeCondition(node *corev1.Node, state dataplaneState) (corev1.NodeCondition, bool) {
+	condition := corev1.NodeCondition{
+		Type:    metalnetv1alpha1.NodeNetworkDataplaneUnavailable,
+		Status:  corev1.ConditionTrue,
+		Reason:  state.reason,
+		Message: state.message,
+	}
+	if state.available() {
+		condition.Status = corev1.ConditionFalse
+	}
+	for _, existing := range node.Status.Conditions {
+		if existing.Type == condition.Type {
+			return condition, existing.Status != condition.Status || existing.Reason != condition.Reason
+		}
+	}
+	return condition, true
+}
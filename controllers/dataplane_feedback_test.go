@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Dataplane feedback", func() {
+	It("should taint the node while dpservice or all metalbond peers are down", func(ctx SpecContext) {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&corev1.Node{}).WithObjects(node).Build()
+
+		var dpserviceDown, metalbondDown atomic.Bool
+		feedback := NewDataplaneFeedback(c, "node", DataplaneFeedbackTaint, DataplaneFeedbackOptions{
+			DPService: func(context.Context) error {
+				if dpserviceDown.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			MetalbondConnected: func() bool { return !metalbondDown.Load() },
+			ProbeInterval:      10 * time.Millisecond,
+		})
+		feedbackCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(feedback.Start(feedbackCtx)).To(Succeed())
+		}()
+
+		dataplaneReason := func() string {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == metalnetv1alpha1.NodeNetworkDataplaneUnavailable {
+					return condition.Reason
+				}
+			}
+			return ""
+		}
+		dataplaneTaints := func() []corev1.Taint {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			return node.Spec.Taints
+		}
+		dataplaneTaint := corev1.Taint{
+			Key:    metalnetv1alpha1.NetworkDataplaneUnavailableTaint,
+			Effect: corev1.TaintEffectNoSchedule,
+		}
+
+		By("reporting an available dataplane initially")
+		Eventually(dataplaneReason).Should(Equal(metalnetv1alpha1.DataplaneReasonAvailable))
+		Expect(dataplaneTaints()).To(BeEmpty())
+
+		By("losing all metalbond peers")
+		metalbondDown.Store(true)
+		Eventually(dataplaneReason).Should(Equal(metalnetv1alpha1.DataplaneReasonMetalbondDown))
+		Eventually(dataplaneTaints).Should(ConsistOf(dataplaneTaint))
+
+		By("losing dpservice as well")
+		dpserviceDown.Store(true)
+		Eventually(dataplaneReason).Should(Equal(metalnetv1alpha1.DataplaneReasonDPServiceDown))
+		Expect(dataplaneTaints()).To(ConsistOf(dataplaneTaint))
+
+		By("recovering")
+		dpserviceDown.Store(false)
+		metalbondDown.Store(false)
+		Eventually(dataplaneTaints).Should(BeEmpty())
+		Expect(dataplaneReason()).To(Equal(metalnetv1alpha1.DataplaneReasonAvailable))
+	})
+
+	It("should not mark the node during the grace period", func(ctx SpecContext) {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&corev1.Node{}).WithObjects(node).Build()
+
+		feedback := NewDataplaneFeedback(c, "node", DataplaneFeedbackCondition, DataplaneFeedbackOptions{
+			DPService:          func(context.Context) error { return nil },
+			MetalbondConnected: func() bool { return false },
+			ProbeInterval:      10 * time.Millisecond,
+			GracePeriod:        200 * time.Millisecond,
+		})
+		feedbackCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(feedback.Start(feedbackCtx)).To(Succeed())
+		}()
+
+		nodeConditions := func() []corev1.NodeCondition {
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			return node.Status.Conditions
+		}
+		Consistently(nodeConditions, 100*time.Millisecond).Should(BeEmpty())
+		Eventually(nodeConditions).Should(ConsistOf(HaveField("Reason", metalnetv1alpha1.DataplaneReasonMetalbondDown)))
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+})
@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// endpointSliceTargetEventBufferSize is the number of NetworkInterface events buffered for the
+// NetworkInterface controller.
+const endpointSliceTargetEventBufferSize = 1024
+
+// EndpointSliceTargetReconciler discovers the targets of LoadBalancers with EndpointSliceTargets from the
+// EndpointSlices of the workload cluster.
+//
+// The ready endpoints of the selected Service are matched against the ips and prefixes of the
+// NetworkInterfaces on this node that are in the Network of the LoadBalancer. The ip of the LoadBalancer
+// becomes a target of every matching NetworkInterface. The NetworkInterface controller programs and
+// announces the discovered targets like the ones listed in the NetworkInterface spec, and withdraws them
+// once the endpoints are gone.
+type EndpointSliceTargetReconciler struct {
+	client.Client
+
+	// Workload reads the EndpointSlices of the workload cluster.
+	Workload client.Reader
+
+	NodeName string
+
+	mu sync.RWMutex
+	// targets maps the LoadBalancers to the NetworkInterfaces they target and the target prefix.
+	targets map[types.NamespacedName]map[types.NamespacedName]netip.Prefix
+
+	eventsOnce sync.Once
+	events     chan event.GenericEvent
+}
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch
+
+// Targets returns the discovered load balancer targets of the NetworkInterface with the given key.
+// It is safe to call on a nil reconciler.
+func (r *EndpointSliceTargetReconciler) Targets(nicKey types.NamespacedName) []netip.Prefix {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var prefixes []netip.Prefix
+	for _, nics := range r.targets {
+		if prefix, ok := nics[nicKey]; ok && !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	slices.SortFunc(prefixes, func(a, b netip.Prefix) int {
+		return a.Addr().Compare(b.Addr())
+	})
+	return prefixes
+}
+
+// NetworkInterfaceEvents returns the channel the NetworkInterfaces with changed targets are sent to.
+func (r *EndpointSliceTargetReconciler) NetworkInterfaceEvents() <-chan event.GenericEvent {
+	return r.eventChannel()
+}
+
+func (r *EndpointSliceTargetReconciler) eventChannel() chan event.GenericEvent {
+	r.eventsOnce.Do(func() {
+		r.events = make(chan event.GenericEvent, endpointSliceTargetEventBufferSize)
+	})
+	return r.events
+}
+
+func (r *EndpointSliceTargetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	lb := &metalnetv1alpha1.LoadBalancer{}
+	if err := r.Get(ctx, req.NamespacedName, lb); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("error getting loadbalancer: %w", err)
+		}
+		r.setTargets(ctx, log, req.NamespacedName, nil)
+		return ctrl.Result{}, nil
+	}
+
+	if !lb.DeletionTimestamp.IsZero() || lb.Spec.EndpointSliceTargets == nil || !lb.Spec.IP.IsValid() {
+		r.setTargets(ctx, log, req.NamespacedName, nil)
+		return ctrl.Result{}, nil
+	}
+
+	targets, err := r.discoverTargets(ctx, lb)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.setTargets(ctx, log, req.NamespacedName, targets)
+	return ctrl.Result{}, nil
+}
+
+func (r *EndpointSliceTargetReconciler) discoverTargets(ctx context.Context, lb *metalnetv1alpha1.LoadBalancer) (map[types.NamespacedName]netip.Prefix, error) {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.Workload.List(ctx, sliceList,
+		client.InNamespace(lb.Spec.EndpointSliceTargets.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: lb.Spec.EndpointSliceTargets.ServiceName},
+	); err != nil {
+		return nil, fmt.Errorf("error listing endpoint slices: %w", err)
+	}
+
+	var addrs []netip.Addr
+	for _, slice := range sliceList.Items {
+		if !endpointSliceMatchesFamily(&slice, lb.Spec.IP.Addr) {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				addr, err := netip.ParseAddr(address)
+				if err != nil {
+					continue
+				}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList,
+		client.InNamespace(lb.Namespace),
+		client.MatchingFields{metalnetclient.NetworkInterfaceNetworkRefNameField: lb.Spec.NetworkRef.Name},
+	); err != nil {
+		return nil, fmt.Errorf("error listing network interfaces: %w", err)
+	}
+
+	targetPrefix := netip.PrefixFrom(lb.Spec.IP.Addr, lb.Spec.IP.Addr.BitLen())
+	targets := make(map[types.NamespacedName]netip.Prefix)
+	for _, nic := range nicList.Items {
+		if nic.Spec.NodeName == nil || *nic.Spec.NodeName != r.NodeName || !nic.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if slices.ContainsFunc(addrs, func(addr netip.Addr) bool { return networkInterfaceContains(&nic, addr) }) {
+			targets[client.ObjectKeyFromObject(&nic)] = targetPrefix
+		}
+	}
+	return targets, nil
+}
+
+func endpointSliceMatchesFamily(slice *discoveryv1.EndpointSlice, addr netip.Addr) bool {
+	switch slice.AddressType {
+	case discoveryv1.AddressTypeIPv4:
+		return addr.Is4()
+	case discoveryv1.AddressTypeIPv6:
+		return addr.Is6()
+	default:
+		return false
+	}
+}
+
+func networkInterfaceContains(nic *metalnetv1alpha1.NetworkInterface, addr netip.Addr) bool {
+	for _, ip := range nic.Spec.IPs {
+		if ip.Addr == addr {
+			return true
+		}
+	}
+	for _, prefix := range nic.Spec.Prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// setTargets replaces the targets of the LoadBalancer with the given key and informs the
+// NetworkInterface controller about the NetworkInterfaces whose targets changed.
+func (r *EndpointSliceTargetReconciler) setTargets(ctx context.Context, log logr.Logger, lbKey types.NamespacedName, targets map[types.NamespacedName]netip.Prefix) {
+	r.mu.Lock()
+	previous := r.targets[lbKey]
+	if len(targets) == 0 {
+		delete(r.targets, lbKey)
+	} else {
+		if r.targets == nil {
+			r.targets = make(map[types.NamespacedName]map[types.NamespacedName]netip.Prefix)
+		}
+		r.targets[lbKey] = targets
+	}
+	r.mu.Unlock()
+
+	var changed []types.NamespacedName
+	for nicKey, prefix := range targets {
+		if previousPrefix, ok := previous[nicKey]; !ok || previousPrefix != prefix {
+			changed = append(changed, nicKey)
+		}
+	}
+	for nicKey := range previous {
+		if _, ok := targets[nicKey]; !ok {
+			changed = append(changed, nicKey)
+		}
+	}
+
+	events := r.eventChannel()
+	for _, nicKey := range changed {
+		log.V(1).Info("Discovered targets changed", "NetworkInterfaceKey", nicKey)
+		nic := &metalnetv1alpha1.NetworkInterface{}
+		nic.Namespace, nic.Name = nicKey.Namespace, nicKey.Name
+		select {
+		case events <- event.GenericEvent{Object: nic}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager. The EndpointSlices are watched in the
+// given workload cluster.
+func (r *EndpointSliceTargetReconciler) SetupWithManager(mgr ctrl.Manager, workload cluster.Cluster) error {
+	log := ctrl.Log.WithName("endpointslicetarget").WithName("setup")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("endpointslicetarget").
+		For(&metalnetv1alpha1.LoadBalancer{}).
+		WatchesRawSource(
+			source.Kind(workload.GetCache(), &discoveryv1.EndpointSlice{}),
+			r.enqueueLoadBalancersSelectingEndpointSlice(log),
+		).
+		Watches(
+			&metalnetv1alpha1.NetworkInterface{},
+			r.enqueueLoadBalancersInNetworkOfNetworkInterface(log),
+		).
+		Complete(r)
+}
+
+func (r *EndpointSliceTargetReconciler) enqueueLoadBalancersSelectingEndpointSlice(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+		if !ok {
+			return nil
+		}
+
+		lbList := &metalnetv1alpha1.LoadBalancerList{}
+		if err := r.List(ctx, lbList); err != nil {
+			log.Error(err, "Error listing loadbalancers", "EndpointSliceKey", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+
+		var reqs []ctrl.Request
+		for _, lb := range lbList.Items {
+			targets := lb.Spec.EndpointSliceTargets
+			if targets != nil && targets.Namespace == obj.GetNamespace() && targets.ServiceName == serviceName {
+				reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&lb)})
+			}
+		}
+		return reqs
+	})
+}
+
+func (r *EndpointSliceTargetReconciler) enqueueLoadBalancersInNetworkOfNetworkInterface(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		nic := obj.(*metalnetv1alpha1.NetworkInterface)
+		if nic.Spec.ClusterNetworkRef != nil {
+			// LoadBalancers are only in the Networks of their namespace.
+			return nil
+		}
+		lbList := &metalnetv1alpha1.LoadBalancerList{}
+		if err := r.List(ctx, lbList,
+			client.InNamespace(nic.Namespace),
+			client.MatchingFields{metalnetclient.LoadBalancerNetworkRefNameField: nic.Spec.NetworkRef.Name},
+		); err != nil {
+			log.Error(err, "Error listing loadbalancers", "NetworkInterfaceKey", client.ObjectKeyFromObject(nic))
+			return nil
+		}
+
+		var reqs []ctrl.Request
+		for _, lb := range lbList.Items {
+			if lb.Spec.EndpointSliceTargets != nil {
+				reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&lb)})
+			}
+		}
+		return reqs
+	})
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"net/netip"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("EndpointSliceTargetReconciler", func() {
+	newNIC := func(name, nodeName, ip string, prefixes ...string) *metalnetv1alpha1.NetworkInterface {
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "network"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP(ip)},
+				NodeName:   ptr.To(nodeName),
+			},
+		}
+		for _, prefix := range prefixes {
+			nic.Spec.Prefixes = append(nic.Spec.Prefixes, metalnetv1alpha1.MustParseIPPrefix(prefix))
+		}
+		return nic
+	}
+
+	It("should target the local network interfaces of ready endpoints", func(ctx SpecContext) {
+		lb := &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb"},
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "network"},
+				LBtype:     metalnetv1alpha1.LoadBalancerTypePublic,
+				IPFamily:   corev1.IPv4Protocol,
+				IP:         metalnetv1alpha1.MustParseIP("45.0.0.1"),
+				EndpointSliceTargets: &metalnetv1alpha1.EndpointSliceTargets{
+					Namespace:   "workload",
+					ServiceName: "svc",
+				},
+			},
+		}
+		byIP := newNIC("by-ip", "node", "10.0.0.1")
+		byPrefix := newNIC("by-prefix", "node", "10.0.0.2", "10.1.0.0/24")
+		notReady := newNIC("not-ready", "node", "10.0.0.3")
+		remote := newNIC("remote", "other", "10.0.0.4")
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		Expect(discoveryv1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(lb, byIP, byPrefix, notReady, remote).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "workload",
+				Name:      "svc-abcde",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "svc"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}},
+				{Addresses: []string{"10.1.0.5"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				{Addresses: []string{"10.0.0.3"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+				{Addresses: []string{"10.0.0.4"}},
+			},
+		}
+		workload := fake.NewClientBuilder().WithScheme(s).WithObjects(slice).Build()
+
+		r := &EndpointSliceTargetReconciler{Client: c, Workload: workload, NodeName: "node"}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(lb)}
+		receiveChanged := func() []types.NamespacedName {
+			var keys []types.NamespacedName
+			for len(r.NetworkInterfaceEvents()) > 0 {
+				keys = append(keys, client.ObjectKeyFromObject((<-r.NetworkInterfaceEvents()).Object))
+			}
+			return keys
+		}
+		target := []netip.Prefix{netip.MustParsePrefix("45.0.0.1/32")}
+
+		By("discovering the targets")
+		Expect(r.Reconcile(ctx, req)).To(Equal(ctrl.Result{}))
+		Expect(r.Targets(client.ObjectKeyFromObject(byIP))).To(Equal(target))
+		Expect(r.Targets(client.ObjectKeyFromObject(byPrefix))).To(Equal(target))
+		Expect(r.Targets(client.ObjectKeyFromObject(notReady))).To(BeEmpty())
+		Expect(r.Targets(client.ObjectKeyFromObject(remote))).To(BeEmpty())
+		Expect(receiveChanged()).To(ConsistOf(client.ObjectKeyFromObject(byIP), client.ObjectKeyFromObject(byPrefix)))
+
+		By("withdrawing the target of an endpoint that is gone")
+		slice.Endpoints = slice.Endpoints[1:]
+		Expect(workload.Update(ctx, slice)).To(Succeed())
+		Expect(r.Reconcile(ctx, req)).To(Equal(ctrl.Result{}))
+		Expect(r.Targets(client.ObjectKeyFromObject(byIP))).To(BeEmpty())
+		Expect(r.Targets(client.ObjectKeyFromObject(byPrefix))).To(Equal(target))
+		Expect(receiveChanged()).To(ConsistOf(client.ObjectKeyFromObject(byIP)))
+
+		By("withdrawing all targets once the loadbalancer is gone")
+		Expect(c.Delete(ctx, lb)).To(Succeed())
+		Expect(r.Reconcile(ctx, req)).To(Equal(ctrl.Result{}))
+		Expect(r.Targets(client.ObjectKeyFromObject(byPrefix))).To(BeEmpty())
+		Expect(receiveChanged()).To(ConsistOf(client.ObjectKeyFromObject(byPrefix)))
+	})
+
+	It("should not return targets without reconciler", func() {
+		var r *EndpointSliceTargetReconciler
+		Expect(r.Targets(types.NamespacedName{Namespace: "default", Name: "nic"})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// initialSyncRequeueInterval is the interval work deferred until the initial sync is complete is retried at.
+const initialSyncRequeueInterval = time.Second
+
+type initialSyncKey struct {
+	kind string
+	key  client.ObjectKey
+}
+
+func newInitialSyncKey(obj client.Object, key client.ObjectKey) initialSyncKey {
+	return initialSyncKey{kind: fmt.Sprintf("%T", obj), key: key}
+}
+
+// startupTier is the position of a kind of object in the order objects are reconciled in after startup.
+type startupTier int
+
+const (
+	// startupTierNetworks sets up the VNIs the objects of the later tiers are created in.
+	startupTierNetworks startupTier = iota
+	// startupTierNetworkInterfaces creates the interfaces along with their virtual ips, nat ips and prefixes.
+	startupTierNetworkInterfaces
+	// startupTierLoadBalancers creates the LoadBalancers, whose targets may be local interfaces.
+	startupTierLoadBalancers
+
+	numStartupTiers
+)
+
+func (t startupTier) String() string {
+	switch t {
+	case startupTierNetworks:
+		return "Networks"
+	case startupTierNetworkInterfaces:
+		return "NetworkInterfaces"
+	case startupTierLoadBalancers:
+		return "LoadBalancers"
+	default:
+		return fmt.Sprintf("startupTier(%d)", int(t))
+	}
+}
+
+// InitialSync tracks the first reconcile of the Networks and the local NetworkInterfaces and LoadBalancers
+// after startup.
+//
+// Until all of them were reconciled once, the dpservice state is only partially rebuilt. Reporting
+// ready or subscribing to the VNIs of the networks in that state could blackhole traffic, so the
+// readiness check fails and subscriptions are deferred until the initial sync is complete.
+//
+// The objects are reconciled in tiers: the Networks first, then the NetworkInterfaces, then the
+// LoadBalancers. The objects of a tier are held back until all objects of the previous tiers were
+// reconciled, so they do not fail on VNIs that are not set up yet. Within a tier, objects are
+// reconciled in parallel.
+//
+// A nil InitialSync is always complete.
+type InitialSync struct {
+	client   client.Reader
+	nodeName string
+	timeout  time.Duration
+	log      logr.Logger
+
+	mu         sync.Mutex
+	listed     bool
+	pending    map[initialSyncKey]startupTier
+	reconciled map[initialSyncKey]struct{}
+	// pendingPerTier counts the pending objects of each tier.
+	pendingPerTier [numStartupTiers]int
+	done           chan struct{}
+}
+
+// NewInitialSync creates an InitialSync for the objects of the given node. If the initial sync is not
+// complete after the given timeout, it is considered complete anyway. Zero waits forever.
+func NewInitialSync(c client.Reader, nodeName string, timeout time.Duration) *InitialSync {
+	return &InitialSync{
+		client:     c,
+		nodeName:   nodeName,
+		timeout:    timeout,
+		log:        ctrl.Log.WithName("initial-sync"),
+		pending:    make(map[initialSyncKey]startupTier),
+		reconciled: make(map[initialSyncKey]struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start lists the local objects to wait for. It implements manager.Runnable and is started once the
+// caches are synced.
+func (s *InitialSync) Start(ctx context.Context) error {
+	s.log.Info("Listing local objects")
+	keys, err := s.listLocalObjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for key, tier := range keys {
+		if _, ok := s.reconciled[key]; !ok {
+			s.pending[key] = tier
+			s.pendingPerTier[tier]++
+		}
+	}
+	s.listed = true
+	s.reconciled = nil
+	s.log.Info("Waiting for local objects to be reconciled", "Pending", len(s.pending),
+		"PendingNetworks", s.pendingPerTier[startupTierNetworks],
+		"PendingNetworkInterfaces", s.pendingPerTier[startupTierNetworkInterfaces],
+		"PendingLoadBalancers", s.pendingPerTier[startupTierLoadBalancers])
+	s.completeIfSynced()
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if s.timeout > 0 {
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-s.done:
+	case <-timeout:
+		s.mu.Lock()
+		s.log.Info("Initial sync timed out, considering it complete", "Pending", len(s.pending))
+		s.complete()
+		s.mu.Unlock()
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The lease is per node, so the active metalnet
+// instance of every node syncs its node. The timeout of a standby instance starts once it takes over.
+func (s *InitialSync) NeedLeaderElection() bool {
+	return true
+}
+
+func (s *InitialSync) listLocalObjects(ctx context.Context) (map[initialSyncKey]startupTier, error) {
+	keys := make(map[initialSyncKey]startupTier)
+
+	// Every node reconciles all Networks and ClusterNetworks.
+	networkList := &metalnetv1alpha1.NetworkList{}
+	if err := s.client.List(ctx, networkList); err != nil {
+		return nil, fmt.Errorf("error listing networks: %w", err)
+	}
+	for i := range networkList.Items {
+		network := &networkList.Items[i]
+		keys[newInitialSyncKey(network, client.ObjectKeyFromObject(network))] = startupTierNetworks
+	}
+	clusterNetworkList := &metalnetv1alpha1.ClusterNetworkList{}
+	if err := s.client.List(ctx, clusterNetworkList); err != nil {
+		return nil, fmt.Errorf("error listing cluster networks: %w", err)
+	}
+	for i := range clusterNetworkList.Items {
+		network := &clusterNetworkList.Items[i]
+		keys[newInitialSyncKey(network, client.ObjectKeyFromObject(network))] = startupTierNetworks
+	}
+
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := s.client.List(ctx, nicList); err != nil {
+		return nil, fmt.Errorf("error listing network interfaces: %w", err)
+	}
+	for i := range nicList.Items {
+		nic := &nicList.Items[i]
+		if s.isLocal(nic.Spec.NodeName) {
+			keys[newInitialSyncKey(nic, client.ObjectKeyFromObject(nic))] = startupTierNetworkInterfaces
+		}
+	}
+
+	lbList := &metalnetv1alpha1.LoadBalancerList{}
+	if err := s.client.List(ctx, lbList); err != nil {
+		return nil, fmt.Errorf("error listing loadbalancers: %w", err)
+	}
+	var (
+		nodeLabels        labels.Set
+		nodeLabelsFetched bool
+	)
+	for i := range lbList.Items {
+		lb := &lbList.Items[i]
+		if isActiveActive(lb) && !nodeLabelsFetched {
+			var err error
+			if nodeLabels, err = NodeLabels(ctx, s.client, s.nodeName); err != nil {
+				return nil, err
+			}
+			nodeLabelsFetched = true
+		}
+		if IsLoadBalancerOnNode(lb, s.nodeName, nodeLabels) {
+			keys[newInitialSyncKey(lb, client.ObjectKeyFromObject(lb))] = startupTierLoadBalancers
+		}
+	}
+	return keys, nil
+}
+
+func (s *InitialSync) isLocal(nodeName *string) bool {
+	return nodeName != nil && *nodeName == s.nodeName
+}
+
+// Reconciled records that the object with the given kind and key was reconciled (or is gone).
+func (s *InitialSync) Reconciled(obj client.Object, key client.ObjectKey) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Done() {
+		return
+	}
+
+	k := newInitialSyncKey(obj, key)
+	if !s.listed {
+		s.reconciled[k] = struct{}{}
+		return
+	}
+	if tier, ok := s.pending[k]; ok {
+		delete(s.pending, k)
+		s.pendingPerTier[tier]--
+		if s.pendingPerTier[tier] == 0 && tier+1 < numStartupTiers {
+			s.log.Info("Startup tier reconciled, releasing the next tier", "Tier", tier.String())
+		}
+	}
+	s.completeIfSynced()
+}
+
+// tierReady reports whether the objects of the given tier may be reconciled, i.e. whether all objects
+// of the previous tiers were reconciled since startup.
+func (s *InitialSync) tierReady(tier startupTier) bool {
+	if s.Done() {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.listed {
+		return false
+	}
+	for t := startupTier(0); t < tier; t++ {
+		if s.pendingPerTier[t] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *InitialSync) completeIfSynced() {
+	if s.listed && len(s.pending) == 0 {
+		s.log.Info("Initial sync complete")
+		s.complete()
+	}
+}
+
+func (s *InitialSync) complete() {
+	if !s.Done() {
+		close(s.done)
+	}
+}
+
+// Done reports whether the initial sync is complete.
+func (s *InitialSync) Done() bool {
+	if s == nil {
+		return true
+	}
+
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checker is a healthz.Checker failing until the initial sync is complete.
+func (s *InitialSync) Checker(_ *http.Request) error {
+	if !s.Done() {
+		return errors.New("initial sync is not complete")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("InitialSync", func() {
+	It("should complete once all local objects were reconciled", func(ctx SpecContext) {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+
+		local := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "local"},
+			Spec:       metalnetv1alpha1.NetworkInterfaceSpec{NodeName: ptr.To("node")},
+		}
+		remote := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "remote"},
+			Spec:       metalnetv1alpha1.NetworkInterfaceSpec{NodeName: ptr.To("other")},
+		}
+		lb := &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "local"},
+			Spec:       metalnetv1alpha1.LoadBalancerSpec{NodeName: ptr.To("node")},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(local, remote, lb).Build()
+
+		initialSync := NewInitialSync(c, "node", 0)
+		Expect(initialSync.Checker(nil)).NotTo(Succeed())
+
+		By("reconciling an object before the local objects are listed")
+		initialSync.Reconciled(lb, client.ObjectKeyFromObject(lb))
+
+		startCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(initialSync.Start(startCtx)).To(Succeed())
+		}()
+
+		Consistently(initialSync.Done).Should(BeFalse())
+
+		By("reconciling the remaining local object")
+		initialSync.Reconciled(&metalnetv1alpha1.NetworkInterface{}, client.ObjectKeyFromObject(local))
+		Eventually(initialSync.Done).Should(BeTrue())
+		Expect(initialSync.Checker(nil)).To(Succeed())
+	})
+
+	It("should release the tiers once the previous tiers were reconciled", func(ctx SpecContext) {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic"},
+			Spec:       metalnetv1alpha1.NetworkInterfaceSpec{NodeName: ptr.To("node")},
+		}
+		lb := &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb"},
+			Spec:       metalnetv1alpha1.LoadBalancerSpec{NodeName: ptr.To("node")},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(network, nic, lb).Build()
+
+		initialSync := NewInitialSync(c, "node", 0)
+		Expect(initialSync.tierReady(startupTierNetworkInterfaces)).To(BeFalse())
+
+		startCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(initialSync.Start(startCtx)).To(Succeed())
+		}()
+
+		Eventually(func() bool { return initialSync.tierReady(startupTierNetworks) }).Should(BeTrue())
+		Expect(initialSync.tierReady(startupTierNetworkInterfaces)).To(BeFalse())
+		Expect(initialSync.tierReady(startupTierLoadBalancers)).To(BeFalse())
+
+		By("reconciling the network")
+		initialSync.Reconciled(network, client.ObjectKeyFromObject(network))
+		Expect(initialSync.tierReady(startupTierNetworkInterfaces)).To(BeTrue())
+		Expect(initialSync.tierReady(startupTierLoadBalancers)).To(BeFalse())
+
+		By("reconciling the network interface")
+		initialSync.Reconciled(nic, client.ObjectKeyFromObject(nic))
+		Expect(initialSync.tierReady(startupTierLoadBalancers)).To(BeTrue())
+		Expect(initialSync.Done()).To(BeFalse())
+
+		By("reconciling the loadbalancer")
+		initialSync.Reconciled(lb, client.ObjectKeyFromObject(lb))
+		Eventually(initialSync.Done).Should(BeTrue())
+	})
+
+	It("should be complete if nil", func() {
+		var initialSync *InitialSync
+		Expect(initialSync.Done()).To(BeTrue())
+		Expect(initialSync.tierReady(startupTierLoadBalancers)).To(BeTrue())
+	})
+})
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// interfaceInfoCollectTimeout is the timeout of listing the network interfaces for the info metric.
+const interfaceInfoCollectTimeout = 10 * time.Second
+
+// InterfaceMetadata selects the labels and annotations of the NetworkInterfaces that are reported by the metric
+// metalnet_network_interface_info. Dataplane statistics can be correlated with the identity of the tenant or
+// workload that way.
+//
+// A nil InterfaceMetadata propagates nothing.
+type InterfaceMetadata struct {
+	labels      []string
+	annotations []string
+
+	desc *prometheus.Desc
+}
+
+// NewInterfaceMetadata propagates the labels and annotations with the given keys. In metrics, the keys are
+// prefixed with label_ or annotation_ and characters not allowed in label names are replaced by underscores.
+// Keys that end up with the same metric label name are rejected.
+func NewInterfaceMetadata(labels, annotations []string) (*InterfaceMetadata, error) {
+	metricLabels := []string{"namespace", "network_interface", "uid"}
+	keys := make(map[string]string)
+	for _, set := range []struct {
+		prefix string
+		keys   []string
+	}{{"label_", labels}, {"annotation_", annotations}} {
+		for _, key := range set.keys {
+			name := set.prefix + sanitizeMetricLabelName(key)
+			if other, ok := keys[name]; ok {
+				return nil, fmt.Errorf("keys %q and %q both map to metric label %s", other, key, name)
+			}
+			keys[name] = key
+			metricLabels = append(metricLabels, name)
+		}
+	}
+
+	return &InterfaceMetadata{
+		labels:      labels,
+		annotations: annotations,
+		desc: prometheus.NewDesc(
+			"metalnet_network_interface_info",
+			"Information about a network interface on this node, including its propagated labels and annotations. "+
+				"Join it on namespace and network_interface to correlate dataplane metrics with the workload.",
+			metricLabels, nil,
+		),
+	}, nil
+}
+
+func sanitizeMetricLabelName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// Values returns the propagated labels and annotations the object has. Annotations take precedence over
+// labels with the same key.
+func (m *InterfaceMetadata) Values(obj metav1.Object) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	var values map[string]string
+	add := func(keys []string, from map[string]string) {
+		for _, key := range keys {
+			if value, ok := from[key]; ok {
+				if values == nil {
+					values = make(map[string]string)
+				}
+				values[key] = value
+			}
+		}
+	}
+	add(m.labels, obj.GetLabels())
+	add(m.annotations, obj.GetAnnotations())
+	return values
+}
+
+// metricLabelValues returns the values of the metric labels of the info metric for the NetworkInterface.
+// Missing labels and annotations are reported as empty values.
+func (m *InterfaceMetadata) metricLabelValues(nic *metalnetv1alpha1.NetworkInterface) []string {
+	values := []string{nic.Namespace, nic.Name, string(nic.UID)}
+	for _, key := range m.labels {
+		values = append(values, nic.Labels[key])
+	}
+	for _, key := range m.annotations {
+		values = append(values, nic.Annotations[key])
+	}
+	return values
+}
+
+// NetworkInterfaceInfoCollector is a prometheus collector reporting metalnet_network_interface_info for the
+// network interfaces on this node.
+type NetworkInterfaceInfoCollector struct {
+	client.Reader
+	NodeName string
+	Metadata *InterfaceMetadata
+
+	log logr.Logger
+}
+
+func NewNetworkInterfaceInfoCollector(c client.Reader, nodeName string, metadata *InterfaceMetadata) *NetworkInterfaceInfoCollector {
+	return &NetworkInterfaceInfoCollector{
+		Reader:   c,
+		NodeName: nodeName,
+		Metadata: metadata,
+		log:      ctrl.Log.WithName("network-interface-info"),
+	}
+}
+
+func (c *NetworkInterfaceInfoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Metadata.desc
+}
+
+func (c *NetworkInterfaceInfoCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), interfaceInfoCollectTimeout)
+	defer cancel()
+
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := c.List(ctx, nicList); err != nil {
+		c.log.Error(err, "Error listing network interfaces")
+		return
+	}
+
+	for i := range nicList.Items {
+		nic := &nicList.Items[i]
+		if nic.Spec.NodeName == nil || *nic.Spec.NodeName != c.NodeName {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.Metadata.desc, prometheus.GaugeValue, 1, c.Metadata.metricLabelValues(nic)...)
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"strings"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("InterfaceMetadata", func() {
+	nic := &metalnetv1alpha1.NetworkInterface{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "nic",
+			UID:         "nic-uid",
+			Labels:      map[string]string{"tenant.example.com/id": "tenant-1", "other": "ignored"},
+			Annotations: map[string]string{"workload": "vm-1"},
+		},
+		Spec: metalnetv1alpha1.NetworkInterfaceSpec{NodeName: ptr.To("node")},
+	}
+
+	It("should return the propagated labels and annotations", func() {
+		metadata, err := NewInterfaceMetadata([]string{"tenant.example.com/id", "missing"}, []string{"workload"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata.Values(nic)).To(Equal(map[string]string{
+			"tenant.example.com/id": "tenant-1",
+			"workload":              "vm-1",
+		}))
+
+		var nilMetadata *InterfaceMetadata
+		Expect(nilMetadata.Values(nic)).To(BeNil())
+	})
+
+	It("should reject keys mapping to the same metric label", func() {
+		_, err := NewInterfaceMetadata([]string{"tenant.id", "tenant/id"}, nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewInterfaceMetadata([]string{"tenant.id"}, []string{"tenant.id"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the info metric of the local network interfaces", func() {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		remote := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "remote"},
+			Spec:       metalnetv1alpha1.NetworkInterfaceSpec{NodeName: ptr.To("other")},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(nic.DeepCopy(), remote).Build()
+
+		metadata, err := NewInterfaceMetadata([]string{"tenant.example.com/id"}, []string{"workload"})
+		Expect(err).NotTo(HaveOccurred())
+		collector := NewNetworkInterfaceInfoCollector(c, "node", metadata)
+
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP metalnet_network_interface_info Information about a network interface on this node, including its propagated labels and annotations. Join it on namespace and network_interface to correlate dataplane metrics with the workload.
+# TYPE metalnet_network_interface_info gauge
+metalnet_network_interface_info{annotation_workload="vm-1",label_tenant_example_com_id="tenant-1",namespace="default",network_interface="nic",uid="nic-uid"} 1
+`))).To(Succeed())
+	})
+})
@@ -0,0 +1,379 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	internetGatewayPortBlocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "metalnet_internet_gateway_port_blocks",
+		Help: "Number of port blocks of a public IP of the NAT pool of an internet gateway.",
+	}, []string{"namespace", "internet_gateway", "ip"})
+	internetGatewayPortBlocksAllocated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "metalnet_internet_gateway_port_blocks_allocated",
+		Help: "Number of port blocks of a public IP of the NAT pool of an internet gateway allocated to network interfaces.",
+	}, []string{"namespace", "internet_gateway", "ip"})
+	internetGatewayPendingNetworkInterfaces = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "metalnet_internet_gateway_pending_network_interfaces",
+		Help: "Number of network interfaces waiting for a port block of an internet gateway.",
+	}, []string{"namespace", "internet_gateway"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(internetGatewayPortBlocks, internetGatewayPortBlocksAllocated, internetGatewayPendingNetworkInterfaces)
+}
+
+// InternetGatewayReconciler reconciles an InternetGateway object.
+//
+// It allocates the port blocks of the NAT pool to the NetworkInterfaces referencing the InternetGateway.
+// The NetworkInterfaceReconciler of the node an interface lives on programs the allocated port block as
+// the NAT of the interface.
+type InternetGatewayReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=internetgateways,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=internetgateways/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch
+
+func (r *InternetGatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	internetGateway := &metalnetv1alpha1.InternetGateway{}
+	if err := r.Get(ctx, req.NamespacedName, internetGateway); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteInternetGatewayMetrics(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if paused, err := updatePausedCondition(ctx, r.Client, internetGateway, &internetGateway.Status.Conditions); err != nil || paused {
+		if paused {
+			log.V(1).Info("Reconciliation is paused")
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !internetGateway.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Internet gateway is being deleted, not allocating port blocks")
+		return ctrl.Result{}, nil
+	}
+	return r.reconcile(ctx, log, internetGateway)
+}
+
+func (r *InternetGatewayReconciler) reconcile(ctx context.Context, log logr.Logger, internetGateway *metalnetv1alpha1.InternetGateway) (ctrl.Result, error) {
+	log.V(1).Info("Listing network interfaces referencing internet gateway")
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList,
+		client.InNamespace(internetGateway.Namespace),
+		client.MatchingFields{metalnetclient.NetworkInterfaceInternetGatewayRefNameField: internetGateway.Name},
+	); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error listing network interfaces referencing internet gateway: %w", err)
+	}
+
+	allocations, capacity, pending := allocateInternetGatewayPortBlocks(internetGateway, nicList.Items)
+	log.V(1).Info("Allocated port blocks", "Allocations", len(allocations), "Capacity", capacity, "Pending", pending)
+
+	ips := internetGatewayIPStatuses(internetGateway, allocations)
+	conditions := append([]metav1.Condition(nil), internetGateway.Status.Conditions...)
+	meta.SetStatusCondition(&conditions, internetGatewayPortsExhaustedCondition(internetGateway, capacity, int32(len(allocations)), pending))
+	setInternetGatewayMetrics(internetGateway, ips, pending)
+
+	if equality.Semantic.DeepEqual(internetGateway.Status.Allocations, allocations) &&
+		internetGateway.Status.Capacity == capacity &&
+		internetGateway.Status.Pending == pending &&
+		reflect.DeepEqual(internetGateway.Status.IPs, ips) &&
+		reflect.DeepEqual(internetGateway.Status.Conditions, conditions) {
+		log.V(1).Info("Internet gateway status is up-to-date")
+		return ctrl.Result{}, nil
+	}
+
+	log.V(1).Info("Patching internet gateway status")
+	base := internetGateway.DeepCopy()
+	internetGateway.Status.Allocations = allocations
+	internetGateway.Status.Capacity = capacity
+	internetGateway.Status.Pending = pending
+	internetGateway.Status.IPs = ips
+	internetGateway.Status.Conditions = conditions
+	// The controller runs on every node, so concurrent allocations must not overwrite each other.
+	if err := r.Status().Patch(ctx, internetGateway, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+		if apierrors.IsConflict(err) {
+			log.V(1).Info("Internet gateway was modified concurrently, requeueing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("error patching internet gateway status: %w", err)
+	}
+	log.V(1).Info("Patched internet gateway status")
+	return ctrl.Result{}, nil
+}
+
+type internetGatewayPortBlock struct {
+	ip   metalnetv1alpha1.IP
+	port int32
+}
+
+// allocateInternetGatewayPortBlocks returns the port block allocations of the internet gateway for the given
+// network interfaces, the number of port blocks of the NAT pool and the number of interfaces left without one.
+//
+// Existing allocations are kept stable. An allocation is only released once its interface is gone or no longer
+// eligible and does not use the port block anymore, so the port block is not handed out while still in use.
+// Port blocks allocated before PortsPerNetworkInterface changed are kept the same way as long as their interface
+// uses them; the ports they cover are not handed out until they are released.
+func allocateInternetGatewayPortBlocks(
+	internetGateway *metalnetv1alpha1.InternetGateway,
+	nics []metalnetv1alpha1.NetworkInterface,
+) ([]metalnetv1alpha1.InternetGatewayAllocation, int32, int32) {
+	size, blocksPerIP := internetGatewayPortBlockSize(internetGateway)
+	capacity := int32(len(internetGateway.Spec.IPs)) * blocksPerIP
+
+	nicByName := make(map[string]*metalnetv1alpha1.NetworkInterface, len(nics))
+	for i := range nics {
+		nicByName[nics[i].Name] = &nics[i]
+	}
+
+	isInPool := func(allocation metalnetv1alpha1.InternetGatewayAllocation) bool {
+		if allocation.Port > allocation.EndPort ||
+			allocation.Port < metalnetv1alpha1.InternetGatewayMinPort ||
+			allocation.EndPort > metalnetv1alpha1.InternetGatewayMaxPort {
+			return false
+		}
+		return slices.Contains(internetGateway.Spec.IPs, allocation.IP)
+	}
+	hasCurrentSize := func(allocation metalnetv1alpha1.InternetGatewayAllocation) bool {
+		return allocation.EndPort-allocation.Port+1 == size && (allocation.Port-metalnetv1alpha1.InternetGatewayMinPort)%size == 0
+	}
+
+	var (
+		allocations []metalnetv1alpha1.InternetGatewayAllocation
+		allocated   = make(map[string]bool)
+		// used holds the port blocks of the current size that are allocated or overlap a previous allocation.
+		used = make(map[internetGatewayPortBlock]bool)
+		// current holds the port blocks of the current size that are allocated.
+		current = make(map[internetGatewayPortBlock]bool)
+		// previous holds the allocations of a previous size that are still in use.
+		previous []metalnetv1alpha1.InternetGatewayAllocation
+	)
+	for _, allocation := range internetGateway.Status.Allocations {
+		nic, ok := nicByName[allocation.NetworkInterfaceName]
+		if !ok {
+			continue
+		}
+		if !isInPool(allocation) {
+			continue
+		}
+
+		if hasCurrentSize(allocation) {
+			if !isInternetGatewayEligible(nic) && !isUsingInternetGatewayAllocation(nic, allocation) {
+				continue
+			}
+			block := internetGatewayPortBlock{allocation.IP, allocation.Port}
+			if used[block] {
+				continue
+			}
+			used[block] = true
+			current[block] = true
+		} else {
+			if !isUsingInternetGatewayAllocation(nic, allocation) {
+				continue
+			}
+			blocks := overlappingInternetGatewayPortBlocks(allocation, size)
+			if slices.ContainsFunc(blocks, func(block internetGatewayPortBlock) bool { return current[block] }) ||
+				slices.ContainsFunc(previous, func(other metalnetv1alpha1.InternetGatewayAllocation) bool {
+					return other.IP == allocation.IP && other.Port <= allocation.EndPort && allocation.Port <= other.EndPort
+				}) {
+				continue
+			}
+			for _, block := range blocks {
+				used[block] = true
+			}
+			previous = append(previous, allocation)
+		}
+		allocated[nic.Name] = true
+		allocations = append(allocations, allocation)
+	}
+
+	var waiting []string
+	for _, nic := range nics {
+		if !allocated[nic.Name] && isInternetGatewayEligible(&nic) {
+			waiting = append(waiting, nic.Name)
+		}
+	}
+	sort.Strings(waiting)
+
+	var pending int32
+	for _, name := range waiting {
+		allocation, ok := nextFreeInternetGatewayPortBlock(internetGateway.Spec.IPs, size, blocksPerIP, used)
+		if !ok {
+			pending++
+			continue
+		}
+		allocation.NetworkInterfaceName = name
+		used[internetGatewayPortBlock{allocation.IP, allocation.Port}] = true
+		allocations = append(allocations, allocation)
+	}
+
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].NetworkInterfaceName < allocations[j].NetworkInterfaceName
+	})
+	return allocations, capacity, pending
+}
+
+// overlappingInternetGatewayPortBlocks returns the port blocks of the given size overlapping the allocation.
+func overlappingInternetGatewayPortBlocks(allocation metalnetv1alpha1.InternetGatewayAllocation, size int32) []internetGatewayPortBlock {
+	var blocks []internetGatewayPortBlock
+	start := allocation.Port - (allocation.Port-metalnetv1alpha1.InternetGatewayMinPort)%size
+	for port := start; port <= allocation.EndPort; port += size {
+		blocks = append(blocks, internetGatewayPortBlock{allocation.IP, port})
+	}
+	return blocks
+}
+
+// internetGatewayPortBlockSize returns the number of ports per port block and the number of port blocks per IP.
+func internetGatewayPortBlockSize(internetGateway *metalnetv1alpha1.InternetGateway) (int32, int32) {
+	size := internetGateway.Spec.PortsPerNetworkInterface
+	if size <= 0 {
+		size = metalnetv1alpha1.InternetGatewayMinPort
+	}
+	return size, (metalnetv1alpha1.InternetGatewayMaxPort - metalnetv1alpha1.InternetGatewayMinPort + 1) / size
+}
+
+// internetGatewayIPStatuses returns the port block utilization of the IPs of the internet gateway.
+func internetGatewayIPStatuses(
+	internetGateway *metalnetv1alpha1.InternetGateway,
+	allocations []metalnetv1alpha1.InternetGatewayAllocation,
+) []metalnetv1alpha1.InternetGatewayIPStatus {
+	_, blocksPerIP := internetGatewayPortBlockSize(internetGateway)
+
+	allocated := make(map[metalnetv1alpha1.IP]int32)
+	for _, allocation := range allocations {
+		allocated[allocation.IP]++
+	}
+
+	var ips []metalnetv1alpha1.InternetGatewayIPStatus
+	for _, ip := range internetGateway.Spec.IPs {
+		if slices.ContainsFunc(ips, func(status metalnetv1alpha1.InternetGatewayIPStatus) bool { return status.IP == ip }) {
+			continue
+		}
+		ips = append(ips, metalnetv1alpha1.InternetGatewayIPStatus{
+			IP:        ip,
+			Capacity:  blocksPerIP,
+			Allocated: allocated[ip],
+		})
+	}
+	return ips
+}
+
+func internetGatewayPortsExhaustedCondition(internetGateway *metalnetv1alpha1.InternetGateway, capacity, allocated, pending int32) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               metalnetv1alpha1.InternetGatewayPortsExhausted,
+		ObservedGeneration: internetGateway.Generation,
+		Message:            fmt.Sprintf("%d of %d port blocks allocated, %d network interfaces pending", allocated, capacity, pending),
+	}
+	if allocated >= capacity || pending > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = metalnetv1alpha1.PortsExhaustedReasonNoFreePortBlocks
+	} else {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = metalnetv1alpha1.PortsExhaustedReasonPortBlocksAvailable
+	}
+	return condition
+}
+
+func setInternetGatewayMetrics(internetGateway *metalnetv1alpha1.InternetGateway, ips []metalnetv1alpha1.InternetGatewayIPStatus, pending int32) {
+	deleteInternetGatewayMetrics(client.ObjectKeyFromObject(internetGateway))
+	for _, ip := range ips {
+		internetGatewayPortBlocks.WithLabelValues(internetGateway.Namespace, internetGateway.Name, ip.IP.String()).Set(float64(ip.Capacity))
+		internetGatewayPortBlocksAllocated.WithLabelValues(internetGateway.Namespace, internetGateway.Name, ip.IP.String()).Set(float64(ip.Allocated))
+	}
+	internetGatewayPendingNetworkInterfaces.WithLabelValues(internetGateway.Namespace, internetGateway.Name).Set(float64(pending))
+}
+
+func deleteInternetGatewayMetrics(key client.ObjectKey) {
+	labels := prometheus.Labels{"namespace": key.Namespace, "internet_gateway": key.Name}
+	internetGatewayPortBlocks.DeletePartialMatch(labels)
+	internetGatewayPortBlocksAllocated.DeletePartialMatch(labels)
+	internetGatewayPendingNetworkInterfaces.DeletePartialMatch(labels)
+}
+
+func nextFreeInternetGatewayPortBlock(
+	ips []metalnetv1alpha1.IP,
+	size, blocksPerIP int32,
+	used map[internetGatewayPortBlock]bool,
+) (metalnetv1alpha1.InternetGatewayAllocation, bool) {
+	for _, ip := range ips {
+		for i := int32(0); i < blocksPerIP; i++ {
+			port := metalnetv1alpha1.InternetGatewayMinPort + i*size
+			if used[internetGatewayPortBlock{ip, port}] {
+				continue
+			}
+			return metalnetv1alpha1.InternetGatewayAllocation{
+				IP:      ip,
+				Port:    port,
+				EndPort: port + size - 1,
+			}, true
+		}
+	}
+	return metalnetv1alpha1.InternetGatewayAllocation{}, false
+}
+
+// isInternetGatewayEligible reports whether the network interface egresses through its internet gateway.
+func isInternetGatewayEligible(nic *metalnetv1alpha1.NetworkInterface) bool {
+	return nic.DeletionTimestamp.IsZero() &&
+		nic.Spec.InternetGatewayRef != nil &&
+		nic.Spec.VirtualIP == nil &&
+		nic.Spec.NAT == nil
+}
+
+func isUsingInternetGatewayAllocation(nic *metalnetv1alpha1.NetworkInterface, allocation metalnetv1alpha1.InternetGatewayAllocation) bool {
+	natIP := nic.Status.NatIP
+	return natIP != nil &&
+		natIP.IP != nil &&
+		*natIP.IP == allocation.IP &&
+		natIP.Port == allocation.Port &&
+		natIP.EndPort == allocation.EndPort
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *InternetGatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	log := ctrl.Log.WithName("internetgateway").WithName("setup")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metalnetv1alpha1.InternetGateway{}).
+		Watches(
+			&metalnetv1alpha1.NetworkInterface{},
+			r.enqueueInternetGatewayReferencedByNetworkInterface(log),
+		).
+		Complete(withTracing("InternetGateway", r))
+}
+
+func (r *InternetGatewayReconciler) enqueueInternetGatewayReferencedByNetworkInterface(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		nic := obj.(*metalnetv1alpha1.NetworkInterface)
+		if nic.Spec.InternetGatewayRef == nil {
+			return nil
+		}
+		log.V(2).Info("Enqueueing internet gateway referenced by network interface", "NetworkInterfaceKey", client.ObjectKeyFromObject(nic))
+		return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: nic.Namespace, Name: nic.Spec.InternetGatewayRef.Name}}}
+	})
+}
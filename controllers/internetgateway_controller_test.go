@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+var _ = Describe("Internet Gateway port block allocation", Label("internetgateway"), func() {
+	var (
+		ipA = metalnetv1alpha1.IP{Addr: netip.MustParseAddr("46.0.0.1")}
+		ipB = metalnetv1alpha1.IP{Addr: netip.MustParseAddr("46.0.0.2")}
+	)
+
+	newInternetGateway := func(ips ...metalnetv1alpha1.IP) *metalnetv1alpha1.InternetGateway {
+		return &metalnetv1alpha1.InternetGateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "igw"},
+			Spec: metalnetv1alpha1.InternetGatewaySpec{
+				IPs:                      ips,
+				PortsPerNetworkInterface: 32768,
+			},
+		}
+	}
+
+	newNIC := func(name string) metalnetv1alpha1.NetworkInterface {
+		return metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				InternetGatewayRef: &corev1.LocalObjectReference{Name: "igw"},
+			},
+		}
+	}
+
+	It("should allocate port blocks in order and count the pending interfaces", func() {
+		allocations, capacity, pending := allocateInternetGatewayPortBlocks(
+			newInternetGateway(ipA, ipB),
+			[]metalnetv1alpha1.NetworkInterface{newNIC("c"), newNIC("a"), newNIC("b")},
+		)
+		Expect(capacity).To(Equal(int32(2)))
+		Expect(pending).To(Equal(int32(1)))
+		Expect(allocations).To(Equal([]metalnetv1alpha1.InternetGatewayAllocation{
+			{NetworkInterfaceName: "a", IP: ipA, Port: 1024, EndPort: 33791},
+			{NetworkInterfaceName: "b", IP: ipB, Port: 1024, EndPort: 33791},
+		}))
+	})
+
+	It("should keep existing allocations stable", func() {
+		internetGateway := newInternetGateway(ipA, ipB)
+		internetGateway.Status.Allocations = []metalnetv1alpha1.InternetGatewayAllocation{
+			{NetworkInterfaceName: "b", IP: ipB, Port: 1024, EndPort: 33791},
+		}
+
+		allocations, _, pending := allocateInternetGatewayPortBlocks(
+			internetGateway,
+			[]metalnetv1alpha1.NetworkInterface{newNIC("a"), newNIC("b")},
+		)
+		Expect(pending).To(BeZero())
+		Expect(allocations).To(Equal([]metalnetv1alpha1.InternetGatewayAllocation{
+			{NetworkInterfaceName: "a", IP: ipA, Port: 1024, EndPort: 33791},
+			{NetworkInterfaceName: "b", IP: ipB, Port: 1024, EndPort: 33791},
+		}))
+	})
+
+	It("should release a port block only once the interface stopped using it", func() {
+		internetGateway := newInternetGateway(ipA)
+		allocation := metalnetv1alpha1.InternetGatewayAllocation{NetworkInterfaceName: "a", IP: ipA, Port: 1024, EndPort: 33791}
+		internetGateway.Status.Allocations = []metalnetv1alpha1.InternetGatewayAllocation{allocation}
+
+		nic := newNIC("a")
+		nic.Spec.VirtualIP = &metalnetv1alpha1.IP{Addr: netip.MustParseAddr("45.0.0.1")}
+		nic.Status.NatIP = &metalnetv1alpha1.NATDetails{IP: &ipA, Port: 1024, EndPort: 33791}
+
+		allocations, _, _ := allocateInternetGatewayPortBlocks(internetGateway, []metalnetv1alpha1.NetworkInterface{nic})
+		Expect(allocations).To(ConsistOf(allocation))
+
+		nic.Status.NatIP = nil
+		allocations, _, _ = allocateInternetGatewayPortBlocks(internetGateway, []metalnetv1alpha1.NetworkInterface{nic})
+		Expect(allocations).To(BeEmpty())
+	})
+
+	It("should keep port blocks of a previous size until the interface stopped using them", func() {
+		internetGateway := newInternetGateway(ipA)
+		internetGateway.Spec.PortsPerNetworkInterface = 16384
+		internetGateway.Status.Allocations = []metalnetv1alpha1.InternetGatewayAllocation{
+			{NetworkInterfaceName: "a", IP: ipA, Port: 1024, EndPort: 33791},
+		}
+
+		a := newNIC("a")
+		a.Status.NatIP = &metalnetv1alpha1.NATDetails{IP: &ipA, Port: 1024, EndPort: 33791}
+
+		allocations, _, pending := allocateInternetGatewayPortBlocks(internetGateway, []metalnetv1alpha1.NetworkInterface{a, newNIC("b")})
+		Expect(pending).To(BeZero())
+		Expect(allocations).To(Equal([]metalnetv1alpha1.InternetGatewayAllocation{
+			{NetworkInterfaceName: "a", IP: ipA, Port: 1024, EndPort: 33791},
+			{NetworkInterfaceName: "b", IP: ipA, Port: 33792, EndPort: 50175},
+		}))
+
+		internetGateway.Status.Allocations = allocations
+		a.Status.NatIP = nil
+		allocations, _, pending = allocateInternetGatewayPortBlocks(internetGateway, []metalnetv1alpha1.NetworkInterface{a, newNIC("b")})
+		Expect(pending).To(BeZero())
+		Expect(allocations).To(Equal([]metalnetv1alpha1.InternetGatewayAllocation{
+			{NetworkInterfaceName: "a", IP: ipA, Port: 1024, EndPort: 17407},
+			{NetworkInterfaceName: "b", IP: ipA, Port: 33792, EndPort: 50175},
+		}))
+	})
+
+	It("should report the port block utilization and the exhaustion of the NAT pool", func() {
+		internetGateway := newInternetGateway(ipA, ipB)
+		allocations, capacity, pending := allocateInternetGatewayPortBlocks(
+			internetGateway,
+			[]metalnetv1alpha1.NetworkInterface{newNIC("a")},
+		)
+		Expect(internetGatewayIPStatuses(internetGateway, allocations)).To(Equal([]metalnetv1alpha1.InternetGatewayIPStatus{
+			{IP: ipA, Capacity: 1, Allocated: 1},
+			{IP: ipB, Capacity: 1, Allocated: 0},
+		}))
+		Expect(internetGatewayPortsExhaustedCondition(internetGateway, capacity, int32(len(allocations)), pending)).To(And(
+			HaveField("Status", metav1.ConditionFalse),
+			HaveField("Reason", metalnetv1alpha1.PortsExhaustedReasonPortBlocksAvailable),
+		))
+
+		allocations, capacity, pending = allocateInternetGatewayPortBlocks(
+			internetGateway,
+			[]metalnetv1alpha1.NetworkInterface{newNIC("a"), newNIC("b")},
+		)
+		Expect(internetGatewayPortsExhaustedCondition(internetGateway, capacity, int32(len(allocations)), pending)).To(And(
+			HaveField("Status", metav1.ConditionTrue),
+			HaveField("Reason", metalnetv1alpha1.PortsExhaustedReasonNoFreePortBlocks),
+			HaveField("Message", "2 of 2 port blocks allocated, 0 network interfaces pending"),
+		))
+	})
+})
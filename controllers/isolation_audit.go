@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IsolationViolationRemover removes the routes of a VNI into VNIs it is not peered with.
+// It is implemented by *metalbond.MetalnetClient.
+type IsolationViolationRemover interface {
+	RemoveIsolationViolations(ctx context.Context, vni uint32) (int, error)
+}
+
+// IsolationAudit periodically removes the routes into not peered VNIs from the VNIs of all networks,
+// so a stale peering cache cannot leave inter-VNI routing open.
+type IsolationAudit struct {
+	client   client.Reader
+	remover  IsolationViolationRemover
+	interval time.Duration
+	log      logr.Logger
+}
+
+func NewIsolationAudit(c client.Reader, remover IsolationViolationRemover, interval time.Duration) *IsolationAudit {
+	return &IsolationAudit{
+		client:   c,
+		remover:  remover,
+		interval: interval,
+		log:      ctrl.Log.WithName("isolation-audit"),
+	}
+}
+
+// Start audits the VNIs at the configured interval until the context is done. It implements manager.Runnable.
+func (a *IsolationAudit) Start(ctx context.Context) error {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := a.Audit(ctx); err != nil {
+			a.log.Error(err, "Error auditing vni isolation")
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The lease is per node, so the active metalnet
+// instance of every node audits its own dpservice.
+func (a *IsolationAudit) NeedLeaderElection() bool {
+	return true
+}
+
+// Audit removes the routes into not peered VNIs from the VNIs of all networks.
+func (a *IsolationAudit) Audit(ctx context.Context) error {
+	networkList := &metalnetv1alpha1.NetworkList{}
+	if err := a.client.List(ctx, networkList); err != nil {
+		return fmt.Errorf("error listing networks: %w", err)
+	}
+
+	audited := make(map[uint32]struct{}, len(networkList.Items))
+	for _, network := range networkList.Items {
+		vni := uint32(network.Spec.ID)
+		if _, ok := audited[vni]; ok {
+			continue
+		}
+		audited[vni] = struct{}{}
+
+		removed, err := a.remover.RemoveIsolationViolations(ctx, vni)
+		if errors.Is(err, metalnetdpdk.ErrListTooLarge) {
+			// The other VNIs are audited nevertheless.
+			a.log.Error(err, "Not auditing vni whose route table is too large", "VNI", vni)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error auditing vni %d: %w", vni, err)
+		}
+		if removed > 0 {
+			a.log.Info("Removed routes into not peered vnis", "VNI", vni, "Removed", removed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// isolationViolationRemoverFunc implements IsolationViolationRemover with a function.
+type isolationViolationRemoverFunc func(ctx context.Context, vni uint32) (int, error)
+
+func (f isolationViolationRemoverFunc) RemoveIsolationViolations(ctx context.Context, vni uint32) (int, error) {
+	return f(ctx, vni)
+}
+
+var _ = Describe("Isolation audit", func() {
+	It("should audit the other vnis if the route table of a vni is too large", func(ctx SpecContext) {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			&metalnetv1alpha1.Network{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}, Spec: metalnetv1alpha1.NetworkSpec{ID: 100}},
+			&metalnetv1alpha1.Network{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}, Spec: metalnetv1alpha1.NetworkSpec{ID: 200}},
+		).Build()
+
+		var audited []uint32
+		audit := NewIsolationAudit(c, isolationViolationRemoverFunc(func(_ context.Context, vni uint32) (int, error) {
+			audited = append(audited, vni)
+			if vni == 100 {
+				return 0, fmt.Errorf("error listing routes: %w", metalnetdpdk.ErrListTooLarge)
+			}
+			return 0, nil
+		}), time.Minute)
+
+		Expect(audit.Audit(ctx)).To(Succeed())
+		Expect(audited).To(ConsistOf(uint32(100), uint32(200)))
+	})
+})
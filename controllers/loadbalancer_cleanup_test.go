@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("LoadBalancer cleanup", func() {
+	var (
+		dpdkClient dpdkclient.Client
+		deleteErr  error
+		cache      *internal.MetalnetCache
+		r          *LoadBalancerReconciler
+		lb         *metalnetv1alpha1.LoadBalancer
+	)
+
+	BeforeEach(func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				if method == dpdkproto.DPDKironcore_DeleteLoadBalancer_FullMethodName && deleteErr != nil {
+					return deleteErr
+				}
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		deleteErr = nil
+		dpdkClient = dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		lb = &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb", UID: "lb-uid"},
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				LBtype: metalnetv1alpha1.LoadBalancerTypeInternal,
+				IP:     metalnetv1alpha1.MustParseIP("10.0.0.100"),
+			},
+		}
+		_, err = dpdkClient.CreateLoadBalancer(ctx, &dpdk.LoadBalancer{
+			LoadBalancerMeta: dpdk.LoadBalancerMeta{ID: string(lb.UID)},
+			Spec:             dpdk.LoadBalancerSpec{VNI: 100, LbVipIP: &lb.Spec.IP.Addr},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, target := range []string{"2001:db8::1", "2001:db8::2"} {
+			targetIP := netip.MustParseAddr(target)
+			_, err = dpdkClient.CreateLoadBalancerTarget(ctx, &dpdk.LoadBalancerTarget{
+				LoadBalancerTargetMeta: dpdk.LoadBalancerTargetMeta{LoadbalancerID: string(lb.UID)},
+				Spec:                   dpdk.LoadBalancerTargetSpec{TargetIP: &targetIP},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		log := logr.Discard()
+		cache = internal.NewMetalnetCache(&log)
+		Expect(cache.AddLoadBalancerServer(100, "10.0.0.100", lb.UID)).To(Succeed())
+		r = &LoadBalancerReconciler{
+			EventRecorder: &record.FakeRecorder{},
+			DPDK:          dpdkClient,
+			RouteUtil:     &routeTable{routes: make(map[string]struct{})},
+			MetalnetCache: cache,
+			NodeName:      "node",
+		}
+	})
+
+	It("should remove the cache entry and purge the targets of deleted load balancers", func(ctx SpecContext) {
+		Expect(r.cleanUpDataplane(ctx, logr.Discard(), lb)).To(Succeed())
+
+		_, ok := cache.GetLoadBalancerServer(100, "10.0.0.100")
+		Expect(ok).To(BeFalse())
+		_, err := dpdkClient.GetLoadBalancer(ctx, string(lb.UID))
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)).To(BeTrue())
+	})
+
+	It("should remove the cache entry and purge the targets even if the dpservice loadbalancer cannot be deleted", func(ctx SpecContext) {
+		deleteErr = status.Error(codes.Unavailable, "dpservice unavailable")
+		Expect(r.cleanUpDataplane(ctx, logr.Discard(), lb)).NotTo(Succeed())
+
+		_, ok := cache.GetLoadBalancerServer(100, "10.0.0.100")
+		Expect(ok).To(BeFalse())
+		targets, err := dpdkClient.ListLoadBalancerTargets(ctx, string(lb.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targets.Items).To(BeEmpty())
+	})
+})
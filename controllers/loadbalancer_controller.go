@@ -7,16 +7,24 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"slices"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
@@ -27,12 +35,14 @@ import (
 	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
 	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
 	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
 	"github.com/ironcore-dev/metalnet/internal"
 	"github.com/ironcore-dev/metalnet/metalbond"
 )
 
 const (
-	loadBalancerFinalizer = "networking.metalnet.ironcore.dev/loadBalancer"
+	loadBalancerFinalizer     = "networking.metalnet.ironcore.dev/loadBalancer"
+	loadBalancerTombstoneKind = "LoadBalancer"
 )
 
 // LoadBalancerReconciler reconciles a LoadBalancer object
@@ -48,11 +58,35 @@ type LoadBalancerReconciler struct {
 	NodeName          string
 	PublicVNI         int
 	EnableIPv6Support bool
+
+	// PublicVNIIPv6 is the VNI public IPv6 LoadBalancers are announced into. If zero, PublicVNI is used.
+	PublicVNIIPv6 int
+
+	// RateLimiter limits how often the updates of a LoadBalancer are applied. If nil, updates are not limited.
+	RateLimiter *ObjectRateLimiter
+	// InitialSync is informed about the LoadBalancers reconciled after startup and holds them back until
+	// the Networks and NetworkInterfaces are reconciled.
+	InitialSync *InitialSync
+	// MaxConcurrentReconciles is the number of LoadBalancers reconciled in parallel. Zero reconciles one
+	// at a time.
+	MaxConcurrentReconciles int
+	// Resync periodically reconciles all LoadBalancers. If nil, LoadBalancers are only reconciled on watch events.
+	Resync *Resync
+	// Convergence measures the time from the changes of a LoadBalancer to it being announced and programmed.
+	// If nil, it is not measured.
+	Convergence *ConvergenceTracker
+	// Tombstones records the deleted LoadBalancers until their dataplane state is removed, see TombstoneKind.
+	// If nil, nothing is recorded.
+	Tombstones *Tombstones
 }
 
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancerippools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancerippools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *LoadBalancerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -61,15 +95,79 @@ func (r *LoadBalancerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	lb := &metalnetv1alpha1.LoadBalancer{}
 
 	if err := r.Get(ctx, req.NamespacedName, lb); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.RateLimiter.Forget(req.NamespacedName)
+			r.Convergence.Forget(req.NamespacedName)
+			r.InitialSync.Reconciled(lb, req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if nodeName := lb.Spec.NodeName; nodeName == nil || *nodeName != r.NodeName {
+	var nodeLabels labels.Set
+	if isActiveActive(lb) {
+		var err error
+		if nodeLabels, err = NodeLabels(ctx, r.Client, r.NodeName); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if !IsLoadBalancerOnNode(lb, r.NodeName, nodeLabels) {
+		if controllerutil.ContainsFinalizer(lb, r.nodeFinalizer()) {
+			log.V(1).Info("LoadBalancer is no longer scheduled to this node, cleaning up")
+			res, err := r.delete(ctx, log, lb)
+			return ignoreConflict(log, res, err)
+		}
 		log.V(1).Info("LoadBalancer is not assigned to this node", "NodeName", lb.Spec.NodeName)
+		r.InitialSync.Reconciled(lb, req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
-	return r.reconcileExists(ctx, log, lb)
+	if !r.InitialSync.tierReady(startupTierLoadBalancers) {
+		log.V(1).Info("Networks and network interfaces are not reconciled since startup yet, requeueing")
+		return ctrl.Result{RequeueAfter: initialSyncRequeueInterval}, nil
+	}
+
+	if paused, err := updatePausedCondition(ctx, r.Client, lb, &lb.Status.Conditions); err != nil || paused {
+		if paused {
+			log.V(1).Info("Reconciliation is paused")
+			r.InitialSync.Reconciled(lb, req.NamespacedName)
+		}
+		return ctrl.Result{}, err
+	}
+
+	res, err := r.reconcileExists(ctx, log, lb)
+	if err := recordReconcileResult(ctx, r.Client, lb, &lb.Status.ReconcileTimeline, err); err != nil {
+		log.Error(err, "Error recording reconcile result")
+	}
+	res, err = requeueOnError(log, res, err)
+	if (err == nil && !res.Requeue) || isValidationError(err) {
+		r.InitialSync.Reconciled(lb, req.NamespacedName)
+	}
+	if isActiveActive(lb) && apierrors.IsConflict(err) {
+		// The nodes programming an active-active LoadBalancer patch its status concurrently.
+		return ignoreConflict(log, ctrl.Result{}, err)
+	}
+	return res, err
+}
+
+func ignoreConflict(log logr.Logger, res ctrl.Result, err error) (ctrl.Result, error) {
+	if apierrors.IsConflict(err) {
+		log.V(1).Info("LoadBalancer was modified concurrently, requeueing")
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return res, err
+}
+
+// finalizer returns the finalizer the node places on the LoadBalancer. Active-active LoadBalancers carry a
+// finalizer per node they are programmed on.
+func (r *LoadBalancerReconciler) finalizer(lb *metalnetv1alpha1.LoadBalancer) string {
+	if isActiveActive(lb) {
+		return r.nodeFinalizer()
+	}
+	return loadBalancerFinalizer
+}
+
+func (r *LoadBalancerReconciler) nodeFinalizer() string {
+	return fmt.Sprintf("%s-%s", loadBalancerFinalizer, r.NodeName)
 }
 
 func (r *LoadBalancerReconciler) reconcileExists(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer) (ctrl.Result, error) {
@@ -83,30 +181,68 @@ func (r *LoadBalancerReconciler) reconcileExists(ctx context.Context, log logr.L
 func (r *LoadBalancerReconciler) delete(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer) (ctrl.Result, error) {
 	log.V(1).Info("Delete")
 
-	if !controllerutil.ContainsFinalizer(lb, loadBalancerFinalizer) {
+	finalizer := r.finalizer(lb)
+	if !controllerutil.ContainsFinalizer(lb, finalizer) {
 		log.V(1).Info("No finalizer present, nothing to do")
 		return ctrl.Result{}, nil
 	}
 	log.V(1).Info("Finalizer present, cleaning up")
 
+	if !lb.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Releasing loadbalancer ip allocations")
+		if err := r.releaseIP(ctx, log, lb); err != nil {
+			if apierrors.IsConflict(err) {
+				log.V(1).Info("Loadbalancer ip pool was modified concurrently, requeueing")
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, err
+		}
+		log.V(1).Info("Released loadbalancer ip allocations")
+	}
+
+	log.V(1).Info("Recording tombstone")
+	if err := r.Tombstones.Record(loadBalancerTombstoneKind, lb); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error recording tombstone: %w", err)
+	}
+
+	if err := r.cleanUpDataplane(ctx, log, lb); err != nil {
+		if !isForceDeleted(lb) {
+			return ctrl.Result{}, err
+		}
+		log.Info("Force removing finalizer, retrying cleanup in the background", "Error", err.Error())
+		if err := r.removeFinalizer(ctx, log, lb, finalizer); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Eventf(lb, corev1.EventTypeWarning, "ForceDeleted",
+			"Removed finalizer although the dataplane state could not be removed: %v", err)
+		return ctrl.Result{}, nil
+	}
+
+	log.V(1).Info("Removing finalizer")
+	if err := r.removeFinalizer(ctx, log, lb, finalizer); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Tombstones.Remove(loadBalancerTombstoneKind, lb.UID); err != nil {
+		log.Error(err, "Error removing tombstone, leaving it to be purged")
+	}
+	return ctrl.Result{}, nil
+}
+
+// cleanUpDataplane removes the dpservice state and the route of the LoadBalancer.
+func (r *LoadBalancerReconciler) cleanUpDataplane(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer) error {
+	// The LoadBalancer server is removed first, so the routes of its targets received while (or after failing
+	// at) removing the dpservice state are no longer applied to it.
+	log.V(1).Info("Removing LoadBalancer server", "ip", lb.Spec.IP.Addr.String())
+	r.MetalnetCache.RemoveLoadBalancer(lb.UID)
+
 	log.V(1).Info("Getting dpdk loadbalancer")
 	dpdkLoadBalancer, err := r.DPDK.GetLoadBalancer(ctx, string(lb.UID))
-	ip := lb.Spec.IP.Addr.String()
 	if err != nil {
 		if !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND) {
-			return ctrl.Result{}, fmt.Errorf("error getting dpdk loadbalancer: %w", err)
-		}
-		log.V(1).Info("Remove LoadBalancer server", "ip", ip)
-		if err := r.MetalnetCache.RemoveLoadBalancerServer(ip, lb.UID); err != nil {
-			return ctrl.Result{}, fmt.Errorf("error deleting dpdk loadbalancer from internal cache: %w", err)
-		}
-		log.V(1).Info("No dpdk loadbalancer, removing finalizer")
-		if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, lb, loadBalancerFinalizer); err != nil {
-			return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
+			return fmt.Errorf("error getting dpdk loadbalancer: %w", err)
 		}
-		log.V(1).Info("Removed finalizer")
-
-		return ctrl.Result{}, nil
+		log.V(1).Info("No dpdk loadbalancer, nothing to clean up")
+		return nil
 	}
 
 	vni := dpdkLoadBalancer.Spec.VNI
@@ -115,20 +251,44 @@ func (r *LoadBalancerReconciler) delete(ctx context.Context, log logr.Logger, lb
 
 	log.V(1).Info("Deleting LoadBalancer")
 	if err := r.deleteLoadBalancer(ctx, log, lb, vni, *underlayRoute); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting underlay route: %w", err)
+		return fmt.Errorf("error deleting underlay route: %w", err)
 	}
 	log.V(1).Info("Deleted Loadbalancer")
-	log.V(1).Info("Remove LoadBalancer server", "vni", vni, "ip", ip)
-	if err := r.MetalnetCache.RemoveLoadBalancerServer(ip, lb.UID); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting dpdk loadbalancer from internal cache: %w", err)
+	return nil
+}
+
+// TombstoneKind returns the TombstoneKind purging the dataplane state of LoadBalancers whose finalizer of
+// this node was removed before their dataplane state.
+func (r *LoadBalancerReconciler) TombstoneKind() TombstoneKind {
+	return TombstoneKind{
+		Kind:      loadBalancerTombstoneKind,
+		NewObject: func() client.Object { return &metalnetv1alpha1.LoadBalancer{} },
+		Finalizer: func(obj client.Object) string {
+			return r.finalizer(obj.(*metalnetv1alpha1.LoadBalancer))
+		},
+		Purge: func(ctx context.Context, log logr.Logger, obj client.Object) error {
+			return r.cleanUpDataplane(ctx, log, obj.(*metalnetv1alpha1.LoadBalancer))
+		},
 	}
+}
 
-	log.V(1).Info("Removing finalizer")
-	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, lb, loadBalancerFinalizer); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
+// removeFinalizer removes the given finalizer of the node. Active-active LoadBalancers additionally stop
+// listing the node as programming them.
+func (r *LoadBalancerReconciler) removeFinalizer(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer, finalizer string) error {
+	if slices.Contains(lb.Status.Nodes, r.NodeName) {
+		if err := r.patchStatus(ctx, lb, func() {
+			lb.Status.Nodes = slices.DeleteFunc(slices.Clone(lb.Status.Nodes), func(node string) bool {
+				return node == r.NodeName
+			})
+		}); err != nil {
+			return err
+		}
+	}
+	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, lb, finalizer); err != nil {
+		return fmt.Errorf("error removing finalizer: %w", err)
 	}
 	log.V(1).Info("Removed finalizer")
-	return ctrl.Result{}, nil
+	return nil
 }
 
 func (r *LoadBalancerReconciler) deleteLoadBalancer(
@@ -144,6 +304,12 @@ func (r *LoadBalancerReconciler) deleteLoadBalancer(
 	}
 	log.V(1).Info("Removed loadbalancer route if existed")
 
+	log.V(1).Info("Purging dpdk loadbalancer targets")
+	if err := r.purgeLoadBalancerTargets(ctx, lb); err != nil {
+		return err
+	}
+	log.V(1).Info("Purged dpdk loadbalancer targets")
+
 	log.V(1).Info("Deleting dpdk loadbalancer if exists")
 	if _, err := r.DPDK.DeleteLoadBalancer(
 		ctx,
@@ -158,15 +324,37 @@ func (r *LoadBalancerReconciler) deleteLoadBalancer(
 	return nil
 }
 
-func (r *LoadBalancerReconciler) removeLoadBalancerRouteIfExists(ctx context.Context, lb *metalnetv1alpha1.LoadBalancer, underlayRoute netip.Addr, vni uint32) error {
-	var localVni uint32
+// purgeLoadBalancerTargets deletes the targets left in the dpservice loadbalancer of the LoadBalancer, so none
+// of them outlive it.
+func (r *LoadBalancerReconciler) purgeLoadBalancerTargets(ctx context.Context, lb *metalnetv1alpha1.LoadBalancer) error {
+	targets, err := r.DPDK.ListLoadBalancerTargets(ctx, string(lb.UID), dpdkerrors.Ignore(dpdkerrors.NOT_FOUND, dpdkerrors.NO_LB))
+	if err != nil {
+		return fmt.Errorf("error listing loadbalancer targets: %w", err)
+	}
+	for _, target := range targets.Items {
+		if _, err := r.DPDK.DeleteLoadBalancerTarget(
+			ctx,
+			string(lb.UID),
+			target.Spec.TargetIP,
+			dpdkerrors.Ignore(dpdkerrors.NOT_FOUND, dpdkerrors.NO_BACKIP, dpdkerrors.NO_LB),
+		); err != nil {
+			return fmt.Errorf("error deleting loadbalancer target %s: %w", target.Spec.TargetIP, err)
+		}
+	}
+	return nil
+}
 
+// loadBalancerVNI returns the VNI the route of the LoadBalancer is announced into: the VNI of its Network if
+// it is internal, the public VNI otherwise.
+func (r *LoadBalancerReconciler) loadBalancerVNI(lb *metalnetv1alpha1.LoadBalancer, vni uint32) metalbond.VNI {
 	if lb.Spec.LBtype == metalnetv1alpha1.LoadBalancerTypeInternal {
-		localVni = vni
-	} else {
-		localVni = uint32(r.PublicVNI)
+		return metalbond.VNI(vni)
 	}
-	if err := r.RouteUtil.WithdrawRoute(ctx, metalbond.VNI(localVni), metalbond.Destination{
+	return publicVNIFor(lb.Spec.IP.Addr, r.PublicVNI, r.PublicVNIIPv6)
+}
+
+func (r *LoadBalancerReconciler) removeLoadBalancerRouteIfExists(ctx context.Context, lb *metalnetv1alpha1.LoadBalancer, underlayRoute netip.Addr, vni uint32) error {
+	if err := r.RouteUtil.WithdrawRoute(ctx, r.loadBalancerVNI(lb, vni), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(lb.Spec.IP.Addr),
 	}, metalbond.NextHop{
 		TargetVNI:     0,
@@ -178,14 +366,7 @@ func (r *LoadBalancerReconciler) removeLoadBalancerRouteIfExists(ctx context.Con
 }
 
 func (r *LoadBalancerReconciler) addLoadBalancerRouteIfNotExists(ctx context.Context, lb *metalnetv1alpha1.LoadBalancer, underlayRoute netip.Addr, vni uint32) error {
-	var localVni uint32
-
-	if lb.Spec.LBtype == metalnetv1alpha1.LoadBalancerTypeInternal {
-		localVni = vni
-	} else {
-		localVni = uint32(r.PublicVNI)
-	}
-	if err := r.RouteUtil.AnnounceRoute(ctx, metalbond.VNI(localVni), metalbond.Destination{
+	if err := r.RouteUtil.AnnounceRoute(ctx, r.loadBalancerVNI(lb, vni), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(lb.Spec.IP.Addr),
 	}, metalbond.NextHop{
 		TargetVNI:     0,
@@ -205,7 +386,12 @@ func (r *LoadBalancerReconciler) patchStatus(
 
 	mutate()
 
-	if err := r.Status().Patch(ctx, lb, client.MergeFrom(base)); err != nil {
+	patch := client.MergeFrom(base)
+	if isActiveActive(lb) {
+		// Several nodes patch the status, e.g. its nodes.
+		patch = client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})
+	}
+	if err := r.Status().Patch(ctx, lb, patch); err != nil {
 		return fmt.Errorf("error patching status: %w", err)
 	}
 	return nil
@@ -215,7 +401,7 @@ func (r *LoadBalancerReconciler) reconcile(ctx context.Context, log logr.Logger,
 	log.V(1).Info("Reconcile")
 
 	log.V(1).Info("Ensuring finalizer")
-	modified, err := clientutils.PatchEnsureFinalizer(ctx, r.Client, lb, loadBalancerFinalizer)
+	modified, err := clientutils.PatchEnsureFinalizer(ctx, r.Client, lb, r.finalizer(lb))
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error ensuring finalizer: %w", err)
 	}
@@ -225,15 +411,35 @@ func (r *LoadBalancerReconciler) reconcile(ctx context.Context, log logr.Logger,
 	}
 	log.V(1).Info("Ensured finalizer")
 
+	if delay := r.RateLimiter.Delay(lb); delay > 0 {
+		log.V(1).Info("Updates are rate limited, delaying", "Delay", delay)
+		if !isUpdateThrottled(lb.Status.Conditions) {
+			r.Eventf(lb, corev1.EventTypeWarning, "UpdateThrottled", "Updates are rate limited, delaying by %s", delay)
+		}
+		if err := r.patchStatus(ctx, lb, func() {
+			setUpdateThrottledCondition(&lb.Status.Conditions, lb.Generation)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	ctx = r.Convergence.Start(ctx, lb)
+
+	if !lb.Spec.IP.IsValid() {
+		log.V(1).Info("Loadbalancer has no ip, allocating one")
+		return r.allocateIP(ctx, log, lb)
+	}
+
 	if !r.EnableIPv6Support && lb.Spec.IP.Addr.Is6() {
 		if err := r.patchStatus(ctx, lb, func() {
 			lb.Status = metalnetv1alpha1.LoadBalancerStatus{
-				State: metalnetv1alpha1.LoadBalancerStateError,
+				State:             metalnetv1alpha1.LoadBalancerStateError,
+				ReconcileTimeline: lb.Status.ReconcileTimeline,
 			}
 		}); err != nil {
 			log.Error(err, "Error patching loadbalancer status")
 		}
-		return ctrl.Result{}, fmt.Errorf("ipv6 flag not enabled but ipv6 address set on loadbalancer")
+		return ctrl.Result{}, newValidationError(fmt.Errorf("ipv6 flag not enabled but ipv6 address set on loadbalancer"))
 	}
 
 	network := &metalnetv1alpha1.Network{}
@@ -247,7 +453,8 @@ func (r *LoadBalancerReconciler) reconcile(ctx context.Context, log logr.Logger,
 		r.Eventf(lb, corev1.EventTypeWarning, "NetworkNotFound", "Network %s could not be found", networkKey.Name)
 		if err := r.patchStatus(ctx, lb, func() {
 			lb.Status = metalnetv1alpha1.LoadBalancerStatus{
-				State: metalnetv1alpha1.LoadBalancerStatePending,
+				State:             metalnetv1alpha1.LoadBalancerStatePending,
+				ReconcileTimeline: lb.Status.ReconcileTimeline,
 			}
 		}); err != nil {
 			return ctrl.Result{}, err
@@ -260,10 +467,41 @@ func (r *LoadBalancerReconciler) reconcile(ctx context.Context, log logr.Logger,
 
 	log.V(1).Info("Applying loadbalancer")
 	underlayRoute, err := r.applyLoadBalancer(ctx, log, lb, vni)
+	if metalnetdpdk.IsCapacityError(err) {
+		if !meta.IsStatusConditionTrue(lb.Status.Conditions, metalnetv1alpha1.CapacityExceeded) {
+			r.Eventf(lb, corev1.EventTypeWarning, "CapacityExceeded", "Dpservice table is full: %v", err)
+		}
+		if err := r.patchStatus(ctx, lb, func() {
+			lb.Status.State = metalnetv1alpha1.LoadBalancerStatePending
+			meta.SetStatusCondition(&lb.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.CapacityExceeded,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: lb.Generation,
+				Reason:             metalnetv1alpha1.CapacityReasonTableFull,
+				Message:            err.Error(),
+			})
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, fmt.Errorf("error applying loadbalancer: %w", err)
+	}
+	if metalbond.IsRouteDeniedError(err) {
+		if !meta.IsStatusConditionTrue(lb.Status.Conditions, metalnetv1alpha1.AnnouncementDenied) {
+			r.Eventf(lb, corev1.EventTypeWarning, "AnnouncementDenied", "Announcement denied: %v", err)
+		}
+		if err := r.patchStatus(ctx, lb, func() {
+			lb.Status.State = metalnetv1alpha1.LoadBalancerStateError
+			setAnnouncementDeniedCondition(&lb.Status.Conditions, lb.Generation, err)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, fmt.Errorf("error applying loadbalancer: %w", err)
+	}
 	if err != nil {
 		if err := r.patchStatus(ctx, lb, func() {
 			lb.Status = metalnetv1alpha1.LoadBalancerStatus{
-				State: metalnetv1alpha1.LoadBalancerStateError,
+				State:             metalnetv1alpha1.LoadBalancerStateError,
+				ReconcileTimeline: lb.Status.ReconcileTimeline,
 			}
 		}); err != nil {
 			log.Error(err, "Error patching loadbalancer status")
@@ -275,13 +513,46 @@ func (r *LoadBalancerReconciler) reconcile(ctx context.Context, log logr.Logger,
 	log.V(1).Info("Patching status")
 	if err := r.patchStatus(ctx, lb, func() {
 		lb.Status.State = metalnetv1alpha1.LoadBalancerStateReady
+		recordProgrammed(&lb.Status.ReconcileTimeline, true)
+		if isActiveActive(lb) && !slices.Contains(lb.Status.Nodes, r.NodeName) {
+			lb.Status.Nodes = append(slices.Clone(lb.Status.Nodes), r.NodeName)
+			slices.Sort(lb.Status.Nodes)
+		}
+		meta.RemoveStatusCondition(&lb.Status.Conditions, metalnetv1alpha1.UpdateThrottled)
+		meta.RemoveStatusCondition(&lb.Status.Conditions, metalnetv1alpha1.CapacityExceeded)
+		meta.RemoveStatusCondition(&lb.Status.Conditions, metalnetv1alpha1.AnnouncementDenied)
 	}); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error patching status: %w", err)
 	}
+	r.Convergence.Programmed(lb)
 
 	return ctrl.Result{}, nil
 }
 
+// lbPortProtocols maps the protocols of LBPorts to the protocols of dpservice.
+var lbPortProtocols = map[string]dpdkproto.Protocol{
+	metalnetv1alpha1.LBPortProtocolTCP:  dpdkproto.Protocol_TCP,
+	metalnetv1alpha1.LBPortProtocolUDP:  dpdkproto.Protocol_UDP,
+	metalnetv1alpha1.LBPortProtocolSCTP: dpdkproto.Protocol_SCTP,
+}
+
+// dpdkLBPorts converts the given ports to dpservice ports. Protocols are matched case-insensitively, as
+// objects created before the validating webhook may use lower case protocols.
+func dpdkLBPorts(lbPorts []metalnetv1alpha1.LBPort) ([]dpdk.LBPort, error) {
+	var ports []dpdk.LBPort
+	for _, lbPort := range lbPorts {
+		protocol, ok := lbPortProtocols[strings.ToUpper(lbPort.Protocol)]
+		if !ok {
+			return nil, newValidationError(fmt.Errorf("unsupported protocol %q of port %d", lbPort.Protocol, lbPort.Port))
+		}
+		ports = append(ports, dpdk.LBPort{
+			Port:     uint32(lbPort.Port),
+			Protocol: uint32(protocol),
+		})
+	}
+	return ports, nil
+}
+
 func (r *LoadBalancerReconciler) applyLoadBalancer(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer, vni uint32) (netip.Addr, error) {
 	log.V(1).Info("Getting dpdk loadbalancer")
 	ip := lb.Spec.IP.Addr.String()
@@ -291,41 +562,46 @@ func (r *LoadBalancerReconciler) applyLoadBalancer(ctx context.Context, log logr
 			return netip.Addr{}, fmt.Errorf("error getting dpdk loadbalancer: %w", err)
 		}
 
-		var ports []dpdk.LBPort
-		for _, LBPort := range lb.Spec.Ports {
-			port := dpdk.LBPort{
-				Port:     uint32(LBPort.Port),
-				Protocol: uint32(dpdkproto.Protocol_value[LBPort.Protocol]),
-			}
-			ports = append(ports, port)
-		}
-
 		log.V(1).Info("DPDK loadbalancer does not yet exist, creating it")
-
-		lbalancer, err := r.DPDK.CreateLoadBalancer(ctx, &dpdk.LoadBalancer{
-			LoadBalancerMeta: dpdk.LoadBalancerMeta{ID: string(lb.UID)},
-			Spec: dpdk.LoadBalancerSpec{
-				VNI:     vni,
-				LbVipIP: &lb.Spec.IP.Addr,
-				Lbports: ports,
-			},
-		})
-		if err != nil {
-			return netip.Addr{}, fmt.Errorf("error creating dpdk loadbalancer: %w", err)
-		}
-		log.V(1).Info("Adding loadbalancer server", "vni", vni, "ip", ip)
-		if err := r.MetalnetCache.AddLoadBalancerServer(vni, ip, lb.UID); err != nil {
-			return netip.Addr{}, fmt.Errorf("error adding dpdk loadbalancer to internal cache: %w", err)
-		}
-		log.V(1).Info("Adding loadbalancer route if not exists")
-		if err := r.addLoadBalancerRouteIfNotExists(ctx, lb, *lbalancer.Spec.UnderlayRoute, vni); err != nil {
-			return netip.Addr{}, err
-		}
-		log.V(1).Info("Added loadbalancer route if not existed")
-		return *lbalancer.Spec.UnderlayRoute, nil
+		return r.createLoadBalancer(ctx, log, lb, vni)
 	}
 
 	log.V(1).Info("DPDK loadbalancer exists")
+	if lbalancer.Spec.VNI != vni {
+		return r.migrateLoadBalancer(ctx, log, lb, lbalancer, vni)
+	}
+
+	log.V(1).Info("Adding loadbalancer server", "vni", vni, "ip", ip)
+	if err := r.MetalnetCache.AddLoadBalancerServer(vni, ip, lb.UID); err != nil {
+		return netip.Addr{}, fmt.Errorf("error adding dpdk loadbalancer to internal cache: %w", err)
+	}
+	log.V(1).Info("Adding loadbalancer route if not exists")
+	if err := r.addLoadBalancerRouteIfNotExists(ctx, lb, *lbalancer.Spec.UnderlayRoute, vni); err != nil {
+		return netip.Addr{}, err
+	}
+	log.V(1).Info("Added loadbalancer route if not existed")
+	return *lbalancer.Spec.UnderlayRoute, nil
+}
+
+// createLoadBalancer creates the dpservice loadbalancer of the LoadBalancer in the given VNI and announces it.
+func (r *LoadBalancerReconciler) createLoadBalancer(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer, vni uint32) (netip.Addr, error) {
+	ports, err := dpdkLBPorts(lb.Spec.Ports)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	lbalancer, err := r.DPDK.CreateLoadBalancer(ctx, &dpdk.LoadBalancer{
+		LoadBalancerMeta: dpdk.LoadBalancerMeta{ID: string(lb.UID)},
+		Spec: dpdk.LoadBalancerSpec{
+			VNI:     vni,
+			LbVipIP: &lb.Spec.IP.Addr,
+			Lbports: ports,
+		},
+	})
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("error creating dpdk loadbalancer: %w", err)
+	}
+	ip := lb.Spec.IP.Addr.String()
 	log.V(1).Info("Adding loadbalancer server", "vni", vni, "ip", ip)
 	if err := r.MetalnetCache.AddLoadBalancerServer(vni, ip, lb.UID); err != nil {
 		return netip.Addr{}, fmt.Errorf("error adding dpdk loadbalancer to internal cache: %w", err)
@@ -338,18 +614,76 @@ func (r *LoadBalancerReconciler) applyLoadBalancer(ctx context.Context, log logr
 	return *lbalancer.Spec.UnderlayRoute, nil
 }
 
+// migrateLoadBalancer moves the dpservice loadbalancer of a LoadBalancer whose Network changed its VNI to the
+// given VNI. dpservice does not allow two loadbalancers with the same id, so the loadbalancer is recreated in
+// the new VNI and announced before the route of the previous VNI is withdrawn.
+func (r *LoadBalancerReconciler) migrateLoadBalancer(
+	ctx context.Context,
+	log logr.Logger,
+	lb *metalnetv1alpha1.LoadBalancer,
+	lbalancer *dpdk.LoadBalancer,
+	vni uint32,
+) (netip.Addr, error) {
+	previousVNI := lbalancer.Spec.VNI
+	previousUnderlayRoute := *lbalancer.Spec.UnderlayRoute
+	log = log.WithValues("PreviousVNI", previousVNI)
+
+	log.V(1).Info("VNI of dpdk loadbalancer changed, recreating it")
+	if _, err := r.DPDK.DeleteLoadBalancer(ctx, string(lb.UID), dpdkerrors.Ignore(dpdkerrors.NOT_FOUND)); err != nil {
+		return netip.Addr{}, fmt.Errorf("error deleting loadbalancer of previous vni: %w", err)
+	}
+	r.MetalnetCache.RemoveLoadBalancer(lb.UID)
+
+	underlayRoute, err := r.createLoadBalancer(ctx, log, lb, vni)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	if r.loadBalancerVNI(lb, previousVNI) != r.loadBalancerVNI(lb, vni) || previousUnderlayRoute != underlayRoute {
+		log.V(1).Info("Removing loadbalancer route of previous VNI if exists")
+		if err := r.removeLoadBalancerRouteIfExists(ctx, lb, previousUnderlayRoute, previousVNI); err != nil {
+			return netip.Addr{}, err
+		}
+		log.V(1).Info("Removed loadbalancer route of previous VNI if existed")
+	}
+	r.Eventf(lb, corev1.EventTypeNormal, "VNIMigrated", "Moved loadbalancer from VNI %d to VNI %d", previousVNI, vni)
+	return underlayRoute, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LoadBalancerReconciler) SetupWithManager(mgr ctrl.Manager, metalnetCache cache.Cache) error {
 	log := ctrl.Log.WithName("loadbalancer").WithName("setup")
 	ctx := ctrl.LoggerInto(context.TODO(), log)
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&metalnetv1alpha1.LoadBalancer{}).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&metalnetv1alpha1.LoadBalancer{}, builder.WithPredicates(
+			ignoreTimelineUpdates(func(lb *metalnetv1alpha1.LoadBalancer) *metalnetv1alpha1.ReconcileTimeline {
+				return &lb.Status.ReconcileTimeline
+			}),
+		)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		WatchesRawSource(
 			source.Kind(metalnetCache, &metalnetv1alpha1.Network{}),
 			r.enqueueLoadBalancersReferencingNetwork(ctx, log),
 		).
-		Complete(r)
+		Watches(
+			&metalnetv1alpha1.LoadBalancerIPPool{},
+			r.enqueueLoadBalancersWithoutIP(log),
+		).
+		Watches(
+			&corev1.Node{},
+			r.enqueueActiveActiveLoadBalancers(log),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == r.NodeName
+			})),
+		)
+	if r.Resync != nil {
+		b = b.WatchesRawSource(
+			&source.Channel{Source: r.Resync.Events(&metalnetv1alpha1.LoadBalancerList{})},
+			&handler.EnqueueRequestForObject{},
+		)
+	}
+	return b.Complete(withTracing("LoadBalancer", r))
 }
 
 func (r *LoadBalancerReconciler) enqueueLoadBalancersReferencingNetwork(ctx context.Context, log logr.Logger) handler.EventHandler {
@@ -371,3 +705,23 @@ func (r *LoadBalancerReconciler) enqueueLoadBalancersReferencingNetwork(ctx cont
 		return reqs
 	})
 }
+
+// enqueueActiveActiveLoadBalancers enqueues the active-active LoadBalancers on changes of the node, as their
+// node selectors may match or stop matching it.
+func (r *LoadBalancerReconciler) enqueueActiveActiveLoadBalancers(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		lbList := &metalnetv1alpha1.LoadBalancerList{}
+		if err := r.List(ctx, lbList); err != nil {
+			log.Error(err, "Error listing loadbalancers", "Node", obj.GetName())
+			return nil
+		}
+
+		var reqs []ctrl.Request
+		for _, lb := range lbList.Items {
+			if isActiveActive(&lb) {
+				reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&lb)})
+			}
+		}
+		return reqs
+	})
+}
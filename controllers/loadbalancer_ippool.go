@@ -0,0 +1,303 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/ipam"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+var (
+	errNoMatchingLoadBalancerIPPool = errors.New("no loadbalancer ip pool matches the loadbalancer")
+	errLoadBalancerIPPoolsExhausted = errors.New("all matching loadbalancer ip pools are exhausted")
+)
+
+// allocateIP allocates the IP of a LoadBalancer without one from a matching LoadBalancerIPPool.
+//
+// The allocation is recorded in the status of the pool before the IP is set in the spec of the
+// LoadBalancer, so concurrent allocations on other nodes do not hand out the same IP. The
+// LoadBalancer is reconciled again once its spec is updated.
+func (r *LoadBalancerReconciler) allocateIP(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer) (ctrl.Result, error) {
+	log.V(1).Info("Listing loadbalancer ip pools")
+	poolList := &metalnetv1alpha1.LoadBalancerIPPoolList{}
+	if err := r.List(ctx, poolList); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error listing loadbalancer ip pools: %w", err)
+	}
+
+	log.V(1).Info("Listing loadbalancers")
+	lbList := &metalnetv1alpha1.LoadBalancerList{}
+	if err := r.List(ctx, lbList); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error listing loadbalancers: %w", err)
+	}
+
+	log.V(1).Info("Getting namespace", "Namespace", lb.Namespace)
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: lb.Namespace}, namespace); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error getting namespace %s: %w", lb.Namespace, err)
+	}
+
+	pool, ip, err := allocateLoadBalancerIP(lb, namespace, poolList.Items, lbList.Items)
+	if err != nil {
+		reason := metalnetv1alpha1.IPAllocatedReasonPoolExhausted
+		if errors.Is(err, errNoMatchingLoadBalancerIPPool) {
+			reason = metalnetv1alpha1.IPAllocatedReasonNoMatchingPool
+		}
+		log.V(1).Info("Could not allocate loadbalancer ip", "Reason", reason)
+
+		if cond := meta.FindStatusCondition(lb.Status.Conditions, metalnetv1alpha1.LoadBalancerIPAllocated); cond == nil || cond.Reason != reason {
+			r.Eventf(lb, corev1.EventTypeWarning, "IPNotAllocated", "Could not allocate IP: %v", err)
+		}
+		if err := r.patchStatus(ctx, lb, func() {
+			lb.Status.State = metalnetv1alpha1.LoadBalancerStatePending
+			meta.SetStatusCondition(&lb.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.LoadBalancerIPAllocated,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: lb.Generation,
+				Reason:             reason,
+				Message:            fmt.Sprintf("Could not allocate IP: %v", err),
+			})
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Changes of the pools requeue the loadbalancer.
+		return ctrl.Result{}, nil
+	}
+
+	if !hasLoadBalancerIPPoolAllocation(pool, lb) {
+		log.V(1).Info("Recording loadbalancer ip allocation", "Pool", pool.Name, "IP", ip)
+		base := pool.DeepCopy()
+		pool.Status.Allocations = append(pool.Status.Allocations, metalnetv1alpha1.LoadBalancerIPPoolAllocation{
+			Namespace:        lb.Namespace,
+			LoadBalancerName: lb.Name,
+			IP:               ip,
+		})
+		sortLoadBalancerIPPoolAllocations(pool.Status.Allocations)
+		// Loadbalancers are allocated by the node they live on, so concurrent allocations must not overwrite each other.
+		if err := r.Status().Patch(ctx, pool, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			if apierrors.IsConflict(err) {
+				log.V(1).Info("Loadbalancer ip pool was modified concurrently, requeueing")
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("error recording loadbalancer ip allocation: %w", err)
+		}
+		log.V(1).Info("Recorded loadbalancer ip allocation")
+	}
+
+	log.V(1).Info("Setting allocated ip", "IP", ip)
+	base := lb.DeepCopy()
+	lb.Spec.IP = ip
+	if lb.Spec.IPFamily == "" {
+		lb.Spec.IPFamily = ip.Family()
+	}
+	if err := r.Patch(ctx, lb, client.MergeFrom(base)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error setting allocated ip: %w", err)
+	}
+	r.Eventf(lb, corev1.EventTypeNormal, "IPAllocated", "Allocated IP %s from pool %s", ip, pool.Name)
+
+	if err := r.patchStatus(ctx, lb, func() {
+		meta.SetStatusCondition(&lb.Status.Conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.LoadBalancerIPAllocated,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: lb.Generation,
+			Reason:             metalnetv1alpha1.IPAllocatedReasonAllocated,
+			Message:            fmt.Sprintf("Allocated IP %s from pool %s", ip, pool.Name),
+		})
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// releaseIP removes the allocations of the LoadBalancer from all LoadBalancerIPPools.
+func (r *LoadBalancerReconciler) releaseIP(ctx context.Context, log logr.Logger, lb *metalnetv1alpha1.LoadBalancer) error {
+	log.V(1).Info("Listing loadbalancer ip pools")
+	poolList := &metalnetv1alpha1.LoadBalancerIPPoolList{}
+	if err := r.List(ctx, poolList); err != nil {
+		return fmt.Errorf("error listing loadbalancer ip pools: %w", err)
+	}
+
+	for i := range poolList.Items {
+		pool := &poolList.Items[i]
+		if !hasLoadBalancerIPPoolAllocation(pool, lb) {
+			continue
+		}
+
+		log.V(1).Info("Releasing loadbalancer ip allocation", "Pool", pool.Name)
+		base := pool.DeepCopy()
+		var allocations []metalnetv1alpha1.LoadBalancerIPPoolAllocation
+		for _, allocation := range pool.Status.Allocations {
+			if !isLoadBalancerIPPoolAllocationOf(allocation, lb) {
+				allocations = append(allocations, allocation)
+			}
+		}
+		pool.Status.Allocations = allocations
+		if err := r.Status().Patch(ctx, pool, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return fmt.Errorf("error releasing loadbalancer ip allocation of pool %s: %w", pool.Name, err)
+		}
+		log.V(1).Info("Released loadbalancer ip allocation", "Pool", pool.Name)
+	}
+	return nil
+}
+
+// allocateLoadBalancerIP returns the pool and the IP to allocate to the given loadbalancer.
+//
+// An IP already allocated to the loadbalancer is returned again. Otherwise, the first free IP of the
+// first matching pool by name is allocated. IPs allocated by any pool or set on any other loadbalancer
+// are not free, and neither are the network and IPv4 broadcast addresses of the CIDRs of the pools.
+func allocateLoadBalancerIP(
+	lb *metalnetv1alpha1.LoadBalancer,
+	namespace *corev1.Namespace,
+	pools []metalnetv1alpha1.LoadBalancerIPPool,
+	lbs []metalnetv1alpha1.LoadBalancer,
+) (*metalnetv1alpha1.LoadBalancerIPPool, metalnetv1alpha1.IP, error) {
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	used := make(map[netip.Addr]bool)
+	for _, other := range lbs {
+		if other.Namespace == lb.Namespace && other.Name == lb.Name {
+			continue
+		}
+		if other.Spec.IP.IsValid() {
+			used[other.Spec.IP.Addr] = true
+		}
+	}
+	for i := range pools {
+		for _, allocation := range pools[i].Status.Allocations {
+			if !isLoadBalancerIPPoolAllocationOf(allocation, lb) {
+				used[allocation.IP.Addr] = true
+			}
+		}
+	}
+
+	var matching []*metalnetv1alpha1.LoadBalancerIPPool
+	for i := range pools {
+		if loadBalancerIPPoolMatches(&pools[i], lb, namespace) {
+			matching = append(matching, &pools[i])
+		}
+	}
+	if len(matching) == 0 {
+		return nil, metalnetv1alpha1.IP{}, errNoMatchingLoadBalancerIPPool
+	}
+
+	for _, pool := range matching {
+		for _, allocation := range pool.Status.Allocations {
+			if isLoadBalancerIPPoolAllocationOf(allocation, lb) && !used[allocation.IP.Addr] && loadBalancerIPPoolContains(pool, allocation.IP) {
+				return pool, allocation.IP, nil
+			}
+		}
+	}
+
+	for _, pool := range matching {
+		for _, cidr := range pool.Spec.CIDRs {
+			if lb.Spec.IPFamily != "" && cidr.IP().Family() != lb.Spec.IPFamily {
+				continue
+			}
+			if addr, ok := ipam.FirstFreeAddr(cidr.Prefix, used); ok {
+				return pool, metalnetv1alpha1.IP{Addr: addr}, nil
+			}
+		}
+	}
+	return nil, metalnetv1alpha1.IP{}, errLoadBalancerIPPoolsExhausted
+}
+
+// loadBalancerIPPoolMatches reports whether the loadbalancer may allocate from the pool.
+func loadBalancerIPPoolMatches(pool *metalnetv1alpha1.LoadBalancerIPPool, lb *metalnetv1alpha1.LoadBalancer, namespace *corev1.Namespace) bool {
+	if !pool.DeletionTimestamp.IsZero() {
+		return false
+	}
+	if lb.Spec.IPPoolRef != nil && lb.Spec.IPPoolRef.Name != pool.Name {
+		return false
+	}
+	if pool.Spec.Type != nil && *pool.Spec.Type != lb.Spec.LBtype {
+		return false
+	}
+	if pool.Spec.NamespaceSelector == nil {
+		return true
+	}
+	sel, err := metav1.LabelSelectorAsSelector(pool.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return sel.Matches(labels.Set(namespace.Labels))
+}
+
+func loadBalancerIPPoolContains(pool *metalnetv1alpha1.LoadBalancerIPPool, ip metalnetv1alpha1.IP) bool {
+	for _, cidr := range pool.Spec.CIDRs {
+		if cidr.Contains(ip.Addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLoadBalancerIPPoolAllocation(pool *metalnetv1alpha1.LoadBalancerIPPool, lb *metalnetv1alpha1.LoadBalancer) bool {
+	for _, allocation := range pool.Status.Allocations {
+		if isLoadBalancerIPPoolAllocationOf(allocation, lb) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoadBalancerIPPoolAllocationOf(allocation metalnetv1alpha1.LoadBalancerIPPoolAllocation, lb *metalnetv1alpha1.LoadBalancer) bool {
+	return allocation.Namespace == lb.Namespace && allocation.LoadBalancerName == lb.Name
+}
+
+func sortLoadBalancerIPPoolAllocations(allocations []metalnetv1alpha1.LoadBalancerIPPoolAllocation) {
+	sort.Slice(allocations, func(i, j int) bool {
+		if allocations[i].Namespace != allocations[j].Namespace {
+			return allocations[i].Namespace < allocations[j].Namespace
+		}
+		return allocations[i].LoadBalancerName < allocations[j].LoadBalancerName
+	})
+}
+
+func (r *LoadBalancerReconciler) enqueueLoadBalancersWithoutIP(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		lbList := &metalnetv1alpha1.LoadBalancerList{}
+		if err := r.List(ctx, lbList); err != nil {
+			log.Error(err, "Error listing loadbalancers", "LoadBalancerIPPool", obj.GetName())
+			return nil
+		}
+
+		var (
+			reqs              []ctrl.Request
+			nodeLabels        labels.Set
+			nodeLabelsFetched bool
+		)
+		for i := range lbList.Items {
+			lb := &lbList.Items[i]
+			if lb.Spec.IP.IsValid() {
+				continue
+			}
+			if isActiveActive(lb) && !nodeLabelsFetched {
+				var err error
+				if nodeLabels, err = NodeLabels(ctx, r.Client, r.NodeName); err != nil {
+					log.Error(err, "Error getting node labels", "LoadBalancerIPPool", obj.GetName())
+					return nil
+				}
+				nodeLabelsFetched = true
+			}
+			if IsLoadBalancerOnNode(lb, r.NodeName, nodeLabels) {
+				reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(lb)})
+			}
+		}
+		return reqs
+	})
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+var _ = Describe("LoadBalancer IP allocation", Label("loadbalancer"), func() {
+	var namespace *corev1.Namespace
+
+	BeforeEach(func() {
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"tenant": "a"}},
+		}
+	})
+
+	newPool := func(name string, cidrs ...string) metalnetv1alpha1.LoadBalancerIPPool {
+		pool := metalnetv1alpha1.LoadBalancerIPPool{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		for _, cidr := range cidrs {
+			pool.Spec.CIDRs = append(pool.Spec.CIDRs, metalnetv1alpha1.MustParseIPPrefix(cidr))
+		}
+		return pool
+	}
+
+	newLB := func(name string) *metalnetv1alpha1.LoadBalancer {
+		return &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       metalnetv1alpha1.LoadBalancerSpec{LBtype: metalnetv1alpha1.LoadBalancerTypePublic},
+		}
+	}
+
+	It("should allocate the first free ip of the first matching pool", func() {
+		poolA := newPool("a", "45.0.0.0/31")
+		poolA.Status.Allocations = []metalnetv1alpha1.LoadBalancerIPPoolAllocation{
+			{Namespace: "default", LoadBalancerName: "other", IP: metalnetv1alpha1.MustParseIP("45.0.0.0")},
+		}
+		other := newLB("explicit")
+		other.Spec.IP = metalnetv1alpha1.MustParseIP("45.0.0.1")
+
+		pool, ip, err := allocateLoadBalancerIP(newLB("lb"), namespace,
+			[]metalnetv1alpha1.LoadBalancerIPPool{newPool("b", "46.0.0.0/30"), poolA},
+			[]metalnetv1alpha1.LoadBalancer{*other},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pool.Name).To(Equal("b"))
+		Expect(ip).To(Equal(metalnetv1alpha1.MustParseIP("46.0.0.1")))
+	})
+
+	It("should not allocate the network and ipv4 broadcast addresses", func() {
+		pool := newPool("a", "45.0.0.0/30", "fd00::/126")
+		pool.Status.Allocations = []metalnetv1alpha1.LoadBalancerIPPoolAllocation{
+			{Namespace: "default", LoadBalancerName: "other", IP: metalnetv1alpha1.MustParseIP("45.0.0.1")},
+			{Namespace: "default", LoadBalancerName: "another", IP: metalnetv1alpha1.MustParseIP("45.0.0.2")},
+		}
+
+		lb := newLB("lb")
+		lb.Spec.IPFamily = corev1.IPv4Protocol
+		_, _, err := allocateLoadBalancerIP(lb, namespace, []metalnetv1alpha1.LoadBalancerIPPool{pool}, nil)
+		Expect(err).To(MatchError(errLoadBalancerIPPoolsExhausted))
+
+		lb.Spec.IPFamily = corev1.IPv6Protocol
+		_, ip, err := allocateLoadBalancerIP(lb, namespace, []metalnetv1alpha1.LoadBalancerIPPool{pool}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ip).To(Equal(metalnetv1alpha1.MustParseIP("fd00::1")))
+	})
+
+	It("should return an existing allocation again", func() {
+		pool := newPool("a", "45.0.0.0/30")
+		pool.Status.Allocations = []metalnetv1alpha1.LoadBalancerIPPoolAllocation{
+			{Namespace: "default", LoadBalancerName: "lb", IP: metalnetv1alpha1.MustParseIP("45.0.0.2")},
+		}
+
+		_, ip, err := allocateLoadBalancerIP(newLB("lb"), namespace, []metalnetv1alpha1.LoadBalancerIPPool{pool}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ip).To(Equal(metalnetv1alpha1.MustParseIP("45.0.0.2")))
+	})
+
+	It("should only allocate from pools matching the namespace, type, family and pool reference", func() {
+		internal := metalnetv1alpha1.LoadBalancerTypeInternal
+		byType := newPool("a", "45.0.0.0/32")
+		byType.Spec.Type = &internal
+		byNamespace := newPool("b", "46.0.0.0/32")
+		byNamespace.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}}
+		byFamily := newPool("c", "47.0.0.0/32", "fd00::/127")
+		referenced := newPool("d", "48.0.0.0/32")
+
+		lb := newLB("lb")
+		lb.Spec.IPFamily = corev1.IPv6Protocol
+		pools := []metalnetv1alpha1.LoadBalancerIPPool{byType, byNamespace, byFamily, referenced}
+		pool, ip, err := allocateLoadBalancerIP(lb, namespace, pools, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pool.Name).To(Equal("c"))
+		Expect(ip).To(Equal(metalnetv1alpha1.MustParseIP("fd00::")))
+
+		lb.Spec.IPPoolRef = &corev1.LocalObjectReference{Name: "d"}
+		lb.Spec.IPFamily = ""
+		pool, ip, err = allocateLoadBalancerIP(lb, namespace, pools, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pool.Name).To(Equal("d"))
+		Expect(ip).To(Equal(metalnetv1alpha1.MustParseIP("48.0.0.0")))
+	})
+
+	It("should report whether no pool matches or all matching pools are exhausted", func() {
+		pool := newPool("a", "45.0.0.0/32")
+		pool.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}}
+		_, _, err := allocateLoadBalancerIP(newLB("lb"), namespace, []metalnetv1alpha1.LoadBalancerIPPool{pool}, nil)
+		Expect(err).To(MatchError(errNoMatchingLoadBalancerIPPool))
+
+		pool = newPool("a", "45.0.0.0/32")
+		pool.Status.Allocations = []metalnetv1alpha1.LoadBalancerIPPoolAllocation{
+			{Namespace: "default", LoadBalancerName: "other", IP: metalnetv1alpha1.MustParseIP("45.0.0.0")},
+		}
+		_, _, err = allocateLoadBalancerIP(newLB("lb"), namespace, []metalnetv1alpha1.LoadBalancerIPPool{pool}, nil)
+		Expect(err).To(MatchError(errLoadBalancerIPPoolsExhausted))
+	})
+})
+
+var _ = Describe("LoadBalancer IP pool changes", Label("loadbalancer"), func() {
+	It("should requeue the loadbalancers without ip scheduled to the node", func(ctx SpecContext) {
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"role": "gateway"}}}
+		newLB := func(name string, spec metalnetv1alpha1.LoadBalancerSpec) *metalnetv1alpha1.LoadBalancer {
+			return &metalnetv1alpha1.LoadBalancer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}, Spec: spec}
+		}
+		byName := newLB("by-name", metalnetv1alpha1.LoadBalancerSpec{NodeName: ptr.To("node")})
+		bySelector := newLB("by-selector", metalnetv1alpha1.LoadBalancerSpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "gateway"}},
+		})
+		withIP := newLB("with-ip", metalnetv1alpha1.LoadBalancerSpec{NodeName: ptr.To("node"), IP: metalnetv1alpha1.MustParseIP("45.0.0.1")})
+		otherNode := newLB("other-node", metalnetv1alpha1.LoadBalancerSpec{NodeName: ptr.To("other")})
+		otherSelector := newLB("other-selector", metalnetv1alpha1.LoadBalancerSpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}},
+		})
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(node, byName, bySelector, withIP, otherNode, otherSelector).Build()
+
+		r := &LoadBalancerReconciler{Client: c, NodeName: "node"}
+		queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		DeferCleanup(queue.ShutDown)
+		pool := &metalnetv1alpha1.LoadBalancerIPPool{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+		r.enqueueLoadBalancersWithoutIP(logr.Discard()).Create(ctx, event.CreateEvent{Object: pool}, queue)
+
+		var reqs []ctrl.Request
+		for queue.Len() > 0 {
+			item, _ := queue.Get()
+			reqs = append(reqs, item.(ctrl.Request))
+			queue.Done(item)
+		}
+		Expect(reqs).To(ConsistOf(
+			ctrl.Request{NamespacedName: client.ObjectKeyFromObject(byName)},
+			ctrl.Request{NamespacedName: client.ObjectKeyFromObject(bySelector)},
+		))
+	})
+})
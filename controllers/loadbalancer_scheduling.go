@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+// NodeLabels returns the labels of the node with the given name. A node that does not exist, e.g. in
+// standalone mode, has no labels.
+func NodeLabels(ctx context.Context, c client.Reader, nodeName string) (labels.Set, error) {
+	node := &corev1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return nil, fmt.Errorf("error getting node %s: %w", nodeName, err)
+		}
+		return nil, nil
+	}
+	if node.Labels == nil {
+		return labels.Set{}, nil
+	}
+	return node.Labels, nil
+}
+
+// IsLoadBalancerOnNode reports whether the LoadBalancer is scheduled to the node with the given name and
+// labels, either by its node name or by its node selector. Invalid node selectors match no node.
+func IsLoadBalancerOnNode(lb *metalnetv1alpha1.LoadBalancer, nodeName string, nodeLabels labels.Set) bool {
+	if lb.Spec.NodeSelector == nil {
+		return lb.Spec.NodeName != nil && *lb.Spec.NodeName == nodeName
+	}
+	if nodeLabels == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(lb.Spec.NodeSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(nodeLabels)
+}
+
+// isActiveActive reports whether the LoadBalancer may be programmed on several nodes at once.
+func isActiveActive(lb *metalnetv1alpha1.LoadBalancer) bool {
+	return lb.Spec.NodeSelector != nil
+}
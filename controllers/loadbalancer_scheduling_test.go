@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+var _ = Describe("LoadBalancer scheduling", Label("loadbalancer"), func() {
+	It("should schedule load balancers by their node name", func() {
+		lb := &metalnetv1alpha1.LoadBalancer{Spec: metalnetv1alpha1.LoadBalancerSpec{NodeName: ptr.To("node-1")}}
+		Expect(IsLoadBalancerOnNode(lb, "node-1", nil)).To(BeTrue())
+		Expect(IsLoadBalancerOnNode(lb, "node-2", labels.Set{"role": "gateway"})).To(BeFalse())
+	})
+
+	It("should schedule active-active load balancers to all nodes matching their node selector", func() {
+		lb := &metalnetv1alpha1.LoadBalancer{Spec: metalnetv1alpha1.LoadBalancerSpec{
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "gateway"}},
+		}}
+		Expect(IsLoadBalancerOnNode(lb, "node-1", labels.Set{"role": "gateway"})).To(BeTrue())
+		Expect(IsLoadBalancerOnNode(lb, "node-2", labels.Set{"role": "gateway", "zone": "a"})).To(BeTrue())
+		Expect(IsLoadBalancerOnNode(lb, "node-3", labels.Set{"role": "worker"})).To(BeFalse())
+
+		By("not scheduling them without node")
+		lb.Spec.NodeSelector = &metav1.LabelSelector{}
+		Expect(IsLoadBalancerOnNode(lb, "node-1", labels.Set{})).To(BeTrue())
+		Expect(IsLoadBalancerOnNode(lb, "node-1", nil)).To(BeFalse())
+	})
+})
@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// MaintenanceReconciler puts the node into maintenance while its Node carries the maintenance annotation
+// or maintenance is forced. In maintenance, all routes of the node are withdrawn and its NetworkInterfaces
+// and LoadBalancers get the NodeInMaintenance condition. Their dpservice state is kept untouched.
+type MaintenanceReconciler struct {
+	client.Client
+
+	RouteUtil *metalbond.MaintenanceRouteUtil
+
+	NodeName string
+	// Forced keeps the node in maintenance regardless of the annotation.
+	Forced bool
+}
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers/status,verbs=get;update;patch
+
+func (r *MaintenanceReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	inMaintenance, err := r.isInMaintenance(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	log = log.WithValues("InMaintenance", inMaintenance)
+
+	if inMaintenance != r.RouteUtil.InMaintenance() {
+		if inMaintenance {
+			log.Info("Entering maintenance, withdrawing all announcements")
+		} else {
+			log.Info("Leaving maintenance, announcing all routes")
+		}
+		if err := r.RouteUtil.SetInMaintenance(ctx, inMaintenance); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error updating announcements: %w", err)
+		}
+	}
+
+	log.V(1).Info("Updating maintenance condition of local objects")
+	if err := r.updateConditions(ctx, inMaintenance); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.V(1).Info("Updated maintenance condition of local objects")
+	return ctrl.Result{}, nil
+}
+
+func (r *MaintenanceReconciler) isInMaintenance(ctx context.Context) (bool, error) {
+	if r.Forced {
+		return true, nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.NodeName}, node); err != nil {
+		if err := client.IgnoreNotFound(err); err != nil {
+			return false, fmt.Errorf("error getting node %s: %w", r.NodeName, err)
+		}
+		return false, nil
+	}
+	return node.Annotations[metalnetv1alpha1.MaintenanceAnnotation] == "true", nil
+}
+
+func (r *MaintenanceReconciler) updateConditions(ctx context.Context, inMaintenance bool) error {
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList); err != nil {
+		return fmt.Errorf("error listing network interfaces: %w", err)
+	}
+	lbList := &metalnetv1alpha1.LoadBalancerList{}
+	if err := r.List(ctx, lbList); err != nil {
+		return fmt.Errorf("error listing loadbalancers: %w", err)
+	}
+
+	var errs []error
+	for i := range nicList.Items {
+		nic := &nicList.Items[i]
+		if !r.isLocal(nic.Spec.NodeName) {
+			continue
+		}
+		if err := r.patchCondition(ctx, nic, &nic.Status.Conditions, inMaintenance); err != nil {
+			errs = append(errs, fmt.Errorf("error patching network interface %s: %w", client.ObjectKeyFromObject(nic), err))
+		}
+	}
+	nodeLabels, err := NodeLabels(ctx, r.Client, r.NodeName)
+	if err != nil {
+		return err
+	}
+	for i := range lbList.Items {
+		lb := &lbList.Items[i]
+		if !IsLoadBalancerOnNode(lb, r.NodeName, nodeLabels) {
+			continue
+		}
+		if err := r.patchCondition(ctx, lb, &lb.Status.Conditions, inMaintenance); err != nil {
+			errs = append(errs, fmt.Errorf("error patching loadbalancer %s: %w", client.ObjectKeyFromObject(lb), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *MaintenanceReconciler) isLocal(nodeName *string) bool {
+	return nodeName != nil && *nodeName == r.NodeName
+}
+
+func (r *MaintenanceReconciler) patchCondition(ctx context.Context, obj client.Object, conditions *[]metav1.Condition, inMaintenance bool) error {
+	hasCondition := meta.FindStatusCondition(*conditions, metalnetv1alpha1.NodeInMaintenance) != nil
+	if hasCondition == inMaintenance {
+		return nil
+	}
+
+	base := obj.DeepCopyObject().(client.Object)
+	if inMaintenance {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.NodeInMaintenance,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             metalnetv1alpha1.MaintenanceReasonAnnouncementsWithdrawn,
+			Message:            fmt.Sprintf("Node %s is in maintenance, routes are not announced", r.NodeName),
+		})
+	} else {
+		meta.RemoveStatusCondition(conditions, metalnetv1alpha1.NodeInMaintenance)
+	}
+	return r.Status().Patch(ctx, obj, client.MergeFrom(base))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MaintenanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	log := ctrl.Log.WithName("maintenance").WithName("setup")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("maintenance").
+		For(
+			&corev1.Node{},
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == r.NodeName
+			})),
+		).
+		Watches(
+			&metalnetv1alpha1.NetworkInterface{},
+			r.enqueueNode(log),
+		).
+		Watches(
+			&metalnetv1alpha1.LoadBalancer{},
+			r.enqueueNode(log),
+		).
+		Complete(r)
+}
+
+// enqueueNode enqueues the node so that new local objects get the maintenance condition as well.
+func (r *MaintenanceReconciler) enqueueNode(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		if !r.RouteUtil.InMaintenance() && !r.Forced {
+			return nil
+		}
+		log.V(2).Info("Enqueueing node for object", "ObjectKey", client.ObjectKeyFromObject(obj))
+		return []ctrl.Request{{NamespacedName: client.ObjectKey{Name: r.NodeName}}}
+	})
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// natPortCollectTimeout is the timeout of collecting the NAT port metrics from dpservice.
+const natPortCollectTimeout = 10 * time.Second
+
+var natPortsAllocatedDesc = prometheus.NewDesc(
+	"metalnet_nat_ports_allocated",
+	"Number of ports of a NAT IP programmed into dpservice, by scope (local for the interfaces of this node, "+
+		"neighbor for the interfaces of other nodes).",
+	[]string{"nat_ip", "scope"}, nil,
+)
+
+// NATPortCollector is a prometheus collector reporting the ports of the NAT IPs used on this node as
+// programmed into dpservice. Together with the port blocks of the InternetGateways, it shows how close
+// a NAT IP is to running out of ports.
+type NATPortCollector struct {
+	client.Reader
+	DPDK     dpdkclient.Client
+	NodeName string
+
+	log logr.Logger
+}
+
+func NewNATPortCollector(c client.Reader, dpdk dpdkclient.Client, nodeName string) *NATPortCollector {
+	return &NATPortCollector{
+		Reader:   c,
+		DPDK:     dpdk,
+		NodeName: nodeName,
+		log:      ctrl.Log.WithName("nat-ports"),
+	}
+}
+
+func (c *NATPortCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- natPortsAllocatedDesc
+}
+
+func (c *NATPortCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), natPortCollectTimeout)
+	defer cancel()
+
+	natIPs, err := c.natIPs(ctx)
+	if err != nil {
+		c.log.Error(err, "Error listing nat ips")
+		return
+	}
+
+	for _, natIP := range natIPs {
+		local, err := c.DPDK.ListLocalNats(ctx, &natIP)
+		if err != nil {
+			c.log.Error(err, "Error listing local nats", "NatIP", natIP)
+			continue
+		}
+		neighbor, err := c.DPDK.ListNeighborNats(ctx, &natIP)
+		if err != nil {
+			c.log.Error(err, "Error listing neighbor nats", "NatIP", natIP)
+			continue
+		}
+
+		var localPorts, neighborPorts float64
+		for _, nat := range local.Items {
+			localPorts += float64(nat.Spec.MaxPort - nat.Spec.MinPort + 1)
+		}
+		for _, nat := range neighbor.Items {
+			neighborPorts += float64(nat.Spec.MaxPort - nat.Spec.MinPort + 1)
+		}
+		ch <- prometheus.MustNewConstMetric(natPortsAllocatedDesc, prometheus.GaugeValue, localPorts, natIP.String(), "local")
+		ch <- prometheus.MustNewConstMetric(natPortsAllocatedDesc, prometheus.GaugeValue, neighborPorts, natIP.String(), "neighbor")
+	}
+}
+
+// natIPs returns the NAT IPs of the network interfaces on this node.
+func (c *NATPortCollector) natIPs(ctx context.Context) ([]netip.Addr, error) {
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := c.List(ctx, nicList); err != nil {
+		return nil, err
+	}
+
+	natIPs := sets.New[netip.Addr]()
+	for _, nic := range nicList.Items {
+		if nic.Spec.NodeName == nil || *nic.Spec.NodeName != c.NodeName {
+			continue
+		}
+		if nat := nic.Status.NatIP; nat != nil && nat.IP != nil {
+			natIPs.Insert(nat.IP.Addr)
+		}
+	}
+	res := natIPs.UnsortedList()
+	slices.SortFunc(res, netip.Addr.Compare)
+	return res, nil
+}
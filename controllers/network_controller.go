@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"slices"
 
 	"github.com/go-logr/logr"
 	"github.com/ironcore-dev/controller-utils/clientutils"
@@ -16,6 +17,7 @@ import (
 	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
 	"github.com/ironcore-dev/metalnet/internal"
 	"github.com/ironcore-dev/metalnet/metalbond"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -23,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -34,51 +37,82 @@ const (
 	networkFinalizer = "networking.metalnet.ironcore.dev/network"
 )
 
-// NetworkReconciler reconciles metalnetv1alpha1.Network.
+// NetworkReconciler reconciles metalnetv1alpha1.Network and metalnetv1alpha1.ClusterNetwork.
 type NetworkReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
 	DPDK dpdkclient.Client
 
-	RouteUtil        metalbond.RouteUtil
+	RouteUtil metalbond.RouteUtil
+	// Subscriptions reference counts the metalbond subscriptions of the VNIs. Every Network holds the
+	// subscriptions of its VNI and its peered VNIs while its VNI is in use by local objects.
+	Subscriptions    *metalbond.Subscriptions
 	MetalnetCache    *internal.MetalnetCache
 	MetalnetMBClient *metalbond.MetalnetClient
 
 	DefaultRouterAddr *metalbond.DefaultRouterAddress
 	NodeName          string
 	EnableIPv6Support bool
+
+	// InitialSync defers subscribing to the VNIs of the networks until the local objects are reconciled.
+	InitialSync *InitialSync
+	// MaxConcurrentReconciles is the number of Networks reconciled in parallel. Zero reconciles one at a time.
+	MaxConcurrentReconciles int
+	// Resync periodically reconciles all Networks. If nil, Networks are only reconciled on watch events.
+	Resync *Resync
 }
 
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networks/finalizers,verbs=update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=clusternetworks,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=clusternetworks/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=clusternetworks/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
-	network := &metalnetv1alpha1.Network{}
-	if err := r.Get(ctx, req.NamespacedName, network); err != nil {
+	// Requests without namespace are for ClusterNetworks.
+	obj := newNetworkObject(req.NamespacedName)
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.InitialSync.Reconciled(obj, req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	return r.reconcileExists(ctx, log, network)
+	if isPaused(obj) {
+		log.V(1).Info("Reconciliation is paused")
+		r.InitialSync.Reconciled(obj, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	res, err := r.reconcileExists(ctx, log, obj, networkOf(obj))
+	res, err = requeueOnError(log, res, err)
+	if err == nil && !res.Requeue {
+		// Deferring the subscription until the initial sync is complete still counts as reconciled.
+		r.InitialSync.Reconciled(obj, req.NamespacedName)
+	}
+	return res, err
 }
 
-func (r *NetworkReconciler) reconcileExists(ctx context.Context, log logr.Logger, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
+// reconcileExists reconciles the given Network. obj is the Network or ClusterNetwork it was read from, which
+// carries the finalizer.
+func (r *NetworkReconciler) reconcileExists(ctx context.Context, log logr.Logger, obj client.Object, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
 	log = log.WithValues("VNI", network.Spec.ID)
 	if !network.DeletionTimestamp.IsZero() {
-		return r.delete(ctx, log, network)
+		return r.delete(ctx, log, obj, network)
 	}
-	return r.reconcile(ctx, log, network)
+	return r.reconcile(ctx, log, obj, network)
 }
 
-func (r *NetworkReconciler) delete(ctx context.Context, log logr.Logger, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
+func (r *NetworkReconciler) delete(ctx context.Context, log logr.Logger, obj client.Object, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
 	log.V(1).Info("Delete")
 
-	if !controllerutil.ContainsFinalizer(network, r.networkFinalizer()) {
+	if !controllerutil.ContainsFinalizer(obj, r.networkFinalizer()) {
 		log.V(1).Info("No finalizer present, nothing to do.")
 		return ctrl.Result{}, nil
 	}
@@ -87,11 +121,19 @@ func (r *NetworkReconciler) delete(ctx context.Context, log logr.Logger, network
 
 	vni := uint32(network.Spec.ID)
 
-	log.V(1).Info("Unsubscribing from metalbond if not subscribed")
-	if err := r.unsubscribeIfSubscribed(ctx, vni); err != nil {
+	for _, previous := range r.previousVNIs(obj, vni) {
+		log.V(1).Info("Cleaning up previous VNI", "PreviousVNI", previous)
+		if err := r.cleanUpPreviousVNI(ctx, log, obj, network, uint32(previous)); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.V(1).Info("Cleaned up previous VNI", "PreviousVNI", previous)
+	}
+
+	log.V(1).Info("Releasing metalbond subscriptions")
+	if err := r.Subscriptions.ReleaseAll(ctx, subscriptionHolder(obj)); err != nil {
 		return ctrl.Result{}, err
 	}
-	log.V(1).Info("Unsubscribed from metalbond if subscribed")
+	log.V(1).Info("Released metalbond subscriptions")
 
 	log.V(1).Info("Deleting default route if exists")
 	if err := r.deleteDefaultRouteIfExists(ctx, vni); err != nil {
@@ -104,9 +146,15 @@ func (r *NetworkReconciler) delete(ctx context.Context, log logr.Logger, network
 		return ctrl.Result{}, err
 	}
 	log.V(1).Info("Deleted peered VNIs")
+	r.MetalnetCache.RemoveNetwork(vni)
+
+	log.V(1).Info("Removing node from status")
+	if recorded, err := r.setNodeVNIs(ctx, log, obj, nil); err != nil || !recorded {
+		return ctrl.Result{Requeue: !recorded}, err
+	}
 
 	log.V(1).Info("Cleanup done, removing finalizer")
-	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, network, r.networkFinalizer()); err != nil {
+	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, obj, r.networkFinalizer()); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
 	}
 
@@ -114,11 +162,11 @@ func (r *NetworkReconciler) delete(ctx context.Context, log logr.Logger, network
 	return ctrl.Result{}, nil
 }
 
-func (r *NetworkReconciler) reconcile(ctx context.Context, log logr.Logger, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
+func (r *NetworkReconciler) reconcile(ctx context.Context, log logr.Logger, obj client.Object, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
 	log.V(1).Info("Reconcile")
 
 	log.V(1).Info("Ensuring finalizer")
-	modified, err := clientutils.PatchEnsureFinalizer(ctx, r.Client, network, r.networkFinalizer())
+	modified, err := clientutils.PatchEnsureFinalizer(ctx, r.Client, obj, r.networkFinalizer())
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error ensuring finalizer: %w", err)
 	}
@@ -136,17 +184,32 @@ func (r *NetworkReconciler) reconcile(ctx context.Context, log logr.Logger, netw
 		return ctrl.Result{}, err
 	}
 
+	log.V(1).Info("Migrating from previous VNIs if changed")
+	migrating, err := r.migrateVNI(ctx, log, obj, network, vni, vniAvail.Spec.InUse)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error migrating from previous vni: %w", err)
+	}
+	var res ctrl.Result
+	if migrating {
+		res.RequeueAfter = vniMigrationRequeueInterval
+	}
+
 	if !vniAvail.Spec.InUse {
-		if !r.MetalnetCache.IsVniPeered(vni) {
-			log.V(1).Info("VNI doesn't exist in dp-service and no peering, unsubscribe from it")
-			if err := r.unsubscribeIfSubscribed(ctx, vni); err != nil {
-				return ctrl.Result{}, err
-			}
-			log.V(1).Info("VNI doesn't exist in dp-service and no peering, unsubscribed from it")
+		if migrating {
+			// The NetworkInterfaces did not move yet, keep the subscriptions of the previous VNI.
+			log.V(1).Info("VNI doesn't exist in dp-service yet, previous VNI is still in use")
+			return res, nil
+		}
+
+		// Peering Networks hold the subscription of the VNI themselves while they need its routes.
+		log.V(1).Info("VNI doesn't exist in dp-service, releasing metalbond subscriptions")
+		if err := r.Subscriptions.ReleaseAll(ctx, subscriptionHolder(obj)); err != nil {
+			return ctrl.Result{}, err
 		}
+		log.V(1).Info("VNI doesn't exist in dp-service, released metalbond subscriptions")
 
 		log.V(1).Info("Reconciling peered VNIs")
-		if err := r.reconcilePeeredVNIs(ctx, log, network, vni, vniAvail.Spec.InUse); err != nil {
+		if err := r.reconcilePeeredVNIs(ctx, log, obj, network, vni, vniAvail.Spec.InUse); err != nil {
 			return ctrl.Result{}, err
 		}
 		log.V(1).Info("Reconciled peered VNIs")
@@ -154,35 +217,99 @@ func (r *NetworkReconciler) reconcile(ctx context.Context, log logr.Logger, netw
 	}
 	log.V(1).Info("Checked existence of the VNI")
 
-	log.V(1).Info("Creating dpdk default route if not exists")
-	if err := r.createDefaultRoutesIfNotExist(ctx, vni); err != nil {
+	log.V(1).Info("Reconciling dpdk default routes")
+	if err := r.reconcileDefaultRoutes(ctx, log, network, vni); err != nil {
 		return ctrl.Result{}, err
 	}
-	log.V(1).Info("Created dpdk default route if not existed")
+	log.V(1).Info("Reconciled dpdk default routes")
 
 	log.V(1).Info("Reconciling peered VNIs")
-	if err := r.reconcilePeeredVNIs(ctx, log, network, vni, vniAvail.Spec.InUse); err != nil {
+	if err := r.reconcilePeeredVNIs(ctx, log, obj, network, vni, vniAvail.Spec.InUse); err != nil {
 		return ctrl.Result{}, err
 	}
 	log.V(1).Info("Reconciled peered VNIs")
 
+	if !r.InitialSync.Done() {
+		log.V(1).Info("Initial sync is not complete, deferring subscription")
+		return ctrl.Result{RequeueAfter: initialSyncRequeueInterval}, nil
+	}
+
 	log.V(1).Info("Subscribing to metalbond if not subscribed")
-	if err := r.subscribeIfNotSubscribed(ctx, vni); err != nil {
+	if err := r.Subscriptions.Acquire(ctx, subscriptionHolder(obj), metalbond.VNI(vni)); err != nil {
 		return ctrl.Result{}, err
 	}
 	log.V(1).Info("Subscribed to metalbond if not subscribed")
 
-	return ctrl.Result{}, nil
+	return res, nil
 }
 
-func (r *NetworkReconciler) createDefaultRoutesIfNotExist(ctx context.Context, vni uint32) error {
-	defaultRoutePrefix := netip.MustParsePrefix("0.0.0.0/0")
-	defaultIPv6RoutePrefix := netip.MustParsePrefix("::/0")
+// ownDefaultRoute returns the default route of the Network if its next hop VNI is the VNI of the Network or one
+// of its peered VNIs.
+func ownDefaultRoute(network *metalnetv1alpha1.Network) *metalnetv1alpha1.DefaultRoute {
+	defaultRoute := network.Spec.DefaultRoute
+	if defaultRoute == nil {
+		return nil
+	}
+	if defaultRoute.NextHopVNI != network.Spec.ID && !slices.Contains(network.Spec.PeeredIDs, defaultRoute.NextHopVNI) {
+		return nil
+	}
+	return defaultRoute
+}
+
+// reconcileDefaultRoutes points the default routes of the VNI to the own default route of the Network or, if it
+// has none, to the default router of the public VNI.
+func (r *NetworkReconciler) reconcileDefaultRoutes(ctx context.Context, log logr.Logger, network *metalnetv1alpha1.Network, vni uint32) error {
+	// Hold the default router, so it is not changed for the VNI while its default routes are reconciled.
 	r.DefaultRouterAddr.RWMutex.RLock()
 	defer r.DefaultRouterAddr.RWMutex.RUnlock()
 
-	if !r.DefaultRouterAddr.RouterAddress.IsValid() {
-		return fmt.Errorf("default router address is invalid")
+	defaultRoute := ownDefaultRoute(network)
+	var nextHop dpdk.RouteNextHop
+	if defaultRoute != nil {
+		gateway := defaultRoute.Gateway.Addr
+		nextHop = dpdk.RouteNextHop{VNI: uint32(defaultRoute.NextHopVNI), IP: &gateway}
+	} else {
+		if network.Spec.DefaultRoute != nil {
+			log.Info("Next hop VNI of the default route is not peered, using the default router",
+				"NextHopVNI", network.Spec.DefaultRoute.NextHopVNI)
+		}
+		if !r.DefaultRouterAddr.RouterAddress.IsValid() {
+			return fmt.Errorf("default router address is invalid")
+		}
+		routerAddress := r.DefaultRouterAddr.RouterAddress
+		nextHop = dpdk.RouteNextHop{VNI: vni, IP: &routerAddress}
+	}
+	r.MetalnetCache.SetOwnDefaultRoute(vni, defaultRoute != nil)
+
+	prefixes := []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0")}
+	if r.EnableIPv6Support {
+		prefixes = append(prefixes, netip.MustParsePrefix("::/0"))
+	}
+	routes, err := r.DPDK.ListRoutes(ctx, vni)
+	if err != nil {
+		return fmt.Errorf("error listing routes: %w", err)
+	}
+	for _, prefix := range prefixes {
+		if err := r.applyDefaultRoute(ctx, log, vni, prefix, nextHop, routes.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *NetworkReconciler) applyDefaultRoute(ctx context.Context, log logr.Logger, vni uint32, prefix netip.Prefix, nextHop dpdk.RouteNextHop, routes []dpdk.Route) error {
+	for _, route := range routes {
+		if route.Spec.Prefix == nil || *route.Spec.Prefix != prefix || route.Spec.NextHop == nil {
+			continue
+		}
+		if route.Spec.NextHop.VNI == nextHop.VNI && route.Spec.NextHop.IP != nil && *route.Spec.NextHop.IP == *nextHop.IP {
+			return nil
+		}
+
+		log.V(1).Info("Replacing default route", "Prefix", prefix, "NextHopVNI", nextHop.VNI, "NextHopIP", *nextHop.IP)
+		if _, err := r.DPDK.DeleteRoute(ctx, vni, &prefix, dpdkerrors.Ignore(dpdkerrors.ROUTE_NOT_FOUND)); err != nil {
+			return fmt.Errorf("error deleting %s route: %w", prefix, err)
+		}
 	}
 
 	if _, err := r.DPDK.CreateRoute(ctx, &dpdk.Route{
@@ -190,35 +317,13 @@ func (r *NetworkReconciler) createDefaultRoutesIfNotExist(ctx context.Context, v
 			VNI: vni,
 		},
 		Spec: dpdk.RouteSpec{
-			Prefix: &defaultRoutePrefix,
-			NextHop: &dpdk.RouteNextHop{
-				VNI: vni,
-				IP:  &r.DefaultRouterAddr.RouterAddress,
-			},
+			Prefix:  &prefix,
+			NextHop: &nextHop,
 		},
 	},
 		dpdkerrors.Ignore(dpdkerrors.ROUTE_EXISTS),
 	); err != nil {
-		return fmt.Errorf("error creating ipv4 route: %w", err)
-	}
-
-	if r.EnableIPv6Support {
-		if _, err := r.DPDK.CreateRoute(ctx, &dpdk.Route{
-			RouteMeta: dpdk.RouteMeta{
-				VNI: vni,
-			},
-			Spec: dpdk.RouteSpec{
-				Prefix: &defaultIPv6RoutePrefix,
-				NextHop: &dpdk.RouteNextHop{
-					VNI: vni,
-					IP:  &r.DefaultRouterAddr.RouterAddress,
-				},
-			},
-		},
-			dpdkerrors.Ignore(dpdkerrors.ROUTE_EXISTS),
-		); err != nil {
-			return fmt.Errorf("error creating ipv6 route: %w", err)
-		}
+		return fmt.Errorf("error creating %s route: %w", prefix, err)
 	}
 	return nil
 }
@@ -247,11 +352,9 @@ func (r *NetworkReconciler) deleteDefaultRouteIfExists(ctx context.Context, vni
 	return nil
 }
 
-func (r *NetworkReconciler) subscribeIfNotSubscribed(ctx context.Context, vni uint32) error {
-	if err := r.RouteUtil.Subscribe(ctx, metalbond.VNI(vni)); metalbond.IgnoreAlreadySubscribedToVNIError(err) != nil {
-		return fmt.Errorf("error subscribing to vni: %w", err)
-	}
-	return nil
+// subscriptionHolder returns the holder of the metalbond subscriptions of the given Network or ClusterNetwork.
+func subscriptionHolder(obj client.Object) string {
+	return fmt.Sprintf("%T %s", obj, client.ObjectKeyFromObject(obj))
 }
 
 func (r *NetworkReconciler) setDifference(s1, s2 sets.Set[uint32]) sets.Set[uint32] {
@@ -264,7 +367,7 @@ func (r *NetworkReconciler) setDifference(s1, s2 sets.Set[uint32]) sets.Set[uint
 	return diff
 }
 
-func (r *NetworkReconciler) reconcilePeeredVNIs(ctx context.Context, log logr.Logger, network *metalnetv1alpha1.Network, vni uint32, ownVniAvail bool) error {
+func (r *NetworkReconciler) reconcilePeeredVNIs(ctx context.Context, log logr.Logger, obj client.Object, network *metalnetv1alpha1.Network, vni uint32, ownVniAvail bool) error {
 	log.V(1).Info("reconcilePeeredVNIs", "vni", vni, "ownVniAvail", ownVniAvail)
 
 	// the ok flag is ignored because the existence of the VNI is already checked before this function is called
@@ -272,17 +375,31 @@ func (r *NetworkReconciler) reconcilePeeredVNIs(ctx context.Context, log logr.Lo
 
 	// prepare peered prefixes
 	peeredPrefixes := map[uint32][]netip.Prefix{}
+	peeringPrefixes := map[uint32]internal.PeeringPrefixes{}
 	if len(network.Spec.PeeredPrefixes) > 0 {
 		for _, prefixes := range network.Spec.PeeredPrefixes {
 			peeredVni := uint32(prefixes.ID)
 
-			peeredPrefixes[peeredVni] = []netip.Prefix{}
-			for _, prefix := range prefixes.Prefixes {
-				peeredPrefixes[peeredVni] = append(peeredPrefixes[peeredVni], prefix.Prefix)
+			if len(prefixes.Prefixes) > 0 {
+				peeredPrefixes[peeredVni] = []netip.Prefix{}
+				for _, prefix := range prefixes.Prefixes {
+					peeredPrefixes[peeredVni] = append(peeredPrefixes[peeredVni], prefix.Prefix)
+				}
+			}
+			if len(prefixes.ExportPrefixes) > 0 || len(prefixes.ImportPrefixes) > 0 {
+				peeringPrefixes[peeredVni] = internal.PeeringPrefixes{
+					Export: netIPPrefixes(prefixes.ExportPrefixes),
+					Import: netIPPrefixes(prefixes.ImportPrefixes),
+				}
 			}
 		}
 	}
 	r.MetalnetCache.SetPeeredPrefixes(vni, peeredPrefixes)
+	if r.MetalnetCache.SetPeeringPrefixes(vni, peeringPrefixes) && ownVniAvail {
+		if err := r.reapplyPeeringPrefixes(ctx, log, vni); err != nil {
+			return err
+		}
+	}
 
 	specPeerVnis := sets.New[uint32]()
 	if network.Spec.PeeredIDs != nil {
@@ -319,21 +436,19 @@ func (r *NetworkReconciler) reconcilePeeredVNIs(ctx context.Context, log logr.Lo
 			if err := r.MetalnetCache.RemoveVniFromPeerVnis(vni, peeredVNI); err != nil {
 				return err
 			}
-			if !peeredVniAvail.Spec.InUse {
-				if err := r.unsubscribeIfSubscribed(ctx, peeredVNI); err != nil {
-					return err
-				}
-			} else if peeredVniAvail.Spec.InUse && ownVniAvail {
+			if err := r.Subscriptions.Release(ctx, subscriptionHolder(obj), metalbond.VNI(peeredVNI)); err != nil {
+				return err
+			}
+			// Unsubscribing does not withdraw the installed routes, so remove the routes to the peered VNI.
+			if ownVniAvail {
 				if err := r.MetalnetMBClient.CleanupNotPeeredRoutes(vni); err != nil {
 					return err
 				}
+			}
+			if peeredVniAvail.Spec.InUse {
 				if err := r.MetalnetMBClient.CleanupNotPeeredRoutes(peeredVNI); err != nil {
 					return err
 				}
-			} else {
-				if err := r.MetalnetMBClient.CleanupNotPeeredRoutes(vni); err != nil {
-					return err
-				}
 			}
 		}
 
@@ -350,12 +465,11 @@ func (r *NetworkReconciler) reconcilePeeredVNIs(ctx context.Context, log logr.Lo
 			if err := r.MetalnetCache.AddVniToPeerVnis(vni, peeredVNI); err != nil {
 				return err
 			}
-			if ownVniAvail && !peeredVniAvail.Spec.InUse {
-				if err := r.subscribeIfNotSubscribed(ctx, peeredVNI); err != nil {
-					return err
-				}
+			// Hold the peered VNI even if it is in use, so it stays subscribed to once it is no longer in use.
+			if err := r.Subscriptions.Acquire(ctx, subscriptionHolder(obj), metalbond.VNI(peeredVNI)); err != nil {
+				return err
 			}
-			if ownVniAvail && peeredVniAvail.Spec.InUse {
+			if peeredVniAvail.Spec.InUse {
 				if err := r.recycleVNISubscription(ctx, vni); err != nil {
 					return err
 				}
@@ -383,11 +497,7 @@ func (r *NetworkReconciler) deletePeeredVNIs(ctx context.Context, log logr.Logge
 		if err != nil {
 			return err
 		}
-		if !vniAvail.Spec.InUse {
-			if err := r.unsubscribeIfSubscribed(ctx, peeredVNI); err != nil {
-				return err
-			}
-		} else {
+		if vniAvail.Spec.InUse {
 			if err := r.MetalnetMBClient.CleanupNotPeeredRoutes(peeredVNI); err != nil {
 				return err
 			}
@@ -396,25 +506,49 @@ func (r *NetworkReconciler) deletePeeredVNIs(ctx context.Context, log logr.Logge
 	return nil
 }
 
-func (r *NetworkReconciler) recycleVNISubscription(ctx context.Context, vni uint32) error {
-	if err := r.RouteUtil.GetRoutesForVni(ctx, metalbond.VNI(vni)); err != nil {
-		return fmt.Errorf("error getting routes for vni: %w", err)
+// netIPPrefixes returns the prefixes as netip.Prefix.
+func netIPPrefixes(prefixes []metalnetv1alpha1.IPPrefix) []netip.Prefix {
+	var res []netip.Prefix
+	for _, prefix := range prefixes {
+		res = append(res, prefix.Prefix)
+	}
+	return res
+}
+
+// reapplyPeeringPrefixes removes the routes no longer exchanged between the VNI and its peered VNIs and receives
+// the routes of the VNI and its peered VNIs again to install the routes that became exchanged.
+func (r *NetworkReconciler) reapplyPeeringPrefixes(ctx context.Context, log logr.Logger, vni uint32) error {
+	log.V(1).Info("Peering prefixes changed, reapplying routes of peered VNIs")
+	peeredVNIs, _ := r.MetalnetCache.GetPeerVnis(vni)
+	for _, v := range append([]uint32{vni}, peeredVNIs.UnsortedList()...) {
+		if err := r.MetalnetMBClient.CleanupNotExchangedRoutes(ctx, v); err != nil {
+			return err
+		}
+		if err := r.recycleVNISubscription(ctx, v); err != nil {
+			return err
+		}
 	}
+	log.V(1).Info("Reapplied routes of peered VNIs")
 	return nil
 }
 
-func (r *NetworkReconciler) unsubscribeIfSubscribed(ctx context.Context, vni uint32) error {
-	if err := r.RouteUtil.Unsubscribe(ctx, metalbond.VNI(vni)); metalbond.IgnoreAlreadyUnsubscribedToVNIError(err) != nil {
-		return fmt.Errorf("error unsubscribing to vni: %w", err)
+func (r *NetworkReconciler) recycleVNISubscription(ctx context.Context, vni uint32) error {
+	if err := r.RouteUtil.GetRoutesForVni(ctx, metalbond.VNI(vni)); err != nil {
+		return fmt.Errorf("error getting routes for vni: %w", err)
 	}
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NetworkReconciler) SetupWithManager(mgr ctrl.Manager, metalnetCache cache.Cache) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&metalnetv1alpha1.Network{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		WithEventFilter(predicate.ResourceVersionChangedPredicate{}).
+		WatchesRawSource(
+			source.Kind(metalnetCache, &metalnetv1alpha1.ClusterNetwork{}),
+			&handler.EnqueueRequestForObject{},
+		).
 		WatchesRawSource(
 			source.Kind(metalnetCache, &metalnetv1alpha1.NetworkInterface{}),
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNetworkInterface),
@@ -424,8 +558,17 @@ func (r *NetworkReconciler) SetupWithManager(mgr ctrl.Manager, metalnetCache cac
 			source.Kind(metalnetCache, &metalnetv1alpha1.LoadBalancer{}),
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForLoadBalancer),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
-		).
-		Complete(r)
+		)
+	if r.Resync != nil {
+		b = b.WatchesRawSource(
+			&source.Channel{Source: r.Resync.Events(&metalnetv1alpha1.NetworkList{})},
+			&handler.EnqueueRequestForObject{},
+		).WatchesRawSource(
+			&source.Channel{Source: r.Resync.Events(&metalnetv1alpha1.ClusterNetworkList{})},
+			&handler.EnqueueRequestForObject{},
+		)
+	}
+	return b.Complete(withTracing("Network", r))
 }
 
 func (r *NetworkReconciler) findObjectsForNetworkInterface(ctx context.Context, obj client.Object) []reconcile.Request {
@@ -434,12 +577,7 @@ func (r *NetworkReconciler) findObjectsForNetworkInterface(ctx context.Context,
 		return []reconcile.Request{}
 	}
 
-	return []reconcile.Request{{
-		NamespacedName: types.NamespacedName{
-			Name:      networkInterface.Spec.NetworkRef.Name,
-			Namespace: networkInterface.GetNamespace(),
-		},
-	}}
+	return []reconcile.Request{{NamespacedName: networkInterfaceNetworkKey(networkInterface)}}
 }
 
 func (r *NetworkReconciler) networkFinalizer() string {
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Network default route", func() {
+	It("should point the default route to the own default route of the network while it is peered", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		// Routes can only be created in VNIs in use.
+		ip := netip.MustParseAddr("10.0.0.1")
+		_, err = dpdkClient.CreateInterface(ctx, &dpdk.Interface{
+			InterfaceMeta: dpdk.InterfaceMeta{ID: "nic"},
+			Spec:          dpdk.InterfaceSpec{VNI: 100, Device: "net_tap4", IPv4: &ip},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		log := logr.Discard()
+		cache := internal.NewMetalnetCache(&log)
+		r := &NetworkReconciler{
+			DPDK:          dpdkClient,
+			MetalnetCache: cache,
+			DefaultRouterAddr: &metalbond.DefaultRouterAddress{
+				RouterAddress: netip.MustParseAddr("2001:db8::1"),
+				PublicVNI:     200,
+			},
+		}
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec: metalnetv1alpha1.NetworkSpec{
+				ID:        100,
+				PeeredIDs: []int32{300},
+				DefaultRoute: &metalnetv1alpha1.DefaultRoute{
+					NextHopVNI: 300,
+					Gateway:    metalnetv1alpha1.MustParseIP("2001:db8::2"),
+				},
+			},
+		}
+		defaultRouteNextHop := func() dpdk.RouteNextHop {
+			routes, err := dpdkClient.ListRoutes(ctx, 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routes.Items).To(HaveLen(1))
+			Expect(*routes.Items[0].Spec.Prefix).To(Equal(netip.MustParsePrefix("0.0.0.0/0")))
+			return *routes.Items[0].Spec.NextHop
+		}
+
+		By("reconciling the own default route")
+		Expect(r.reconcileDefaultRoutes(ctx, log, network, 100)).To(Succeed())
+		Expect(defaultRouteNextHop().VNI).To(Equal(uint32(300)))
+		Expect(*defaultRouteNextHop().IP).To(Equal(netip.MustParseAddr("2001:db8::2")))
+		Expect(cache.HasOwnDefaultRoute(100)).To(BeTrue())
+
+		By("falling back to the default router once the next hop VNI is no longer peered")
+		network.Spec.PeeredIDs = nil
+		Expect(r.reconcileDefaultRoutes(ctx, log, network, 100)).To(Succeed())
+		Expect(defaultRouteNextHop().VNI).To(Equal(uint32(100)))
+		Expect(*defaultRouteNextHop().IP).To(Equal(netip.MustParseAddr("2001:db8::1")))
+		Expect(cache.HasOwnDefaultRoute(100)).To(BeFalse())
+
+		By("keeping an up-to-date default route")
+		Expect(r.reconcileDefaultRoutes(ctx, log, network, 100)).To(Succeed())
+		Expect(*defaultRouteNextHop().IP).To(Equal(netip.MustParseAddr("2001:db8::1")))
+	})
+})
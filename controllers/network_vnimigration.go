@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// vniMigrationRequeueInterval is the interval Networks are requeued at while the local objects still use
+// their previous VNI.
+const vniMigrationRequeueInterval = 5 * time.Second
+
+// nodeVNIs returns the VNIs the given Network is recorded to be programmed with on this node.
+func (r *NetworkReconciler) nodeVNIs(obj client.Object) (metalnetv1alpha1.NetworkNodeStatus, bool) {
+	for _, node := range networkOf(obj).Status.Nodes {
+		if node.NodeName == r.NodeName {
+			return node, true
+		}
+	}
+	return metalnetv1alpha1.NetworkNodeStatus{}, false
+}
+
+// setNodeVNIs records the VNIs the given Network is programmed with on this node. If node is nil, the node is
+// removed from the status. The VNIs are recorded in the status rather than in memory, so the state of
+// previous VNIs is still cleaned up after a restart. It returns false if the Network was modified
+// concurrently.
+func (r *NetworkReconciler) setNodeVNIs(ctx context.Context, log logr.Logger, obj client.Object, node *metalnetv1alpha1.NetworkNodeStatus) (bool, error) {
+	status := networkStatusOf(obj)
+	nodes := slices.DeleteFunc(slices.Clone(status.Nodes), func(node metalnetv1alpha1.NetworkNodeStatus) bool {
+		return node.NodeName == r.NodeName
+	})
+	if node != nil {
+		nodes = append(nodes, *node)
+		slices.SortFunc(nodes, func(a, b metalnetv1alpha1.NetworkNodeStatus) int {
+			return strings.Compare(a.NodeName, b.NodeName)
+		})
+	}
+	if slices.EqualFunc(status.Nodes, nodes, func(a, b metalnetv1alpha1.NetworkNodeStatus) bool {
+		return a.NodeName == b.NodeName && a.VNI == b.VNI && slices.Equal(a.PreviousVNIs, b.PreviousVNIs)
+	}) {
+		return true, nil
+	}
+
+	log.V(1).Info("Recording VNIs of the node")
+	base := obj.DeepCopyObject().(client.Object)
+	status.Nodes = nodes
+	// The controller runs on every node, so concurrent updates must not overwrite each other.
+	if err := r.Status().Patch(ctx, obj, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+		if apierrors.IsConflict(err) {
+			log.V(1).Info("Network was modified concurrently")
+			return false, nil
+		}
+		return false, fmt.Errorf("error recording vnis of the node: %w", err)
+	}
+	log.V(1).Info("Recorded VNIs of the node")
+	return true, nil
+}
+
+// previousVNIs returns the VNIs the given Network had on this node before its VNI changed to vni, including
+// those of earlier changes whose state is not cleaned up yet.
+func (r *NetworkReconciler) previousVNIs(obj client.Object, vni uint32) []int32 {
+	node, ok := r.nodeVNIs(obj)
+	if !ok {
+		return nil
+	}
+	previous := slices.Clone(node.PreviousVNIs)
+	if node.VNI != 0 && !slices.Contains(previous, node.VNI) {
+		previous = append(previous, node.VNI)
+	}
+	// The Network may move back to a previous VNI.
+	return slices.DeleteFunc(previous, func(previous int32) bool { return uint32(previous) == vni })
+}
+
+// migrateVNI tears down the state of the previous VNIs of a Network whose VNI changed, once no local object
+// uses them anymore. Until then, the subscription, the default route and the peerings of a previous VNI are
+// kept, so the NetworkInterfaces moving to the new VNI keep their connectivity. The VNI is only recorded for
+// the node while local objects use it. It returns whether the migration is still in progress.
+func (r *NetworkReconciler) migrateVNI(ctx context.Context, log logr.Logger, obj client.Object, network *metalnetv1alpha1.Network, vni uint32, inUse bool) (bool, error) {
+	var (
+		remaining []int32
+		errs      []error
+	)
+	for _, previous := range r.previousVNIs(obj, vni) {
+		log := log.WithValues("PreviousVNI", previous)
+
+		log.V(1).Info("Checking existence of the previous VNI")
+		previousAvail, err := r.DPDK.GetVni(ctx, uint32(previous), 0)
+		if err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, previous)
+			continue
+		}
+		if previousAvail.Spec.InUse {
+			log.V(1).Info("Previous VNI is still in use, keeping its state")
+			remaining = append(remaining, previous)
+			// The subscription is held in memory only and has to be acquired again after a restart.
+			if r.InitialSync.Done() {
+				if err := r.Subscriptions.Acquire(ctx, subscriptionHolder(obj), metalbond.VNI(previous)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		if err := r.cleanUpPreviousVNI(ctx, log, obj, network, uint32(previous)); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, previous)
+			continue
+		}
+		log.V(1).Info("Migrated from previous VNI")
+	}
+
+	var node *metalnetv1alpha1.NetworkNodeStatus
+	if inUse || len(remaining) > 0 {
+		node = &metalnetv1alpha1.NetworkNodeStatus{
+			NodeName:     r.NodeName,
+			VNI:          int32(vni),
+			PreviousVNIs: remaining,
+		}
+	}
+	recorded, err := r.setNodeVNIs(ctx, log, obj, node)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return len(remaining) > 0 || !recorded, errors.Join(errs...)
+}
+
+// cleanUpPreviousVNI removes the state of the previous VNI of a Network.
+func (r *NetworkReconciler) cleanUpPreviousVNI(ctx context.Context, log logr.Logger, obj client.Object, network *metalnetv1alpha1.Network, previous uint32) error {
+	log.V(1).Info("Releasing metalbond subscription of the previous VNI")
+	if err := r.Subscriptions.Release(ctx, subscriptionHolder(obj), metalbond.VNI(previous)); err != nil {
+		return fmt.Errorf("error releasing subscription of previous vni: %w", err)
+	}
+
+	log.V(1).Info("Deleting default route of the previous VNI if exists")
+	if err := r.deleteDefaultRouteIfExists(ctx, previous); err != nil {
+		return fmt.Errorf("error deleting default route of previous vni: %w", err)
+	}
+
+	log.V(1).Info("Deleting peered VNIs of the previous VNI")
+	if err := r.deletePeeredVNIs(ctx, log, network, previous); err != nil {
+		return fmt.Errorf("error deleting peered vnis of previous vni: %w", err)
+	}
+	r.MetalnetCache.RemoveNetwork(previous)
+	return nil
+}
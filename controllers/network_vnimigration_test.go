@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// subscribingRouteUtil accepts all subscriptions. Calling any other method panics.
+type subscribingRouteUtil struct {
+	metalbond.RouteUtil
+}
+
+func (subscribingRouteUtil) Subscribe(context.Context, metalbond.VNI) error {
+	return nil
+}
+
+func (subscribingRouteUtil) Unsubscribe(context.Context, metalbond.VNI) error {
+	return nil
+}
+
+var _ = Describe("Network VNI migration", func() {
+	It("should keep the state of all previous VNIs across restarts until they are not in use anymore", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		createInterface := func(id string, vni uint32, device, ip string) {
+			GinkgoHelper()
+			addr := netip.MustParseAddr(ip)
+			_, err := dpdkClient.CreateInterface(ctx, &dpdk.Interface{
+				InterfaceMeta: dpdk.InterfaceMeta{ID: id},
+				Spec:          dpdk.InterfaceSpec{VNI: vni, Device: device, IPv4: &addr},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.Network{}).
+			WithObjects(network).
+			Build()
+
+		// newReconciler returns a reconciler without any in-memory state, like after a restart.
+		newReconciler := func() *NetworkReconciler {
+			log := logr.Discard()
+			return &NetworkReconciler{
+				Client:        c,
+				DPDK:          dpdkClient,
+				Subscriptions: metalbond.NewSubscriptions(subscribingRouteUtil{}),
+				MetalnetCache: internal.NewMetalnetCache(&log),
+				DefaultRouterAddr: &metalbond.DefaultRouterAddress{
+					RouterAddress: netip.MustParseAddr("2001:db8::1"),
+					PublicVNI:     200,
+				},
+				NodeName: "node",
+			}
+		}
+		r := newReconciler()
+		reconcile := func() ctrl.Result {
+			GinkgoHelper()
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(network)})
+				Expect(err).NotTo(HaveOccurred())
+				if !res.Requeue {
+					Expect(c.Get(ctx, client.ObjectKeyFromObject(network), network)).To(Succeed())
+					return res
+				}
+			}
+		}
+		subscribedVNIs := func() []metalbond.VNI {
+			var vnis []metalbond.VNI
+			for vni := range r.Subscriptions.Holders() {
+				vnis = append(vnis, vni)
+			}
+			return vnis
+		}
+
+		By("programming the network")
+		createInterface("a", 100, "net_tap4", "10.0.0.1")
+		reconcile()
+		Expect(network.Status.Nodes).To(ConsistOf(metalnetv1alpha1.NetworkNodeStatus{NodeName: "node", VNI: 100}))
+		Expect(subscribedVNIs()).To(ConsistOf(metalbond.VNI(100)))
+
+		By("changing the VNI twice while the previous VNIs are in use")
+		network.Spec.ID = 101
+		Expect(c.Update(ctx, network)).To(Succeed())
+		createInterface("b", 101, "net_tap5", "10.0.0.2")
+		Expect(reconcile().RequeueAfter).To(Equal(vniMigrationRequeueInterval))
+
+		network.Spec.ID = 102
+		Expect(c.Update(ctx, network)).To(Succeed())
+		createInterface("c", 102, "net_tap6", "10.0.0.3")
+		Expect(reconcile().RequeueAfter).To(Equal(vniMigrationRequeueInterval))
+		Expect(network.Status.Nodes).To(ConsistOf(metalnetv1alpha1.NetworkNodeStatus{
+			NodeName:     "node",
+			VNI:          102,
+			PreviousVNIs: []int32{100, 101},
+		}))
+		Expect(subscribedVNIs()).To(ConsistOf(metalbond.VNI(100), metalbond.VNI(101), metalbond.VNI(102)))
+
+		By("restarting")
+		r = newReconciler()
+		Expect(reconcile().RequeueAfter).To(Equal(vniMigrationRequeueInterval))
+		Expect(network.Status.Nodes).To(ConsistOf(HaveField("PreviousVNIs", []int32{100, 101})))
+		Expect(subscribedVNIs()).To(ConsistOf(metalbond.VNI(100), metalbond.VNI(101), metalbond.VNI(102)))
+
+		By("moving the last objects off the previous VNIs")
+		for _, id := range []string{"a", "b"} {
+			_, err := dpdkClient.DeleteInterface(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(reconcile().RequeueAfter).To(BeZero())
+		Expect(network.Status.Nodes).To(ConsistOf(metalnetv1alpha1.NetworkNodeStatus{NodeName: "node", VNI: 102}))
+		Expect(subscribedVNIs()).To(ConsistOf(metalbond.VNI(102)))
+	})
+})
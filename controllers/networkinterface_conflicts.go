@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// removeConflictingDPDKInterfaces deletes the dpservice interfaces occupying the device or the primary
+// IPs of the desired interface under another ID.
+//
+// Interfaces are keyed by the UID of their NetworkInterface, so an interface left behind by a deleted
+// NetworkInterface, e.g. because its finalizer was removed by hand, is not found by the recreated
+// NetworkInterface of the same name and blocks its device and IPs. Interfaces of NetworkInterfaces
+// that still exist are never deleted; an error is returned instead.
+func (r *NetworkInterfaceReconciler) removeConflictingDPDKInterfaces(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, desired *dpdk.Interface) error {
+	log.V(1).Info("Listing dpdk interfaces")
+	ifaceList, err := r.DPDK.ListInterfaces(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing dpdk interfaces: %w", err)
+	}
+
+	conflicting := findConflictingDPDKInterfaces(ifaceList.Items, desired)
+	if len(conflicting) == 0 {
+		return nil
+	}
+
+	log.V(1).Info("Listing network interfaces")
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList); err != nil {
+		return fmt.Errorf("error listing network interfaces: %w", err)
+	}
+	owners := make(map[types.UID]client.ObjectKey, len(nicList.Items))
+	for i := range nicList.Items {
+		owners[nicList.Items[i].UID] = client.ObjectKeyFromObject(&nicList.Items[i])
+	}
+
+	for i := range conflicting {
+		iface := &conflicting[i]
+		if owner, ok := owners[types.UID(iface.ID)]; ok {
+			return fmt.Errorf("dpdk interface %s of network interface %s uses the same device or ips", iface.ID, owner)
+		}
+
+		log.V(1).Info("Removing conflicting stale dpdk interface",
+			"ID", iface.ID,
+			"VNI", iface.Spec.VNI,
+			"Device", iface.Spec.Device,
+			"IPs", getDPDKInterfaceIPs(iface),
+		)
+		r.Eventf(nic, corev1.EventTypeWarning, "ConflictingInterfaceRemoved",
+			"Removed stale dpservice interface %s using the same device or ips", iface.ID)
+
+		if iface.Spec.UnderlayRoute != nil {
+			if err := r.removeInterfaceRoutesIfExist(ctx, log, iface.Spec.VNI, getDPDKInterfaceIPs(iface), *iface.Spec.UnderlayRoute); err != nil {
+				return err
+			}
+		}
+		if err := r.deleteDPDKInterfaceIfExists(ctx, types.UID(iface.ID)); err != nil {
+			return err
+		}
+		if err := r.releaseNetFnIfClaimExists(types.UID(iface.ID)); err != nil {
+			return err
+		}
+		log.V(1).Info("Removed conflicting stale dpdk interface", "ID", iface.ID)
+	}
+	return nil
+}
+
+// findConflictingDPDKInterfaces returns the interfaces with another ID than the desired interface that are
+// attached to its device or use one of its primary IPs in its VNI.
+func findConflictingDPDKInterfaces(ifaces []dpdk.Interface, desired *dpdk.Interface) []dpdk.Interface {
+	desiredIPs := make(map[netip.Addr]struct{})
+	for _, ip := range getDPDKInterfaceIPs(desired) {
+		desiredIPs[ip] = struct{}{}
+	}
+
+	var res []dpdk.Interface
+	for i := range ifaces {
+		iface := &ifaces[i]
+		if iface.ID == desired.ID {
+			continue
+		}
+		if iface.Spec.Device != "" && iface.Spec.Device == desired.Spec.Device {
+			res = append(res, *iface)
+			continue
+		}
+		if iface.Spec.VNI != desired.Spec.VNI {
+			continue
+		}
+		for _, ip := range getDPDKInterfaceIPs(iface) {
+			if _, ok := desiredIPs[ip]; ok {
+				res = append(res, *iface)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// prefixConflictRequeueInterval is the interval a NetworkInterface whose ips or prefixes conflict with
+// another NetworkInterface is rechecked at.
+const prefixConflictRequeueInterval = 30 * time.Second
+
+// findPrefixConflict returns a description of the conflict of the ips and prefixes of the given NetworkInterface
+// with an older NetworkInterface in the same Network on the same node, or an empty string if there is none.
+// Of two conflicting NetworkInterfaces, only the younger one is reported, so the older one keeps being programmed.
+func (r *NetworkInterfaceReconciler) findPrefixConflict(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) (string, error) {
+	networkKey := networkInterfaceNetworkKey(nic)
+	opts := []client.ListOption{
+		client.InNamespace(nic.Namespace),
+		client.MatchingFields{metalnetclient.NetworkInterfaceNetworkRefNameField: networkKey.Name},
+	}
+	if networkKey.Namespace == "" {
+		// The NetworkInterfaces of a ClusterNetwork may be in any namespace.
+		opts = []client.ListOption{client.MatchingFields{metalnetclient.NetworkInterfaceClusterNetworkRefNameField: networkKey.Name}}
+	}
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList, opts...); err != nil {
+		return "", fmt.Errorf("error listing network interfaces of network %s: %w", networkKey.Name, err)
+	}
+
+	addrs := networkInterfaceAddresses(nic)
+	for i := range nicList.Items {
+		other := &nicList.Items[i]
+		if other.UID == nic.UID || !other.DeletionTimestamp.IsZero() ||
+			other.Spec.NodeName == nil || *other.Spec.NodeName != r.NodeName ||
+			!isOlderNetworkInterface(other, nic) {
+			continue
+		}
+		if err := internal.FindAddressConflict(addrs, networkInterfaceAddresses(other)); err != nil {
+			name := other.Name
+			if other.Namespace != nic.Namespace {
+				name = client.ObjectKeyFromObject(other).String()
+			}
+			return fmt.Sprintf("Conflicts with network interface %s: %v", name, err), nil
+		}
+	}
+	return "", nil
+}
+
+func networkInterfaceAddresses(nic *metalnetv1alpha1.NetworkInterface) internal.InterfaceAddresses {
+	var addrs internal.InterfaceAddresses
+	for _, ip := range nic.Spec.IPs {
+		addrs.IPs = append(addrs.IPs, ip.Addr)
+	}
+	for _, prefix := range nic.Spec.Prefixes {
+		addrs.Prefixes = append(addrs.Prefixes, prefix.Prefix)
+	}
+	return addrs
+}
+
+// isOlderNetworkInterface reports whether a was created before b. NetworkInterfaces created in the same second
+// are ordered by name.
+func isOlderNetworkInterface(a, b *metalnetv1alpha1.NetworkInterface) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
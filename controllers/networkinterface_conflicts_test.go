@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("NetworkInterface conflicts", Label("network-interface"), func() {
+	newIface := func(id string, vni uint32, device, ip string) dpdk.Interface {
+		ipv4 := netip.MustParseAddr(ip)
+		return dpdk.Interface{
+			InterfaceMeta: dpdk.InterfaceMeta{ID: id},
+			Spec:          dpdk.InterfaceSpec{VNI: vni, Device: device, IPv4: &ipv4},
+		}
+	}
+
+	It("should find the interfaces using the same device or ip under another id", func() {
+		desired := newIface("new", 100, "dev-1", "10.0.0.1")
+		sameID := newIface("new", 100, "dev-1", "10.0.0.1")
+		sameDevice := newIface("old-device", 200, "dev-1", "10.0.0.9")
+		sameIP := newIface("old-ip", 100, "dev-2", "10.0.0.1")
+		sameIPOtherVNI := newIface("other-vni", 200, "dev-3", "10.0.0.1")
+		unrelated := newIface("unrelated", 100, "dev-4", "10.0.0.2")
+
+		Expect(findConflictingDPDKInterfaces(
+			[]dpdk.Interface{sameID, sameDevice, sameIP, sameIPOtherVNI, unrelated},
+			&desired,
+		)).To(ConsistOf(sameDevice, sameIP))
+	})
+
+	It("should report prefix conflicts only for the younger network interface", func() {
+		now := time.Now()
+		newNIC := func(name string, created time.Time, ip string, prefixes ...string) *metalnetv1alpha1.NetworkInterface {
+			nic := &metalnetv1alpha1.NetworkInterface{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "default",
+					Name:              name,
+					UID:               types.UID(name),
+					CreationTimestamp: metav1.NewTime(created),
+				},
+				Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+					NetworkRef: corev1.LocalObjectReference{Name: "net"},
+					IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP(ip)},
+					NodeName:   ptr.To("node"),
+				},
+			}
+			for _, prefix := range prefixes {
+				nic.Spec.Prefixes = append(nic.Spec.Prefixes, metalnetv1alpha1.MustParseIPPrefix(prefix))
+			}
+			return nic
+		}
+		older := newNIC("older", now.Add(-time.Hour), "10.0.0.1", "10.0.1.0/24")
+		younger := newNIC("younger", now, "10.0.1.1")
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(older, younger).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+		r := &NetworkInterfaceReconciler{Client: c, NodeName: "node"}
+
+		Expect(r.findPrefixConflict(context.TODO(), older)).To(BeEmpty())
+		Expect(r.findPrefixConflict(context.TODO(), younger)).To(Equal(
+			"Conflicts with network interface older: ip 10.0.1.1 lies in prefix 10.0.1.0/24"))
+	})
+})
@@ -8,9 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
 	"sort"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/ironcore-dev/controller-utils/clientutils"
@@ -21,28 +21,35 @@ import (
 	"github.com/ironcore-dev/metalbond/pb"
 	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
 	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/eventbus"
+	"github.com/ironcore-dev/metalnet/ipam"
 	"github.com/ironcore-dev/metalnet/metalbond"
 	"github.com/ironcore-dev/metalnet/netfns"
-	"github.com/ironcore-dev/metalnet/sysfs"
-	"github.com/jaypipes/ghw"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
-	networkInterfaceFinalizer = "networking.metalnet.ironcore.dev/networkInterface"
-	defaultFirewallRulePrio   = 100
-	defaultFirewallRulePrefix = "0.0.0.0/0"
+	networkInterfaceFinalizer     = "networking.metalnet.ironcore.dev/networkInterface"
+	networkInterfaceTombstoneKind = "NetworkInterface"
+	defaultFirewallRulePrio       = 100
+	defaultFirewallRulePrefix     = "0.0.0.0/0"
 )
 
 func getIP(ipFamily corev1.IPFamily, ipFamilies []corev1.IPFamily, ips []metalnetv1alpha1.IP) netip.Addr {
@@ -73,6 +80,18 @@ func getNetworkInterfaceIPs(nic *metalnetv1alpha1.NetworkInterface) []netip.Addr
 	return res
 }
 
+// getDPDKInterfaceIPs returns the primary IPs of a dpservice interface, skipping
+// the unspecified addresses used to disable an ip family.
+func getDPDKInterfaceIPs(iface *dpdk.Interface) []netip.Addr {
+	var res []netip.Addr
+	for _, ip := range []*netip.Addr{iface.Spec.IPv4, iface.Spec.IPv6} {
+		if ip != nil && ip.IsValid() && !ip.IsUnspecified() {
+			res = append(res, *ip)
+		}
+	}
+	return res
+}
+
 // NetworkInterfaceReconciler reconciles a NetworkInterface object
 type NetworkInterfaceReconciler struct {
 	client.Client
@@ -82,24 +101,84 @@ type NetworkInterfaceReconciler struct {
 
 	DPDK      dpdkclient.Client
 	RouteUtil metalbond.RouteUtil
+	// AliasPrefixAnnouncer announces the alias prefixes of the NetworkInterfaces once per node.
+	AliasPrefixAnnouncer *metalbond.AliasPrefixAnnouncer
+
+	// DeviceAllocator hands out the devices the NetworkInterfaces are attached to.
+	DeviceAllocator netfns.DeviceAllocator
+	// DeviceClaims resolves the devices of NetworkInterfaces referencing a device claim. If nil, such
+	// NetworkInterfaces are not programmed.
+	DeviceClaims *netfns.PodResourcesResolver
+	// HostInterfaceDevice is the dpservice device of the host port host interfaces are programmed on. If empty,
+	// host interfaces are not programmed.
+	HostInterfaceDevice string
 
-	NetFnsManager *netfns.Manager
-	SysFS         sysfs.FS
-
-	PfToVfOffset                int
 	NodeName                    string
 	PublicVNI                   int
 	EnableIPv6Support           bool
 	BluefieldDetected           bool
 	BluefieldHostDefaultBusAddr string
+
+	// PublicVNIIPv6 is the VNI public IPv6 addresses are announced into. If zero, PublicVNI is used.
+	PublicVNIIPv6 int
+
+	// RateLimiter limits how often the updates of a NetworkInterface are applied. If nil, updates are not limited.
+	RateLimiter *ObjectRateLimiter
+	// InitialSync is informed about the NetworkInterfaces reconciled after startup and holds them back
+	// until the Networks are reconciled.
+	InitialSync *InitialSync
+	// MaxConcurrentReconciles is the number of NetworkInterfaces reconciled in parallel. Zero reconciles
+	// one at a time.
+	MaxConcurrentReconciles int
+	// Resync periodically reconciles all NetworkInterfaces. If nil, NetworkInterfaces are only reconciled on
+	// watch events.
+	Resync *Resync
+
+	// VirtualIPHandoverTimeout is the maximum time a virtual ip removed from a NetworkInterface
+	// is kept announced while waiting for the NetworkInterface taking it over. Zero waits forever.
+	VirtualIPHandoverTimeout time.Duration
+
+	// EndpointSliceTargets provides the load balancer targets discovered from the EndpointSlices of the
+	// workload cluster. If nil, only the targets of the NetworkInterface spec are programmed.
+	EndpointSliceTargets *EndpointSliceTargetReconciler
+
+	// CapacityFeedback is informed about the network interfaces blocked by full dpservice tables. If nil,
+	// the node is not marked.
+	CapacityFeedback *CapacityFeedback
+
+	// EventBus publishes the programming of interfaces, virtual ips and load balancer targets. If nil,
+	// nothing is published.
+	EventBus *eventbus.Bus
+
+	// Convergence measures the time from the changes of a NetworkInterface to its routes being announced and
+	// its dataplane being programmed. If nil, it is not measured.
+	Convergence *ConvergenceTracker
+
+	// Tombstones records the deleted NetworkInterfaces until their dataplane state is removed, see
+	// TombstoneKind. If nil, nothing is recorded.
+	Tombstones *Tombstones
+
+	// IPAM allocates the ips of the NetworkInterfaces created without ips. If nil, ips are not allocated.
+	IPAM ipam.IPAM
+}
+
+func newNetworkInterfaceEvent(eventType eventbus.EventType, nic *metalnetv1alpha1.NetworkInterface) eventbus.Event {
+	return eventbus.Event{
+		Type:      eventType,
+		Namespace: nic.Namespace,
+		Name:      nic.Name,
+		UID:       nic.UID,
+	}
 }
 
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=clusternetworks,verbs=get;list;watch
 //+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=loadbalancers,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -109,15 +188,42 @@ func (r *NetworkInterfaceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	nic := &metalnetv1alpha1.NetworkInterface{}
 
 	if err := r.Get(ctx, req.NamespacedName, nic); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.RateLimiter.Forget(req.NamespacedName)
+			r.Convergence.Forget(req.NamespacedName)
+			r.InitialSync.Reconciled(nic, req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	if nodeName := nic.Spec.NodeName; nodeName == nil || *nodeName != r.NodeName {
 		log.V(1).Info("Network interface is not assigned to this node", "NodeName", nic.Spec.NodeName)
+		r.InitialSync.Reconciled(nic, req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
-	return r.reconcileExists(ctx, log, nic)
+	if !r.InitialSync.tierReady(startupTierNetworkInterfaces) {
+		log.V(1).Info("Networks are not reconciled since startup yet, requeueing")
+		return ctrl.Result{RequeueAfter: initialSyncRequeueInterval}, nil
+	}
+
+	if paused, err := updatePausedCondition(ctx, r.Client, nic, &nic.Status.Conditions); err != nil || paused {
+		if paused {
+			log.V(1).Info("Reconciliation is paused")
+			r.InitialSync.Reconciled(nic, req.NamespacedName)
+		}
+		return ctrl.Result{}, err
+	}
+
+	res, err := r.reconcileExists(ctx, log, nic)
+	if err := recordReconcileResult(ctx, r.Client, nic, &nic.Status.ReconcileTimeline, err); err != nil {
+		log.Error(err, "Error recording reconcile result")
+	}
+	res, err = requeueOnError(log, res, err)
+	if (err == nil && !res.Requeue) || isValidationError(err) {
+		r.InitialSync.Reconciled(nic, req.NamespacedName)
+	}
+	return res, err
 }
 
 func (r *NetworkInterfaceReconciler) reconcileExists(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface) (ctrl.Result, error) {
@@ -132,6 +238,14 @@ func NetIPAddrPrefix(addr netip.Addr) netip.Prefix {
 	return netip.PrefixFrom(addr, addr.BitLen())
 }
 
+// publicVNIFor returns the public VNI the given address is announced into.
+func publicVNIFor(addr netip.Addr, publicVNI, publicVNIIPv6 int) metalbond.VNI {
+	if addr.Is6() && publicVNIIPv6 != 0 {
+		return metalbond.VNI(publicVNIIPv6)
+	}
+	return metalbond.VNI(publicVNI)
+}
+
 func (r *NetworkInterfaceReconciler) isValidIPConfiguration(ips []metalnetv1alpha1.IP, ipFamilies []corev1.IPFamily) (bool, error) {
 	var ipv4Count, ipv6Count int
 
@@ -177,7 +291,7 @@ func (r *NetworkInterfaceReconciler) isValidInterfaceSpec(spec *metalnetv1alpha1
 }
 
 func (r *NetworkInterfaceReconciler) releaseNetFnIfClaimExists(uid types.UID) error {
-	if err := r.NetFnsManager.Release(uid); err != nil && !errors.Is(err, netfns.ErrClaimNotFound) {
+	if err := r.DeviceAllocator.Release(uid); err != nil && !errors.Is(err, netfns.ErrClaimNotFound) {
 		return fmt.Errorf("error releasing claim: %w", err)
 	}
 	return nil
@@ -195,7 +309,7 @@ func (r *NetworkInterfaceReconciler) deleteDPDKVirtualIPIfExists(ctx context.Con
 }
 
 func (r *NetworkInterfaceReconciler) removeVirtualIPRouteIfExists(ctx context.Context, virtualIP netip.Addr, underlayRoute netip.Addr) error {
-	if err := r.RouteUtil.WithdrawRoute(ctx, metalbond.VNI(r.PublicVNI), metalbond.Destination{
+	if err := r.RouteUtil.WithdrawRoute(ctx, publicVNIFor(virtualIP, r.PublicVNI, r.PublicVNIIPv6), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(virtualIP),
 	}, metalbond.NextHop{
 		TargetAddress: underlayRoute,
@@ -208,7 +322,7 @@ func (r *NetworkInterfaceReconciler) removeVirtualIPRouteIfExists(ctx context.Co
 }
 
 func (r *NetworkInterfaceReconciler) addVirtualIPRouteIfNotExists(ctx context.Context, virtualIP netip.Addr, underlayRoute netip.Addr) error {
-	if err := r.RouteUtil.AnnounceRoute(ctx, metalbond.VNI(r.PublicVNI), metalbond.Destination{
+	if err := r.RouteUtil.AnnounceRoute(ctx, publicVNIFor(virtualIP, r.PublicVNI, r.PublicVNIIPv6), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(virtualIP),
 	}, metalbond.NextHop{
 		TargetAddress: underlayRoute,
@@ -220,6 +334,25 @@ func (r *NetworkInterfaceReconciler) addVirtualIPRouteIfNotExists(ctx context.Co
 	return nil
 }
 
+// applyVirtualIPRoute announces the virtual ip into the public VNI unless its scope is private,
+// in which case a previously announced route is withdrawn.
+func (r *NetworkInterfaceReconciler) applyVirtualIPRoute(ctx context.Context, virtualIP, underlayRoute netip.Addr, scope metalnetv1alpha1.AnnouncementScope) error {
+	if scope == metalnetv1alpha1.AnnouncementScopePrivate {
+		return r.removeVirtualIPRouteIfExists(ctx, virtualIP, underlayRoute)
+	}
+	return r.addVirtualIPRouteIfNotExists(ctx, virtualIP, underlayRoute)
+}
+
+func virtualIPAnnouncementScope(nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) metalnetv1alpha1.AnnouncementScope {
+	if nic.Spec.VirtualIPAnnouncementScope != nil {
+		return *nic.Spec.VirtualIPAnnouncementScope
+	}
+	if network.Spec.VirtualIPAnnouncementScope != "" {
+		return network.Spec.VirtualIPAnnouncementScope
+	}
+	return metalnetv1alpha1.AnnouncementScopePublic
+}
+
 func (r *NetworkInterfaceReconciler) addInterfaceRouteIfNotExists(ctx context.Context, vni uint32, ip, underlayRoute netip.Addr) error {
 	if err := r.RouteUtil.AnnounceRoute(ctx, metalbond.VNI(vni), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(ip),
@@ -276,27 +409,15 @@ func (r *NetworkInterfaceReconciler) removeInterfaceRoutesIfExist(ctx context.Co
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) addPrefixRouteIfNotExists(ctx context.Context, vni uint32, prefix netip.Prefix, underlayRoute netip.Addr) error {
-	if err := r.RouteUtil.AnnounceRoute(ctx, metalbond.VNI(vni), metalbond.Destination{
-		Prefix: prefix,
-	}, metalbond.NextHop{
-		TargetVNI:     0,
-		TargetAddress: underlayRoute,
-		TargetHopType: pb.NextHopType_STANDARD,
-	}); metalbond.IgnoreNextHopAlreadyExistsError(err) != nil {
+func (r *NetworkInterfaceReconciler) addPrefixRouteIfNotExists(ctx context.Context, vni uint32, nicUID types.UID, prefix netip.Prefix, underlayRoute netip.Addr) error {
+	if err := r.AliasPrefixAnnouncer.Add(ctx, metalbond.VNI(vni), prefix, string(nicUID), underlayRoute); err != nil {
 		return fmt.Errorf("error adding prefix route: %w", err)
 	}
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) removePrefixRouteIfExists(ctx context.Context, vni uint32, prefix netip.Prefix, underlayRoute netip.Addr) error {
-	if err := r.RouteUtil.WithdrawRoute(ctx, metalbond.VNI(vni), metalbond.Destination{
-		Prefix: prefix,
-	}, metalbond.NextHop{
-		TargetVNI:     0,
-		TargetAddress: underlayRoute,
-		TargetHopType: pb.NextHopType_STANDARD,
-	}); metalbond.IgnoreNextHopNotFoundError(err) != nil {
+func (r *NetworkInterfaceReconciler) removePrefixRouteIfExists(ctx context.Context, vni uint32, nicUID types.UID, prefix netip.Prefix) error {
+	if err := r.AliasPrefixAnnouncer.Remove(ctx, metalbond.VNI(vni), prefix, string(nicUID)); err != nil {
 		return fmt.Errorf("error removing prefix route: %w", err)
 	}
 	return nil
@@ -328,6 +449,15 @@ func (r *NetworkInterfaceReconciler) removeLBTargetRouteIfExists(ctx context.Con
 	return nil
 }
 
+// ensureLBTargetRoute announces the lb target route unless the NetworkInterface is draining. A draining
+// NetworkInterface withdraws its lb target routes, so the load balancers stop handing it new connections.
+func (r *NetworkInterfaceReconciler) ensureLBTargetRoute(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, vni uint32, prefix netip.Prefix, underlayRoute netip.Addr) error {
+	if policy := nic.Spec.LoadBalancerTargetPolicy; policy != nil && policy.Draining {
+		return r.removeLBTargetRouteIfExists(ctx, vni, prefix, underlayRoute)
+	}
+	return r.addLBTargetRouteIfNotExists(ctx, vni, prefix, underlayRoute)
+}
+
 func (r *NetworkInterfaceReconciler) fillTCPUDPFilter(ctx context.Context, specFirewallRule *metalnetv1alpha1.FirewallRule, protocolFilter *dpdkproto.ProtocolFilter) error {
 	var SrcPortLower, DstPortLower, SrcPortUpper, DstPortUpper int32
 	if specFirewallRule.ProtocolMatch.PortRange != nil {
@@ -370,7 +500,7 @@ func (r *NetworkInterfaceReconciler) fillTCPUDPFilter(ctx context.Context, specF
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) createDPDKFwRule(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, specFirewallRule *metalnetv1alpha1.FirewallRule) error {
+func (r *NetworkInterfaceReconciler) newDPDKFwRule(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, specFirewallRule *metalnetv1alpha1.FirewallRule) (*dpdk.FirewallRule, error) {
 	var (
 		protocolFilter dpdkproto.ProtocolFilter
 		priority       uint32 = defaultFirewallRulePrio
@@ -401,7 +531,7 @@ func (r *NetworkInterfaceReconciler) createDPDKFwRule(ctx context.Context, nic *
 			IcmpCode: icmpCode}}
 	case metalnetv1alpha1.FirewallRuleProtocolTypeUDP, metalnetv1alpha1.FirewallRuleProtocolTypeTCP:
 		if err := r.fillTCPUDPFilter(ctx, specFirewallRule, &protocolFilter); err != nil {
-			return fmt.Errorf("error filling TCP/UDP filter: %w", err)
+			return nil, fmt.Errorf("error filling TCP/UDP filter: %w", err)
 		}
 	default:
 		protocolFilter.Filter = nil
@@ -423,7 +553,7 @@ func (r *NetworkInterfaceReconciler) createDPDKFwRule(ctx context.Context, nic *
 		destPrefix.Prefix = specFirewallRule.DestinationPrefix.Prefix
 	}
 
-	fwrule, err := r.DPDK.CreateFirewallRule(ctx, &dpdk.FirewallRule{
+	return &dpdk.FirewallRule{
 		TypeMeta: dpdk.TypeMeta{Kind: dpdk.FirewallRuleKind},
 		FirewallRuleMeta: dpdk.FirewallRuleMeta{
 			InterfaceID: string(nic.UID),
@@ -438,7 +568,11 @@ func (r *NetworkInterfaceReconciler) createDPDKFwRule(ctx context.Context, nic *
 			ProtocolFilter: &dpdkproto.ProtocolFilter{
 				Filter: protocolFilter.Filter},
 		},
-	})
+	}, nil
+}
+
+func (r *NetworkInterfaceReconciler) createDPDKFwRule(ctx context.Context, dpdkFirewallRule *dpdk.FirewallRule) error {
+	fwrule, err := r.DPDK.CreateFirewallRule(ctx, dpdkFirewallRule)
 	if err != nil && fwrule.Status.Code == 0 {
 		return fmt.Errorf("error adding firewall rule: %w", err)
 	}
@@ -492,19 +626,56 @@ func (r *NetworkInterfaceReconciler) deleteDPDKInterfaceIfExists(ctx context.Con
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) reconcileNATIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, vni uint32) error {
-	if nic.Spec.NAT != nil && nic.Spec.NAT.IP != nil {
-		natIP := nic.Spec.NAT.IP.Addr
+// getNATDetails returns the NAT the interface egresses through. An explicit NAT takes precedence over
+// the port block allocated by the referenced InternetGateway, which is only used without a virtual ip.
+func (r *NetworkInterfaceReconciler) getNATDetails(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) (*metalnetv1alpha1.NATDetails, error) {
+	if nic.Spec.NAT != nil {
+		return nic.Spec.NAT, nil
+	}
+	if nic.Spec.InternetGatewayRef == nil || nic.Spec.VirtualIP != nil {
+		return nil, nil
+	}
+
+	internetGateway := &metalnetv1alpha1.InternetGateway{}
+	internetGatewayKey := client.ObjectKey{Namespace: nic.Namespace, Name: nic.Spec.InternetGatewayRef.Name}
+	if err := r.Get(ctx, internetGatewayKey, internetGateway); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting internet gateway %s: %w", internetGatewayKey.Name, err)
+		}
+		return nil, nil
+	}
+
+	for _, allocation := range internetGateway.Status.Allocations {
+		if allocation.NetworkInterfaceName == nic.Name {
+			ip := allocation.IP
+			return &metalnetv1alpha1.NATDetails{
+				IP:      &ip,
+				Port:    allocation.Port,
+				EndPort: allocation.EndPort,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *NetworkInterfaceReconciler) reconcileNATIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, vni uint32) (*metalnetv1alpha1.NATDetails, error) {
+	nat, err := r.getNATDetails(ctx, nic)
+	if err != nil {
+		return nil, err
+	}
+
+	if nat != nil && nat.IP != nil {
+		natIP := nat.IP.Addr
 		log = log.WithValues("NatIP", natIP)
 		log.V(1).Info("Apply nat ip")
-		return r.applyNATIP(ctx, log, nic, natIP, vni)
+		return nat, r.applyNATIP(ctx, log, nic, nat, vni)
 	}
 
 	log.V(1).Info("Delete nat ip")
-	return r.deleteNATIP(ctx, log, nic, vni)
+	return nil, r.deleteNATIP(ctx, log, nic, vni)
 }
 
-func (r *NetworkInterfaceReconciler) applyNATIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, natIP netip.Addr, vni uint32) error {
+func (r *NetworkInterfaceReconciler) applyNATIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, nat *metalnetv1alpha1.NATDetails, vni uint32) error {
 	log.V(1).Info("Getting dpdk nat ip")
 	dpdkNAT, err := r.DPDK.GetNat(ctx, string(nic.UID))
 	if err != nil {
@@ -513,12 +684,12 @@ func (r *NetworkInterfaceReconciler) applyNATIP(ctx context.Context, log logr.Lo
 		}
 
 		log.V(1).Info("DPDK nat ip does not exist, creating it")
-		return r.createNATIP(ctx, log, nic, natIP, vni)
+		return r.createNATIP(ctx, log, nic, nat, vni)
 	}
 
 	underlayRoute := dpdkNAT.Spec.UnderlayRoute
 	existingNATIP := *dpdkNAT.Spec.NatIP
-	if existingNATIP == natIP && dpdkNAT.Spec.MinPort == uint32(nic.Spec.NAT.Port) && dpdkNAT.Spec.MaxPort == uint32(nic.Spec.NAT.EndPort) {
+	if existingNATIP == nat.IP.Addr && dpdkNAT.Spec.MinPort == uint32(nat.Port) && dpdkNAT.Spec.MaxPort == uint32(nat.EndPort) {
 		log.V(1).Info("DPDK nat ip is up-to-date, adding metalbond route if not exists")
 		if err := r.addNATIPRouteIfNotExists(ctx, dpdkNAT, *underlayRoute, vni); err != nil {
 			return err
@@ -536,20 +707,21 @@ func (r *NetworkInterfaceReconciler) applyNATIP(ctx context.Context, log logr.Lo
 	log.V(1).Info("Deleted existing nat ip")
 
 	log.V(1).Info("Creating nat ip")
-	if err := r.createNATIP(ctx, log, nic, natIP, vni); err != nil {
+	if err := r.createNATIP(ctx, log, nic, nat, vni); err != nil {
 		return err
 	}
 	log.V(1).Info("Created nat ip")
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) createNATIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, natIP netip.Addr, vni uint32) error {
+func (r *NetworkInterfaceReconciler) createNATIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, nat *metalnetv1alpha1.NATDetails, vni uint32) error {
+	natIP := nat.IP.Addr
 	natLocal, err := r.DPDK.CreateNat(ctx, &dpdk.Nat{
 		NatMeta: dpdk.NatMeta{InterfaceID: string(nic.UID)},
 		Spec: dpdk.NatSpec{
 			NatIP:   &natIP,
-			MinPort: uint32(nic.Spec.NAT.Port),
-			MaxPort: uint32(nic.Spec.NAT.EndPort),
+			MinPort: uint32(nat.Port),
+			MaxPort: uint32(nat.EndPort),
 		},
 	})
 	if err != nil {
@@ -608,66 +780,213 @@ func (r *NetworkInterfaceReconciler) deleteDPDKNATIPIfExists(ctx context.Context
 }
 
 func (r *NetworkInterfaceReconciler) removeNATIPRouteIfExists(ctx context.Context, natLocal *dpdk.Nat, underlayRoute netip.Addr, vni uint32) error {
-	if err := r.RouteUtil.WithdrawRoute(ctx, metalbond.VNI(r.PublicVNI), metalbond.Destination{
+	if err := r.RouteUtil.WithdrawRoute(ctx, publicVNIFor(*natLocal.Spec.NatIP, r.PublicVNI, r.PublicVNIIPv6), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(*natLocal.Spec.NatIP),
-	}, metalbond.NextHop{
-		TargetAddress: underlayRoute,
-		TargetVNI:     0,
-		TargetHopType: pb.NextHopType_STANDARD,
-	}); metalbond.IgnoreNextHopNotFoundError(err) != nil {
+	}, natNextHop(natLocal, underlayRoute)); metalbond.IgnoreNextHopNotFoundError(err) != nil {
 		return fmt.Errorf("error removing metalbond route: %w", err)
 	}
+	return r.removeNATIPVNIRouteIfExists(ctx, natLocal, underlayRoute, vni)
+}
+
+// removeNATIPVNIRouteIfExists withdraws the nat route of the nat ip from the given VNI only, keeping its
+// announcement in the public VNI.
+func (r *NetworkInterfaceReconciler) removeNATIPVNIRouteIfExists(ctx context.Context, natLocal *dpdk.Nat, underlayRoute netip.Addr, vni uint32) error {
 	if err := r.RouteUtil.WithdrawRoute(ctx, metalbond.VNI(vni), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(*natLocal.Spec.NatIP),
-	}, metalbond.NextHop{
-		TargetAddress:    underlayRoute,
-		TargetVNI:        0,
-		TargetHopType:    pb.NextHopType_NAT,
-		TargetNATMinPort: uint16(natLocal.Spec.MinPort),
-		TargetNATMaxPort: uint16(natLocal.Spec.MaxPort),
-	}); metalbond.IgnoreNextHopNotFoundError(err) != nil {
+	}, natNextHop(natLocal, underlayRoute)); metalbond.IgnoreNextHopNotFoundError(err) != nil {
 		return fmt.Errorf("error removing metalbond route: %w", err)
 	}
 	return nil
 }
 
 func (r *NetworkInterfaceReconciler) addNATIPRouteIfNotExists(ctx context.Context, natLocal *dpdk.Nat, underlayRoute netip.Addr, vni uint32) error {
-	if err := r.RouteUtil.AnnounceRoute(ctx, metalbond.VNI(r.PublicVNI), metalbond.Destination{
+	if err := r.RouteUtil.AnnounceRoute(ctx, publicVNIFor(*natLocal.Spec.NatIP, r.PublicVNI, r.PublicVNIIPv6), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(*natLocal.Spec.NatIP),
-	}, metalbond.NextHop{
-		TargetAddress: underlayRoute,
-		TargetVNI:     0,
-		TargetHopType: pb.NextHopType_STANDARD,
-	}); metalbond.IgnoreNextHopAlreadyExistsError(err) != nil {
+	}, natNextHop(natLocal, underlayRoute)); metalbond.IgnoreNextHopAlreadyExistsError(err) != nil {
 		return fmt.Errorf("error adding metalbond route: %w", err)
 	}
 	if err := r.RouteUtil.AnnounceRoute(ctx, metalbond.VNI(vni), metalbond.Destination{
 		Prefix: NetIPAddrPrefix(*natLocal.Spec.NatIP),
-	}, metalbond.NextHop{
+	}, natNextHop(natLocal, underlayRoute)); metalbond.IgnoreNextHopAlreadyExistsError(err) != nil {
+		return fmt.Errorf("error adding metalbond route: %w", err)
+	}
+	return nil
+}
+
+// natNextHop returns the next hop of the return traffic of the given nat. The nat ip is shared by the
+// NetworkInterfaces of several nodes, so the next hop carries the port block of the nat to steer return traffic
+// to the node owning the destination port, both in the VNI and in the public VNI.
+func natNextHop(natLocal *dpdk.Nat, underlayRoute netip.Addr) metalbond.NextHop {
+	return metalbond.NextHop{
 		TargetAddress:    underlayRoute,
 		TargetVNI:        0,
 		TargetHopType:    pb.NextHopType_NAT,
 		TargetNATMinPort: uint16(natLocal.Spec.MinPort),
 		TargetNATMaxPort: uint16(natLocal.Spec.MaxPort),
-	}); metalbond.IgnoreNextHopAlreadyExistsError(err) != nil {
-		return fmt.Errorf("error adding metalbond route: %w", err)
+	}
+}
+
+// natPortBlocks returns the port blocks of the nat announced for the return traffic of a NetworkInterface in the
+// given VNI.
+func (r *NetworkInterfaceReconciler) natPortBlocks(nat *metalnetv1alpha1.NATDetails, vni uint32) []metalnetv1alpha1.NATPortBlock {
+	if nat == nil || nat.IP == nil {
+		return nil
+	}
+	return []metalnetv1alpha1.NATPortBlock{
+		{VNI: int32(publicVNIFor(nat.IP.Addr, r.PublicVNI, r.PublicVNIIPv6)), IP: *nat.IP, Port: nat.Port, EndPort: nat.EndPort},
+		{VNI: int32(vni), IP: *nat.IP, Port: nat.Port, EndPort: nat.EndPort},
+	}
+}
+
+// errVirtualIPHandoverPending is returned when a virtual ip has to stay announced because
+// the NetworkInterface taking it over has not announced it yet.
+var errVirtualIPHandoverPending = errors.New("virtual ip handover pending")
+
+const virtualIPHandoverRequeueInterval = 2 * time.Second
+
+// deviceNotReadyRequeueInterval is the interval the creation of an interface is retried at
+// while its device is not ready.
+const deviceNotReadyRequeueInterval = 5 * time.Second
+
+func isVirtualIPAnnounced(nic *metalnetv1alpha1.NetworkInterface, virtualIP netip.Addr) bool {
+	if nic.Status.VirtualIP == nil || nic.Status.VirtualIP.Addr != virtualIP {
+		return false
+	}
+	return meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)
+}
+
+func setVirtualIPHandoverPendingCondition(nic *metalnetv1alpha1.NetworkInterface) {
+	// Restart the transition time when entering handover so the timeout is measured from here.
+	if cond := meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady); cond != nil &&
+		cond.Reason != metalnetv1alpha1.VirtualIPReasonHandoverPending {
+		meta.RemoveStatusCondition(&nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)
+	}
+	meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+		Type:               metalnetv1alpha1.NetworkInterfaceVirtualIPReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: nic.Generation,
+		Reason:             metalnetv1alpha1.VirtualIPReasonHandoverPending,
+		Message:            "Virtual ip is kept announced until its new owner announces it",
+	})
+}
+
+func setCapacityExceededCondition(nic *metalnetv1alpha1.NetworkInterface, err error) {
+	if err == nil {
+		meta.RemoveStatusCondition(&nic.Status.Conditions, metalnetv1alpha1.CapacityExceeded)
+		return
+	}
+	meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+		Type:               metalnetv1alpha1.CapacityExceeded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: nic.Generation,
+		Reason:             metalnetv1alpha1.CapacityReasonTableFull,
+		Message:            err.Error(),
+	})
+}
+
+// routeDeniedError returns the first of the given errors caused by a route denied by the announcement policy.
+func routeDeniedError(errs []error) error {
+	for _, err := range errs {
+		if metalbond.IsRouteDeniedError(err) {
+			return err
+		}
 	}
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) reconcileVirtualIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface) error {
+// setAnnouncementDeniedCondition reports a route denied by the announcement policy in the given conditions,
+// or removes the condition if err is nil.
+func setAnnouncementDeniedCondition(conditions *[]metav1.Condition, generation int64, err error) {
+	if err == nil {
+		meta.RemoveStatusCondition(conditions, metalnetv1alpha1.AnnouncementDenied)
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               metalnetv1alpha1.AnnouncementDenied,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             metalnetv1alpha1.AnnouncementReasonDeniedByPolicy,
+		Message:            err.Error(),
+	})
+}
+
+// eventCapacityExceeded emits a warning once the network interface runs into a full dpservice table.
+func (r *NetworkInterfaceReconciler) eventCapacityExceeded(nic *metalnetv1alpha1.NetworkInterface, err error) {
+	if !meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.CapacityExceeded) {
+		r.Eventf(nic, corev1.EventTypeWarning, "CapacityExceeded", "Dpservice table is full: %v", err)
+	}
+}
+
+func setDetachPendingCondition(nic *metalnetv1alpha1.NetworkInterface) {
+	meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+		Type:               metalnetv1alpha1.NetworkInterfaceDetachPending,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: nic.Generation,
+		Reason:             metalnetv1alpha1.DetachPendingReasonAttached,
+		Message:            "Dataplane is kept until the network interface is detached from its machine",
+	})
+}
+
+// virtualIPHandoverPending reports whether the given virtual ip, which is about to be removed from the
+// network interface, is claimed by a network interface on another node that did not announce it yet.
+func (r *NetworkInterfaceReconciler) virtualIPHandoverPending(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, virtualIP netip.Addr) (bool, error) {
+	log.V(1).Info("Listing network interfaces to check for virtual ip handover")
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList); err != nil {
+		return false, fmt.Errorf("error listing network interfaces: %w", err)
+	}
+
+	var pending []client.ObjectKey
+	for i := range nicList.Items {
+		other := &nicList.Items[i]
+		if other.UID == nic.UID || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if other.Spec.VirtualIP == nil || other.Spec.VirtualIP.Addr != virtualIP {
+			continue
+		}
+		// dpservice cannot hold the same virtual ip twice, so a handover on the same node
+		// is always break-before-make.
+		if other.Spec.NodeName == nil || *other.Spec.NodeName == r.NodeName {
+			continue
+		}
+		if isVirtualIPAnnounced(other, virtualIP) {
+			log.V(1).Info("Virtual ip is announced by new owner", "NewOwner", client.ObjectKeyFromObject(other))
+			return false, nil
+		}
+		pending = append(pending, client.ObjectKeyFromObject(other))
+	}
+	if len(pending) == 0 {
+		return false, nil
+	}
+
+	if r.VirtualIPHandoverTimeout > 0 {
+		cond := meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)
+		if cond != nil && cond.Reason == metalnetv1alpha1.VirtualIPReasonHandoverPending &&
+			time.Since(cond.LastTransitionTime.Time) >= r.VirtualIPHandoverTimeout {
+			log.Info("Virtual ip handover timed out, withdrawing virtual ip", "NewOwners", pending)
+			r.Eventf(nic, corev1.EventTypeWarning, "VirtualIPHandoverTimeout", "Virtual ip %s was not announced by %v in time", virtualIP, pending)
+			return false, nil
+		}
+	}
+
+	log.V(1).Info("Waiting for new owner to announce virtual ip", "NewOwners", pending)
+	return true, nil
+}
+
+func (r *NetworkInterfaceReconciler) reconcileVirtualIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, scope metalnetv1alpha1.AnnouncementScope) error {
 	if nic.Spec.VirtualIP != nil {
 		virtualIP := nic.Spec.VirtualIP.Addr
-		log = log.WithValues("VirtualIP", virtualIP)
+		log = log.WithValues("VirtualIP", virtualIP, "AnnouncementScope", scope)
 		log.V(1).Info("Apply virtual ip")
-		return r.applyVirtualIP(ctx, log, nic, virtualIP)
+		return r.applyVirtualIP(ctx, log, nic, virtualIP, scope)
 	}
 
 	log.V(1).Info("Delete virtual ip")
 	return r.deleteVirtualIP(ctx, log, nic)
 }
 
-func (r *NetworkInterfaceReconciler) applyVirtualIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, virtualIP netip.Addr) error {
+func (r *NetworkInterfaceReconciler) applyVirtualIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, virtualIP netip.Addr, scope metalnetv1alpha1.AnnouncementScope) error {
 	log.V(1).Info("Getting dpdk virtual ip")
 	dpdkVIP, err := r.DPDK.GetVirtualIP(ctx, string(nic.UID))
 	if err != nil {
@@ -676,21 +995,29 @@ func (r *NetworkInterfaceReconciler) applyVirtualIP(ctx context.Context, log log
 		}
 
 		log.V(1).Info("DPDK virtual ip does not exist, creating it")
-		return r.createVirtualIP(ctx, log, nic, virtualIP)
+		return r.createVirtualIP(ctx, log, nic, virtualIP, scope)
 	}
 	underlayRoute := dpdkVIP.Spec.UnderlayRoute
 	existingVirtualIP := *dpdkVIP.Spec.IP
 	if existingVirtualIP == virtualIP {
-		log.V(1).Info("DPDK virtual ip is up-to-date, adding metalbond route if not exists")
-		if err := r.addVirtualIPRouteIfNotExists(ctx, virtualIP, *underlayRoute); err != nil {
+		log.V(1).Info("DPDK virtual ip is up-to-date, applying metalbond route")
+		if err := r.applyVirtualIPRoute(ctx, virtualIP, *underlayRoute, scope); err != nil {
 			return err
 		}
-		log.V(1).Info("Ensured metalbond route exists")
+		log.V(1).Info("Applied metalbond route")
 		return nil
 	}
 
 	log.V(1).Info("Virtual ip is not up-to-date", "ExistingVirtualIP", existingVirtualIP)
 
+	pending, err := r.virtualIPHandoverPending(ctx, log, nic, existingVirtualIP)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return errVirtualIPHandoverPending
+	}
+
 	log.V(1).Info("Delete existing virtual ip")
 	if err := r.deleteExistingVirtualIP(ctx, log, nic, existingVirtualIP, *underlayRoute); err != nil {
 		return err
@@ -698,14 +1025,14 @@ func (r *NetworkInterfaceReconciler) applyVirtualIP(ctx context.Context, log log
 	log.V(1).Info("Deleted existing virtual ip")
 
 	log.V(1).Info("Creating virtual ip")
-	if err := r.createVirtualIP(ctx, log, nic, virtualIP); err != nil {
+	if err := r.createVirtualIP(ctx, log, nic, virtualIP, scope); err != nil {
 		return err
 	}
 	log.V(1).Info("Created virtual ip")
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) createVirtualIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, virtualIP netip.Addr) error {
+func (r *NetworkInterfaceReconciler) createVirtualIP(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, virtualIP netip.Addr, scope metalnetv1alpha1.AnnouncementScope) error {
 	dpdkVIP, err := r.DPDK.CreateVirtualIP(ctx, &dpdk.VirtualIP{
 		VirtualIPMeta: dpdk.VirtualIPMeta{InterfaceID: string(nic.UID)},
 		Spec:          dpdk.VirtualIPSpec{IP: &virtualIP},
@@ -713,11 +1040,16 @@ func (r *NetworkInterfaceReconciler) createVirtualIP(ctx context.Context, log lo
 	if err != nil {
 		return fmt.Errorf("error creating dpdk virtual ip: %w", err)
 	}
-	log.V(1).Info("Adding virtual ip route if not exists")
-	if err := r.addVirtualIPRouteIfNotExists(ctx, virtualIP, *dpdkVIP.Spec.UnderlayRoute); err != nil {
+	log.V(1).Info("Applying virtual ip route")
+	if err := r.applyVirtualIPRoute(ctx, virtualIP, *dpdkVIP.Spec.UnderlayRoute, scope); err != nil {
 		return err
 	}
-	log.V(1).Info("Added virtual ip route if not existed")
+	log.V(1).Info("Applied virtual ip route")
+
+	event := newNetworkInterfaceEvent(eventbus.VirtualIPAnnounced, nic)
+	event.IPs = []netip.Addr{virtualIP}
+	event.UnderlayRoute = dpdkVIP.Spec.UnderlayRoute
+	r.EventBus.Publish(event)
 	return nil
 }
 
@@ -736,6 +1068,14 @@ func (r *NetworkInterfaceReconciler) deleteVirtualIP(ctx context.Context, log lo
 	virtualIP := *dpdkVIP.Spec.IP
 	underlayRoute := *dpdkVIP.Spec.UnderlayRoute
 	log.V(1).Info("Virtual ip exists", "ExistingVirtualIP", virtualIP, "UnderlayRoute", underlayRoute)
+
+	pending, err := r.virtualIPHandoverPending(ctx, log, nic, virtualIP)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return errVirtualIPHandoverPending
+	}
 	return r.deleteExistingVirtualIP(ctx, log, nic, virtualIP, underlayRoute)
 }
 
@@ -751,6 +1091,11 @@ func (r *NetworkInterfaceReconciler) deleteExistingVirtualIP(ctx context.Context
 		return err
 	}
 	log.V(1).Info("Deleted dpdk virtual ip if existed")
+
+	event := newNetworkInterfaceEvent(eventbus.VirtualIPWithdrawn, nic)
+	event.IPs = []netip.Addr{virtualIP}
+	event.UnderlayRoute = &underlayRoute
+	r.EventBus.Publish(event)
 	return nil
 }
 
@@ -768,10 +1113,24 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 	}
 	log.V(1).Info("Ensured finalizer")
 
-	network := &metalnetv1alpha1.Network{}
-	networkKey := client.ObjectKey{Namespace: nic.Namespace, Name: nic.Spec.NetworkRef.Name}
+	if delay := r.RateLimiter.Delay(nic); delay > 0 {
+		log.V(1).Info("Updates are rate limited, delaying", "Delay", delay)
+		if !isUpdateThrottled(nic.Status.Conditions) {
+			r.Eventf(nic, corev1.EventTypeWarning, "UpdateThrottled", "Updates are rate limited, delaying by %s", delay)
+		}
+		if err := r.patchStatus(ctx, nic, func() {
+			setUpdateThrottledCondition(&nic.Status.Conditions, nic.Generation)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	ctx = r.Convergence.Start(ctx, nic)
+
+	networkKey := networkInterfaceNetworkKey(nic)
 	log.V(1).Info("Getting network", "NetworkKey", networkKey)
-	if err := r.Get(ctx, networkKey, network); err != nil {
+	network, err := getNetwork(ctx, r.Client, networkKey)
+	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return ctrl.Result{}, fmt.Errorf("error getting network %s: %w", networkKey, err)
 		}
@@ -779,7 +1138,9 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 		r.Eventf(nic, corev1.EventTypeWarning, "NetworkNotFound", "Network %s could not be found", networkKey.Name)
 		if err := r.patchStatus(ctx, nic, func() {
 			nic.Status = metalnetv1alpha1.NetworkInterfaceStatus{
-				State: metalnetv1alpha1.NetworkInterfaceStatePending,
+				State:              metalnetv1alpha1.NetworkInterfaceStatePending,
+				ReconcileTimeline:  nic.Status.ReconcileTimeline,
+				ReplacedInterfaces: nic.Status.ReplacedInterfaces,
 			}
 		}); err != nil {
 			return ctrl.Result{}, err
@@ -787,58 +1148,163 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 		return ctrl.Result{}, nil
 	}
 
+	if len(nic.Spec.IPs) == 0 {
+		return r.allocateIPs(ctx, log, nic, network)
+	}
+
 	isValid, err := r.isValidInterfaceSpec(&nic.Spec)
 	if !isValid {
 		if errPatch := r.patchStatus(ctx, nic, func() {
 			nic.Status = metalnetv1alpha1.NetworkInterfaceStatus{
-				State: metalnetv1alpha1.NetworkInterfaceStateError,
+				State:              metalnetv1alpha1.NetworkInterfaceStateError,
+				ReconcileTimeline:  nic.Status.ReconcileTimeline,
+				ReplacedInterfaces: nic.Status.ReplacedInterfaces,
 			}
 		}); errPatch != nil {
 			log.Error(errPatch, "Error patching network interface status")
 		}
-		return ctrl.Result{}, fmt.Errorf("interface spec validation error: %w", err)
+		return ctrl.Result{}, newValidationError(fmt.Errorf("interface spec validation error: %w", err))
+	}
+
+	log.V(1).Info("Checking for prefix conflicts")
+	conflict, err := r.findPrefixConflict(ctx, nic)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if conflict != "" {
+		log.V(1).Info("Ips or prefixes conflict with another network interface, not programming", "Conflict", conflict)
+		if cond := meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfacePrefixConflict); cond == nil ||
+			cond.Status != metav1.ConditionTrue {
+			r.Eventf(nic, corev1.EventTypeWarning, "PrefixConflict", "%s", conflict)
+		}
+		if err := r.patchStatus(ctx, nic, func() {
+			nic.Status.State = metalnetv1alpha1.NetworkInterfaceStateError
+			meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.NetworkInterfacePrefixConflict,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: nic.Generation,
+				Reason:             metalnetv1alpha1.PrefixConflictReasonConflict,
+				Message:            conflict,
+			})
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: prefixConflictRequeueInterval}, nil
+	}
+	if meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfacePrefixConflict) {
+		if err := r.patchStatus(ctx, nic, func() {
+			meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.NetworkInterfacePrefixConflict,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: nic.Generation,
+				Reason:             metalnetv1alpha1.PrefixConflictReasonNoConflict,
+			})
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	vni := uint32(network.Spec.ID)
 	log.V(1).Info("Got network", "NetworkKey", networkKey, "VNI", vni)
 
 	log.V(1).Info("Applying interface")
-	pciAddr, underlayRoute, isCreated, err := r.applyInterface(ctx, log, nic, vni)
+	device, underlayRoute, replaced, isCreated, err := r.applyInterface(ctx, log, nic, vni)
+	if errors.Is(err, netfns.ErrDeviceNotReady) {
+		log.V(1).Info("Device is not ready, retrying later", "Reason", err.Error())
+		if cond := meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceDeviceReady); cond == nil ||
+			cond.Status != metav1.ConditionFalse {
+			r.Eventf(nic, corev1.EventTypeWarning, "DeviceNotReady", "Waiting for device to become ready: %v", err)
+		}
+		if err := r.patchStatus(ctx, nic, func() {
+			nic.Status.State = metalnetv1alpha1.NetworkInterfaceStatePending
+			meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.NetworkInterfaceDeviceReady,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: nic.Generation,
+				Reason:             metalnetv1alpha1.DeviceReasonNotReady,
+				Message:            err.Error(),
+			})
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: deviceNotReadyRequeueInterval}, nil
+	}
+	if metalnetdpdk.IsCapacityError(err) {
+		log.V(1).Info("Dpservice table is full, retrying later", "Reason", err.Error())
+		r.eventCapacityExceeded(nic, err)
+		if err := r.patchStatus(ctx, nic, func() {
+			nic.Status.State = metalnetv1alpha1.NetworkInterfaceStatePending
+			setCapacityExceededCondition(nic, err)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.CapacityFeedback.Report(nic.UID, true)
+		return ctrl.Result{RequeueAfter: capacityRequeueInterval}, nil
+	}
 	if err != nil {
 		if err := r.patchStatus(ctx, nic, func() {
 			nic.Status = metalnetv1alpha1.NetworkInterfaceStatus{
-				State: metalnetv1alpha1.NetworkInterfaceStateError,
+				State:              metalnetv1alpha1.NetworkInterfaceStateError,
+				ReconcileTimeline:  nic.Status.ReconcileTimeline,
+				ReplacedInterfaces: nic.Status.ReplacedInterfaces,
 			}
 		}); err != nil {
 			log.Error(err, "Error patching network interface status")
 		}
 		return ctrl.Result{}, fmt.Errorf("error applying interface: %w", err)
 	}
-	log.V(1).Info("Applied interface", "PCIAddress", pciAddr, "UnderlayRoute", underlayRoute)
+	log.V(1).Info("Applied interface", "Device", device.Name, "PCIAddress", &device.PCIAddress, "UnderlayRoute", underlayRoute)
+	if isCreated {
+		event := newNetworkInterfaceEvent(eventbus.InterfaceProgrammed, nic)
+		event.VNI = vni
+		event.IPs = getNetworkInterfaceIPs(nic)
+		event.UnderlayRoute = &underlayRoute
+		r.EventBus.Publish(event)
+	}
 
 	// The interface was just created via GRPC and object status state is already Ready.
 	// So toggle the status state to reflect the "readiness" of the interface.
 	if isCreated && nic.Status.State == metalnetv1alpha1.NetworkInterfaceStateReady {
 		if err := r.patchStatus(ctx, nic, func() {
 			nic.Status = metalnetv1alpha1.NetworkInterfaceStatus{
-				State: metalnetv1alpha1.NetworkInterfaceStatePending,
+				State:              metalnetv1alpha1.NetworkInterfaceStatePending,
+				ReconcileTimeline:  nic.Status.ReconcileTimeline,
+				ReplacedInterfaces: nic.Status.ReplacedInterfaces,
 			}
 		}); err != nil {
 			log.Error(err, "Error patching network interface status to pending")
 		}
 		if err := r.patchStatus(ctx, nic, func() {
 			nic.Status = metalnetv1alpha1.NetworkInterfaceStatus{
-				State: metalnetv1alpha1.NetworkInterfaceStateReady,
+				State:              metalnetv1alpha1.NetworkInterfaceStateReady,
+				ReconcileTimeline:  nic.Status.ReconcileTimeline,
+				ReplacedInterfaces: nic.Status.ReplacedInterfaces,
 			}
 		}); err != nil {
 			log.Error(err, "Error patching network interface status to ready")
 		}
 	}
+	if replaced != nil && uint32(replaced.VNI) != vni {
+		if err := r.patchStatus(ctx, nic, func() {
+			meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.NetworkInterfaceVNIMigration,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: nic.Generation,
+				Reason:             metalnetv1alpha1.VNIMigrationReasonMigrating,
+				Message:            fmt.Sprintf("Migrating from VNI %d to VNI %d", replaced.VNI, vni),
+			})
+		}); err != nil {
+			log.Error(err, "Error patching network interface status to migrating")
+		}
+	}
 	var errs []error
 
 	log.V(1).Info("Reconciling virtual ip")
-	virtualIPErr := r.reconcileVirtualIP(ctx, log, nic)
-	if virtualIPErr != nil {
+	virtualIPScope := virtualIPAnnouncementScope(nic, network)
+	virtualIPErr := r.reconcileVirtualIP(ctx, log, nic, virtualIPScope)
+	if errors.Is(virtualIPErr, errVirtualIPHandoverPending) {
+		log.V(1).Info("Keeping existing virtual ip until handover completes")
+	} else if virtualIPErr != nil {
 		errs = append(errs, fmt.Errorf("error reconciling virtual ip: %w", virtualIPErr))
 		log.Error(virtualIPErr, "Error reconciling virtual ip")
 		r.Eventf(nic, corev1.EventTypeWarning, "ErrorReconcilingVirtualIP", "Error reconciling virtual ip: %v", err)
@@ -847,7 +1313,7 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 	}
 
 	log.V(1).Info("Reconciling nat ip")
-	natIPErr := r.reconcileNATIP(ctx, log, nic, vni)
+	nat, natIPErr := r.reconcileNATIP(ctx, log, nic, vni)
 	if natIPErr != nil {
 		errs = append(errs, fmt.Errorf("error reconciling nat ip: %w", natIPErr))
 		log.Error(natIPErr, "Error reconciling nat ip")
@@ -877,7 +1343,7 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 	}
 
 	log.V(1).Info("Reconciling firewall rules")
-	fwruleErr := r.reconcileFirewallRules(ctx, log, nic)
+	fwruleErr := r.reconcileFirewallRules(ctx, log, nic, interfaceFirewallRules(nic, network))
 	if fwruleErr != nil {
 		errs = append(errs, fmt.Errorf("error reconciling firewall rules: %w", fwruleErr))
 		log.Error(fwruleErr, "Error reconciling firewall rules")
@@ -886,12 +1352,41 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 		log.V(1).Info("Reconciled firewall rules")
 	}
 
+	log.V(1).Info("Withdrawing announcements of replaced interfaces")
+	migratedVNIs, migratingVNIs, withdrawErr := r.withdrawReplacedInterfaces(ctx, log, nic, vni, underlayRoute)
+	if withdrawErr != nil {
+		errs = append(errs, fmt.Errorf("error withdrawing announcements of replaced interface: %w", withdrawErr))
+		log.Error(withdrawErr, "Error withdrawing announcements of replaced interface")
+	} else {
+		log.V(1).Info("Withdrew announcements of replaced interfaces")
+	}
+	for _, oldVNI := range migratedVNIs {
+		r.Eventf(nic, corev1.EventTypeNormal, "VNIMigrated", "Withdrew announcements of VNI %d", oldVNI)
+	}
+
+	capacityErr, onlyCapacityErrs := capacityError(errs)
+	if capacityErr != nil {
+		r.eventCapacityExceeded(nic, capacityErr)
+	}
+	deniedErr := routeDeniedError(errs)
+	if deniedErr != nil && !meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.AnnouncementDenied) {
+		r.Eventf(nic, corev1.EventTypeWarning, "AnnouncementDenied", "Announcement denied: %v", deniedErr)
+	}
+	announced := virtualIPErr == nil && natIPErr == nil &&
+		lbTargetErr == nil && prefixesErr == nil && withdrawErr == nil
+
 	log.V(1).Info("Patching status")
 	if err := r.patchStatus(ctx, nic, func() {
 		nic.Status.State = metalnetv1alpha1.NetworkInterfaceStateReady
+		recordProgrammed(&nic.Status.ReconcileTimeline, announced)
+		meta.RemoveStatusCondition(&nic.Status.Conditions, metalnetv1alpha1.UpdateThrottled)
+		setCapacityExceededCondition(nic, capacityErr)
+		setAnnouncementDeniedCondition(&nic.Status.Conditions, nic.Generation, deniedErr)
+		setVNIMigrationCondition(nic, vni, migratedVNIs, migratingVNIs, withdrawErr)
+		pciAddr := device.PCIAddress
 		if r.BluefieldDetected {
 			pciAddr.Bus = r.BluefieldHostDefaultBusAddr
-			log.V(1).Info("Bluefield detected. Converting PCI Bus to the host PCI bus", "PCIAddress", pciAddr)
+			log.V(1).Info("Bluefield detected. Converting PCI Bus to the host PCI bus", "PCIAddress", &pciAddr)
 		}
 		nic.Status.PCIAddress = &metalnetv1alpha1.PCIAddress{
 			Bus:      pciAddr.Bus,
@@ -899,17 +1394,41 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 			Slot:     pciAddr.Device,
 			Function: pciAddr.Function,
 		}
-		if virtualIPErr == nil {
+		nic.Status.Device = deviceStatus(device, r.BluefieldDetected)
+		meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.NetworkInterfaceDeviceReady,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nic.Generation,
+			Reason:             metalnetv1alpha1.DeviceReasonReady,
+			Message:            fmt.Sprintf("Interface is programmed on device %s", device.Name),
+		})
+		switch {
+		case errors.Is(virtualIPErr, errVirtualIPHandoverPending):
+			setVirtualIPHandoverPendingCondition(nic)
+		case virtualIPErr == nil:
 			nic.Status.VirtualIP = nic.Spec.VirtualIP
-		}
-		if natIPErr == nil {
-			if nic.Spec.NAT != nil {
-				nic.Status.NatIP = nic.Spec.NAT
+			if nic.Spec.VirtualIP != nil {
+				reason, message := metalnetv1alpha1.VirtualIPReasonAnnounced, "Virtual ip is programmed and announced"
+				if virtualIPScope == metalnetv1alpha1.AnnouncementScopePrivate {
+					reason, message = metalnetv1alpha1.VirtualIPReasonPrivate, "Virtual ip is programmed but not announced into the public VNI"
+				}
+				meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+					Type:               metalnetv1alpha1.NetworkInterfaceVirtualIPReady,
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: nic.Generation,
+					Reason:             reason,
+					Message:            message,
+				})
 			} else {
-				nic.Status.NatIP = nil
+				meta.RemoveStatusCondition(&nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)
 			}
+		}
+		if natIPErr == nil {
+			nic.Status.NatIP = nat
+			nic.Status.NATPortBlocks = r.natPortBlocks(nat, vni)
 		} else {
 			nic.Status.NatIP = nil
+			nic.Status.NATPortBlocks = nil
 		}
 		if prefixesErr == nil {
 			nic.Status.Prefixes = nic.Spec.Prefixes
@@ -920,10 +1439,20 @@ func (r *NetworkInterfaceReconciler) reconcile(ctx context.Context, log logr.Log
 	}); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error patching status: %w", err)
 	}
+	r.CapacityFeedback.Report(nic.UID, capacityErr != nil)
 
+	if onlyCapacityErrs {
+		// Retrying does not help until dpservice tables free up, don't hammer it with the error backoff.
+		log.V(1).Info("Dpservice table is full, retrying later", "Reason", capacityErr.Error())
+		return ctrl.Result{RequeueAfter: capacityRequeueInterval}, nil
+	}
 	if len(errs) > 0 {
-		return ctrl.Result{}, fmt.Errorf("error applying network interface parts: %v", errs)
+		return ctrl.Result{}, fmt.Errorf("error applying network interface parts: %w", errors.Join(errs...))
 	}
+	if errors.Is(virtualIPErr, errVirtualIPHandoverPending) {
+		return ctrl.Result{RequeueAfter: virtualIPHandoverRequeueInterval}, nil
+	}
+	r.Convergence.Programmed(nic)
 	return ctrl.Result{}, nil
 }
 
@@ -947,31 +1476,20 @@ func (r *NetworkInterfaceReconciler) reconcilePrefixes(ctx context.Context, log
 		}
 	}
 
-	// Sort prefixes to have deterministic error event output
-	allPrefixes := dpdkPrefixes.UnsortedList()
-	sort.Slice(allPrefixes, func(i, j int) bool {
-		return allPrefixes[i].String() < allPrefixes[j].String()
-	})
-
-	if dpdkPrefixes.Len() < specPrefixes.Len() {
-		allPrefixes = specPrefixes.UnsortedList()
-		sort.Slice(allPrefixes, func(i, j int) bool {
-			return allPrefixes[i].String() < allPrefixes[j].String()
-		})
-	}
 	var errs []error
-	for _, prefix := range allPrefixes {
+	for _, prefix := range orderPrefixChanges(dpdkPrefixes, specPrefixes) {
+		if len(errs) > 0 && dpdkPrefixes.Has(prefix) && !specPrefixes.Has(prefix) {
+			// Keep the prefixes to be replaced until all new ones are in place.
+			log.V(1).Info("Not removing prefix as reconciling other prefixes failed", "Prefix", prefix)
+			continue
+		}
 		if err := func() error {
 			log := log.WithValues("Prefix", prefix)
 			switch {
 			case dpdkPrefixes.Has(prefix) && !specPrefixes.Has(prefix):
 				log.V(1).Info("Delete prefix")
-				underlayRoute, err := getUnderlayRouteFromPrefixesList(list.Items, prefix)
-				if err != nil {
-					return err
-				}
 				log.V(1).Info("Ensuring metalbond prefix route does not exist")
-				if err := r.removePrefixRouteIfExists(ctx, vni, prefix, underlayRoute); err != nil {
+				if err := r.removePrefixRouteIfExists(ctx, vni, nic.UID, prefix); err != nil {
 					return err
 				}
 				log.V(1).Info("Ensured metalbond prefix route does not exist")
@@ -996,7 +1514,7 @@ func (r *NetworkInterfaceReconciler) reconcilePrefixes(ctx context.Context, log
 				log.V(1).Info("Ensured dpdk prefix exists")
 
 				log.V(1).Info("Ensuring metalbond prefix route exists")
-				if err := r.addPrefixRouteIfNotExists(ctx, vni, prefix, *resPrefix.Spec.UnderlayRoute); err != nil {
+				if err := r.addPrefixRouteIfNotExists(ctx, vni, nic.UID, prefix, *resPrefix.Spec.UnderlayRoute); err != nil {
 					return err
 				}
 				log.V(1).Info("Ensured metalbond prefix route exists")
@@ -1008,7 +1526,7 @@ func (r *NetworkInterfaceReconciler) reconcilePrefixes(ctx context.Context, log
 					return err
 				}
 				log.V(1).Info("Ensuring metalbond prefix route exists")
-				if err := r.addPrefixRouteIfNotExists(ctx, vni, prefix, underlayRoute); err != nil {
+				if err := r.addPrefixRouteIfNotExists(ctx, vni, nic.UID, prefix, underlayRoute); err != nil {
 					return err
 				}
 				log.V(1).Info("Ensured metalbond prefix route exists")
@@ -1020,14 +1538,31 @@ func (r *NetworkInterfaceReconciler) reconcilePrefixes(ctx context.Context, log
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("error(s) reconciling prefix(es): %v", errs)
+		return fmt.Errorf("error(s) reconciling prefix(es): %w", errors.Join(errs...))
 	}
 	return nil
 }
 
+// orderPrefixChanges returns the union of the current and desired prefixes in the order they are reconciled: added
+// prefixes first, then kept ones and removed ones last, each sorted to have deterministic error event output.
+// Programming and announcing new prefixes before removing the replaced ones keeps replacements, e.g. after a
+// selector change, hitless.
+func orderPrefixChanges(current, desired sets.Set[netip.Prefix]) []netip.Prefix {
+	sorted := func(prefixes sets.Set[netip.Prefix]) []netip.Prefix {
+		list := prefixes.UnsortedList()
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].String() < list[j].String()
+		})
+		return list
+	}
+	ordered := sorted(desired.Difference(current))
+	ordered = append(ordered, sorted(desired.Intersection(current))...)
+	return append(ordered, sorted(current.Difference(desired))...)
+}
+
 func getUnderlayRouteFromPrefixesList(list []dpdk.Prefix, searchPrefix netip.Prefix) (netip.Addr, error) {
 	for _, dpdkPrefix := range list {
-		if dpdkPrefix.Spec.Prefix.Addr() == searchPrefix.Addr() {
+		if dpdkPrefix.Spec.Prefix == searchPrefix {
 			return *dpdkPrefix.Spec.UnderlayRoute, nil
 		}
 	}
@@ -1050,21 +1585,15 @@ func (r *NetworkInterfaceReconciler) reconcileLBTargets(ctx context.Context, log
 	for _, specPrefix := range nic.Spec.LoadBalancerTargets {
 		specPrefixes.Insert(specPrefix.Prefix)
 	}
+	specPrefixes.Insert(r.EndpointSliceTargets.Targets(client.ObjectKeyFromObject(nic))...)
 
-	// Sort prefixes to have deterministic error event output
-	allPrefixes := dpdkPrefixes.UnsortedList()
-	sort.Slice(allPrefixes, func(i, j int) bool {
-		return allPrefixes[i].String() < allPrefixes[j].String()
-	})
-
-	if dpdkPrefixes.Len() < specPrefixes.Len() {
-		allPrefixes = specPrefixes.UnsortedList()
-		sort.Slice(allPrefixes, func(i, j int) bool {
-			return allPrefixes[i].String() < allPrefixes[j].String()
-		})
-	}
 	var errs []error
-	for _, prefix := range allPrefixes {
+	for _, prefix := range orderPrefixChanges(dpdkPrefixes, specPrefixes) {
+		if len(errs) > 0 && dpdkPrefixes.Has(prefix) && !specPrefixes.Has(prefix) {
+			// Keep the prefixes to be replaced until all new ones are in place.
+			log.V(1).Info("Not removing prefix as reconciling other prefixes failed", "Prefix", prefix)
+			continue
+		}
 		if err := func() error {
 			log := log.WithValues("LB Target", prefix)
 			switch {
@@ -1085,6 +1614,12 @@ func (r *NetworkInterfaceReconciler) reconcileLBTargets(ctx context.Context, log
 					return err
 				}
 				log.V(1).Info("Ensured dpdk lb target does not exist")
+
+				event := newNetworkInterfaceEvent(eventbus.LoadBalancerTargetRemoved, nic)
+				event.VNI = vni
+				event.Prefix = &prefix
+				event.UnderlayRoute = &underlayRoute
+				r.EventBus.Publish(event)
 				return nil
 			case specPrefixes.Has(prefix) && !dpdkPrefixes.Has(prefix):
 				log.V(1).Info("Create lb target")
@@ -1100,10 +1635,16 @@ func (r *NetworkInterfaceReconciler) reconcileLBTargets(ctx context.Context, log
 				log.V(1).Info("Ensured dpdk lb target exists")
 
 				log.V(1).Info("Ensuring metalbond lb target route exists")
-				if err := r.addLBTargetRouteIfNotExists(ctx, vni, prefix, *resPrefix.Spec.UnderlayRoute); err != nil {
+				if err := r.ensureLBTargetRoute(ctx, nic, vni, prefix, *resPrefix.Spec.UnderlayRoute); err != nil {
 					return err
 				}
 				log.V(1).Info("Ensured metalbond lb target route exists")
+
+				event := newNetworkInterfaceEvent(eventbus.LoadBalancerTargetAdded, nic)
+				event.VNI = vni
+				event.Prefix = &prefix
+				event.UnderlayRoute = resPrefix.Spec.UnderlayRoute
+				r.EventBus.Publish(event)
 				return nil
 			default:
 				log.V(1).Info("Update lb target")
@@ -1115,7 +1656,7 @@ func (r *NetworkInterfaceReconciler) reconcileLBTargets(ctx context.Context, log
 				if err := r.removeLBTargetRouteIfExists(ctx, vni, prefix, underlayRoute); err != nil {
 					return err
 				}
-				if err := r.addLBTargetRouteIfNotExists(ctx, vni, prefix, underlayRoute); err != nil {
+				if err := r.ensureLBTargetRoute(ctx, nic, vni, prefix, underlayRoute); err != nil {
 					return err
 				}
 				log.V(1).Info("Ensured metalbond lb target route exists")
@@ -1127,12 +1668,32 @@ func (r *NetworkInterfaceReconciler) reconcileLBTargets(ctx context.Context, log
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("error(s) reconciling lb target: %v", errs)
+		return fmt.Errorf("error(s) reconciling lb target: %w", errors.Join(errs...))
 	}
 	return nil
 }
 
-func (r *NetworkInterfaceReconciler) reconcileFirewallRules(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface) error {
+// interfaceFirewallRules returns the firewall rules of the NetworkInterface merged with the default firewall
+// rules of its Network. Rules of the NetworkInterface override default rules with the same id.
+func interfaceFirewallRules(nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) []metalnetv1alpha1.FirewallRule {
+	if len(network.Spec.DefaultFirewallRules) == 0 {
+		return nic.Spec.FirewallRules
+	}
+
+	ids := sets.New[types.UID]()
+	for _, rule := range nic.Spec.FirewallRules {
+		ids.Insert(rule.FirewallRuleID)
+	}
+	rules := slices.Clone(nic.Spec.FirewallRules)
+	for _, rule := range network.Spec.DefaultFirewallRules {
+		if !ids.Has(rule.FirewallRuleID) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (r *NetworkInterfaceReconciler) reconcileFirewallRules(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, rules []metalnetv1alpha1.FirewallRule) error {
 	log.V(1).Info("Listing firewall rules")
 	fwList, err := r.DPDK.ListFirewallRules(ctx, string(nic.UID))
 	if err != nil {
@@ -1141,12 +1702,14 @@ func (r *NetworkInterfaceReconciler) reconcileFirewallRules(ctx context.Context,
 	list := fwList.Items
 
 	dpdkFirewallRules := sets.New[string]()
-	for _, dpdkFirewallRule := range list {
+	dpdkFirewallRuleSpecs := make(map[string]*dpdk.FirewallRuleSpec, len(list))
+	for i, dpdkFirewallRule := range list {
 		dpdkFirewallRules.Insert(dpdkFirewallRule.Spec.RuleID)
+		dpdkFirewallRuleSpecs[dpdkFirewallRule.Spec.RuleID] = &list[i].Spec
 	}
 
 	specFirewallRules := sets.New[string]()
-	for _, specFirewallRule := range nic.Spec.FirewallRules {
+	for _, specFirewallRule := range rules {
 		specFirewallRules.Insert(string(specFirewallRule.FirewallRuleID))
 	}
 
@@ -1176,18 +1739,44 @@ func (r *NetworkInterfaceReconciler) reconcileFirewallRules(ctx context.Context,
 				log.V(1).Info("Ensured dpdk fwRuleID does not exist")
 				return nil
 			case specFirewallRules.Has(fwRuleID) && !dpdkFirewallRules.Has(fwRuleID):
-				for _, specFirewallRule = range nic.Spec.FirewallRules {
+				for _, specFirewallRule = range rules {
 					if specFirewallRule.FirewallRuleID == types.UID(fwRuleID) {
 						break
 					}
 				}
+				dpdkFirewallRule, err := r.newDPDKFwRule(ctx, nic, &specFirewallRule)
+				if err != nil {
+					return err
+				}
 				log.V(1).Info("Creating dpdk fwRuleID")
-				if err := r.createDPDKFwRule(ctx, nic, &specFirewallRule); err != nil {
+				if err := r.createDPDKFwRule(ctx, dpdkFirewallRule); err != nil {
 					return err
 				}
 				log.V(1).Info("Ensured dpdk fwRuleID exists")
 				return nil
 			default:
+				for _, specFirewallRule = range rules {
+					if specFirewallRule.FirewallRuleID == types.UID(fwRuleID) {
+						break
+					}
+				}
+				dpdkFirewallRule, err := r.newDPDKFwRule(ctx, nic, &specFirewallRule)
+				if err != nil {
+					return err
+				}
+				if !metalnetdpdk.FirewallRuleSpecDrifted(dpdkFirewallRuleSpecs[fwRuleID], &dpdkFirewallRule.Spec) {
+					return nil
+				}
+
+				// Happens e.g. if a default rule of the Network is overridden by the NetworkInterface.
+				log.V(1).Info("DPDK fwRuleID drifted, recreating it")
+				if err := r.deleteDPDKfwRuleIDIfExists(ctx, string(nic.UID), fwRuleID); err != nil {
+					return err
+				}
+				if err := r.createDPDKFwRule(ctx, dpdkFirewallRule); err != nil {
+					return err
+				}
+				log.V(1).Info("Recreated dpdk fwRuleID")
 				return nil
 			}
 		}(); err != nil {
@@ -1196,7 +1785,7 @@ func (r *NetworkInterfaceReconciler) reconcileFirewallRules(ctx context.Context,
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("error(s) reconciling fwRuleID(es): %v", errs)
+		return fmt.Errorf("error(s) reconciling fwRuleID(es): %w", errors.Join(errs...))
 	}
 	return nil
 }
@@ -1237,116 +1826,255 @@ func (r *NetworkInterfaceReconciler) getInterfaceMeteringParams(nic *metalnetv1a
 	return meterParams, nil
 }
 
-func (r *NetworkInterfaceReconciler) applyInterface(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, vni uint32) (*ghw.PCIAddress, netip.Addr, bool, error) {
+func (r *NetworkInterfaceReconciler) newDPDKInterface(nic *metalnetv1alpha1.NetworkInterface, vni uint32, dpdkDevice string) (*dpdk.Interface, error) {
+	primaryIpv4 := getNetworkInterfaceIP(corev1.IPv4Protocol, nic)
+	primaryIpv6 := getNetworkInterfaceIP(corev1.IPv6Protocol, nic)
+
+	meteringParams, err := r.getInterfaceMeteringParams(nic)
+	if err != nil {
+		return nil, fmt.Errorf("error getting metering params: %w", err)
+	}
+
+	return &dpdk.Interface{
+		InterfaceMeta: dpdk.InterfaceMeta{ID: string(nic.UID)},
+		Spec: dpdk.InterfaceSpec{
+			VNI:      vni,
+			Device:   dpdkDevice,
+			IPv4:     &primaryIpv4,
+			IPv6:     &primaryIpv6,
+			Metering: meteringParams,
+		},
+	}, nil
+}
+
+func (r *NetworkInterfaceReconciler) createDPDKInterface(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, vni uint32, device *netfns.Device) (netip.Addr, error) {
+	desired, err := r.newDPDKInterface(nic, vni, device.Name)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	if err := r.removeConflictingDPDKInterfaces(ctx, log, nic, desired); err != nil {
+		return netip.Addr{}, err
+	}
+
+	log.V(1).Info("Creating dpdk interface")
+	iface, err := r.DPDK.CreateInterface(ctx, desired)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("error creating dpdk interface: %w", err)
+	}
+	log.V(1).Info("Created dpdk interface")
+	return *iface.Spec.UnderlayRoute, nil
+}
+
+// checkDeviceReady returns an error wrapping netfns.ErrDeviceNotReady if the interface cannot be
+// created on the device yet. Creating it anyway races with the driver binding the device and fails
+// with errors that do not point at the device.
+func (r *NetworkInterfaceReconciler) checkDeviceReady(log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, device *netfns.Device) error {
+	if nic.Spec.HostInterface {
+		// The host port is set up with dpservice.
+		return nil
+	}
+	log.V(1).Info("Checking device readiness", "Device", device.Name)
+	ready := r.DeviceAllocator.Ready
+	if nic.Spec.DeviceClaim != nil && r.DeviceClaims != nil {
+		ready = r.DeviceClaims.Ready
+	}
+	if err := ready(device); err != nil {
+		return fmt.Errorf("device %s: %w", device.Name, err)
+	}
+	log.V(1).Info("Device is ready", "Device", device.Name)
+	return nil
+}
+
+// getOrClaimDevice returns the host port for host interfaces, the device allocated to the pod of the device claim
+// of the network interface or, if it has none, the device it claimed from the device pool, claiming a free one if
+// there is none.
+func (r *NetworkInterfaceReconciler) getOrClaimDevice(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) (*netfns.Device, error) {
+	switch {
+	case nic.Spec.HostInterface:
+		return r.hostInterfaceDevice()
+	case nic.Spec.DeviceClaim != nil:
+		return r.resolveDeviceClaim(ctx, nic)
+	}
+	return r.DeviceAllocator.GetOrClaim(nic.UID)
+}
+
+// getDevice returns the host port for host interfaces, the device allocated to the pod of the device claim of the
+// network interface or, if it has none, the device it claimed from the device pool.
+func (r *NetworkInterfaceReconciler) getDevice(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) (*netfns.Device, error) {
+	switch {
+	case nic.Spec.HostInterface:
+		return r.hostInterfaceDevice()
+	case nic.Spec.DeviceClaim != nil:
+		return r.resolveDeviceClaim(ctx, nic)
+	}
+	return r.DeviceAllocator.Get(nic.UID)
+}
+
+func (r *NetworkInterfaceReconciler) hostInterfaceDevice() (*netfns.Device, error) {
+	if r.HostInterfaceDevice == "" {
+		return nil, fmt.Errorf("network interface is a host interface, but host interfaces are not enabled on the node")
+	}
+	return &netfns.Device{Name: r.HostInterfaceDevice}, nil
+}
+
+func (r *NetworkInterfaceReconciler) resolveDeviceClaim(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) (*netfns.Device, error) {
+	if r.DeviceClaims == nil {
+		return nil, fmt.Errorf("network interface references a device claim, but device claims are not enabled on the node")
+	}
+	claim := nic.Spec.DeviceClaim
+	return r.DeviceClaims.Resolve(ctx, netfns.DeviceClaim{
+		Namespace: nic.Namespace,
+		Pod:       claim.PodRef.Name,
+		Container: claim.ContainerName,
+		Resource:  claim.ResourceName,
+		Index:     int(claim.Index),
+	})
+}
+
+// deviceStatus returns the details of the device to report to the compute layer. On Bluefield cards the
+// driver and NUMA node describe the card instead of the host and are left out.
+func deviceStatus(device *netfns.Device, bluefield bool) *metalnetv1alpha1.DeviceStatus {
+	status := &metalnetv1alpha1.DeviceStatus{Name: device.Name}
+	if device.VFIndex != nil {
+		status.VFIndex = ptr.To(int32(*device.VFIndex))
+	}
+	if !bluefield {
+		status.Driver = device.Driver
+		if device.NUMANode != nil {
+			status.NUMANode = ptr.To(int32(*device.NUMANode))
+		}
+	}
+	return status
+}
+
+// setVNIMigrationCondition reports the VNI migrations of the network interface. The condition stays true until
+// the announcements of all previous VNIs are withdrawn.
+func setVNIMigrationCondition(nic *metalnetv1alpha1.NetworkInterface, vni uint32, migratedVNIs, migratingVNIs []uint32, withdrawErr error) {
+	switch {
+	case len(migratingVNIs) > 0:
+		meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.NetworkInterfaceVNIMigration,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nic.Generation,
+			Reason:             metalnetv1alpha1.VNIMigrationReasonMigrating,
+			Message:            fmt.Sprintf("Migrating from VNI %v to VNI %d, withdrawing old announcements failed: %v", migratingVNIs, vni, withdrawErr),
+		})
+	case len(migratedVNIs) > 0:
+		meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.NetworkInterfaceVNIMigration,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: nic.Generation,
+			Reason:             metalnetv1alpha1.VNIMigrationReasonMigrated,
+			Message:            fmt.Sprintf("Migrated from VNI %d to VNI %d", migratedVNIs[len(migratedVNIs)-1], vni),
+		})
+	}
+}
+
+// applyInterface creates or updates the dpservice interface of the network interface. If the interface was
+// replaced to update its primary ips or its VNI, the replaced interface is returned. Its announcements are
+// withdrawn once the replacement is fully programmed.
+func (r *NetworkInterfaceReconciler) applyInterface(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, vni uint32) (*netfns.Device, netip.Addr, *metalnetv1alpha1.ReplacedInterface, bool, error) {
 	log.V(1).Info("Getting dpdk interface")
 	iface, err := r.DPDK.GetInterface(ctx, string(nic.UID))
 	if err != nil {
 		if !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND) {
-			return nil, netip.Addr{}, false, fmt.Errorf("error getting dpdk interface: %w", err)
+			return nil, netip.Addr{}, nil, false, fmt.Errorf("error getting dpdk interface: %w", err)
 		}
 
 		log.V(1).Info("DPDK interface does not yet exist, creating it")
 
-		log.V(1).Info("Getting or claiming pci address")
-		addr, err := r.NetFnsManager.GetOrClaim(nic.UID)
+		log.V(1).Info("Getting or claiming device")
+		device, err := r.getOrClaimDevice(ctx, nic)
 		if err != nil {
-			return nil, netip.Addr{}, false, fmt.Errorf("error claiming address: %w", err)
+			return nil, netip.Addr{}, nil, false, fmt.Errorf("error claiming device: %w", err)
 		}
-		log.V(1).Info("Got pci address", "Address", addr)
+		log.V(1).Info("Got device", "Device", device.Name)
 
-		log.V(1).Info("Converting to dpdk device")
-		dpdkDevice, err := r.convertToDPDKDevice(*addr)
-		if err != nil {
-			return nil, netip.Addr{}, false, fmt.Errorf("error converting %s to dpdk device: %w", addr, err)
+		if err := r.checkDeviceReady(log, nic, device); err != nil {
+			return nil, netip.Addr{}, nil, false, err
 		}
-		log.V(1).Info("Converted to dpdk device", "DPDKDevice", dpdkDevice)
 
-		log.V(1).Info("Creating dpdk interface")
-
-		primaryIpv4 := getNetworkInterfaceIP(corev1.IPv4Protocol, nic)
-		primaryIpv6 := getNetworkInterfaceIP(corev1.IPv6Protocol, nic)
-
-		meteringParams, err := r.getInterfaceMeteringParams(nic)
-		if err != nil {
-			return nil, netip.Addr{}, false, fmt.Errorf("error getting metering params: %w", err)
-		}
-
-		iface, err := r.DPDK.CreateInterface(ctx, &dpdk.Interface{
-			InterfaceMeta: dpdk.InterfaceMeta{ID: string(nic.UID)},
-			Spec: dpdk.InterfaceSpec{
-				VNI:      vni,
-				Device:   dpdkDevice,
-				IPv4:     &primaryIpv4,
-				IPv6:     &primaryIpv6,
-				Metering: meteringParams,
-			},
-		})
+		underlayRoute, err := r.createDPDKInterface(ctx, log, nic, vni, device)
 		if err != nil {
-			return nil, netip.Addr{}, false, fmt.Errorf("error creating dpdk interface: %w", err)
+			return nil, netip.Addr{}, nil, false, err
 		}
+
 		log.V(1).Info("Adding interface routes if not exist")
 		ips := getNetworkInterfaceIPs(nic)
-		if err := r.addInterfaceRoutesIfNotExist(ctx, log, vni, ips, *iface.Spec.UnderlayRoute); err != nil {
-			return nil, netip.Addr{}, false, err
+		if err := r.addInterfaceRoutesIfNotExist(ctx, log, vni, ips, underlayRoute); err != nil {
+			return nil, netip.Addr{}, nil, false, err
 		}
 		log.V(1).Info("Added interface routes if not existed")
-		return addr, *iface.Spec.UnderlayRoute, true, nil
+		return device, underlayRoute, nil, true, nil
 	}
 
 	log.V(1).Info("DPDK interface exists")
 
-	log.V(1).Info("Getting pci device for uid")
-	addr, err := r.NetFnsManager.Get(nic.UID)
+	log.V(1).Info("Getting device for uid")
+	device, err := r.getDevice(ctx, nic)
 	if err != nil {
-		return nil, netip.Addr{}, false, fmt.Errorf("error getting pci address: %w", err)
+		return nil, netip.Addr{}, nil, false, fmt.Errorf("error getting device: %w", err)
 	}
-	log.V(1).Info("Got pci device for uid", "PCIDevice", addr)
+	log.V(1).Info("Got device for uid", "Device", device.Name)
 
-	log.V(1).Info("Adding interface route if not exists")
-	ips := getNetworkInterfaceIPs(nic)
-	if err := r.addInterfaceRoutesIfNotExist(ctx, log, vni, ips, *iface.Spec.UnderlayRoute); err != nil {
-		return nil, netip.Addr{}, false, err
-	}
-	log.V(1).Info("Added interface route if not existed")
-	return addr, *iface.Spec.UnderlayRoute, false, nil
-}
-
-func (r *NetworkInterfaceReconciler) convertToDPDKDevice(addr ghw.PCIAddress) (string, error) {
-	if strings.Contains(addr.Device, "tap") {
-		return strings.ReplaceAll(strings.ReplaceAll(addr.Device, ":", ""), ".", ""), nil
-	}
-	pciFunction, err := strconv.ParseUint(addr.Function, 8, 64)
+	desired, err := r.newDPDKInterface(nic, vni, device.Name)
 	if err != nil {
-		return "", fmt.Errorf("error parsing address function %s: %w", addr.Function, err)
+		return nil, netip.Addr{}, nil, false, err
 	}
 
-	pciDevice, err := strconv.ParseUint(addr.Device, 16, 64)
-	if err != nil {
-		return "", fmt.Errorf("error parsing address device %s: %w", addr.Device, err)
+	if iface.Spec.VNI != vni || metalnetdpdk.InterfaceIPsDrifted(&iface.Spec, &desired.Spec) {
+		log.V(1).Info("VNI or primary ips of dpdk interface changed, replacing it",
+			"ExistingVNI", iface.Spec.VNI,
+			"ExistingIPv4", iface.Spec.IPv4,
+			"ExistingIPv6", iface.Spec.IPv6,
+		)
+		underlayRoute, replaced, err := r.replaceDPDKInterface(ctx, log, nic, iface, device, vni)
+		if err != nil {
+			return nil, netip.Addr{}, nil, false, err
+		}
+		return device, underlayRoute, replaced, true, nil
 	}
-	pciFunction = pciDevice*8 + pciFunction
 
-	pciDev, err := r.SysFS.PCIDevice(addr)
-	if err != nil {
-		// Calculate based on the offset parameter if sysfs not available
-		return fmt.Sprintf("%s:%s:00.0_representor_vf%d", addr.Domain, addr.Bus, pciFunction-uint64(r.PfToVfOffset)), nil
-	} else {
-		physFn, err := pciDev.Physfn()
-		if err != nil {
-			return "", fmt.Errorf("error getting sysfs physfn: %w", err)
+	if metalnetdpdk.InterfaceSpecDrifted(&iface.Spec, &desired.Spec) {
+		log.V(1).Info("DPDK interface drifted, recreating it",
+			"ExistingVNI", iface.Spec.VNI,
+			"ExistingIPv4", iface.Spec.IPv4,
+			"ExistingIPv6", iface.Spec.IPv6,
+			"ExistingDevice", iface.Spec.Device,
+		)
+
+		if err := r.checkDeviceReady(log, nic, device); err != nil {
+			return nil, netip.Addr{}, nil, false, err
 		}
 
-		physFnAddr, err := physFn.Address()
-		if err != nil {
-			return "", fmt.Errorf("error getting physfn details: %w", err)
+		log.V(1).Info("Removing routes of drifted interface if exist")
+		if err := r.removeInterfaceRoutesIfExist(ctx, log, iface.Spec.VNI, getDPDKInterfaceIPs(iface), *iface.Spec.UnderlayRoute); err != nil {
+			return nil, netip.Addr{}, nil, false, err
 		}
+		log.V(1).Info("Removed routes of drifted interface if existed")
 
-		sriov, err := physFn.SRIOV()
+		underlayRoute, err := r.createDPDKInterface(ctx, log, nic, vni, device)
 		if err != nil {
-			return "", fmt.Errorf("error getting sysfs sriov: %w", err)
+			return nil, netip.Addr{}, nil, false, err
+		}
+
+		log.V(1).Info("Adding interface routes if not exist")
+		ips := getNetworkInterfaceIPs(nic)
+		if err := r.addInterfaceRoutesIfNotExist(ctx, log, vni, ips, underlayRoute); err != nil {
+			return nil, netip.Addr{}, nil, false, err
 		}
-		return fmt.Sprintf("%s:%s:%s.0_representor_vf%d", physFnAddr.Domain, physFnAddr.Bus, physFnAddr.Device, pciFunction-sriov.Offset), nil
+		log.V(1).Info("Added interface routes if not existed")
+		return device, underlayRoute, nil, true, nil
 	}
+
+	log.V(1).Info("Adding interface route if not exists")
+	ips := getNetworkInterfaceIPs(nic)
+	if err := r.addInterfaceRoutesIfNotExist(ctx, log, vni, ips, *iface.Spec.UnderlayRoute); err != nil {
+		return nil, netip.Addr{}, nil, false, err
+	}
+	log.V(1).Info("Added interface route if not existed")
+	return device, *iface.Spec.UnderlayRoute, nil, false, nil
 }
 
 func (r *NetworkInterfaceReconciler) patchStatus(
@@ -1372,75 +2100,161 @@ func (r *NetworkInterfaceReconciler) delete(ctx context.Context, log logr.Logger
 		return ctrl.Result{}, nil
 	}
 
+	if controllerutil.ContainsFinalizer(nic, metalnetv1alpha1.AttachedFinalizer) {
+		log.V(1).Info("Network interface still attached, waiting for detachment before cleaning up")
+		if err := r.patchStatus(ctx, nic, func() {
+			setDetachPendingCondition(nic)
+		}); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error patching status: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
 	log.V(1).Info("Finalizer present, cleaning up")
 
+	log.V(1).Info("Recording tombstone")
+	if err := r.Tombstones.Record(networkInterfaceTombstoneKind, nic); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error recording tombstone: %w", err)
+	}
+
+	if err := r.cleanUpDataplane(ctx, log, nic); err != nil {
+		if isForceDeleted(nic) {
+			return ctrl.Result{}, r.forceRemoveFinalizer(ctx, log, nic, err)
+		}
+		if !errors.Is(err, errVirtualIPHandoverPending) {
+			return ctrl.Result{}, err
+		}
+
+		log.V(1).Info("Keeping virtual ip until handover completes")
+		if err := r.patchStatus(ctx, nic, func() {
+			setVirtualIPHandoverPendingCondition(nic)
+		}); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error patching status: %w", err)
+		}
+		return ctrl.Result{RequeueAfter: virtualIPHandoverRequeueInterval}, nil
+	}
+
+	if err := r.releaseIPs(ctx, log, nic); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.V(1).Info("Removing finalizer")
+	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, nic, networkInterfaceFinalizer); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
+	}
+	log.V(1).Info("Removed finalizer")
+	r.CapacityFeedback.Report(nic.UID, false)
+
+	if err := r.Tombstones.Remove(networkInterfaceTombstoneKind, nic.UID); err != nil {
+		log.Error(err, "Error removing tombstone, leaving it to be purged")
+	}
+	return ctrl.Result{}, nil
+}
+
+// forceRemoveFinalizer removes the finalizer of the NetworkInterface even though its dataplane state could not
+// be removed because of cleanupErr. Its tombstone is kept, so the removal is retried in the background.
+func (r *NetworkInterfaceReconciler) forceRemoveFinalizer(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, cleanupErr error) error {
+	log.Info("Force removing finalizer, retrying cleanup in the background", "Error", cleanupErr.Error())
+	if err := r.releaseIPs(ctx, log, nic); err != nil {
+		log.Error(err, "Error releasing ips of force deleted network interface")
+	}
+	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, nic, networkInterfaceFinalizer); err != nil {
+		return fmt.Errorf("error removing finalizer: %w", err)
+	}
+	r.Eventf(nic, corev1.EventTypeWarning, "ForceDeleted",
+		"Removed finalizer although the dataplane state could not be removed: %v", cleanupErr)
+	r.CapacityFeedback.Report(nic.UID, false)
+	return nil
+}
+
+// cleanUpDataplane removes the dpservice state, the device claim and the routes of the NetworkInterface. It
+// returns errVirtualIPHandoverPending while its virtual ip is kept for the NetworkInterface taking it over.
+func (r *NetworkInterfaceReconciler) cleanUpDataplane(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface) error {
 	log.V(1).Info("Getting dpdk interface")
 	dpdkIface, err := r.DPDK.GetInterface(ctx, string(nic.UID))
 	if err != nil {
 		if !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND) {
-			return ctrl.Result{}, fmt.Errorf("error getting dpdk interface: %w", err)
+			return fmt.Errorf("error getting dpdk interface: %w", err)
 		}
 
-		log.V(1).Info("Releasing device if existed")
+		log.V(1).Info("No dpdk interface, releasing device if existed")
 		if err := r.releaseNetFnIfClaimExists(nic.UID); err != nil {
-			return ctrl.Result{}, fmt.Errorf("error removing claim: %w", err)
+			return fmt.Errorf("error removing claim: %w", err)
 		}
 		log.V(1).Info("Released device if existed")
-
-		log.V(1).Info("No dpdk interface, removing finalizer")
-		if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, nic, networkInterfaceFinalizer); err != nil {
-			return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
-		}
-		log.V(1).Info("Removed finalizer")
-		return ctrl.Result{}, nil
+		return nil
 	}
 
 	vni := dpdkIface.Spec.VNI
 	underlayRoute := dpdkIface.Spec.UnderlayRoute
 	log.V(1).Info("Got dpdk interface", "VNI", vni, "UnderlayRoute", underlayRoute)
 
+	// The virtual ip goes first, so the interface stays fully functional while a handover is pending.
+	log.V(1).Info("Deleting virtual ip")
+	if err := r.deleteVirtualIP(ctx, log, nic); err != nil {
+		if errors.Is(err, errVirtualIPHandoverPending) {
+			return err
+		}
+		return fmt.Errorf("error deleting virtual ip: %w", err)
+	}
+	log.V(1).Info("Deleted virtual ip")
+
 	log.V(1).Info("Deleting prefixes")
 	if err := r.deletePrefixes(ctx, log, nic, vni); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting prefixes: %w", err)
+		return fmt.Errorf("error deleting prefixes: %w", err)
 	}
 	log.V(1).Info("Deleted prefixes")
 
 	log.V(1).Info("Deleting lb targets")
 	if err := r.deleteLBTargets(ctx, log, nic, vni); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting lb targets: %w", err)
+		return fmt.Errorf("error deleting lb targets: %w", err)
 	}
 	log.V(1).Info("Deleted lb targets")
 
 	log.V(1).Info("Deleting firewall rules")
 	if err := r.deleteFirewallRules(ctx, log, nic); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting firewall rules: %w", err)
+		return fmt.Errorf("error deleting firewall rules: %w", err)
 	}
 	log.V(1).Info("Deleted firewall rules")
 
 	log.V(1).Info("Deleting nat ip")
 	if err := r.deleteNATIP(ctx, log, nic, vni); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting nat ip: %w", err)
+		return fmt.Errorf("error deleting nat ip: %w", err)
 	}
 	log.V(1).Info("Deleted nat ip")
 
-	log.V(1).Info("Deleting virtual ip")
-	if err := r.deleteVirtualIP(ctx, log, nic); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting virtual ip: %w", err)
-	}
-	log.V(1).Info("Deleted virtual ip")
-
 	log.V(1).Info("Deleting interface")
 	if err := r.deleteInterface(ctx, log, nic, vni, *underlayRoute); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error deleting underlay route: %w", err)
+		return fmt.Errorf("error deleting underlay route: %w", err)
 	}
 	log.V(1).Info("Deleted interface")
 
-	log.V(1).Info("Removing finalizer")
-	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, nic, networkInterfaceFinalizer); err != nil {
-		return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
+	log.V(1).Info("Withdrawing announcements of replaced interfaces")
+	if _, _, err := r.withdrawReplacedInterfaces(ctx, log, nic, vni, *underlayRoute); err != nil {
+		return fmt.Errorf("error withdrawing announcements of replaced interface: %w", err)
 	}
-	log.V(1).Info("Removed finalizer")
-	return ctrl.Result{}, nil
+	log.V(1).Info("Withdrew announcements of replaced interfaces")
+	return nil
+}
+
+// TombstoneKind returns the TombstoneKind purging the dataplane state of NetworkInterfaces whose finalizer
+// was removed before their dataplane state.
+func (r *NetworkInterfaceReconciler) TombstoneKind() TombstoneKind {
+	return TombstoneKind{
+		Kind:      networkInterfaceTombstoneKind,
+		NewObject: func() client.Object { return &metalnetv1alpha1.NetworkInterface{} },
+		Finalizer: func(client.Object) string { return networkInterfaceFinalizer },
+		Purge:     r.purge,
+	}
+}
+
+func (r *NetworkInterfaceReconciler) purge(ctx context.Context, log logr.Logger, obj client.Object) error {
+	nic := obj.(*metalnetv1alpha1.NetworkInterface)
+	if err := r.cleanUpDataplane(ctx, log, nic); err != nil {
+		return err
+	}
+	r.CapacityFeedback.Report(nic.UID, false)
+	return nil
 }
 
 func (r *NetworkInterfaceReconciler) deleteLBTargets(
@@ -1476,6 +2290,12 @@ func (r *NetworkInterfaceReconciler) deleteLBTargets(
 				return err
 			}
 			log.V(1).Info("Removed dpdk lb target if existed")
+
+			event := newNetworkInterfaceEvent(eventbus.LoadBalancerTargetRemoved, nic)
+			event.VNI = vni
+			event.Prefix = &prefix
+			event.UnderlayRoute = prefixItem.Spec.UnderlayRoute
+			r.EventBus.Publish(event)
 			return nil
 		}(); err != nil {
 			errs = append(errs, fmt.Errorf("[lb target %s] %w", prefix, err))
@@ -1511,7 +2331,7 @@ func (r *NetworkInterfaceReconciler) deletePrefixes(
 		log := log.WithValues("Prefix", pfx)
 		if err := func() error {
 			log.V(1).Info("Removing prefix route if exists")
-			if err := r.removePrefixRouteIfExists(ctx, vni, pfx, *prefix.Spec.UnderlayRoute); err != nil {
+			if err := r.removePrefixRouteIfExists(ctx, vni, nic.UID, pfx); err != nil {
 				return err
 			}
 			log.V(1).Info("Removed prefix route if existed")
@@ -1595,6 +2415,12 @@ func (r *NetworkInterfaceReconciler) deleteInterface(
 		return err
 	}
 	log.V(1).Info("Released device if existed")
+
+	event := newNetworkInterfaceEvent(eventbus.InterfaceRemoved, nic)
+	event.VNI = vni
+	event.IPs = ips
+	event.UnderlayRoute = &underlayRoute
+	r.EventBus.Publish(event)
 	return nil
 }
 
@@ -1603,17 +2429,42 @@ func (r *NetworkInterfaceReconciler) SetupWithManager(mgr ctrl.Manager, metalnet
 	log := ctrl.Log.WithName("networkinterface").WithName("setup")
 	ctx := ctrl.LoggerInto(context.TODO(), log)
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&metalnetv1alpha1.NetworkInterface{}).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&metalnetv1alpha1.NetworkInterface{}, builder.WithPredicates(
+			ignoreTimelineUpdates(func(nic *metalnetv1alpha1.NetworkInterface) *metalnetv1alpha1.ReconcileTimeline {
+				return &nic.Status.ReconcileTimeline
+			}),
+		)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		WatchesRawSource(
 			source.Kind(metalnetCache, &metalnetv1alpha1.Network{}),
 			r.enqueueNetworkInterfacesReferencingNetwork(ctx, log),
 		).
+		WatchesRawSource(
+			source.Kind(metalnetCache, &metalnetv1alpha1.ClusterNetwork{}),
+			r.enqueueNetworkInterfacesReferencingClusterNetwork(ctx, log),
+		).
 		WatchesRawSource(
 			source.Kind(metalnetCache, &metalnetv1alpha1.LoadBalancer{}),
 			r.enqueueNetworkInterfacesReferencingLoadBalancer(ctx, log),
 		).
-		Complete(r)
+		WatchesRawSource(
+			source.Kind(metalnetCache, &metalnetv1alpha1.InternetGateway{}),
+			r.enqueueNetworkInterfacesReferencingInternetGateway(ctx, log),
+		)
+	if r.EndpointSliceTargets != nil {
+		b = b.WatchesRawSource(
+			&source.Channel{Source: r.EndpointSliceTargets.NetworkInterfaceEvents()},
+			&handler.EnqueueRequestForObject{},
+		)
+	}
+	if r.Resync != nil {
+		b = b.WatchesRawSource(
+			&source.Channel{Source: r.Resync.Events(&metalnetv1alpha1.NetworkInterfaceList{})},
+			&handler.EnqueueRequestForObject{},
+		)
+	}
+	return b.Complete(withTracing("NetworkInterface", r))
 }
 
 func (r *NetworkInterfaceReconciler) enqueueNetworkInterfacesReferencingNetwork(ctx context.Context, log logr.Logger) handler.EventHandler {
@@ -1636,6 +2487,25 @@ func (r *NetworkInterfaceReconciler) enqueueNetworkInterfacesReferencingNetwork(
 	})
 }
 
+func (r *NetworkInterfaceReconciler) enqueueNetworkInterfacesReferencingClusterNetwork(ctx context.Context, log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		network := obj.(*metalnetv1alpha1.ClusterNetwork)
+		nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+		if err := r.List(ctx, nicList,
+			client.MatchingFields{metalnetclient.NetworkInterfaceClusterNetworkRefNameField: network.Name},
+		); err != nil {
+			log.Error(err, "Error listing network interfaces referencing cluster network", "ClusterNetworkKey", client.ObjectKeyFromObject(network))
+			return nil
+		}
+
+		reqs := make([]ctrl.Request, len(nicList.Items))
+		for i, nic := range nicList.Items {
+			reqs[i] = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&nic)}
+		}
+		return reqs
+	})
+}
+
 func (r *NetworkInterfaceReconciler) enqueueNetworkInterfacesReferencingLoadBalancer(ctx context.Context, log logr.Logger) handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
 		loadBalancer := obj.(*metalnetv1alpha1.LoadBalancer)
@@ -1655,3 +2525,23 @@ func (r *NetworkInterfaceReconciler) enqueueNetworkInterfacesReferencingLoadBala
 		return reqs
 	})
 }
+
+func (r *NetworkInterfaceReconciler) enqueueNetworkInterfacesReferencingInternetGateway(ctx context.Context, log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		internetGateway := obj.(*metalnetv1alpha1.InternetGateway)
+		nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+		if err := r.List(ctx, nicList,
+			client.InNamespace(internetGateway.Namespace),
+			client.MatchingFields{metalnetclient.NetworkInterfaceInternetGatewayRefNameField: internetGateway.Name},
+		); err != nil {
+			log.Error(err, "Error listing network interfaces referencing internet gateway", "InternetGatewayKey", client.ObjectKeyFromObject(internetGateway))
+			return nil
+		}
+
+		reqs := make([]ctrl.Request, len(nicList.Items))
+		for i, nic := range nicList.Items {
+			reqs[i] = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&nic)}
+		}
+		return reqs
+	})
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("Network interface detachment", Label("network-interface"), func() {
+	It("should keep the dataplane of a deleted network interface until it is detached", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: "nic-uid"},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				NodeName:   ptr.To("node"),
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes := &routeTable{routes: make(map[string]struct{})}
+		r := &NetworkInterfaceReconciler{
+			Client:               c,
+			EventRecorder:        &record.FakeRecorder{},
+			DPDK:                 dpdkClient,
+			RouteUtil:            routes,
+			AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+			DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+			NodeName:             "node",
+			PublicVNI:            200,
+		}
+		reconcile := func() {
+			GinkgoHelper()
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				Expect(err).NotTo(HaveOccurred())
+				if !res.Requeue {
+					return
+				}
+			}
+		}
+
+		By("programming and attaching the network interface")
+		reconcile()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+		controllerutil.AddFinalizer(nic, metalnetv1alpha1.AttachedFinalizer)
+		Expect(c.Update(ctx, nic)).To(Succeed())
+
+		By("deleting the attached network interface")
+		Expect(c.Delete(ctx, nic)).To(Succeed())
+		reconcile()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceDetachPending)).To(BeTrue())
+		_, err = dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(routes.Routes()).NotTo(BeEmpty())
+
+		By("detaching the network interface")
+		controllerutil.RemoveFinalizer(nic, metalnetv1alpha1.AttachedFinalizer)
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		reconcile()
+		Expect(apierrors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(nic), nic))).To(BeTrue())
+		_, err = dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)).To(BeTrue())
+		Expect(routes.Routes()).To(BeEmpty())
+	})
+})
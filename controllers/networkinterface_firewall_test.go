@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+var _ = Describe("Network interface firewall rules", Label("firewall"), func() {
+	newRule := func(id types.UID, action metalnetv1alpha1.FirewallRuleAction) metalnetv1alpha1.FirewallRule {
+		return metalnetv1alpha1.FirewallRule{
+			FirewallRuleID: id,
+			Direction:      metalnetv1alpha1.FirewallRuleDirectionIngress,
+			Action:         action,
+		}
+	}
+
+	It("should merge the default rules of the network with the rules of the interface", func() {
+		network := &metalnetv1alpha1.Network{
+			Spec: metalnetv1alpha1.NetworkSpec{
+				DefaultFirewallRules: []metalnetv1alpha1.FirewallRule{
+					newRule("allow-ssh", metalnetv1alpha1.FirewallRuleActionAccept),
+					newRule("deny-all", metalnetv1alpha1.FirewallRuleActionDeny),
+				},
+			},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				FirewallRules: []metalnetv1alpha1.FirewallRule{
+					newRule("allow-http", metalnetv1alpha1.FirewallRuleActionAccept),
+					newRule("deny-all", metalnetv1alpha1.FirewallRuleActionAccept),
+				},
+			},
+		}
+
+		Expect(interfaceFirewallRules(nic, network)).To(Equal([]metalnetv1alpha1.FirewallRule{
+			newRule("allow-http", metalnetv1alpha1.FirewallRuleActionAccept),
+			newRule("deny-all", metalnetv1alpha1.FirewallRuleActionAccept),
+			newRule("allow-ssh", metalnetv1alpha1.FirewallRuleActionAccept),
+		}))
+		Expect(nic.Spec.FirewallRules).To(HaveLen(2))
+	})
+
+	It("should only use the rules of the interface if the network has no default rules", func() {
+		nic := &metalnetv1alpha1.NetworkInterface{
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				FirewallRules: []metalnetv1alpha1.FirewallRule{newRule("allow-http", metalnetv1alpha1.FirewallRuleActionAccept)},
+			},
+		}
+		Expect(interfaceFirewallRules(nic, &metalnetv1alpha1.Network{})).To(Equal(nic.Spec.FirewallRules))
+	})
+})
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Host interfaces", func() {
+	nic := &metalnetv1alpha1.NetworkInterface{Spec: metalnetv1alpha1.NetworkInterfaceSpec{HostInterface: true}}
+
+	It("should program host interfaces on the host port", func(ctx SpecContext) {
+		r := &NetworkInterfaceReconciler{HostInterfaceDevice: "host0"}
+
+		device, err := r.getOrClaimDevice(ctx, nic)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device.Name).To(Equal("host0"))
+		Expect(r.checkDeviceReady(logr.Discard(), nic, device)).To(Succeed())
+
+		device, err = r.getDevice(ctx, nic)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device.Name).To(Equal("host0"))
+	})
+
+	It("should not program host interfaces if they are not enabled on the node", func(ctx SpecContext) {
+		r := &NetworkInterfaceReconciler{}
+
+		_, err := r.getOrClaimDevice(ctx, nic)
+		Expect(err).To(MatchError(ContainSubstring("host interfaces are not enabled")))
+	})
+})
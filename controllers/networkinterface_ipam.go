@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/ipam"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ipAllocationRequeueInterval is the interval the allocation of the ips of a NetworkInterface is retried at
+// while there is no IPAM, no pool or no free ip for it.
+const ipAllocationRequeueInterval = 30 * time.Second
+
+func (r *NetworkInterfaceReconciler) ipam() ipam.IPAM {
+	if r.IPAM == nil {
+		return ipam.Static{}
+	}
+	return r.IPAM
+}
+
+// ipAllocationFailedReason returns the reason of the IPsAllocated condition for the given allocation error.
+func ipAllocationFailedReason(err error) string {
+	switch {
+	case errors.Is(err, ipam.ErrNoIPAM):
+		return metalnetv1alpha1.IPAllocatedReasonNoIPAM
+	case errors.Is(err, ipam.ErrNoMatchingPool):
+		return metalnetv1alpha1.IPAllocatedReasonNoMatchingPool
+	case errors.Is(err, ipam.ErrPoolExhausted):
+		return metalnetv1alpha1.IPAllocatedReasonPoolExhausted
+	default:
+		return metalnetv1alpha1.IPAllocatedReasonFailed
+	}
+}
+
+// allocateIPs allocates the ips of a NetworkInterface created without ips and stores them in its spec. The
+// update of the spec triggers the reconciliation programming the NetworkInterface.
+func (r *NetworkInterfaceReconciler) allocateIPs(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) (ctrl.Result, error) {
+	log.V(1).Info("Allocating ips", "IPFamilies", nic.Spec.IPFamilies)
+	ips, err := r.ipam().Allocate(ctx, nic, network)
+	if err != nil {
+		reason := ipAllocationFailedReason(err)
+		log.V(1).Info("Could not allocate ips", "Reason", reason, "Error", err.Error())
+		if cond := meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceIPsAllocated); cond == nil ||
+			cond.Status != metav1.ConditionFalse || cond.Reason != reason {
+			r.Eventf(nic, corev1.EventTypeWarning, "IPsNotAllocated", "Could not allocate ips: %v", err)
+		}
+		if err := r.patchStatus(ctx, nic, func() {
+			nic.Status.State = metalnetv1alpha1.NetworkInterfaceStatePending
+			meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+				Type:               metalnetv1alpha1.NetworkInterfaceIPsAllocated,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: nic.Generation,
+				Reason:             reason,
+				Message:            err.Error(),
+			})
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		if reason == metalnetv1alpha1.IPAllocatedReasonFailed {
+			return ctrl.Result{}, fmt.Errorf("error allocating ips: %w", err)
+		}
+		return ctrl.Result{RequeueAfter: ipAllocationRequeueInterval}, nil
+	}
+	log.V(1).Info("Allocated ips", "IPs", ips)
+
+	// The condition is set before the ips are stored, so the ips are released on deletion even if storing
+	// them fails.
+	if err := r.patchStatus(ctx, nic, func() {
+		meta.SetStatusCondition(&nic.Status.Conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.NetworkInterfaceIPsAllocated,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nic.Generation,
+			Reason:             metalnetv1alpha1.IPAllocatedReasonAllocated,
+		})
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	base := nic.DeepCopy()
+	nic.Spec.IPs = ips
+	if err := r.Patch(ctx, nic, client.MergeFrom(base)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error storing allocated ips: %w", err)
+	}
+	r.Eventf(nic, corev1.EventTypeNormal, "IPsAllocated", "Allocated ips %v", ips)
+	return ctrl.Result{}, nil
+}
+
+// releaseIPs releases the ips allocated to the NetworkInterface, if any.
+func (r *NetworkInterfaceReconciler) releaseIPs(ctx context.Context, log logr.Logger, nic *metalnetv1alpha1.NetworkInterface) error {
+	if !meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceIPsAllocated) {
+		return nil
+	}
+
+	networkKey := networkInterfaceNetworkKey(nic)
+	network, err := getNetwork(ctx, r.Client, networkKey)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error getting network %s: %w", networkKey, err)
+		}
+		network = &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: networkKey.Namespace, Name: networkKey.Name},
+		}
+	}
+
+	log.V(1).Info("Releasing ips", "IPs", nic.Spec.IPs)
+	if err := r.ipam().Release(ctx, nic, network); err != nil {
+		return fmt.Errorf("error releasing ips: %w", err)
+	}
+	log.V(1).Info("Released ips")
+	return nil
+}
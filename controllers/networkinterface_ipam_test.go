@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/ipam"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeIPAM struct {
+	ips      []metalnetv1alpha1.IP
+	err      error
+	released []string
+}
+
+func (f *fakeIPAM) Allocate(context.Context, *metalnetv1alpha1.NetworkInterface, *metalnetv1alpha1.Network) ([]metalnetv1alpha1.IP, error) {
+	return f.ips, f.err
+}
+
+func (f *fakeIPAM) Release(_ context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) error {
+	f.released = append(f.released, network.Name+"/"+nic.Name)
+	return nil
+}
+
+var _ = Describe("Network interface IPAM", Label("network-interface"), func() {
+	var (
+		c        client.Client
+		recorder *record.FakeRecorder
+		nic      *metalnetv1alpha1.NetworkInterface
+	)
+
+	newReconciler := func(nicIPAM ipam.IPAM) *NetworkInterfaceReconciler {
+		return &NetworkInterfaceReconciler{Client: c, EventRecorder: recorder, IPAM: nicIPAM}
+	}
+
+	BeforeEach(func() {
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic = &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       "nic",
+				Finalizers: []string{networkInterfaceFinalizer},
+			},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			Build()
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	It("should store the allocated ips and release them", func(ctx SpecContext) {
+		allocator := &fakeIPAM{ips: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")}}
+		r := newReconciler(allocator)
+
+		Expect(r.reconcile(ctx, ctrl.Log, nic)).To(Equal(ctrl.Result{}))
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Spec.IPs).To(Equal(allocator.ips))
+		Expect(meta.IsStatusConditionTrue(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceIPsAllocated)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("IPsAllocated")))
+
+		Expect(r.releaseIPs(ctx, ctrl.Log, nic)).To(Succeed())
+		Expect(allocator.released).To(Equal([]string{"net/nic"}))
+	})
+
+	It("should keep network interfaces without ips pending without ipam", func(ctx SpecContext) {
+		r := newReconciler(nil)
+
+		Expect(r.reconcile(ctx, ctrl.Log, nic)).To(Equal(ctrl.Result{RequeueAfter: ipAllocationRequeueInterval}))
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Spec.IPs).To(BeEmpty())
+		Expect(nic.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStatePending))
+		Expect(meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceIPsAllocated)).To(HaveField("Reason", metalnetv1alpha1.IPAllocatedReasonNoIPAM))
+		Expect(recorder.Events).To(Receive(ContainSubstring("IPsNotAllocated")))
+
+		By("not repeating the event")
+		Expect(r.reconcile(ctx, ctrl.Log, nic)).To(Equal(ctrl.Result{RequeueAfter: ipAllocationRequeueInterval}))
+		Expect(recorder.Events).NotTo(Receive())
+
+		By("not releasing ips that were never allocated")
+		allocator := &fakeIPAM{}
+		Expect(newReconciler(allocator).releaseIPs(ctx, ctrl.Log, nic)).To(Succeed())
+		Expect(allocator.released).To(BeEmpty())
+	})
+})
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/netfns"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// replaceDPDKInterface replaces the given dpservice interface by one with the primary ips of the network
+// interface in the given VNI. The replacement keeps the id and the device of the interface, so the device
+// stays claimed and attached, and its primary ips are announced before returning.
+//
+// dpservice removes the virtual ip, nat ip, alias prefixes and load balancer targets together with the
+// interface, they are programmed again with the underlay routes of the replacement by the following
+// reconcile steps. What was announced for the replaced interface is recorded in the status before it is
+// deleted and only withdrawn afterwards, so the addresses stay announced throughout the update, even if
+// metalnet restarts in between.
+func (r *NetworkInterfaceReconciler) replaceDPDKInterface(
+	ctx context.Context,
+	log logr.Logger,
+	nic *metalnetv1alpha1.NetworkInterface,
+	iface *dpdk.Interface,
+	device *netfns.Device,
+	vni uint32,
+) (netip.Addr, *metalnetv1alpha1.ReplacedInterface, error) {
+	replaced := metalnetv1alpha1.ReplacedInterface{
+		VNI:           int32(iface.Spec.VNI),
+		UnderlayRoute: metalnetv1alpha1.IP{Addr: *iface.Spec.UnderlayRoute},
+	}
+	for _, ip := range getDPDKInterfaceIPs(iface) {
+		replaced.IPs = append(replaced.IPs, metalnetv1alpha1.IP{Addr: ip})
+	}
+
+	log.V(1).Info("Getting state of replaced interface")
+	virtualIP, err := r.DPDK.GetVirtualIP(ctx, string(nic.UID))
+	switch {
+	case err == nil:
+		replaced.VirtualIP = &metalnetv1alpha1.ReplacedVirtualIP{
+			IP:            metalnetv1alpha1.IP{Addr: *virtualIP.Spec.IP},
+			UnderlayRoute: metalnetv1alpha1.IP{Addr: *virtualIP.Spec.UnderlayRoute},
+		}
+	case !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_VM, dpdkerrors.SNAT_NO_DATA):
+		return netip.Addr{}, nil, fmt.Errorf("error getting dpdk virtual ip: %w", err)
+	}
+	nat, err := r.DPDK.GetNat(ctx, string(nic.UID))
+	switch {
+	case err == nil:
+		replaced.NAT = &metalnetv1alpha1.ReplacedNAT{
+			IP:            metalnetv1alpha1.IP{Addr: *nat.Spec.NatIP},
+			Port:          int32(nat.Spec.MinPort),
+			EndPort:       int32(nat.Spec.MaxPort),
+			UnderlayRoute: metalnetv1alpha1.IP{Addr: *nat.Spec.UnderlayRoute},
+		}
+	case !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_VM, dpdkerrors.SNAT_NO_DATA):
+		return netip.Addr{}, nil, fmt.Errorf("error getting dpdk nat ip: %w", err)
+	}
+	lbTargets, err := r.DPDK.ListLoadBalancerPrefixes(ctx, string(nic.UID))
+	if err != nil {
+		return netip.Addr{}, nil, fmt.Errorf("error listing lb targets: %w", err)
+	}
+	for _, lbTarget := range lbTargets.Items {
+		replaced.LoadBalancerTargets = append(replaced.LoadBalancerTargets, metalnetv1alpha1.ReplacedLoadBalancerTarget{
+			Prefix:        metalnetv1alpha1.IPPrefix{Prefix: lbTarget.Spec.Prefix},
+			UnderlayRoute: metalnetv1alpha1.IP{Addr: *lbTarget.Spec.UnderlayRoute},
+		})
+	}
+	if iface.Spec.VNI != vni {
+		prefixes, err := r.DPDK.ListPrefixes(ctx, string(nic.UID))
+		if err != nil {
+			return netip.Addr{}, nil, fmt.Errorf("error listing prefixes: %w", err)
+		}
+		for _, prefix := range prefixes.Items {
+			replaced.Prefixes = append(replaced.Prefixes, metalnetv1alpha1.IPPrefix{Prefix: prefix.Spec.Prefix})
+		}
+	}
+
+	if err := r.checkDeviceReady(log, nic, device); err != nil {
+		return netip.Addr{}, nil, err
+	}
+
+	log.V(1).Info("Recording replaced interface")
+	if err := r.patchStatus(ctx, nic, func() {
+		nic.Status.ReplacedInterfaces = append(nic.Status.ReplacedInterfaces, replaced)
+	}); err != nil {
+		return netip.Addr{}, nil, fmt.Errorf("error recording replaced interface: %w", err)
+	}
+
+	log.V(1).Info("Deleting replaced dpdk interface")
+	if err := r.deleteDPDKInterfaceIfExists(ctx, nic.UID); err != nil {
+		return netip.Addr{}, nil, err
+	}
+
+	underlayRoute, err := r.createDPDKInterface(ctx, log, nic, vni, device)
+	if err != nil {
+		return netip.Addr{}, nil, err
+	}
+
+	log.V(1).Info("Adding interface routes if not exist")
+	if err := r.addInterfaceRoutesIfNotExist(ctx, log, vni, getNetworkInterfaceIPs(nic), underlayRoute); err != nil {
+		return netip.Addr{}, nil, err
+	}
+	log.V(1).Info("Added interface routes if not existed")
+
+	if iface.Spec.VNI != vni {
+		r.Eventf(nic, corev1.EventTypeNormal, "VNIMigrating", "Moved interface from VNI %d to VNI %d", iface.Spec.VNI, vni)
+	}
+	if previousIPs, ips := getDPDKInterfaceIPs(iface), getNetworkInterfaceIPs(nic); !slices.Equal(previousIPs, ips) {
+		r.Eventf(nic, corev1.EventTypeNormal, "PrimaryIPsUpdated", "Updated primary ips from %v to %v", previousIPs, ips)
+	}
+	return underlayRoute, &replaced, nil
+}
+
+// withdrawReplacedInterface withdraws the announcements of a replaced interface. Announcements sharing the
+// VNI and the underlay route of the replacement are kept.
+func (r *NetworkInterfaceReconciler) withdrawReplacedInterface(
+	ctx context.Context,
+	log logr.Logger,
+	nic *metalnetv1alpha1.NetworkInterface,
+	replaced metalnetv1alpha1.ReplacedInterface,
+	vni uint32,
+	underlayRoute netip.Addr,
+) error {
+	var errs []error
+	replacedVNI := uint32(replaced.VNI)
+	moved := replacedVNI != vni
+	if moved || replaced.UnderlayRoute.Addr != underlayRoute {
+		log.V(1).Info("Removing routes of replaced interface if exist")
+		var ips []netip.Addr
+		for _, ip := range replaced.IPs {
+			ips = append(ips, ip.Addr)
+		}
+		if err := r.removeInterfaceRoutesIfExist(ctx, log, replacedVNI, ips, replaced.UnderlayRoute.Addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if vip := replaced.VirtualIP; vip != nil && vip.UnderlayRoute.Addr != underlayRoute {
+		log.V(1).Info("Removing virtual ip route of replaced interface if exists")
+		if err := r.removeVirtualIPRouteIfExists(ctx, vip.IP.Addr, vip.UnderlayRoute.Addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if replacedNAT := replaced.NAT; replacedNAT != nil {
+		nat := &dpdk.Nat{Spec: dpdk.NatSpec{
+			NatIP:   &replacedNAT.IP.Addr,
+			MinPort: uint32(replacedNAT.Port),
+			MaxPort: uint32(replacedNAT.EndPort),
+		}}
+		switch {
+		case replacedNAT.UnderlayRoute.Addr != underlayRoute:
+			log.V(1).Info("Removing nat ip route of replaced interface if exists")
+			if err := r.removeNATIPRouteIfExists(ctx, nat, replacedNAT.UnderlayRoute.Addr, replacedVNI); err != nil {
+				errs = append(errs, err)
+			}
+		case moved:
+			// The announcement in the public VNI is shared with the replacement.
+			log.V(1).Info("Removing nat ip route of replaced interface from its vni if exists")
+			if err := r.removeNATIPVNIRouteIfExists(ctx, nat, replacedNAT.UnderlayRoute.Addr, replacedVNI); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, lbTarget := range replaced.LoadBalancerTargets {
+		if !moved && lbTarget.UnderlayRoute.Addr == underlayRoute {
+			continue
+		}
+		log.V(1).Info("Removing lb target route of replaced interface if exists", "LB Target", lbTarget.Prefix)
+		if err := r.removeLBTargetRouteIfExists(ctx, replacedVNI, lbTarget.Prefix.Prefix, lbTarget.UnderlayRoute.Addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, prefix := range replaced.Prefixes {
+		log.V(1).Info("Removing prefix route of replaced interface if exists", "Prefix", prefix)
+		if err := r.removePrefixRouteIfExists(ctx, replacedVNI, nic.UID, prefix.Prefix); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withdrawReplacedInterfaces withdraws the announcements of the replaced interfaces recorded in the status
+// and removes those withdrawn from it. Replaced interfaces whose withdrawal fails are kept, so the withdrawal
+// is retried by the next reconcile. It returns the VNI migrations completed and those still pending.
+func (r *NetworkInterfaceReconciler) withdrawReplacedInterfaces(
+	ctx context.Context,
+	log logr.Logger,
+	nic *metalnetv1alpha1.NetworkInterface,
+	vni uint32,
+	underlayRoute netip.Addr,
+) (migrated, pending []uint32, err error) {
+	var (
+		remaining []metalnetv1alpha1.ReplacedInterface
+		errs      []error
+	)
+	for _, replaced := range nic.Status.ReplacedInterfaces {
+		replacedVNI := uint32(replaced.VNI)
+		if err := r.withdrawReplacedInterface(ctx, log, nic, replaced, vni, underlayRoute); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, replaced)
+			if replacedVNI != vni && !slices.Contains(pending, replacedVNI) {
+				pending = append(pending, replacedVNI)
+			}
+			continue
+		}
+		if replacedVNI != vni && !slices.Contains(migrated, replacedVNI) {
+			migrated = append(migrated, replacedVNI)
+		}
+	}
+
+	if len(remaining) != len(nic.Status.ReplacedInterfaces) {
+		if err := r.patchStatus(ctx, nic, func() {
+			nic.Status.ReplacedInterfaces = remaining
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("error removing withdrawn replaced interfaces: %w", err))
+		}
+	}
+	return migrated, pending, errors.Join(errs...)
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"sync"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// routeTable keeps the announced routes as "<vni> <prefix> via <next hop>". Calling any other method panics.
+type routeTable struct {
+	metalbond.RouteUtil
+
+	mu     sync.Mutex
+	routes map[string]struct{}
+	// withdrawErr fails all withdrawals if set.
+	withdrawErr error
+}
+
+func routeKey(vni metalbond.VNI, destination metalbond.Destination, nextHop metalbond.NextHop) string {
+	return fmt.Sprintf("%d %s via %s", vni, destination.Prefix, nextHop.TargetAddress)
+}
+
+func (t *routeTable) AnnounceRoute(_ context.Context, vni metalbond.VNI, destination metalbond.Destination, nextHop metalbond.NextHop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes[routeKey(vni, destination, nextHop)] = struct{}{}
+	return nil
+}
+
+func (t *routeTable) WithdrawRoute(_ context.Context, vni metalbond.VNI, destination metalbond.Destination, nextHop metalbond.NextHop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.withdrawErr != nil {
+		return t.withdrawErr
+	}
+	delete(t.routes, routeKey(vni, destination, nextHop))
+	return nil
+}
+
+func (t *routeTable) setWithdrawError(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.withdrawErr = err
+}
+
+func (t *routeTable) Routes() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var routes []string
+	for route := range t.routes {
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+var _ = Describe("Network interface primary ip update", Label("network-interface"), func() {
+	It("should replace the dpservice interface on the same device and move the announcements", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: "nic-uid"},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				VirtualIP:  metalnetv1alpha1.MustParseNewIP("45.0.0.1"),
+				NodeName:   ptr.To("node"),
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4", "net_tap5"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes := &routeTable{routes: make(map[string]struct{})}
+		r := &NetworkInterfaceReconciler{
+			Client:               c,
+			EventRecorder:        &record.FakeRecorder{},
+			DPDK:                 dpdkClient,
+			RouteUtil:            routes,
+			AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+			DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+			NodeName:             "node",
+			PublicVNI:            200,
+		}
+		reconcile := func() {
+			GinkgoHelper()
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				Expect(err).NotTo(HaveOccurred())
+				if !res.Requeue {
+					return
+				}
+			}
+		}
+		underlayRoutes := func() (netip.Addr, netip.Addr) {
+			GinkgoHelper()
+			iface, err := dpdkClient.GetInterface(ctx, string(nic.UID))
+			Expect(err).NotTo(HaveOccurred())
+			vip, err := dpdkClient.GetVirtualIP(ctx, string(nic.UID))
+			Expect(err).NotTo(HaveOccurred())
+			return *iface.Spec.UnderlayRoute, *vip.Spec.UnderlayRoute
+		}
+
+		By("programming the network interface")
+		reconcile()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+		device := nic.Status.Device.Name
+		ifaceUnderlay, vipUnderlay := underlayRoutes()
+		Expect(routes.Routes()).To(ConsistOf(
+			fmt.Sprintf("100 10.0.0.1/32 via %s", ifaceUnderlay),
+			fmt.Sprintf("200 45.0.0.1/32 via %s", vipUnderlay),
+		))
+
+		By("changing the primary ip")
+		nic.Spec.IPs = []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.2")}
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		reconcile()
+
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+		Expect(nic.Status.Device.Name).To(Equal(device))
+
+		iface, err := dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*iface.Spec.IPv4).To(Equal(netip.MustParseAddr("10.0.0.2")))
+		Expect(iface.Spec.Device).To(Equal(device))
+
+		newIfaceUnderlay, newVIPUnderlay := underlayRoutes()
+		Expect(newIfaceUnderlay).NotTo(Equal(ifaceUnderlay))
+		Expect(routes.Routes()).To(ConsistOf(
+			fmt.Sprintf("100 10.0.0.2/32 via %s", newIfaceUnderlay),
+			fmt.Sprintf("200 45.0.0.1/32 via %s", newVIPUnderlay),
+		))
+	})
+})
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Network interface lb target draining", Label("network-interface"), func() {
+	It("should withdraw the lb target routes while the network interface is draining", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: types.UID("uid-nic")},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef:          corev1.LocalObjectReference{Name: "net"},
+				IPFamilies:          []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:                 []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				LoadBalancerTargets: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.0.100/32")},
+				NodeName:            ptr.To("node"),
+			},
+		}
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes := &natRouteTable{routes: make(map[string]struct{})}
+		r := &NetworkInterfaceReconciler{
+			Client:               c,
+			EventRecorder:        &record.FakeRecorder{},
+			DPDK:                 dpdkClient,
+			RouteUtil:            routes,
+			AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+			DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+			NodeName:             "node",
+		}
+		reconcile := func() {
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				Expect(err).NotTo(HaveOccurred())
+				if !res.Requeue {
+					break
+				}
+			}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		}
+		lbTargetRoutes := func() []string {
+			var res []string
+			for _, route := range routes.Routes() {
+				if route == "100 10.0.0.100/32 LOADBALANCER_TARGET 0-0" {
+					res = append(res, route)
+				}
+			}
+			return res
+		}
+
+		By("announcing the lb target route")
+		reconcile()
+		Expect(lbTargetRoutes()).To(HaveLen(1))
+
+		By("draining the network interface")
+		nic.Spec.LoadBalancerTargetPolicy = &metalnetv1alpha1.LoadBalancerTargetPolicy{Draining: true}
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		reconcile()
+		Expect(lbTargetRoutes()).To(BeEmpty())
+		Expect(nic.Status.LoadBalancerTargets).To(Equal(nic.Spec.LoadBalancerTargets))
+
+		By("ending the draining")
+		nic.Spec.LoadBalancerTargetPolicy = nil
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		reconcile()
+		Expect(lbTargetRoutes()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// natRouteTable records the announced routes including the hop type and NAT port range of their next hops.
+type natRouteTable struct {
+	metalbond.RouteUtil
+
+	mu     sync.Mutex
+	routes map[string]struct{}
+}
+
+func natRouteKey(vni metalbond.VNI, destination metalbond.Destination, nextHop metalbond.NextHop) string {
+	return fmt.Sprintf("%d %s %s %d-%d", vni, destination.Prefix, nextHop.TargetHopType, nextHop.TargetNATMinPort, nextHop.TargetNATMaxPort)
+}
+
+func (t *natRouteTable) AnnounceRoute(_ context.Context, vni metalbond.VNI, destination metalbond.Destination, nextHop metalbond.NextHop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes[natRouteKey(vni, destination, nextHop)] = struct{}{}
+	return nil
+}
+
+func (t *natRouteTable) WithdrawRoute(_ context.Context, vni metalbond.VNI, destination metalbond.Destination, nextHop metalbond.NextHop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.routes, natRouteKey(vni, destination, nextHop))
+	return nil
+}
+
+func (t *natRouteTable) Routes() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var routes []string
+	for route := range t.routes {
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+var _ = Describe("Network interface NAT return path", Label("network-interface"), func() {
+	It("should announce the port block of the nat ip into the public VNI and follow port block changes", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: types.UID("uid-nic")},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				NAT: &metalnetv1alpha1.NATDetails{
+					IP:      ptr.To(metalnetv1alpha1.MustParseIP("12.0.0.1")),
+					Port:    1024,
+					EndPort: 2047,
+				},
+				NodeName: ptr.To("node"),
+			},
+		}
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes := &natRouteTable{routes: make(map[string]struct{})}
+		r := &NetworkInterfaceReconciler{
+			Client:               c,
+			EventRecorder:        &record.FakeRecorder{},
+			DPDK:                 dpdkClient,
+			RouteUtil:            routes,
+			AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+			DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+			NodeName:             "node",
+			PublicVNI:            200,
+		}
+		reconcile := func() {
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				Expect(err).NotTo(HaveOccurred())
+				if !res.Requeue {
+					break
+				}
+			}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		}
+
+		By("announcing the port block of the nat ip")
+		reconcile()
+		Expect(routes.Routes()).To(ContainElements(
+			"200 12.0.0.1/32 NAT 1024-2047",
+			"100 12.0.0.1/32 NAT 1024-2047",
+		))
+		Expect(nic.Status.NATPortBlocks).To(ConsistOf(
+			metalnetv1alpha1.NATPortBlock{VNI: 200, IP: metalnetv1alpha1.MustParseIP("12.0.0.1"), Port: 1024, EndPort: 2047},
+			metalnetv1alpha1.NATPortBlock{VNI: 100, IP: metalnetv1alpha1.MustParseIP("12.0.0.1"), Port: 1024, EndPort: 2047},
+		))
+
+		By("changing the port block of the nat ip")
+		nic.Spec.NAT.Port = 2048
+		nic.Spec.NAT.EndPort = 3071
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		reconcile()
+		Expect(routes.Routes()).To(ContainElements(
+			"200 12.0.0.1/32 NAT 2048-3071",
+			"100 12.0.0.1/32 NAT 2048-3071",
+		))
+		Expect(routes.Routes()).NotTo(ContainElement(ContainSubstring("1024-2047")))
+		Expect(nic.Status.NATPortBlocks).To(ConsistOf(
+			metalnetv1alpha1.NATPortBlock{VNI: 200, IP: metalnetv1alpha1.MustParseIP("12.0.0.1"), Port: 2048, EndPort: 3071},
+			metalnetv1alpha1.NATPortBlock{VNI: 100, IP: metalnetv1alpha1.MustParseIP("12.0.0.1"), Port: 2048, EndPort: 3071},
+		))
+
+		By("removing the nat ip")
+		nic.Spec.NAT = nil
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		reconcile()
+		Expect(routes.Routes()).NotTo(ContainElement(ContainSubstring("12.0.0.1")))
+		Expect(nic.Status.NATPortBlocks).To(BeEmpty())
+	})
+})
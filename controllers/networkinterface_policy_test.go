@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"path/filepath"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Network interface announcement policy", Label("network-interface"), func() {
+	It("should report prefixes denied by the announcement policy without retrying", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: types.UID("uid-nic")},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				Prefixes:   []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("0.0.0.0/0")},
+				NodeName:   ptr.To("node"),
+			},
+		}
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes := metalbond.NewPolicyRouteUtil(&natRouteTable{routes: make(map[string]struct{})}, &metalbond.AnnouncementPolicy{
+			Default: &metalbond.PrefixFilter{
+				Deny: []metalbond.PrefixListEntry{{Prefix: netip.MustParsePrefix("0.0.0.0/0")}},
+			},
+		})
+		r := &NetworkInterfaceReconciler{
+			Client:               c,
+			EventRecorder:        &record.FakeRecorder{},
+			DPDK:                 dpdkClient,
+			RouteUtil:            routes,
+			AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+			DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+			NodeName:             "node",
+		}
+		reconcileNIC := func() error {
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				if err != nil || !res.Requeue {
+					Expect(res).To(Equal(ctrl.Result{}))
+					Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+					return err
+				}
+			}
+		}
+
+		By("denying the default route prefix")
+		err = reconcileNIC()
+		Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		Expect(meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.AnnouncementDenied)).To(HaveField("Reason", metalnetv1alpha1.AnnouncementReasonDeniedByPolicy))
+
+		By("removing the denied prefix")
+		nic.Spec.Prefixes = nil
+		Expect(c.Update(ctx, nic)).To(Succeed())
+		Expect(reconcileNIC()).To(Succeed())
+		Expect(meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.AnnouncementDenied)).To(BeNil())
+	})
+})
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var _ = Describe("Prefix reconciliation order", Label("networkinterface"), func() {
+	It("should add new prefixes before removing replaced ones", func() {
+		current := sets.New(
+			netip.MustParsePrefix("10.0.0.0/24"),
+			netip.MustParsePrefix("10.0.2.0/24"),
+			netip.MustParsePrefix("10.0.4.0/24"),
+		)
+		desired := sets.New(
+			netip.MustParsePrefix("10.0.3.0/24"),
+			netip.MustParsePrefix("10.0.0.0/25"),
+			netip.MustParsePrefix("10.0.2.0/24"),
+		)
+
+		Expect(orderPrefixChanges(current, desired)).To(Equal([]netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/25"),
+			netip.MustParsePrefix("10.0.3.0/24"),
+			netip.MustParsePrefix("10.0.2.0/24"),
+			netip.MustParsePrefix("10.0.0.0/24"),
+			netip.MustParsePrefix("10.0.4.0/24"),
+		}))
+	})
+})
@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Network interface VNI migration", Label("network-interface"), func() {
+	It("should move the dpservice interface to the new VNI and withdraw the announcements of the old one", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: "nic-uid"},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				VirtualIP:  metalnetv1alpha1.MustParseNewIP("45.0.0.1"),
+				Prefixes:   []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.1.0/24")},
+				NodeName:   ptr.To("node"),
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4", "net_tap5"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes := &routeTable{routes: make(map[string]struct{})}
+		r := &NetworkInterfaceReconciler{
+			Client:               c,
+			EventRecorder:        &record.FakeRecorder{},
+			DPDK:                 dpdkClient,
+			RouteUtil:            routes,
+			AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+			DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+			NodeName:             "node",
+			PublicVNI:            200,
+		}
+		reconcile := func() {
+			GinkgoHelper()
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				Expect(err).NotTo(HaveOccurred())
+				if !res.Requeue {
+					return
+				}
+			}
+		}
+
+		By("programming the network interface")
+		reconcile()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+		Expect(meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVNIMigration)).To(BeNil())
+		device := nic.Status.Device.Name
+
+		By("changing the VNI of the network")
+		network.Spec.ID = 101
+		Expect(c.Update(ctx, network)).To(Succeed())
+		reconcile()
+
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		Expect(nic.Status.State).To(Equal(metalnetv1alpha1.NetworkInterfaceStateReady))
+		Expect(nic.Status.Device.Name).To(Equal(device))
+		cond := meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVNIMigration)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(metalnetv1alpha1.VNIMigrationReasonMigrated))
+		Expect(cond.Message).To(Equal("Migrated from VNI 100 to VNI 101"))
+
+		iface, err := dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(BeEquivalentTo(101))
+		Expect(iface.Spec.Device).To(Equal(device))
+		vip, err := dpdkClient.GetVirtualIP(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		prefixes, err := dpdkClient.ListPrefixes(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prefixes.Items).To(HaveLen(1))
+		Expect(routes.Routes()).To(ConsistOf(
+			fmt.Sprintf("101 10.0.0.1/32 via %s", *iface.Spec.UnderlayRoute),
+			fmt.Sprintf("101 10.0.1.0/24 via %s", *prefixes.Items[0].Spec.UnderlayRoute),
+			fmt.Sprintf("200 45.0.0.1/32 via %s", *vip.Spec.UnderlayRoute),
+		))
+	})
+
+	It("should withdraw the announcements of all replaced interfaces after a restart", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: "nic-uid"},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				VirtualIP:  metalnetv1alpha1.MustParseNewIP("45.0.0.1"),
+				NodeName:   ptr.To("node"),
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).
+			WithObjects(network, nic).
+			WithIndex(&metalnetv1alpha1.NetworkInterface{}, metalnetclient.NetworkInterfaceNetworkRefNameField, func(obj client.Object) []string {
+				return []string{obj.(*metalnetv1alpha1.NetworkInterface).Spec.NetworkRef.Name}
+			}).
+			Build()
+
+		claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "claims"), true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap4"})
+		Expect(err).NotTo(HaveOccurred())
+		netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+		deviceAllocator := netfns.NewNetdevAllocator(netFnsManager)
+
+		routes := &routeTable{routes: make(map[string]struct{})}
+		// newReconciler returns a reconciler without any in-memory state, like after a restart.
+		newReconciler := func() *NetworkInterfaceReconciler {
+			return &NetworkInterfaceReconciler{
+				Client:               c,
+				EventRecorder:        &record.FakeRecorder{},
+				DPDK:                 dpdkClient,
+				RouteUtil:            routes,
+				AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routes),
+				DeviceAllocator:      deviceAllocator,
+				NodeName:             "node",
+				PublicVNI:            200,
+			}
+		}
+		r := newReconciler()
+		reconcile := func() error {
+			GinkgoHelper()
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nic)})
+				if err != nil || !res.Requeue {
+					Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+					return err
+				}
+			}
+		}
+		changeVNI := func(vni int32) {
+			GinkgoHelper()
+			network.Spec.ID = vni
+			Expect(c.Update(ctx, network)).To(Succeed())
+		}
+
+		By("programming the network interface")
+		Expect(reconcile()).To(Succeed())
+
+		By("changing the VNI twice while the announcements cannot be withdrawn")
+		routes.setWithdrawError(errors.New("metalbond unavailable"))
+		changeVNI(101)
+		Expect(reconcile()).NotTo(Succeed())
+		changeVNI(102)
+		Expect(reconcile()).NotTo(Succeed())
+		Expect(nic.Status.ReplacedInterfaces).To(ConsistOf(
+			HaveField("VNI", int32(100)),
+			HaveField("VNI", int32(101)),
+		))
+
+		By("restarting")
+		routes.setWithdrawError(nil)
+		r = newReconciler()
+		Expect(reconcile()).To(Succeed())
+		Expect(nic.Status.ReplacedInterfaces).To(BeEmpty())
+
+		iface, err := dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(BeEquivalentTo(102))
+		vip, err := dpdkClient.GetVirtualIP(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(routes.Routes()).To(ConsistOf(
+			fmt.Sprintf("102 10.0.0.1/32 via %s", *iface.Spec.UnderlayRoute),
+			fmt.Sprintf("200 45.0.0.1/32 via %s", *vip.Spec.UnderlayRoute),
+		))
+	})
+})
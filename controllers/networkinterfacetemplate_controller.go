@@ -0,0 +1,368 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NetworkInterfaceTemplateReconciler reconciles a NetworkInterfaceTemplate object.
+//
+// It stamps out the NetworkInterfaces of the instances of the template on its node. The ips of instances
+// without ips are allocated from the ip pool of the template and recorded in its status before the
+// NetworkInterfaces are created, so the reconcilers of other nodes do not hand out the same ips.
+type NetworkInterfaceTemplateReconciler struct {
+	client.Client
+	record.EventRecorder
+	Scheme *runtime.Scheme
+
+	NodeName string
+}
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfacetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfacetemplates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *NetworkInterfaceTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	template := &metalnetv1alpha1.NetworkInterfaceTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, template); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !template.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Network interface template is being deleted, its network interfaces are garbage collected")
+		return ctrl.Result{}, nil
+	}
+	return r.reconcile(ctx, log, template)
+}
+
+func (r *NetworkInterfaceTemplateReconciler) reconcile(ctx context.Context, log logr.Logger, template *metalnetv1alpha1.NetworkInterfaceTemplate) (ctrl.Result, error) {
+	log.V(1).Info("Listing network interfaces of template")
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := r.List(ctx, nicList,
+		client.InNamespace(template.Namespace),
+		client.MatchingLabels{metalnetv1alpha1.NetworkInterfaceTemplateLabel: template.Name},
+	); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error listing network interfaces of template: %w", err)
+	}
+
+	if err := r.deleteStaleNetworkInterfaces(ctx, log, template, nicList.Items); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	allocations, exhausted := allocateNetworkInterfaceTemplateIPs(template, nicList.Items, r.NodeName)
+	if !equality.Semantic.DeepEqual(template.Status.Allocations, allocations) {
+		log.V(1).Info("Recording ip allocations", "Allocations", len(allocations))
+		base := template.DeepCopy()
+		template.Status.Allocations = allocations
+		// The controller runs on every node, so concurrent allocations must not overwrite each other.
+		if err := r.Status().Patch(ctx, template, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			if apierrors.IsConflict(err) {
+				log.V(1).Info("Network interface template was modified concurrently, requeueing")
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("error recording ip allocations: %w", err)
+		}
+		log.V(1).Info("Recorded ip allocations")
+	}
+
+	for _, instance := range template.Spec.Instances {
+		if instance.NodeName != r.NodeName {
+			continue
+		}
+		if exhausted[instance.Name] {
+			log.V(1).Info("Could not allocate ips of instance", "Instance", instance.Name)
+			r.Eventf(template, corev1.EventTypeWarning, "IPsNotAllocated", "Could not allocate the ips of instance %s from the ip pool", instance.Name)
+			continue
+		}
+		if err := r.applyInstance(ctx, log, template, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// deleteStaleNetworkInterfaces deletes the NetworkInterfaces of the template on the node whose instance was removed
+// or moved to another node.
+func (r *NetworkInterfaceTemplateReconciler) deleteStaleNetworkInterfaces(
+	ctx context.Context,
+	log logr.Logger,
+	template *metalnetv1alpha1.NetworkInterfaceTemplate,
+	nics []metalnetv1alpha1.NetworkInterface,
+) error {
+	desired := make(map[string]string, len(template.Spec.Instances))
+	for _, instance := range template.Spec.Instances {
+		desired[networkInterfaceTemplateInstanceName(template, instance.Name)] = instance.NodeName
+	}
+
+	for i := range nics {
+		nic := &nics[i]
+		if !metav1.IsControlledBy(nic, template) || nic.Spec.NodeName == nil || *nic.Spec.NodeName != r.NodeName {
+			continue
+		}
+		if nodeName, ok := desired[nic.Name]; ok && nodeName == r.NodeName {
+			continue
+		}
+		if !nic.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		log.V(1).Info("Deleting network interface of removed instance", "NetworkInterfaceKey", client.ObjectKeyFromObject(nic))
+		if err := r.Delete(ctx, nic); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("error deleting network interface %s: %w", nic.Name, err)
+		}
+		log.V(1).Info("Deleted network interface of removed instance", "NetworkInterfaceKey", client.ObjectKeyFromObject(nic))
+	}
+	return nil
+}
+
+// applyInstance creates or updates the NetworkInterface of the instance.
+func (r *NetworkInterfaceTemplateReconciler) applyInstance(
+	ctx context.Context,
+	log logr.Logger,
+	template *metalnetv1alpha1.NetworkInterfaceTemplate,
+	instance metalnetv1alpha1.NetworkInterfaceTemplateInstance,
+) error {
+	ips := instance.IPs
+	if len(ips) == 0 {
+		ips = networkInterfaceTemplateAllocation(template, instance.Name).IPs
+	}
+
+	nic := &metalnetv1alpha1.NetworkInterface{}
+	key := client.ObjectKey{Namespace: template.Namespace, Name: networkInterfaceTemplateInstanceName(template, instance.Name)}
+	log = log.WithValues("Instance", instance.Name, "NetworkInterfaceKey", key)
+	if err := r.Get(ctx, key, nic); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error getting network interface %s: %w", key.Name, err)
+		}
+
+		nic = &metalnetv1alpha1.NetworkInterface{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		applyNetworkInterfaceTemplate(nic, template, instance, ips)
+		if err := controllerutil.SetControllerReference(template, nic, r.Scheme); err != nil {
+			return fmt.Errorf("error setting controller reference: %w", err)
+		}
+		log.V(1).Info("Creating network interface")
+		if err := r.Create(ctx, nic); err != nil {
+			return fmt.Errorf("error creating network interface %s: %w", key.Name, err)
+		}
+		log.V(1).Info("Created network interface")
+		return nil
+	}
+
+	if !metav1.IsControlledBy(nic, template) {
+		log.V(1).Info("Network interface is not controlled by template")
+		r.Eventf(template, corev1.EventTypeWarning, "NetworkInterfaceExists", "Network interface %s of instance %s is not controlled by the template", key.Name, instance.Name)
+		return nil
+	}
+	if nic.Spec.NodeName != nil && *nic.Spec.NodeName != r.NodeName {
+		// The node of the interface deletes it, which requeues the template.
+		log.V(1).Info("Network interface of instance is still on another node", "NodeName", *nic.Spec.NodeName)
+		return nil
+	}
+	if !nic.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Network interface is being deleted")
+		return nil
+	}
+
+	base := nic.DeepCopy()
+	applyNetworkInterfaceTemplate(nic, template, instance, ips)
+	if equality.Semantic.DeepEqual(base, nic) {
+		log.V(1).Info("Network interface is up-to-date")
+		return nil
+	}
+	log.V(1).Info("Patching network interface")
+	if err := r.Patch(ctx, nic, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("error patching network interface %s: %w", key.Name, err)
+	}
+	log.V(1).Info("Patched network interface")
+	return nil
+}
+
+// applyNetworkInterfaceTemplate sets the labels, annotations and spec fields of the template and instance on the
+// network interface. Other labels, annotations and spec fields are kept.
+func applyNetworkInterfaceTemplate(
+	nic *metalnetv1alpha1.NetworkInterface,
+	template *metalnetv1alpha1.NetworkInterfaceTemplate,
+	instance metalnetv1alpha1.NetworkInterfaceTemplateInstance,
+	ips []metalnetv1alpha1.IP,
+) {
+	labels := nic.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for k, v := range template.Spec.Template.Labels {
+		labels[k] = v
+	}
+	labels[metalnetv1alpha1.NetworkInterfaceTemplateLabel] = template.Name
+	nic.SetLabels(labels)
+	for k, v := range template.Spec.Template.Annotations {
+		metav1.SetMetaDataAnnotation(&nic.ObjectMeta, k, v)
+	}
+
+	spec := template.Spec.Template.Spec.DeepCopy()
+	nic.Spec.NetworkRef = spec.NetworkRef
+	nic.Spec.IPFamilies = spec.IPFamilies
+	nic.Spec.IPs = append([]metalnetv1alpha1.IP(nil), ips...)
+	nic.Spec.NodeName = &instance.NodeName
+	nic.Spec.LoadBalancerTargets = spec.LoadBalancerTargets
+	nic.Spec.LoadBalancerTargetPolicy = spec.LoadBalancerTargetPolicy
+	nic.Spec.InternetGatewayRef = spec.InternetGatewayRef
+	nic.Spec.FirewallRules = spec.FirewallRules
+	nic.Spec.MeteringRate = spec.MeteringRate
+}
+
+// allocateNetworkInterfaceTemplateIPs returns the ip allocations of the template with an ip per ip family for
+// every instance on the given node without ips, and the instances of the node the ip pool is exhausted for.
+//
+// Existing allocations are kept stable. An allocation is only released once its instance got ips or was removed
+// and its network interface is gone, so its ips are not handed out while still in use.
+func allocateNetworkInterfaceTemplateIPs(
+	template *metalnetv1alpha1.NetworkInterfaceTemplate,
+	nics []metalnetv1alpha1.NetworkInterface,
+	nodeName string,
+) ([]metalnetv1alpha1.NetworkInterfaceTemplateAllocation, map[string]bool) {
+	instances := make(map[string]metalnetv1alpha1.NetworkInterfaceTemplateInstance, len(template.Spec.Instances))
+	used := make(map[metalnetv1alpha1.IP]bool)
+	for _, instance := range template.Spec.Instances {
+		instances[instance.Name] = instance
+		for _, ip := range instance.IPs {
+			used[ip] = true
+		}
+	}
+	existingNICs := make(map[string]bool, len(nics))
+	for _, nic := range nics {
+		existingNICs[nic.Name] = true
+	}
+
+	var allocations []metalnetv1alpha1.NetworkInterfaceTemplateAllocation
+	for _, allocation := range template.Status.Allocations {
+		instance, ok := instances[allocation.Instance]
+		if (!ok || len(instance.IPs) > 0) && !existingNICs[networkInterfaceTemplateInstanceName(template, allocation.Instance)] {
+			continue
+		}
+		allocations = append(allocations, *allocation.DeepCopy())
+		for _, ip := range allocation.IPs {
+			used[ip] = true
+		}
+	}
+
+	exhausted := make(map[string]bool)
+	for _, instance := range template.Spec.Instances {
+		if instance.NodeName != nodeName || len(instance.IPs) > 0 {
+			continue
+		}
+
+		idx := -1
+		for i := range allocations {
+			if allocations[i].Instance == instance.Name {
+				idx = i
+				break
+			}
+		}
+		var current []metalnetv1alpha1.IP
+		if idx >= 0 {
+			current = allocations[idx].IPs
+		}
+
+		var ips []metalnetv1alpha1.IP
+		for _, family := range template.Spec.Template.Spec.IPFamilies {
+			ip, ok := networkInterfaceTemplateIPOfFamily(template, current, family)
+			if !ok {
+				ip, ok = nextFreeNetworkInterfaceTemplateIP(template, family, used)
+			}
+			if !ok {
+				exhausted[instance.Name] = true
+				break
+			}
+			used[ip] = true
+			ips = append(ips, ip)
+		}
+		if exhausted[instance.Name] {
+			continue
+		}
+
+		if idx >= 0 {
+			allocations[idx].IPs = ips
+		} else {
+			allocations = append(allocations, metalnetv1alpha1.NetworkInterfaceTemplateAllocation{Instance: instance.Name, IPs: ips})
+		}
+	}
+
+	sort.Slice(allocations, func(i, j int) bool { return allocations[i].Instance < allocations[j].Instance })
+	return allocations, exhausted
+}
+
+// networkInterfaceTemplateIPOfFamily returns the ip of the given family of the ips that is still in the ip pool.
+func networkInterfaceTemplateIPOfFamily(template *metalnetv1alpha1.NetworkInterfaceTemplate, ips []metalnetv1alpha1.IP, family corev1.IPFamily) (metalnetv1alpha1.IP, bool) {
+	if template.Spec.IPPool == nil {
+		return metalnetv1alpha1.IP{}, false
+	}
+	for _, ip := range ips {
+		if ip.Family() != family {
+			continue
+		}
+		for _, cidr := range template.Spec.IPPool.CIDRs {
+			if cidr.Contains(ip.Addr) {
+				return ip, true
+			}
+		}
+	}
+	return metalnetv1alpha1.IP{}, false
+}
+
+// nextFreeNetworkInterfaceTemplateIP returns the first ip of the given family of the ip pool that is not used.
+func nextFreeNetworkInterfaceTemplateIP(template *metalnetv1alpha1.NetworkInterfaceTemplate, family corev1.IPFamily, used map[metalnetv1alpha1.IP]bool) (metalnetv1alpha1.IP, bool) {
+	if template.Spec.IPPool == nil {
+		return metalnetv1alpha1.IP{}, false
+	}
+	for _, cidr := range template.Spec.IPPool.CIDRs {
+		if cidr.IP().Family() != family {
+			continue
+		}
+		for addr := cidr.Masked().Addr(); cidr.Contains(addr); addr = addr.Next() {
+			ip := metalnetv1alpha1.IP{Addr: addr}
+			if !used[ip] {
+				return ip, true
+			}
+		}
+	}
+	return metalnetv1alpha1.IP{}, false
+}
+
+func networkInterfaceTemplateAllocation(template *metalnetv1alpha1.NetworkInterfaceTemplate, instance string) metalnetv1alpha1.NetworkInterfaceTemplateAllocation {
+	for _, allocation := range template.Status.Allocations {
+		if allocation.Instance == instance {
+			return allocation
+		}
+	}
+	return metalnetv1alpha1.NetworkInterfaceTemplateAllocation{}
+}
+
+func networkInterfaceTemplateInstanceName(template *metalnetv1alpha1.NetworkInterfaceTemplate, instance string) string {
+	return template.Name + "-" + instance
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NetworkInterfaceTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metalnetv1alpha1.NetworkInterfaceTemplate{}).
+		Owns(&metalnetv1alpha1.NetworkInterface{}).
+		Complete(withTracing("NetworkInterfaceTemplate", r))
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+var _ = Describe("NetworkInterfaceTemplate", Label("networkinterface"), func() {
+	var template *metalnetv1alpha1.NetworkInterfaceTemplate
+
+	BeforeEach(func() {
+		template = &metalnetv1alpha1.NetworkInterfaceTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "workers"},
+			Spec: metalnetv1alpha1.NetworkInterfaceTemplateSpec{
+				Template: metalnetv1alpha1.NetworkInterfaceTemplateObject{
+					Labels: map[string]string{"role": "worker"},
+					Spec: metalnetv1alpha1.NetworkInterfaceTemplateInterfaceSpec{
+						NetworkRef: corev1.LocalObjectReference{Name: "network"},
+						IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+					},
+				},
+				Instances: []metalnetv1alpha1.NetworkInterfaceTemplateInstance{
+					{Name: "a", NodeName: "node-1"},
+					{Name: "b", NodeName: "node-2"},
+					{Name: "c", NodeName: "node-1", IPs: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.0")}},
+				},
+				IPPool: &metalnetv1alpha1.NetworkInterfaceTemplateIPPool{
+					CIDRs: []metalnetv1alpha1.IPPrefix{
+						metalnetv1alpha1.MustParseIPPrefix("10.0.0.0/30"),
+						metalnetv1alpha1.MustParseIPPrefix("fd00::/127"),
+					},
+				},
+			},
+		}
+	})
+
+	It("should allocate an ip per family to the instances of the node without ips", func() {
+		template.Status.Allocations = []metalnetv1alpha1.NetworkInterfaceTemplateAllocation{
+			{Instance: "b", IPs: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1"), metalnetv1alpha1.MustParseIP("fd00::")}},
+		}
+
+		allocations, exhausted := allocateNetworkInterfaceTemplateIPs(template, nil, "node-1")
+		Expect(exhausted).To(BeEmpty())
+		Expect(allocations).To(Equal([]metalnetv1alpha1.NetworkInterfaceTemplateAllocation{
+			{Instance: "a", IPs: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.2"), metalnetv1alpha1.MustParseIP("fd00::1")}},
+			{Instance: "b", IPs: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1"), metalnetv1alpha1.MustParseIP("fd00::")}},
+		}))
+
+		By("keeping the allocations stable")
+		template.Status.Allocations = allocations
+		stable, _ := allocateNetworkInterfaceTemplateIPs(template, nil, "node-1")
+		Expect(stable).To(Equal(allocations))
+	})
+
+	It("should report the instances the pool is exhausted for", func() {
+		template.Status.Allocations = []metalnetv1alpha1.NetworkInterfaceTemplateAllocation{
+			{Instance: "b", IPs: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1"), metalnetv1alpha1.MustParseIP("fd00::")}},
+		}
+		template.Spec.IPPool.CIDRs[1] = metalnetv1alpha1.MustParseIPPrefix("fd00::/128")
+
+		allocations, exhausted := allocateNetworkInterfaceTemplateIPs(template, nil, "node-1")
+		Expect(exhausted).To(Equal(map[string]bool{"a": true}))
+		Expect(allocations).To(Equal(template.Status.Allocations))
+	})
+
+	It("should release allocations of removed instances once their network interface is gone", func() {
+		template.Status.Allocations = []metalnetv1alpha1.NetworkInterfaceTemplateAllocation{
+			{Instance: "b", IPs: []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1"), metalnetv1alpha1.MustParseIP("fd00::")}},
+		}
+		template.Spec.Instances = template.Spec.Instances[:1]
+		nic := metalnetv1alpha1.NetworkInterface{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "workers-b"}}
+
+		allocations, _ := allocateNetworkInterfaceTemplateIPs(template, []metalnetv1alpha1.NetworkInterface{nic}, "node-1")
+		Expect(allocations).To(HaveLen(2))
+		Expect(allocations[0].IPs).To(Equal([]metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.0"), metalnetv1alpha1.MustParseIP("fd00::1")}))
+
+		allocations, _ = allocateNetworkInterfaceTemplateIPs(template, nil, "node-1")
+		Expect(allocations).To(HaveLen(1))
+		Expect(allocations[0].Instance).To(Equal("a"))
+	})
+
+	It("should apply the template to the network interface", func() {
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"other": "label"}},
+			Spec:       metalnetv1alpha1.NetworkInterfaceSpec{VirtualIP: metalnetv1alpha1.MustParseNewIP("45.0.0.1")},
+		}
+		ips := []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")}
+		applyNetworkInterfaceTemplate(nic, template, template.Spec.Instances[0], ips)
+
+		Expect(nic.Labels).To(Equal(map[string]string{
+			"other": "label",
+			"role":  "worker",
+			metalnetv1alpha1.NetworkInterfaceTemplateLabel: "workers",
+		}))
+		Expect(nic.Spec.NetworkRef.Name).To(Equal("network"))
+		Expect(nic.Spec.IPs).To(Equal(ips))
+		Expect(nic.Spec.NodeName).To(Equal(ptr.To("node-1")))
+		Expect(nic.Spec.VirtualIP).To(Equal(metalnetv1alpha1.MustParseNewIP("45.0.0.1")))
+	})
+})
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// objectMetricsCollectTimeout is the timeout of collecting the object metrics.
+const objectMetricsCollectTimeout = 10 * time.Second
+
+var (
+	networkInterfacesDesc = prometheus.NewDesc(
+		"metalnet_network_interfaces",
+		"Number of network interfaces on this node, by network. The namespace is empty for cluster networks.",
+		[]string{"namespace", "network", "vni"}, nil,
+	)
+	networkVirtualIPsDesc = prometheus.NewDesc(
+		"metalnet_network_virtual_ips",
+		"Number of virtual ips of the network interfaces on this node, by network. The namespace is empty for cluster networks.",
+		[]string{"namespace", "network", "vni"}, nil,
+	)
+	networkInterfacePrefixesDesc = prometheus.NewDesc(
+		"metalnet_network_interface_prefixes",
+		"Number of prefixes and load balancer target prefixes of a network interface on this node.",
+		[]string{"namespace", "network_interface", "type"}, nil,
+	)
+	loadBalancerTargetsDesc = prometheus.NewDesc(
+		"metalnet_load_balancer_targets",
+		"Number of targets of a load balancer on this node as programmed into dpservice.",
+		[]string{"namespace", "load_balancer"}, nil,
+	)
+)
+
+// ObjectCollector is a prometheus collector reporting the number of objects programmed on this node, so
+// capacity planning dashboards can be built from the metrics of metalnet instead of the API server.
+type ObjectCollector struct {
+	client.Reader
+	DPDK     dpdkclient.Client
+	NodeName string
+
+	log logr.Logger
+}
+
+func NewObjectCollector(c client.Reader, dpdk dpdkclient.Client, nodeName string) *ObjectCollector {
+	return &ObjectCollector{
+		Reader:   c,
+		DPDK:     dpdk,
+		NodeName: nodeName,
+		log:      ctrl.Log.WithName("object-metrics"),
+	}
+}
+
+func (c *ObjectCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- networkInterfacesDesc
+	ch <- networkVirtualIPsDesc
+	ch <- networkInterfacePrefixesDesc
+	ch <- loadBalancerTargetsDesc
+}
+
+func (c *ObjectCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), objectMetricsCollectTimeout)
+	defer cancel()
+
+	c.collectNetworkInterfaces(ctx, ch)
+	c.collectLoadBalancers(ctx, ch)
+}
+
+// networkCount is the number of network interfaces and virtual ips of a network.
+type networkCount struct {
+	networkInterfaces, virtualIPs int
+}
+
+func (c *ObjectCollector) collectNetworkInterfaces(ctx context.Context, ch chan<- prometheus.Metric) {
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := c.List(ctx, nicList); err != nil {
+		c.log.Error(err, "Error listing network interfaces")
+		return
+	}
+
+	counts := map[client.ObjectKey]*networkCount{}
+	for i := range nicList.Items {
+		nic := &nicList.Items[i]
+		if nic.Spec.NodeName == nil || *nic.Spec.NodeName != c.NodeName {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(networkInterfacePrefixesDesc, prometheus.GaugeValue,
+			float64(len(nic.Spec.Prefixes)), nic.Namespace, nic.Name, "prefix")
+		ch <- prometheus.MustNewConstMetric(networkInterfacePrefixesDesc, prometheus.GaugeValue,
+			float64(len(nic.Spec.LoadBalancerTargets)), nic.Namespace, nic.Name, "loadbalancer_target")
+
+		networkKey := networkInterfaceNetworkKey(nic)
+		count, ok := counts[networkKey]
+		if !ok {
+			count = &networkCount{}
+			counts[networkKey] = count
+		}
+		count.networkInterfaces++
+		if nic.Spec.VirtualIP != nil {
+			count.virtualIPs++
+		}
+	}
+
+	for networkKey, count := range counts {
+		network, err := getNetwork(ctx, c, networkKey)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				c.log.Error(err, "Error getting network", "NetworkKey", networkKey)
+			}
+			continue
+		}
+		vni := strconv.Itoa(int(network.Spec.ID))
+		ch <- prometheus.MustNewConstMetric(networkInterfacesDesc, prometheus.GaugeValue,
+			float64(count.networkInterfaces), networkKey.Namespace, networkKey.Name, vni)
+		ch <- prometheus.MustNewConstMetric(networkVirtualIPsDesc, prometheus.GaugeValue,
+			float64(count.virtualIPs), networkKey.Namespace, networkKey.Name, vni)
+	}
+}
+
+func (c *ObjectCollector) collectLoadBalancers(ctx context.Context, ch chan<- prometheus.Metric) {
+	lbList := &metalnetv1alpha1.LoadBalancerList{}
+	if err := c.List(ctx, lbList); err != nil {
+		c.log.Error(err, "Error listing loadbalancers")
+		return
+	}
+
+	for i := range lbList.Items {
+		lb := &lbList.Items[i]
+		if !c.isLocalLoadBalancer(lb) {
+			continue
+		}
+		targets, err := c.DPDK.ListLoadBalancerTargets(ctx, string(lb.UID))
+		if err != nil {
+			c.log.Error(err, "Error listing loadbalancer targets", "LoadBalancer", client.ObjectKeyFromObject(lb))
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(loadBalancerTargetsDesc, prometheus.GaugeValue,
+			float64(len(targets.Items)), lb.Namespace, lb.Name)
+	}
+}
+
+// isLocalLoadBalancer reports whether the loadbalancer is programmed on this node.
+func (c *ObjectCollector) isLocalLoadBalancer(lb *metalnetv1alpha1.LoadBalancer) bool {
+	if isActiveActive(lb) {
+		return slices.Contains(lb.Status.Nodes, c.NodeName)
+	}
+	return lb.Spec.NodeName != nil && *lb.Spec.NodeName == c.NodeName
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ObjectCollector", func() {
+	It("should report the objects programmed on this node", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		lbIP := netip.MustParseAddr("10.0.0.100")
+		_, err = dpdkClient.CreateLoadBalancer(ctx, &dpdk.LoadBalancer{
+			LoadBalancerMeta: dpdk.LoadBalancerMeta{ID: "lb-uid"},
+			Spec:             dpdk.LoadBalancerSpec{VNI: 100, LbVipIP: &lbIP},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, target := range []string{"2001:db8::1", "2001:db8::2"} {
+			targetIP := netip.MustParseAddr(target)
+			_, err = dpdkClient.CreateLoadBalancerTarget(ctx, &dpdk.LoadBalancerTarget{
+				LoadBalancerTargetMeta: dpdk.LoadBalancerTargetMeta{LoadbalancerID: "lb-uid"},
+				Spec:                   dpdk.LoadBalancerTargetSpec{TargetIP: &targetIP},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		newNIC := func(name, node string, virtualIP bool) *metalnetv1alpha1.NetworkInterface {
+			nic := &metalnetv1alpha1.NetworkInterface{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+				Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+					NetworkRef: corev1.LocalObjectReference{Name: "net"},
+					NodeName:   ptr.To(node),
+					Prefixes:   []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.1.0.0/24")},
+				},
+			}
+			if virtualIP {
+				nic.Spec.VirtualIP = ptr.To(metalnetv1alpha1.MustParseIP("45.86.6.1"))
+			}
+			return nic
+		}
+		lb := &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb", UID: "lb-uid"},
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				NodeName:   ptr.To("node"),
+			},
+		}
+		remoteLB := &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "remote-lb", UID: "remote-lb-uid"},
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				NodeName:   ptr.To("other"),
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(network, newNIC("nic-1", "node", true), newNIC("nic-2", "node", false),
+				newNIC("remote", "other", true), lb, remoteLB).
+			Build()
+
+		collector := NewObjectCollector(c, dpdkClient, "node")
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP metalnet_load_balancer_targets Number of targets of a load balancer on this node as programmed into dpservice.
+# TYPE metalnet_load_balancer_targets gauge
+metalnet_load_balancer_targets{load_balancer="lb",namespace="default"} 2
+# HELP metalnet_network_interface_prefixes Number of prefixes and load balancer target prefixes of a network interface on this node.
+# TYPE metalnet_network_interface_prefixes gauge
+metalnet_network_interface_prefixes{namespace="default",network_interface="nic-1",type="loadbalancer_target"} 0
+metalnet_network_interface_prefixes{namespace="default",network_interface="nic-1",type="prefix"} 1
+metalnet_network_interface_prefixes{namespace="default",network_interface="nic-2",type="loadbalancer_target"} 0
+metalnet_network_interface_prefixes{namespace="default",network_interface="nic-2",type="prefix"} 1
+# HELP metalnet_network_interfaces Number of network interfaces on this node, by network. The namespace is empty for cluster networks.
+# TYPE metalnet_network_interfaces gauge
+metalnet_network_interfaces{namespace="default",network="net",vni="100"} 2
+# HELP metalnet_network_virtual_ips Number of virtual ips of the network interfaces on this node, by network. The namespace is empty for cluster networks.
+# TYPE metalnet_network_virtual_ips gauge
+metalnet_network_virtual_ips{namespace="default",network="net",vni="100"} 1
+`))).To(Succeed())
+	})
+})
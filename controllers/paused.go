@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isPaused reports whether the reconciliation of the object is paused by the paused annotation.
+func isPaused(obj client.Object) bool {
+	return obj.GetAnnotations()[metalnetv1alpha1.PausedAnnotation] == "true"
+}
+
+// updatePausedCondition sets the ReconciliationPaused condition of the object if it is paused and removes it
+// otherwise. It reports whether the object is paused.
+func updatePausedCondition(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition) (bool, error) {
+	paused := isPaused(obj)
+	hasCondition := meta.FindStatusCondition(*conditions, metalnetv1alpha1.ReconciliationPaused) != nil
+	if hasCondition == paused {
+		return paused, nil
+	}
+
+	base := obj.DeepCopyObject().(client.Object)
+	if paused {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               metalnetv1alpha1.ReconciliationPaused,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             metalnetv1alpha1.PausedReasonAnnotated,
+			Message:            fmt.Sprintf("Reconciliation is paused by the %s annotation", metalnetv1alpha1.PausedAnnotation),
+		})
+	} else {
+		meta.RemoveStatusCondition(conditions, metalnetv1alpha1.ReconciliationPaused)
+	}
+	if err := c.Status().Patch(ctx, obj, client.MergeFrom(base)); err != nil {
+		return paused, fmt.Errorf("error patching paused condition: %w", err)
+	}
+	return paused, nil
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Paused reconciliation", func() {
+	It("should set the paused condition while the object is annotated", func(ctx SpecContext) {
+		nic := &metalnetv1alpha1.NetworkInterface{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "nic",
+			Annotations: map[string]string{metalnetv1alpha1.PausedAnnotation: "true"},
+		}}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&metalnetv1alpha1.NetworkInterface{}).WithObjects(nic).Build()
+
+		pausedCondition := func() *metav1.Condition {
+			stored := &metalnetv1alpha1.NetworkInterface{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), stored)).To(Succeed())
+			return meta.FindStatusCondition(stored.Status.Conditions, metalnetv1alpha1.ReconciliationPaused)
+		}
+
+		By("pausing the network interface")
+		paused, err := updatePausedCondition(ctx, c, nic, &nic.Status.Conditions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paused).To(BeTrue())
+		Expect(pausedCondition()).NotTo(BeNil())
+		Expect(pausedCondition().Reason).To(Equal(metalnetv1alpha1.PausedReasonAnnotated))
+
+		By("resuming the network interface")
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(nic), nic)).To(Succeed())
+		base := nic.DeepCopy()
+		nic.Annotations[metalnetv1alpha1.PausedAnnotation] = "false"
+		Expect(c.Patch(ctx, nic, client.MergeFrom(base))).To(Succeed())
+		paused, err = updatePausedCondition(ctx, c, nic, &nic.Status.Conditions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paused).To(BeFalse())
+		Expect(pausedCondition()).To(BeNil())
+	})
+})
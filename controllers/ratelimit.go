@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type objectLimiter struct {
+	limiter *rate.Limiter
+	// generation is the last generation admitted to be applied.
+	generation int64
+}
+
+// ObjectRateLimiter limits how often the spec updates of a single object are applied, so a
+// tenant flapping an object cannot hammer dpservice and metalbond. Each object has its own
+// token bucket. Reconciles of an already admitted generation are not limited.
+//
+// A nil ObjectRateLimiter does not limit.
+type ObjectRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	objects map[client.ObjectKey]*objectLimiter
+}
+
+// NewObjectRateLimiter allows limit updates per second and object with bursts of up to burst updates.
+func NewObjectRateLimiter(limit rate.Limit, burst int) *ObjectRateLimiter {
+	return &ObjectRateLimiter{
+		limit:   limit,
+		burst:   burst,
+		objects: make(map[client.ObjectKey]*objectLimiter),
+	}
+}
+
+// Delay returns how long applying the current generation of the object has to be delayed.
+// Zero admits the generation to be applied now.
+func (l *ObjectRateLimiter) Delay(obj client.Object) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := client.ObjectKeyFromObject(obj)
+	o, ok := l.objects[key]
+	if !ok {
+		o = &objectLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.objects[key] = o
+	}
+	if o.generation == obj.GetGeneration() {
+		return 0
+	}
+
+	reservation := o.limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return delay
+	}
+	o.generation = obj.GetGeneration()
+	return 0
+}
+
+// Forget drops the token bucket of the object with the given key.
+func (l *ObjectRateLimiter) Forget(key client.ObjectKey) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.objects, key)
+}
+
+func isUpdateThrottled(conditions []metav1.Condition) bool {
+	return meta.IsStatusConditionTrue(conditions, metalnetv1alpha1.UpdateThrottled)
+}
+
+func setUpdateThrottledCondition(conditions *[]metav1.Condition, generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               metalnetv1alpha1.UpdateThrottled,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             metalnetv1alpha1.ThrottleReasonRateLimited,
+		Message:            "Updates are rate limited, the latest generation is applied later",
+	})
+}
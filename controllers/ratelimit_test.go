@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ObjectRateLimiter", func() {
+	newNIC := func(name string, generation int64) *metalnetv1alpha1.NetworkInterface {
+		return &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: generation},
+		}
+	}
+
+	It("should limit the updates of each object separately", func() {
+		l := NewObjectRateLimiter(rate.Limit(0.001), 2)
+
+		Expect(l.Delay(newNIC("foo", 1))).To(BeZero())
+		Expect(l.Delay(newNIC("foo", 2))).To(BeZero())
+
+		By("reconciling an admitted generation again")
+		Expect(l.Delay(newNIC("foo", 2))).To(BeZero())
+
+		By("exceeding the burst")
+		Expect(l.Delay(newNIC("foo", 3))).To(BeNumerically(">", 0))
+		Expect(l.Delay(newNIC("bar", 1))).To(BeZero())
+
+		By("forgetting the object")
+		l.Forget(client.ObjectKey{Namespace: "default", Name: "foo"})
+		Expect(l.Delay(newNIC("foo", 3))).To(BeZero())
+	})
+
+	It("should not limit if nil", func() {
+		var l *ObjectRateLimiter
+		Expect(l.Delay(newNIC("foo", 1))).To(BeZero())
+	})
+})
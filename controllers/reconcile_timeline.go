@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// timelineRefreshInterval is the interval the timestamps of the reconcile timeline are refreshed at most at, so
+// objects reconciled often do not write their status every time.
+const timelineRefreshInterval = time.Minute
+
+// refreshTime sets t to now unless it was set within timelineRefreshInterval.
+func refreshTime(t **metav1.Time, now time.Time) {
+	if *t != nil && now.Sub((*t).Time) < timelineRefreshInterval {
+		return
+	}
+	*t = &metav1.Time{Time: now}
+}
+
+// recordProgrammed records that the object was programmed into dpservice and, if announced, that all of its
+// routes were announced.
+func recordProgrammed(timeline *metalnetv1alpha1.ReconcileTimeline, announced bool) {
+	now := time.Now()
+	refreshTime(&timeline.LastProgrammedTime, now)
+	if announced {
+		refreshTime(&timeline.LastAnnouncedTime, now)
+	}
+}
+
+// recordReconcileResult counts a failed reconciliation of the object, whose reconcile timeline is timeline, and
+// resets the consecutive failed reconciliations once one succeeds. Conflicts are not counted, they are retried
+// right away.
+func recordReconcileResult(ctx context.Context, c client.Client, obj client.Object, timeline *metalnetv1alpha1.ReconcileTimeline, reconcileErr error) error {
+	switch {
+	case !obj.GetDeletionTimestamp().IsZero(), apierrors.IsConflict(reconcileErr):
+		return nil
+	case reconcileErr == nil && timeline.ConsecutiveReconcileErrors == 0:
+		return nil
+	}
+
+	base := obj.DeepCopyObject().(client.Object)
+	if reconcileErr == nil {
+		timeline.ConsecutiveReconcileErrors = 0
+	} else {
+		timeline.ReconcileErrors++
+		timeline.ConsecutiveReconcileErrors++
+	}
+	if err := c.Status().Patch(ctx, obj, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("error patching reconcile timeline: %w", err)
+	}
+	return nil
+}
+
+// ignoreTimelineUpdates filters the updates of objects changing nothing but the reconcile timeline, so recording
+// a failed reconciliation does not trigger another one right away, bypassing the error backoff.
+func ignoreTimelineUpdates[T client.Object](timeline func(T) *metalnetv1alpha1.ReconcileTimeline) predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldObj, ok := e.ObjectOld.(T)
+			if !ok {
+				return true
+			}
+			newObj, ok := e.ObjectNew.(T)
+			if !ok {
+				return true
+			}
+			oldObj = oldObj.DeepCopyObject().(T)
+			newObj = newObj.DeepCopyObject().(T)
+			for _, obj := range []T{oldObj, newObj} {
+				*timeline(obj) = metalnetv1alpha1.ReconcileTimeline{}
+				obj.SetResourceVersion("")
+				obj.SetManagedFields(nil)
+			}
+			return !equality.Semantic.DeepEqual(oldObj, newObj)
+		},
+	}
+}
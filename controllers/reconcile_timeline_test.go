@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Reconcile timeline", func() {
+	It("should refresh the timestamps at most once per refresh interval", func() {
+		timeline := &metalnetv1alpha1.ReconcileTimeline{}
+		recordProgrammed(timeline, false)
+		Expect(timeline.LastProgrammedTime).NotTo(BeNil())
+		Expect(timeline.LastAnnouncedTime).To(BeNil())
+
+		programmed := timeline.LastProgrammedTime
+		recordProgrammed(timeline, true)
+		Expect(timeline.LastProgrammedTime).To(BeIdenticalTo(programmed))
+		Expect(timeline.LastAnnouncedTime).NotTo(BeNil())
+
+		stale := metav1.NewTime(time.Now().Add(-2 * timelineRefreshInterval))
+		timeline.LastProgrammedTime = &stale
+		recordProgrammed(timeline, true)
+		Expect(timeline.LastProgrammedTime.Time).To(BeTemporally(">", stale.Time))
+	})
+
+	It("should count failed reconciliations", func(ctx SpecContext) {
+		lb := &metalnetv1alpha1.LoadBalancer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb"}}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithStatusSubresource(&metalnetv1alpha1.LoadBalancer{}).
+			WithObjects(lb).
+			Build()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(lb), lb)).To(Succeed())
+
+		record := func(err error) metalnetv1alpha1.ReconcileTimeline {
+			GinkgoHelper()
+			Expect(recordReconcileResult(ctx, c, lb, &lb.Status.ReconcileTimeline, err)).To(Succeed())
+			stored := &metalnetv1alpha1.LoadBalancer{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(lb), stored)).To(Succeed())
+			return stored.Status.ReconcileTimeline
+		}
+
+		Expect(record(errors.New("dpservice unavailable"))).To(Equal(metalnetv1alpha1.ReconcileTimeline{
+			ReconcileErrors:            1,
+			ConsecutiveReconcileErrors: 1,
+		}))
+		Expect(record(errors.New("dpservice unavailable"))).To(Equal(metalnetv1alpha1.ReconcileTimeline{
+			ReconcileErrors:            2,
+			ConsecutiveReconcileErrors: 2,
+		}))
+
+		By("not counting conflicts")
+		conflict := apierrors.NewConflict(schema.GroupResource{Resource: "loadbalancers"}, lb.Name, errors.New("modified"))
+		Expect(record(conflict).ReconcileErrors).To(BeEquivalentTo(2))
+
+		By("resetting the consecutive failed reconciliations once one succeeds")
+		Expect(record(nil)).To(Equal(metalnetv1alpha1.ReconcileTimeline{
+			ReconcileErrors: 2,
+		}))
+	})
+
+	It("should ignore updates of the reconcile timeline only", func() {
+		predicate := ignoreTimelineUpdates(func(nic *metalnetv1alpha1.NetworkInterface) *metalnetv1alpha1.ReconcileTimeline {
+			return &nic.Status.ReconcileTimeline
+		})
+		oldNIC := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", ResourceVersion: "1"},
+		}
+
+		newNIC := oldNIC.DeepCopy()
+		newNIC.ResourceVersion = "2"
+		newNIC.Status.ReconcileErrors = 1
+		newNIC.Status.LastProgrammedTime = ptr.To(metav1.Now())
+		Expect(predicate.Update(event.UpdateEvent{ObjectOld: oldNIC, ObjectNew: newNIC})).To(BeFalse())
+
+		newNIC.Status.State = metalnetv1alpha1.NetworkInterfaceStateError
+		Expect(predicate.Update(event.UpdateEvent{ObjectOld: oldNIC, ObjectNew: newNIC})).To(BeTrue())
+	})
+})
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// transientErrorRequeueInterval is the interval an object that could not be programmed because dpservice was
+// temporarily unreachable is retried at.
+const transientErrorRequeueInterval = 2 * time.Second
+
+// validationError is returned for objects whose spec cannot be programmed. Retrying does not help until the
+// spec changes, which triggers a reconciliation anyway.
+type validationError struct {
+	err error
+}
+
+func newValidationError(err error) error {
+	return &validationError{err: err}
+}
+
+func (e *validationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *validationError) Unwrap() error {
+	return e.err
+}
+
+func isValidationError(err error) bool {
+	var validationErr *validationError
+	return errors.As(err, &validationErr)
+}
+
+// allErrorsAre reports whether all errors err is made of satisfy is. Unlike errors.Is and errors.As, a joined
+// error only satisfies is if every joined error does.
+func allErrorsAre(err error, is func(error) bool) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, err := range errs {
+			if !allErrorsAre(err, is) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	if wrapped := errors.Unwrap(err); wrapped != nil {
+		return allErrorsAre(wrapped, is)
+	}
+	return is(err)
+}
+
+// requeueOnError adjusts the result of a failed reconciliation to the kind of failure, so only failures that
+// may go away by retrying are retried with the error backoff:
+//   - Validation errors and routes denied by the announcement policy are not retried. The reconcilers report
+//     denied routes in the AnnouncementDenied condition.
+//   - Errors of full dpservice tables are retried after capacityRequeueInterval. The reconcilers report them
+//     in the CapacityExceeded condition.
+//   - Transient dpservice errors are retried after transientErrorRequeueInterval.
+//
+// Failures of several parts of an object are only treated as capacity or transient errors if all parts failed
+// that way.
+func requeueOnError(log logr.Logger, res ctrl.Result, err error) (ctrl.Result, error) {
+	switch {
+	case err == nil:
+		return res, nil
+	case isValidationError(err), allErrorsAre(err, metalbond.IsRouteDeniedError):
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	case allErrorsAre(err, metalnetdpdk.IsCapacityError):
+		log.V(1).Info("Dpservice table is full, retrying later", "Reason", err.Error())
+		return ctrl.Result{RequeueAfter: capacityRequeueInterval}, nil
+	case allErrorsAre(err, metalnetdpdk.IsTransientError):
+		log.Info("Dpservice is temporarily unavailable, retrying", "Reason", err.Error())
+		return ctrl.Result{RequeueAfter: transientErrorRequeueInterval}, nil
+	default:
+		return res, err
+	}
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Requeue on error", func() {
+	var (
+		capacityErr  = fmt.Errorf("error creating prefix: %w", dpdkerrors.NewStatusError(dpdkerrors.LIMIT_REACHED, "limit reached"))
+		transientErr = fmt.Errorf("error getting interface: %w", status.Error(codes.Unavailable, "connection refused"))
+		deniedErr    = fmt.Errorf("error announcing prefix: %w", &metalbond.RouteDeniedError{VNI: 100, Prefix: netip.MustParsePrefix("0.0.0.0/0")})
+		otherErr     = errors.New("something failed")
+	)
+
+	DescribeTable("should requeue by the kind of failure",
+		func(err error, expectedRes ctrl.Result, expectErr, expectTerminal bool) {
+			res, err := requeueOnError(ctrl.Log, ctrl.Result{}, err)
+			Expect(res).To(Equal(expectedRes))
+			if !expectErr {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, reconcile.TerminalError(nil))).To(Equal(expectTerminal))
+		},
+		Entry("validation errors", newValidationError(otherErr), ctrl.Result{}, true, true),
+		Entry("denied routes", deniedErr, ctrl.Result{}, true, true),
+		Entry("denied routes joined with other errors", fmt.Errorf("parts: %w", errors.Join(deniedErr, otherErr)),
+			ctrl.Result{}, true, false),
+		Entry("capacity errors", capacityErr, ctrl.Result{RequeueAfter: capacityRequeueInterval}, false, false),
+		Entry("transient errors", transientErr, ctrl.Result{RequeueAfter: transientErrorRequeueInterval}, false, false),
+		Entry("joined transient errors", fmt.Errorf("parts: %w", errors.Join(transientErr, transientErr)),
+			ctrl.Result{RequeueAfter: transientErrorRequeueInterval}, false, false),
+		Entry("transient errors joined with other errors", fmt.Errorf("parts: %w", errors.Join(transientErr, otherErr)),
+			ctrl.Result{}, true, false),
+		Entry("other errors", otherErr, ctrl.Result{}, true, false),
+	)
+})
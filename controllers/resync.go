@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// resyncJitterFactor is the maximum fraction of the resync period added to it, so the resyncs of the
+// metalnet instances of a cluster do not hit the apiserver and metalbond at the same time.
+const resyncJitterFactor = 0.1
+
+type resyncSource struct {
+	list   client.ObjectList
+	events chan event.GenericEvent
+}
+
+// Resync periodically reconciles all objects of the registered kinds, independent of watch events. It is a
+// safety net against missed events and against the dataplane drifting away from the objects.
+type Resync struct {
+	client client.Reader
+	period time.Duration
+	log    logr.Logger
+
+	mu      sync.Mutex
+	sources []resyncSource
+}
+
+// NewResync creates a Resync listing the objects from the given client every period plus jitter.
+func NewResync(c client.Reader, period time.Duration) *Resync {
+	return &Resync{
+		client: c,
+		period: period,
+		log:    ctrl.Log.WithName("resync"),
+	}
+}
+
+// Events returns the channel the objects of the given list kind are sent to on every resync. It has to be
+// called before the Resync is started.
+func (r *Resync) Events(list client.ObjectList) <-chan event.GenericEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make(chan event.GenericEvent)
+	r.sources = append(r.sources, resyncSource{list: list, events: events})
+	return events
+}
+
+// Start resyncs the registered kinds until the context is done. It implements manager.Runnable.
+func (r *Resync) Start(ctx context.Context) error {
+	r.mu.Lock()
+	sources := r.sources
+	r.mu.Unlock()
+
+	// The objects were just reconciled after the caches synced, so wait one period before the first resync.
+	timer := time.NewTimer(wait.Jitter(r.period, resyncJitterFactor))
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil
+		}
+
+		for _, source := range sources {
+			if err := r.resync(ctx, source); err != nil {
+				r.log.Error(err, "Error resyncing objects", "Kind", fmt.Sprintf("%T", source.list))
+			}
+		}
+		timer.Reset(wait.Jitter(r.period, resyncJitterFactor))
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every metalnet instance reconciles the
+// objects of its own node.
+func (r *Resync) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Resync) resync(ctx context.Context, source resyncSource) error {
+	list := source.list.DeepCopyObject().(client.ObjectList)
+	if err := r.client.List(ctx, list); err != nil {
+		return fmt.Errorf("error listing objects: %w", err)
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return fmt.Errorf("error extracting objects: %w", err)
+	}
+
+	r.log.V(1).Info("Resyncing objects", "Kind", fmt.Sprintf("%T", source.list), "Count", len(objs))
+	for _, obj := range objs {
+		select {
+		case source.events <- event.GenericEvent{Object: obj.(client.Object)}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
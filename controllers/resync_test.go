@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Resync", func() {
+	It("should periodically send all objects of the registered kinds", func(ctx SpecContext) {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			&metalnetv1alpha1.Network{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"}},
+			&metalnetv1alpha1.NetworkInterface{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic-1"}},
+			&metalnetv1alpha1.NetworkInterface{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic-2"}},
+		).Build()
+
+		resync := NewResync(c, 50*time.Millisecond)
+		networkEvents := resync.Events(&metalnetv1alpha1.NetworkList{})
+		nicEvents := resync.Events(&metalnetv1alpha1.NetworkInterfaceList{})
+		resyncCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(resync.Start(resyncCtx)).To(Succeed())
+		}()
+
+		receive := func(events <-chan event.GenericEvent) string {
+			GinkgoHelper()
+			var evt event.GenericEvent
+			Eventually(events).Should(Receive(&evt))
+			return client.ObjectKeyFromObject(evt.Object).Name
+		}
+		for i := 0; i < 2; i++ {
+			Expect(receive(networkEvents)).To(Equal("net"))
+			Expect([]string{receive(nicEvents), receive(nicEvents)}).To(ConsistOf("nic-1", "nic-2"))
+		}
+	})
+})
@@ -0,0 +1,458 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/ironcore-dev/controller-utils/clientutils"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const serviceChainFinalizer = "networking.metalnet.ironcore.dev/serviceChain"
+
+// ServiceChainReconciler reconciles a ServiceChain object.
+//
+// It programs the route entries of the chain in the VNIs in use on its node: the prefixes of the chain are
+// routed to the first hop in the VNI of the Network and to the next hop in the VNI of each hop but the last.
+// Changes of the chain are applied make-before-break and rolled back if they cannot be applied completely,
+// so the traffic is never steered into a partial chain. The programmed entries are recorded per node in the
+// status, so entries removed from the chain are cleaned up as well. The hop VNIs do not need to be peered, so the
+// entries are also recorded in the MetalnetCache to exempt them from the isolation checks of metalbond.
+type ServiceChainReconciler struct {
+	client.Client
+	record.EventRecorder
+	Scheme *runtime.Scheme
+
+	DPDK          dpdkclient.Client
+	MetalnetCache *internal.MetalnetCache
+
+	NodeName string
+}
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=servicechains,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=servicechains/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=servicechains/finalizers,verbs=update;patch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *ServiceChainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	chain := &metalnetv1alpha1.ServiceChain{}
+	if err := r.Get(ctx, req.NamespacedName, chain); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !chain.DeletionTimestamp.IsZero() {
+		return r.delete(ctx, log, chain)
+	}
+	return r.reconcile(ctx, log, chain)
+}
+
+func (r *ServiceChainReconciler) delete(ctx context.Context, log logr.Logger, chain *metalnetv1alpha1.ServiceChain) (ctrl.Result, error) {
+	log.V(1).Info("Delete")
+
+	if !controllerutil.ContainsFinalizer(chain, r.finalizer()) {
+		log.V(1).Info("No finalizer present, nothing to do.")
+		return ctrl.Result{}, nil
+	}
+
+	log.V(1).Info("Finalizer present, deleting route entries")
+	applied := r.nodeRoutes(chain)
+	r.recordRoutes(chain, applied)
+	if err := r.deleteRoutes(ctx, applied); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordRoutes(chain)
+	log.V(1).Info("Deleted route entries")
+	return r.removeNode(ctx, log, chain)
+}
+
+func (r *ServiceChainReconciler) reconcile(ctx context.Context, log logr.Logger, chain *metalnetv1alpha1.ServiceChain) (ctrl.Result, error) {
+	log.V(1).Info("Reconcile")
+
+	// The programmed entries are recorded first, so the isolation checks keep them after a restart.
+	applied := r.nodeRoutes(chain)
+	r.recordRoutes(chain, applied)
+	desired, err := r.desiredRoutes(ctx, log, chain)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(desired) == 0 {
+		log.V(1).Info("No route entries on the node, deleting programmed ones")
+		if err := r.deleteRoutes(ctx, applied); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recordRoutes(chain)
+		return r.removeNode(ctx, log, chain)
+	}
+
+	log.V(1).Info("Ensuring finalizer")
+	modified, err := clientutils.PatchEnsureFinalizer(ctx, r.Client, chain, r.finalizer())
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error ensuring finalizer: %w", err)
+	}
+	if modified {
+		log.V(1).Info("Added finalizer, requeueing")
+		return ctrl.Result{Requeue: true}, nil
+	}
+	log.V(1).Info("Ensured finalizer")
+
+	log.V(1).Info("Applying route entries", "Routes", len(desired))
+	r.recordRoutes(chain, applied, desired)
+	if err := r.applyRoutes(ctx, log, desired, applied); err != nil {
+		r.Eventf(chain, corev1.EventTypeWarning, "RoutesNotProgrammed", "Could not program the route entries on node %s: %v", r.NodeName, err)
+		return ctrl.Result{}, err
+	}
+	log.V(1).Info("Applied route entries")
+
+	log.V(1).Info("Deleting stale route entries")
+	if err := r.deleteRoutes(ctx, staleServiceChainRoutes(applied, desired)); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordRoutes(chain, desired)
+	log.V(1).Info("Deleted stale route entries")
+
+	return r.setNodeRoutes(ctx, log, chain, desired)
+}
+
+// desiredRoutes returns the route entries of the chain in the VNIs in use on the node, in the order of the chain.
+func (r *ServiceChainReconciler) desiredRoutes(ctx context.Context, log logr.Logger, chain *metalnetv1alpha1.ServiceChain) ([]metalnetv1alpha1.ServiceChainRoute, error) {
+	network := &metalnetv1alpha1.Network{}
+	networkKey := client.ObjectKey{Namespace: chain.Namespace, Name: chain.Spec.NetworkRef.Name}
+	if err := r.Get(ctx, networkKey, network); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting network %s: %w", networkKey.Name, err)
+		}
+		log.V(1).Info("Network not found", "NetworkKey", networkKey)
+		return nil, nil
+	}
+	if !network.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Network is being deleted", "NetworkKey", networkKey)
+		return nil, nil
+	}
+	for _, hop := range chain.Spec.Hops {
+		if hop.VNI == network.Spec.ID {
+			r.Eventf(chain, corev1.EventTypeWarning, "InvalidHop", "Hop VNI %d is the VNI of network %s", hop.VNI, network.Name)
+			return nil, nil
+		}
+	}
+
+	inUse := make(map[int32]bool)
+	var routes []metalnetv1alpha1.ServiceChainRoute
+	for _, route := range serviceChainRoutes(chain, network.Spec.ID) {
+		vniInUse, ok := inUse[route.VNI]
+		if !ok {
+			vni, err := r.DPDK.GetVni(ctx, uint32(route.VNI), 0)
+			if err != nil {
+				return nil, fmt.Errorf("error checking vni %d: %w", route.VNI, err)
+			}
+			vniInUse = vni.Spec.InUse
+			inUse[route.VNI] = vniInUse
+		}
+		if vniInUse {
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// serviceChainRoutes returns the route entries of the chain starting in the given VNI, in the order of the chain.
+func serviceChainRoutes(chain *metalnetv1alpha1.ServiceChain, vni int32) []metalnetv1alpha1.ServiceChainRoute {
+	var routes []metalnetv1alpha1.ServiceChainRoute
+	for _, hop := range chain.Spec.Hops {
+		for _, prefix := range chain.Spec.Prefixes {
+			routes = append(routes, metalnetv1alpha1.ServiceChainRoute{
+				VNI:            vni,
+				Prefix:         prefix,
+				NextHopVNI:     hop.VNI,
+				NextHopAddress: hop.UnderlayAddress,
+			})
+		}
+		vni = hop.VNI
+	}
+	return routes
+}
+
+// recordRoutes records the given route entries of the chain in the MetalnetCache, replacing the ones recorded
+// before. Without route entries, the entries of the chain are removed from the cache.
+func (r *ServiceChainReconciler) recordRoutes(chain *metalnetv1alpha1.ServiceChain, routeLists ...[]metalnetv1alpha1.ServiceChainRoute) {
+	var routes []internal.ServiceChainRoute
+	for _, list := range routeLists {
+		for _, route := range list {
+			routes = append(routes, internal.ServiceChainRoute{
+				VNI:        uint32(route.VNI),
+				Prefix:     route.Prefix.Prefix,
+				NextHopVNI: uint32(route.NextHopVNI),
+			})
+		}
+	}
+	r.MetalnetCache.SetServiceChainRoutes(chain.UID, routes)
+}
+
+// serviceChainRouteKey identifies a route entry in dpservice, which holds one route per prefix and VNI.
+type serviceChainRouteKey struct {
+	vni    int32
+	prefix netip.Prefix
+}
+
+func serviceChainRouteKeyOf(route metalnetv1alpha1.ServiceChainRoute) serviceChainRouteKey {
+	return serviceChainRouteKey{vni: route.VNI, prefix: route.Prefix.Prefix}
+}
+
+// staleServiceChainRoutes returns the applied route entries whose prefix is not routed in their VNI anymore.
+func staleServiceChainRoutes(applied, desired []metalnetv1alpha1.ServiceChainRoute) []metalnetv1alpha1.ServiceChainRoute {
+	var stale []metalnetv1alpha1.ServiceChainRoute
+	for _, route := range applied {
+		if !slices.ContainsFunc(desired, func(d metalnetv1alpha1.ServiceChainRoute) bool {
+			return serviceChainRouteKeyOf(d) == serviceChainRouteKeyOf(route)
+		}) {
+			stale = append(stale, route)
+		}
+	}
+	return stale
+}
+
+// applyRoutes programs the desired route entries, replacing applied ones with another next hop. The entries are
+// programmed from the end of the chain, so the traffic is only steered into the chain once its later entries
+// exist. If an entry cannot be programmed, the entries programmed so far are rolled back.
+func (r *ServiceChainReconciler) applyRoutes(ctx context.Context, log logr.Logger, desired, applied []metalnetv1alpha1.ServiceChainRoute) error {
+	appliedByKey := make(map[serviceChainRouteKey]metalnetv1alpha1.ServiceChainRoute, len(applied))
+	for _, route := range applied {
+		appliedByKey[serviceChainRouteKeyOf(route)] = route
+	}
+
+	var undo []func() error
+	rollback := func() {
+		log.V(1).Info("Rolling back route entries")
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err := undo[i](); err != nil {
+				log.Error(err, "Error rolling back route entry")
+			}
+		}
+	}
+
+	for i := len(desired) - 1; i >= 0; i-- {
+		route := desired[i]
+		old, ok := appliedByKey[serviceChainRouteKeyOf(route)]
+		if ok && old == route {
+			continue
+		}
+		if ok {
+			log.V(1).Info("Replacing route entry", "VNI", route.VNI, "Prefix", route.Prefix, "NextHopVNI", route.NextHopVNI)
+			if err := r.deleteRoute(ctx, old); err != nil {
+				rollback()
+				return err
+			}
+			undo = append(undo, func() error { return r.createRoute(ctx, old) })
+		}
+		if err := r.createRoute(ctx, route); err != nil {
+			rollback()
+			return err
+		}
+		undo = append(undo, func() error { return r.deleteRoute(ctx, route) })
+	}
+	return nil
+}
+
+// deleteRoutes deletes the given route entries from the start of the chain, so the traffic is no longer steered
+// into the chain before its later entries are gone.
+func (r *ServiceChainReconciler) deleteRoutes(ctx context.Context, routes []metalnetv1alpha1.ServiceChainRoute) error {
+	for _, route := range routes {
+		if err := r.deleteRoute(ctx, route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRoute creates the given route entry. An existing route to the prefix is only accepted if it has the
+// same next hop, e.g. if it was programmed before its status was recorded.
+func (r *ServiceChainReconciler) createRoute(ctx context.Context, route metalnetv1alpha1.ServiceChainRoute) error {
+	prefix := route.Prefix.Prefix
+	nextHopAddress := route.NextHopAddress.Addr
+	_, err := r.DPDK.CreateRoute(ctx, &dpdk.Route{
+		RouteMeta: dpdk.RouteMeta{
+			VNI: uint32(route.VNI),
+		},
+		Spec: dpdk.RouteSpec{
+			Prefix:  &prefix,
+			NextHop: &dpdk.RouteNextHop{VNI: uint32(route.NextHopVNI), IP: &nextHopAddress},
+		},
+	})
+	if err == nil {
+		return nil
+	}
+	if !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.ROUTE_EXISTS) {
+		return fmt.Errorf("error creating %s route in vni %d: %w", prefix, route.VNI, err)
+	}
+
+	routes, err := r.DPDK.ListRoutes(ctx, uint32(route.VNI))
+	if err != nil {
+		return fmt.Errorf("error listing routes of vni %d: %w", route.VNI, err)
+	}
+	for _, existing := range routes.Items {
+		if existing.Spec.Prefix == nil || *existing.Spec.Prefix != prefix || existing.Spec.NextHop == nil {
+			continue
+		}
+		nextHop := existing.Spec.NextHop
+		if nextHop.VNI == uint32(route.NextHopVNI) && nextHop.IP != nil && *nextHop.IP == nextHopAddress {
+			return nil
+		}
+	}
+	return fmt.Errorf("another %s route exists in vni %d", prefix, route.VNI)
+}
+
+func (r *ServiceChainReconciler) deleteRoute(ctx context.Context, route metalnetv1alpha1.ServiceChainRoute) error {
+	prefix := route.Prefix.Prefix
+	if _, err := r.DPDK.DeleteRoute(
+		ctx,
+		uint32(route.VNI),
+		&prefix,
+		dpdkerrors.Ignore(dpdkerrors.NO_VNI, dpdkerrors.ROUTE_NOT_FOUND),
+	); err != nil {
+		return fmt.Errorf("error deleting %s route in vni %d: %w", prefix, route.VNI, err)
+	}
+	return nil
+}
+
+// nodeRoutes returns the route entries recorded as programmed on the node.
+func (r *ServiceChainReconciler) nodeRoutes(chain *metalnetv1alpha1.ServiceChain) []metalnetv1alpha1.ServiceChainRoute {
+	for _, node := range chain.Status.Nodes {
+		if node.NodeName == r.NodeName {
+			return node.Routes
+		}
+	}
+	return nil
+}
+
+// setNodeRoutes records the route entries programmed on the node.
+func (r *ServiceChainReconciler) setNodeRoutes(ctx context.Context, log logr.Logger, chain *metalnetv1alpha1.ServiceChain, routes []metalnetv1alpha1.ServiceChainRoute) (ctrl.Result, error) {
+	nodes := slices.DeleteFunc(slices.Clone(chain.Status.Nodes), func(node metalnetv1alpha1.ServiceChainNodeStatus) bool {
+		return node.NodeName == r.NodeName
+	})
+	if len(routes) > 0 {
+		nodes = append(nodes, metalnetv1alpha1.ServiceChainNodeStatus{NodeName: r.NodeName, Routes: routes})
+		slices.SortFunc(nodes, func(a, b metalnetv1alpha1.ServiceChainNodeStatus) int {
+			return strings.Compare(a.NodeName, b.NodeName)
+		})
+	}
+	if slices.EqualFunc(chain.Status.Nodes, nodes, func(a, b metalnetv1alpha1.ServiceChainNodeStatus) bool {
+		return a.NodeName == b.NodeName && slices.Equal(a.Routes, b.Routes)
+	}) {
+		return ctrl.Result{}, nil
+	}
+
+	log.V(1).Info("Recording route entries", "Routes", len(routes))
+	base := chain.DeepCopy()
+	chain.Status.Nodes = nodes
+	// The controller runs on every node, so concurrent updates must not overwrite each other.
+	if err := r.Status().Patch(ctx, chain, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+		if apierrors.IsConflict(err) {
+			log.V(1).Info("Service chain was modified concurrently, requeueing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("error recording route entries: %w", err)
+	}
+	log.V(1).Info("Recorded route entries")
+	return ctrl.Result{}, nil
+}
+
+// removeNode removes the node from the status and its finalizer once its route entries are deleted.
+func (r *ServiceChainReconciler) removeNode(ctx context.Context, log logr.Logger, chain *metalnetv1alpha1.ServiceChain) (ctrl.Result, error) {
+	if res, err := r.setNodeRoutes(ctx, log, chain, nil); err != nil || res.Requeue {
+		return res, err
+	}
+	if !controllerutil.ContainsFinalizer(chain, r.finalizer()) {
+		return ctrl.Result{}, nil
+	}
+	log.V(1).Info("Removing finalizer")
+	if err := clientutils.PatchRemoveFinalizer(ctx, r.Client, chain, r.finalizer()); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error removing finalizer: %w", err)
+	}
+	log.V(1).Info("Removed finalizer")
+	return ctrl.Result{}, nil
+}
+
+func (r *ServiceChainReconciler) finalizer() string {
+	return fmt.Sprintf("%s-%s", serviceChainFinalizer, r.NodeName)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceChainReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	log := ctrl.Log.WithName("servicechain").WithName("setup")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metalnetv1alpha1.ServiceChain{}).
+		Watches(
+			&metalnetv1alpha1.Network{},
+			r.enqueueServiceChainsReferencingNetwork(log),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		// VNIs come into and out of use with the NetworkInterfaces on the node.
+		Watches(
+			&metalnetv1alpha1.NetworkInterface{},
+			r.enqueueServiceChains(log),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(withTracing("ServiceChain", r))
+}
+
+func (r *ServiceChainReconciler) enqueueServiceChainsReferencingNetwork(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		network := obj.(*metalnetv1alpha1.Network)
+		chainList := &metalnetv1alpha1.ServiceChainList{}
+		if err := r.List(ctx, chainList, client.InNamespace(network.Namespace)); err != nil {
+			log.Error(err, "Error listing service chains")
+			return nil
+		}
+		var reqs []ctrl.Request
+		for _, chain := range chainList.Items {
+			if chain.Spec.NetworkRef.Name == network.Name {
+				reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&chain)})
+			}
+		}
+		return reqs
+	})
+}
+
+func (r *ServiceChainReconciler) enqueueServiceChains(log logr.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []ctrl.Request {
+		nic := obj.(*metalnetv1alpha1.NetworkInterface)
+		if nic.Spec.NodeName == nil || *nic.Spec.NodeName != r.NodeName {
+			return nil
+		}
+		chainList := &metalnetv1alpha1.ServiceChainList{}
+		if err := r.List(ctx, chainList); err != nil {
+			log.Error(err, "Error listing service chains")
+			return nil
+		}
+		reqs := make([]ctrl.Request, 0, len(chainList.Items))
+		for _, chain := range chainList.Items {
+			reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&chain)})
+		}
+		return reqs
+	})
+}
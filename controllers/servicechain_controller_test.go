@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ServiceChain", func() {
+	It("should program the route entries of the chain in the VNIs in use and clean them up", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		// The VNI of the network and of the first hop are in use on the node, the one of the second hop is not.
+		for i, vni := range []uint32{100, 300} {
+			ip := netip.AddrFrom4([4]byte{10, 0, byte(i), 1})
+			_, err = dpdkClient.CreateInterface(ctx, &dpdk.Interface{
+				InterfaceMeta: dpdk.InterfaceMeta{ID: fmt.Sprintf("nic-%d", i)},
+				Spec:          dpdk.InterfaceSpec{VNI: vni, Device: fmt.Sprintf("net_tap%d", i+4), IPv4: &ip},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		network := &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "net"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 100},
+		}
+		chain := &metalnetv1alpha1.ServiceChain{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "chain", UID: types.UID("uid-chain")},
+			Spec: metalnetv1alpha1.ServiceChainSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				Prefixes:   []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.10.0/24")},
+				Hops: []metalnetv1alpha1.ServiceChainHop{
+					{VNI: 300, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:1::1")},
+					{VNI: 301, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:2::1")},
+				},
+			},
+		}
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&metalnetv1alpha1.ServiceChain{}).WithObjects(network, chain).Build()
+		log := GinkgoLogr
+		metalnetCache := internal.NewMetalnetCache(&log)
+		r := &ServiceChainReconciler{
+			Client:        c,
+			EventRecorder: record.NewFakeRecorder(10),
+			Scheme:        s,
+			DPDK:          dpdkClient,
+			MetalnetCache: metalnetCache,
+			NodeName:      "node",
+		}
+		mbClient := metalbond.NewMetalnetClient(&log, dpdkClient, metalnetCache, &metalbond.DefaultRouterAddress{}, metalbond.ClientOptions{})
+		reconcileChain := func() error {
+			for {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(chain)})
+				if err != nil || !res.Requeue {
+					return err
+				}
+			}
+		}
+		nextHops := func(vni uint32) map[string]string {
+			routes, err := dpdkClient.ListRoutes(ctx, vni)
+			Expect(err).NotTo(HaveOccurred())
+			nextHops := make(map[string]string)
+			for _, route := range routes.Items {
+				nextHops[route.Spec.Prefix.String()] = netip.AddrPortFrom(*route.Spec.NextHop.IP, uint16(route.Spec.NextHop.VNI)).String()
+			}
+			return nextHops
+		}
+
+		By("programming the entries of the VNIs in use")
+		Expect(reconcileChain()).To(Succeed())
+		Expect(nextHops(100)).To(Equal(map[string]string{"10.0.10.0/24": "[fc00:1::1]:300"}))
+		Expect(nextHops(300)).To(Equal(map[string]string{"10.0.10.0/24": "[fc00:2::1]:301"}))
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(chain), chain)).To(Succeed())
+		Expect(chain.Finalizers).To(ConsistOf("networking.metalnet.ironcore.dev/serviceChain-node"))
+		Expect(chain.Status.Nodes).To(ConsistOf(HaveField("Routes", HaveLen(2))))
+
+		By("keeping the entries into the not peered hop VNIs in the isolation audit")
+		for _, vni := range []uint32{100, 300} {
+			Expect(mbClient.RemoveIsolationViolations(ctx, vni)).To(BeZero())
+		}
+		Expect(nextHops(100)).To(Equal(map[string]string{"10.0.10.0/24": "[fc00:1::1]:300"}))
+		Expect(nextHops(300)).To(Equal(map[string]string{"10.0.10.0/24": "[fc00:2::1]:301"}))
+
+		By("rolling back a change that cannot be programmed completely")
+		foreignPrefix := netip.MustParsePrefix("10.0.20.0/24")
+		foreignNextHop := netip.MustParseAddr("fc00:9::1")
+		_, err = dpdkClient.CreateRoute(ctx, &dpdk.Route{
+			RouteMeta: dpdk.RouteMeta{VNI: 300},
+			Spec:      dpdk.RouteSpec{Prefix: &foreignPrefix, NextHop: &dpdk.RouteNextHop{VNI: 300, IP: &foreignNextHop}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		base := chain.DeepCopy()
+		chain.Spec.Prefixes = append(chain.Spec.Prefixes, metalnetv1alpha1.MustParseIPPrefix("10.0.20.0/24"))
+		chain.Spec.Hops[1].UnderlayAddress = metalnetv1alpha1.MustParseIP("fc00:3::1")
+		Expect(c.Patch(ctx, chain, client.MergeFrom(base))).To(Succeed())
+		Expect(reconcileChain()).To(MatchError(ContainSubstring("another 10.0.20.0/24 route exists in vni 300")))
+		Expect(nextHops(100)).To(Equal(map[string]string{"10.0.10.0/24": "[fc00:1::1]:300"}))
+		Expect(nextHops(300)).To(Equal(map[string]string{
+			"10.0.10.0/24": "[fc00:2::1]:301",
+			"10.0.20.0/24": "[fc00:9::1]:300",
+		}))
+
+		By("replacing the next hop of the chain")
+		base = chain.DeepCopy()
+		chain.Spec.Prefixes = chain.Spec.Prefixes[:1]
+		Expect(c.Patch(ctx, chain, client.MergeFrom(base))).To(Succeed())
+		Expect(reconcileChain()).To(Succeed())
+		Expect(nextHops(100)).To(Equal(map[string]string{"10.0.10.0/24": "[fc00:1::1]:300"}))
+		Expect(nextHops(300)).To(Equal(map[string]string{
+			"10.0.10.0/24": "[fc00:3::1]:301",
+			"10.0.20.0/24": "[fc00:9::1]:300",
+		}))
+
+		By("deleting the entries with the chain")
+		Expect(c.Delete(ctx, chain)).To(Succeed())
+		Expect(reconcileChain()).To(Succeed())
+		Expect(nextHops(100)).To(BeEmpty())
+		Expect(nextHops(300)).To(Equal(map[string]string{"10.0.20.0/24": "[fc00:9::1]:300"}))
+		Expect(apierrors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(chain), chain))).To(BeTrue())
+		Expect(metalnetCache.IsServiceChainRoute(100, netip.MustParsePrefix("10.0.10.0/24"), 300)).To(BeFalse())
+	})
+})
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Standby tracks whether a metalnet instance of a high-availability pair is the active or the standby
+// instance of its node.
+//
+// Both instances of a pair run with leader election on the lease of their node. The instance holding the
+// lease is active: it runs the controllers and every runnable changing dpservice, metalbond or the API.
+// The standby instance only keeps its caches and its dpservice and metalbond connections warm, so it can
+// take over as soon as the lease is released or expires.
+//
+// A nil Standby is always active.
+type Standby struct {
+	elected <-chan struct{}
+	synced  atomic.Bool
+	log     logr.Logger
+}
+
+// NewStandby creates a Standby becoming active once the given channel is closed, see manager.Manager.Elected.
+func NewStandby(elected <-chan struct{}) *Standby {
+	return &Standby{
+		elected: elected,
+		log:     ctrl.Log.WithName("standby"),
+	}
+}
+
+// Start waits for this instance to become active. It implements manager.Runnable and is started once the
+// caches are synced.
+func (s *Standby) Start(ctx context.Context) error {
+	s.synced.Store(true)
+	if !s.Active() {
+		s.log.Info("Standing by until the active instance of the node releases its lease")
+	}
+
+	select {
+	case <-s.elected:
+		s.log.Info("Became the active instance of the node")
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The standby instance waits for the election.
+func (s *Standby) NeedLeaderElection() bool {
+	return false
+}
+
+// Active reports whether this instance holds the lease of its node.
+func (s *Standby) Active() bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case <-s.elected:
+		return true
+	default:
+		return false
+	}
+}
+
+// Checker returns a healthz.Checker running the given check on the active instance. The standby instance is
+// ready once its caches are synced, so it does not block rollouts while it waits to take over.
+func (s *Standby) Checker(active healthz.Checker) healthz.Checker {
+	return func(req *http.Request) error {
+		if s.Active() {
+			return active(req)
+		}
+		if !s.synced.Load() {
+			return errors.New("caches of the standby instance are not synced yet")
+		}
+		return nil
+	}
+}
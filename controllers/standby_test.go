@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Standby", func() {
+	errNotSynced := errors.New("initial sync not complete")
+	activeChecker := func(_ *http.Request) error { return errNotSynced }
+
+	It("should only run the readiness check of the active instance once elected", func(ctx SpecContext) {
+		elected := make(chan struct{})
+		standby := NewStandby(elected)
+		checker := standby.Checker(activeChecker)
+
+		By("failing until the caches are synced")
+		Expect(standby.Active()).To(BeFalse())
+		Expect(checker(nil)).To(HaveOccurred())
+		Expect(checker(nil)).NotTo(MatchError(errNotSynced))
+
+		By("being ready as standby instance once started")
+		startCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		done := make(chan error)
+		go func() { done <- standby.Start(startCtx) }()
+		Eventually(func() error { return checker(nil) }).Should(Succeed())
+
+		By("running the check of the active instance once elected")
+		close(elected)
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(standby.Active()).To(BeTrue())
+		Expect(checker(nil)).To(MatchError(errNotSynced))
+	})
+
+	It("should always be active if nil", func() {
+		var standby *Standby
+		Expect(standby.Active()).To(BeTrue())
+		Expect(standby.Checker(activeChecker)(nil)).To(MatchError(errNotSynced))
+	})
+})
@@ -53,6 +53,8 @@ var (
 	metalnetCache      *internal.MetalnetCache
 	metalnetMBClient   *metalbond.MetalnetClient
 	metalbondRouteUtil *metalbond.MBRouteUtil
+	subscriptions      *metalbond.Subscriptions
+	aliasPrefixes      *metalbond.AliasPrefixAnnouncer
 	enableIPv6Support  bool = true
 )
 
@@ -128,12 +130,13 @@ var _ = BeforeSuite(func() {
 
 	metalnetCache = internal.NewMetalnetCache(&logger)
 	metalnetMBClient := metalbond.NewMetalnetClient(&logger, dpdkClient, metalnetCache, &defaultRouterAddr, metalbond.ClientOptions{
-		IPv4Only:         true,
-		PreferredNetwork: nil,
+		IPv4Only: true,
 	})
 
 	mbInstance := mb.NewMetalBond(config, metalnetMBClient)
 	metalbondRouteUtil = metalbond.NewMBRouteUtil(mbInstance)
+	subscriptions = metalbond.NewSubscriptions(metalbondRouteUtil)
+	aliasPrefixes = metalbond.NewAliasPrefixAnnouncer(metalbondRouteUtil)
 
 	err = mbInstance.AddPeer("[::1]:4711", "")
 	Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,337 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var pendingTombstones = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "metalnet_tombstones",
+	Help: "Number of deleted objects of the node whose dataplane state is not confirmed to be removed yet.",
+}, []string{"kind"})
+
+func init() {
+	metrics.Registry.MustRegister(pendingTombstones)
+}
+
+const (
+	// tombstoneTempFilePrefix is the prefix of the files tombstones are written to before being renamed into place.
+	tombstoneTempFilePrefix = ".tombstone-"
+	tombstoneFileSuffix     = ".json"
+)
+
+// isForceDeleted reports whether the finalizer of the object may be removed even though its dataplane state
+// could not be removed.
+func isForceDeleted(obj client.Object) bool {
+	return obj.GetAnnotations()[metalnetv1alpha1.ForceDeleteAnnotation] == "true"
+}
+
+// Tombstone is the record of a deleted object whose dataplane state is not confirmed to be removed yet.
+type Tombstone struct {
+	// Kind is the kind of the object, see TombstoneKind.
+	Kind string `json:"kind"`
+	// Recorded is the time the deletion of the object was first recorded.
+	Recorded time.Time `json:"recorded"`
+	// Object is the object as it was when its deletion was recorded.
+	Object json.RawMessage `json:"object"`
+}
+
+// TombstoneKind purges the dataplane state of the deleted objects of one kind.
+type TombstoneKind struct {
+	// Kind is the name of the kind.
+	Kind string
+	// NewObject returns an empty object of the kind.
+	NewObject func() client.Object
+	// Finalizer returns the finalizer of this node on the given object. The dataplane state of an object is
+	// only purged after the finalizer was removed, until then its controller is in charge of it.
+	Finalizer func(obj client.Object) string
+	// Purge removes the dataplane state of the given object. A nil error confirms the removal.
+	Purge func(ctx context.Context, log logr.Logger, obj client.Object) error
+}
+
+// Tombstones keeps the intent to delete the dataplane state of an object on disk until the dataplane
+// confirmed the removal, so it survives restarts of metalnet. The controllers record a tombstone when they
+// start cleaning up a deleted object and remove it once they removed its finalizer after a complete
+// cleanup. The tombstones of objects whose finalizer is gone nevertheless, e.g. because it was removed by
+// the ForceDeleteAnnotation or by hand, are purged periodically until the purge succeeds.
+type Tombstones struct {
+	dir      string
+	client   client.Reader
+	interval time.Duration
+	log      logr.Logger
+
+	mu       sync.Mutex
+	kinds    map[string]TombstoneKind
+	recorded map[types.UID]struct{}
+}
+
+// NewTombstones stores the tombstones in the given directory and purges the ones of objects whose finalizer
+// is gone at the given interval.
+func NewTombstones(dir string, c client.Reader, interval time.Duration) (*Tombstones, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating tombstone directory at %s: %w", dir, err)
+	}
+
+	// Remove the leftovers of tombstones interrupted while being written.
+	tempFiles, err := filepath.Glob(filepath.Join(dir, tombstoneTempFilePrefix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("error looking up temporary tombstone files: %w", err)
+	}
+	for _, tempFile := range tempFiles {
+		if err := os.Remove(tempFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error removing temporary tombstone file %s: %w", tempFile, err)
+		}
+	}
+
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Tombstones{
+		dir:      dir,
+		client:   c,
+		interval: interval,
+		log:      ctrl.Log.WithName("tombstones"),
+		kinds:    make(map[string]TombstoneKind),
+		recorded: make(map[types.UID]struct{}),
+	}, nil
+}
+
+// Register registers a kind of objects tombstones are recorded for. The tombstones of kinds that are not
+// registered are kept.
+func (t *Tombstones) Register(kind TombstoneKind) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.kinds[kind.Kind] = kind
+}
+
+func (t *Tombstones) file(uid types.UID) string {
+	return filepath.Join(t.dir, string(uid)+tombstoneFileSuffix)
+}
+
+// Record records the tombstone of the given object of the given kind, unless there already is one. It is
+// safe to call on nil Tombstones.
+func (t *Tombstones) Record(kind string, obj client.Object) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.recorded[obj.GetUID()]; ok {
+		return nil
+	}
+	if _, err := os.Stat(t.file(obj.GetUID())); err == nil {
+		t.track(kind, obj.GetUID())
+		return nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", kind, err)
+	}
+	data, err = json.Marshal(Tombstone{Kind: kind, Recorded: time.Now(), Object: data})
+	if err != nil {
+		return fmt.Errorf("error encoding tombstone: %w", err)
+	}
+
+	// The tombstone is written to a temporary file first and renamed into place afterwards, so a tombstone
+	// file is either complete or missing, even if metalnet crashes while writing it.
+	tempFile, err := os.CreateTemp(t.dir, tombstoneTempFilePrefix+"*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary tombstone file: %w", err)
+	}
+	defer func() { _ = os.Remove(tempFile.Name()) }()
+	if _, err := tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("error writing tombstone: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("error syncing tombstone: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("error closing tombstone: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), t.file(obj.GetUID())); err != nil {
+		return fmt.Errorf("error renaming tombstone: %w", err)
+	}
+	t.track(kind, obj.GetUID())
+	return nil
+}
+
+// track tracks the recorded tombstone of the object with the given UID. t.mu has to be held.
+func (t *Tombstones) track(kind string, uid types.UID) {
+	if _, ok := t.recorded[uid]; ok {
+		return
+	}
+	t.recorded[uid] = struct{}{}
+	pendingTombstones.WithLabelValues(kind).Inc()
+}
+
+// untrack stops tracking the removed tombstone of the object with the given UID. t.mu has to be held.
+func (t *Tombstones) untrack(kind string, uid types.UID) {
+	if _, ok := t.recorded[uid]; !ok {
+		return
+	}
+	delete(t.recorded, uid)
+	pendingTombstones.WithLabelValues(kind).Dec()
+}
+
+// Remove removes the tombstone of the object with the given UID of the given kind, if any. It is safe to
+// call on nil Tombstones.
+func (t *Tombstones) Remove(kind string, uid types.UID) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.Remove(t.file(uid)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing tombstone: %w", err)
+	}
+	t.untrack(kind, uid)
+	return nil
+}
+
+// List returns the recorded tombstones by the UID of their object.
+func (t *Tombstones) List() (map[types.UID]Tombstone, error) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tombstone directory: %w", err)
+	}
+
+	tombstones := make(map[types.UID]Tombstone)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, tombstoneTempFilePrefix) || !strings.HasSuffix(name, tombstoneFileSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(t.dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("error reading tombstone %s: %w", name, err)
+		}
+		var tombstone Tombstone
+		if err := json.Unmarshal(data, &tombstone); err != nil {
+			return nil, fmt.Errorf("error decoding tombstone %s: %w", name, err)
+		}
+		tombstones[types.UID(strings.TrimSuffix(name, tombstoneFileSuffix))] = tombstone
+	}
+	return tombstones, nil
+}
+
+// Start counts the tombstones left by previous runs and purges the ones of objects whose finalizer is gone
+// periodically. It implements manager.Runnable.
+func (t *Tombstones) Start(ctx context.Context) error {
+	tombstones, err := t.List()
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	for uid, tombstone := range tombstones {
+		t.track(tombstone.Kind, uid)
+	}
+	t.mu.Unlock()
+	if len(tombstones) > 0 {
+		t.log.Info("Found tombstones of previous runs", "Count", len(tombstones))
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Purge(ctx); err != nil {
+			t.log.Error(err, "Error purging tombstones")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The lease is per node, so the active metalnet
+// instance of every node purges the dataplane state of its node, and the standby instance leaves it alone.
+func (t *Tombstones) NeedLeaderElection() bool {
+	return true
+}
+
+// Purge purges the dataplane state of the objects whose finalizer is gone once and removes their
+// tombstones if the dataplane confirmed the removal.
+func (t *Tombstones) Purge(ctx context.Context) error {
+	tombstones, err := t.List()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for uid, tombstone := range tombstones {
+		log := t.log.WithValues("Kind", tombstone.Kind, "UID", uid)
+		if err := t.purge(ctx, log, uid, tombstone); err != nil {
+			errs = append(errs, fmt.Errorf("error purging %s %s: %w", tombstone.Kind, uid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Tombstones) purge(ctx context.Context, log logr.Logger, uid types.UID, tombstone Tombstone) error {
+	t.mu.Lock()
+	kind, ok := t.kinds[tombstone.Kind]
+	t.mu.Unlock()
+	if !ok {
+		log.V(1).Info("Unknown kind, keeping tombstone")
+		return nil
+	}
+
+	obj := kind.NewObject()
+	if err := json.Unmarshal(tombstone.Object, obj); err != nil {
+		return fmt.Errorf("error decoding object: %w", err)
+	}
+	log = log.WithValues("Object", client.ObjectKeyFromObject(obj))
+
+	current := kind.NewObject()
+	if err := t.client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error getting object: %w", err)
+		}
+	} else if current.GetUID() == uid && controllerutil.ContainsFinalizer(current, kind.Finalizer(current)) {
+		log.V(2).Info("Finalizer present, leaving cleanup to the controller")
+		return nil
+	}
+
+	log.Info("Purging dataplane state of deleted object", "Recorded", tombstone.Recorded)
+	if err := kind.Purge(ctx, log, obj); err != nil {
+		return err
+	}
+	if err := t.Remove(tombstone.Kind, uid); err != nil {
+		return err
+	}
+	log.Info("Purged dataplane state of deleted object")
+	return nil
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Tombstones", func() {
+	const finalizer = "networking.metalnet.ironcore.dev/test"
+
+	var (
+		dir    string
+		purged []string
+		purge  error
+	)
+
+	newNetworkInterface := func(name string, finalizers ...string) *metalnetv1alpha1.NetworkInterface {
+		return &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "default",
+				Name:       name,
+				UID:        types.UID("uid-" + name),
+				Finalizers: finalizers,
+			},
+		}
+	}
+
+	newTombstones := func(objs ...client.Object) *Tombstones {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+		tombstones, err := NewTombstones(dir, c, 0)
+		Expect(err).NotTo(HaveOccurred())
+		tombstones.Register(TombstoneKind{
+			Kind:      "NetworkInterface",
+			NewObject: func() client.Object { return &metalnetv1alpha1.NetworkInterface{} },
+			Finalizer: func(client.Object) string { return finalizer },
+			Purge: func(_ context.Context, _ logr.Logger, obj client.Object) error {
+				purged = append(purged, obj.GetName())
+				return purge
+			},
+		})
+		return tombstones
+	}
+
+	BeforeEach(func() {
+		dir = filepath.Join(GinkgoT().TempDir(), "tombstones")
+		purged, purge = nil, nil
+	})
+
+	It("should purge the dataplane state of objects whose finalizer is gone until it succeeds", func(ctx SpecContext) {
+		deleting := newNetworkInterface("deleting", finalizer)
+		forced := newNetworkInterface("forced")
+		tombstones := newTombstones(deleting, forced)
+
+		Expect(tombstones.Record("NetworkInterface", deleting)).To(Succeed())
+		Expect(tombstones.Record("NetworkInterface", forced)).To(Succeed())
+		Expect(tombstones.Record("NetworkInterface", newNetworkInterface("gone"))).To(Succeed())
+
+		By("failing to purge")
+		purge = errors.New("dpservice unavailable")
+		Expect(tombstones.Purge(ctx)).To(MatchError(ContainSubstring("dpservice unavailable")))
+		Expect(purged).To(ConsistOf("forced", "gone"))
+
+		By("surviving a restart")
+		tombstones = newTombstones(deleting, forced)
+		list, err := tombstones.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(3))
+
+		By("purging successfully")
+		purge, purged = nil, nil
+		Expect(tombstones.Purge(ctx)).To(Succeed())
+		Expect(purged).To(ConsistOf("forced", "gone"))
+		list, err = tombstones.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveKey(types.UID("uid-deleting")))
+		Expect(list).To(HaveLen(1))
+
+		By("removing the tombstone after the controller cleaned up")
+		Expect(tombstones.Remove("NetworkInterface", deleting.UID)).To(Succeed())
+		Expect(tombstones.Remove("NetworkInterface", deleting.UID)).To(Succeed())
+		list, err = tombstones.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(BeEmpty())
+	})
+
+	It("should purge objects recreated with the same name", func(ctx SpecContext) {
+		recreated := newNetworkInterface("nic", finalizer)
+		tombstones := newTombstones(recreated)
+		old := newNetworkInterface("nic")
+		old.UID = "uid-old"
+		Expect(tombstones.Record("NetworkInterface", old)).To(Succeed())
+
+		Expect(tombstones.Purge(ctx)).To(Succeed())
+		Expect(purged).To(ConsistOf("nic"))
+	})
+
+	It("should discard interrupted tombstones and keep the ones of unknown kinds", func(ctx SpecContext) {
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, tombstoneTempFilePrefix+"123"), []byte("{"), 0644)).To(Succeed())
+		tombstones := newTombstones()
+		Expect(filepath.Join(dir, tombstoneTempFilePrefix+"123")).NotTo(BeAnExistingFile())
+
+		Expect(tombstones.Record("LoadBalancer", newNetworkInterface("lb"))).To(Succeed())
+		Expect(tombstones.Purge(ctx)).To(Succeed())
+		Expect(purged).To(BeEmpty())
+		Expect(filepath.Join(dir, "uid-lb.json")).To(BeAnExistingFile())
+	})
+})
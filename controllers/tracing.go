@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var tracer = otel.Tracer("github.com/ironcore-dev/metalnet/controllers")
+
+// tracingReconciler records a span for every reconcile. The dpservice calls and route announcements
+// of the reconcile are recorded as its children. The reconcile runs with the pprof label reconciler set to
+// the kind, so profiles can be broken down by reconciler.
+type tracingReconciler struct {
+	kind string
+	reconcile.Reconciler
+}
+
+func withTracing(kind string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &tracingReconciler{kind: kind, Reconciler: r}
+}
+
+func (r *tracingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracer.Start(ctx, r.kind+".Reconcile", trace.WithAttributes(
+		attribute.String("k8s.object.kind", r.kind),
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("k8s.object.name", req.Name),
+	))
+	defer span.End()
+
+	var (
+		res ctrl.Result
+		err error
+	)
+	pprof.Do(ctx, pprof.Labels("reconciler", r.kind), func(ctx context.Context) {
+		res, err = r.Reconciler.Reconcile(ctx, req)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return res, err
+}
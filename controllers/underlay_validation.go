@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var underlayMismatch = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "metalnet_underlay_mismatch",
+	Help: "Whether the underlay routes reported by dpservice are outside the underlay prefix of the node (1) or not (0).",
+})
+
+func init() {
+	metrics.Registry.MustRegister(underlayMismatch)
+}
+
+// UnderlayValidatorOptions are the options of an UnderlayValidator.
+type UnderlayValidatorOptions struct {
+	// Prefix is the underlay prefix of the node, i.e. its loopback or uplink address together with the
+	// prefix length dpservice derives its underlay routes from.
+	Prefix netip.Prefix
+	// Interval is the interval the underlay routes are validated at. Defaults to one minute.
+	Interval time.Duration
+}
+
+// UnderlayValidator validates that the underlay routes dpservice reports for its interfaces are within the
+// underlay prefix of the node.
+//
+// metalnet announces the underlay routes of dpservice as next hops. If dpservice runs with a different
+// underlay address than the node, these next hops are unreachable, so the readiness check fails and a
+// warning event is recorded for the node while they do not match.
+type UnderlayValidator struct {
+	dpdk     dpdkclient.Client
+	recorder record.EventRecorder
+	nodeName string
+	opts     UnderlayValidatorOptions
+	log      logr.Logger
+
+	mu  sync.Mutex
+	err error
+}
+
+func NewUnderlayValidator(dpdk dpdkclient.Client, recorder record.EventRecorder, nodeName string, opts UnderlayValidatorOptions) *UnderlayValidator {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	return &UnderlayValidator{
+		dpdk:     dpdk,
+		recorder: recorder,
+		nodeName: nodeName,
+		opts:     opts,
+		log:      ctrl.Log.WithName("underlay-validation"),
+	}
+}
+
+// Start validates the underlay routes on startup and then periodically. It implements manager.Runnable.
+func (v *UnderlayValidator) Start(ctx context.Context) error {
+	v.log.Info("Validating underlay routes", "Prefix", v.opts.Prefix)
+	ticker := time.NewTicker(v.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		v.setErr(v.Validate(ctx))
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every metalnet instance validates its own node.
+func (v *UnderlayValidator) NeedLeaderElection() bool {
+	return false
+}
+
+// Validate checks the underlay routes of the dpservice interfaces against the underlay prefix of the node.
+func (v *UnderlayValidator) Validate(ctx context.Context) error {
+	ifaces, err := v.dpdk.ListInterfaces(ctx)
+	if err != nil {
+		v.log.Error(err, "Error listing dpdk interfaces")
+		// Whether dpservice is reachable is covered by the health check.
+		return nil
+	}
+
+	for _, iface := range ifaces.Items {
+		if route := iface.Spec.UnderlayRoute; route != nil && !v.opts.Prefix.Contains(*route) {
+			return fmt.Errorf("underlay route %s of interface %s is not in the underlay prefix %s of the node",
+				route, iface.ID, v.opts.Prefix)
+		}
+	}
+	return nil
+}
+
+func (v *UnderlayValidator) setErr(err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch {
+	case err != nil && v.err == nil:
+		v.log.Error(err, "Underlay routes of dpservice do not match the node")
+		v.recorder.Eventf(v.nodeRef(), corev1.EventTypeWarning, "UnderlayMismatch",
+			"Announced next hops are unreachable: %v", err)
+		underlayMismatch.Set(1)
+	case err == nil && v.err != nil:
+		v.log.Info("Underlay routes of dpservice match the node again")
+		v.recorder.Event(v.nodeRef(), corev1.EventTypeNormal, "UnderlayMatch", "Underlay routes of dpservice match the node")
+		underlayMismatch.Set(0)
+	}
+	v.err = err
+}
+
+func (v *UnderlayValidator) nodeRef() *corev1.ObjectReference {
+	return &corev1.ObjectReference{Kind: "Node", Name: v.nodeName, UID: types.UID(v.nodeName)}
+}
+
+// Checker is a healthz.Checker failing while the underlay routes do not match the node.
+func (v *UnderlayValidator) Checker(_ *http.Request) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.err
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Underlay validation", Label("underlay"), func() {
+	It("should fail readiness and record an event while the underlay routes do not match the node", func(ctx SpecContext) {
+		// The simulator allocates the underlay routes following the given prefix. The first one is the last
+		// address of the underlay prefix of the node, all following ones are outside of it.
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{
+			UnderlayPrefix: netip.MustParsePrefix("fc00:1::ffff:ffff:ffff:fffe/127"),
+		}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		createInterface := func(id, device, ip string) {
+			GinkgoHelper()
+			addr := netip.MustParseAddr(ip)
+			_, err := dpdkClient.CreateInterface(ctx, &dpdk.Interface{
+				InterfaceMeta: dpdk.InterfaceMeta{ID: id},
+				Spec:          dpdk.InterfaceSpec{VNI: 100, Device: device, IPv4: &addr},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		v := NewUnderlayValidator(dpdkClient, recorder, "node", UnderlayValidatorOptions{
+			Prefix: netip.MustParsePrefix("fc00:1::/64"),
+		})
+
+		createInterface("a", "net_tap4", "10.0.0.1")
+		v.setErr(v.Validate(ctx))
+		Expect(v.Checker(nil)).To(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+
+		createInterface("b", "net_tap5", "10.0.0.2")
+		v.setErr(v.Validate(ctx))
+		Expect(v.Checker(nil)).To(MatchError(ContainSubstring("underlay route fc00:1:0:1:: of interface b")))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning UnderlayMismatch")))
+
+		v.setErr(v.Validate(ctx))
+		Expect(recorder.Events).To(BeEmpty())
+
+		_, err = dpdkClient.DeleteInterface(ctx, "b")
+		Expect(err).NotTo(HaveOccurred())
+		v.setErr(v.Validate(ctx))
+		Expect(v.Checker(nil)).To(Succeed())
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal UnderlayMatch")))
+	})
+})
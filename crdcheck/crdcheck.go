@@ -0,0 +1,254 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package crdcheck compares the CustomResourceDefinitions installed in the cluster with the ones metalnet was
+// built with, so partial upgrades are detected before objects are written with fields the API server drops.
+package crdcheck
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Mode defines how metalnet handles incompatible CustomResourceDefinitions.
+type Mode string
+
+const (
+	// ModeWarn logs the incompatibilities and starts as usual.
+	ModeWarn Mode = "warn"
+	// ModeStrict refuses to start.
+	ModeStrict Mode = "strict"
+	// ModeReadOnly starts without writing any objects, see client.NewReadOnlyClient.
+	ModeReadOnly Mode = "read-only"
+)
+
+// Incompatibility is a difference between an installed CustomResourceDefinition and the expected one.
+type Incompatibility struct {
+	// CRD is the name of the CustomResourceDefinition.
+	CRD string
+	// Version is the incompatible version. Empty if the whole CustomResourceDefinition is affected.
+	Version string
+	// Field is the path of the missing field. Empty if the whole version is affected.
+	Field string
+	// Reason describes the incompatibility.
+	Reason string
+}
+
+func (i Incompatibility) String() string {
+	var b strings.Builder
+	b.WriteString(i.CRD)
+	if i.Version != "" {
+		b.WriteString(" ")
+		b.WriteString(i.Version)
+	}
+	if i.Field != "" {
+		b.WriteString(" ")
+		b.WriteString(i.Field)
+	}
+	b.WriteString(": ")
+	b.WriteString(i.Reason)
+	return b.String()
+}
+
+// LoadCRDs reads the CustomResourceDefinitions from the files of fsys matching the given pattern.
+func LoadCRDs(fsys fs.FS, pattern string) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	crds := make([]apiextensionsv1.CustomResourceDefinition, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := yaml.Unmarshal(data, &crd); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", name, err)
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// Checker compares the installed CustomResourceDefinitions with the expected ones.
+type Checker struct {
+	// Discovery reports the versions served by the API server.
+	Discovery discovery.ServerResourcesInterface
+	// Reader reads the installed CustomResourceDefinitions to compare their fields. If it is not allowed to, only
+	// the served versions are compared.
+	Reader client.Reader
+}
+
+// Check returns the incompatibilities of the installed CustomResourceDefinitions with the expected ones, sorted
+// by CustomResourceDefinition, version and field.
+func (c *Checker) Check(ctx context.Context, expected []apiextensionsv1.CustomResourceDefinition) ([]Incompatibility, error) {
+	var incompatibilities []Incompatibility
+	for i := range expected {
+		crd := &expected[i]
+		crdIncompatibilities, err := c.checkCRD(ctx, crd)
+		if err != nil {
+			return nil, fmt.Errorf("error checking %s: %w", crd.Name, err)
+		}
+		incompatibilities = append(incompatibilities, crdIncompatibilities...)
+	}
+	sort.Slice(incompatibilities, func(i, j int) bool {
+		a, b := incompatibilities[i], incompatibilities[j]
+		if a.CRD != b.CRD {
+			return a.CRD < b.CRD
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Field < b.Field
+	})
+	return incompatibilities, nil
+}
+
+func (c *Checker) checkCRD(ctx context.Context, expected *apiextensionsv1.CustomResourceDefinition) ([]Incompatibility, error) {
+	var (
+		incompatibilities []Incompatibility
+		servedVersions    int
+	)
+	for _, version := range expected.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+		servedVersions++
+		served, err := c.isServed(expected.Spec.Group, version.Name, expected.Spec.Names.Plural)
+		if err != nil {
+			return nil, err
+		}
+		if !served {
+			incompatibilities = append(incompatibilities, Incompatibility{
+				CRD:     expected.Name,
+				Version: version.Name,
+				Reason:  "version is not served",
+			})
+		}
+	}
+	if len(incompatibilities) == servedVersions {
+		// The CustomResourceDefinition is most likely not installed at all.
+		return incompatibilities, nil
+	}
+
+	installed := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Reader.Get(ctx, client.ObjectKey{Name: expected.Name}, installed); err != nil {
+		switch {
+		case apierrors.IsNotFound(err):
+			return append(incompatibilities, Incompatibility{CRD: expected.Name, Reason: "not installed"}), nil
+		case apierrors.IsForbidden(err):
+			return incompatibilities, nil
+		default:
+			return nil, err
+		}
+	}
+	return append(incompatibilities, compareCRDs(expected, installed)...), nil
+}
+
+func (c *Checker) isServed(group, version, plural string) (bool, error) {
+	resources, err := c.Discovery.ServerResourcesForGroupVersion(group + "/" + version)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return slices.ContainsFunc(resources.APIResources, func(resource metav1.APIResource) bool {
+		return resource.Name == plural
+	}), nil
+}
+
+// compareCRDs returns the versions and fields of the expected CustomResourceDefinition missing in the installed
+// one. Additional versions and fields of the installed one are compatible.
+func compareCRDs(expected, installed *apiextensionsv1.CustomResourceDefinition) []Incompatibility {
+	var incompatibilities []Incompatibility
+	for _, version := range expected.Spec.Versions {
+		idx := slices.IndexFunc(installed.Spec.Versions, func(v apiextensionsv1.CustomResourceDefinitionVersion) bool {
+			return v.Name == version.Name
+		})
+		if idx < 0 {
+			// Missing served versions are reported by discovery.
+			continue
+		}
+		installedVersion := installed.Spec.Versions[idx]
+		if version.Storage && !installedVersion.Storage {
+			incompatibilities = append(incompatibilities, Incompatibility{
+				CRD:     expected.Name,
+				Version: version.Name,
+				Reason:  "version is not the storage version",
+			})
+		}
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil ||
+			installedVersion.Schema == nil || installedVersion.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		for _, field := range missingFields("", version.Schema.OpenAPIV3Schema, installedVersion.Schema.OpenAPIV3Schema) {
+			incompatibilities = append(incompatibilities, Incompatibility{
+				CRD:     expected.Name,
+				Version: version.Name,
+				Field:   field,
+				Reason:  "field is not defined, it would be dropped",
+			})
+		}
+	}
+	return incompatibilities
+}
+
+// missingFields returns the paths of the fields of the expected schema missing in the installed schema. Fields
+// below a missing field are not reported.
+func missingFields(path string, expected, installed *apiextensionsv1.JSONSchemaProps) []string {
+	if installed == nil {
+		return []string{path}
+	}
+	if installed.XPreserveUnknownFields != nil && *installed.XPreserveUnknownFields {
+		return nil
+	}
+
+	var missing []string
+	for name, expectedProp := range expected.Properties {
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if fieldPath == "metadata" {
+			continue
+		}
+		installedProp, ok := installed.Properties[name]
+		if !ok {
+			missing = append(missing, fieldPath)
+			continue
+		}
+		missing = append(missing, missingFields(fieldPath, &expectedProp, &installedProp)...)
+	}
+	if expected.Items != nil && expected.Items.Schema != nil {
+		var installedItems *apiextensionsv1.JSONSchemaProps
+		if installed.Items != nil {
+			installedItems = installed.Items.Schema
+		}
+		missing = append(missing, missingFields(path+"[]", expected.Items.Schema, installedItems)...)
+	}
+	if expected.AdditionalProperties != nil && expected.AdditionalProperties.Schema != nil {
+		var installedValues *apiextensionsv1.JSONSchemaProps
+		if installed.AdditionalProperties != nil {
+			installedValues = installed.AdditionalProperties.Schema
+		}
+		if installed.AdditionalProperties == nil || installed.AdditionalProperties.Schema != nil {
+			missing = append(missing, missingFields(path+"{}", expected.AdditionalProperties.Schema, installedValues)...)
+		}
+	}
+	return missing
+}
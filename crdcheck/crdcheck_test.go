@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package crdcheck_test
+
+import (
+	"os"
+	"slices"
+
+	"github.com/ironcore-dev/metalnet/crdcheck"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Checker", func() {
+	var expected []apiextensionsv1.CustomResourceDefinition
+
+	BeforeEach(func() {
+		var err error
+		expected, err = crdcheck.LoadCRDs(os.DirFS("../config/crd/bases"), "*.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expected).NotTo(BeEmpty())
+	})
+
+	// newChecker returns a Checker for a cluster with the given CustomResourceDefinitions installed.
+	newChecker := func(installed []apiextensionsv1.CustomResourceDefinition) *crdcheck.Checker {
+		resources := map[string]*metav1.APIResourceList{}
+		objs := make([]client.Object, len(installed))
+		for i := range installed {
+			crd := &installed[i]
+			objs[i] = crd
+			for _, version := range crd.Spec.Versions {
+				if !version.Served {
+					continue
+				}
+				groupVersion := crd.Spec.Group + "/" + version.Name
+				if resources[groupVersion] == nil {
+					resources[groupVersion] = &metav1.APIResourceList{GroupVersion: groupVersion}
+				}
+				resources[groupVersion].APIResources = append(resources[groupVersion].APIResources,
+					metav1.APIResource{Name: crd.Spec.Names.Plural})
+			}
+		}
+		discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		for _, list := range resources {
+			discovery.Resources = append(discovery.Resources, list)
+		}
+
+		s := runtime.NewScheme()
+		Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
+		return &crdcheck.Checker{
+			Discovery: discovery,
+			Reader:    fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		}
+	}
+
+	// copyExpected returns deep copies of the expected CustomResourceDefinitions except the ones with the given names.
+	copyExpected := func(except ...string) []apiextensionsv1.CustomResourceDefinition {
+		var crds []apiextensionsv1.CustomResourceDefinition
+		for _, crd := range expected {
+			if !slices.Contains(except, crd.Name) {
+				crds = append(crds, *crd.DeepCopy())
+			}
+		}
+		return crds
+	}
+
+	It("should report no incompatibilities for the expected definitions", func(ctx SpecContext) {
+		installed := copyExpected()
+		Expect(newChecker(installed).Check(ctx, expected)).To(BeEmpty())
+	})
+
+	It("should report missing fields", func(ctx SpecContext) {
+		installed := copyExpected()
+		for i := range installed {
+			crd := &installed[i]
+			if crd.Name != "servicechains.networking.metalnet.ironcore.dev" {
+				continue
+			}
+			for _, version := range crd.Spec.Versions {
+				spec := version.Schema.OpenAPIV3Schema.Properties["spec"]
+				delete(spec.Properties, "hops")
+				version.Schema.OpenAPIV3Schema.Properties["spec"] = spec
+			}
+		}
+
+		Expect(newChecker(installed).Check(ctx, expected)).To(Equal([]crdcheck.Incompatibility{
+			{
+				CRD:     "servicechains.networking.metalnet.ironcore.dev",
+				Version: "v1alpha1",
+				Field:   "spec.hops",
+				Reason:  "field is not defined, it would be dropped",
+			},
+		}))
+	})
+
+	It("should report definitions that are not installed", func(ctx SpecContext) {
+		installed := copyExpected("servicechains.networking.metalnet.ironcore.dev")
+
+		incompatibilities, err := newChecker(installed).Check(ctx, expected)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(incompatibilities).To(HaveLen(1))
+		Expect(incompatibilities[0].String()).To(Equal("servicechains.networking.metalnet.ironcore.dev v1alpha1: version is not served"))
+	})
+
+	It("should accept fields preserved as unknown fields", func(ctx SpecContext) {
+		installed := copyExpected()
+		for i := range installed {
+			crd := &installed[i]
+			if crd.Name != "servicechains.networking.metalnet.ironcore.dev" {
+				continue
+			}
+			for _, version := range crd.Spec.Versions {
+				version.Schema.OpenAPIV3Schema.Properties["spec"] = apiextensionsv1.JSONSchemaProps{
+					Type:                   "object",
+					XPreserveUnknownFields: ptr.To(true),
+				}
+			}
+		}
+
+		Expect(newChecker(installed).Check(ctx, expected)).To(BeEmpty())
+	})
+})
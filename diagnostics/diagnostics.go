@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package diagnostics helps to find leaks and hangs of long-running metalnet instances in the field: it serves
+// the pprof endpoints, logs runtime stats periodically and dumps the goroutine stacks on a signal.
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// workqueueDepthMetric is the metric controller-runtime reports the length of the reconcile queues in.
+const workqueueDepthMetric = "workqueue_depth"
+
+// PprofHandler returns the handler serving the pprof endpoints below /debug/pprof/.
+func PprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// PprofServer serves the pprof endpoints. The reconciles carry the pprof label reconciler, so CPU and goroutine
+// profiles can be broken down by reconciler.
+type PprofServer struct {
+	addr string
+	log  logr.Logger
+}
+
+// NewPprofServer returns a server serving the pprof endpoints on the given address.
+func NewPprofServer(addr string) *PprofServer {
+	return &PprofServer{
+		addr: addr,
+		log:  ctrl.Log.WithName("pprof"),
+	}
+}
+
+// Start serves the pprof endpoints until the context is done.
+func (s *PprofServer) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", s.addr, err)
+	}
+
+	// Profiles and traces take as long as requested, so there is no write timeout.
+	srv := &http.Server{Handler: PprofHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.log.Error(err, "Error shutting down pprof server")
+		}
+	}()
+
+	s.log.Info("Serving pprof endpoints", "Address", lis.Addr().String())
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving pprof endpoints: %w", err)
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every metalnet instance is diagnosed by itself.
+func (s *PprofServer) NeedLeaderElection() bool {
+	return false
+}
+
+// RuntimeStats are the runtime stats of metalnet.
+type RuntimeStats struct {
+	Goroutines int
+	// HeapAlloc is the number of bytes of allocated heap objects.
+	HeapAlloc uint64
+	// HeapObjects is the number of allocated heap objects.
+	HeapObjects uint64
+	// Sys is the number of bytes obtained from the operating system.
+	Sys uint64
+	// NumGC is the number of completed GC cycles.
+	NumGC uint32
+	// QueueLengths are the lengths of the reconcile queues by controller name.
+	QueueLengths map[string]float64
+}
+
+// ReadRuntimeStats returns the current runtime stats. The queue lengths are read from the given gatherer.
+func ReadRuntimeStats(gatherer prometheus.Gatherer) (RuntimeStats, error) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	stats := RuntimeStats{
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    memStats.HeapAlloc,
+		HeapObjects:  memStats.HeapObjects,
+		Sys:          memStats.Sys,
+		NumGC:        memStats.NumGC,
+		QueueLengths: map[string]float64{},
+	}
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		return stats, fmt.Errorf("error gathering metrics: %w", err)
+	}
+	for _, family := range families {
+		if family.GetName() != workqueueDepthMetric {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" {
+					stats.QueueLengths[label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return stats, nil
+}
+
+// RuntimeStatsLogger logs the runtime stats periodically, so leaks show up in the logs of long-running instances.
+type RuntimeStatsLogger struct {
+	gatherer prometheus.Gatherer
+	interval time.Duration
+	log      logr.Logger
+}
+
+// NewRuntimeStatsLogger returns a logger logging the runtime stats at the given interval. The queue lengths are
+// read from the given gatherer.
+func NewRuntimeStatsLogger(gatherer prometheus.Gatherer, interval time.Duration) *RuntimeStatsLogger {
+	return &RuntimeStatsLogger{
+		gatherer: gatherer,
+		interval: interval,
+		log:      ctrl.Log.WithName("runtime-stats"),
+	}
+}
+
+// Start logs the runtime stats until the context is done.
+func (l *RuntimeStatsLogger) Start(ctx context.Context) error {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			l.logStats()
+		}
+	}
+}
+
+func (l *RuntimeStatsLogger) logStats() {
+	stats, err := ReadRuntimeStats(l.gatherer)
+	if err != nil {
+		l.log.Error(err, "Error reading queue lengths")
+	}
+	l.log.Info("Runtime stats",
+		"Goroutines", stats.Goroutines,
+		"HeapAlloc", stats.HeapAlloc,
+		"HeapObjects", stats.HeapObjects,
+		"Sys", stats.Sys,
+		"NumGC", stats.NumGC,
+		"QueueLengths", stats.QueueLengths,
+	)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every metalnet instance is diagnosed by itself.
+func (l *RuntimeStatsLogger) NeedLeaderElection() bool {
+	return false
+}
+
+// DumpStacksOnSignal writes the stacks of all goroutines to w whenever one of the given signals is received,
+// until the context is done. Unlike the default handling of SIGQUIT, the process keeps running. The signals are
+// handled once DumpStacksOnSignal returns.
+func DumpStacksOnSignal(ctx context.Context, log logr.Logger, w io.Writer, sigs ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-ch:
+				log.Info("Dumping goroutine stacks", "Signal", sig.String())
+				if err := runtimepprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+					log.Error(err, "Error dumping goroutine stacks")
+				}
+			}
+		}
+	}()
+}
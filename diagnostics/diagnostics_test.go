@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package diagnostics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"syscall"
+
+	"github.com/go-logr/logr"
+	. "github.com/ironcore-dev/metalnet/diagnostics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// syncBuffer is a buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+var _ = Describe("Diagnostics", func() {
+	It("should serve the pprof endpoints", func() {
+		srv := httptest.NewServer(PprofHandler())
+		DeferCleanup(srv.Close)
+
+		res, err := http.Get(srv.URL + "/debug/pprof/goroutine?debug=1")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = res.Body.Close() }()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should read the reconcile queue lengths", func() {
+		registry := prometheus.NewRegistry()
+		depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "workqueue_depth"}, []string{"name"})
+		registry.MustRegister(depth)
+		depth.WithLabelValues("networkinterface").Set(3)
+		depth.WithLabelValues("network").Set(0)
+
+		stats, err := ReadRuntimeStats(registry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Goroutines).To(BeNumerically(">", 0))
+		Expect(stats.HeapAlloc).To(BeNumerically(">", 0))
+		Expect(stats.QueueLengths).To(Equal(map[string]float64{"networkinterface": 3, "network": 0}))
+	})
+
+	It("should dump the goroutine stacks on a signal", func(ctx SpecContext) {
+		var buf syncBuffer
+		DumpStacksOnSignal(ctx, logr.Discard(), &buf, syscall.SIGUSR1)
+
+		Expect(syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)).To(Succeed())
+		Eventually(buf.String).Should(ContainSubstring("goroutine "))
+	})
+})
@@ -0,0 +1,311 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var cacheReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "metalnet_dpservice_cache_reads_total",
+	Help: "Number of dpservice reads served by the dpservice read cache, by kind and result (hit or miss).",
+}, []string{"kind", "result"})
+
+func init() {
+	metrics.Registry.MustRegister(cacheReads)
+}
+
+type CachingClientOptions struct {
+	// TTL is the maximum age of a cached object. Reads of older objects go to dpservice again,
+	// so state changed outside of metalnet is picked up with the next resync. Defaults to 1 minute.
+	TTL time.Duration
+}
+
+type cacheEntry[T any] struct {
+	value    T
+	storedAt time.Time
+}
+
+// cacheRead tracks the reads of a key in flight. Its generation is increased whenever the key is
+// invalidated, so reads that started before are not cached.
+type cacheRead struct {
+	readers    int
+	generation uint64
+}
+
+type cacheStore[T any] struct {
+	kind    string
+	entries map[string]cacheEntry[T]
+	reads   map[string]*cacheRead
+}
+
+func newCacheStore[T any](kind string) *cacheStore[T] {
+	return &cacheStore[T]{kind: kind, entries: make(map[string]cacheEntry[T]), reads: make(map[string]*cacheRead)}
+}
+
+// CachingClient is a write-through cache of the dpservice state read by the reconcilers.
+//
+// Successful reads of interfaces, virtual ips, NATs, prefixes, load balancer prefixes, firewall rules and
+// load balancers are cached per interface (or load balancer) and served from the cache until they are older
+// than the TTL. Every write goes to dpservice and drops the cached state it touches, whether it succeeded
+// or not, so the next read sees what dpservice actually programmed. Reads overlapping with such an
+// invalidation are not cached. Absent objects are never cached.
+//
+// Callers must not modify the returned objects.
+type CachingClient struct {
+	dpdkclient.Client
+	ttl time.Duration
+
+	mu            sync.Mutex
+	interfaces    *cacheStore[dpdk.Interface]
+	virtualIPs    *cacheStore[dpdk.VirtualIP]
+	nats          *cacheStore[dpdk.Nat]
+	prefixes      *cacheStore[dpdk.PrefixList]
+	lbPrefixes    *cacheStore[dpdk.PrefixList]
+	firewallRules *cacheStore[dpdk.FirewallRuleList]
+	loadBalancers *cacheStore[dpdk.LoadBalancer]
+}
+
+// NewCachingClient wraps the given client with a write-through read cache.
+func NewCachingClient(c dpdkclient.Client, opts CachingClientOptions) *CachingClient {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return &CachingClient{
+		Client:        c,
+		ttl:           ttl,
+		interfaces:    newCacheStore[dpdk.Interface]("interface"),
+		virtualIPs:    newCacheStore[dpdk.VirtualIP]("virtualip"),
+		nats:          newCacheStore[dpdk.Nat]("nat"),
+		prefixes:      newCacheStore[dpdk.PrefixList]("prefix"),
+		lbPrefixes:    newCacheStore[dpdk.PrefixList]("loadbalancerprefix"),
+		firewallRules: newCacheStore[dpdk.FirewallRuleList]("firewallrule"),
+		loadBalancers: newCacheStore[dpdk.LoadBalancer]("loadbalancer"),
+	}
+}
+
+// Invalidate drops all cached state, e.g. after dpservice restarted or drift was detected.
+func (c *CachingClient) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interfaces.reset()
+	c.virtualIPs.reset()
+	c.nats.reset()
+	c.prefixes.reset()
+	c.lbPrefixes.reset()
+	c.firewallRules.reset()
+	c.loadBalancers.reset()
+}
+
+// InvalidateInterface drops all cached state of the interface with the given ID.
+func (c *CachingClient) InvalidateInterface(interfaceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interfaces.drop(interfaceID)
+	c.virtualIPs.drop(interfaceID)
+	c.nats.drop(interfaceID)
+	c.prefixes.drop(interfaceID)
+	c.lbPrefixes.drop(interfaceID)
+	c.firewallRules.drop(interfaceID)
+}
+
+func (s *cacheStore[T]) reset() {
+	s.entries = make(map[string]cacheEntry[T])
+	for _, read := range s.reads {
+		read.generation++
+	}
+}
+
+func (s *cacheStore[T]) drop(key string) {
+	delete(s.entries, key)
+	if read, ok := s.reads[key]; ok {
+		read.generation++
+	}
+}
+
+// getCached returns the cached object. If there is none, it registers a read of the key and returns the
+// generation to pass to finishRead.
+func getCached[T any](c *CachingClient, s *cacheStore[T], key string) (T, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := s.entries[key]
+	if ok && time.Since(entry.storedAt) > c.ttl {
+		delete(s.entries, key)
+		ok = false
+	}
+	if !ok {
+		cacheReads.WithLabelValues(s.kind, "miss").Inc()
+		read, ok := s.reads[key]
+		if !ok {
+			read = &cacheRead{}
+			s.reads[key] = read
+		}
+		read.readers++
+		var zero T
+		return zero, false, read.generation
+	}
+	cacheReads.WithLabelValues(s.kind, "hit").Inc()
+	return entry.value, true, 0
+}
+
+// finishRead caches the object read, unless it is nil or the key was invalidated since the read started.
+func finishRead[T any](c *CachingClient, s *cacheStore[T], key string, generation uint64, value *T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	read := s.reads[key]
+	read.readers--
+	if read.readers == 0 {
+		delete(s.reads, key)
+	}
+	if value != nil && read.generation == generation {
+		s.entries[key] = cacheEntry[T]{value: *value, storedAt: time.Now()}
+	}
+}
+
+func dropCached[T any](c *CachingClient, s *cacheStore[T], key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s.drop(key)
+}
+
+// readThrough serves the object from the cache or reads and caches it. Only successful reads
+// of existing objects are cached, so ignored errors (e.g. NOT_FOUND) always go to dpservice.
+func readThrough[T any, PT interface {
+	*T
+	GetStatus() dpdk.Status
+}](c *CachingClient, s *cacheStore[T], key string, read func() (PT, error)) (PT, error) {
+	value, ok, generation := getCached(c, s, key)
+	if ok {
+		return &value, nil
+	}
+	res, err := read()
+	if err != nil || res == nil || res.GetStatus().Code != 0 {
+		finishRead[T](c, s, key, generation, nil)
+		return res, err
+	}
+	finishRead(c, s, key, generation, (*T)(res))
+	return res, nil
+}
+
+func (c *CachingClient) GetInterface(ctx context.Context, id string, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	return readThrough(c, c.interfaces, id, func() (*dpdk.Interface, error) {
+		return c.Client.GetInterface(ctx, id, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreateInterface(ctx context.Context, iface *dpdk.Interface, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	// The wrapped client may replace a drifted interface, so all state cached for it is stale afterwards.
+	defer c.InvalidateInterface(iface.ID)
+	return c.Client.CreateInterface(ctx, iface, ignoredErrors...)
+}
+
+func (c *CachingClient) DeleteInterface(ctx context.Context, id string, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	defer c.InvalidateInterface(id)
+	return c.Client.DeleteInterface(ctx, id, ignoredErrors...)
+}
+
+func (c *CachingClient) GetVirtualIP(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.VirtualIP, error) {
+	return readThrough(c, c.virtualIPs, interfaceID, func() (*dpdk.VirtualIP, error) {
+		return c.Client.GetVirtualIP(ctx, interfaceID, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreateVirtualIP(ctx context.Context, virtualIP *dpdk.VirtualIP, ignoredErrors ...[]uint32) (*dpdk.VirtualIP, error) {
+	defer dropCached(c, c.virtualIPs, virtualIP.InterfaceID)
+	return c.Client.CreateVirtualIP(ctx, virtualIP, ignoredErrors...)
+}
+
+func (c *CachingClient) DeleteVirtualIP(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.VirtualIP, error) {
+	defer dropCached(c, c.virtualIPs, interfaceID)
+	return c.Client.DeleteVirtualIP(ctx, interfaceID, ignoredErrors...)
+}
+
+func (c *CachingClient) GetNat(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.Nat, error) {
+	return readThrough(c, c.nats, interfaceID, func() (*dpdk.Nat, error) {
+		return c.Client.GetNat(ctx, interfaceID, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreateNat(ctx context.Context, nat *dpdk.Nat, ignoredErrors ...[]uint32) (*dpdk.Nat, error) {
+	defer dropCached(c, c.nats, nat.InterfaceID)
+	return c.Client.CreateNat(ctx, nat, ignoredErrors...)
+}
+
+func (c *CachingClient) DeleteNat(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.Nat, error) {
+	defer dropCached(c, c.nats, interfaceID)
+	return c.Client.DeleteNat(ctx, interfaceID, ignoredErrors...)
+}
+
+func (c *CachingClient) ListPrefixes(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.PrefixList, error) {
+	return readThrough(c, c.prefixes, interfaceID, func() (*dpdk.PrefixList, error) {
+		return c.Client.ListPrefixes(ctx, interfaceID, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreatePrefix(ctx context.Context, prefix *dpdk.Prefix, ignoredErrors ...[]uint32) (*dpdk.Prefix, error) {
+	defer dropCached(c, c.prefixes, prefix.InterfaceID)
+	return c.Client.CreatePrefix(ctx, prefix, ignoredErrors...)
+}
+
+func (c *CachingClient) DeletePrefix(ctx context.Context, interfaceID string, prefix *netip.Prefix, ignoredErrors ...[]uint32) (*dpdk.Prefix, error) {
+	defer dropCached(c, c.prefixes, interfaceID)
+	return c.Client.DeletePrefix(ctx, interfaceID, prefix, ignoredErrors...)
+}
+
+func (c *CachingClient) ListLoadBalancerPrefixes(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.PrefixList, error) {
+	return readThrough(c, c.lbPrefixes, interfaceID, func() (*dpdk.PrefixList, error) {
+		return c.Client.ListLoadBalancerPrefixes(ctx, interfaceID, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreateLoadBalancerPrefix(ctx context.Context, prefix *dpdk.LoadBalancerPrefix, ignoredErrors ...[]uint32) (*dpdk.LoadBalancerPrefix, error) {
+	defer dropCached(c, c.lbPrefixes, prefix.InterfaceID)
+	return c.Client.CreateLoadBalancerPrefix(ctx, prefix, ignoredErrors...)
+}
+
+func (c *CachingClient) DeleteLoadBalancerPrefix(ctx context.Context, interfaceID string, prefix *netip.Prefix, ignoredErrors ...[]uint32) (*dpdk.LoadBalancerPrefix, error) {
+	defer dropCached(c, c.lbPrefixes, interfaceID)
+	return c.Client.DeleteLoadBalancerPrefix(ctx, interfaceID, prefix, ignoredErrors...)
+}
+
+func (c *CachingClient) ListFirewallRules(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.FirewallRuleList, error) {
+	return readThrough(c, c.firewallRules, interfaceID, func() (*dpdk.FirewallRuleList, error) {
+		return c.Client.ListFirewallRules(ctx, interfaceID, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreateFirewallRule(ctx context.Context, fwRule *dpdk.FirewallRule, ignoredErrors ...[]uint32) (*dpdk.FirewallRule, error) {
+	defer dropCached(c, c.firewallRules, fwRule.InterfaceID)
+	return c.Client.CreateFirewallRule(ctx, fwRule, ignoredErrors...)
+}
+
+func (c *CachingClient) DeleteFirewallRule(ctx context.Context, interfaceID string, ruleID string, ignoredErrors ...[]uint32) (*dpdk.FirewallRule, error) {
+	defer dropCached(c, c.firewallRules, interfaceID)
+	return c.Client.DeleteFirewallRule(ctx, interfaceID, ruleID, ignoredErrors...)
+}
+
+func (c *CachingClient) GetLoadBalancer(ctx context.Context, id string, ignoredErrors ...[]uint32) (*dpdk.LoadBalancer, error) {
+	return readThrough(c, c.loadBalancers, id, func() (*dpdk.LoadBalancer, error) {
+		return c.Client.GetLoadBalancer(ctx, id, ignoredErrors...)
+	})
+}
+
+func (c *CachingClient) CreateLoadBalancer(ctx context.Context, lb *dpdk.LoadBalancer, ignoredErrors ...[]uint32) (*dpdk.LoadBalancer, error) {
+	defer dropCached(c, c.loadBalancers, lb.ID)
+	return c.Client.CreateLoadBalancer(ctx, lb, ignoredErrors...)
+}
+
+func (c *CachingClient) DeleteLoadBalancer(ctx context.Context, id string, ignoredErrors ...[]uint32) (*dpdk.LoadBalancer, error) {
+	defer dropCached(c, c.loadBalancers, id)
+	return c.Client.DeleteLoadBalancer(ctx, id, ignoredErrors...)
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+	"time"
+
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachingClient", func() {
+	var (
+		ctx  context.Context
+		fake *fakeClient
+		c    *CachingClient
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = newFakeClient()
+		c = NewCachingClient(fake, CachingClientOptions{TTL: time.Minute})
+	})
+
+	It("should serve repeated reads from the cache", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+
+		for i := 0; i < 3; i++ {
+			iface, err := c.GetInterface(ctx, "iface")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(iface.Spec.VNI).To(Equal(uint32(1)))
+		}
+		Expect(fake.calls).To(Equal([]string{"GetInterface"}))
+	})
+
+	It("should not cache absent objects", func() {
+		for i := 0; i < 2; i++ {
+			_, err := c.GetInterface(ctx, "iface", dpdkerrors.Ignore(dpdkerrors.NOT_FOUND))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(fake.calls).To(Equal([]string{"GetInterface", "GetInterface"}))
+	})
+
+	It("should drop the cached state on writes", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+		_, err := c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = c.DeleteInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.GetInterface(ctx, "iface")
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)).To(BeTrue())
+
+		_, err = c.CreateInterface(ctx, newInterface(2, "10.0.0.2"))
+		Expect(err).NotTo(HaveOccurred())
+		iface, err := c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(2)))
+
+		Expect(fake.calls).To(Equal([]string{"GetInterface", "DeleteInterface", "GetInterface", "CreateInterface", "GetInterface"}))
+	})
+
+	It("should read again after invalidation", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+		_, err := c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+
+		fake.interfaces["iface"] = *newInterface(3, "10.0.0.1")
+		c.Invalidate()
+		iface, err := c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(3)))
+	})
+
+	It("should not cache reads overlapping with an invalidation", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+		fake.afterGet = func() {
+			fake.afterGet = nil
+			fake.interfaces["iface"] = *newInterface(3, "10.0.0.1")
+			c.InvalidateInterface("iface")
+		}
+		iface, err := c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(1)))
+
+		iface, err = c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(3)))
+		Expect(fake.calls).To(Equal([]string{"GetInterface", "GetInterface"}))
+	})
+
+	It("should read again once the cached state expired", func() {
+		c = NewCachingClient(fake, CachingClientOptions{TTL: 10 * time.Millisecond})
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+		_, err := c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = c.GetInterface(ctx, "iface")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.calls).To(Equal([]string{"GetInterface", "GetInterface"}))
+	})
+})
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var capacityErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "metalnet_dpservice_capacity_errors_total",
+	Help: "Number of dpservice writes refused because a dpservice table is full, by table.",
+}, []string{"table"})
+
+func init() {
+	metrics.Registry.MustRegister(capacityErrors)
+}
+
+// IsCapacityError reports whether dpservice refused a write because one of its tables is full.
+func IsCapacityError(err error) bool {
+	return dpdkerrors.IsStatusErrorCode(err, dpdkerrors.OUT_OF_MEMORY, dpdkerrors.LIMIT_REACHED)
+}
+
+// CapacityClient counts the writes refused by dpservice because a table is full, by table.
+type CapacityClient struct {
+	dpdkclient.Client
+}
+
+// NewCapacityClient wraps the given client with capacity error accounting.
+func NewCapacityClient(c dpdkclient.Client) *CapacityClient {
+	return &CapacityClient{Client: c}
+}
+
+func observeCapacity(table string, err error) {
+	if IsCapacityError(err) {
+		capacityErrors.WithLabelValues(table).Inc()
+	}
+}
+
+func (c *CapacityClient) CreateLoadBalancer(ctx context.Context, lb *dpdk.LoadBalancer, ignoredErrors ...[]uint32) (*dpdk.LoadBalancer, error) {
+	res, err := c.Client.CreateLoadBalancer(ctx, lb, ignoredErrors...)
+	observeCapacity("load_balancer", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateLoadBalancerPrefix(ctx context.Context, prefix *dpdk.LoadBalancerPrefix, ignoredErrors ...[]uint32) (*dpdk.LoadBalancerPrefix, error) {
+	res, err := c.Client.CreateLoadBalancerPrefix(ctx, prefix, ignoredErrors...)
+	observeCapacity("load_balancer_prefix", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateLoadBalancerTarget(ctx context.Context, target *dpdk.LoadBalancerTarget, ignoredErrors ...[]uint32) (*dpdk.LoadBalancerTarget, error) {
+	res, err := c.Client.CreateLoadBalancerTarget(ctx, target, ignoredErrors...)
+	observeCapacity("load_balancer_target", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateInterface(ctx context.Context, iface *dpdk.Interface, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	res, err := c.Client.CreateInterface(ctx, iface, ignoredErrors...)
+	observeCapacity("interface", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateVirtualIP(ctx context.Context, virtualIP *dpdk.VirtualIP, ignoredErrors ...[]uint32) (*dpdk.VirtualIP, error) {
+	res, err := c.Client.CreateVirtualIP(ctx, virtualIP, ignoredErrors...)
+	observeCapacity("virtual_ip", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreatePrefix(ctx context.Context, prefix *dpdk.Prefix, ignoredErrors ...[]uint32) (*dpdk.Prefix, error) {
+	res, err := c.Client.CreatePrefix(ctx, prefix, ignoredErrors...)
+	observeCapacity("prefix", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateRoute(ctx context.Context, route *dpdk.Route, ignoredErrors ...[]uint32) (*dpdk.Route, error) {
+	res, err := c.Client.CreateRoute(ctx, route, ignoredErrors...)
+	observeCapacity("route", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateNat(ctx context.Context, nat *dpdk.Nat, ignoredErrors ...[]uint32) (*dpdk.Nat, error) {
+	res, err := c.Client.CreateNat(ctx, nat, ignoredErrors...)
+	observeCapacity("nat", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateNeighborNat(ctx context.Context, nat *dpdk.NeighborNat, ignoredErrors ...[]uint32) (*dpdk.NeighborNat, error) {
+	res, err := c.Client.CreateNeighborNat(ctx, nat, ignoredErrors...)
+	observeCapacity("neighbor_nat", err)
+	return res, err
+}
+
+func (c *CapacityClient) CreateFirewallRule(ctx context.Context, rule *dpdk.FirewallRule, ignoredErrors ...[]uint32) (*dpdk.FirewallRule, error) {
+	res, err := c.Client.CreateFirewallRule(ctx, rule, ignoredErrors...)
+	observeCapacity("firewall_rule", err)
+	return res, err
+}
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"errors"
+
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"github.com/ironcore-dev/metalnet/chaos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chaosExemptMethods are the methods metalnet needs to start up and report its health, which are never failed.
+var chaosExemptMethods = map[string]bool{
+	dpdkproto.DPDKironcore_CheckInitialized_FullMethodName: true,
+	dpdkproto.DPDKironcore_Initialize_FullMethodName:       true,
+	dpdkproto.DPDKironcore_GetVersion_FullMethodName:       true,
+}
+
+// ChaosUnaryClientInterceptor injects the faults of the given Injector into the unary calls to dpservice.
+// Injected faults are returned as Unavailable errors.
+func ChaosUnaryClientInterceptor(injector *chaos.Injector) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if chaosExemptMethods[method] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		err := injector.Do(ctx, "dpservice", method, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+		if errors.Is(err, chaos.ErrInjected) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		return err
+	}
+}
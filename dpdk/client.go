@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dpdk contains wrappers around the dpservice client that add behavior
+// metalnet relies on but dpservice itself does not provide.
+package dpdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+type idempotentClient struct {
+	dpdkclient.Client
+}
+
+// NewIdempotentClient wraps the given client so that CreateInterface first looks up
+// an interface with the same ID and only creates it when missing. An existing interface
+// matching the requested spec is returned as-is, a drifted one is deleted and recreated.
+func NewIdempotentClient(c dpdkclient.Client) dpdkclient.Client {
+	return &idempotentClient{c}
+}
+
+func (c *idempotentClient) CreateInterface(ctx context.Context, iface *dpdk.Interface, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	existing, err := c.Client.GetInterface(ctx, iface.ID)
+	if err != nil {
+		if !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND) {
+			return existing, fmt.Errorf("error getting interface: %w", err)
+		}
+		return c.Client.CreateInterface(ctx, iface, ignoredErrors...)
+	}
+
+	if !InterfaceSpecDrifted(&existing.Spec, &iface.Spec) {
+		return existing, nil
+	}
+
+	if _, err := c.Client.DeleteInterface(ctx, iface.ID, dpdkerrors.Ignore(dpdkerrors.NOT_FOUND)); err != nil {
+		return existing, fmt.Errorf("error deleting drifted interface: %w", err)
+	}
+	return c.Client.CreateInterface(ctx, iface, ignoredErrors...)
+}
+
+// InterfaceSpecDrifted reports whether the actual interface spec reported by dpservice
+// differs from the desired one in any field that can only be changed by recreating
+// the interface (VNI, primary IPs and device).
+func InterfaceSpecDrifted(actual, desired *dpdk.InterfaceSpec) bool {
+	if actual.VNI != desired.VNI {
+		return true
+	}
+	if !equalAddrPtrs(actual.IPv4, desired.IPv4) || !equalAddrPtrs(actual.IPv6, desired.IPv6) {
+		return true
+	}
+	// dpservice may not report the device for every interface type, so only
+	// compare it if both sides know about it.
+	if actual.Device != "" && desired.Device != "" && actual.Device != desired.Device {
+		return true
+	}
+	return false
+}
+
+// InterfaceIPsDrifted reports whether the actual interface spec differs from the desired one in its
+// primary IPs only. dpservice cannot change the primary IPs of an interface, but such an interface can
+// be replaced in the same VNI on the same device.
+func InterfaceIPsDrifted(actual, desired *dpdk.InterfaceSpec) bool {
+	if actual.VNI != desired.VNI {
+		return false
+	}
+	if actual.Device != "" && desired.Device != "" && actual.Device != desired.Device {
+		return false
+	}
+	return !equalAddrPtrs(actual.IPv4, desired.IPv4) || !equalAddrPtrs(actual.IPv6, desired.IPv6)
+}
+
+// FirewallRuleSpecDrifted reports whether the actual firewall rule spec reported by dpservice
+// differs from the desired one. Firewall rules cannot be updated, a drifted rule has to be
+// deleted and recreated.
+func FirewallRuleSpecDrifted(actual, desired *dpdk.FirewallRuleSpec) bool {
+	if !strings.EqualFold(actual.TrafficDirection, desired.TrafficDirection) ||
+		firewallRuleAccepts(actual.FirewallAction) != firewallRuleAccepts(desired.FirewallAction) ||
+		actual.Priority != desired.Priority {
+		return true
+	}
+	if !equalPrefixPtrs(actual.SourcePrefix, desired.SourcePrefix) || !equalPrefixPtrs(actual.DestinationPrefix, desired.DestinationPrefix) {
+		return true
+	}
+	return !proto.Equal(protocolFilterOrEmpty(actual.ProtocolFilter), protocolFilterOrEmpty(desired.ProtocolFilter))
+}
+
+func protocolFilterOrEmpty(filter *dpdkproto.ProtocolFilter) *dpdkproto.ProtocolFilter {
+	if filter == nil {
+		return &dpdkproto.ProtocolFilter{}
+	}
+	return filter
+}
+
+// firewallRuleAccepts reports whether the given action accepts traffic. dpservice reports
+// denying rules with the Drop action.
+func firewallRuleAccepts(action string) bool {
+	switch strings.ToLower(action) {
+	case "accept", "allow":
+		return true
+	default:
+		return false
+	}
+}
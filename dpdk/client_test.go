@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+	"net/netip"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newInterface(vni uint32, ipv4 string) *dpdk.Interface {
+	ip := netip.MustParseAddr(ipv4)
+	ipv6 := netip.MustParseAddr("::")
+	underlayRoute := netip.MustParseAddr("fc00::1")
+	return &dpdk.Interface{
+		InterfaceMeta: dpdk.InterfaceMeta{ID: "iface"},
+		Spec: dpdk.InterfaceSpec{
+			VNI:           vni,
+			Device:        "net_tap4",
+			IPv4:          &ip,
+			IPv6:          &ipv6,
+			UnderlayRoute: &underlayRoute,
+		},
+	}
+}
+
+var _ = Describe("IdempotentClient", func() {
+	var (
+		ctx  context.Context
+		fake *fakeClient
+		c    dpdkclient.Client
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = newFakeClient()
+		c = NewIdempotentClient(fake)
+	})
+
+	It("should create a missing interface", func() {
+		iface, err := c.CreateInterface(ctx, newInterface(1, "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(1)))
+		Expect(fake.calls).To(Equal([]string{"GetInterface", "CreateInterface"}))
+	})
+
+	It("should return an existing interface with matching spec", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+
+		_, err := c.CreateInterface(ctx, newInterface(1, "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.calls).To(Equal([]string{"GetInterface"}))
+	})
+
+	It("should recreate a drifted interface", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+
+		iface, err := c.CreateInterface(ctx, newInterface(2, "10.0.0.2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(2)))
+		Expect(fake.calls).To(Equal([]string{"GetInterface", "DeleteInterface", "CreateInterface"}))
+		Expect(*fake.interfaces["iface"].Spec.IPv4).To(Equal(netip.MustParseAddr("10.0.0.2")))
+	})
+})
+
+var _ = Describe("InterfaceSpecDrifted", func() {
+	It("should ignore an unknown device", func() {
+		actual := newInterface(1, "10.0.0.1")
+		actual.Spec.Device = ""
+		Expect(InterfaceSpecDrifted(&actual.Spec, &newInterface(1, "10.0.0.1").Spec)).To(BeFalse())
+	})
+
+	It("should treat a missing and an unspecified ip as equal", func() {
+		actual := newInterface(1, "10.0.0.1")
+		desired := newInterface(1, "10.0.0.1")
+		desired.Spec.IPv6 = nil
+		Expect(InterfaceSpecDrifted(&actual.Spec, &desired.Spec)).To(BeFalse())
+	})
+
+	It("should detect a changed device", func() {
+		desired := newInterface(1, "10.0.0.1")
+		desired.Spec.Device = "net_tap5"
+		Expect(InterfaceSpecDrifted(&newInterface(1, "10.0.0.1").Spec, &desired.Spec)).To(BeTrue())
+	})
+})
+
+var _ = Describe("InterfaceIPsDrifted", func() {
+	It("should detect changed primary ips", func() {
+		Expect(InterfaceIPsDrifted(&newInterface(1, "10.0.0.1").Spec, &newInterface(1, "10.0.0.2").Spec)).To(BeTrue())
+		Expect(InterfaceIPsDrifted(&newInterface(1, "10.0.0.1").Spec, &newInterface(1, "10.0.0.1").Spec)).To(BeFalse())
+	})
+
+	It("should not report an interface moving to another vni or device", func() {
+		Expect(InterfaceIPsDrifted(&newInterface(1, "10.0.0.1").Spec, &newInterface(2, "10.0.0.2").Spec)).To(BeFalse())
+
+		desired := newInterface(1, "10.0.0.2")
+		desired.Spec.Device = "net_tap5"
+		Expect(InterfaceIPsDrifted(&newInterface(1, "10.0.0.1").Spec, &desired.Spec)).To(BeFalse())
+	})
+})
+
+var _ = Describe("FirewallRuleSpecDrifted", func() {
+	newFirewallRule := func(action string) *dpdk.FirewallRuleSpec {
+		prefix := netip.MustParsePrefix("0.0.0.0/0")
+		return &dpdk.FirewallRuleSpec{
+			RuleID:            "rule",
+			TrafficDirection:  "Ingress",
+			FirewallAction:    action,
+			Priority:          1000,
+			SourcePrefix:      &prefix,
+			DestinationPrefix: &prefix,
+		}
+	}
+
+	It("should treat the actions reported by dpservice as equal to the requested ones", func() {
+		Expect(FirewallRuleSpecDrifted(newFirewallRule("Drop"), newFirewallRule("Deny"))).To(BeFalse())
+		Expect(FirewallRuleSpecDrifted(newFirewallRule("Accept"), newFirewallRule("Accept"))).To(BeFalse())
+	})
+
+	It("should detect a changed action, prefix or protocol filter", func() {
+		Expect(FirewallRuleSpecDrifted(newFirewallRule("Drop"), newFirewallRule("Accept"))).To(BeTrue())
+
+		desired := newFirewallRule("Accept")
+		prefix := netip.MustParsePrefix("10.0.0.0/8")
+		desired.SourcePrefix = &prefix
+		Expect(FirewallRuleSpecDrifted(newFirewallRule("Accept"), desired)).To(BeTrue())
+
+		desired = newFirewallRule("Accept")
+		desired.ProtocolFilter = &dpdkproto.ProtocolFilter{Filter: &dpdkproto.ProtocolFilter_Tcp{Tcp: &dpdkproto.TcpFilter{DstPortLower: 80}}}
+		Expect(FirewallRuleSpecDrifted(newFirewallRule("Accept"), desired)).To(BeTrue())
+	})
+})
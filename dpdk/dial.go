@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// UnixAddressPrefix prefixes the addresses of dpservice unix domain sockets, e.g. unix:///var/run/dpservice.sock.
+const UnixAddressPrefix = "unix://"
+
+// Dial connects to the dpservice gRPC API at the given address. The address is either a host:port
+// or the absolute path of a unix domain socket prefixed with UnixAddressPrefix. A socket has to be
+// readable and writable by metalnet and must not be writable by everyone, as anyone able to write
+// to it can program dpservice. The host of a host:port is resolved through DNS, and resolved again whenever
+// the connection breaks, so dpservice can move to another address.
+func Dial(ctx context.Context, address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	target := address
+	if path, ok := strings.CutPrefix(address, UnixAddressPrefix); ok {
+		if err := checkSocket(path); err != nil {
+			return nil, err
+		}
+	} else if !strings.Contains(address, "://") {
+		target = dnsScheme + address
+	}
+
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	return grpc.DialContext(ctx, target, opts...)
+}
+
+// dnsScheme makes gRPC resolve the target through DNS instead of passing it to the dialer as is.
+const dnsScheme = "dns:///"
+
+// DialRetryOptions are the options of DialWithRetry.
+type DialRetryOptions struct {
+	// Timeout is the timeout of a single connection attempt.
+	Timeout time.Duration
+	// Retries is the number of attempts made after the first one failed. Negative retries until the context
+	// is done.
+	Retries int
+	// Interval is the time waited between two attempts.
+	Interval time.Duration
+	// OnRetry is called with the error of every failed attempt that is retried.
+	OnRetry func(attempt int, err error)
+}
+
+// DialWithRetry connects to the dpservice gRPC API at the given address like Dial, blocking until the
+// connection is established. Failed attempts, e.g. while dpservice is still starting, are retried.
+func DialWithRetry(ctx context.Context, address string, opts DialRetryOptions, dialOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	dialOpts = append(dialOpts, grpc.WithBlock())
+	for attempt := 1; ; attempt++ {
+		conn, err := dialAttempt(ctx, address, opts.Timeout, dialOpts)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if opts.Retries >= 0 && attempt > opts.Retries {
+			return nil, fmt.Errorf("error connecting to dpservice at %s after %d attempt(s): %w", address, attempt, err)
+		}
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt, err)
+		}
+
+		timer := time.NewTimer(opts.Interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func dialAttempt(ctx context.Context, address string, timeout time.Duration, dialOpts []grpc.DialOption) (*grpc.ClientConn, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return Dial(ctx, address, dialOpts...)
+}
+
+func checkSocket(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("socket path %s is not absolute", path)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("error stat-ing socket %s: %w", path, err)
+	}
+	if stat.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s is not a socket", path)
+	}
+	if perm := stat.Mode().Perm(); perm&0002 != 0 {
+		return fmt.Errorf("socket %s must not be writable by everyone (mode %s)", path, perm)
+	}
+	if err := unix.Access(path, unix.R_OK|unix.W_OK); err != nil {
+		return fmt.Errorf("socket %s is not accessible: %w", path, err)
+	}
+	return nil
+}
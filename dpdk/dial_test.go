@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Dial", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		// Unix socket paths are limited in length, so the directory has to be short.
+		dir, err = os.MkdirTemp("", "dpdk")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+	})
+
+	listen := func(perm os.FileMode) string {
+		path := filepath.Join(dir, "dpservice.sock")
+		listener, err := net.Listen("unix", path)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(listener.Close)
+		Expect(os.Chmod(path, perm)).To(Succeed())
+		return path
+	}
+
+	It("should connect to a unix domain socket", func() {
+		path := listen(0660)
+
+		conn, err := Dial(context.TODO(), UnixAddressPrefix+path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("should refuse sockets writable by everyone", func() {
+		path := listen(0666)
+
+		_, err := Dial(context.TODO(), UnixAddressPrefix+path)
+		Expect(err).To(MatchError(ContainSubstring("writable by everyone")))
+	})
+
+	It("should refuse paths that are no sockets", func() {
+		path := filepath.Join(dir, "dpservice.sock")
+		Expect(os.WriteFile(path, nil, 0600)).To(Succeed())
+
+		_, err := Dial(context.TODO(), UnixAddressPrefix+path)
+		Expect(err).To(MatchError(ContainSubstring("is not a socket")))
+
+		_, err = Dial(context.TODO(), UnixAddressPrefix+"dpservice.sock")
+		Expect(err).To(MatchError(ContainSubstring("is not absolute")))
+	})
+
+	It("should retry connecting until dpservice is listening", func() {
+		path := filepath.Join(dir, "dpservice.sock")
+		var attempts int
+		opts := DialRetryOptions{
+			Timeout:  100 * time.Millisecond,
+			Retries:  -1,
+			Interval: 10 * time.Millisecond,
+			OnRetry: func(attempt int, _ error) {
+				attempts = attempt
+				if attempt == 3 {
+					listener, err := net.Listen("unix", path)
+					Expect(err).NotTo(HaveOccurred())
+					srv := grpc.NewServer()
+					go func() { _ = srv.Serve(listener) }()
+					DeferCleanup(srv.Stop)
+				}
+			},
+		}
+
+		conn, err := DialWithRetry(context.TODO(), UnixAddressPrefix+path, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Close()).To(Succeed())
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("should give up after the configured retries", func() {
+		var attempts int
+		opts := DialRetryOptions{
+			Timeout:  100 * time.Millisecond,
+			Retries:  2,
+			Interval: 10 * time.Millisecond,
+			OnRetry:  func(attempt int, _ error) { attempts = attempt },
+		}
+
+		_, err := DialWithRetry(context.TODO(), UnixAddressPrefix+filepath.Join(dir, "dpservice.sock"), opts)
+		Expect(err).To(MatchError(ContainSubstring("after 3 attempt(s)")))
+		Expect(attempts).To(Equal(2))
+	})
+})
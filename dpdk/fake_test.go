@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+)
+
+// fakeClient implements the interface calls of dpdkclient.Client in memory.
+// Calling any other method panics.
+type fakeClient struct {
+	dpdkclient.Client
+
+	interfaces map[string]dpdk.Interface
+	calls      []string
+	// afterGet is called by GetInterface after reading the interface, if set.
+	afterGet func()
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{interfaces: make(map[string]dpdk.Interface)}
+}
+
+func ignored(err *dpdkerrors.StatusError, ignoredErrors [][]uint32) error {
+	if len(ignoredErrors) > 0 {
+		for _, code := range ignoredErrors[0] {
+			if code == err.ErrorCode() {
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+func (c *fakeClient) GetInterface(_ context.Context, id string, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	c.calls = append(c.calls, "GetInterface")
+	iface, ok := c.interfaces[id]
+	if c.afterGet != nil {
+		c.afterGet()
+	}
+	if !ok {
+		// Like dpservice-go, report the status of an ignored error in the returned object.
+		return &dpdk.Interface{
+			InterfaceMeta: dpdk.InterfaceMeta{ID: id},
+			Status:        dpdk.Status{Code: dpdkerrors.NOT_FOUND, Message: "not found"},
+		}, ignored(dpdkerrors.NewStatusError(dpdkerrors.NOT_FOUND, "not found"), ignoredErrors)
+	}
+	return &iface, nil
+}
+
+func (c *fakeClient) CreateInterface(_ context.Context, iface *dpdk.Interface, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	c.calls = append(c.calls, "CreateInterface")
+	if _, ok := c.interfaces[iface.ID]; ok {
+		return &dpdk.Interface{}, ignored(dpdkerrors.NewStatusError(dpdkerrors.ALREADY_EXISTS, "already exists"), ignoredErrors)
+	}
+	res := *iface
+	c.interfaces[iface.ID] = res
+	return &res, nil
+}
+
+func (c *fakeClient) DeleteInterface(_ context.Context, id string, ignoredErrors ...[]uint32) (*dpdk.Interface, error) {
+	c.calls = append(c.calls, "DeleteInterface")
+	iface, ok := c.interfaces[id]
+	if !ok {
+		return &dpdk.Interface{}, ignored(dpdkerrors.NewStatusError(dpdkerrors.NOT_FOUND, "not found"), ignoredErrors)
+	}
+	delete(c.interfaces, id)
+	return &iface, nil
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var connected = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "metalnet_dpservice_connected",
+	Help: "Whether the gRPC connection to dpservice is ready (1) or not (0).",
+})
+
+func init() {
+	metrics.Registry.MustRegister(connected)
+}
+
+// KeepaliveOptions are the keepalive options of the connection to dpservice.
+type KeepaliveOptions struct {
+	// Time is the interval keepalive pings are sent at while the connection is idle. gRPC sends at most one
+	// ping every ten seconds. Zero disables keepalive pings, dead connections are then only detected by the
+	// next failing call.
+	Time time.Duration
+	// Timeout is the time waited for the acknowledgement of a keepalive ping before the connection is closed.
+	Timeout time.Duration
+	// MaxIdle is the time without calls after which the connection is closed until the next call. Zero keeps
+	// the connection open.
+	MaxIdle time.Duration
+}
+
+// KeepaliveDialOptions returns the dial options applying the given keepalive options.
+func KeepaliveDialOptions(opts KeepaliveOptions) []grpc.DialOption {
+	var dialOpts []grpc.DialOption
+	if opts.Time > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    opts.Time,
+			Timeout: opts.Timeout,
+			// metalnet may not call dpservice for a long time, the connection has to be checked nevertheless.
+			PermitWithoutStream: true,
+		}))
+	}
+	if opts.MaxIdle > 0 {
+		dialOpts = append(dialOpts, grpc.WithIdleTimeout(opts.MaxIdle))
+	}
+	return dialOpts
+}
+
+// WatchConnection reports the state of the given connection to dpservice in the metalnet_dpservice_connected
+// metric until the context is done. If reconnect is true, a connection that was closed, e.g. because the
+// keepalive pings were not acknowledged, is reestablished right away instead of on the next call, so its
+// state reflects whether dpservice is reachable.
+func WatchConnection(ctx context.Context, log logr.Logger, conn *grpc.ClientConn, reconnect bool) {
+	state := conn.GetState()
+	for {
+		if state == connectivity.Ready {
+			connected.Set(1)
+		} else {
+			connected.Set(0)
+		}
+		if state == connectivity.Idle && reconnect {
+			conn.Connect()
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		newState := conn.GetState()
+		if newState == connectivity.TransientFailure || state == connectivity.Ready {
+			log.Info("Connection to dpservice changed state", "From", state, "To", newState)
+		} else {
+			log.V(1).Info("Connection to dpservice changed state", "From", state, "To", newState)
+		}
+		state = newState
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// dpserviceConnected returns the value of the metalnet_dpservice_connected metric.
+func dpserviceConnected() float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() == "metalnet_dpservice_connected" {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+var _ = Describe("WatchConnection", func() {
+	It("should report whether the connection to dpservice is ready", func(ctx SpecContext) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		srv := grpc.NewServer()
+		go func() { _ = srv.Serve(listener) }()
+		DeferCleanup(srv.Stop)
+
+		conn, err := Dial(ctx, listener.Addr().String(), KeepaliveDialOptions(KeepaliveOptions{
+			Time:    10 * time.Second,
+			Timeout: time.Second,
+		})...)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		go WatchConnection(ctx, logr.Discard(), conn, true)
+
+		By("connecting right away")
+		Eventually(dpserviceConnected).Should(Equal(1.0))
+
+		By("stopping dpservice")
+		srv.Stop()
+		Eventually(dpserviceConnected).Should(Equal(0.0))
+	})
+})
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultMaxListMessageSize is the default maximum size of a response received from dpservice, the default of
+	// gRPC. It bounds the memory a single list takes, e.g. the routes of a VNI with a very large route table.
+	DefaultMaxListMessageSize = 4 << 20
+)
+
+// ErrListTooLarge is returned if a list response of dpservice exceeds the maximum message size.
+var ErrListTooLarge = errors.New("dpservice list response exceeds the maximum message size")
+
+var listsTooLarge = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "metalnet_dpservice_lists_too_large_total",
+	Help: "Number of dpservice lists not received because the response exceeded the maximum message size, by kind.",
+}, []string{"kind"})
+
+func init() {
+	metrics.Registry.MustRegister(listsTooLarge)
+}
+
+// MaxMessageSizeDialOption returns the dial option limiting the size of the responses received from dpservice.
+func MaxMessageSizeDialOption(size int) grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(size))
+}
+
+// listError wraps an error of listing items of the given kind in ErrListTooLarge if the response exceeded the
+// maximum message size.
+func listError(kind string, err error) error {
+	if status.Code(err) != codes.ResourceExhausted {
+		return err
+	}
+	listsTooLarge.WithLabelValues(kind).Inc()
+	return fmt.Errorf("%w: %w", ErrListTooLarge, err)
+}
+
+// ListClient marks the errors of lists whose response exceeded the maximum message size by wrapping them in
+// ErrListTooLarge.
+//
+// dpservice cannot page its lists, so a list is always received and held in memory as a whole. Only the
+// maximum message size bounds that memory.
+type ListClient struct {
+	dpdkclient.Client
+}
+
+// NewListClient wraps the given client with list size error accounting.
+func NewListClient(c dpdkclient.Client) *ListClient {
+	return &ListClient{Client: c}
+}
+
+func (c *ListClient) ListRoutes(ctx context.Context, vni uint32, ignoredErrors ...[]uint32) (*dpdk.RouteList, error) {
+	res, err := c.Client.ListRoutes(ctx, vni, ignoredErrors...)
+	if err != nil {
+		return res, listError("routes", err)
+	}
+	return res, nil
+}
+
+func (c *ListClient) ListPrefixes(ctx context.Context, interfaceID string, ignoredErrors ...[]uint32) (*dpdk.PrefixList, error) {
+	res, err := c.Client.ListPrefixes(ctx, interfaceID, ignoredErrors...)
+	if err != nil {
+		return res, listError("prefixes", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ = Describe("ListClient", func() {
+	// newClient returns a client of a dpservice simulator holding n routes in VNI 100.
+	newClient := func(ctx context.Context, n int, opts ...grpc.DialOption) dpdkclient.Client {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet", append([]grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}, opts...)...)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		c := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		ip := netip.MustParseAddr("10.1.0.1")
+		_, err = c.CreateInterface(ctx, &dpdk.Interface{
+			InterfaceMeta: dpdk.InterfaceMeta{ID: "nic"},
+			Spec:          dpdk.InterfaceSpec{VNI: 100, Device: "net_tap4", IPv4: &ip},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		nextHop := netip.MustParseAddr("fc00:1::1")
+		for i := 0; i < n; i++ {
+			prefix := netip.PrefixFrom(netip.AddrFrom4([4]byte{10, 0, byte(i >> 8), byte(i)}), 32)
+			_, err := c.CreateRoute(ctx, &dpdk.Route{
+				RouteMeta: dpdk.RouteMeta{VNI: 100},
+				Spec:      dpdk.RouteSpec{Prefix: &prefix, NextHop: &dpdk.RouteNextHop{VNI: 100, IP: &nextHop}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return c
+	}
+
+	It("should list the routes", func(ctx SpecContext) {
+		c := NewListClient(newClient(ctx, 5))
+		routes, err := c.ListRoutes(ctx, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(routes.Items).To(HaveLen(5))
+	})
+
+	It("should report lists exceeding the maximum message size", func(ctx SpecContext) {
+		c := NewListClient(newClient(ctx, 100, MaxMessageSizeDialOption(1024)))
+		_, err := c.ListRoutes(ctx, 100)
+		Expect(err).To(MatchError(ErrListTooLarge))
+		Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+	})
+})
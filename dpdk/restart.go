@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// RestartDetector detects restarts of dpservice by comparing the id dpservice reports for its instance with
+// the id of the instance metalnet initialized.
+//
+// dpservice is checked whenever Check is called, e.g. by the health checks, and periodically while the
+// detector runs. OnRestart is called once when a restart is detected.
+type RestartDetector struct {
+	client    dpdkproto.DPDKironcoreClient
+	uuid      string
+	interval  time.Duration
+	onRestart func()
+	log       logr.Logger
+
+	once       sync.Once
+	mu         sync.Mutex
+	restartErr error
+}
+
+// NewRestartDetector detects restarts of the dpservice instance with the given id. The interval defaults to
+// 10 seconds.
+func NewRestartDetector(client dpdkproto.DPDKironcoreClient, uuid string, interval time.Duration, onRestart func()) *RestartDetector {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &RestartDetector{
+		client:    client,
+		uuid:      uuid,
+		interval:  interval,
+		onRestart: onRestart,
+		log:       ctrl.Log.WithName("dpservice-restart"),
+	}
+}
+
+// Check returns an error if dpservice is down or restarted.
+func (d *RestartDetector) Check(ctx context.Context) error {
+	d.mu.Lock()
+	restartErr := d.restartErr
+	d.mu.Unlock()
+	if restartErr != nil {
+		return restartErr
+	}
+
+	res, err := d.client.CheckInitialized(ctx, &dpdkproto.CheckInitializedRequest{})
+	if err != nil {
+		return fmt.Errorf("dp-service down: %w", err)
+	}
+	actualUUID := res.GetUuid()
+	if actualUUID == d.uuid {
+		return nil
+	}
+
+	d.once.Do(func() {
+		d.log.Info("Detected dpservice restart", "Expected", d.uuid, "Actual", actualUUID)
+		if d.onRestart != nil {
+			d.onRestart()
+		}
+	})
+	restartErr = fmt.Errorf("dp-service restart detected - %s | %s", d.uuid, actualUUID)
+	d.mu.Lock()
+	d.restartErr = restartErr
+	d.mu.Unlock()
+	return restartErr
+}
+
+// Start checks dpservice periodically until the context is done. It implements manager.Runnable.
+func (d *RestartDetector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := d.Check(ctx); err != nil {
+			d.log.V(1).Info("dpservice check failed", "Error", err)
+		}
+	}
+}
+
+func (d *RestartDetector) NeedLeaderElection() bool {
+	return false
+}
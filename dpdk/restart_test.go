@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+	"net"
+
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ = Describe("RestartDetector", func() {
+	var (
+		client dpdkproto.DPDKironcoreClient
+		uuid   string
+	)
+	BeforeEach(func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		client = dpdkproto.NewDPDKironcoreClient(conn)
+
+		res, err := client.Initialize(ctx, &dpdkproto.InitializeRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		uuid = res.GetUuid()
+	})
+
+	It("should pass while dpservice runs the initialized instance", func(ctx SpecContext) {
+		d := NewRestartDetector(client, uuid, 0, func() {
+			Fail("no restart expected")
+		})
+		Expect(d.Check(ctx)).To(Succeed())
+	})
+
+	It("should call back once when dpservice restarted", func(ctx SpecContext) {
+		var restarts int
+		d := NewRestartDetector(client, "previous-instance", 0, func() {
+			restarts++
+		})
+		Expect(d.Check(ctx)).To(MatchError(ContainSubstring("dp-service restart detected")))
+		Expect(d.Check(ctx)).To(MatchError(ContainSubstring("dp-service restart detected")))
+		Expect(restarts).To(Equal(1))
+	})
+})
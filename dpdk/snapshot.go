@@ -0,0 +1,322 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	"google.golang.org/grpc"
+)
+
+// Snapshot is the state of dpservice on a node.
+type Snapshot struct {
+	// Time is the time the snapshot was taken at.
+	Time time.Time `json:"time"`
+	// Interfaces are the interfaces and their state.
+	Interfaces []InterfaceSnapshot `json:"interfaces,omitempty"`
+	// LoadBalancers are the load balancers and their targets.
+	LoadBalancers []LoadBalancerSnapshot `json:"loadBalancers,omitempty"`
+	// Routes are the routes of the VNIs of the interfaces and load balancers and of the additional VNIs.
+	Routes []dpdk.Route `json:"routes,omitempty"`
+}
+
+// InterfaceSnapshot is the state of an interface.
+type InterfaceSnapshot struct {
+	Interface            dpdk.Interface      `json:"interface"`
+	VirtualIP            *dpdk.VirtualIP     `json:"virtualIP,omitempty"`
+	NAT                  *dpdk.Nat           `json:"nat,omitempty"`
+	Prefixes             []dpdk.Prefix       `json:"prefixes,omitempty"`
+	LoadBalancerPrefixes []dpdk.Prefix       `json:"loadBalancerPrefixes,omitempty"`
+	FirewallRules        []dpdk.FirewallRule `json:"firewallRules,omitempty"`
+}
+
+// LoadBalancerSnapshot is the state of a load balancer.
+type LoadBalancerSnapshot struct {
+	LoadBalancer dpdk.LoadBalancer         `json:"loadBalancer"`
+	Targets      []dpdk.LoadBalancerTarget `json:"targets,omitempty"`
+}
+
+type restoringKey struct{}
+
+// RestoreGate holds back the calls to dpservice while a snapshot is restored, so the reconcilers and the routes
+// received from metalbond do not write conflicting state in between. Calls already in flight complete before
+// the restore starts.
+type RestoreGate struct {
+	mu sync.RWMutex
+}
+
+// UnaryClientInterceptor returns an interceptor holding back the calls of a connection during restores.
+func (g *RestoreGate) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if ctx.Value(restoringKey{}) == nil {
+			g.mu.RLock()
+			defer g.mu.RUnlock()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// SnapshotOptions are the options of a Snapshotter.
+type SnapshotOptions struct {
+	// LoadBalancerIDs returns the ids of the load balancers to snapshot, as dpservice cannot list them.
+	LoadBalancerIDs func(ctx context.Context) ([]string, error)
+	// VNIs are the VNIs whose routes are snapshot in addition to the VNIs of the interfaces and load balancers,
+	// e.g. the public VNIs.
+	VNIs []uint32
+	// AllowRestore allows restoring snapshots via the handler.
+	AllowRestore bool
+	// OnRestored is called after a snapshot was restored, e.g. to invalidate caches of the dpservice state.
+	OnRestored func()
+}
+
+// Snapshotter takes snapshots of the dpservice state of a node and restores them, e.g. after a reinstallation
+// of dpservice. A nil Snapshotter serves no snapshots.
+type Snapshotter struct {
+	client dpdkclient.Client
+	gate   *RestoreGate
+	opts   SnapshotOptions
+}
+
+// NewSnapshotter creates a Snapshotter. The client has to be connected through a connection intercepted by the
+// given gate.
+func NewSnapshotter(client dpdkclient.Client, gate *RestoreGate, opts SnapshotOptions) *Snapshotter {
+	return &Snapshotter{client: client, gate: gate, opts: opts}
+}
+
+// Take takes a snapshot of the dpservice state.
+func (s *Snapshotter) Take(ctx context.Context) (*Snapshot, error) {
+	snapshot := &Snapshot{Time: time.Now()}
+	vnis := make(map[uint32]struct{})
+	for _, vni := range s.opts.VNIs {
+		vnis[vni] = struct{}{}
+	}
+
+	ifaces, err := s.client.ListInterfaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing interfaces: %w", err)
+	}
+	for _, iface := range ifaces.Items {
+		ifaceSnapshot, err := s.takeInterface(ctx, iface)
+		if err != nil {
+			return nil, fmt.Errorf("[interface %s] %w", iface.ID, err)
+		}
+		snapshot.Interfaces = append(snapshot.Interfaces, *ifaceSnapshot)
+		vnis[iface.Spec.VNI] = struct{}{}
+	}
+
+	if s.opts.LoadBalancerIDs != nil {
+		ids, err := s.opts.LoadBalancerIDs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting load balancer ids: %w", err)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			lb, err := s.client.GetLoadBalancer(ctx, id)
+			if err != nil {
+				if dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND) {
+					continue
+				}
+				return nil, fmt.Errorf("error getting load balancer %s: %w", id, err)
+			}
+			targets, err := s.client.ListLoadBalancerTargets(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("error listing targets of load balancer %s: %w", id, err)
+			}
+			snapshot.LoadBalancers = append(snapshot.LoadBalancers, LoadBalancerSnapshot{LoadBalancer: *lb, Targets: targets.Items})
+			vnis[lb.Spec.VNI] = struct{}{}
+		}
+	}
+
+	sortedVNIs := make([]uint32, 0, len(vnis))
+	for vni := range vnis {
+		sortedVNIs = append(sortedVNIs, vni)
+	}
+	sort.Slice(sortedVNIs, func(i, j int) bool { return sortedVNIs[i] < sortedVNIs[j] })
+	for _, vni := range sortedVNIs {
+		routes, err := s.client.ListRoutes(ctx, vni)
+		if err != nil {
+			return nil, fmt.Errorf("error listing routes of vni %d: %w", vni, err)
+		}
+		snapshot.Routes = append(snapshot.Routes, routes.Items...)
+	}
+	return snapshot, nil
+}
+
+func (s *Snapshotter) takeInterface(ctx context.Context, iface dpdk.Interface) (*InterfaceSnapshot, error) {
+	snapshot := &InterfaceSnapshot{Interface: iface}
+
+	virtualIP, err := s.client.GetVirtualIP(ctx, iface.ID)
+	switch {
+	case err == nil:
+		snapshot.VirtualIP = virtualIP
+	case !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_VM, dpdkerrors.SNAT_NO_DATA):
+		return nil, fmt.Errorf("error getting virtual ip: %w", err)
+	}
+	nat, err := s.client.GetNat(ctx, iface.ID)
+	switch {
+	case err == nil:
+		snapshot.NAT = nat
+	case !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_VM, dpdkerrors.SNAT_NO_DATA):
+		return nil, fmt.Errorf("error getting nat: %w", err)
+	}
+
+	prefixes, err := s.client.ListPrefixes(ctx, iface.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing prefixes: %w", err)
+	}
+	snapshot.Prefixes = prefixes.Items
+	lbPrefixes, err := s.client.ListLoadBalancerPrefixes(ctx, iface.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing load balancer prefixes: %w", err)
+	}
+	snapshot.LoadBalancerPrefixes = lbPrefixes.Items
+	rules, err := s.client.ListFirewallRules(ctx, iface.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing firewall rules: %w", err)
+	}
+	snapshot.FirewallRules = rules.Items
+	return snapshot, nil
+}
+
+// Restore programs the state of the snapshot into dpservice while holding back all other calls to dpservice.
+// Existing state is kept. Underlay routes are assigned by dpservice anew, the reconcilers announce them once
+// they run again.
+func (s *Snapshotter) Restore(ctx context.Context, snapshot *Snapshot) error {
+	s.gate.mu.Lock()
+	defer s.gate.mu.Unlock()
+	defer func() {
+		if s.opts.OnRestored != nil {
+			s.opts.OnRestored()
+		}
+	}()
+	ctx = context.WithValue(ctx, restoringKey{}, struct{}{})
+
+	exists := dpdkerrors.Ignore(dpdkerrors.ALREADY_EXISTS)
+	var errs []error
+	for _, ifaceSnapshot := range snapshot.Interfaces {
+		if err := s.restoreInterface(ctx, ifaceSnapshot); err != nil {
+			errs = append(errs, fmt.Errorf("[interface %s] %w", ifaceSnapshot.Interface.ID, err))
+		}
+	}
+
+	for _, lbSnapshot := range snapshot.LoadBalancers {
+		lb := lbSnapshot.LoadBalancer
+		lb.Spec.UnderlayRoute = nil
+		if _, err := s.client.CreateLoadBalancer(ctx, &lb, exists); err != nil {
+			errs = append(errs, fmt.Errorf("[load balancer %s] error creating load balancer: %w", lb.ID, err))
+			continue
+		}
+		for _, target := range lbSnapshot.Targets {
+			target := target
+			if _, err := s.client.CreateLoadBalancerTarget(ctx, &target, exists); err != nil {
+				errs = append(errs, fmt.Errorf("[load balancer %s] error creating target %s: %w", lb.ID, target.Spec.TargetIP, err))
+			}
+		}
+	}
+
+	for _, route := range snapshot.Routes {
+		route := route
+		if _, err := s.client.CreateRoute(ctx, &route, dpdkerrors.Ignore(dpdkerrors.ROUTE_EXISTS)); err != nil {
+			errs = append(errs, fmt.Errorf("[vni %d] error creating route %s: %w", route.VNI, route.Spec.Prefix, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Snapshotter) restoreInterface(ctx context.Context, snapshot InterfaceSnapshot) error {
+	exists := dpdkerrors.Ignore(dpdkerrors.ALREADY_EXISTS)
+	iface := snapshot.Interface
+	iface.Spec.UnderlayRoute = nil
+	if _, err := s.client.CreateInterface(ctx, &iface, exists); err != nil {
+		return fmt.Errorf("error creating interface: %w", err)
+	}
+
+	var errs []error
+	if snapshot.VirtualIP != nil {
+		virtualIP := *snapshot.VirtualIP
+		virtualIP.Spec.UnderlayRoute = nil
+		if _, err := s.client.CreateVirtualIP(ctx, &virtualIP, dpdkerrors.Ignore(dpdkerrors.ALREADY_EXISTS, dpdkerrors.SNAT_EXISTS)); err != nil {
+			errs = append(errs, fmt.Errorf("error creating virtual ip: %w", err))
+		}
+	}
+	if snapshot.NAT != nil {
+		nat := *snapshot.NAT
+		nat.Spec.UnderlayRoute = nil
+		if _, err := s.client.CreateNat(ctx, &nat, dpdkerrors.Ignore(dpdkerrors.ALREADY_EXISTS, dpdkerrors.SNAT_EXISTS)); err != nil {
+			errs = append(errs, fmt.Errorf("error creating nat: %w", err))
+		}
+	}
+	for _, prefix := range snapshot.Prefixes {
+		if _, err := s.client.CreatePrefix(ctx, &dpdk.Prefix{
+			PrefixMeta: dpdk.PrefixMeta{InterfaceID: iface.ID},
+			Spec:       dpdk.PrefixSpec{Prefix: prefix.Spec.Prefix},
+		}, exists); err != nil {
+			errs = append(errs, fmt.Errorf("error creating prefix %s: %w", prefix.Spec.Prefix, err))
+		}
+	}
+	for _, prefix := range snapshot.LoadBalancerPrefixes {
+		if _, err := s.client.CreateLoadBalancerPrefix(ctx, &dpdk.LoadBalancerPrefix{
+			LoadBalancerPrefixMeta: dpdk.LoadBalancerPrefixMeta{InterfaceID: iface.ID},
+			Spec:                   dpdk.LoadBalancerPrefixSpec{Prefix: prefix.Spec.Prefix},
+		}, exists); err != nil {
+			errs = append(errs, fmt.Errorf("error creating load balancer prefix %s: %w", prefix.Spec.Prefix, err))
+		}
+	}
+	for _, rule := range snapshot.FirewallRules {
+		rule := rule
+		if _, err := s.client.CreateFirewallRule(ctx, &rule, exists); err != nil {
+			errs = append(errs, fmt.Errorf("error creating firewall rule %s: %w", rule.Spec.RuleID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ServeHTTP serves a snapshot as JSON on GET and, if allowed, restores the snapshot sent as JSON on POST.
+func (s *Snapshotter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s == nil {
+		http.Error(w, "dpservice snapshots are not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		snapshot, err := s.Take(req.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error taking snapshot: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(snapshot)
+	case http.MethodPost:
+		if !s.opts.AllowRestore {
+			http.Error(w, "restoring dpservice snapshots is not enabled", http.StatusForbidden)
+			return
+		}
+		snapshot := &Snapshot{}
+		if err := json.NewDecoder(req.Body).Decode(snapshot); err != nil {
+			http.Error(w, fmt.Sprintf("error decoding snapshot: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := s.Restore(req.Context(), snapshot); err != nil {
+			http.Error(w, fmt.Sprintf("error restoring snapshot: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	. "github.com/ironcore-dev/metalnet/dpdk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// snapshotFakeClient extends fakeClient by the calls taking and restoring snapshots of interfaces without
+// virtual ip, nat, prefixes and firewall rules.
+type snapshotFakeClient struct {
+	*fakeClient
+
+	routes map[uint32][]dpdk.Route
+}
+
+func (c *snapshotFakeClient) ListInterfaces(context.Context, ...[]uint32) (*dpdk.InterfaceList, error) {
+	list := &dpdk.InterfaceList{}
+	for _, iface := range c.interfaces {
+		list.Items = append(list.Items, iface)
+	}
+	return list, nil
+}
+
+func (c *snapshotFakeClient) GetVirtualIP(context.Context, string, ...[]uint32) (*dpdk.VirtualIP, error) {
+	return &dpdk.VirtualIP{}, dpdkerrors.NewStatusError(dpdkerrors.SNAT_NO_DATA, "no data")
+}
+
+func (c *snapshotFakeClient) GetNat(context.Context, string, ...[]uint32) (*dpdk.Nat, error) {
+	return &dpdk.Nat{}, dpdkerrors.NewStatusError(dpdkerrors.SNAT_NO_DATA, "no data")
+}
+
+func (c *snapshotFakeClient) ListPrefixes(context.Context, string, ...[]uint32) (*dpdk.PrefixList, error) {
+	return &dpdk.PrefixList{}, nil
+}
+
+func (c *snapshotFakeClient) ListLoadBalancerPrefixes(context.Context, string, ...[]uint32) (*dpdk.PrefixList, error) {
+	return &dpdk.PrefixList{}, nil
+}
+
+func (c *snapshotFakeClient) ListFirewallRules(context.Context, string, ...[]uint32) (*dpdk.FirewallRuleList, error) {
+	return &dpdk.FirewallRuleList{}, nil
+}
+
+func (c *snapshotFakeClient) ListRoutes(_ context.Context, vni uint32, _ ...[]uint32) (*dpdk.RouteList, error) {
+	return &dpdk.RouteList{Items: c.routes[vni]}, nil
+}
+
+func (c *snapshotFakeClient) CreateRoute(_ context.Context, route *dpdk.Route, _ ...[]uint32) (*dpdk.Route, error) {
+	c.routes[route.VNI] = append(c.routes[route.VNI], *route)
+	return route, nil
+}
+
+var _ = Describe("Snapshotter", func() {
+	var (
+		ctx  context.Context
+		fake *snapshotFakeClient
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = &snapshotFakeClient{fakeClient: newFakeClient(), routes: make(map[uint32][]dpdk.Route)}
+	})
+
+	It("should restore a snapshot into a reinstalled dpservice", func() {
+		fake.interfaces["iface"] = *newInterface(1, "10.0.0.1")
+		prefix := netip.MustParsePrefix("10.0.1.0/24")
+		fake.routes[2] = []dpdk.Route{{
+			RouteMeta: dpdk.RouteMeta{VNI: 2},
+			Spec:      dpdk.RouteSpec{Prefix: &prefix, NextHop: &dpdk.RouteNextHop{VNI: 2, IP: &netip.Addr{}}},
+		}}
+		restored := 0
+		s := NewSnapshotter(fake, &RestoreGate{}, SnapshotOptions{VNIs: []uint32{2}, OnRestored: func() { restored++ }})
+
+		snapshot, err := s.Take(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Interfaces).To(HaveLen(1))
+		Expect(snapshot.Routes).To(HaveLen(1))
+
+		By("restoring the snapshot into an empty dpservice")
+		fake.interfaces = make(map[string]dpdk.Interface)
+		fake.routes = make(map[uint32][]dpdk.Route)
+		Expect(s.Restore(ctx, snapshot)).To(Succeed())
+		Expect(restored).To(Equal(1))
+		Expect(fake.interfaces).To(HaveKey("iface"))
+		Expect(fake.interfaces["iface"].Spec.UnderlayRoute).To(BeNil())
+		Expect(fake.routes[2]).To(HaveLen(1))
+
+		By("restoring the snapshot again")
+		Expect(s.Restore(ctx, snapshot)).To(Succeed())
+	})
+
+	It("should only restore snapshots via the handler if allowed", func() {
+		s := NewSnapshotter(fake, &RestoreGate{}, SnapshotOptions{})
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		body := rec.Body.String()
+
+		rec = httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+
+		rec = httptest.NewRecorder()
+		(*Snapshotter)(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+})
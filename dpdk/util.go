@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpdk
+
+import (
+	"net/netip"
+)
+
+func equalAddrPtrs(a, b *netip.Addr) bool {
+	switch {
+	case a == nil && b == nil:
+		return true
+	case a == nil:
+		return !b.IsValid() || b.IsUnspecified()
+	case b == nil:
+		return !a.IsValid() || a.IsUnspecified()
+	default:
+		return *a == *b
+	}
+}
+
+func equalPrefixPtrs(a, b *netip.Prefix) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
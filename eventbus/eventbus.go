@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package eventbus publishes the programming of network interfaces, virtual ips and load balancer targets
+// to a message bus, so external systems (e.g. inventory or billing) can track them without polling the
+// API server.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultSubjectPrefix is the default prefix of the subjects events are published to.
+	DefaultSubjectPrefix = "metalnet"
+	// DefaultBufferSize is the default number of events buffered while the bus is slow or unreachable.
+	DefaultBufferSize = 1024
+	// publishTimeout is the timeout of publishing a single event.
+	publishTimeout = 10 * time.Second
+)
+
+var (
+	eventsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metalnet_event_bus_events_dropped_total",
+		Help: "Number of events not published because the event buffer was full.",
+	})
+	publishErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metalnet_event_bus_publish_errors_total",
+		Help: "Number of events that could not be published to the event bus.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventsDropped, publishErrors)
+}
+
+// EventType is the type of an Event.
+type EventType string
+
+const (
+	// InterfaceProgrammed is published when a network interface was created in dpservice.
+	InterfaceProgrammed EventType = "InterfaceProgrammed"
+	// InterfaceRemoved is published when a network interface was removed from dpservice.
+	InterfaceRemoved EventType = "InterfaceRemoved"
+	// VirtualIPAnnounced is published when a virtual ip was programmed and its route applied.
+	VirtualIPAnnounced EventType = "VirtualIPAnnounced"
+	// VirtualIPWithdrawn is published when a virtual ip was removed together with its route.
+	VirtualIPWithdrawn EventType = "VirtualIPWithdrawn"
+	// LoadBalancerTargetAdded is published when a load balancer target was programmed and announced.
+	LoadBalancerTargetAdded EventType = "LoadBalancerTargetAdded"
+	// LoadBalancerTargetRemoved is published when a load balancer target was withdrawn and removed.
+	LoadBalancerTargetRemoved EventType = "LoadBalancerTargetRemoved"
+)
+
+// Event is a programming event of a network interface.
+type Event struct {
+	Type      EventType     `json:"type"`
+	Time      time.Time     `json:"time"`
+	Node      string        `json:"node"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	UID       types.UID     `json:"uid"`
+	VNI       uint32        `json:"vni,omitempty"`
+	IPs       []netip.Addr  `json:"ips,omitempty"`
+	Prefix    *netip.Prefix `json:"prefix,omitempty"`
+	// UnderlayRoute is the underlay address the traffic of the event's addresses is routed to.
+	UnderlayRoute *netip.Addr `json:"underlayRoute,omitempty"`
+}
+
+// Sink delivers the encoded events to a message bus.
+type Sink interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+	Close() error
+}
+
+// Options are the options of a Bus.
+type Options struct {
+	// NodeName is the node the events are published for.
+	NodeName string
+	// SubjectPrefix is the prefix of the subjects, the subject of an event is <prefix>.<node>.<type>.
+	// Defaults to DefaultSubjectPrefix.
+	SubjectPrefix string
+	// BufferSize is the number of events buffered. Defaults to DefaultBufferSize.
+	BufferSize int
+}
+
+// Bus publishes Events to a Sink.
+//
+// Events are buffered and published in the background, so reconciles never wait for the message bus.
+// If the buffer is full, events are dropped. A nil Bus discards all events.
+type Bus struct {
+	sink   Sink
+	opts   Options
+	events chan Event
+	log    logr.Logger
+}
+
+func NewBus(sink Sink, opts Options) *Bus {
+	if opts.SubjectPrefix == "" {
+		opts.SubjectPrefix = DefaultSubjectPrefix
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultBufferSize
+	}
+	return &Bus{
+		sink:   sink,
+		opts:   opts,
+		events: make(chan Event, opts.BufferSize),
+		log:    ctrl.Log.WithName("event-bus"),
+	}
+}
+
+// Publish queues the given event for publishing.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Node = b.opts.NodeName
+
+	select {
+	case b.events <- event:
+	default:
+		eventsDropped.Inc()
+		b.log.V(1).Info("Event buffer is full, dropping event", "Type", event.Type, "Namespace", event.Namespace, "Name", event.Name)
+	}
+}
+
+// Start publishes the queued events until the context is done. It implements manager.Runnable.
+func (b *Bus) Start(ctx context.Context) error {
+	defer func() {
+		if err := b.sink.Close(); err != nil {
+			b.log.Error(err, "Error closing event bus")
+		}
+	}()
+
+	for {
+		select {
+		case event := <-b.events:
+			b.publish(ctx, event)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every metalnet instance publishes the
+// events of its own node.
+func (b *Bus) NeedLeaderElection() bool {
+	return false
+}
+
+func (b *Bus) publish(ctx context.Context, event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		b.log.Error(err, "Error encoding event", "Type", event.Type)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	if err := b.sink.Publish(ctx, b.subject(event), data); err != nil {
+		publishErrors.Inc()
+		b.log.Error(err, "Error publishing event", "Type", event.Type, "Namespace", event.Namespace, "Name", event.Name)
+	}
+}
+
+func (b *Bus) subject(event Event) string {
+	return b.opts.SubjectPrefix + "." + b.opts.NodeName + "." + string(event.Type)
+}
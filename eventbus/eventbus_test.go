@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package eventbus_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"sync"
+
+	"github.com/ironcore-dev/metalnet/eventbus"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type message struct {
+	Subject string
+	Event   eventbus.Event
+}
+
+type recordingSink struct {
+	mu       sync.Mutex
+	messages []message
+	closed   bool
+}
+
+func (s *recordingSink) Publish(_ context.Context, subject string, data []byte) error {
+	var event eventbus.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, message{Subject: subject, Event: event})
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingSink) Messages() []message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]message(nil), s.messages...)
+}
+
+func (s *recordingSink) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+var _ = Describe("Bus", func() {
+	It("should publish the events of the node to the sink", func() {
+		sink := &recordingSink{}
+		bus := eventbus.NewBus(sink, eventbus.Options{NodeName: "node"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(bus.Start(ctx)).To(Succeed())
+		}()
+
+		prefix := netip.MustParsePrefix("10.0.0.1/32")
+		bus.Publish(eventbus.Event{Type: eventbus.LoadBalancerTargetAdded, Namespace: "default", Name: "nic", VNI: 100, Prefix: &prefix})
+
+		Eventually(sink.Messages).Should(ConsistOf(SatisfyAll(
+			HaveField("Subject", "metalnet.node.LoadBalancerTargetAdded"),
+			HaveField("Event.Node", "node"),
+			HaveField("Event.Name", "nic"),
+			HaveField("Event.VNI", uint32(100)),
+			HaveField("Event.Prefix", &prefix),
+			HaveField("Event.Time", Not(BeZero())),
+		)))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+		Expect(sink.Closed()).To(BeTrue())
+	})
+
+	It("should drop events while the buffer is full", func() {
+		sink := &recordingSink{}
+		bus := eventbus.NewBus(sink, eventbus.Options{NodeName: "node", BufferSize: 1})
+
+		bus.Publish(eventbus.Event{Type: eventbus.InterfaceProgrammed, Name: "a"})
+		bus.Publish(eventbus.Event{Type: eventbus.InterfaceProgrammed, Name: "b"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(bus.Start(ctx)).To(Succeed())
+		}()
+		Eventually(sink.Messages).Should(ConsistOf(HaveField("Event.Name", "a")))
+		Consistently(sink.Messages).Should(HaveLen(1))
+	})
+
+	It("should discard events if nil", func() {
+		var bus *eventbus.Bus
+		bus.Publish(eventbus.Event{Type: eventbus.InterfaceProgrammed})
+	})
+})
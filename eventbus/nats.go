@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package eventbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSink publishes events to a NATS server.
+type NATSSink struct {
+	conn *nats.Conn
+}
+
+// NewNATSSink connects to the NATS servers of the given comma-separated urls. The connection is
+// re-established indefinitely if it is lost.
+func NewNATSSink(url string, opts ...nats.Option) (*NATSSink, error) {
+	opts = append([]nats.Option{
+		nats.Name("metalnet"),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+	}, opts...)
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats: %w", err)
+	}
+	return &NATSSink{conn: conn}, nil
+}
+
+// Publish publishes the given data. NATS buffers the messages while reconnecting, so the message is not
+// guaranteed to be delivered once Publish returns.
+func (s *NATSSink) Publish(_ context.Context, subject string, data []byte) error {
+	return s.conn.Publish(subject, data)
+}
+
+// Close flushes the buffered messages and closes the connection.
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command bench runs the metalnet scale benchmark against the dpservice simulator and writes the
+// results as JSON, so they can be tracked for regressions across commits.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/ironcore-dev/metalnet/test/bench"
+)
+
+// report is the JSON document written for a run.
+type report struct {
+	Time       time.Time     `json:"time"`
+	Label      string        `json:"label,omitempty"`
+	GoVersion  string        `json:"goVersion"`
+	GOMAXPROCS int           `json:"gomaxprocs"`
+	Result     *bench.Result `json:"result"`
+}
+
+func main() {
+	var (
+		opts   bench.Options
+		label  string
+		output string
+	)
+	flag.IntVar(&opts.NetworkInterfaces, "network-interfaces", 1000, "Number of network interfaces to reconcile.")
+	flag.IntVar(&opts.VirtualIPs, "virtual-ips", 0, "Number of network interfaces that get a virtual ip.")
+	flag.IntVar(&opts.Routes, "routes", 10000, "Number of metalbond routes to ingest.")
+	flag.IntVar(&opts.Workers, "workers", 1, "Number of network interfaces reconciled in parallel.")
+	flag.IntVar(&opts.RouteWorkers, "route-workers", 1, "Number of route ingestion workers.")
+	flag.DurationVar(&opts.Timeout, "timeout", 5*time.Minute, "Maximum time to wait for the routes to be ingested.")
+	flag.StringVar(&label, "label", "", "Label of the run, e.g. the commit, written to the results.")
+	flag.StringVar(&output, "output", "", "File to write the results to. Written to stdout if empty.")
+	flag.Parse()
+
+	if err := run(opts, label, output); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts bench.Options, label, output string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	start := time.Now()
+	res, err := bench.Run(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report{
+		Time:       start.UTC(),
+		Label:      label,
+		GoVersion:  runtime.Version(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Result:     res,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding results: %w", err)
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("error writing results: %w", err)
+	}
+	return nil
+}
@@ -4,36 +4,126 @@
 package internal
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
 	"net/netip"
+	"reflect"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+const (
+	cacheKindLoadBalancerServers = "load_balancer_servers"
+	cacheKindPeeredPrefixes      = "peered_prefixes"
+	cacheKindPeeringPrefixes     = "peering_prefixes"
+	cacheKindPeeredVNIs          = "peered_vnis"
+)
+
+var (
+	cacheEntries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "metalnet_cache_entries",
+		Help: "Number of entries of the metalnet cache, by kind.",
+	}, []string{"kind"})
+	cacheRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "metalnet_cache_rejected_total",
+		Help: "Number of entries not added to the metalnet cache because it is full, by kind.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cacheEntries, cacheRejected)
+}
+
+// ErrCacheFull is returned when adding an entry to a MetalnetCache that reached its bound.
+var ErrCacheFull = errors.New("metalnet cache is full")
+
+type MetalnetCacheOptions struct {
+	// MaxLoadBalancerServers bounds the number of load balancer servers. Zero means unbounded.
+	MaxLoadBalancerServers int
+	// MaxPeeredVNIs bounds the number of peerings between VNIs. Zero means unbounded.
+	MaxPeeredVNIs int
+}
+
+// PeeringPrefixes are the prefixes of the routes a VNI exchanges with a peered VNI. Nil prefixes do not restrict
+// the routes.
+type PeeringPrefixes struct {
+	// Export are the prefixes of the routes of the VNI installed into the peered VNI.
+	Export []netip.Prefix `json:"export,omitempty"`
+	// Import are the prefixes of the routes of the peered VNI installed into the VNI.
+	Import []netip.Prefix `json:"import,omitempty"`
+}
+
+// ServiceChainRoute is a route entry of a ServiceChain. It steers a prefix of a VNI into another VNI, which
+// does not need to be peered with it.
+type ServiceChainRoute struct {
+	VNI        uint32
+	Prefix     netip.Prefix
+	NextHopVNI uint32
+}
+
 type MetalnetCache struct {
+	opts MetalnetCacheOptions
+
+	mtx            sync.RWMutex
 	lbServerMap    map[uint32]map[string]types.UID
+	lbServerCount  int
 	peeredPrefixes map[uint32]map[uint32][]netip.Prefix
-	peeredVnis     map[uint32]sets.Set[uint32]
+	// peeringPrefixes is guarded by mtx as well.
+	peeringPrefixes map[uint32]map[uint32]PeeringPrefixes
+
 	mtxPeeredVnis  sync.RWMutex
-	log            *logr.Logger
+	peeredVnis     map[uint32]sets.Set[uint32]
+	peeredVniCount int
+
+	mtxDefaultRoutes    sync.RWMutex
+	ownDefaultRouteVNIs sets.Set[uint32]
+
+	mtxServiceChains   sync.RWMutex
+	serviceChainRoutes map[types.UID]sets.Set[ServiceChainRoute]
+
+	log *logr.Logger
 }
 
 func NewMetalnetCache(log *logr.Logger) *MetalnetCache {
+	return NewBoundedMetalnetCache(log, MetalnetCacheOptions{})
+}
+
+// NewBoundedMetalnetCache creates a MetalnetCache whose entries are bounded by the given options.
+func NewBoundedMetalnetCache(log *logr.Logger, opts MetalnetCacheOptions) *MetalnetCache {
 	return &MetalnetCache{
-		lbServerMap:    make(map[uint32]map[string]types.UID),
-		peeredPrefixes: make(map[uint32]map[uint32][]netip.Prefix),
-		peeredVnis:     make(map[uint32]sets.Set[uint32]),
-		log:            log,
+		opts:                opts,
+		lbServerMap:         make(map[uint32]map[string]types.UID),
+		peeredPrefixes:      make(map[uint32]map[uint32][]netip.Prefix),
+		peeringPrefixes:     make(map[uint32]map[uint32]PeeringPrefixes),
+		peeredVnis:          make(map[uint32]sets.Set[uint32]),
+		ownDefaultRouteVNIs: sets.New[uint32](),
+		serviceChainRoutes:  make(map[types.UID]sets.Set[ServiceChainRoute]),
+		log:                 log,
 	}
 }
 
 func (c *MetalnetCache) SetPeeredPrefixes(vni uint32, peeredPrefixes map[uint32][]netip.Prefix) {
-	c.peeredPrefixes[vni] = peeredPrefixes
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(peeredPrefixes) == 0 {
+		delete(c.peeredPrefixes, vni)
+	} else {
+		c.peeredPrefixes[vni] = peeredPrefixes
+	}
+	cacheEntries.WithLabelValues(cacheKindPeeredPrefixes).Set(float64(len(c.peeredPrefixes)))
 }
 
 func (c *MetalnetCache) GetPeeredPrefixes(vni uint32) (map[uint32][]netip.Prefix, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
 	prefixes, ok := c.peeredPrefixes[vni]
 	if !ok {
 		return nil, false
@@ -48,6 +138,35 @@ func (c *MetalnetCache) GetPeeredPrefixes(vni uint32) (map[uint32][]netip.Prefix
 	return copiedPrefixes, true
 }
 
+// SetPeeringPrefixes sets the prefixes the VNI exchanges with its peered VNIs and reports whether they changed.
+func (c *MetalnetCache) SetPeeringPrefixes(vni uint32, peeringPrefixes map[uint32]PeeringPrefixes) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	changed := !reflect.DeepEqual(c.peeringPrefixes[vni], peeringPrefixes) &&
+		(len(c.peeringPrefixes[vni]) != 0 || len(peeringPrefixes) != 0)
+	if len(peeringPrefixes) == 0 {
+		delete(c.peeringPrefixes, vni)
+	} else {
+		c.peeringPrefixes[vni] = peeringPrefixes
+	}
+	cacheEntries.WithLabelValues(cacheKindPeeringPrefixes).Set(float64(len(c.peeringPrefixes)))
+	return changed
+}
+
+// GetPeeringPrefixes returns the prefixes the VNI exchanges with the given peered VNI.
+func (c *MetalnetCache) GetPeeringPrefixes(vni, peeredVNI uint32) (PeeringPrefixes, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	prefixes, ok := c.peeringPrefixes[vni][peeredVNI]
+	if !ok {
+		return PeeringPrefixes{}, false
+	}
+	return PeeringPrefixes{
+		Export: slices.Clone(prefixes.Export),
+		Import: slices.Clone(prefixes.Import),
+	}, true
+}
+
 func (c *MetalnetCache) IsVniPeered(vni uint32) bool {
 	c.mtxPeeredVnis.RLock()
 	defer c.mtxPeeredVnis.RUnlock()
@@ -66,7 +185,7 @@ func (c *MetalnetCache) GetPeerVnis(vni uint32) (sets.Set[uint32], bool) {
 	if !ok {
 		return sets.New[uint32](), false
 	}
-	return vnis, true
+	return vnis.Clone(), true
 }
 
 func (c *MetalnetCache) AddVniToPeerVnis(vni, peeredVNI uint32) error {
@@ -74,11 +193,20 @@ func (c *MetalnetCache) AddVniToPeerVnis(vni, peeredVNI uint32) error {
 	defer c.mtxPeeredVnis.Unlock()
 	c.log.V(1).Info("Adding to peered VNI list", "VNI", vni, "peeredVNI", peeredVNI)
 	set, ok := c.peeredVnis[vni]
+	if ok && set.Has(peeredVNI) {
+		return nil
+	}
+	if c.opts.MaxPeeredVNIs > 0 && c.peeredVniCount >= c.opts.MaxPeeredVNIs {
+		cacheRejected.WithLabelValues(cacheKindPeeredVNIs).Inc()
+		return ErrCacheFull
+	}
 	if !ok {
 		set = sets.New[uint32]()
 		c.peeredVnis[vni] = set
 	}
 	set.Insert(peeredVNI)
+	c.peeredVniCount++
+	cacheEntries.WithLabelValues(cacheKindPeeredVNIs).Set(float64(c.peeredVniCount))
 	c.log.V(1).Info("Added to peered VNI list", "VNI", vni, "peeredVNI", peeredVNI)
 	return nil
 }
@@ -88,34 +216,72 @@ func (c *MetalnetCache) RemoveVniFromPeerVnis(vni, peeredVNI uint32) error {
 	defer c.mtxPeeredVnis.Unlock()
 	c.log.V(1).Info("Removing from peered VNI list", "VNI", vni, "peeredVNI", peeredVNI)
 	set, ok := c.peeredVnis[vni]
-	if !ok {
+	if !ok || !set.Has(peeredVNI) {
 		return nil
 	}
+	// The empty set is kept until the Network is removed, so its routes of formerly peered VNIs are still
+	// recognized as not peered.
 	set.Delete(peeredVNI)
+	c.peeredVniCount--
+	cacheEntries.WithLabelValues(cacheKindPeeredVNIs).Set(float64(c.peeredVniCount))
 	c.log.V(1).Info("Removed from peered VNI list", "VNI", vni, "peeredVNI", peeredVNI)
 	return nil
 }
 
 func (c *MetalnetCache) AddLoadBalancerServer(vni uint32, ip string, uid types.UID) error {
-	if _, exists := c.lbServerMap[vni]; !exists {
-		c.lbServerMap[vni] = make(map[string]types.UID)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	servers, exists := c.lbServerMap[vni]
+	if _, ok := servers[ip]; !ok {
+		if c.opts.MaxLoadBalancerServers > 0 && c.lbServerCount >= c.opts.MaxLoadBalancerServers {
+			cacheRejected.WithLabelValues(cacheKindLoadBalancerServers).Inc()
+			return ErrCacheFull
+		}
+		c.lbServerCount++
+	}
+	if !exists {
+		servers = make(map[string]types.UID)
+		c.lbServerMap[vni] = servers
 	}
-	c.lbServerMap[vni][ip] = uid
+	servers[ip] = uid
+	cacheEntries.WithLabelValues(cacheKindLoadBalancerServers).Set(float64(c.lbServerCount))
 	return nil
 }
 
 func (c *MetalnetCache) RemoveLoadBalancerServer(ip string, uid types.UID) error {
-	for _, innerMap := range c.lbServerMap {
+	c.removeLoadBalancerServers(func(keyIp string, value types.UID) bool {
+		return ip == keyIp && value == uid
+	})
+	return nil
+}
+
+// RemoveLoadBalancer removes all servers of the LoadBalancer with the given UID, whatever their VNI and ip.
+func (c *MetalnetCache) RemoveLoadBalancer(uid types.UID) {
+	c.removeLoadBalancerServers(func(_ string, value types.UID) bool {
+		return value == uid
+	})
+}
+
+func (c *MetalnetCache) removeLoadBalancerServers(match func(ip string, uid types.UID) bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for vni, innerMap := range c.lbServerMap {
 		for keyIp, value := range innerMap {
-			if ip == keyIp && value == uid {
-				delete(innerMap, ip)
+			if match(keyIp, value) {
+				delete(innerMap, keyIp)
+				c.lbServerCount--
 			}
 		}
+		if len(innerMap) == 0 {
+			delete(c.lbServerMap, vni)
+		}
 	}
-	return nil
+	cacheEntries.WithLabelValues(cacheKindLoadBalancerServers).Set(float64(c.lbServerCount))
 }
 
 func (c *MetalnetCache) GetLoadBalancerServer(vni uint32, ip string) (types.UID, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
 	innerMap, exists := c.lbServerMap[vni]
 	if !exists {
 		return "", false
@@ -123,3 +289,126 @@ func (c *MetalnetCache) GetLoadBalancerServer(vni uint32, ip string) (types.UID,
 	uid, exists := innerMap[ip]
 	return uid, exists
 }
+
+// SetOwnDefaultRoute records whether the Network with the given VNI has a default route of its own instead of
+// the one to the default router of the public VNI.
+func (c *MetalnetCache) SetOwnDefaultRoute(vni uint32, own bool) {
+	c.mtxDefaultRoutes.Lock()
+	defer c.mtxDefaultRoutes.Unlock()
+	if own {
+		c.ownDefaultRouteVNIs.Insert(vni)
+	} else {
+		c.ownDefaultRouteVNIs.Delete(vni)
+	}
+}
+
+// HasOwnDefaultRoute reports whether the Network with the given VNI has a default route of its own.
+func (c *MetalnetCache) HasOwnDefaultRoute(vni uint32) bool {
+	c.mtxDefaultRoutes.RLock()
+	defer c.mtxDefaultRoutes.RUnlock()
+	return c.ownDefaultRouteVNIs.Has(vni)
+}
+
+// SetServiceChainRoutes records the route entries of the ServiceChain with the given UID. Empty routes remove
+// the entries of the ServiceChain.
+func (c *MetalnetCache) SetServiceChainRoutes(uid types.UID, routes []ServiceChainRoute) {
+	c.mtxServiceChains.Lock()
+	defer c.mtxServiceChains.Unlock()
+	if len(routes) == 0 {
+		delete(c.serviceChainRoutes, uid)
+		return
+	}
+	c.serviceChainRoutes[uid] = sets.New(routes...)
+}
+
+// IsServiceChainRoute reports whether a ServiceChain steers the prefix of the VNI into nextHopVNI.
+func (c *MetalnetCache) IsServiceChainRoute(vni uint32, prefix netip.Prefix, nextHopVNI uint32) bool {
+	c.mtxServiceChains.RLock()
+	defer c.mtxServiceChains.RUnlock()
+	route := ServiceChainRoute{VNI: vni, Prefix: prefix, NextHopVNI: nextHopVNI}
+	for _, routes := range c.serviceChainRoutes {
+		if routes.Has(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveNetwork removes the peered and peering prefixes, peered VNIs and default route of the Network with the given VNI. It
+// is called once the Network is deleted, so no stale entries are left behind if its cleanup was skipped.
+func (c *MetalnetCache) RemoveNetwork(vni uint32) {
+	c.SetPeeredPrefixes(vni, nil)
+	c.SetPeeringPrefixes(vni, nil)
+	c.SetOwnDefaultRoute(vni, false)
+
+	c.mtxPeeredVnis.Lock()
+	defer c.mtxPeeredVnis.Unlock()
+	c.peeredVniCount -= c.peeredVnis[vni].Len()
+	delete(c.peeredVnis, vni)
+	cacheEntries.WithLabelValues(cacheKindPeeredVNIs).Set(float64(c.peeredVniCount))
+}
+
+// MetalnetCacheDump is the content of a MetalnetCache.
+type MetalnetCacheDump struct {
+	// LoadBalancerServers maps VNIs to the load balancer ips and the UIDs of their LoadBalancers.
+	LoadBalancerServers map[uint32]map[string]types.UID `json:"loadBalancerServers"`
+	// PeeredPrefixes maps VNIs to the prefixes they accept from their peered VNIs.
+	PeeredPrefixes map[uint32]map[uint32][]netip.Prefix `json:"peeredPrefixes"`
+	// PeeringPrefixes maps VNIs to the prefixes they export to and import from their peered VNIs.
+	PeeringPrefixes map[uint32]map[uint32]PeeringPrefixes `json:"peeringPrefixes"`
+	// PeeredVNIs maps VNIs to their peered VNIs.
+	PeeredVNIs map[uint32][]uint32 `json:"peeredVNIs"`
+}
+
+// Dump returns a copy of the content of the cache.
+func (c *MetalnetCache) Dump() MetalnetCacheDump {
+	dump := MetalnetCacheDump{
+		LoadBalancerServers: make(map[uint32]map[string]types.UID),
+		PeeredPrefixes:      make(map[uint32]map[uint32][]netip.Prefix),
+		PeeringPrefixes:     make(map[uint32]map[uint32]PeeringPrefixes),
+		PeeredVNIs:          make(map[uint32][]uint32),
+	}
+
+	c.mtx.RLock()
+	for vni, servers := range c.lbServerMap {
+		dump.LoadBalancerServers[vni] = make(map[string]types.UID, len(servers))
+		for ip, uid := range servers {
+			dump.LoadBalancerServers[vni][ip] = uid
+		}
+	}
+	for vni, prefixes := range c.peeredPrefixes {
+		dump.PeeredPrefixes[vni] = make(map[uint32][]netip.Prefix, len(prefixes))
+		for peeredVNI, peeredPrefixes := range prefixes {
+			dump.PeeredPrefixes[vni][peeredVNI] = append([]netip.Prefix(nil), peeredPrefixes...)
+		}
+	}
+	for vni, prefixes := range c.peeringPrefixes {
+		dump.PeeringPrefixes[vni] = make(map[uint32]PeeringPrefixes, len(prefixes))
+		for peeredVNI, peeringPrefixes := range prefixes {
+			dump.PeeringPrefixes[vni][peeredVNI] = PeeringPrefixes{
+				Export: slices.Clone(peeringPrefixes.Export),
+				Import: slices.Clone(peeringPrefixes.Import),
+			}
+		}
+	}
+	c.mtx.RUnlock()
+
+	c.mtxPeeredVnis.RLock()
+	for vni, peeredVNIs := range c.peeredVnis {
+		list := peeredVNIs.UnsortedList()
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+		dump.PeeredVNIs[vni] = list
+	}
+	c.mtxPeeredVnis.RUnlock()
+	return dump
+}
+
+// Handler returns a handler serving the content of the cache as JSON for debugging.
+func (c *MetalnetCache) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(c.Dump())
+	})
+}
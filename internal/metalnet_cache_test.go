@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	. "github.com/ironcore-dev/metalnet/internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("MetalnetCache", func() {
+	log := logr.Discard()
+
+	It("should reject entries beyond its bounds", func() {
+		c := NewBoundedMetalnetCache(&log, MetalnetCacheOptions{MaxLoadBalancerServers: 1, MaxPeeredVNIs: 1})
+
+		Expect(c.AddLoadBalancerServer(100, "11.0.0.1", "lb-1")).To(Succeed())
+		Expect(c.AddLoadBalancerServer(100, "11.0.0.1", "lb-1")).To(Succeed())
+		Expect(c.AddLoadBalancerServer(100, "11.0.0.2", "lb-2")).To(MatchError(ErrCacheFull))
+
+		Expect(c.AddVniToPeerVnis(100, 200)).To(Succeed())
+		Expect(c.AddVniToPeerVnis(100, 300)).To(MatchError(ErrCacheFull))
+
+		By("freeing up space")
+		Expect(c.RemoveVniFromPeerVnis(100, 200)).To(Succeed())
+		Expect(c.AddVniToPeerVnis(100, 300)).To(Succeed())
+		c.RemoveLoadBalancer("lb-1")
+		Expect(c.AddLoadBalancerServer(100, "11.0.0.2", "lb-2")).To(Succeed())
+	})
+
+	It("should invalidate the entries of deleted load balancers and networks", func() {
+		c := NewMetalnetCache(&log)
+		Expect(c.AddLoadBalancerServer(100, "11.0.0.1", "lb-1")).To(Succeed())
+		Expect(c.AddLoadBalancerServer(200, "11.0.0.2", "lb-1")).To(Succeed())
+		Expect(c.AddVniToPeerVnis(100, 200)).To(Succeed())
+		c.SetPeeredPrefixes(100, map[uint32][]netip.Prefix{200: {netip.MustParsePrefix("10.0.0.0/24")}})
+		Expect(c.SetPeeringPrefixes(100, map[uint32]PeeringPrefixes{200: {Export: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}}})).To(BeTrue())
+
+		c.RemoveLoadBalancer("lb-1")
+		c.RemoveNetwork(100)
+
+		_, ok := c.GetLoadBalancerServer(100, "11.0.0.1")
+		Expect(ok).To(BeFalse())
+		_, ok = c.GetPeerVnis(100)
+		Expect(ok).To(BeFalse())
+		_, ok = c.GetPeeredPrefixes(100)
+		Expect(ok).To(BeFalse())
+		_, ok = c.GetPeeringPrefixes(100, 200)
+		Expect(ok).To(BeFalse())
+		Expect(c.Dump()).To(Equal(MetalnetCacheDump{
+			LoadBalancerServers: map[uint32]map[string]types.UID{},
+			PeeredPrefixes:      map[uint32]map[uint32][]netip.Prefix{},
+			PeeringPrefixes:     map[uint32]map[uint32]PeeringPrefixes{},
+			PeeredVNIs:          map[uint32][]uint32{},
+		}))
+	})
+
+	It("should report changes of the peering prefixes", func() {
+		c := NewMetalnetCache(&log)
+		prefixes := map[uint32]PeeringPrefixes{200: {Import: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}}}
+		Expect(c.SetPeeringPrefixes(100, nil)).To(BeFalse())
+		Expect(c.SetPeeringPrefixes(100, prefixes)).To(BeTrue())
+		Expect(c.SetPeeringPrefixes(100, map[uint32]PeeringPrefixes{200: {Import: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}}})).To(BeFalse())
+		got, ok := c.GetPeeringPrefixes(100, 200)
+		Expect(ok).To(BeTrue())
+		Expect(got).To(Equal(prefixes[200]))
+		Expect(c.SetPeeringPrefixes(100, map[uint32]PeeringPrefixes{})).To(BeTrue())
+	})
+
+	It("should serve its content", func() {
+		c := NewMetalnetCache(&log)
+		Expect(c.AddLoadBalancerServer(100, "11.0.0.1", "lb-1")).To(Succeed())
+		Expect(c.AddVniToPeerVnis(100, 300)).To(Succeed())
+		Expect(c.AddVniToPeerVnis(100, 200)).To(Succeed())
+
+		rec := httptest.NewRecorder()
+		c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/metalnet-cache", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		dump := MetalnetCacheDump{}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &dump)).To(Succeed())
+		Expect(dump.LoadBalancerServers).To(HaveKeyWithValue(uint32(100), HaveKeyWithValue("11.0.0.1", BeEquivalentTo("lb-1"))))
+		Expect(dump.PeeredVNIs).To(HaveKeyWithValue(uint32(100), Equal([]uint32{200, 300})))
+	})
+})
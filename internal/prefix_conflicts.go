@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// InterfaceAddresses are the primary ips and alias prefixes of a network interface.
+type InterfaceAddresses struct {
+	IPs      []netip.Addr
+	Prefixes []netip.Prefix
+}
+
+// FindAddressConflict reports why the addresses of two network interfaces in the same network on the same node
+// cannot be programmed side by side, or returns nil if they can.
+//
+// Two interfaces conflict if they share a primary ip, if the primary ip of one lies in an alias prefix of the
+// other or if their alias prefixes overlap. Load balancer targets are not considered, as several interfaces
+// legitimately target the same load balancer.
+func FindAddressConflict(a, b InterfaceAddresses) error {
+	for _, ip := range a.IPs {
+		for _, otherIP := range b.IPs {
+			if ip == otherIP {
+				return fmt.Errorf("ip %s is used by both interfaces", ip)
+			}
+		}
+		for _, prefix := range b.Prefixes {
+			if prefix.Contains(ip) {
+				return fmt.Errorf("ip %s lies in prefix %s", ip, prefix)
+			}
+		}
+	}
+	for _, prefix := range a.Prefixes {
+		for _, ip := range b.IPs {
+			if prefix.Contains(ip) {
+				return fmt.Errorf("prefix %s contains ip %s", prefix, ip)
+			}
+		}
+		for _, otherPrefix := range b.Prefixes {
+			if prefix.Overlaps(otherPrefix) {
+				return fmt.Errorf("prefix %s overlaps prefix %s", prefix, otherPrefix)
+			}
+		}
+	}
+	return nil
+}
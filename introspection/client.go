@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ErrNotFound is returned by Client.GetInterface if the NetworkInterface does not exist on the node.
+var ErrNotFound = errors.New("network interface not found")
+
+// Client queries the introspection API of the metalnet instance of the node.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the introspection API served on the given unix socket.
+func NewClient(socketPath string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// ListInterfaces lists the NetworkInterfaces of the node. If device is not empty, only the NetworkInterfaces
+// programmed on the device are listed.
+func (c *Client) ListInterfaces(ctx context.Context, device string) ([]Interface, error) {
+	path := interfacesPath
+	if device != "" {
+		path += "?" + url.Values{"device": {device}}.Encode()
+	}
+	list := &InterfaceList{}
+	if err := c.get(ctx, path, list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetInterface returns the NetworkInterface with the given namespace and name.
+func (c *Client) GetInterface(ctx context.Context, namespace, name string) (*Interface, error) {
+	iface := &Interface{}
+	if err := c.get(ctx, fmt.Sprintf("%s/%s/%s", interfacesPath, url.PathEscape(namespace), url.PathEscape(name)), iface); err != nil {
+		return nil, err
+	}
+	return iface, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://metalnet"+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error querying introspection api: %w", err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("introspection api returned %s: %s", res.Status, body)
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
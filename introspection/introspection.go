@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package introspection serves the programming state of the NetworkInterfaces of a node to other agents on
+// the node, e.g. CSI drivers or metal agents, over a local unix socket. The agents learn which device a
+// NetworkInterface is programmed on, its VNI and underlay route without watching the Kubernetes API.
+//
+// The API is JSON over HTTP:
+//
+//	GET /v1/interfaces                       all NetworkInterfaces of the node
+//	GET /v1/interfaces?device=<name>         the NetworkInterfaces programmed on the given device
+//	GET /v1/interfaces/<namespace>/<name>    a single NetworkInterface
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const interfacesPath = "/v1/interfaces"
+
+// Interface is the programming state of a NetworkInterface.
+type Interface struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+	// State is the state reported in the status of the NetworkInterface.
+	State metalnetv1alpha1.NetworkInterfaceState `json:"state,omitempty"`
+	// Device is the device the NetworkInterface is programmed on.
+	Device     *metalnetv1alpha1.DeviceStatus `json:"device,omitempty"`
+	PCIAddress *metalnetv1alpha1.PCIAddress   `json:"pciAddress,omitempty"`
+	IPs        []netip.Addr                   `json:"ips,omitempty"`
+	VirtualIP  *netip.Addr                    `json:"virtualIP,omitempty"`
+	// Programmed reports whether dpservice has an interface for the NetworkInterface.
+	Programmed bool `json:"programmed"`
+	// VNI and UnderlayRoute are the VNI and underlay route of the dpservice interface, if programmed.
+	VNI           uint32      `json:"vni,omitempty"`
+	UnderlayRoute *netip.Addr `json:"underlayRoute,omitempty"`
+}
+
+// InterfaceList is the response of listing interfaces.
+type InterfaceList struct {
+	Items []Interface `json:"items"`
+}
+
+type Options struct {
+	// SocketPath is the path of the unix socket the API is served on.
+	SocketPath string
+	// NodeName is the node whose NetworkInterfaces are served.
+	NodeName string
+}
+
+// Server serves the introspection API.
+type Server struct {
+	client client.Reader
+	dpdk   dpdkclient.Client
+	opts   Options
+	log    logr.Logger
+}
+
+func NewServer(c client.Reader, dpdk dpdkclient.Client, opts Options) *Server {
+	return &Server{
+		client: c,
+		dpdk:   dpdk,
+		opts:   opts,
+		log:    ctrl.Log.WithName("introspection"),
+	}
+}
+
+// Start serves the API until the context is done. A socket left behind by a previous run is replaced.
+func (s *Server) Start(ctx context.Context) error {
+	if err := os.Remove(s.opts.SocketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing stale socket: %w", err)
+	}
+	lis, err := net.Listen("unix", s.opts.SocketPath)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", s.opts.SocketPath, err)
+	}
+
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.log.Error(err, "Error shutting down introspection server")
+		}
+	}()
+
+	s.log.Info("Serving introspection API", "Socket", s.opts.SocketPath)
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving introspection api: %w", err)
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every metalnet instance serves the agents
+// of its own node.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(interfacesPath, s.listInterfaces)
+	mux.HandleFunc(interfacesPath+"/", s.getInterface)
+	return mux
+}
+
+func (s *Server) listInterfaces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := req.Context()
+
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := s.client.List(ctx, nicList); err != nil {
+		s.error(w, fmt.Errorf("error listing network interfaces: %w", err))
+		return
+	}
+	ifaces, err := s.dpdk.ListInterfaces(ctx)
+	if err != nil {
+		s.error(w, fmt.Errorf("error listing dpdk interfaces: %w", err))
+		return
+	}
+	dpdkIfaces := make(map[string]*dpdk.Interface, len(ifaces.Items))
+	for i := range ifaces.Items {
+		dpdkIfaces[ifaces.Items[i].ID] = &ifaces.Items[i]
+	}
+
+	device := req.URL.Query().Get("device")
+	res := InterfaceList{Items: []Interface{}}
+	for i := range nicList.Items {
+		nic := &nicList.Items[i]
+		if !s.onNode(nic) {
+			continue
+		}
+		iface := newInterface(nic, dpdkIfaces[string(nic.UID)])
+		if device != "" && (iface.Device == nil || iface.Device.Name != device) {
+			continue
+		}
+		res.Items = append(res.Items, iface)
+	}
+	writeJSON(w, res)
+}
+
+func (s *Server) getInterface(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := req.Context()
+
+	namespace, name, ok := strings.Cut(strings.TrimPrefix(req.URL.Path, interfacesPath+"/"), "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		http.Error(w, "expected /v1/interfaces/<namespace>/<name>", http.StatusNotFound)
+		return
+	}
+
+	nic := &metalnetv1alpha1.NetworkInterface{}
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, nic); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.error(w, fmt.Errorf("error getting network interface: %w", err))
+		return
+	}
+	if !s.onNode(nic) {
+		http.Error(w, fmt.Sprintf("network interface %s/%s is not assigned to this node", namespace, name), http.StatusNotFound)
+		return
+	}
+
+	dpdkIface, err := s.dpdk.GetInterface(ctx, string(nic.UID))
+	if err != nil {
+		if !dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND) {
+			s.error(w, fmt.Errorf("error getting dpdk interface: %w", err))
+			return
+		}
+		dpdkIface = nil
+	}
+	writeJSON(w, newInterface(nic, dpdkIface))
+}
+
+func (s *Server) onNode(nic *metalnetv1alpha1.NetworkInterface) bool {
+	return nic.Spec.NodeName != nil && *nic.Spec.NodeName == s.opts.NodeName
+}
+
+func (s *Server) error(w http.ResponseWriter, err error) {
+	s.log.Error(err, "Error serving introspection request")
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func newInterface(nic *metalnetv1alpha1.NetworkInterface, dpdkIface *dpdk.Interface) Interface {
+	iface := Interface{
+		Namespace:  nic.Namespace,
+		Name:       nic.Name,
+		UID:        nic.UID,
+		State:      nic.Status.State,
+		Device:     nic.Status.Device,
+		PCIAddress: nic.Status.PCIAddress,
+	}
+	for _, ip := range nic.Spec.IPs {
+		iface.IPs = append(iface.IPs, ip.Addr)
+	}
+	if nic.Status.VirtualIP != nil {
+		virtualIP := nic.Status.VirtualIP.Addr
+		iface.VirtualIP = &virtualIP
+	}
+	if dpdkIface != nil {
+		iface.Programmed = true
+		iface.VNI = dpdkIface.Spec.VNI
+		iface.UnderlayRoute = dpdkIface.Spec.UnderlayRoute
+	}
+	return iface
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
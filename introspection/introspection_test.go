@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package introspection_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/introspection"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Introspection", func() {
+	It("should serve the programming state of the network interfaces of the node", func(ctx SpecContext) {
+		lis := bufconn.Listen(1 << 20)
+		srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+		DeferCleanup(srv.Stop)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		dpdkClient := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+		ip := netip.MustParseAddr("10.0.0.1")
+		_, err = dpdkClient.CreateInterface(ctx, &dpdk.Interface{
+			InterfaceMeta: dpdk.InterfaceMeta{ID: "programmed-uid"},
+			Spec:          dpdk.InterfaceSpec{VNI: 100, Device: "net_tap4", IPv4: &ip},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		newNIC := func(name, node, device string) *metalnetv1alpha1.NetworkInterface {
+			return &metalnetv1alpha1.NetworkInterface{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: "programmed-uid"},
+				Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+					IPs:      []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+					NodeName: ptr.To(node),
+				},
+				Status: metalnetv1alpha1.NetworkInterfaceStatus{
+					State:  metalnetv1alpha1.NetworkInterfaceStateReady,
+					Device: &metalnetv1alpha1.DeviceStatus{Name: device},
+				},
+			}
+		}
+		programmed := newNIC("programmed", "node", "net_tap4")
+		pending := newNIC("pending", "node", "net_tap5")
+		pending.UID = "pending-uid"
+		other := newNIC("other", "other-node", "net_tap4")
+		other.UID = "other-uid"
+
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(programmed, pending, other).Build()
+
+		dir, err := os.MkdirTemp("", "introspection")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+		socketPath := filepath.Join(dir, "metalnet.sock")
+
+		server := introspection.NewServer(c, dpdkClient, introspection.Options{SocketPath: socketPath, NodeName: "node"})
+		serverCtx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(server.Start(serverCtx)).To(Succeed())
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+
+		ic := introspection.NewClient(socketPath)
+		Eventually(func() error {
+			_, err := ic.ListInterfaces(ctx, "")
+			return err
+		}).Should(Succeed())
+
+		ifaces, err := ic.ListInterfaces(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ifaces).To(ConsistOf(
+			HaveField("Name", "programmed"),
+			HaveField("Name", "pending"),
+		))
+
+		ifaces, err = ic.ListInterfaces(ctx, "net_tap4")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ifaces).To(ConsistOf(SatisfyAll(
+			HaveField("Name", "programmed"),
+			HaveField("Programmed", true),
+			HaveField("VNI", uint32(100)),
+			HaveField("UnderlayRoute", Not(BeNil())),
+			HaveField("IPs", Equal([]netip.Addr{ip})),
+		)))
+
+		iface, err := ic.GetInterface(ctx, "default", "pending")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Programmed).To(BeFalse())
+		Expect(iface.Device.Name).To(Equal("net_tap5"))
+
+		_, err = ic.GetInterface(ctx, "default", "other")
+		Expect(err).To(MatchError(introspection.ErrNotFound))
+		_, err = ic.GetInterface(ctx, "default", "missing")
+		Expect(err).To(MatchError(introspection.ErrNotFound))
+	})
+})
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ipam allocates the IPs of NetworkInterfaces created without IPs.
+package ipam
+
+import (
+	"context"
+	"errors"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+)
+
+var (
+	// ErrNoIPAM is returned by Static, which does not allocate IPs.
+	ErrNoIPAM = errors.New("network interface has no ips and no IPAM is configured")
+	// ErrNoMatchingPool is returned if no pool provides IPs for the Network of the NetworkInterface.
+	ErrNoMatchingPool = errors.New("no ip pool provides ips for the network of the network interface")
+	// ErrPoolExhausted is returned if the pools of the Network have no free IPs left.
+	ErrPoolExhausted = errors.New("all ip pools of the network are exhausted")
+)
+
+// IPAM allocates the IPs of NetworkInterfaces.
+type IPAM interface {
+	// Allocate returns an IP per IP family of the NetworkInterface, unique within the given Network. Allocating
+	// again for the same NetworkInterface returns the same IPs until they are released. The Network of a
+	// NetworkInterface connected to a ClusterNetwork has no namespace.
+	Allocate(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) ([]metalnetv1alpha1.IP, error)
+	// Release releases the IPs allocated to the NetworkInterface. Releasing IPs that are not allocated is
+	// no error.
+	Release(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) error
+}
+
+// Static is the IPAM of NetworkInterfaces that bring their own IPs in their spec. It does not allocate IPs.
+type Static struct{}
+
+func (Static) Allocate(context.Context, *metalnetv1alpha1.NetworkInterface, *metalnetv1alpha1.Network) ([]metalnetv1alpha1.IP, error) {
+	return nil, ErrNoIPAM
+}
+
+func (Static) Release(context.Context, *metalnetv1alpha1.NetworkInterface, *metalnetv1alpha1.Network) error {
+	return nil
+}
@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Pool allocates the IPs of NetworkInterfaces from the NetworkIPPools of their Network.
+//
+// The allocation is recorded in the status of the pool with an optimistic lock, so concurrent allocations
+// on other nodes do not hand out the same IPs; a conflict error is returned if the pool was modified
+// concurrently. The Networks of ClusterNetworks have no pools.
+type Pool struct {
+	client client.Client
+}
+
+func NewPool(c client.Client) *Pool {
+	return &Pool{client: c}
+}
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkippools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=networkippools/status,verbs=get;update;patch
+
+func (p *Pool) Allocate(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) ([]metalnetv1alpha1.IP, error) {
+	pools, err := p.listPools(ctx, network)
+	if err != nil {
+		return nil, err
+	}
+
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := p.client.List(ctx, nicList, client.InNamespace(network.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing network interfaces: %w", err)
+	}
+
+	pool, ips, err := allocatePoolIPs(nic, network, pools, nicList.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, allocation := range pool.Status.Allocations {
+		if allocation.NetworkInterfaceName == nic.Name && slices.Equal(allocation.IPs, ips) {
+			return ips, nil
+		}
+	}
+	base := pool.DeepCopy()
+	pool.Status.Allocations = slices.DeleteFunc(pool.Status.Allocations, func(allocation metalnetv1alpha1.NetworkIPPoolAllocation) bool {
+		return allocation.NetworkInterfaceName == nic.Name
+	})
+	pool.Status.Allocations = append(pool.Status.Allocations, metalnetv1alpha1.NetworkIPPoolAllocation{
+		NetworkInterfaceName: nic.Name,
+		IPs:                  ips,
+	})
+	sort.Slice(pool.Status.Allocations, func(i, j int) bool {
+		return pool.Status.Allocations[i].NetworkInterfaceName < pool.Status.Allocations[j].NetworkInterfaceName
+	})
+	if err := p.client.Status().Patch(ctx, pool, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+		return nil, fmt.Errorf("error recording ip allocation in pool %s: %w", pool.Name, err)
+	}
+	return ips, nil
+}
+
+func (p *Pool) Release(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) error {
+	pools, err := p.listPools(ctx, network)
+	if err != nil {
+		return err
+	}
+
+	for i := range pools {
+		pool := &pools[i]
+		if !slices.ContainsFunc(pool.Status.Allocations, func(allocation metalnetv1alpha1.NetworkIPPoolAllocation) bool {
+			return allocation.NetworkInterfaceName == nic.Name
+		}) {
+			continue
+		}
+
+		base := pool.DeepCopy()
+		pool.Status.Allocations = slices.DeleteFunc(pool.Status.Allocations, func(allocation metalnetv1alpha1.NetworkIPPoolAllocation) bool {
+			return allocation.NetworkInterfaceName == nic.Name
+		})
+		if err := p.client.Status().Patch(ctx, pool, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return fmt.Errorf("error releasing ip allocation of pool %s: %w", pool.Name, err)
+		}
+	}
+	return nil
+}
+
+// listPools lists the pools of the given Network sorted by name.
+func (p *Pool) listPools(ctx context.Context, network *metalnetv1alpha1.Network) ([]metalnetv1alpha1.NetworkIPPool, error) {
+	if network.Namespace == "" {
+		return nil, nil
+	}
+
+	poolList := &metalnetv1alpha1.NetworkIPPoolList{}
+	if err := p.client.List(ctx, poolList, client.InNamespace(network.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing network ip pools: %w", err)
+	}
+
+	var pools []metalnetv1alpha1.NetworkIPPool
+	for _, pool := range poolList.Items {
+		if pool.Spec.NetworkRef.Name == network.Name {
+			pools = append(pools, pool)
+		}
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools, nil
+}
+
+// allocatePoolIPs returns the pool and the IPs to allocate to the given NetworkInterface from the given pools
+// of its Network.
+//
+// IPs already allocated to the NetworkInterface are returned again. Otherwise, the first free IP per IP family
+// of the first pool providing all IP families of the NetworkInterface is allocated. IPs allocated by any pool
+// of the Network or set on any other NetworkInterface of the Network are not free.
+func allocatePoolIPs(
+	nic *metalnetv1alpha1.NetworkInterface,
+	network *metalnetv1alpha1.Network,
+	pools []metalnetv1alpha1.NetworkIPPool,
+	nics []metalnetv1alpha1.NetworkInterface,
+) (*metalnetv1alpha1.NetworkIPPool, []metalnetv1alpha1.IP, error) {
+	var matching []*metalnetv1alpha1.NetworkIPPool
+	for i := range pools {
+		if pools[i].DeletionTimestamp.IsZero() {
+			matching = append(matching, &pools[i])
+		}
+	}
+	if len(matching) == 0 {
+		return nil, nil, ErrNoMatchingPool
+	}
+
+	used := make(map[netip.Addr]bool)
+	for _, other := range nics {
+		if other.Name == nic.Name || other.Spec.ClusterNetworkRef != nil || other.Spec.NetworkRef.Name != network.Name {
+			continue
+		}
+		for _, ip := range other.Spec.IPs {
+			used[ip.Addr] = true
+		}
+	}
+	for _, pool := range matching {
+		for _, allocation := range pool.Status.Allocations {
+			if allocation.NetworkInterfaceName == nic.Name {
+				continue
+			}
+			for _, ip := range allocation.IPs {
+				used[ip.Addr] = true
+			}
+		}
+	}
+
+	for _, pool := range matching {
+		for _, allocation := range pool.Status.Allocations {
+			if allocation.NetworkInterfaceName == nic.Name && isValidAllocation(pool, allocation.IPs, nic.Spec.IPFamilies, used) {
+				return pool, allocation.IPs, nil
+			}
+		}
+	}
+
+	for _, pool := range matching {
+		if ips, ok := freeIPs(pool, nic.Spec.IPFamilies, used); ok {
+			return pool, ips, nil
+		}
+	}
+	return nil, nil, ErrPoolExhausted
+}
+
+// isValidAllocation reports whether the given IPs are free IPs of the pool, one per IP family.
+func isValidAllocation(pool *metalnetv1alpha1.NetworkIPPool, ips []metalnetv1alpha1.IP, ipFamilies []corev1.IPFamily, used map[netip.Addr]bool) bool {
+	if len(ips) != len(ipFamilies) {
+		return false
+	}
+	for i, ip := range ips {
+		if ip.Family() != ipFamilies[i] || used[ip.Addr] || !slices.ContainsFunc(pool.Spec.CIDRs, func(cidr metalnetv1alpha1.IPPrefix) bool {
+			return cidr.Contains(ip.Addr)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// freeIPs returns the first free IP of the pool per IP family.
+func freeIPs(pool *metalnetv1alpha1.NetworkIPPool, ipFamilies []corev1.IPFamily, used map[netip.Addr]bool) ([]metalnetv1alpha1.IP, bool) {
+	var ips []metalnetv1alpha1.IP
+	for _, ipFamily := range ipFamilies {
+		var found bool
+		for _, cidr := range pool.Spec.CIDRs {
+			if cidr.IP().Family() != ipFamily {
+				continue
+			}
+			if addr, ok := FirstFreeAddr(cidr.Prefix, used); ok {
+				ips = append(ips, metalnetv1alpha1.IP{Addr: addr})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return ips, true
+}
+
+// FirstFreeAddr returns the first free address of the given prefix. The network address and the IPv4
+// broadcast address are never handed out, except for point-to-point prefixes.
+func FirstFreeAddr(prefix netip.Prefix, used map[netip.Addr]bool) (netip.Addr, bool) {
+	prefix = prefix.Masked()
+	addr := prefix.Addr()
+	pointToPoint := prefix.Bits() >= addr.BitLen()-1
+	if !pointToPoint {
+		addr = addr.Next()
+	}
+	for ; prefix.Contains(addr); addr = addr.Next() {
+		if addr.Is4() && !pointToPoint && !prefix.Contains(addr.Next()) {
+			break
+		}
+		if !used[addr] {
+			return addr, true
+		}
+	}
+	return netip.Addr{}, false
+}
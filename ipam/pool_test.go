@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipam_test
+
+import (
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/ipam"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Pool", func() {
+	var (
+		c       client.Client
+		pool    *ipam.Pool
+		network *metalnetv1alpha1.Network
+	)
+
+	newPool := func(name string, cidrs ...string) *metalnetv1alpha1.NetworkIPPool {
+		ipPool := &metalnetv1alpha1.NetworkIPPool{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: metalnetv1alpha1.NetworkIPPoolSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: network.Name},
+			},
+		}
+		for _, cidr := range cidrs {
+			ipPool.Spec.CIDRs = append(ipPool.Spec.CIDRs, metalnetv1alpha1.MustParseIPPrefix(cidr))
+		}
+		return ipPool
+	}
+
+	newNetworkInterface := func(name string, ipFamilies ...corev1.IPFamily) *metalnetv1alpha1.NetworkInterface {
+		return &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: network.Name},
+				IPFamilies: ipFamilies,
+			},
+		}
+	}
+
+	setup := func(objs ...client.Object) {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		c = fake.NewClientBuilder().
+			WithScheme(s).
+			WithObjects(objs...).
+			WithStatusSubresource(&metalnetv1alpha1.NetworkIPPool{}).
+			Build()
+		pool = ipam.NewPool(c)
+	}
+
+	allocations := func(ctx SpecContext, name string) []metalnetv1alpha1.NetworkIPPoolAllocation {
+		ipPool := &metalnetv1alpha1.NetworkIPPool{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, ipPool)).To(Succeed())
+		return ipPool.Status.Allocations
+	}
+
+	BeforeEach(func() {
+		network = &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 1000},
+		}
+	})
+
+	It("should allocate stable ips per ip family and release them", func(ctx SpecContext) {
+		static := newNetworkInterface("static", corev1.IPv4Protocol)
+		static.Spec.IPs = []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")}
+		setup(newPool("pool", "10.0.0.0/24", "fd00::/120"), static)
+
+		nic := newNetworkInterface("nic", corev1.IPv4Protocol, corev1.IPv6Protocol)
+		ips, err := pool.Allocate(ctx, nic, network)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(Equal([]metalnetv1alpha1.IP{
+			metalnetv1alpha1.MustParseIP("10.0.0.2"),
+			metalnetv1alpha1.MustParseIP("fd00::1"),
+		}))
+		Expect(allocations(ctx, "pool")).To(ConsistOf(metalnetv1alpha1.NetworkIPPoolAllocation{
+			NetworkInterfaceName: "nic",
+			IPs:                  ips,
+		}))
+
+		By("allocating again")
+		Expect(pool.Allocate(ctx, nic, network)).To(Equal(ips))
+
+		By("allocating for another network interface")
+		Expect(pool.Allocate(ctx, newNetworkInterface("other", corev1.IPv4Protocol), network)).
+			To(Equal([]metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.3")}))
+
+		By("releasing")
+		Expect(pool.Release(ctx, nic, network)).To(Succeed())
+		Expect(pool.Release(ctx, nic, network)).To(Succeed())
+		Expect(allocations(ctx, "pool")).To(ConsistOf(HaveField("NetworkInterfaceName", "other")))
+	})
+
+	It("should fall back to the next pool and report exhaustion", func(ctx SpecContext) {
+		setup(newPool("a", "10.0.0.0/30"), newPool("b", "10.0.1.0/31"))
+
+		var allocated []metalnetv1alpha1.IP
+		for _, name := range []string{"nic-1", "nic-2", "nic-3", "nic-4"} {
+			ips, err := pool.Allocate(ctx, newNetworkInterface(name, corev1.IPv4Protocol), network)
+			Expect(err).NotTo(HaveOccurred())
+			allocated = append(allocated, ips...)
+		}
+		Expect(allocated).To(Equal([]metalnetv1alpha1.IP{
+			metalnetv1alpha1.MustParseIP("10.0.0.1"),
+			metalnetv1alpha1.MustParseIP("10.0.0.2"),
+			metalnetv1alpha1.MustParseIP("10.0.1.0"),
+			metalnetv1alpha1.MustParseIP("10.0.1.1"),
+		}))
+
+		_, err := pool.Allocate(ctx, newNetworkInterface("nic-5", corev1.IPv4Protocol), network)
+		Expect(err).To(MatchError(ipam.ErrPoolExhausted))
+
+		By("requiring all ip families from the same pool")
+		_, err = pool.Allocate(ctx, newNetworkInterface("nic-6", corev1.IPv6Protocol), network)
+		Expect(err).To(MatchError(ipam.ErrPoolExhausted))
+	})
+
+	It("should report missing pools", func(ctx SpecContext) {
+		otherNetwork := newPool("other", "10.0.0.0/24")
+		otherNetwork.Spec.NetworkRef.Name = "other"
+		setup(otherNetwork)
+
+		_, err := pool.Allocate(ctx, newNetworkInterface("nic", corev1.IPv4Protocol), network)
+		Expect(err).To(MatchError(ipam.ErrNoMatchingPool))
+
+		By("allocating for a cluster network")
+		clusterNetwork := &metalnetv1alpha1.Network{ObjectMeta: metav1.ObjectMeta{Name: "network"}}
+		_, err = pool.Allocate(ctx, newNetworkInterface("nic", corev1.IPv4Protocol), clusterNetwork)
+		Expect(err).To(MatchError(ipam.ErrNoMatchingPool))
+	})
+})
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	WebhookOperationAllocate = "Allocate"
+	WebhookOperationRelease  = "Release"
+)
+
+// WebhookRequest is the body POSTed to an external IPAM.
+type WebhookRequest struct {
+	// Operation is either WebhookOperationAllocate or WebhookOperationRelease.
+	Operation string `json:"operation"`
+	// Namespace is the namespace of the NetworkInterface.
+	Namespace string `json:"namespace"`
+	// Name is the name of the NetworkInterface.
+	Name string `json:"name"`
+	// UID is the UID of the NetworkInterface.
+	UID types.UID `json:"uid"`
+	// Network is the Network of the NetworkInterface.
+	Network WebhookNetwork `json:"network"`
+	// IPFamilies are the IP families to allocate an IP for.
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+}
+
+// WebhookNetwork identifies the Network of a WebhookRequest.
+type WebhookNetwork struct {
+	// Namespace is the namespace of the Network. It is empty for ClusterNetworks.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the Network.
+	Name string `json:"name"`
+	// VNI is the VNI of the Network.
+	VNI int32 `json:"vni"`
+}
+
+// WebhookResponse is the body an external IPAM responds with.
+type WebhookResponse struct {
+	// IPs are the allocated IPs, one per requested IP family. It is empty for releases.
+	IPs []metalnetv1alpha1.IP `json:"ips,omitempty"`
+}
+
+// Webhook delegates the allocation of IPs to an external IPAM reachable via HTTP.
+//
+// Both operations are POSTed as WebhookRequest to the URL. The IPAM has to answer with a 2xx status code and,
+// for allocations, a WebhookResponse. Allocations have to be idempotent per NetworkInterface UID, and releases
+// of unknown NetworkInterfaces have to succeed.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook calls the external IPAM at the given URL with the given client. If the client is nil,
+// http.DefaultClient is used.
+func NewWebhook(url string, client *http.Client) *Webhook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Webhook{url: url, client: client}
+}
+
+func (w *Webhook) Allocate(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) ([]metalnetv1alpha1.IP, error) {
+	var res WebhookResponse
+	if err := w.call(ctx, newWebhookRequest(WebhookOperationAllocate, nic, network), &res); err != nil {
+		return nil, err
+	}
+	if len(res.IPs) != len(nic.Spec.IPFamilies) {
+		return nil, fmt.Errorf("ipam webhook returned %d ips for %d ip families", len(res.IPs), len(nic.Spec.IPFamilies))
+	}
+	for i, ip := range res.IPs {
+		if ip.Family() != nic.Spec.IPFamilies[i] {
+			return nil, fmt.Errorf("ipam webhook returned ip %s for ip family %s", ip, nic.Spec.IPFamilies[i])
+		}
+	}
+	return res.IPs, nil
+}
+
+func (w *Webhook) Release(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) error {
+	return w.call(ctx, newWebhookRequest(WebhookOperationRelease, nic, network), nil)
+}
+
+func newWebhookRequest(operation string, nic *metalnetv1alpha1.NetworkInterface, network *metalnetv1alpha1.Network) *WebhookRequest {
+	return &WebhookRequest{
+		Operation:  operation,
+		Namespace:  nic.Namespace,
+		Name:       nic.Name,
+		UID:        nic.UID,
+		Network:    WebhookNetwork{Namespace: network.Namespace, Name: network.Name, VNI: network.Spec.ID},
+		IPFamilies: nic.Spec.IPFamilies,
+	}
+}
+
+func (w *Webhook) call(ctx context.Context, webhookReq *WebhookRequest, webhookRes *WebhookResponse) error {
+	data, err := json.Marshal(webhookReq)
+	if err != nil {
+		return fmt.Errorf("error encoding ipam webhook request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating ipam webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling ipam webhook: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading ipam webhook response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("ipam webhook %s failed with status %d: %s", webhookReq.Operation, res.StatusCode, bytes.TrimSpace(body))
+	}
+	if webhookRes == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, webhookRes); err != nil {
+		return fmt.Errorf("error decoding ipam webhook response: %w", err)
+	}
+	return nil
+}
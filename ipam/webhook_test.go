@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipam_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/ipam"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Webhook", func() {
+	var (
+		requests []ipam.WebhookRequest
+		status   int
+		response string
+		webhook  *ipam.Webhook
+	)
+
+	nic := &metalnetv1alpha1.NetworkInterface{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nic", UID: "uid-nic"},
+		Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+			NetworkRef: corev1.LocalObjectReference{Name: "network"},
+			IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+		},
+	}
+	network := &metalnetv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network"},
+		Spec:       metalnetv1alpha1.NetworkSpec{ID: 1000},
+	}
+
+	BeforeEach(func() {
+		requests, status, response = nil, http.StatusOK, ""
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPost))
+			var req ipam.WebhookRequest
+			Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+			requests = append(requests, req)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+		DeferCleanup(server.Close)
+		webhook = ipam.NewWebhook(server.URL, server.Client())
+	})
+
+	It("should allocate and release ips via the external ipam", func(ctx SpecContext) {
+		response = `{"ips":["10.0.0.5","fd00::5"]}`
+		Expect(webhook.Allocate(ctx, nic, network)).To(Equal([]metalnetv1alpha1.IP{
+			metalnetv1alpha1.MustParseIP("10.0.0.5"),
+			metalnetv1alpha1.MustParseIP("fd00::5"),
+		}))
+
+		response = ""
+		Expect(webhook.Release(ctx, nic, network)).To(Succeed())
+
+		Expect(requests).To(Equal([]ipam.WebhookRequest{
+			{
+				Operation:  ipam.WebhookOperationAllocate,
+				Namespace:  "default",
+				Name:       "nic",
+				UID:        "uid-nic",
+				Network:    ipam.WebhookNetwork{Namespace: "default", Name: "network", VNI: 1000},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+			},
+			{
+				Operation:  ipam.WebhookOperationRelease,
+				Namespace:  "default",
+				Name:       "nic",
+				UID:        "uid-nic",
+				Network:    ipam.WebhookNetwork{Namespace: "default", Name: "network", VNI: 1000},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+			},
+		}))
+	})
+
+	It("should reject failed calls and ips not matching the ip families", func(ctx SpecContext) {
+		status, response = http.StatusConflict, "pool exhausted\n"
+		_, err := webhook.Allocate(ctx, nic, network)
+		Expect(err).To(MatchError(ContainSubstring("failed with status 409: pool exhausted")))
+
+		status, response = http.StatusOK, `{"ips":["fd00::5","10.0.0.5"]}`
+		_, err = webhook.Allocate(ctx, nic, network)
+		Expect(err).To(MatchError(ContainSubstring("returned ip fd00::5 for ip family IPv4")))
+	})
+})
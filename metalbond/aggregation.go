@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+type aggregationGroup struct {
+	vni     VNI
+	nextHop NextHop
+}
+
+type aggregatedRoutes struct {
+	// prefixes are the routes announced via the AggregatingRouteUtil.
+	prefixes map[netip.Prefix]struct{}
+	// announced are the aggregated routes announced via the underlying RouteUtil.
+	announced map[netip.Prefix]struct{}
+}
+
+// AggregatingRouteUtil is a RouteUtil that summarizes the routes of the configured VNIs before
+// announcing them, to keep the RIB of the fabric small on large clusters.
+//
+// Only routes with the same next hop are aggregated, and only into prefixes covering exactly the
+// announced routes: two adjacent /32 routes via the same next hop are announced as a single /31,
+// while a lone /32 route is announced as is. The aggregates are updated make-before-break.
+//
+// Aggregates denied by the underlying RouteUtil with a RouteDeniedError, e.g. by an announcement
+// policy, are replaced by the routes they cover.
+type AggregatingRouteUtil struct {
+	RouteUtil
+
+	vnis map[VNI]struct{}
+
+	mu     sync.Mutex
+	groups map[aggregationGroup]*aggregatedRoutes
+}
+
+// NewAggregatingRouteUtil aggregates the routes of the given VNIs. The routes of all other VNIs are passed through.
+func NewAggregatingRouteUtil(routeUtil RouteUtil, vnis []VNI) *AggregatingRouteUtil {
+	vniSet := make(map[VNI]struct{}, len(vnis))
+	for _, vni := range vnis {
+		vniSet[vni] = struct{}{}
+	}
+	return &AggregatingRouteUtil{
+		RouteUtil: routeUtil,
+		vnis:      vniSet,
+		groups:    make(map[aggregationGroup]*aggregatedRoutes),
+	}
+}
+
+func (u *AggregatingRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	if _, ok := u.vnis[vni]; !ok {
+		return u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := aggregationGroup{vni, nextHop}
+	routes, ok := u.groups[key]
+	if !ok {
+		routes = &aggregatedRoutes{
+			prefixes:  make(map[netip.Prefix]struct{}),
+			announced: make(map[netip.Prefix]struct{}),
+		}
+		u.groups[key] = routes
+	}
+	routes.prefixes[destination.Prefix.Masked()] = struct{}{}
+	return u.sync(ctx, key, routes)
+}
+
+func (u *AggregatingRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	if _, ok := u.vnis[vni]; !ok {
+		return u.RouteUtil.WithdrawRoute(ctx, vni, destination, nextHop)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := aggregationGroup{vni, nextHop}
+	routes, ok := u.groups[key]
+	if !ok {
+		return nil
+	}
+	delete(routes.prefixes, destination.Prefix.Masked())
+	if err := u.sync(ctx, key, routes); err != nil {
+		return err
+	}
+	if len(routes.prefixes) == 0 && len(routes.announced) == 0 {
+		delete(u.groups, key)
+	}
+	return nil
+}
+
+// sync announces the aggregates of the routes of a group before withdrawing the ones no longer needed.
+// Failed aggregates are retried with the next change of the group.
+func (u *AggregatingRouteUtil) sync(ctx context.Context, key aggregationGroup, routes *aggregatedRoutes) error {
+	desired := make(map[netip.Prefix]struct{})
+	queue := AggregatePrefixes(keys(routes.prefixes))
+
+	var errs []error
+	for len(queue) > 0 {
+		prefix := queue[0]
+		queue = queue[1:]
+		desired[prefix] = struct{}{}
+		if _, ok := routes.announced[prefix]; ok {
+			continue
+		}
+		err := IgnoreNextHopAlreadyExistsError(u.RouteUtil.AnnounceRoute(ctx, key.vni, Destination{Prefix: prefix}, key.nextHop))
+		if IsRouteDeniedError(err) {
+			if covered := routes.coveredBy(prefix); len(covered) > 0 {
+				delete(desired, prefix)
+				queue = append(queue, covered...)
+				continue
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error announcing aggregate %s: %w", prefix, err))
+			continue
+		}
+		routes.announced[prefix] = struct{}{}
+	}
+	if len(errs) > 0 {
+		// Keep the previous aggregates until all new ones are announced.
+		return errors.Join(errs...)
+	}
+
+	for prefix := range routes.announced {
+		if _, ok := desired[prefix]; ok {
+			continue
+		}
+		if err := IgnoreNextHopNotFoundError(u.RouteUtil.WithdrawRoute(ctx, key.vni, Destination{Prefix: prefix}, key.nextHop)); err != nil {
+			errs = append(errs, fmt.Errorf("error withdrawing aggregate %s: %w", prefix, err))
+			continue
+		}
+		delete(routes.announced, prefix)
+	}
+	return errors.Join(errs...)
+}
+
+// coveredBy returns the routes within the given aggregate, excluding the aggregate itself.
+func (r *aggregatedRoutes) coveredBy(aggregate netip.Prefix) []netip.Prefix {
+	var res []netip.Prefix
+	for prefix := range r.prefixes {
+		if prefix.Bits() > aggregate.Bits() && aggregate.Contains(prefix.Addr()) {
+			res = append(res, prefix)
+		}
+	}
+	return res
+}
+
+func keys(set map[netip.Prefix]struct{}) []netip.Prefix {
+	res := make([]netip.Prefix, 0, len(set))
+	for prefix := range set {
+		res = append(res, prefix)
+	}
+	return res
+}
+
+// AggregatePrefixes returns the smallest set of prefixes covering exactly the given prefixes, sorted.
+func AggregatePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	set := make(map[netip.Prefix]struct{}, len(prefixes))
+	for _, prefix := range prefixes {
+		set[prefix.Masked()] = struct{}{}
+	}
+
+	// Drop the prefixes covered by others.
+	for prefix := range set {
+		for bits := 0; bits < prefix.Bits(); bits++ {
+			if _, ok := set[netip.PrefixFrom(prefix.Addr(), bits).Masked()]; ok {
+				delete(set, prefix)
+				break
+			}
+		}
+	}
+
+	// Merge siblings into their parent until there are no siblings left.
+	for merged := true; merged; {
+		merged = false
+		for prefix := range set {
+			if prefix.Bits() == 0 {
+				continue
+			}
+			if _, ok := set[prefix]; !ok {
+				continue
+			}
+			sibling := siblingPrefix(prefix)
+			if _, ok := set[sibling]; !ok {
+				continue
+			}
+			delete(set, prefix)
+			delete(set, sibling)
+			set[netip.PrefixFrom(prefix.Addr(), prefix.Bits()-1).Masked()] = struct{}{}
+			merged = true
+		}
+	}
+
+	res := keys(set)
+	sort.Slice(res, func(i, j int) bool {
+		if c := res[i].Addr().Compare(res[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return res[i].Bits() < res[j].Bits()
+	})
+	return res
+}
+
+// siblingPrefix returns the other half of the parent of the given prefix.
+func siblingPrefix(prefix netip.Prefix) netip.Prefix {
+	bit := prefix.Bits() - 1
+	addr := prefix.Addr()
+	if addr.Is4() {
+		b := addr.As4()
+		b[bit/8] ^= 0x80 >> (bit % 8)
+		return netip.PrefixFrom(netip.AddrFrom4(b), prefix.Bits())
+	}
+	b := addr.As16()
+	b[bit/8] ^= 0x80 >> (bit % 8)
+	return netip.PrefixFrom(netip.AddrFrom16(b), prefix.Bits())
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+func parsePrefixes(prefixes ...string) []netip.Prefix {
+	res := make([]netip.Prefix, len(prefixes))
+	for i, prefix := range prefixes {
+		res[i] = netip.MustParsePrefix(prefix)
+	}
+	return res
+}
+
+var _ = Describe("AggregatingRouteUtil", func() {
+	var (
+		ctx       = context.TODO()
+		routeUtil *fakeRouteUtil
+		u         *metalbond.AggregatingRouteUtil
+
+		hopA = metalbond.NextHop{TargetAddress: netip.MustParseAddr("fc00::1")}
+		hopB = metalbond.NextHop{TargetAddress: netip.MustParseAddr("fc00::2")}
+	)
+	BeforeEach(func() {
+		routeUtil = &fakeRouteUtil{}
+		u = metalbond.NewAggregatingRouteUtil(routeUtil, []metalbond.VNI{100})
+	})
+
+	announce := func(vni metalbond.VNI, prefix string, hop metalbond.NextHop) {
+		GinkgoHelper()
+		Expect(u.AnnounceRoute(ctx, vni, metalbond.Destination{Prefix: netip.MustParsePrefix(prefix)}, hop)).To(Succeed())
+	}
+
+	withdraw := func(vni metalbond.VNI, prefix string, hop metalbond.NextHop) {
+		GinkgoHelper()
+		Expect(u.WithdrawRoute(ctx, vni, metalbond.Destination{Prefix: netip.MustParsePrefix(prefix)}, hop)).To(Succeed())
+	}
+
+	It("should aggregate adjacent routes with the same next hop", func() {
+		announce(100, "45.0.0.0/32", hopA)
+		announce(100, "45.0.0.1/32", hopA)
+		announce(100, "45.0.0.2/32", hopB)
+		Expect(routeUtil.announced).To(Equal(parsePrefixes("45.0.0.0/32", "45.0.0.0/31", "45.0.0.2/32")))
+		Expect(routeUtil.withdrawn).To(Equal(parsePrefixes("45.0.0.0/32")))
+
+		By("withdrawing a route of an aggregate")
+		withdraw(100, "45.0.0.0/32", hopA)
+		Expect(routeUtil.announced).To(HaveLen(4))
+		Expect(routeUtil.announced[3]).To(Equal(netip.MustParsePrefix("45.0.0.1/32")))
+		Expect(routeUtil.withdrawn).To(Equal(parsePrefixes("45.0.0.0/32", "45.0.0.0/31")))
+	})
+
+	It("should pass through the routes of other VNIs", func() {
+		announce(200, "45.0.0.0/32", hopA)
+		announce(200, "45.0.0.1/32", hopA)
+		Expect(routeUtil.announced).To(Equal(parsePrefixes("45.0.0.0/32", "45.0.0.1/32")))
+	})
+
+	It("should announce the routes of aggregates denied by the announcement policy individually", func() {
+		policy := &metalbond.AnnouncementPolicy{
+			Default: &metalbond.PrefixFilter{
+				Deny: []metalbond.PrefixListEntry{
+					{Prefix: netip.MustParsePrefix("45.0.0.0/8"), MaxLength: ptr.To(31)},
+					{Prefix: netip.MustParsePrefix("45.0.0.5/32")},
+				},
+			},
+		}
+		u := metalbond.NewPolicyRouteUtil(metalbond.NewAggregatingRouteUtil(metalbond.NewPolicyRouteUtil(routeUtil, policy), []metalbond.VNI{100}), policy)
+		for _, prefix := range []string{"45.0.0.0/32", "45.0.0.1/32", "45.0.0.4/32"} {
+			Expect(u.AnnounceRoute(ctx, 100, metalbond.Destination{Prefix: netip.MustParsePrefix(prefix)}, hopA)).To(Succeed())
+		}
+		Expect(routeUtil.announced).To(ConsistOf(parsePrefixes("45.0.0.0/32", "45.0.0.1/32", "45.0.0.4/32")))
+
+		By("denying a route that would be aggregated with an announced one")
+		err := u.AnnounceRoute(ctx, 100, metalbond.Destination{Prefix: netip.MustParsePrefix("45.0.0.5/32")}, hopA)
+		Expect(metalbond.IsRouteDeniedError(err)).To(BeTrue())
+		Expect(routeUtil.announced).To(ConsistOf(parsePrefixes("45.0.0.0/32", "45.0.0.1/32", "45.0.0.4/32")))
+		Expect(routeUtil.withdrawn).To(BeEmpty())
+	})
+
+	It("should compute exact aggregates", func() {
+		Expect(metalbond.AggregatePrefixes(parsePrefixes(
+			"10.0.0.0/32", "10.0.0.1/32", "10.0.0.2/31", "10.0.0.5/32", "10.0.0.0/30", "fd00::/128", "fd00::1/128",
+		))).To(Equal(parsePrefixes("10.0.0.0/30", "10.0.0.5/32", "fd00::/127")))
+	})
+})
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+
+	"github.com/ironcore-dev/metalbond/pb"
+)
+
+type aliasPrefixKey struct {
+	vni    VNI
+	prefix netip.Prefix
+}
+
+type aliasPrefix struct {
+	// interfaces maps the local interfaces the prefix is applied to to their underlay route.
+	interfaces map[string]netip.Addr
+	// affinity is the interface whose underlay route the prefix is announced with.
+	affinity string
+	// announced is the announced underlay route. Invalid if the prefix is not announced.
+	announced netip.Addr
+}
+
+// AliasPrefixAnnouncer announces the alias prefixes of the local interfaces once per node.
+//
+// An alias prefix applied to several interfaces is announced with the underlay route of one of
+// its local interfaces, so every node announces its own next hop and the fabric can ECMP across
+// the nodes. If that interface goes away, the prefix is announced with the underlay route of
+// another local interface. The prefix is withdrawn once the last local interface is gone.
+type AliasPrefixAnnouncer struct {
+	routeUtil RouteUtil
+
+	mu       sync.Mutex
+	prefixes map[aliasPrefixKey]*aliasPrefix
+}
+
+func NewAliasPrefixAnnouncer(routeUtil RouteUtil) *AliasPrefixAnnouncer {
+	return &AliasPrefixAnnouncer{
+		routeUtil: routeUtil,
+		prefixes:  make(map[aliasPrefixKey]*aliasPrefix),
+	}
+}
+
+func aliasPrefixNextHop(underlayRoute netip.Addr) NextHop {
+	return NextHop{
+		TargetVNI:     0,
+		TargetAddress: underlayRoute,
+		TargetHopType: pb.NextHopType_STANDARD,
+	}
+}
+
+// Add records that the prefix is applied to the given interface and announces it if needed.
+func (a *AliasPrefixAnnouncer) Add(ctx context.Context, vni VNI, prefix netip.Prefix, interfaceID string, underlayRoute netip.Addr) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := aliasPrefixKey{vni, prefix}
+	p, ok := a.prefixes[key]
+	if !ok {
+		p = &aliasPrefix{interfaces: make(map[string]netip.Addr)}
+		a.prefixes[key] = p
+	}
+	p.interfaces[interfaceID] = underlayRoute
+
+	if _, ok := p.interfaces[p.affinity]; ok && p.affinity != interfaceID && p.announced.IsValid() {
+		return nil
+	}
+	return a.announce(ctx, key, p, interfaceID)
+}
+
+// Remove records that the prefix is no longer applied to the given interface. The prefix is
+// announced with another local interface or withdrawn if there is none left.
+func (a *AliasPrefixAnnouncer) Remove(ctx context.Context, vni VNI, prefix netip.Prefix, interfaceID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := aliasPrefixKey{vni, prefix}
+	p, ok := a.prefixes[key]
+	if !ok {
+		return nil
+	}
+	delete(p.interfaces, interfaceID)
+
+	if len(p.interfaces) == 0 {
+		if p.announced.IsValid() {
+			if err := IgnoreNextHopNotFoundError(a.routeUtil.WithdrawRoute(ctx, vni, Destination{Prefix: prefix}, aliasPrefixNextHop(p.announced))); err != nil {
+				return fmt.Errorf("error withdrawing alias prefix route: %w", err)
+			}
+		}
+		delete(a.prefixes, key)
+		return nil
+	}
+
+	if p.affinity != interfaceID {
+		return nil
+	}
+	return a.announce(ctx, key, p, nextAffinity(p.interfaces))
+}
+
+// announce announces the prefix with the underlay route of the given interface and withdraws
+// the previously announced underlay route, if any.
+func (a *AliasPrefixAnnouncer) announce(ctx context.Context, key aliasPrefixKey, p *aliasPrefix, interfaceID string) error {
+	underlayRoute := p.interfaces[interfaceID]
+	if p.announced == underlayRoute {
+		p.affinity = interfaceID
+		return nil
+	}
+
+	if err := IgnoreNextHopAlreadyExistsError(a.routeUtil.AnnounceRoute(ctx, key.vni, Destination{Prefix: key.prefix}, aliasPrefixNextHop(underlayRoute))); err != nil {
+		return fmt.Errorf("error announcing alias prefix route: %w", err)
+	}
+	previous := p.announced
+	p.affinity, p.announced = interfaceID, underlayRoute
+
+	if previous.IsValid() {
+		if err := IgnoreNextHopNotFoundError(a.routeUtil.WithdrawRoute(ctx, key.vni, Destination{Prefix: key.prefix}, aliasPrefixNextHop(previous))); err != nil {
+			return fmt.Errorf("error withdrawing previous alias prefix route: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextAffinity deterministically picks the interface a prefix is announced with.
+func nextAffinity(interfaces map[string]netip.Addr) string {
+	ids := make([]string, 0, len(interfaces))
+	for id := range interfaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids[0]
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeNextHopRouteUtil records the announced next hops. Calling any other method panics.
+type fakeNextHopRouteUtil struct {
+	metalbond.RouteUtil
+	announced map[netip.Addr]struct{}
+}
+
+func (u *fakeNextHopRouteUtil) AnnounceRoute(_ context.Context, _ metalbond.VNI, _ metalbond.Destination, nextHop metalbond.NextHop) error {
+	u.announced[nextHop.TargetAddress] = struct{}{}
+	return nil
+}
+
+func (u *fakeNextHopRouteUtil) WithdrawRoute(_ context.Context, _ metalbond.VNI, _ metalbond.Destination, nextHop metalbond.NextHop) error {
+	delete(u.announced, nextHop.TargetAddress)
+	return nil
+}
+
+func (u *fakeNextHopRouteUtil) nextHops() []netip.Addr {
+	var res []netip.Addr
+	for addr := range u.announced {
+		res = append(res, addr)
+	}
+	return res
+}
+
+var _ = Describe("AliasPrefixAnnouncer", func() {
+	var (
+		ctx       = context.TODO()
+		routeUtil *fakeNextHopRouteUtil
+		a         *metalbond.AliasPrefixAnnouncer
+
+		prefix      = netip.MustParsePrefix("10.0.1.0/24")
+		underlayFoo = netip.MustParseAddr("fc00::1")
+		underlayBar = netip.MustParseAddr("fc00::2")
+		underlayNew = netip.MustParseAddr("fc00::3")
+	)
+	BeforeEach(func() {
+		routeUtil = &fakeNextHopRouteUtil{announced: make(map[netip.Addr]struct{})}
+		a = metalbond.NewAliasPrefixAnnouncer(routeUtil)
+	})
+
+	It("should announce a prefix once per node until the last interface is gone", func() {
+		Expect(a.Add(ctx, 1, prefix, "foo", underlayFoo)).To(Succeed())
+		Expect(a.Add(ctx, 1, prefix, "bar", underlayBar)).To(Succeed())
+		Expect(routeUtil.nextHops()).To(ConsistOf(underlayFoo))
+
+		By("removing the interface the prefix is announced with")
+		Expect(a.Remove(ctx, 1, prefix, "foo")).To(Succeed())
+		Expect(routeUtil.nextHops()).To(ConsistOf(underlayBar))
+
+		By("removing the last interface")
+		Expect(a.Remove(ctx, 1, prefix, "bar")).To(Succeed())
+		Expect(routeUtil.nextHops()).To(BeEmpty())
+		Expect(a.Remove(ctx, 1, prefix, "bar")).To(Succeed())
+	})
+
+	It("should follow the underlay route of the interface the prefix is announced with", func() {
+		Expect(a.Add(ctx, 1, prefix, "foo", underlayFoo)).To(Succeed())
+		Expect(a.Add(ctx, 1, prefix, "foo", underlayNew)).To(Succeed())
+		Expect(routeUtil.nextHops()).To(ConsistOf(underlayNew))
+	})
+})
@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AnnouncementPolicy decides which routes metalnet may announce via metalbond.
+//
+// Example:
+//
+//	default:
+//	  deny:
+//	  - prefix: 0.0.0.0/0
+//	  - prefix: ::/0
+//	vnis:
+//	  100:
+//	    allow:
+//	    - prefix: 45.0.0.0/16
+//	      maxLength: 32
+type AnnouncementPolicy struct {
+	// Default is the filter of all VNIs without a filter of their own.
+	Default *PrefixFilter `json:"default,omitempty"`
+	// VNIs are the filters per VNI.
+	VNIs map[VNI]PrefixFilter `json:"vnis,omitempty"`
+}
+
+// PrefixFilter is an allow / deny prefix list.
+//
+// A prefix matching any deny entry is denied. Otherwise, if there are allow entries,
+// the prefix has to match one of them.
+type PrefixFilter struct {
+	Allow []PrefixListEntry `json:"allow,omitempty"`
+	Deny  []PrefixListEntry `json:"deny,omitempty"`
+}
+
+// PrefixListEntry matches all prefixes within Prefix whose length is between MinLength and MaxLength.
+type PrefixListEntry struct {
+	Prefix netip.Prefix `json:"prefix"`
+	// MinLength is the minimum length of matching prefixes. Defaults to the length of Prefix.
+	MinLength *int `json:"minLength,omitempty"`
+	// MaxLength is the maximum length of matching prefixes. Defaults to the length of Prefix.
+	MaxLength *int `json:"maxLength,omitempty"`
+}
+
+// LoadAnnouncementPolicy reads and validates the announcement policy in the given YAML or JSON file.
+func LoadAnnouncementPolicy(filename string) (*AnnouncementPolicy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading announcement policy: %w", err)
+	}
+
+	policy := &AnnouncementPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("error decoding announcement policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid announcement policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (p *AnnouncementPolicy) Validate() error {
+	if p.Default != nil {
+		if err := p.Default.validate(); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	for vni, filter := range p.VNIs {
+		if err := filter.validate(); err != nil {
+			return fmt.Errorf("vni %d: %w", vni, err)
+		}
+	}
+	return nil
+}
+
+func (f *PrefixFilter) validate() error {
+	for i, entry := range f.Allow {
+		if err := entry.validate(); err != nil {
+			return fmt.Errorf("allow[%d]: %w", i, err)
+		}
+	}
+	for i, entry := range f.Deny {
+		if err := entry.validate(); err != nil {
+			return fmt.Errorf("deny[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (e *PrefixListEntry) validate() error {
+	if !e.Prefix.IsValid() {
+		return fmt.Errorf("prefix is required")
+	}
+	minLength, maxLength := e.lengthRange()
+	if minLength < e.Prefix.Bits() || maxLength > e.Prefix.Addr().BitLen() || minLength > maxLength {
+		return fmt.Errorf("invalid length range %d-%d for prefix %s", minLength, maxLength, e.Prefix)
+	}
+	return nil
+}
+
+func (e *PrefixListEntry) lengthRange() (int, int) {
+	minLength, maxLength := e.Prefix.Bits(), e.Prefix.Bits()
+	if e.MinLength != nil {
+		minLength = *e.MinLength
+	}
+	if e.MaxLength != nil {
+		maxLength = *e.MaxLength
+	}
+	return minLength, maxLength
+}
+
+func (e *PrefixListEntry) matches(prefix netip.Prefix) bool {
+	if prefix.Addr().Is4() != e.Prefix.Addr().Is4() || !e.Prefix.Contains(prefix.Addr()) {
+		return false
+	}
+	minLength, maxLength := e.lengthRange()
+	return prefix.Bits() >= minLength && prefix.Bits() <= maxLength
+}
+
+func (f *PrefixFilter) allows(prefix netip.Prefix) bool {
+	for _, entry := range f.Deny {
+		if entry.matches(prefix) {
+			return false
+		}
+	}
+	if len(f.Allow) == 0 {
+		return true
+	}
+	for _, entry := range f.Allow {
+		if entry.matches(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the prefix may be announced in the given VNI.
+func (p *AnnouncementPolicy) Allows(vni VNI, prefix netip.Prefix) bool {
+	if filter, ok := p.VNIs[vni]; ok {
+		return filter.allows(prefix.Masked())
+	}
+	if p.Default != nil {
+		return p.Default.allows(prefix.Masked())
+	}
+	return true
+}
+
+type policyRouteUtil struct {
+	RouteUtil
+	policy *AnnouncementPolicy
+}
+
+// NewPolicyRouteUtil returns a RouteUtil that refuses to announce routes denied by the given policy.
+// Withdrawals are never filtered, so routes announced before the policy changed can still be removed.
+func NewPolicyRouteUtil(routeUtil RouteUtil, policy *AnnouncementPolicy) RouteUtil {
+	return &policyRouteUtil{routeUtil, policy}
+}
+
+func (u *policyRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	if !u.policy.Allows(vni, destination.Prefix) {
+		return &RouteDeniedError{VNI: vni, Prefix: destination.Prefix}
+	}
+	return u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop)
+}
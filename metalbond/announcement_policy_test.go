@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRouteUtil records the announced and withdrawn routes and the subscribed VNIs. Calling any other
+// method panics.
+type fakeRouteUtil struct {
+	metalbond.RouteUtil
+	announced  []netip.Prefix
+	withdrawn  []netip.Prefix
+	subscribed []metalbond.VNI
+}
+
+func (u *fakeRouteUtil) AnnounceRoute(_ context.Context, _ metalbond.VNI, destination metalbond.Destination, _ metalbond.NextHop) error {
+	u.announced = append(u.announced, destination.Prefix)
+	return nil
+}
+
+func (u *fakeRouteUtil) WithdrawRoute(_ context.Context, _ metalbond.VNI, destination metalbond.Destination, _ metalbond.NextHop) error {
+	u.withdrawn = append(u.withdrawn, destination.Prefix)
+	return nil
+}
+
+func (u *fakeRouteUtil) Subscribe(_ context.Context, vni metalbond.VNI) error {
+	u.subscribed = append(u.subscribed, vni)
+	return nil
+}
+
+func writePolicy(content string) string {
+	filename := filepath.Join(GinkgoT().TempDir(), "policy.yaml")
+	Expect(os.WriteFile(filename, []byte(content), 0644)).To(Succeed())
+	return filename
+}
+
+var _ = Describe("AnnouncementPolicy", func() {
+	It("should apply the default and the per VNI prefix lists", func() {
+		policy, err := metalbond.LoadAnnouncementPolicy(writePolicy(`
+default:
+  deny:
+  - prefix: 0.0.0.0/0
+vnis:
+  100:
+    allow:
+    - prefix: 45.0.0.0/16
+      maxLength: 32
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policy.Allows(1, netip.MustParsePrefix("0.0.0.0/0"))).To(BeFalse())
+		Expect(policy.Allows(1, netip.MustParsePrefix("10.0.0.0/8"))).To(BeTrue())
+		Expect(policy.Allows(100, netip.MustParsePrefix("45.0.1.1/32"))).To(BeTrue())
+		Expect(policy.Allows(100, netip.MustParsePrefix("45.0.0.0/15"))).To(BeFalse())
+		Expect(policy.Allows(100, netip.MustParsePrefix("46.0.0.1/32"))).To(BeFalse())
+	})
+
+	It("should match prefixes within the length range of an entry", func() {
+		policy, err := metalbond.LoadAnnouncementPolicy(writePolicy(`
+default:
+  deny:
+  - prefix: 10.0.0.0/8
+    minLength: 8
+    maxLength: 16
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policy.Allows(1, netip.MustParsePrefix("10.1.0.0/16"))).To(BeFalse())
+		Expect(policy.Allows(1, netip.MustParsePrefix("10.1.1.0/24"))).To(BeTrue())
+		Expect(policy.Allows(1, netip.MustParsePrefix("fd00::/8"))).To(BeTrue())
+	})
+
+	It("should reject invalid length ranges", func() {
+		_, err := metalbond.LoadAnnouncementPolicy(writePolicy(`
+default:
+  allow:
+  - prefix: 10.0.0.0/8
+    maxLength: 33
+`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should refuse to announce denied routes", func() {
+		routeUtil := &fakeRouteUtil{}
+		policyRouteUtil := metalbond.NewPolicyRouteUtil(routeUtil, &metalbond.AnnouncementPolicy{
+			Default: &metalbond.PrefixFilter{
+				Deny: []metalbond.PrefixListEntry{{Prefix: netip.MustParsePrefix("0.0.0.0/0")}},
+			},
+		})
+
+		err := policyRouteUtil.AnnounceRoute(context.TODO(), 1, metalbond.Destination{Prefix: netip.MustParsePrefix("0.0.0.0/0")}, metalbond.NextHop{})
+		Expect(metalbond.IsRouteDeniedError(err)).To(BeTrue())
+		Expect(policyRouteUtil.AnnounceRoute(context.TODO(), 1, metalbond.Destination{Prefix: netip.MustParsePrefix("10.0.0.1/32")}, metalbond.NextHop{})).To(Succeed())
+		Expect(routeUtil.announced).To(Equal([]netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}))
+	})
+})
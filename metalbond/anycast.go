@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var anycastDestinations = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "metalnet_metalbond_anycast_destinations",
+	Help: "Number of metalbond destinations currently announced with more than one next hop, e.g. by active-active load balancers.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(anycastDestinations)
+}
+
+type anycastRoutes struct {
+	hops     map[mb.NextHop]struct{}
+	selected mb.NextHop
+}
+
+// anycastRouteLocks is the number of locks updates of the same destination are serialized with.
+const anycastRouteLocks = 64
+
+// AnycastRouteClient is a metalbond client for destinations announced with several next hops, e.g. the ip of
+// a LoadBalancer programmed on several nodes. dpservice holds a single route per destination and removes it
+// with any of its hops, so only one hop per destination is passed to the wrapped client. The selected hop is
+// kept until it is withdrawn, so established connections are not moved. Another hop takes over then, chosen by
+// rendezvous hashing with the given seed, so the nodes spread across the remaining hops.
+//
+// Load balancer target and NAT hops are passed through.
+type AnycastRouteClient struct {
+	client mb.Client
+	seed   string
+	log    *logr.Logger
+
+	locks [anycastRouteLocks]sync.Mutex
+
+	mu     sync.Mutex
+	routes map[routeDestination]*anycastRoutes
+}
+
+func NewAnycastRouteClient(log *logr.Logger, client mb.Client, seed string) *AnycastRouteClient {
+	return &AnycastRouteClient{
+		client: client,
+		seed:   seed,
+		log:    log,
+		routes: make(map[routeDestination]*anycastRoutes),
+	}
+}
+
+func (c *AnycastRouteClient) lock(key routeDestination) *sync.Mutex {
+	return &c.locks[key.hash()%anycastRouteLocks]
+}
+
+// preferred returns the hop with the highest rendezvous hash.
+func (c *AnycastRouteClient) preferred(routes *anycastRoutes) mb.NextHop {
+	var (
+		preferred mb.NextHop
+		maxScore  uint32
+		first     = true
+	)
+	for hop := range routes.hops {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(c.seed))
+		_, _ = h.Write([]byte(hop.TargetAddress.String()))
+		score := h.Sum32()
+		if first || score > maxScore || (score == maxScore && hop.TargetAddress.Less(preferred.TargetAddress)) {
+			preferred, maxScore, first = hop, score, false
+		}
+	}
+	return preferred
+}
+
+func (c *AnycastRouteClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	if hop.Type != pb.NextHopType_STANDARD {
+		return c.client.AddRoute(vni, dest, hop)
+	}
+
+	key := routeDestination{vni: vni, dest: dest}
+	lock := c.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	routes, ok := c.routes[key]
+	if !ok {
+		routes = &anycastRoutes{hops: make(map[mb.NextHop]struct{})}
+		c.routes[key] = routes
+	}
+	c.mu.Unlock()
+
+	if _, ok := routes.hops[hop]; ok {
+		if hop != routes.selected {
+			return nil
+		}
+		return c.client.AddRoute(vni, dest, hop)
+	}
+	routes.hops[hop] = struct{}{}
+	switch len(routes.hops) {
+	case 1:
+		routes.selected = hop
+		return c.client.AddRoute(vni, dest, hop)
+	case 2:
+		anycastDestinations.Inc()
+	}
+	c.log.V(1).Info("Holding back additional next hop of destination",
+		"VNI", vni, "Destination", dest, "NextHop", hop, "SelectedNextHop", routes.selected)
+	return nil
+}
+
+func (c *AnycastRouteClient) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	if hop.Type != pb.NextHopType_STANDARD {
+		return c.client.RemoveRoute(vni, dest, hop)
+	}
+
+	key := routeDestination{vni: vni, dest: dest}
+	lock := c.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	routes, ok := c.routes[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if _, ok := routes.hops[hop]; !ok {
+		return nil
+	}
+
+	delete(routes.hops, hop)
+	switch len(routes.hops) {
+	case 0:
+		c.mu.Lock()
+		delete(c.routes, key)
+		c.mu.Unlock()
+		return c.client.RemoveRoute(vni, dest, hop)
+	case 1:
+		anycastDestinations.Dec()
+	}
+	if hop != routes.selected {
+		return nil
+	}
+
+	routes.selected = c.preferred(routes)
+	c.log.Info("Switching metalbond destination to another next hop",
+		"VNI", vni, "Destination", dest, "PreviousNextHop", hop, "NextHop", routes.selected)
+	return errors.Join(
+		c.client.RemoveRoute(vni, dest, hop),
+		c.client.AddRoute(vni, dest, routes.selected),
+	)
+}
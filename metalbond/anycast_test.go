@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AnycastRouteClient", func() {
+	var (
+		client *hopRecordingClient
+		c      *metalbond.AnycastRouteClient
+		dest   = mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+	)
+	hop := func(addr string) mb.NextHop {
+		return mb.NextHop{TargetAddress: netip.MustParseAddr(addr), Type: pb.NextHopType_STANDARD}
+	}
+
+	BeforeEach(func() {
+		client = &hopRecordingClient{hops: make(map[mb.NextHop]struct{})}
+		log := logr.Discard()
+		c = metalbond.NewAnycastRouteClient(&log, client, "node-1")
+	})
+
+	It("should keep the first hop of a destination until it is withdrawn", func() {
+		first, second, third := hop("fc00::1"), hop("fc00::2"), hop("fc00::3")
+
+		Expect(c.AddRoute(100, dest, first)).To(Succeed())
+		Expect(c.AddRoute(100, dest, second)).To(Succeed())
+		Expect(c.AddRoute(100, dest, third)).To(Succeed())
+		Expect(client.Hops()).To(ConsistOf(first))
+
+		By("withdrawing a hop that is not selected")
+		Expect(c.RemoveRoute(100, dest, second)).To(Succeed())
+		Expect(client.Hops()).To(ConsistOf(first))
+
+		By("withdrawing the selected hop")
+		Expect(c.RemoveRoute(100, dest, first)).To(Succeed())
+		Expect(client.Hops()).To(ConsistOf(third))
+
+		Expect(c.RemoveRoute(100, dest, third)).To(Succeed())
+		Expect(client.Hops()).To(BeEmpty())
+	})
+
+	It("should spread the nodes across the remaining hops", func() {
+		hops := []mb.NextHop{hop("fc00::1"), hop("fc00::2"), hop("fc00::3"), hop("fc00::4")}
+		selected := make(map[mb.NextHop]struct{})
+		for _, seed := range []string{"node-1", "node-2", "node-3", "node-4", "node-5", "node-6", "node-7", "node-8"} {
+			client := &hopRecordingClient{hops: make(map[mb.NextHop]struct{})}
+			log := logr.Discard()
+			c := metalbond.NewAnycastRouteClient(&log, client, seed)
+			for _, h := range hops {
+				Expect(c.AddRoute(100, dest, h)).To(Succeed())
+			}
+			Expect(c.RemoveRoute(100, dest, hops[0])).To(Succeed())
+			Expect(client.Hops()).To(HaveLen(1))
+			selected[client.Hops()[0]] = struct{}{}
+		}
+		Expect(len(selected)).To(BeNumerically(">", 1))
+	})
+
+	It("should pass through load balancer target hops", func() {
+		target1 := mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_LOADBALANCER_TARGET}
+		target2 := mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::2"), Type: pb.NextHopType_LOADBALANCER_TARGET}
+
+		Expect(c.AddRoute(100, dest, target1)).To(Succeed())
+		Expect(c.AddRoute(100, dest, target2)).To(Succeed())
+		Expect(client.Hops()).To(ConsistOf(target1, target2))
+	})
+})
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+
+	"github.com/ironcore-dev/metalnet/chaos"
+)
+
+// ChaosRouteUtil is a RouteUtil injecting random delays and failures into route announcements, withdrawals
+// and subscription changes.
+type ChaosRouteUtil struct {
+	RouteUtil
+	injector *chaos.Injector
+}
+
+func NewChaosRouteUtil(routeUtil RouteUtil, injector *chaos.Injector) *ChaosRouteUtil {
+	return &ChaosRouteUtil{RouteUtil: routeUtil, injector: injector}
+}
+
+func (u *ChaosRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	return u.injector.Do(ctx, "metalbond", "AnnounceRoute", func(ctx context.Context) error {
+		return u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop)
+	})
+}
+
+func (u *ChaosRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	return u.injector.Do(ctx, "metalbond", "WithdrawRoute", func(ctx context.Context) error {
+		return u.RouteUtil.WithdrawRoute(ctx, vni, destination, nextHop)
+	})
+}
+
+func (u *ChaosRouteUtil) Subscribe(ctx context.Context, vni VNI) error {
+	return u.injector.Do(ctx, "metalbond", "Subscribe", func(ctx context.Context) error {
+		return u.RouteUtil.Subscribe(ctx, vni)
+	})
+}
+
+func (u *ChaosRouteUtil) Unsubscribe(ctx context.Context, vni VNI) error {
+	return u.injector.Do(ctx, "metalbond", "Unsubscribe", func(ctx context.Context) error {
+		return u.RouteUtil.Unsubscribe(ctx, vni)
+	})
+}
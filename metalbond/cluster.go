@@ -0,0 +1,294 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	clusterRoutesRejected = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metalnet_metalbond_cluster_routes_rejected_total",
+		Help: "Number of received metalbond routes not programmed because their cluster is not a peer cluster.",
+	})
+	clusterRouteConflicts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "metalnet_metalbond_cluster_route_conflicts",
+		Help: "Number of metalbond destinations currently announced by more than one cluster.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(clusterRoutesRejected, clusterRouteConflicts)
+}
+
+// Standard hops do not use the NAT port range, so its start carries the id of the cluster announcing
+// the route. Zero means the route was announced without a cluster id.
+func encodeClusterID(id uint16) uint16 {
+	return id
+}
+
+// ClusterIDFromNextHop returns the id of the cluster that announced a hop, zero if it carries none.
+// Only standard hops carry a cluster id.
+func ClusterIDFromNextHop(hop mb.NextHop) uint16 {
+	if hop.Type != pb.NextHopType_STANDARD {
+		return 0
+	}
+	return hop.NATPortRangeFrom
+}
+
+// ClusterRouteUtil is a RouteUtil that tags the announced standard hops with the id of the local cluster,
+// so nodes of other clusters subscribed to the same VNI can tell the routes of the clusters apart.
+type ClusterRouteUtil struct {
+	RouteUtil
+
+	clusterID uint16
+}
+
+func NewClusterRouteUtil(routeUtil RouteUtil, clusterID uint16) *ClusterRouteUtil {
+	return &ClusterRouteUtil{
+		RouteUtil: routeUtil,
+		clusterID: clusterID,
+	}
+}
+
+func (u *ClusterRouteUtil) tag(nextHop NextHop) NextHop {
+	if nextHop.TargetHopType == pb.NextHopType_STANDARD {
+		nextHop.TargetClusterID = u.clusterID
+	}
+	return nextHop
+}
+
+func (u *ClusterRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	return u.RouteUtil.AnnounceRoute(ctx, vni, destination, u.tag(nextHop))
+}
+
+func (u *ClusterRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	return u.RouteUtil.WithdrawRoute(ctx, vni, destination, u.tag(nextHop))
+}
+
+type ClusterRouteOptions struct {
+	// ClusterID is the id of the local cluster. Routes without a cluster id are considered local.
+	ClusterID uint16
+	// PeerClusterIDs are the ids of the remote clusters whose routes are accepted. Empty accepts the
+	// routes of all clusters.
+	PeerClusterIDs []uint16
+}
+
+// routeDestination is a destination in a VNI.
+type routeDestination struct {
+	vni  mb.VNI
+	dest mb.Destination
+}
+
+func (k routeDestination) hash() uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte{byte(k.vni >> 24), byte(k.vni >> 16), byte(k.vni >> 8), byte(k.vni)})
+	_, _ = h.Write([]byte(k.dest.Prefix.String()))
+	return h.Sum32()
+}
+
+type clusterRoutes struct {
+	// hops are the received next hops by the id of the cluster announcing them.
+	hops map[uint16]map[mb.NextHop]struct{}
+	// selected is the cluster whose hops are passed to the wrapped client.
+	selected uint16
+}
+
+// clusterRouteLocks is the number of locks updates of the same destination are serialized with.
+const clusterRouteLocks = 64
+
+// ClusterRouteClient is a metalbond client resolving conflicts between clusters announcing the same
+// destination in a VNI. dpservice holds a single route per destination, so only the hops of the preferred
+// cluster are passed to the wrapped client: the local cluster is preferred over remote ones, remote
+// clusters are preferred by their lowest id. The hops of other clusters are held back and programmed once
+// the preferred cluster withdraws its routes.
+//
+// Routes of remote clusters that are not peer clusters are dropped. Load balancer target and NAT hops do
+// not carry a cluster id and are passed through.
+type ClusterRouteClient struct {
+	client    mb.Client
+	clusterID uint16
+	peers     map[uint16]struct{}
+	log       *logr.Logger
+
+	locks [clusterRouteLocks]sync.Mutex
+
+	mu     sync.Mutex
+	routes map[routeDestination]*clusterRoutes
+}
+
+func NewClusterRouteClient(log *logr.Logger, client mb.Client, opts ClusterRouteOptions) *ClusterRouteClient {
+	peers := make(map[uint16]struct{}, len(opts.PeerClusterIDs))
+	for _, id := range opts.PeerClusterIDs {
+		peers[id] = struct{}{}
+	}
+	return &ClusterRouteClient{
+		client:    client,
+		clusterID: opts.ClusterID,
+		peers:     peers,
+		log:       log,
+		routes:    make(map[routeDestination]*clusterRoutes),
+	}
+}
+
+func (c *ClusterRouteClient) clusterOf(hop mb.NextHop) uint16 {
+	if id := ClusterIDFromNextHop(hop); id != 0 {
+		return id
+	}
+	return c.clusterID
+}
+
+func (c *ClusterRouteClient) accepted(cluster uint16) bool {
+	if cluster == c.clusterID || len(c.peers) == 0 {
+		return true
+	}
+	_, ok := c.peers[cluster]
+	return ok
+}
+
+// lock returns the lock serializing the updates of the given destination.
+func (c *ClusterRouteClient) lock(key routeDestination) *sync.Mutex {
+	return &c.locks[key.hash()%clusterRouteLocks]
+}
+
+func (c *ClusterRouteClient) preferred(routes *clusterRoutes) uint16 {
+	if _, ok := routes.hops[c.clusterID]; ok {
+		return c.clusterID
+	}
+	var preferred uint16
+	first := true
+	for cluster := range routes.hops {
+		if first || cluster < preferred {
+			preferred, first = cluster, false
+		}
+	}
+	return preferred
+}
+
+// switchCluster removes the given hops of the previously selected cluster from the wrapped client and
+// passes the hops of the given cluster instead.
+func (c *ClusterRouteClient) switchCluster(key routeDestination, routes *clusterRoutes, removed []mb.NextHop, cluster uint16) error {
+	c.log.Info("Switching metalbond destination to routes of another cluster",
+		"VNI", key.vni, "Destination", key.dest, "PreviousClusterID", routes.selected, "ClusterID", cluster)
+
+	var errs []error
+	for _, hop := range removed {
+		if err := c.client.RemoveRoute(key.vni, key.dest, hop); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	routes.selected = cluster
+	for hop := range routes.hops[cluster] {
+		if err := c.client.AddRoute(key.vni, key.dest, hop); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *ClusterRouteClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	if hop.Type != pb.NextHopType_STANDARD {
+		return c.client.AddRoute(vni, dest, hop)
+	}
+	cluster := c.clusterOf(hop)
+	if !c.accepted(cluster) {
+		clusterRoutesRejected.Inc()
+		c.log.V(1).Info("Rejecting route of a cluster that is not a peer cluster",
+			"VNI", vni, "Destination", dest, "NextHop", hop, "ClusterID", cluster)
+		return nil
+	}
+
+	key := routeDestination{vni: vni, dest: dest}
+	lock := c.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	routes, ok := c.routes[key]
+	if !ok {
+		routes = &clusterRoutes{hops: make(map[uint16]map[mb.NextHop]struct{})}
+		c.routes[key] = routes
+	}
+	c.mu.Unlock()
+
+	hadRoutes := len(routes.hops) > 0
+	if _, ok := routes.hops[cluster]; !ok {
+		routes.hops[cluster] = make(map[mb.NextHop]struct{})
+		if len(routes.hops) == 2 {
+			clusterRouteConflicts.Inc()
+		}
+	}
+	routes.hops[cluster][hop] = struct{}{}
+
+	if !hadRoutes {
+		routes.selected = cluster
+		return c.client.AddRoute(vni, dest, hop)
+	}
+	if preferred := c.preferred(routes); preferred != routes.selected {
+		var removed []mb.NextHop
+		for selectedHop := range routes.hops[routes.selected] {
+			removed = append(removed, selectedHop)
+		}
+		return c.switchCluster(key, routes, removed, preferred)
+	}
+	if cluster != routes.selected {
+		c.log.V(1).Info("Holding back route of a less preferred cluster",
+			"VNI", vni, "Destination", dest, "NextHop", hop, "ClusterID", cluster, "SelectedClusterID", routes.selected)
+		return nil
+	}
+	return c.client.AddRoute(vni, dest, hop)
+}
+
+func (c *ClusterRouteClient) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	if hop.Type != pb.NextHopType_STANDARD {
+		return c.client.RemoveRoute(vni, dest, hop)
+	}
+	cluster := c.clusterOf(hop)
+
+	key := routeDestination{vni: vni, dest: dest}
+	lock := c.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.mu.Lock()
+	routes, ok := c.routes[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if _, ok := routes.hops[cluster][hop]; !ok {
+		return nil
+	}
+
+	delete(routes.hops[cluster], hop)
+	if len(routes.hops[cluster]) == 0 {
+		delete(routes.hops, cluster)
+		if len(routes.hops) == 1 {
+			clusterRouteConflicts.Dec()
+		}
+	}
+
+	switch {
+	case len(routes.hops) == 0:
+		c.mu.Lock()
+		delete(c.routes, key)
+		c.mu.Unlock()
+		return c.client.RemoveRoute(vni, dest, hop)
+	case cluster != routes.selected:
+		return nil
+	case len(routes.hops[cluster]) > 0:
+		return c.client.RemoveRoute(vni, dest, hop)
+	default:
+		return c.switchCluster(key, routes, []mb.NextHop{hop}, c.preferred(routes))
+	}
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// hopRecordingClient records the next hops of the programmed routes.
+type hopRecordingClient struct {
+	mu   sync.Mutex
+	hops map[mb.NextHop]struct{}
+}
+
+func (c *hopRecordingClient) AddRoute(_ mb.VNI, _ mb.Destination, hop mb.NextHop) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hops[hop] = struct{}{}
+	return nil
+}
+
+func (c *hopRecordingClient) RemoveRoute(_ mb.VNI, _ mb.Destination, hop mb.NextHop) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.hops, hop)
+	return nil
+}
+
+func (c *hopRecordingClient) Hops() []mb.NextHop {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var hops []mb.NextHop
+	for hop := range c.hops {
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// announcingRouteUtil records the next hops of the last announcement.
+type announcingRouteUtil struct {
+	metalbond.RouteUtil
+	nextHop metalbond.NextHop
+}
+
+func (u *announcingRouteUtil) AnnounceRoute(_ context.Context, _ metalbond.VNI, _ metalbond.Destination, nextHop metalbond.NextHop) error {
+	u.nextHop = nextHop
+	return nil
+}
+
+var _ = Describe("Cluster routes", func() {
+	clusterHop := func(addr string, cluster uint16) mb.NextHop {
+		return mb.NextHop{TargetAddress: netip.MustParseAddr(addr), Type: pb.NextHopType_STANDARD, NATPortRangeFrom: cluster}
+	}
+
+	It("should tag the announced standard hops with the cluster id", func(ctx SpecContext) {
+		routes := &announcingRouteUtil{}
+		u := metalbond.NewClusterRouteUtil(routes, 7)
+		dest := metalbond.Destination{Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+
+		Expect(u.AnnounceRoute(ctx, 100, dest, metalbond.NextHop{TargetHopType: pb.NextHopType_STANDARD})).To(Succeed())
+		Expect(routes.nextHop.TargetClusterID).To(Equal(uint16(7)))
+
+		Expect(u.AnnounceRoute(ctx, 100, dest, metalbond.NextHop{TargetHopType: pb.NextHopType_LOADBALANCER_TARGET})).To(Succeed())
+		Expect(routes.nextHop.TargetClusterID).To(BeZero())
+	})
+
+	It("should read the cluster id of standard hops only", func() {
+		Expect(metalbond.ClusterIDFromNextHop(clusterHop("fc00::1", 7))).To(Equal(uint16(7)))
+		Expect(metalbond.ClusterIDFromNextHop(mb.NextHop{Type: pb.NextHopType_NAT, NATPortRangeFrom: 1024})).To(BeZero())
+	})
+
+	Describe("ClusterRouteClient", func() {
+		var (
+			client *hopRecordingClient
+			c      *metalbond.ClusterRouteClient
+			dest   = mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+		)
+
+		BeforeEach(func() {
+			client = &hopRecordingClient{hops: make(map[mb.NextHop]struct{})}
+			log := logr.Discard()
+			c = metalbond.NewClusterRouteClient(&log, client, metalbond.ClusterRouteOptions{
+				ClusterID:      1,
+				PeerClusterIDs: []uint16{2, 3},
+			})
+		})
+
+		It("should prefer the routes of the local cluster and fall back to remote clusters", func() {
+			remote := clusterHop("fc00:3::1", 3)
+			local := clusterHop("fc00:1::1", 1)
+			untagged := clusterHop("fc00:1::2", 0)
+
+			Expect(c.AddRoute(100, dest, remote)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(remote))
+
+			Expect(c.AddRoute(100, dest, local)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(local))
+
+			Expect(c.AddRoute(100, dest, untagged)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(local, untagged))
+
+			Expect(c.RemoveRoute(100, dest, local)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(untagged))
+
+			Expect(c.RemoveRoute(100, dest, untagged)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(remote))
+
+			Expect(c.RemoveRoute(100, dest, remote)).To(Succeed())
+			Expect(client.Hops()).To(BeEmpty())
+		})
+
+		It("should prefer the remote cluster with the lowest id", func() {
+			first := clusterHop("fc00:2::1", 2)
+			second := clusterHop("fc00:3::1", 3)
+
+			Expect(c.AddRoute(100, dest, second)).To(Succeed())
+			Expect(c.AddRoute(100, dest, first)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(first))
+
+			Expect(c.RemoveRoute(100, dest, second)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(first))
+		})
+
+		It("should drop the routes of clusters that are not peer clusters", func() {
+			Expect(c.AddRoute(100, dest, clusterHop("fc00:4::1", 4))).To(Succeed())
+			Expect(client.Hops()).To(BeEmpty())
+			Expect(c.RemoveRoute(100, dest, clusterHop("fc00:4::1", 4))).To(Succeed())
+		})
+
+		It("should pass through hops without cluster id", func() {
+			lbTarget := mb.NextHop{TargetAddress: netip.MustParseAddr("fc00:4::1"), Type: pb.NextHopType_LOADBALANCER_TARGET, NATPortRangeFrom: 4}
+			Expect(c.AddRoute(100, dest, lbTarget)).To(Succeed())
+			Expect(client.Hops()).To(ConsistOf(lbTarget))
+		})
+	})
+})
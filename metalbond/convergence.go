@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/ironcore-dev/metalbond"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Stages of the convergence of the routes of an object.
+const (
+	// ConvergenceStageAnnounced is reached once a route of the object is accepted by metalbond.
+	ConvergenceStageAnnounced = "announced"
+	// ConvergenceStageReflected is reached once a route of the object is received back from the route reflector.
+	ConvergenceStageReflected = "reflected"
+	// ConvergenceStageProgrammed is reached once the object is programmed into the dataplane.
+	ConvergenceStageProgrammed = "programmed"
+)
+
+var routeConvergenceSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "metalnet_route_convergence_seconds",
+	Help:    "Time from the change of an object to its routes reaching a stage, by kind of object and stage.",
+	Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+}, []string{"kind", "stage"})
+
+func init() {
+	metrics.Registry.MustRegister(routeConvergenceSeconds)
+}
+
+// ObserveConvergence records that the routes of an object of the given kind changed at since reached the stage.
+func ObserveConvergence(kind, stage string, since time.Time) {
+	routeConvergenceSeconds.WithLabelValues(kind, stage).Observe(time.Since(since).Seconds())
+}
+
+type convergenceStartKey struct{}
+
+type convergenceStart struct {
+	kind  string
+	since time.Time
+}
+
+// WithConvergenceStart returns a context whose route announcements are measured as the convergence of a change at
+// since of an object of the given kind. A zero since does not measure.
+func WithConvergenceStart(ctx context.Context, kind string, since time.Time) context.Context {
+	if since.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, convergenceStartKey{}, convergenceStart{kind: kind, since: since})
+}
+
+func convergenceStartFrom(ctx context.Context) (convergenceStart, bool) {
+	start, ok := ctx.Value(convergenceStartKey{}).(convergenceStart)
+	return start, ok
+}
+
+type announcedRoute struct {
+	vni     VNI
+	prefix  netip.Prefix
+	nextHop metalbond.NextHop
+}
+
+// ConvergenceRouteUtil is a RouteUtil measuring the time from the change of an object to its routes being
+// announced to and reflected back by metalbond. Only announcements with a context from WithConvergenceStart
+// are measured.
+type ConvergenceRouteUtil struct {
+	RouteUtil
+
+	mu sync.Mutex
+	// pending are the measured announcements not yet reflected back.
+	pending map[announcedRoute]convergenceStart
+}
+
+func NewConvergenceRouteUtil(routeUtil RouteUtil) *ConvergenceRouteUtil {
+	return &ConvergenceRouteUtil{
+		RouteUtil: routeUtil,
+		pending:   make(map[announcedRoute]convergenceStart),
+	}
+}
+
+func (u *ConvergenceRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	if err := u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop); err != nil {
+		return err
+	}
+	start, ok := convergenceStartFrom(ctx)
+	if !ok {
+		return nil
+	}
+	ObserveConvergence(start.kind, ConvergenceStageAnnounced, start.since)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pending[announcedRoute{vni, destination.Prefix, toMetalbondNextHop(nextHop)}] = start
+	return nil
+}
+
+func (u *ConvergenceRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	u.mu.Lock()
+	delete(u.pending, announcedRoute{vni, destination.Prefix, toMetalbondNextHop(nextHop)})
+	u.mu.Unlock()
+	return u.RouteUtil.WithdrawRoute(ctx, vni, destination, nextHop)
+}
+
+// RouteReceived is informed about every route received from metalbond. Receiving a measured announcement
+// back from the route reflector completes its convergence.
+func (u *ConvergenceRouteUtil) RouteReceived(vni VNI, destination metalbond.Destination, nextHop metalbond.NextHop) {
+	if u == nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	key := announcedRoute{vni, destination.Prefix, nextHop}
+	start, ok := u.pending[key]
+	if !ok {
+		return
+	}
+	delete(u.pending, key)
+	ObserveConvergence(start.kind, ConvergenceStageReflected, start.since)
+}
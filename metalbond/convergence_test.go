@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	mb "github.com/ironcore-dev/metalbond"
+	mbproto "github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// convergenceSamples returns the number of convergences of the given kind observed at the given stage.
+func convergenceSamples(kind, stage string) uint64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "metalnet_route_convergence_seconds" {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if (label.GetName() == "kind" && label.GetValue() != kind) || (label.GetName() == "stage" && label.GetValue() != stage) {
+					continue metrics
+				}
+			}
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+var _ = Describe("ConvergenceRouteUtil", func() {
+	var (
+		ctx = context.TODO()
+		u   *metalbond.ConvergenceRouteUtil
+
+		destination = metalbond.Destination{Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+		nextHop     = metalbond.NextHop{
+			TargetAddress: netip.MustParseAddr("2001:db8::1"),
+			TargetVNI:     100,
+			TargetHopType: mbproto.NextHopType_STANDARD,
+		}
+		received = func() {
+			u.RouteReceived(100, mb.Destination{IPVersion: mb.IPV4, Prefix: destination.Prefix}, mb.NextHop{
+				TargetAddress: nextHop.TargetAddress,
+				TargetVNI:     100,
+				Type:          mbproto.NextHopType_STANDARD,
+			})
+		}
+	)
+	BeforeEach(func() {
+		u = metalbond.NewConvergenceRouteUtil(&fakeRouteUtil{})
+	})
+
+	It("should measure announcements until they are reflected back", func() {
+		kind := "Reflected"
+		Expect(u.AnnounceRoute(ctx, 100, destination, nextHop)).To(Succeed())
+		Expect(convergenceSamples(kind, metalbond.ConvergenceStageAnnounced)).To(BeZero())
+
+		Expect(u.AnnounceRoute(metalbond.WithConvergenceStart(ctx, kind, time.Now()), 100, destination, nextHop)).To(Succeed())
+		Expect(convergenceSamples(kind, metalbond.ConvergenceStageAnnounced)).To(Equal(uint64(1)))
+		Expect(convergenceSamples(kind, metalbond.ConvergenceStageReflected)).To(BeZero())
+
+		received()
+		Expect(convergenceSamples(kind, metalbond.ConvergenceStageReflected)).To(Equal(uint64(1)))
+		By("not measuring the route received again")
+		received()
+		Expect(convergenceSamples(kind, metalbond.ConvergenceStageReflected)).To(Equal(uint64(1)))
+	})
+
+	It("should not measure withdrawn announcements", func() {
+		kind := "Withdrawn"
+		Expect(u.AnnounceRoute(metalbond.WithConvergenceStart(ctx, kind, time.Now()), 100, destination, nextHop)).To(Succeed())
+		Expect(u.WithdrawRoute(ctx, 100, destination, nextHop)).To(Succeed())
+
+		received()
+		Expect(convergenceSamples(kind, metalbond.ConvergenceStageReflected)).To(BeZero())
+	})
+})
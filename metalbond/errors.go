@@ -3,7 +3,12 @@
 
 package metalbond
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
 
 func IsAlreadySubscribedToVNIError(err error) bool {
 	if err == nil {
@@ -91,3 +96,33 @@ func IgnoreNextHopNotFoundError(err error) error {
 	}
 	return err
 }
+
+// RouteDeniedError is returned when the announcement policy does not allow announcing a route.
+type RouteDeniedError struct {
+	VNI    VNI
+	Prefix netip.Prefix
+}
+
+func (e *RouteDeniedError) Error() string {
+	return fmt.Sprintf("announcement of %s in VNI %d denied by announcement policy", e.Prefix, e.VNI)
+}
+
+func IsRouteDeniedError(err error) bool {
+	var deniedErr *RouteDeniedError
+	return errors.As(err, &deniedErr)
+}
+
+// VNIOutOfRangeError is returned when subscribing to a VNI outside of the VNI range of the deployment.
+type VNIOutOfRangeError struct {
+	VNI   VNI
+	Range VNIRange
+}
+
+func (e *VNIOutOfRangeError) Error() string {
+	return fmt.Sprintf("VNI %d is outside of the VNI range %s", e.VNI, e.Range)
+}
+
+func IsVNIOutOfRangeError(err error) bool {
+	var rangeErr *VNIOutOfRangeError
+	return errors.As(err, &rangeErr)
+}
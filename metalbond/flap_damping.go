@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	routeUpdatesSuppressed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metalnet_metalbond_route_updates_suppressed_total",
+		Help: "Number of metalbond route additions not programmed because the route is flapping.",
+	})
+	routesSuppressed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "metalnet_metalbond_routes_suppressed",
+		Help: "Number of metalbond routes currently suppressed because they are flapping.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(routeUpdatesSuppressed, routesSuppressed)
+}
+
+type FlapDampingOptions struct {
+	// Threshold is the number of updates of a route within the window after which the route is suppressed.
+	// Defaults to 5.
+	Threshold int
+	// Window is the period the updates of a route are counted in. Defaults to 1 minute.
+	Window time.Duration
+	// Penalty is the period a flapping route is suppressed for. Defaults to 5 minutes.
+	Penalty time.Duration
+}
+
+// FlapDampingClient is a metalbond client that protects dpservice from route churn.
+//
+// Updates of a route (vni, destination, next hop) are passed to the wrapped client until the route is
+// updated more than the threshold within the window. The route is then suppressed for the penalty period:
+// its withdrawals are still programmed right away, but adding it again is recorded and not programmed. Once
+// the penalty expires, the latest state of the route is programmed if it differs from the programmed one,
+// retrying until it is programmed. Updates during the penalty extend it.
+type FlapDampingClient struct {
+	client    mb.Client
+	threshold int
+	window    time.Duration
+	penalty   time.Duration
+	log       *logr.Logger
+
+	mu     sync.Mutex
+	routes map[routeKey]*dampedRoute
+}
+
+type dampedRoute struct {
+	mu      sync.Mutex
+	deleted bool
+
+	// updates are the times of the updates within the window.
+	updates []time.Time
+	// desired is the latest received operation, applied is the latest programmed one.
+	desired, applied routeOperation
+	suppressedUntil  time.Time
+	timer            *time.Timer
+}
+
+func NewFlapDampingClient(log *logr.Logger, client mb.Client, opts FlapDampingOptions) *FlapDampingClient {
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	window := opts.Window
+	if window <= 0 {
+		window = time.Minute
+	}
+	penalty := opts.Penalty
+	if penalty <= 0 {
+		penalty = 5 * time.Minute
+	}
+	return &FlapDampingClient{
+		client:    client,
+		threshold: threshold,
+		window:    window,
+		penalty:   penalty,
+		log:       log,
+		routes:    make(map[routeKey]*dampedRoute),
+	}
+}
+
+func (c *FlapDampingClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	return c.update(routeKey{vni, dest, hop}, addRouteOperation)
+}
+
+func (c *FlapDampingClient) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	return c.update(routeKey{vni, dest, hop}, removeRouteOperation)
+}
+
+// lockRoute returns the locked state of the given route, creating it if it does not exist.
+func (c *FlapDampingClient) lockRoute(key routeKey) *dampedRoute {
+	for {
+		c.mu.Lock()
+		route, ok := c.routes[key]
+		if !ok {
+			route = &dampedRoute{}
+			c.routes[key] = route
+		}
+		c.mu.Unlock()
+
+		route.mu.Lock()
+		if !route.deleted {
+			return route
+		}
+		route.mu.Unlock()
+	}
+}
+
+func (c *FlapDampingClient) update(key routeKey, op routeOperation) error {
+	route := c.lockRoute(key)
+	defer route.mu.Unlock()
+
+	now := time.Now()
+	route.desired = op
+	route.updates = append(pruneUpdates(route.updates, now.Add(-c.window)), now)
+	defer c.scheduleExpiry(key, route, now)
+
+	suppressed := now.Before(route.suppressedUntil)
+	if suppressed {
+		route.suppressedUntil = now.Add(c.penalty)
+	} else if len(route.updates) > c.threshold {
+		c.log.Info("Suppressing flapping metalbond route", "VNI", key.vni, "Destination", key.dest, "NextHop", key.nextHop,
+			"Updates", len(route.updates), "Penalty", c.penalty)
+		routesSuppressed.Inc()
+		route.suppressedUntil = now.Add(c.penalty)
+		suppressed = true
+	}
+
+	// A withdrawn route must not stay programmed, so only adding a flapping route again is dampened.
+	if suppressed && op == addRouteOperation {
+		routeUpdatesSuppressed.Inc()
+		return nil
+	}
+	return c.apply(key, route)
+}
+
+// apply programs the desired state of the route. The route has to be locked.
+func (c *FlapDampingClient) apply(key routeKey, route *dampedRoute) error {
+	if route.desired == route.applied {
+		return nil
+	}
+
+	var err error
+	switch route.desired {
+	case addRouteOperation:
+		err = c.client.AddRoute(key.vni, key.dest, key.nextHop)
+	case removeRouteOperation:
+		err = c.client.RemoveRoute(key.vni, key.dest, key.nextHop)
+	}
+	if err != nil {
+		return err
+	}
+	route.applied = route.desired
+	return nil
+}
+
+// scheduleExpiry arms the timer of the route to fire once its penalty expires or, if it is not suppressed,
+// once its updates left the window. A route without updates in the window that failed to be programmed is
+// retried after the window. The route has to be locked.
+func (c *FlapDampingClient) scheduleExpiry(key routeKey, route *dampedRoute, now time.Time) {
+	expiry := route.suppressedUntil
+	if expiry.IsZero() {
+		expiry = now.Add(c.window)
+		if len(route.updates) > 0 {
+			expiry = route.updates[len(route.updates)-1].Add(c.window)
+		}
+	}
+
+	if route.timer != nil {
+		route.timer.Stop()
+	}
+	route.timer = time.AfterFunc(expiry.Sub(now), func() {
+		c.expire(key, route)
+	})
+}
+
+// expire programs the latest state of a route whose penalty expired and forgets routes without recent updates
+// whose latest state is programmed.
+func (c *FlapDampingClient) expire(key routeKey, route *dampedRoute) {
+	route.mu.Lock()
+	defer route.mu.Unlock()
+
+	now := time.Now()
+	if route.deleted || now.Before(route.suppressedUntil) {
+		return
+	}
+
+	if !route.suppressedUntil.IsZero() {
+		c.log.Info("Releasing suppressed metalbond route", "VNI", key.vni, "Destination", key.dest, "NextHop", key.nextHop)
+		routesSuppressed.Dec()
+		route.suppressedUntil = time.Time{}
+	}
+	if err := c.apply(key, route); err != nil {
+		c.log.Error(err, "Error processing metalbond route", "VNI", key.vni, "Destination", key.dest, "NextHop", key.nextHop)
+	}
+
+	route.updates = pruneUpdates(route.updates, now.Add(-c.window))
+	if len(route.updates) > 0 || route.desired != route.applied {
+		c.scheduleExpiry(key, route, now)
+		return
+	}
+
+	c.mu.Lock()
+	route.deleted = true
+	delete(c.routes, key)
+	c.mu.Unlock()
+}
+
+func pruneUpdates(updates []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(updates) && !updates[i].After(since) {
+		i++
+	}
+	return updates[i:]
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlapDampingClient", func() {
+	var (
+		client *recordingClient
+		c      *metalbond.FlapDampingClient
+		dest   = mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+		hop    = mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_STANDARD}
+	)
+
+	BeforeEach(func() {
+		client = &recordingClient{}
+		log := logr.Discard()
+		c = metalbond.NewFlapDampingClient(&log, client, metalbond.FlapDampingOptions{
+			Threshold: 2,
+			Window:    time.Minute,
+			Penalty:   200 * time.Millisecond,
+		})
+	})
+
+	It("should suppress a flapping route and program its latest state once the penalty expired", func() {
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, hop)).To(Succeed())
+		Expect(client.Calls()).To(Equal([]string{"add 100 10.0.0.1/32", "remove 100 10.0.0.1/32"}))
+
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, hop)).To(Succeed())
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Consistently(client.Calls, 100*time.Millisecond).Should(HaveLen(2))
+
+		Eventually(client.Calls).Should(Equal([]string{
+			"add 100 10.0.0.1/32",
+			"remove 100 10.0.0.1/32",
+			"add 100 10.0.0.1/32",
+		}))
+	})
+
+	It("should not program a suppressed route whose latest state is already programmed", func() {
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, hop)).To(Succeed())
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, hop)).To(Succeed())
+
+		Consistently(client.Calls, 400*time.Millisecond).Should(Equal([]string{
+			"add 100 10.0.0.1/32",
+			"remove 100 10.0.0.1/32",
+		}))
+	})
+	It("should withdraw a suppressed route right away", func() {
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, hop)).To(Succeed())
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(client.Calls()).To(Equal([]string{"add 100 10.0.0.1/32", "remove 100 10.0.0.1/32"}))
+
+		Eventually(client.Calls).Should(Equal([]string{
+			"add 100 10.0.0.1/32",
+			"remove 100 10.0.0.1/32",
+			"add 100 10.0.0.1/32",
+		}))
+	})
+
+	It("should retry programming the latest state of a route once the penalty expired", func() {
+		log := logr.Discard()
+		c = metalbond.NewFlapDampingClient(&log, client, metalbond.FlapDampingOptions{
+			Threshold: 2,
+			Window:    300 * time.Millisecond,
+			Penalty:   100 * time.Millisecond,
+		})
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, hop)).To(Succeed())
+		client.mu.Lock()
+		client.failures = 1
+		client.mu.Unlock()
+		Expect(c.AddRoute(100, dest, hop)).To(Succeed())
+
+		Eventually(client.Calls).Should(Equal([]string{
+			"add 100 10.0.0.1/32",
+			"remove 100 10.0.0.1/32",
+			"add 100 10.0.0.1/32",
+			"add 100 10.0.0.1/32",
+		}))
+		Consistently(client.Calls, 400*time.Millisecond).Should(HaveLen(4))
+	})
+})
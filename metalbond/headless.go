@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	headlessGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "metalnet_metalbond_headless",
+		Help: "Whether no metalbond peer session is established, so route updates are queued until one is.",
+	})
+	queuedRouteUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metalnet_metalbond_queued_route_updates_total",
+		Help: "Number of route announcements, withdrawals and subscription changes queued while no metalbond peer session was established.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(headlessGauge, queuedRouteUpdates)
+}
+
+// HeadlessRouteUtil is a RouteUtil letting metalnet keep programming dpservice while no metalbond peer session
+// is established. Metalbond records announcements and subscriptions before sending them to its peers and
+// replays them to every newly established session, so failing to send them while no session is established
+// only queues them.
+type HeadlessRouteUtil struct {
+	RouteUtil
+	connected    func() bool
+	pollInterval time.Duration
+	log          *logr.Logger
+
+	headless atomic.Bool
+}
+
+// NewHeadlessRouteUtil creates a HeadlessRouteUtil. connected reports whether any metalbond peer session is
+// established, see PeerManager.Connected.
+func NewHeadlessRouteUtil(log *logr.Logger, routeUtil RouteUtil, connected func() bool) *HeadlessRouteUtil {
+	return &HeadlessRouteUtil{
+		RouteUtil:    routeUtil,
+		connected:    connected,
+		pollInterval: time.Second,
+		log:          log,
+	}
+}
+
+func (u *HeadlessRouteUtil) queue(op string, err error) error {
+	if err == nil || u.connected() {
+		return err
+	}
+	queuedRouteUpdates.Inc()
+	u.log.V(1).Info("No metalbond peer session is established, queueing", "Operation", op, "Error", err.Error())
+	return nil
+}
+
+func (u *HeadlessRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	return u.queue("AnnounceRoute", u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop))
+}
+
+func (u *HeadlessRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	return u.queue("WithdrawRoute", u.RouteUtil.WithdrawRoute(ctx, vni, destination, nextHop))
+}
+
+func (u *HeadlessRouteUtil) Subscribe(ctx context.Context, vni VNI) error {
+	return u.queue("Subscribe", u.RouteUtil.Subscribe(ctx, vni))
+}
+
+func (u *HeadlessRouteUtil) Unsubscribe(ctx context.Context, vni VNI) error {
+	return u.queue("Unsubscribe", u.RouteUtil.Unsubscribe(ctx, vni))
+}
+
+// Start tracks whether metalnet runs headless until the context is done.
+func (u *HeadlessRouteUtil) Start(ctx context.Context) error {
+	ticker := time.NewTicker(u.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		u.update()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *HeadlessRouteUtil) update() {
+	headless := !u.connected()
+	if u.headless.Swap(headless) != headless {
+		if headless {
+			u.log.Info("No metalbond peer session is established, continuing headless")
+		} else {
+			u.log.Info("Metalbond peer session is established, sending queued route updates")
+		}
+	}
+	if headless {
+		headlessGauge.Set(1)
+	} else {
+		headlessGauge.Set(0)
+	}
+}
+
+// Checker fails while metalnet runs headless.
+func (u *HeadlessRouteUtil) Checker(_ *http.Request) error {
+	if u.headless.Load() {
+		return errors.New("no metalbond peer session is established")
+	}
+	return nil
+}
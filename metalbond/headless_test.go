@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// notEstablishedRouteUtil fails all route updates like metalbond does without established peer sessions.
+type notEstablishedRouteUtil struct {
+	metalbond.RouteUtil
+}
+
+var errNotEstablished = errors.New("Connection not ESTABLISHED")
+
+func (notEstablishedRouteUtil) AnnounceRoute(context.Context, metalbond.VNI, metalbond.Destination, metalbond.NextHop) error {
+	return errNotEstablished
+}
+
+func (notEstablishedRouteUtil) Subscribe(context.Context, metalbond.VNI) error {
+	return errNotEstablished
+}
+
+var _ = Describe("HeadlessRouteUtil", func() {
+	var (
+		connected atomic.Bool
+		u         *metalbond.HeadlessRouteUtil
+
+		destination = metalbond.Destination{Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+	)
+	BeforeEach(func() {
+		connected.Store(false)
+		log := logr.Discard()
+		u = metalbond.NewHeadlessRouteUtil(&log, notEstablishedRouteUtil{}, connected.Load)
+	})
+
+	It("should queue route updates while no peer session is established", func(ctx SpecContext) {
+		Expect(u.AnnounceRoute(ctx, 100, destination, metalbond.NextHop{})).To(Succeed())
+		Expect(u.Subscribe(ctx, 100)).To(Succeed())
+
+		connected.Store(true)
+		Expect(u.AnnounceRoute(ctx, 100, destination, metalbond.NextHop{})).To(MatchError(errNotEstablished))
+	})
+
+	It("should fail the readiness check while headless", func(ctx SpecContext) {
+		go func() {
+			defer GinkgoRecover()
+			Expect(u.Start(ctx)).To(Succeed())
+		}()
+		Eventually(func() error { return u.Checker(nil) }).Should(HaveOccurred())
+
+		connected.Store(true)
+		// The state is polled every second.
+		Eventually(func() error { return u.Checker(nil) }).Within(3 * time.Second).Should(Succeed())
+	})
+})
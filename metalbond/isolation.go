@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var isolationViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "metalnet_vni_isolation_violations_total",
+	Help: "Number of routes into another VNI refused or removed because the VNIs are not peered.",
+}, []string{"action"})
+
+func init() {
+	metrics.Registry.MustRegister(isolationViolations)
+}
+
+// isPeered reports whether the route to prefix with a next hop in nextHopVNI may be installed into localVNI.
+// This is the case if the network of nextHopVNI peers with localVNI, see AddRoute, or if a ServiceChain
+// steers the prefix of localVNI into nextHopVNI.
+func (c *MetalnetClient) isPeered(localVNI, nextHopVNI uint32, prefix netip.Prefix) bool {
+	if localVNI == nextHopVNI {
+		return true
+	}
+	peerVNIs, _ := c.metalnetCache.GetPeerVnis(nextHopVNI)
+	return peerVNIs.Has(localVNI) || c.metalnetCache.IsServiceChainRoute(localVNI, prefix, nextHopVNI)
+}
+
+// RemoveIsolationViolations deletes the routes of the given VNI whose next hop lies in another VNI that is
+// not peered with it and returns the number of deleted routes.
+//
+// Unlike CleanupNotPeeredRoutes, which is triggered by peering changes, it does not rely on the VNI having
+// been peered before, so it also removes routes left behind by a stale cache.
+func (c *MetalnetClient) RemoveIsolationViolations(ctx context.Context, vni uint32) (int, error) {
+	routes, err := c.dpdk.ListRoutes(ctx, vni, dpdkerrors.Ignore(dpdkerrors.NO_VNI))
+	if err != nil {
+		return 0, fmt.Errorf("error listing dpdk routes for vni %d: %w", vni, err)
+	}
+
+	var removed int
+	for _, route := range routes.Items {
+		if route.Spec.NextHop == nil || c.isPeered(vni, route.Spec.NextHop.VNI, *route.Spec.Prefix) {
+			continue
+		}
+
+		c.log.Info("Removing route into not peered vni", "VNI", vni, "Prefix", route.Spec.Prefix, "NextHopVNI", route.Spec.NextHop.VNI)
+		if _, err := c.dpdk.DeleteRoute(
+			ctx,
+			vni,
+			route.Spec.Prefix,
+			dpdkerrors.Ignore(dpdkerrors.NO_VNI, dpdkerrors.ROUTE_NOT_FOUND),
+		); err != nil {
+			return removed, fmt.Errorf("error deleting route: %w", err)
+		}
+		isolationViolations.WithLabelValues("removed").Inc()
+		removed++
+	}
+	return removed, nil
+}
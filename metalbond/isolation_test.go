@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VNI isolation", func() {
+	var (
+		dpdkClient dpdkclient.Client
+		cache      *internal.MetalnetCache
+		client     *metalbond.MetalnetClient
+		hop        = mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_STANDARD}
+	)
+
+	newRoute := func(vni, nextHopVNI uint32, prefix string) dpdk.Route {
+		p := netip.MustParsePrefix(prefix)
+		return dpdk.Route{
+			RouteMeta: dpdk.RouteMeta{VNI: vni},
+			Spec:      dpdk.RouteSpec{Prefix: &p, NextHop: &dpdk.RouteNextHop{VNI: nextHopVNI, IP: &hop.TargetAddress}},
+		}
+	}
+
+	BeforeEach(func(ctx SpecContext) {
+		dpdkClient = newDPDKClient(ctx, 100, 200, 300)
+		log := logr.Discard()
+		cache = internal.NewMetalnetCache(&log)
+		client = metalbond.NewMetalnetClient(&log, dpdkClient, cache, &metalbond.DefaultRouterAddress{}, metalbond.ClientOptions{})
+	})
+
+	It("should only install routes into peered vnis", func(ctx SpecContext) {
+		Expect(cache.AddVniToPeerVnis(100, 200)).To(Succeed())
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+
+		Expect(client.AddRoute(100, dest, hop)).To(Succeed())
+		Expect(listRoutes(ctx, dpdkClient, 100)).To(Equal(map[string]uint32{"10.0.0.1/32": 100}))
+		Expect(listRoutes(ctx, dpdkClient, 200)).To(Equal(map[string]uint32{"10.0.0.1/32": 100}))
+
+		Expect(client.AddRoute(300, dest, hop)).To(Succeed())
+		Expect(listRoutes(ctx, dpdkClient, 300)).To(Equal(map[string]uint32{"10.0.0.1/32": 300}))
+		Expect(listRoutes(ctx, dpdkClient, 100)).To(HaveLen(1))
+		Expect(listRoutes(ctx, dpdkClient, 200)).To(HaveLen(1))
+	})
+
+	It("should remove routes into not peered vnis", func(ctx SpecContext) {
+		Expect(cache.AddVniToPeerVnis(100, 200)).To(Succeed())
+		for _, route := range []dpdk.Route{
+			newRoute(200, 200, "10.0.0.1/32"),
+			newRoute(200, 100, "10.0.0.2/32"),
+			newRoute(200, 300, "10.0.0.3/32"),
+		} {
+			_, err := dpdkClient.CreateRoute(ctx, &route)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(client.RemoveIsolationViolations(ctx, 200)).To(Equal(1))
+		Expect(listRoutes(ctx, dpdkClient, 200)).To(Equal(map[string]uint32{"10.0.0.1/32": 200, "10.0.0.2/32": 100}))
+	})
+})
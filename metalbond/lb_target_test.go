@@ -0,0 +1,32 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadBalancer targets", func() {
+	It("should remove stale LoadBalancer servers whose dpservice loadbalancer is gone", func(ctx SpecContext) {
+		log := logr.Discard()
+		cache := internal.NewMetalnetCache(&log)
+		client := metalbond.NewMetalnetClient(&log, newDPDKClient(ctx), cache, &metalbond.DefaultRouterAddress{}, metalbond.ClientOptions{})
+		Expect(cache.AddLoadBalancerServer(100, "10.0.0.100", "lb-uid")).To(Succeed())
+
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.100/32")}
+		hop := mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_LOADBALANCER_TARGET}
+		Expect(client.AddRoute(100, dest, hop)).NotTo(Succeed())
+
+		_, ok := cache.GetLoadBalancerServer(100, "10.0.0.100")
+		Expect(ok).To(BeFalse())
+	})
+})
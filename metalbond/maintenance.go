@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type announcement struct {
+	vni         VNI
+	destination Destination
+	nextHop     NextHop
+}
+
+// MaintenanceRouteUtil is a RouteUtil that keeps track of all announced routes so they can be
+// withdrawn while the node is in maintenance and announced again afterwards.
+//
+// While in maintenance, announcements and withdrawals are only recorded. Subscriptions are not
+// affected, so the node keeps learning the routes of the other nodes.
+type MaintenanceRouteUtil struct {
+	RouteUtil
+
+	mu            sync.Mutex
+	inMaintenance bool
+	announced     map[announcement]struct{}
+}
+
+func NewMaintenanceRouteUtil(routeUtil RouteUtil) *MaintenanceRouteUtil {
+	return &MaintenanceRouteUtil{
+		RouteUtil: routeUtil,
+		announced: make(map[announcement]struct{}),
+	}
+}
+
+// InMaintenance reports whether the announcements are currently withdrawn.
+func (u *MaintenanceRouteUtil) InMaintenance() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.inMaintenance
+}
+
+// SetInMaintenance withdraws all announced routes when entering maintenance and announces them
+// again when leaving it. Failed routes are retried with the next call.
+func (u *MaintenanceRouteUtil) SetInMaintenance(ctx context.Context, inMaintenance bool) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var errs []error
+	for a := range u.announced {
+		var err error
+		if inMaintenance {
+			err = IgnoreNextHopNotFoundError(u.RouteUtil.WithdrawRoute(ctx, a.vni, a.destination, a.nextHop))
+		} else {
+			err = IgnoreNextHopAlreadyExistsError(u.RouteUtil.AnnounceRoute(ctx, a.vni, a.destination, a.nextHop))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error updating route %s in VNI %d: %w", a.destination.Prefix, a.vni, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	u.inMaintenance = inMaintenance
+	return nil
+}
+
+func (u *MaintenanceRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	a := announcement{vni, destination, nextHop}
+	if u.inMaintenance {
+		u.announced[a] = struct{}{}
+		return nil
+	}
+	if err := u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop); IgnoreNextHopAlreadyExistsError(err) != nil {
+		return err
+	}
+	u.announced[a] = struct{}{}
+	return nil
+}
+
+func (u *MaintenanceRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	a := announcement{vni, destination, nextHop}
+	if u.inMaintenance {
+		delete(u.announced, a)
+		return nil
+	}
+	if err := u.RouteUtil.WithdrawRoute(ctx, vni, destination, nextHop); IgnoreNextHopNotFoundError(err) != nil {
+		return err
+	}
+	delete(u.announced, a)
+	return nil
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaintenanceRouteUtil", func() {
+	var (
+		ctx       = context.TODO()
+		routeUtil *fakeRouteUtil
+		u         *metalbond.MaintenanceRouteUtil
+
+		prefixA = netip.MustParsePrefix("10.0.0.1/32")
+		prefixB = netip.MustParsePrefix("10.0.0.2/32")
+		prefixC = netip.MustParsePrefix("10.0.0.3/32")
+	)
+	BeforeEach(func() {
+		routeUtil = &fakeRouteUtil{}
+		u = metalbond.NewMaintenanceRouteUtil(routeUtil)
+	})
+
+	It("should withdraw all announcements in maintenance and restore them afterwards", func() {
+		Expect(u.AnnounceRoute(ctx, 1, metalbond.Destination{Prefix: prefixA}, metalbond.NextHop{})).To(Succeed())
+		Expect(u.AnnounceRoute(ctx, 1, metalbond.Destination{Prefix: prefixB}, metalbond.NextHop{})).To(Succeed())
+
+		By("entering maintenance")
+		Expect(u.SetInMaintenance(ctx, true)).To(Succeed())
+		Expect(u.InMaintenance()).To(BeTrue())
+		Expect(routeUtil.withdrawn).To(ConsistOf(prefixA, prefixB))
+
+		By("changing the announcements while in maintenance")
+		Expect(u.WithdrawRoute(ctx, 1, metalbond.Destination{Prefix: prefixB}, metalbond.NextHop{})).To(Succeed())
+		Expect(u.AnnounceRoute(ctx, 1, metalbond.Destination{Prefix: prefixC}, metalbond.NextHop{})).To(Succeed())
+		Expect(routeUtil.announced).To(ConsistOf(prefixA, prefixB))
+		Expect(routeUtil.withdrawn).To(ConsistOf(prefixA, prefixB))
+
+		By("leaving maintenance")
+		routeUtil.announced = nil
+		Expect(u.SetInMaintenance(ctx, false)).To(Succeed())
+		Expect(u.InMaintenance()).To(BeFalse())
+		Expect(routeUtil.announced).To(ConsistOf(prefixA, prefixC))
+	})
+})
@@ -7,7 +7,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"net/netip"
 	"strings"
 
@@ -21,8 +20,10 @@ import (
 )
 
 type ClientOptions struct {
-	IPv4Only         bool
-	PreferredNetwork *net.IPNet
+	IPv4Only bool
+	// PreferredNetwork is the underlay range the load balancer targets are accepted from. If unset, all
+	// targets are accepted.
+	PreferredNetwork netip.Prefix
 }
 
 type MetalnetClient struct {
@@ -30,6 +31,7 @@ type MetalnetClient struct {
 	config               ClientOptions
 	metalnetCache        *internal.MetalnetCache
 	mbInstance           *mb.MetalBond
+	convergence          *ConvergenceRouteUtil
 	DefaultRouterAddress *DefaultRouterAddress
 
 	log *logr.Logger
@@ -49,6 +51,11 @@ func (c *MetalnetClient) SetMetalBond(mb *mb.MetalBond) {
 	c.mbInstance = mb
 }
 
+// SetConvergenceRouteUtil sets the ConvergenceRouteUtil informed about the received routes.
+func (c *MetalnetClient) SetConvergenceRouteUtil(u *ConvergenceRouteUtil) {
+	c.convergence = u
+}
+
 func (c *MetalnetClient) addLocalRoute(destVni mb.VNI, vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
 	ctx := context.TODO()
 
@@ -64,12 +71,9 @@ func (c *MetalnetClient) addLocalRoute(destVni mb.VNI, vni mb.VNI, dest mb.Desti
 			return fmt.Errorf("no registered LoadBalancer on this client for vni %d and ip %s", vni, ip)
 		}
 
-		if c.config.PreferredNetwork != nil {
-			targetAddress := net.ParseIP(hop.TargetAddress.String())
-			if !c.config.PreferredNetwork.Contains(targetAddress) {
-				c.log.V(1).Info(fmt.Sprintf("LB target %s is not in preferred network %s, ignoring...", targetAddress, c.config.PreferredNetwork))
-				return nil
-			}
+		if c.config.PreferredNetwork.IsValid() && !c.config.PreferredNetwork.Contains(hop.TargetAddress) {
+			c.log.V(1).Info(fmt.Sprintf("LB target %s is not in preferred network %s, ignoring...", hop.TargetAddress, c.config.PreferredNetwork))
+			return nil
 		}
 
 		if _, err := c.dpdk.CreateLoadBalancerTarget(ctx, &dpdk.LoadBalancerTarget{
@@ -81,6 +85,12 @@ func (c *MetalnetClient) addLocalRoute(destVni mb.VNI, vni mb.VNI, dest mb.Desti
 			},
 		}, dpdkerrors.Ignore(dpdkerrors.ALREADY_EXISTS),
 		); err != nil {
+			if dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_LB) {
+				// The LoadBalancer is registered after its dpservice loadbalancer is created, so the
+				// registration is stale and would apply the routes of further targets to the dead loadbalancer.
+				c.log.Info("Removing stale LoadBalancer server without dpservice loadbalancer", "VNI", vni, "IP", ip, "UID", uid)
+				c.metalnetCache.RemoveLoadBalancer(uid)
+			}
 			return fmt.Errorf("error creating lb target: %w", err)
 		}
 		return nil
@@ -105,6 +115,13 @@ func (c *MetalnetClient) addLocalRoute(destVni mb.VNI, vni mb.VNI, dest mb.Desti
 		return nil
 	}
 
+	// The peerings are checked again right before installing the route, as they may have changed
+	// since the route was received.
+	if !c.isPeered(uint32(vni), uint32(destVni), dest.Prefix) {
+		isolationViolations.WithLabelValues("refused").Inc()
+		return fmt.Errorf("refusing to install route %s of vni %d into not peered vni %d", dest.Prefix, destVni, vni)
+	}
+
 	if _, err := c.dpdk.CreateRoute(ctx, &dpdk.Route{
 		RouteMeta: dpdk.RouteMeta{
 			VNI: uint32(vni),
@@ -180,6 +197,7 @@ func (c *MetalnetClient) removeLocalRoute(destVni mb.VNI, vni mb.VNI, dest mb.De
 
 func (c *MetalnetClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
 	c.log.V(1).Info("AddRoute", "VNI", vni, "dest", dest, "hop", hop)
+	c.convergence.RouteReceived(vni, dest, hop)
 	var errStrs []string
 
 	isDefaultRoute, err := c.FilterDefaultRoute(AddDefaultRoute, vni, dest, hop)
@@ -196,31 +214,14 @@ func (c *MetalnetClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHo
 	if hop.Type == mbproto.NextHopType_STANDARD {
 		// the ok flag is ignored because an empty set is returned if the VNI doesn't exist, and the loop below is skipped
 		mbPeerVnis, _ := c.metalnetCache.GetPeerVnis(uint32(vni))
-		peeredPrefixes, ok := c.metalnetCache.GetPeeredPrefixes(uint32(vni))
-		c.log.V(1).Info("GetPeerVnis", "VNI", vni, "mbPeerVnis", mbPeerVnis, "peeredPrefixes", peeredPrefixes)
+		c.log.V(1).Info("GetPeerVnis", "VNI", vni, "mbPeerVnis", mbPeerVnis)
 
 		for _, peeredVNI := range mbPeerVnis.UnsortedList() {
-			// by default, we add the route if no peered prefixes are set
-			addRoute := true
-			if ok {
-				allowedPeeredPrefixes, exists := peeredPrefixes[peeredVNI]
-				// if we have set peered prefixes for this VNI, we need to check if the destination is in the list
-				if exists {
-					// if the destination is not in the list of peered prefixes, we don't add the route
-					addRoute = false
-					for _, peeredPrefix := range allowedPeeredPrefixes {
-						if peeredPrefix.Contains(dest.Prefix.Addr()) {
-							addRoute = true
-							break
-						}
-					}
-				}
+			if !c.isExchanged(uint32(vni), peeredVNI, dest.Prefix) {
+				continue
 			}
-
-			if addRoute {
-				if err := c.addLocalRoute(vni, mb.VNI(peeredVNI), dest, hop); err != nil {
-					errStrs = append(errStrs, err.Error())
-				}
+			if err := c.addLocalRoute(vni, mb.VNI(peeredVNI), dest, hop); err != nil {
+				errStrs = append(errStrs, err.Error())
 			}
 		}
 	}
@@ -276,8 +277,9 @@ func (c *MetalnetClient) CleanupNotPeeredRoutes(vni uint32) error {
 
 	// loop over all routes and delete the ones that are not peered
 	for _, route := range routes.Items {
-		// only delete route if it is not the local vni and not peered
-		if route.Spec.NextHop.VNI != vni && (ok && !set.Has(route.Spec.NextHop.VNI)) {
+		// only delete route if it is not the local vni and not peered, nor steered by a service chain
+		if route.Spec.NextHop.VNI != vni && (ok && !set.Has(route.Spec.NextHop.VNI)) &&
+			!c.metalnetCache.IsServiceChainRoute(vni, *route.Spec.Prefix, route.Spec.NextHop.VNI) {
 			if _, err := c.dpdk.DeleteRoute(
 				ctx,
 				vni,
@@ -305,7 +307,11 @@ func (c *MetalnetClient) handleDefaultRouterChange(operation DefaultRouteOperati
 	existingVNIs := c.mbInstance.GetSubscribedVnis()
 
 	for _, vni := range existingVNIs {
-		if uint32(vni) == c.DefaultRouterAddress.PublicVNI {
+		if uint32(vni) == c.DefaultRouterAddress.PublicVNI || uint32(vni) == c.DefaultRouterAddress.PublicVNIIPv6 {
+			continue
+		}
+		// Networks with a default route of their own do not use the default router.
+		if c.metalnetCache.HasOwnDefaultRoute(uint32(vni)) {
 			continue
 		}
 
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMetalbond(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metalbond Suite")
+}
+
+// newDPDKClient returns a client of a dpservice simulator running for the current spec. An interface is
+// created in each of the given VNIs, as dpservice only accepts routes in VNIs in use.
+func newDPDKClient(ctx context.Context, vnis ...uint32) dpdkclient.Client {
+	lis := bufconn.Listen(1 << 20)
+	srv := dpservice.NewServer(dpservice.Options{}).Start(lis)
+	DeferCleanup(srv.Stop)
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(conn.Close)
+	c := dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+	for i, vni := range vnis {
+		ip := netip.AddrFrom4([4]byte{192, 168, 0, byte(i + 1)})
+		_, err := c.CreateInterface(ctx, &dpdk.Interface{
+			InterfaceMeta: dpdk.InterfaceMeta{ID: fmt.Sprintf("nic-%d", vni)},
+			Spec:          dpdk.InterfaceSpec{VNI: vni, Device: fmt.Sprintf("net_tap%d", i+4), IPv4: &ip},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+	return c
+}
+
+// listRoutes returns the prefixes routed in the given VNI with the VNIs of their next hops.
+func listRoutes(ctx context.Context, c dpdkclient.Client, vni uint32) map[string]uint32 {
+	GinkgoHelper()
+	list, err := c.ListRoutes(ctx, vni)
+	Expect(err).NotTo(HaveOccurred())
+	routes := make(map[string]uint32)
+	for _, route := range list.Items {
+		routes[route.Spec.Prefix.String()] = route.Spec.NextHop.VNI
+	}
+	return routes
+}
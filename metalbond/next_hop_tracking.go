@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	nextHopHostsUnreachable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "metalnet_metalbond_next_hop_hosts_unreachable",
+		Help: "Number of hosts announcing metalbond next hops currently considered unreachable, whose routes are not programmed.",
+	})
+	nextHopHostFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metalnet_metalbond_next_hop_host_failures_total",
+		Help: "Number of times a host announcing metalbond next hops became unreachable and its routes were removed.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(nextHopHostsUnreachable, nextHopHostFailures)
+}
+
+type NextHopTrackingOptions struct {
+	// UnderlayAddress is the underlay address of this node. It is required.
+	//
+	// A next hop is an underlay route dpservice derived from the underlay prefix of the announcing node, so it
+	// does not answer probes itself. The host of the announcing node is probed instead, at the address with the
+	// interface identifier of UnderlayAddress in the underlay prefix of the next hop. All nodes are expected to
+	// number their underlay address the same way within their underlay prefix.
+	UnderlayAddress netip.Addr
+	// UnderlayPrefixLength is the length of the underlay prefix of the nodes. Defaults to 64.
+	UnderlayPrefixLength int
+	// Interval is the interval the hosts are probed at. Defaults to one second.
+	Interval time.Duration
+	// Timeout is the timeout of a single probe. Defaults to the interval.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed probes after which a host is considered
+	// unreachable. Defaults to three.
+	FailureThreshold int
+	// Port is the TCP port the default probe connects to. Defaults to 4711. As a refused connection counts as
+	// reachable, any port the hosts do not filter works.
+	Port uint16
+	// Probe probes the reachability of the host of a node. A nil error means the host is reachable. Defaults to
+	// opening a TCP connection to Port.
+	Probe func(ctx context.Context, addr netip.Addr) error
+}
+
+type trackedHost struct {
+	mu      sync.Mutex
+	deleted bool
+
+	// routes are the received routes with a next hop of this host.
+	routes      map[routeKey]struct{}
+	failures    int
+	unreachable bool
+}
+
+// NextHopTrackingClient is a metalbond client that probes the hosts announcing the next hops of the received
+// routes and removes the routes of unreachable hosts from the wrapped client, so traffic fails over to other next
+// hops of the same destination or is dropped locally well before the metalbond keepalive of the announcing node
+// times out.
+//
+// A host failing FailureThreshold probes in a row is considered unreachable. The routes of all its next hops are
+// removed, and further updates of them are recorded but not passed on. Probing goes on, and the routes are added
+// again after the next successful probe.
+type NextHopTrackingClient struct {
+	client mb.Client
+	opts   NextHopTrackingOptions
+	log    *logr.Logger
+
+	mu    sync.Mutex
+	hosts map[netip.Addr]*trackedHost
+}
+
+func NewNextHopTrackingClient(log *logr.Logger, client mb.Client, opts NextHopTrackingOptions) *NextHopTrackingClient {
+	if opts.UnderlayPrefixLength <= 0 {
+		opts.UnderlayPrefixLength = 64
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = opts.Interval
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 3
+	}
+	if opts.Port == 0 {
+		opts.Port = 4711
+	}
+	c := &NextHopTrackingClient{
+		client: client,
+		opts:   opts,
+		log:    log,
+		hosts:  make(map[netip.Addr]*trackedHost),
+	}
+	if c.opts.Probe == nil {
+		c.opts.Probe = c.probeTCP
+	}
+	return c
+}
+
+// probeTCP opens a TCP connection to the host. A refused connection counts as reachable, as the host answered.
+func (c *NextHopTrackingClient) probeTCP(ctx context.Context, addr netip.Addr) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(addr.String(), strconv.Itoa(int(c.opts.Port))))
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return nil
+		}
+		return err
+	}
+	return conn.Close()
+}
+
+// hostOf returns the address of the host announcing the given next hop: the interface identifier of the
+// underlay address of this node in the underlay prefix of the next hop.
+func (c *NextHopTrackingClient) hostOf(nextHop netip.Addr) netip.Addr {
+	host := nextHop.As16()
+	own := c.opts.UnderlayAddress.As16()
+	for i := range host {
+		mask := byte(0xff) << (8 - min(max(c.opts.UnderlayPrefixLength-8*i, 0), 8))
+		host[i] = host[i]&mask | own[i]&^mask
+	}
+	return netip.AddrFrom16(host)
+}
+
+// lockHost returns the locked state of the host announcing the given next hop, creating it if it does not exist.
+func (c *NextHopTrackingClient) lockHost(nextHop netip.Addr) (netip.Addr, *trackedHost) {
+	addr := c.hostOf(nextHop)
+	for {
+		c.mu.Lock()
+		host, ok := c.hosts[addr]
+		if !ok {
+			host = &trackedHost{routes: make(map[routeKey]struct{})}
+			c.hosts[addr] = host
+		}
+		c.mu.Unlock()
+
+		host.mu.Lock()
+		if !host.deleted {
+			return addr, host
+		}
+		host.mu.Unlock()
+	}
+}
+
+func (c *NextHopTrackingClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	_, tracked := c.lockHost(hop.TargetAddress)
+	defer tracked.mu.Unlock()
+
+	tracked.routes[routeKey{vni, dest, hop}] = struct{}{}
+	if tracked.unreachable {
+		c.log.V(1).Info("Holding back route of unreachable host", "VNI", vni, "Destination", dest, "NextHop", hop)
+		return nil
+	}
+	return c.client.AddRoute(vni, dest, hop)
+}
+
+func (c *NextHopTrackingClient) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	addr, tracked := c.lockHost(hop.TargetAddress)
+	defer tracked.mu.Unlock()
+
+	delete(tracked.routes, routeKey{vni, dest, hop})
+	if len(tracked.routes) == 0 {
+		if tracked.unreachable {
+			nextHopHostsUnreachable.Dec()
+		}
+		c.mu.Lock()
+		tracked.deleted = true
+		delete(c.hosts, addr)
+		c.mu.Unlock()
+	}
+	if tracked.unreachable {
+		return nil
+	}
+	return c.client.RemoveRoute(vni, dest, hop)
+}
+
+// Start probes the hosts periodically until the context is done.
+func (c *NextHopTrackingClient) Start(ctx context.Context) error {
+	c.log.Info("Probing hosts of metalbond next hops", "Interval", c.opts.Interval, "FailureThreshold", c.opts.FailureThreshold)
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.Probe(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Probe probes all hosts once in parallel, and removes the routes of hosts that became unreachable and adds the
+// routes of hosts that recovered.
+func (c *NextHopTrackingClient) Probe(ctx context.Context) {
+	c.mu.Lock()
+	addrs := make([]netip.Addr, 0, len(c.hosts))
+	for addr := range c.hosts {
+		addrs = append(addrs, addr)
+	}
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr netip.Addr) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+			defer cancel()
+			c.record(addr, c.opts.Probe(probeCtx, addr))
+		}(addr)
+	}
+	wg.Wait()
+}
+
+// record records the result of probing the given host and updates its routes if it became unreachable or
+// recovered.
+func (c *NextHopTrackingClient) record(addr netip.Addr, probeErr error) {
+	c.mu.Lock()
+	tracked, ok := c.hosts[addr]
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+	if tracked.deleted {
+		return
+	}
+
+	if probeErr == nil {
+		tracked.failures = 0
+		if !tracked.unreachable {
+			return
+		}
+		c.log.Info("Host of metalbond next hops recovered, adding its routes again", "Host", addr, "Routes", len(tracked.routes))
+		nextHopHostsUnreachable.Dec()
+		tracked.unreachable = false
+		for key := range tracked.routes {
+			if err := c.client.AddRoute(key.vni, key.dest, key.nextHop); err != nil {
+				c.log.Error(err, "Error processing metalbond route", "VNI", key.vni, "Destination", key.dest, "NextHop", key.nextHop)
+			}
+		}
+		return
+	}
+
+	tracked.failures++
+	if tracked.unreachable || tracked.failures < c.opts.FailureThreshold {
+		return
+	}
+	c.log.Info("Host of metalbond next hops is unreachable, removing its routes", "Host", addr, "Routes", len(tracked.routes),
+		"Error", probeErr.Error())
+	nextHopHostsUnreachable.Inc()
+	nextHopHostFailures.Inc()
+	tracked.unreachable = true
+	for key := range tracked.routes {
+		if err := c.client.RemoveRoute(key.vni, key.dest, key.nextHop); err != nil {
+			c.log.Error(err, "Error processing metalbond route", "VNI", key.vni, "Destination", key.dest, "NextHop", key.nextHop)
+		}
+	}
+}
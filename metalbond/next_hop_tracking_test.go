@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NextHopTrackingClient", func() {
+	var (
+		client *recordingClient
+		c      *metalbond.NextHopTrackingClient
+
+		mu          sync.Mutex
+		unreachable map[netip.Addr]bool
+		probed      map[netip.Addr]int
+
+		dest1 = mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+		dest2 = mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.2/32")}
+		dest3 = mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.3/32")}
+		// The next hops are underlay routes dpservice derived from the underlay prefix of the announcing node,
+		// whose host has the same interface identifier as the underlay address of this node, 2001:db8:0:1::1.
+		hop1  = mb.NextHop{TargetAddress: netip.MustParseAddr("2001:db8:0:2:d0a8:3100:0:1"), Type: pb.NextHopType_STANDARD}
+		hop1b = mb.NextHop{TargetAddress: netip.MustParseAddr("2001:db8:0:2:d0a8:3200:0:5"), Type: pb.NextHopType_STANDARD}
+		hop2  = mb.NextHop{TargetAddress: netip.MustParseAddr("2001:db8:0:3:d0a8:3100:0:1"), Type: pb.NextHopType_STANDARD}
+		host1 = netip.MustParseAddr("2001:db8:0:2::1")
+		host2 = netip.MustParseAddr("2001:db8:0:3::1")
+	)
+
+	setUnreachable := func(addr netip.Addr, value bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		unreachable[addr] = value
+	}
+
+	BeforeEach(func() {
+		client = &recordingClient{}
+		unreachable = make(map[netip.Addr]bool)
+		probed = make(map[netip.Addr]int)
+		log := logr.Discard()
+		c = metalbond.NewNextHopTrackingClient(&log, client, metalbond.NextHopTrackingOptions{
+			UnderlayAddress:  netip.MustParseAddr("2001:db8:0:1::1"),
+			FailureThreshold: 2,
+			Probe: func(_ context.Context, addr netip.Addr) error {
+				mu.Lock()
+				defer mu.Unlock()
+				probed[addr]++
+				if unreachable[addr] {
+					return errors.New("timeout")
+				}
+				return nil
+			},
+		})
+	})
+
+	It("should remove the routes of an unreachable next hop until it recovers", func(ctx SpecContext) {
+		Expect(c.AddRoute(100, dest1, hop1)).To(Succeed())
+		Expect(c.AddRoute(100, dest2, hop1)).To(Succeed())
+		Expect(c.AddRoute(100, dest3, hop2)).To(Succeed())
+		Expect(client.Calls()).To(HaveLen(3))
+
+		setUnreachable(host1, true)
+		c.Probe(ctx)
+		Expect(client.Calls()).To(HaveLen(3))
+		c.Probe(ctx)
+		Expect(client.Calls()[3:]).To(ConsistOf("remove 100 10.0.0.1/32", "remove 100 10.0.0.2/32"))
+
+		By("holding back updates of the routes of the unreachable next hop")
+		Expect(c.RemoveRoute(100, dest2, hop1)).To(Succeed())
+		Expect(c.AddRoute(200, dest1, hop1)).To(Succeed())
+		Expect(client.Calls()).To(HaveLen(5))
+
+		By("adding the latest routes once the next hop recovered")
+		setUnreachable(host1, false)
+		c.Probe(ctx)
+		Expect(client.Calls()[5:]).To(ConsistOf("add 100 10.0.0.1/32", "add 200 10.0.0.1/32"))
+	})
+
+	It("should probe the host announcing the next hops and remove the routes of all of them", func(ctx SpecContext) {
+		Expect(c.AddRoute(100, dest1, hop1)).To(Succeed())
+		Expect(c.AddRoute(100, dest2, hop1b)).To(Succeed())
+		Expect(c.AddRoute(100, dest3, hop2)).To(Succeed())
+
+		setUnreachable(host1, true)
+		c.Probe(ctx)
+		c.Probe(ctx)
+		mu.Lock()
+		Expect(probed).To(Equal(map[netip.Addr]int{host1: 2, host2: 2}))
+		mu.Unlock()
+		Expect(client.Calls()[3:]).To(ConsistOf("remove 100 10.0.0.1/32", "remove 100 10.0.0.2/32"))
+	})
+
+	It("should not remove routes of a next hop failing fewer probes than the threshold in a row", func(ctx SpecContext) {
+		Expect(c.AddRoute(100, dest1, hop1)).To(Succeed())
+
+		setUnreachable(host1, true)
+		c.Probe(ctx)
+		setUnreachable(host1, false)
+		c.Probe(ctx)
+		setUnreachable(host1, true)
+		c.Probe(ctx)
+		Expect(client.Calls()).To(Equal([]string{"add 100 10.0.0.1/32"}))
+	})
+
+	It("should forget next hops without routes", func(ctx SpecContext) {
+		Expect(c.AddRoute(100, dest1, hop1)).To(Succeed())
+		setUnreachable(host1, true)
+		c.Probe(ctx)
+		c.Probe(ctx)
+		Expect(c.RemoveRoute(100, dest1, hop1)).To(Succeed())
+		Expect(client.Calls()).To(Equal([]string{"add 100 10.0.0.1/32", "remove 100 10.0.0.1/32"}))
+
+		By("passing the routes of the next hop on again once it is announced again")
+		Expect(c.AddRoute(100, dest1, hop1)).To(Succeed())
+		Expect(client.Calls()).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var routesRejected = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "metalnet_metalbond_routes_rejected_total",
+	Help: "Number of received metalbond routes not programmed because their next hop is no IPv6 address or not in the allowed underlay ranges.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(routesRejected)
+}
+
+// IsUnderlayAddress reports whether the given address can be a next hop in the underlay. The underlay of
+// dpservice is IPv6 only, so IPv4 and IPv4-mapped IPv6 addresses are no underlay addresses.
+func IsUnderlayAddress(addr netip.Addr) bool {
+	return addr.Is6() && !addr.Is4In6() && !addr.IsUnspecified()
+}
+
+// NextHopValidationClient is a metalbond client that only passes routes whose next hop is an underlay address
+// in one of the allowed underlay ranges to the wrapped client. It protects against peers announcing routes that
+// steer the traffic of a VNI to an address outside the underlay. Without allowed ranges, all underlay addresses
+// are allowed.
+//
+// Removals of rejected routes are dropped as well, as the routes were never programmed.
+type NextHopValidationClient struct {
+	client  mb.Client
+	allowed []netip.Prefix
+	log     *logr.Logger
+}
+
+func NewNextHopValidationClient(log *logr.Logger, client mb.Client, allowed []netip.Prefix) *NextHopValidationClient {
+	return &NextHopValidationClient{
+		client:  client,
+		allowed: allowed,
+		log:     log,
+	}
+}
+
+func (c *NextHopValidationClient) validNextHop(hop mb.NextHop) bool {
+	if !IsUnderlayAddress(hop.TargetAddress) {
+		return false
+	}
+	if len(c.allowed) == 0 {
+		return true
+	}
+	for _, prefix := range c.allowed {
+		if prefix.Contains(hop.TargetAddress) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *NextHopValidationClient) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	if !c.validNextHop(hop) {
+		routesRejected.Inc()
+		c.log.Info("Rejecting route with next hop outside of the underlay",
+			"VNI", vni, "Destination", dest, "NextHop", hop)
+		return nil
+	}
+	return c.client.AddRoute(vni, dest, hop)
+}
+
+func (c *NextHopValidationClient) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	if !c.validNextHop(hop) {
+		return nil
+	}
+	return c.client.RemoveRoute(vni, dest, hop)
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NextHopValidationClient", func() {
+	It("should only pass routes with next hops in the allowed underlay ranges", func() {
+		client := &recordingClient{}
+		log := logr.Discard()
+		c := metalbond.NewNextHopValidationClient(&log, client, []netip.Prefix{netip.MustParsePrefix("fc00::/64")})
+
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+		valid := mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_STANDARD}
+		bogus := mb.NextHop{TargetAddress: netip.MustParseAddr("fc00:1::1"), Type: pb.NextHopType_STANDARD}
+
+		Expect(c.AddRoute(100, dest, valid)).To(Succeed())
+		Expect(c.AddRoute(100, dest, bogus)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, bogus)).To(Succeed())
+		Expect(c.RemoveRoute(100, dest, valid)).To(Succeed())
+		Expect(client.Calls()).To(Equal([]string{"add 100 10.0.0.1/32", "remove 100 10.0.0.1/32"}))
+	})
+
+	It("should only pass routes with IPv6 next hops without allowed underlay ranges", func() {
+		client := &recordingClient{}
+		log := logr.Discard()
+		c := metalbond.NewNextHopValidationClient(&log, client, nil)
+
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.0.0.1/32")}
+		for _, addr := range []string{"10.1.0.1", "::ffff:10.1.0.1", "::"} {
+			Expect(c.AddRoute(100, dest, mb.NextHop{TargetAddress: netip.MustParseAddr(addr), Type: pb.NextHopType_STANDARD})).To(Succeed())
+		}
+		Expect(c.AddRoute(100, dest, mb.NextHop{TargetAddress: netip.MustParseAddr("2001:db8::1"), Type: pb.NextHopType_STANDARD})).To(Succeed())
+		Expect(client.Calls()).To(Equal([]string{"add 100 10.0.0.1/32"}))
+	})
+})
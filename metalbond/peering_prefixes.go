@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+)
+
+// isExchanged reports whether the route to the prefix in vni may be installed into peeredVNI. This is the case
+// if the prefix is within the peered prefixes and the export prefixes of vni for peeredVNI and within the import
+// prefixes of peeredVNI for vni.
+func (c *MetalnetClient) isExchanged(vni, peeredVNI uint32, prefix netip.Prefix) bool {
+	if peeredPrefixes, ok := c.metalnetCache.GetPeeredPrefixes(vni); ok {
+		if allowed, exists := peeredPrefixes[peeredVNI]; exists && !slices.ContainsFunc(allowed, func(p netip.Prefix) bool {
+			return p.Contains(prefix.Addr())
+		}) {
+			return false
+		}
+	}
+	if exported, ok := c.metalnetCache.GetPeeringPrefixes(vni, peeredVNI); ok && !prefixesContain(exported.Export, prefix) {
+		return false
+	}
+	if imported, ok := c.metalnetCache.GetPeeringPrefixes(peeredVNI, vni); ok && !prefixesContain(imported.Import, prefix) {
+		return false
+	}
+	return true
+}
+
+// prefixesContain reports whether the prefix lies within one of the prefixes. No prefixes contain all.
+func prefixesContain(prefixes []netip.Prefix, prefix netip.Prefix) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(prefixes, func(p netip.Prefix) bool {
+		return p.Bits() <= prefix.Bits() && p.Contains(prefix.Addr())
+	})
+}
+
+// CleanupNotExchangedRoutes deletes the routes of the given VNI whose next hop lies in a peered VNI that no
+// longer exports them to the VNI or that the VNI no longer imports. Routes that became exchanged are only
+// installed once the routes of the peered VNI are received again.
+func (c *MetalnetClient) CleanupNotExchangedRoutes(ctx context.Context, vni uint32) error {
+	routes, err := c.dpdk.ListRoutes(ctx, vni, dpdkerrors.Ignore(dpdkerrors.NO_VNI))
+	if err != nil {
+		return fmt.Errorf("error listing dpdk routes for vni %d: %w", vni, err)
+	}
+
+	for _, route := range routes.Items {
+		if route.Spec.NextHop == nil || route.Spec.NextHop.VNI == vni || route.Spec.Prefix == nil ||
+			c.isExchanged(route.Spec.NextHop.VNI, vni, *route.Spec.Prefix) {
+			continue
+		}
+
+		c.log.V(1).Info("Removing route not exchanged with peered vni", "VNI", vni, "Prefix", route.Spec.Prefix, "NextHopVNI", route.Spec.NextHop.VNI)
+		if _, err := c.dpdk.DeleteRoute(
+			ctx,
+			vni,
+			route.Spec.Prefix,
+			dpdkerrors.Ignore(dpdkerrors.NO_VNI, dpdkerrors.ROUTE_NOT_FOUND),
+		); err != nil {
+			return fmt.Errorf("error deleting route: %w", err)
+		}
+	}
+	return nil
+}
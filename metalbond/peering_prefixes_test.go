@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"net/netip"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Peering prefixes", func() {
+	var (
+		dpdkClient dpdkclient.Client
+		cache      *internal.MetalnetCache
+		client     *metalbond.MetalnetClient
+		hop        = mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_STANDARD}
+	)
+
+	addRoute := func(vni mb.VNI, prefix string) {
+		GinkgoHelper()
+		Expect(client.AddRoute(vni, mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix(prefix)}, hop)).To(Succeed())
+	}
+
+	BeforeEach(func(ctx SpecContext) {
+		dpdkClient = newDPDKClient(ctx, 100, 200)
+		log := logr.Discard()
+		cache = internal.NewMetalnetCache(&log)
+		client = metalbond.NewMetalnetClient(&log, dpdkClient, cache, &metalbond.DefaultRouterAddress{}, metalbond.ClientOptions{})
+		Expect(cache.AddVniToPeerVnis(100, 200)).To(Succeed())
+		Expect(cache.AddVniToPeerVnis(200, 100)).To(Succeed())
+	})
+
+	It("should only export the export prefixes while importing everything", func(ctx SpecContext) {
+		cache.SetPeeringPrefixes(100, map[uint32]internal.PeeringPrefixes{
+			200: {Export: []netip.Prefix{netip.MustParsePrefix("10.0.1.0/24")}},
+		})
+
+		addRoute(100, "10.0.1.5/32")
+		addRoute(100, "10.0.2.5/32")
+		addRoute(200, "10.1.0.5/32")
+
+		Expect(listRoutes(ctx, dpdkClient, 200)).To(HaveKey("10.0.1.5/32"))
+		Expect(listRoutes(ctx, dpdkClient, 200)).NotTo(HaveKey("10.0.2.5/32"))
+		Expect(listRoutes(ctx, dpdkClient, 100)).To(HaveKey("10.1.0.5/32"))
+	})
+
+	It("should only import the import prefixes", func(ctx SpecContext) {
+		cache.SetPeeringPrefixes(100, map[uint32]internal.PeeringPrefixes{
+			200: {Import: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}},
+		})
+
+		addRoute(200, "10.1.0.5/32")
+		addRoute(200, "10.2.0.5/32")
+		addRoute(200, "10.0.0.0/8")
+
+		Expect(listRoutes(ctx, dpdkClient, 100)).To(HaveKey("10.1.0.5/32"))
+		Expect(listRoutes(ctx, dpdkClient, 100)).NotTo(HaveKey("10.2.0.5/32"))
+		Expect(listRoutes(ctx, dpdkClient, 100)).NotTo(HaveKey("10.0.0.0/8"))
+	})
+
+	It("should remove routes no longer exchanged", func(ctx SpecContext) {
+		addRoute(100, "10.0.1.5/32")
+		addRoute(100, "10.0.2.5/32")
+		Expect(listRoutes(ctx, dpdkClient, 100)).To(HaveLen(2))
+		Expect(listRoutes(ctx, dpdkClient, 200)).To(HaveLen(2))
+
+		cache.SetPeeringPrefixes(200, map[uint32]internal.PeeringPrefixes{
+			100: {Import: []netip.Prefix{netip.MustParsePrefix("10.0.1.0/24")}},
+		})
+		Expect(client.CleanupNotExchangedRoutes(ctx, 200)).To(Succeed())
+		Expect(listRoutes(ctx, dpdkClient, 200)).To(Equal(map[string]uint32{"10.0.1.5/32": 100}))
+		Expect(listRoutes(ctx, dpdkClient, 100)).To(HaveKey("10.0.2.5/32"))
+	})
+})
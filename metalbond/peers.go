@@ -0,0 +1,281 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+)
+
+// Peers manages the peer sessions of a metalbond instance. It is implemented by *metalbond.MetalBond.
+type Peers interface {
+	AddPeer(addr, localIP string) error
+	RemovePeer(addr string) error
+	PeerState(addr string) (mb.ConnectionState, error)
+}
+
+type PeerManagerOptions struct {
+	// SyncPeriod is the time given to new peer sessions to sync their routes after being established
+	// before the removed peers are drained. Defaults to 10 seconds.
+	SyncPeriod time.Duration
+	// PollInterval is the interval the peer states and the peers file are checked at. Defaults to 1 second.
+	PollInterval time.Duration
+	// LocalAddress is the address the peer sessions are dialed from, e.g. the IPv6 loopback address of the
+	// node. If unset, the address is chosen by the kernel.
+	LocalAddress netip.Addr
+}
+
+// PeerManager applies changes of the configured metalbond peers without restarting metalnet.
+//
+// New peers are added right away. Removed peers are only drained once a new peer session is
+// established and had time to sync its routes, so the routes received via the removed peers are
+// replaced by the same routes received via the new peers instead of flapping.
+type PeerManager struct {
+	peers        Peers
+	syncPeriod   time.Duration
+	pollInterval time.Duration
+	localAddress netip.Addr
+	log          *logr.Logger
+
+	mu sync.Mutex
+	// currentMu guards writes to current against Connected. SetPeers reads it under mu.
+	currentMu sync.RWMutex
+	current   map[string]struct{}
+}
+
+func NewPeerManager(log *logr.Logger, peers Peers, opts PeerManagerOptions) *PeerManager {
+	syncPeriod := opts.SyncPeriod
+	if syncPeriod <= 0 {
+		syncPeriod = 10 * time.Second
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &PeerManager{
+		peers:        peers,
+		syncPeriod:   syncPeriod,
+		pollInterval: pollInterval,
+		localAddress: opts.LocalAddress,
+		log:          log,
+		current:      make(map[string]struct{}),
+	}
+}
+
+// SetPeers adds the new peers and drains the peers no longer configured. If peers are removed,
+// it blocks until a new session is synced or the context is done. Peers are given as host:port, with
+// IPv6 addresses in brackets. If any peer is invalid, the peers are left unchanged.
+func (m *PeerManager) SetPeers(ctx context.Context, addrs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	normalized := make([]string, len(addrs))
+	desired := make(map[string]struct{}, len(addrs))
+	for i, addr := range addrs {
+		var err error
+		if normalized[i], err = m.validatePeer(addr); err != nil {
+			return err
+		}
+		desired[normalized[i]] = struct{}{}
+	}
+
+	for addr := range desired {
+		if _, ok := m.current[addr]; ok {
+			continue
+		}
+		m.log.Info("Adding metalbond peer", "Peer", addr)
+		if err := m.peers.AddPeer(addr, m.localIP()); err != nil {
+			return fmt.Errorf("error adding metalbond peer %s: %w", addr, err)
+		}
+		m.currentMu.Lock()
+		m.current[addr] = struct{}{}
+		m.currentMu.Unlock()
+	}
+
+	var removed []string
+	for addr := range m.current {
+		if _, ok := desired[addr]; !ok {
+			removed = append(removed, addr)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	sort.Strings(removed)
+
+	if len(desired) > 0 {
+		m.log.Info("Waiting for a metalbond peer session to be synced before draining removed peers", "Removed", removed)
+		if err := m.waitForSyncedPeer(ctx, normalized); err != nil {
+			return err
+		}
+	}
+
+	for _, addr := range removed {
+		m.log.Info("Draining metalbond peer", "Peer", addr)
+		if err := m.peers.RemovePeer(addr); err != nil {
+			return fmt.Errorf("error removing metalbond peer %s: %w", addr, err)
+		}
+		m.currentMu.Lock()
+		delete(m.current, addr)
+		m.currentMu.Unlock()
+	}
+	return nil
+}
+
+// validatePeer normalizes the given peer address and checks that it can be dialed from the local address.
+func (m *PeerManager) validatePeer(addr string) (string, error) {
+	normalized, err := NormalizePeerAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	if !m.localAddress.IsValid() {
+		return normalized, nil
+	}
+	host, _, _ := net.SplitHostPort(normalized)
+	if ip, err := netip.ParseAddr(host); err == nil && ip.Is4() != m.localAddress.Is4() {
+		return "", fmt.Errorf("metalbond peer %s cannot be dialed from local address %s of another ip family", addr, m.localAddress)
+	}
+	return normalized, nil
+}
+
+// localIP returns the local address in the form metalbond appends the port to.
+func (m *PeerManager) localIP() string {
+	switch {
+	case !m.localAddress.IsValid():
+		return ""
+	case m.localAddress.Is6():
+		return "[" + m.localAddress.String() + "]"
+	default:
+		return m.localAddress.String()
+	}
+}
+
+// NormalizePeerAddress checks that the given peer address is of the form host:port, with IPv6 addresses
+// in brackets, and returns it in canonical form.
+func NormalizePeerAddress(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid metalbond peer %q, expected host:port with IPv6 addresses in brackets: %w", addr, err)
+	}
+	if host == "" || port == "" {
+		return "", fmt.Errorf("invalid metalbond peer %q, expected host:port with IPv6 addresses in brackets", addr)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		host = ip.Unmap().String()
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// waitForSyncedPeer waits until one of the given peers is established for the sync period.
+func (m *PeerManager) waitForSyncedPeer(ctx context.Context, addrs []string) error {
+	var establishedSince time.Time
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if m.isAnyEstablished(addrs) {
+			if establishedSince.IsZero() {
+				establishedSince = time.Now()
+			}
+			if time.Since(establishedSince) >= m.syncPeriod {
+				return nil
+			}
+		} else {
+			establishedSince = time.Time{}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error waiting for a metalbond peer session to be synced: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *PeerManager) isAnyEstablished(addrs []string) bool {
+	for _, addr := range addrs {
+		if state, err := m.peers.PeerState(addr); err == nil && state == mb.ESTABLISHED {
+			return true
+		}
+	}
+	return false
+}
+
+// Connected reports whether a session to any of the current peers is established.
+func (m *PeerManager) Connected() bool {
+	m.currentMu.RLock()
+	addrs := make([]string, 0, len(m.current))
+	for addr := range m.current {
+		addrs = append(addrs, addr)
+	}
+	m.currentMu.RUnlock()
+	return m.isAnyEstablished(addrs)
+}
+
+// WatchPeersFile applies the peers listed in the given file whenever its content changes,
+// until the context is done.
+func (m *PeerManager) WatchPeersFile(ctx context.Context, filename string) error {
+	var last []byte
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		data, err := os.ReadFile(filename)
+		switch {
+		case err != nil:
+			m.log.Error(err, "Error reading metalbond peers file", "File", filename)
+		case !bytes.Equal(data, last):
+			m.log.Info("Metalbond peers file changed, applying peers", "File", filename)
+			if err := m.SetPeers(ctx, ParsePeers(data)); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				m.log.Error(err, "Error applying metalbond peers")
+			} else {
+				last = data
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// LoadPeersFile reads the peers listed in the given file.
+func LoadPeersFile(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading metalbond peers file: %w", err)
+	}
+	return ParsePeers(data), nil
+}
+
+// ParsePeers parses a list of peer addresses, one per line. Empty lines and lines starting with # are ignored.
+func ParsePeers(data []byte) []string {
+	var peers []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		peers = append(peers, line)
+	}
+	return peers
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakePeers records the peers with their local ips and reports the configured states for them.
+type fakePeers struct {
+	mu     sync.Mutex
+	peers  map[string]string
+	states map[string]mb.ConnectionState
+}
+
+func newFakePeers() *fakePeers {
+	return &fakePeers{peers: make(map[string]string), states: make(map[string]mb.ConnectionState)}
+}
+
+func (p *fakePeers) AddPeer(addr, localIP string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.peers[addr]; ok {
+		return fmt.Errorf("peer %s already registered", addr)
+	}
+	p.peers[addr] = localIP
+	return nil
+}
+
+func (p *fakePeers) RemovePeer(addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.peers, addr)
+	return nil
+}
+
+func (p *fakePeers) PeerState(addr string) (mb.ConnectionState, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.peers[addr]; !ok {
+		return mb.CLOSED, fmt.Errorf("peer %s does not exist", addr)
+	}
+	return p.states[addr], nil
+}
+
+func (p *fakePeers) setState(addr string, state mb.ConnectionState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.states[addr] = state
+}
+
+func (p *fakePeers) list() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var addrs []string
+	for addr := range p.peers {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+var _ = Describe("PeerManager", func() {
+	var (
+		ctx   context.Context
+		peers *fakePeers
+		m     *metalbond.PeerManager
+	)
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		peers = newFakePeers()
+		log := logr.Discard()
+		m = metalbond.NewPeerManager(&log, peers, metalbond.PeerManagerOptions{
+			SyncPeriod:   50 * time.Millisecond,
+			PollInterval: 10 * time.Millisecond,
+		})
+		Expect(m.SetPeers(ctx, []string{"[fd00::1]:4711"})).To(Succeed())
+	})
+
+	It("should drain removed peers only once a new session is synced", func() {
+		done := make(chan error)
+		go func() {
+			done <- m.SetPeers(ctx, []string{"[fd00::2]:4711"})
+		}()
+
+		Eventually(peers.list).Should(ConsistOf("[fd00::1]:4711", "[fd00::2]:4711"))
+		Consistently(peers.list, 100*time.Millisecond).Should(ConsistOf("[fd00::1]:4711", "[fd00::2]:4711"))
+
+		peers.setState("[fd00::2]:4711", mb.ESTABLISHED)
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(peers.list()).To(ConsistOf("[fd00::2]:4711"))
+	})
+
+	It("should apply the changes of the peers file", func() {
+		filename := filepath.Join(GinkgoT().TempDir(), "peers")
+		Expect(os.WriteFile(filename, []byte("# peers\n[fd00::1]:4711\n"), 0644)).To(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			Expect(m.WatchPeersFile(ctx, filename)).To(Succeed())
+		}()
+
+		peers.setState("[fd00::3]:4711", mb.ESTABLISHED)
+		Expect(os.WriteFile(filename, []byte("[fd00::1]:4711\n[fd00::3]:4711\n"), 0644)).To(Succeed())
+		Eventually(peers.list).Should(ConsistOf("[fd00::1]:4711", "[fd00::3]:4711"))
+
+		Expect(os.WriteFile(filename, []byte("[fd00::3]:4711\n"), 0644)).To(Succeed())
+		Eventually(peers.list).Should(ConsistOf("[fd00::3]:4711"))
+	})
+
+	It("should reject peers that are not of the form host:port", func() {
+		Expect(m.SetPeers(ctx, []string{"[fd00::1]:4711", "fd00::2"})).NotTo(Succeed())
+		Expect(m.SetPeers(ctx, []string{"fd00::2:4711"})).NotTo(Succeed())
+		Expect(peers.list()).To(ConsistOf("[fd00::1]:4711"))
+	})
+})
+
+var _ = Describe("PeerManager with a local address", func() {
+	It("should dial the peers from the local address", func(ctx SpecContext) {
+		peers := newFakePeers()
+		log := logr.Discard()
+		m := metalbond.NewPeerManager(&log, peers, metalbond.PeerManagerOptions{
+			LocalAddress: netip.MustParseAddr("fd00::10"),
+		})
+
+		Expect(m.SetPeers(ctx, []string{"[FD00:0::1]:4711", "metalbond.example.com:4711"})).To(Succeed())
+		Expect(peers.peers).To(Equal(map[string]string{
+			"[fd00::1]:4711":             "[fd00::10]",
+			"metalbond.example.com:4711": "[fd00::10]",
+		}))
+
+		By("adding a peer of another ip family")
+		Expect(m.SetPeers(ctx, []string{"[fd00::1]:4711", "10.0.0.1:4711"})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// RouteIngesterQueueName is the name of the route ingestion queue. The queue depth and the time routes spend
+// in the queue before being processed are exported as the workqueue metrics carrying this name.
+const RouteIngesterQueueName = "metalbond_routes"
+
+var routeUpdatesCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "metalnet_metalbond_route_updates_coalesced_total",
+	Help: "Number of metalbond route updates superseded by a later update of the same route before being processed.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(routeUpdatesCoalesced)
+}
+
+type routeOperation int
+
+const (
+	addRouteOperation routeOperation = iota + 1
+	removeRouteOperation
+)
+
+type routeKey struct {
+	vni     mb.VNI
+	dest    mb.Destination
+	nextHop mb.NextHop
+}
+
+type RouteIngesterOptions struct {
+	// Workers is the number of routes processed in parallel. Defaults to 1.
+	Workers int
+}
+
+// RouteIngester is a metalbond client that queues the received route updates and hands them to the
+// wrapped client from a pool of workers, so the metalbond peer connection is not blocked by dpservice calls.
+//
+// Updates of a queued route are coalesced: an add and a remove of the same route cancel each other out, so a
+// route that flaps is applied at most once with its final state. Failed updates are retried with exponential
+// backoff unless they are canceled in the meantime. Updates of the same route are never processed concurrently.
+type RouteIngester struct {
+	client  mb.Client
+	workers int
+	log     *logr.Logger
+
+	mu      sync.Mutex
+	pending map[routeKey]routeOperation
+	queue   workqueue.RateLimitingInterface
+}
+
+func NewRouteIngester(log *logr.Logger, client mb.Client, opts RouteIngesterOptions) *RouteIngester {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	return &RouteIngester{
+		client:  client,
+		workers: workers,
+		log:     log,
+		pending: make(map[routeKey]routeOperation),
+		queue: workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+			Name: RouteIngesterQueueName,
+		}),
+	}
+}
+
+func (i *RouteIngester) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	i.enqueue(routeKey{vni, dest, hop}, addRouteOperation)
+	return nil
+}
+
+func (i *RouteIngester) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	i.enqueue(routeKey{vni, dest, hop}, removeRouteOperation)
+	return nil
+}
+
+func (i *RouteIngester) enqueue(key routeKey, op routeOperation) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.coalesce(key, op) {
+		routeUpdatesCoalesced.Inc()
+		return
+	}
+	i.pending[key] = op
+	i.queue.Add(key)
+}
+
+// coalesce merges the given update into the pending update of the route, if any, and reports whether there
+// was one. An add and a remove of the same route cancel each other out, as metalbond only removes announced
+// routes and only adds routes not announced yet. i.mu must be held.
+func (i *RouteIngester) coalesce(key routeKey, op routeOperation) bool {
+	pendingOp, ok := i.pending[key]
+	if !ok {
+		return false
+	}
+	if pendingOp != op {
+		delete(i.pending, key)
+	}
+	return true
+}
+
+// Start processes the queued routes until the context is done.
+func (i *RouteIngester) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for w := 0; w < i.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i.processNext() {
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	i.queue.ShutDown()
+	wg.Wait()
+	return nil
+}
+
+func (i *RouteIngester) processNext() bool {
+	item, shutdown := i.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer i.queue.Done(item)
+
+	key := item.(routeKey)
+	i.mu.Lock()
+	op, ok := i.pending[key]
+	delete(i.pending, key)
+	i.mu.Unlock()
+	if !ok {
+		i.queue.Forget(key)
+		return true
+	}
+
+	var err error
+	switch op {
+	case addRouteOperation:
+		err = i.client.AddRoute(key.vni, key.dest, key.nextHop)
+	case removeRouteOperation:
+		err = i.client.RemoveRoute(key.vni, key.dest, key.nextHop)
+	}
+	if err == nil {
+		i.queue.Forget(key)
+		return true
+	}
+
+	i.log.Error(err, "Error processing metalbond route, retrying", "VNI", key.vni, "Destination", key.dest, "NextHop", key.nextHop)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	// The failed update is treated like an update received now, so a later update received in the meantime
+	// still cancels it or supersedes it.
+	if i.coalesce(key, op) {
+		return true
+	}
+	i.pending[key] = op
+	i.queue.AddRateLimited(key)
+	return true
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/go-logr/logr"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// recordingClient records the route updates and fails the first failures of them.
+type recordingClient struct {
+	mu       sync.Mutex
+	calls    []string
+	failures int
+}
+
+func (c *recordingClient) record(op string, vni mb.VNI, dest mb.Destination) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, fmt.Sprintf("%s %d %s", op, vni, dest.Prefix))
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("dpservice unavailable")
+	}
+	return nil
+}
+
+func (c *recordingClient) AddRoute(vni mb.VNI, dest mb.Destination, _ mb.NextHop) error {
+	return c.record("add", vni, dest)
+}
+
+func (c *recordingClient) RemoveRoute(vni mb.VNI, dest mb.Destination, _ mb.NextHop) error {
+	return c.record("remove", vni, dest)
+}
+
+func (c *recordingClient) Calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.calls...)
+}
+
+var _ = Describe("RouteIngester", func() {
+	var (
+		client   *recordingClient
+		ingester *metalbond.RouteIngester
+		hop      = mb.NextHop{TargetAddress: netip.MustParseAddr("fc00::1"), Type: pb.NextHopType_STANDARD}
+	)
+
+	dest := func(prefix string) mb.Destination {
+		return mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix(prefix)}
+	}
+
+	start := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(ingester.Start(ctx)).To(Succeed())
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+	}
+
+	BeforeEach(func() {
+		log := logr.Discard()
+		client = &recordingClient{}
+		ingester = metalbond.NewRouteIngester(&log, client, metalbond.RouteIngesterOptions{Workers: 4})
+	})
+
+	It("should hand all routes to the wrapped client", func() {
+		start()
+		for i := 0; i < 100; i++ {
+			Expect(ingester.AddRoute(1, dest(fmt.Sprintf("10.0.%d.0/24", i)), hop)).To(Succeed())
+		}
+
+		Eventually(client.Calls).Should(HaveLen(100))
+		Consistently(client.Calls).Should(HaveLen(100))
+	})
+
+	It("should only apply the latest update of a flapping route", func() {
+		Expect(ingester.AddRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		Expect(ingester.RemoveRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		Expect(ingester.AddRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		Expect(ingester.RemoveRoute(1, dest("10.0.1.0/24"), hop)).To(Succeed())
+		start()
+
+		Eventually(client.Calls).Should(ConsistOf("add 1 10.0.0.0/24", "remove 1 10.0.1.0/24"))
+		Consistently(client.Calls).Should(HaveLen(2))
+	})
+
+	It("should drop a route added and removed again before being processed", func() {
+		Expect(ingester.AddRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		Expect(ingester.RemoveRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		Expect(ingester.AddRoute(1, dest("10.0.1.0/24"), hop)).To(Succeed())
+		start()
+
+		Eventually(client.Calls).Should(ConsistOf("add 1 10.0.1.0/24"))
+		Consistently(client.Calls).Should(HaveLen(1))
+	})
+
+	It("should retry failed updates", func() {
+		client.failures = 2
+		Expect(ingester.AddRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		start()
+
+		Eventually(client.Calls).Should(Equal([]string{"add 1 10.0.0.0/24", "add 1 10.0.0.0/24", "add 1 10.0.0.0/24"}))
+		Consistently(client.Calls).Should(HaveLen(3))
+	})
+
+	It("should keep routes of different VNIs apart", func() {
+		Expect(ingester.AddRoute(1, dest("10.0.0.0/24"), hop)).To(Succeed())
+		Expect(ingester.RemoveRoute(2, dest("10.0.0.0/24"), hop)).To(Succeed())
+		start()
+
+		Eventually(client.Calls).Should(ConsistOf("add 1 10.0.0.0/24", "remove 2 10.0.0.0/24"))
+	})
+})
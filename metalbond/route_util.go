@@ -5,6 +5,7 @@ package metalbond
 
 import (
 	"context"
+	"fmt"
 	"net/netip"
 	"sync"
 
@@ -22,6 +23,7 @@ const (
 type DefaultRouterAddress struct {
 	RouterAddress    netip.Addr
 	PublicVNI        uint32
+	PublicVNIIPv6    uint32
 	SetBySubsciption bool
 	RWMutex          sync.RWMutex
 }
@@ -66,32 +68,38 @@ type NextHop struct {
 	TargetHopType    pb.NextHopType
 	TargetNATMinPort uint16
 	TargetNATMaxPort uint16
+	TargetClusterID  uint16
 }
 
-func (c *MBRouteUtil) AnnounceRoute(_ context.Context, vni VNI, destination Destination, nextHop NextHop) error {
-	return c.metalbond.AnnounceRoute(vni, metalbond.Destination{
-		IPVersion: netIPAddrIPVersion(destination.Prefix.Addr()),
-		Prefix:    destination.Prefix,
-	}, metalbond.NextHop{
+func toMetalbondNextHop(nextHop NextHop) metalbond.NextHop {
+	hop := metalbond.NextHop{
 		TargetAddress:    nextHop.TargetAddress,
 		TargetVNI:        uint32(nextHop.TargetVNI),
 		Type:             nextHop.TargetHopType,
 		NATPortRangeFrom: nextHop.TargetNATMinPort,
 		NATPortRangeTo:   nextHop.TargetNATMaxPort,
-	})
+	}
+	if nextHop.TargetHopType == pb.NextHopType_STANDARD && nextHop.TargetClusterID != 0 {
+		hop.NATPortRangeFrom = encodeClusterID(nextHop.TargetClusterID)
+	}
+	return hop
+}
+
+func (c *MBRouteUtil) AnnounceRoute(_ context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	if !IsUnderlayAddress(nextHop.TargetAddress) {
+		return fmt.Errorf("next hop %s of %s in VNI %d is no IPv6 underlay address", nextHop.TargetAddress, destination.Prefix, vni)
+	}
+	return c.metalbond.AnnounceRoute(vni, metalbond.Destination{
+		IPVersion: netIPAddrIPVersion(destination.Prefix.Addr()),
+		Prefix:    destination.Prefix,
+	}, toMetalbondNextHop(nextHop))
 }
 
 func (c *MBRouteUtil) WithdrawRoute(_ context.Context, vni VNI, destination Destination, nextHop NextHop) error {
 	return c.metalbond.WithdrawRoute(vni, metalbond.Destination{
 		IPVersion: netIPAddrIPVersion(destination.Prefix.Addr()),
 		Prefix:    destination.Prefix,
-	}, metalbond.NextHop{
-		TargetAddress:    nextHop.TargetAddress,
-		TargetVNI:        uint32(nextHop.TargetVNI),
-		Type:             nextHop.TargetHopType,
-		NATPortRangeFrom: nextHop.TargetNATMinPort,
-		NATPortRangeTo:   nextHop.TargetNATMaxPort,
-	})
+	}, toMetalbondNextHop(nextHop))
 }
 
 func (c *MBRouteUtil) Subscribe(_ context.Context, vni VNI) error {
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var subscriptionHolders = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "metalnet_metalbond_subscription_holders",
+	Help: "Number of local objects a subscribed VNI is held by, e.g. the Networks with local objects in the VNI or peering it.",
+}, []string{"vni"})
+
+func init() {
+	metrics.Registry.MustRegister(subscriptionHolders)
+}
+
+// Subscriptions reference counts the metalbond subscriptions of the VNIs. Every holder, e.g. a Network
+// with local objects, acquires the VNIs it needs routes of. A VNI is subscribed to while it is held by at
+// least one holder and unsubscribed from once its last holder released it, so VNIs needed by several
+// Networks, e.g. a VNI peered by two Networks, are not unsubscribed from while still in use.
+type Subscriptions struct {
+	routeUtil RouteUtil
+
+	mu      sync.Mutex
+	holders map[VNI]map[string]struct{}
+}
+
+func NewSubscriptions(routeUtil RouteUtil) *Subscriptions {
+	return &Subscriptions{
+		routeUtil: routeUtil,
+		holders:   make(map[VNI]map[string]struct{}),
+	}
+}
+
+// Acquire subscribes to the given VNI for the given holder, unless it is already subscribed to.
+func (s *Subscriptions) Acquire(ctx context.Context, holder string, vni VNI) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	holders := s.holders[vni]
+	if _, ok := holders[holder]; ok {
+		return nil
+	}
+	if len(holders) == 0 {
+		if err := s.routeUtil.Subscribe(ctx, vni); IgnoreAlreadySubscribedToVNIError(err) != nil {
+			return fmt.Errorf("error subscribing to vni %d: %w", vni, err)
+		}
+		holders = make(map[string]struct{})
+		s.holders[vni] = holders
+	}
+	holders[holder] = struct{}{}
+	subscriptionHolders.WithLabelValues(strconv.FormatUint(uint64(vni), 10)).Set(float64(len(holders)))
+	return nil
+}
+
+// Release releases the given VNI for the given holder and unsubscribes from it if it was the last holder.
+// If unsubscribing fails, the holder keeps the VNI, so releasing it can be retried.
+func (s *Subscriptions) Release(ctx context.Context, holder string, vni VNI) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.release(ctx, holder, vni)
+}
+
+// ReleaseAll releases all VNIs held by the given holder.
+func (s *Subscriptions) ReleaseAll(ctx context.Context, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for vni, holders := range s.holders {
+		if _, ok := holders[holder]; !ok {
+			continue
+		}
+		if err := s.release(ctx, holder, vni); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Subscriptions) release(ctx context.Context, holder string, vni VNI) error {
+	holders := s.holders[vni]
+	if _, ok := holders[holder]; !ok {
+		return nil
+	}
+	label := strconv.FormatUint(uint64(vni), 10)
+	if len(holders) > 1 {
+		delete(holders, holder)
+		subscriptionHolders.WithLabelValues(label).Set(float64(len(holders)))
+		return nil
+	}
+
+	if err := s.routeUtil.Unsubscribe(ctx, vni); IgnoreAlreadyUnsubscribedToVNIError(err) != nil {
+		return fmt.Errorf("error unsubscribing from vni %d: %w", vni, err)
+	}
+	delete(s.holders, vni)
+	subscriptionHolders.DeleteLabelValues(label)
+	return nil
+}
+
+// Holders returns the sorted holders of the subscribed VNIs.
+func (s *Subscriptions) Holders() map[VNI][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make(map[VNI][]string, len(s.holders))
+	for vni, holders := range s.holders {
+		names := make([]string, 0, len(holders))
+		for holder := range holders {
+			names = append(names, holder)
+		}
+		slices.Sort(names)
+		res[vni] = names
+	}
+	return res
+}
+
+// Handler serves the holders of the subscribed VNIs as JSON.
+func (s *Subscriptions) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(s.Holders())
+	})
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// subscribingRouteUtil records the subscribed and unsubscribed VNIs. Calling any other method panics.
+type subscribingRouteUtil struct {
+	metalbond.RouteUtil
+	subscribed     []metalbond.VNI
+	unsubscribed   []metalbond.VNI
+	unsubscribeErr error
+}
+
+func (u *subscribingRouteUtil) Subscribe(_ context.Context, vni metalbond.VNI) error {
+	u.subscribed = append(u.subscribed, vni)
+	return nil
+}
+
+func (u *subscribingRouteUtil) Unsubscribe(_ context.Context, vni metalbond.VNI) error {
+	if u.unsubscribeErr != nil {
+		return u.unsubscribeErr
+	}
+	u.unsubscribed = append(u.unsubscribed, vni)
+	return nil
+}
+
+var _ = Describe("Subscriptions", func() {
+	var (
+		ctx       context.Context
+		routeUtil *subscribingRouteUtil
+		s         *metalbond.Subscriptions
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		routeUtil = &subscribingRouteUtil{}
+		s = metalbond.NewSubscriptions(routeUtil)
+	})
+
+	It("should stay subscribed to a VNI until its last holder released it", func() {
+		Expect(s.Acquire(ctx, "net-a", 100)).To(Succeed())
+		Expect(s.Acquire(ctx, "net-a", 100)).To(Succeed())
+		Expect(s.Acquire(ctx, "net-b", 100)).To(Succeed())
+		Expect(routeUtil.subscribed).To(Equal([]metalbond.VNI{100}))
+		Expect(s.Holders()).To(Equal(map[metalbond.VNI][]string{100: {"net-a", "net-b"}}))
+
+		Expect(s.Release(ctx, "net-a", 100)).To(Succeed())
+		Expect(routeUtil.unsubscribed).To(BeEmpty())
+
+		Expect(s.Release(ctx, "net-b", 100)).To(Succeed())
+		Expect(s.Release(ctx, "net-b", 100)).To(Succeed())
+		Expect(routeUtil.unsubscribed).To(Equal([]metalbond.VNI{100}))
+		Expect(s.Holders()).To(BeEmpty())
+	})
+
+	It("should release all VNIs of a holder", func() {
+		Expect(s.Acquire(ctx, "net-a", 100)).To(Succeed())
+		Expect(s.Acquire(ctx, "net-a", 200)).To(Succeed())
+		Expect(s.Acquire(ctx, "net-b", 200)).To(Succeed())
+
+		Expect(s.ReleaseAll(ctx, "net-a")).To(Succeed())
+		Expect(routeUtil.unsubscribed).To(Equal([]metalbond.VNI{100}))
+		Expect(s.Holders()).To(Equal(map[metalbond.VNI][]string{200: {"net-b"}}))
+	})
+
+	It("should keep the holder if unsubscribing fails", func() {
+		Expect(s.Acquire(ctx, "net-a", 100)).To(Succeed())
+
+		routeUtil.unsubscribeErr = errors.New("not connected")
+		Expect(s.Release(ctx, "net-a", 100)).To(MatchError("error unsubscribing from vni 100: not connected"))
+		Expect(s.Holders()).To(Equal(map[metalbond.VNI][]string{100: {"net-a"}}))
+
+		routeUtil.unsubscribeErr = errors.New("Already unsubscribed from VNI 100")
+		Expect(s.Release(ctx, "net-a", 100)).To(Succeed())
+		Expect(s.Holders()).To(BeEmpty())
+	})
+
+	It("should serve the holders of the subscribed VNIs", func() {
+		Expect(s.Acquire(ctx, "public VNI", 100)).To(Succeed())
+
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/metalbond-subscriptions", nil))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var holders map[string][]string
+		Expect(json.Unmarshal(rec.Body.Bytes(), &holders)).To(Succeed())
+		Expect(holders).To(Equal(map[string][]string{"100": {"public VNI"}}))
+	})
+})
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ironcore-dev/metalnet/metalbond")
+
+// TracingRouteUtil is a RouteUtil recording a span for every route announcement, withdrawal and
+// subscription change, so they can be traced back to the reconcile causing them.
+type TracingRouteUtil struct {
+	RouteUtil
+}
+
+func NewTracingRouteUtil(routeUtil RouteUtil) *TracingRouteUtil {
+	return &TracingRouteUtil{routeUtil}
+}
+
+func (u *TracingRouteUtil) AnnounceRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	ctx, span := startRouteSpan(ctx, "metalbond.AnnounceRoute", vni, destination, nextHop)
+	defer span.End()
+	return endSpan(span, u.RouteUtil.AnnounceRoute(ctx, vni, destination, nextHop))
+}
+
+func (u *TracingRouteUtil) WithdrawRoute(ctx context.Context, vni VNI, destination Destination, nextHop NextHop) error {
+	ctx, span := startRouteSpan(ctx, "metalbond.WithdrawRoute", vni, destination, nextHop)
+	defer span.End()
+	return endSpan(span, u.RouteUtil.WithdrawRoute(ctx, vni, destination, nextHop))
+}
+
+func (u *TracingRouteUtil) Subscribe(ctx context.Context, vni VNI) error {
+	ctx, span := tracer.Start(ctx, "metalbond.Subscribe", trace.WithAttributes(attribute.Int64("metalbond.vni", int64(vni))))
+	defer span.End()
+	return endSpan(span, u.RouteUtil.Subscribe(ctx, vni))
+}
+
+func (u *TracingRouteUtil) Unsubscribe(ctx context.Context, vni VNI) error {
+	ctx, span := tracer.Start(ctx, "metalbond.Unsubscribe", trace.WithAttributes(attribute.Int64("metalbond.vni", int64(vni))))
+	defer span.End()
+	return endSpan(span, u.RouteUtil.Unsubscribe(ctx, vni))
+}
+
+func startRouteSpan(ctx context.Context, name string, vni VNI, destination Destination, nextHop NextHop) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.Int64("metalbond.vni", int64(vni)),
+		attribute.String("metalbond.destination", destination.Prefix.String()),
+		attribute.String("metalbond.next_hop.address", nextHop.TargetAddress.String()),
+		attribute.String("metalbond.next_hop.type", nextHop.TargetHopType.String()),
+	))
+}
+
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
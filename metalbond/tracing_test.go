@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// The tracers of the package delegate to the first global tracer provider only.
+var spanRecorder = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+}
+
+var _ = Describe("TracingRouteUtil", func() {
+	It("should record the announced route as child of the calling span", func() {
+		routeUtil := &fakeRouteUtil{}
+		u := metalbond.NewTracingRouteUtil(routeUtil)
+
+		ctx, parent := otel.Tracer("test").Start(context.TODO(), "parent")
+		prefix := netip.MustParsePrefix("10.0.0.1/32")
+		Expect(u.AnnounceRoute(ctx, 100, metalbond.Destination{Prefix: prefix}, metalbond.NextHop{
+			TargetAddress: netip.MustParseAddr("fd00::1"),
+		})).To(Succeed())
+		parent.End()
+		Expect(routeUtil.announced).To(Equal([]netip.Prefix{prefix}))
+
+		spans := spanRecorder.Ended()
+		Expect(len(spans)).To(BeNumerically(">=", 2))
+		span := spans[len(spans)-2]
+		Expect(span.Name()).To(Equal("metalbond.AnnounceRoute"))
+		Expect(span.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(span.Attributes()).To(ContainElements(
+			attribute.Int64("metalbond.vni", 100),
+			attribute.String("metalbond.destination", "10.0.0.1/32"),
+			attribute.String("metalbond.next_hop.address", "fd00::1"),
+		))
+	})
+})
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxVNI is the largest VNI, VNIs are 24 bit.
+const MaxVNI VNI = 1<<24 - 1
+
+// VNIRange is an inclusive range of VNIs. The zero VNIRange contains all VNIs.
+type VNIRange struct {
+	Min VNI
+	Max VNI
+}
+
+// ParseVNIRange parses a VNI range given as <min>-<max>, e.g. 1000-9999. An empty string is the zero VNIRange.
+func ParseVNIRange(s string) (VNIRange, error) {
+	if s == "" {
+		return VNIRange{}, nil
+	}
+	minStr, maxStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return VNIRange{}, fmt.Errorf("invalid VNI range %q: expected <min>-<max>", s)
+	}
+	minVNI, err := parseVNI(minStr)
+	if err != nil {
+		return VNIRange{}, fmt.Errorf("invalid VNI range %q: %w", s, err)
+	}
+	maxVNI, err := parseVNI(maxStr)
+	if err != nil {
+		return VNIRange{}, fmt.Errorf("invalid VNI range %q: %w", s, err)
+	}
+	if minVNI > maxVNI {
+		return VNIRange{}, fmt.Errorf("invalid VNI range %q: %d is larger than %d", s, minVNI, maxVNI)
+	}
+	return VNIRange{Min: minVNI, Max: maxVNI}, nil
+}
+
+func parseVNI(s string) (VNI, error) {
+	vni, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid VNI %q", s)
+	}
+	if vni < 1 || VNI(vni) > MaxVNI {
+		return 0, fmt.Errorf("VNI %d is not between 1 and %d", vni, MaxVNI)
+	}
+	return VNI(vni), nil
+}
+
+// IsZero reports whether r is the zero VNIRange.
+func (r VNIRange) IsZero() bool {
+	return r == VNIRange{}
+}
+
+// Contains reports whether the given VNI is within the range.
+func (r VNIRange) Contains(vni VNI) bool {
+	return r.IsZero() || (r.Min <= vni && vni <= r.Max)
+}
+
+func (r VNIRange) String() string {
+	if r.IsZero() {
+		return fmt.Sprintf("1-%d", MaxVNI)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// VNIRangeRouteUtil is a RouteUtil refusing to subscribe to VNIs outside of the VNI range of the deployment,
+// so a typo in a Network ID does not pull the routes of a reserved fabric VNI into a Network.
+type VNIRangeRouteUtil struct {
+	RouteUtil
+
+	vniRange VNIRange
+	reserved map[VNI]struct{}
+}
+
+// NewVNIRangeRouteUtil restricts subscriptions to the given VNI range. The given reserved VNIs of the
+// deployment itself, e.g. the public VNI, may be subscribed to as well.
+func NewVNIRangeRouteUtil(routeUtil RouteUtil, vniRange VNIRange, reserved ...VNI) *VNIRangeRouteUtil {
+	reservedSet := make(map[VNI]struct{}, len(reserved))
+	for _, vni := range reserved {
+		reservedSet[vni] = struct{}{}
+	}
+	return &VNIRangeRouteUtil{
+		RouteUtil: routeUtil,
+		vniRange:  vniRange,
+		reserved:  reservedSet,
+	}
+}
+
+func (u *VNIRangeRouteUtil) Subscribe(ctx context.Context, vni VNI) error {
+	if _, ok := u.reserved[vni]; !ok && !u.vniRange.Contains(vni) {
+		return &VNIOutOfRangeError{VNI: vni, Range: u.vniRange}
+	}
+	return u.RouteUtil.Subscribe(ctx, vni)
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package metalbond_test
+
+import (
+	"context"
+
+	"github.com/ironcore-dev/metalnet/metalbond"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VNIRange", func() {
+	It("should parse VNI ranges", func() {
+		r, err := metalbond.ParseVNIRange("1000-1999")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(metalbond.VNIRange{Min: 1000, Max: 1999}))
+		Expect(r.Contains(999)).To(BeFalse())
+		Expect(r.Contains(1000)).To(BeTrue())
+		Expect(r.Contains(1999)).To(BeTrue())
+		Expect(r.Contains(2000)).To(BeFalse())
+
+		r, err = metalbond.ParseVNIRange("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Contains(metalbond.MaxVNI)).To(BeTrue())
+
+		for _, s := range []string{"1000", "2000-1000", "0-10", "1-16777216", "a-b"} {
+			_, err := metalbond.ParseVNIRange(s)
+			Expect(err).To(HaveOccurred(), s)
+		}
+	})
+
+	It("should refuse to subscribe to VNIs outside of the range", func() {
+		routeUtil := &fakeRouteUtil{}
+		u := metalbond.NewVNIRangeRouteUtil(routeUtil, metalbond.VNIRange{Min: 1000, Max: 1999}, 100)
+
+		Expect(u.Subscribe(context.TODO(), 1000)).To(Succeed())
+		Expect(u.Subscribe(context.TODO(), 100)).To(Succeed())
+		err := u.Subscribe(context.TODO(), 101)
+		Expect(metalbond.IsVNIOutOfRangeError(err)).To(BeTrue())
+		Expect(err).To(MatchError("VNI 101 is outside of the VNI range 1000-1999"))
+		Expect(routeUtil.subscribed).To(Equal([]metalbond.VNI{1000, 100}))
+	})
+})
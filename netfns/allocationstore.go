@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/jaypipes/ghw"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AllocationPool is the pool of the Allocations of the devices of NetworkInterfaces.
+const AllocationPool = "netfns"
+
+// allocationRequestTimeout is the timeout of a request of the allocation claim store.
+const allocationRequestTimeout = 30 * time.Second
+
+//+kubebuilder:rbac:groups=networking.metalnet.ironcore.dev,resources=allocations,verbs=get;list;watch;create;delete;deletecollection
+
+type allocationClaimStore struct {
+	client     client.Client
+	nodeName   string
+	pool       string
+	isTAPStore bool
+}
+
+// NewAllocationClaimStore creates a ClaimStore persisting the claims as Allocation objects.
+//
+// Unlike the claims of the file claim store, the claims survive the loss of the node-local state. The name
+// of an Allocation is derived from the node, pool and address, so the apiserver rejects a second claim of
+// an address. The client should not be backed by a cache, as the claims are listed before the manager starts.
+func NewAllocationClaimStore(c client.Client, nodeName, pool string, isTAPStore bool) ClaimStore {
+	return &allocationClaimStore{
+		client:     c,
+		nodeName:   nodeName,
+		pool:       pool,
+		isTAPStore: isTAPStore,
+	}
+}
+
+// allocationName returns the name of the Allocation of the given value.
+func allocationName(nodeName, pool, value string) string {
+	sum := sha256.Sum256([]byte(nodeName + "/" + value))
+	return pool + "-" + hex.EncodeToString(sum[:16])
+}
+
+func (s *allocationClaimStore) labels() client.MatchingLabels {
+	return client.MatchingLabels{
+		metalnetv1alpha1.AllocationNodeNameLabel: s.nodeName,
+		metalnetv1alpha1.AllocationPoolLabel:     s.pool,
+	}
+}
+
+func (s *allocationClaimStore) Create(uid types.UID, addr ghw.PCIAddress) error {
+	ctx, cancel := context.WithTimeout(context.Background(), allocationRequestTimeout)
+	defer cancel()
+
+	if _, err := s.get(ctx, uid); err == nil {
+		return ErrClaimAlreadyExists
+	} else if !errors.Is(err, ErrClaimNotFound) {
+		return err
+	}
+
+	value := formatClaimAddress(addr, s.isTAPStore)
+	allocation := &metalnetv1alpha1.Allocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: allocationName(s.nodeName, s.pool, value),
+			Labels: map[string]string{
+				metalnetv1alpha1.AllocationNodeNameLabel: s.nodeName,
+				metalnetv1alpha1.AllocationPoolLabel:     s.pool,
+				metalnetv1alpha1.AllocationOwnerUIDLabel: string(uid),
+			},
+		},
+		Spec: metalnetv1alpha1.AllocationSpec{
+			NodeName: s.nodeName,
+			Pool:     s.pool,
+			OwnerUID: uid,
+			Value:    value,
+		},
+	}
+	if err := s.client.Create(ctx, allocation); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error creating allocation: %w", err)
+		}
+
+		existing := &metalnetv1alpha1.Allocation{}
+		if err := s.client.Get(ctx, client.ObjectKeyFromObject(allocation), existing); err != nil {
+			return fmt.Errorf("error getting existing allocation: %w", err)
+		}
+		if existing.Spec.OwnerUID == uid {
+			return ErrClaimAlreadyExists
+		}
+		return fmt.Errorf("address %s is already allocated to %s", value, existing.Spec.OwnerUID)
+	}
+	return nil
+}
+
+func (s *allocationClaimStore) get(ctx context.Context, uid types.UID) (*metalnetv1alpha1.Allocation, error) {
+	list := &metalnetv1alpha1.AllocationList{}
+	if err := s.client.List(ctx, list, s.labels(), client.MatchingLabels{metalnetv1alpha1.AllocationOwnerUIDLabel: string(uid)}); err != nil {
+		return nil, fmt.Errorf("error listing allocations: %w", err)
+	}
+
+	switch len(list.Items) {
+	case 0:
+		return nil, ErrClaimNotFound
+	case 1:
+		return &list.Items[0], nil
+	default:
+		return nil, fmt.Errorf("found %d allocations of %s", len(list.Items), uid)
+	}
+}
+
+func (s *allocationClaimStore) Get(uid types.UID) (*ghw.PCIAddress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), allocationRequestTimeout)
+	defer cancel()
+
+	allocation, err := s.get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return parseClaimAddress(allocation.Spec.Value, s.isTAPStore)
+}
+
+func (s *allocationClaimStore) Delete(uid types.UID) (*ghw.PCIAddress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), allocationRequestTimeout)
+	defer cancel()
+
+	allocation, err := s.get(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := parseClaimAddress(allocation.Spec.Value, s.isTAPStore)
+	if err != nil {
+		return nil, err
+	}
+
+	allocationUID := allocation.UID
+	if err := s.client.Delete(ctx, allocation, client.Preconditions{UID: &allocationUID}); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("error deleting allocation: %w", err)
+	}
+	return addr, nil
+}
+
+func (s *allocationClaimStore) DeleteAll() error {
+	ctx, cancel := context.WithTimeout(context.Background(), allocationRequestTimeout)
+	defer cancel()
+
+	if err := s.client.DeleteAllOf(ctx, &metalnetv1alpha1.Allocation{}, s.labels()); err != nil {
+		return fmt.Errorf("error deleting allocations: %w", err)
+	}
+	return nil
+}
+
+func (s *allocationClaimStore) List() ([]Claim, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), allocationRequestTimeout)
+	defer cancel()
+
+	list := &metalnetv1alpha1.AllocationList{}
+	if err := s.client.List(ctx, list, s.labels()); err != nil {
+		return nil, fmt.Errorf("error listing allocations: %w", err)
+	}
+
+	claims := make([]Claim, 0, len(list.Items))
+	for _, allocation := range list.Items {
+		addr, err := parseClaimAddress(allocation.Spec.Value, s.isTAPStore)
+		if err != nil {
+			return nil, fmt.Errorf("[allocation %s] error parsing address: %w", allocation.Name, err)
+		}
+
+		claims = append(claims, Claim{UID: allocation.Spec.OwnerUID, Address: *addr})
+	}
+	return claims, nil
+}
+
+// MigrateClaims copies the claims of one store into another one, e.g. when switching from the file claim
+// store to the allocation claim store. Claims already present in the destination are kept.
+func MigrateClaims(src, dst ClaimStore) error {
+	claims, err := src.List()
+	if err != nil {
+		return fmt.Errorf("error listing claims: %w", err)
+	}
+
+	for _, claim := range claims {
+		if err := dst.Create(claim.UID, claim.Address); err != nil && !errors.Is(err, ErrClaimAlreadyExists) {
+			return fmt.Errorf("[claim %s] error migrating claim: %w", claim.UID, err)
+		}
+	}
+	return nil
+}
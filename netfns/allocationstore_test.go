@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns_test
+
+import (
+	"os"
+	"path/filepath"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/jaypipes/ghw"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ClaimStore", func() {
+	addr := *ghw.PCIAddressFromString("0000:3b:00.2")
+	otherAddr := *ghw.PCIAddressFromString("0000:3b:00.3")
+
+	newAllocationStore := func(nodeName string) netfns.ClaimStore {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		return netfns.NewAllocationClaimStore(fake.NewClientBuilder().WithScheme(s).Build(), nodeName, netfns.AllocationPool, false)
+	}
+
+	It("should persist claims as allocations and reject claiming an address twice", func() {
+		store := newAllocationStore("node")
+
+		Expect(store.Create("foo", addr)).To(Succeed())
+		Expect(store.Create("foo", otherAddr)).To(MatchError(netfns.ErrClaimAlreadyExists))
+		Expect(store.Create("bar", addr)).To(MatchError(ContainSubstring("already allocated to foo")))
+		Expect(store.Get("foo")).To(Equal(&addr))
+		_, err := store.Get("bar")
+		Expect(err).To(MatchError(netfns.ErrClaimNotFound))
+
+		Expect(store.Create("bar", otherAddr)).To(Succeed())
+		Expect(store.List()).To(ConsistOf(
+			netfns.Claim{UID: "foo", Address: addr},
+			netfns.Claim{UID: "bar", Address: otherAddr},
+		))
+
+		Expect(store.Delete("foo")).To(Equal(&addr))
+		Expect(store.Create("baz", addr)).To(Succeed())
+
+		Expect(store.DeleteAll()).To(Succeed())
+		Expect(store.List()).To(BeEmpty())
+	})
+
+	It("should move claims from the file store to the allocation store", func() {
+		fileStore, err := netfns.NewFileClaimStore(GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fileStore.Create("foo", addr)).To(Succeed())
+		Expect(fileStore.Create("bar", otherAddr)).To(Succeed())
+
+		store := newAllocationStore("node")
+		Expect(store.Create("foo", addr)).To(Succeed())
+		Expect(netfns.MigrateClaims(fileStore, store)).To(Succeed())
+		Expect(store.List()).To(ConsistOf(
+			netfns.Claim{UID: "foo", Address: addr},
+			netfns.Claim{UID: "bar", Address: otherAddr},
+		))
+	})
+
+	It("should ignore claim files interrupted while being written", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, ".claim-123"), []byte("0000:3b"), 0666)).To(Succeed())
+
+		store, err := netfns.NewFileClaimStore(dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.List()).To(BeEmpty())
+		Expect(filepath.Join(dir, ".claim-123")).NotTo(BeAnExistingFile())
+
+		Expect(store.Create("foo", addr)).To(Succeed())
+		Expect(store.Create("foo", otherAddr)).To(MatchError(netfns.ErrClaimAlreadyExists))
+		Expect(store.Get("foo")).To(Equal(&addr))
+		Expect(os.ReadDir(dir)).To(HaveLen(1))
+	})
+
+	It("should not start with an address claimed twice", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "foo"), []byte(addr.String()), 0666)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "bar"), []byte(addr.String()), 0666)).To(Succeed())
+
+		store, err := netfns.NewFileClaimStore(dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = netfns.NewManager(store, []ghw.PCIAddress{addr, otherAddr})
+		Expect(err).To(MatchError(ContainSubstring("both claim address 0000:3b:00.2")))
+	})
+})
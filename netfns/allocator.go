@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ironcore-dev/metalnet/sysfs"
+	"github.com/jaypipes/ghw"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ErrDeviceNotReady is returned if a device cannot be programmed yet, e.g. because no driver is bound to it.
+var ErrDeviceNotReady = errors.New("device not ready")
+
+// Device is a network function a NetworkInterface is attached to.
+type Device struct {
+	// Name is the name dpservice knows the device by.
+	Name string
+	// PCIAddress is the address the device is exposed at to the host. Devices without a PCI address
+	// (e.g. TAP devices) only set the Device field to their name.
+	PCIAddress ghw.PCIAddress
+	// VFIndex is the index of the device among the virtual functions of its physical function,
+	// or nil if the device is no virtual function or sysfs does not know it.
+	VFIndex *int
+	// Driver is the name of the driver bound to the device, if known.
+	Driver string
+	// NUMANode is the NUMA node of the device, or nil if it is unknown or the device is not associated
+	// with a NUMA node.
+	NUMANode *int
+}
+
+// DeviceAllocator hands out the devices of a node to NetworkInterfaces.
+type DeviceAllocator interface {
+	// GetOrClaim returns the device claimed by the given uid, claiming a free one if there is none.
+	GetOrClaim(uid types.UID) (*Device, error)
+	// Get returns the device claimed by the given uid or ErrClaimNotFound.
+	Get(uid types.UID) (*Device, error)
+	// Release frees the device claimed by the given uid or returns ErrClaimNotFound.
+	Release(uid types.UID) error
+	// Ready returns an error wrapping ErrDeviceNotReady if the given device cannot be programmed yet.
+	Ready(device *Device) error
+}
+
+type allocator struct {
+	manager  *Manager
+	toDevice func(addr ghw.PCIAddress) (*Device, error)
+	// ready checks the readiness of a device. If nil, devices are always ready.
+	ready func(device *Device) error
+}
+
+func (a *allocator) GetOrClaim(uid types.UID) (*Device, error) {
+	addr, err := a.manager.GetOrClaim(uid)
+	if err != nil {
+		return nil, err
+	}
+	return a.toDevice(*addr)
+}
+
+func (a *allocator) Get(uid types.UID) (*Device, error) {
+	addr, err := a.manager.Get(uid)
+	if err != nil {
+		return nil, err
+	}
+	return a.toDevice(*addr)
+}
+
+func (a *allocator) Release(uid types.UID) error {
+	return a.manager.Release(uid)
+}
+
+func (a *allocator) Ready(device *Device) error {
+	if a.ready == nil {
+		return nil
+	}
+	return a.ready(device)
+}
+
+// NewPCIAllocator hands out the virtual functions managed by the given Manager. The dpservice name
+// of a virtual function is its representor on the physical function. The physical function is looked
+// up in sysfs. If sysfs does not know the virtual function, it is assumed to be located at pfToVfOffset
+// functions after the first function of the same bus.
+// A virtual function is ready once a driver is bound to it. Virtual functions unknown to sysfs are
+// considered ready.
+func NewPCIAllocator(manager *Manager, fs sysfs.FS, pfToVfOffset int) DeviceAllocator {
+	return &allocator{
+		manager: manager,
+		toDevice: func(addr ghw.PCIAddress) (*Device, error) {
+			name, vfIndex, err := representorName(fs, pfToVfOffset, addr)
+			if err != nil {
+				return nil, fmt.Errorf("error getting representor of %s: %w", &addr, err)
+			}
+			device := &Device{Name: name, PCIAddress: addr, VFIndex: &vfIndex}
+			if err := describePCIDevice(fs, device); err != nil {
+				return nil, err
+			}
+			return device, nil
+		},
+		ready: func(device *Device) error {
+			return pciDeviceReady(fs, device.PCIAddress)
+		},
+	}
+}
+
+// NewNetdevAllocator hands out the netdevs managed by the given Manager (see CollectTAPFunctions).
+// The dpservice name of a netdev is its name.
+func NewNetdevAllocator(manager *Manager) DeviceAllocator {
+	return &allocator{
+		manager: manager,
+		toDevice: func(addr ghw.PCIAddress) (*Device, error) {
+			return &Device{Name: addr.Device, PCIAddress: addr}, nil
+		},
+	}
+}
+
+// NewStaticAllocator hands out explicitly configured devices. The given Manager has to manage the
+// names of the devices (see CollectTAPFunctions). Devices with a PCI address are ready once a driver
+// is bound to them.
+func NewStaticAllocator(manager *Manager, fs sysfs.FS, devices []Device) DeviceAllocator {
+	byName := make(map[string]Device, len(devices))
+	for _, device := range devices {
+		byName[device.Name] = device
+	}
+	return &allocator{
+		manager: manager,
+		toDevice: func(addr ghw.PCIAddress) (*Device, error) {
+			device, ok := byName[addr.Device]
+			if !ok {
+				return nil, fmt.Errorf("device %s is not configured", addr.Device)
+			}
+			if device.PCIAddress.Domain != "" {
+				if err := describePCIDevice(fs, &device); err != nil {
+					return nil, err
+				}
+			}
+			return &device, nil
+		},
+		ready: func(device *Device) error {
+			if device.PCIAddress.Domain == "" {
+				return nil
+			}
+			return pciDeviceReady(fs, device.PCIAddress)
+		},
+	}
+}
+
+func pciDeviceReady(fs sysfs.FS, addr ghw.PCIAddress) error {
+	pciDev, err := fs.PCIDevice(addr)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error getting sysfs pci device %s: %w", &addr, err)
+	}
+
+	if _, err := pciDev.Driver(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: no driver is bound to %s", ErrDeviceNotReady, &addr)
+		}
+		return fmt.Errorf("error getting driver of %s: %w", &addr, err)
+	}
+	return nil
+}
+
+// describePCIDevice sets the virtual function index, the driver and the NUMA node of the given device as far as
+// sysfs knows them.
+func describePCIDevice(fs sysfs.FS, device *Device) error {
+	pciDev, err := fs.PCIDevice(device.PCIAddress)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error getting sysfs pci device %s: %w", &device.PCIAddress, err)
+	}
+
+	if device.VFIndex == nil {
+		vfIndex, err := virtfnIndex(pciDev, device.PCIAddress)
+		if err != nil {
+			return fmt.Errorf("error getting virtual function index of %s: %w", &device.PCIAddress, err)
+		}
+		device.VFIndex = vfIndex
+	}
+
+	driver, err := pciDev.Driver()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error getting driver of %s: %w", &device.PCIAddress, err)
+	}
+	device.Driver = driver
+
+	numaNode, err := pciDev.NUMANode()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error getting numa node of %s: %w", &device.PCIAddress, err)
+	}
+	if err == nil && numaNode >= 0 {
+		device.NUMANode = &numaNode
+	}
+	return nil
+}
+
+// virtfnIndex returns the index of the given device among the virtual functions of its physical function, or nil
+// if it is no virtual function.
+func virtfnIndex(pciDev sysfs.PCIDevice, addr ghw.PCIAddress) (*int, error) {
+	physFn, err := pciDev.Physfn()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting sysfs physfn: %w", err)
+	}
+
+	virtfns, err := physFn.Virtfns()
+	if err != nil {
+		return nil, fmt.Errorf("error getting sysfs virtfns: %w", err)
+	}
+	for _, virtfn := range virtfns {
+		virtfnAddr, err := virtfn.Address()
+		if err != nil {
+			return nil, fmt.Errorf("error getting virtfn details: %w", err)
+		}
+		if virtfnAddr.String() != addr.String() {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(string(virtfn)), "virtfn"))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing virtfn index: %w", err)
+		}
+		return &index, nil
+	}
+	return nil, nil
+}
+
+// representorName returns the dpservice name and the index of the given virtual function.
+func representorName(fs sysfs.FS, pfToVfOffset int, addr ghw.PCIAddress) (string, int, error) {
+	pciFunction, err := strconv.ParseUint(addr.Function, 8, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("error parsing address function %s: %w", addr.Function, err)
+	}
+
+	pciDevice, err := strconv.ParseUint(addr.Device, 16, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("error parsing address device %s: %w", addr.Device, err)
+	}
+	pciFunction = pciDevice*8 + pciFunction
+
+	pciDev, err := fs.PCIDevice(addr)
+	if err != nil {
+		// Calculate based on the offset parameter if sysfs not available
+		vfIndex := int(pciFunction) - pfToVfOffset
+		return fmt.Sprintf("%s:%s:00.0_representor_vf%d", addr.Domain, addr.Bus, vfIndex), vfIndex, nil
+	}
+
+	physFn, err := pciDev.Physfn()
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting sysfs physfn: %w", err)
+	}
+
+	physFnAddr, err := physFn.Address()
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting physfn details: %w", err)
+	}
+
+	sriov, err := physFn.SRIOV()
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting sysfs sriov: %w", err)
+	}
+	vfIndex := int(pciFunction) - int(sriov.Offset)
+	return fmt.Sprintf("%s:%s:%s.0_representor_vf%d", physFnAddr.Domain, physFnAddr.Bus, physFnAddr.Device, vfIndex), vfIndex, nil
+}
@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/sysfs"
+	"github.com/jaypipes/ghw"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("DeviceAllocator", func() {
+	var (
+		claimsDir string
+		sysFS     sysfs.FS
+	)
+
+	BeforeEach(func() {
+		claimsDir = GinkgoT().TempDir()
+		var err error
+		sysFS, err = sysfs.NewFS(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	newManager := func(names ...string) *netfns.Manager {
+		store, err := netfns.NewFileClaimStore(claimsDir, true)
+		Expect(err).NotTo(HaveOccurred())
+		initAvailable, err := netfns.CollectTAPFunctions(names)
+		Expect(err).NotTo(HaveOccurred())
+		manager, err := netfns.NewManager(store, initAvailable)
+		Expect(err).NotTo(HaveOccurred())
+		return manager
+	}
+
+	It("should hand out netdevs by name and keep claims across restarts", func() {
+		allocator := netfns.NewNetdevAllocator(newManager("net_tap3"))
+
+		device, err := allocator.GetOrClaim("foo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device.Name).To(Equal("net_tap3"))
+
+		_, err = allocator.GetOrClaim("bar")
+		Expect(err).To(MatchError(netfns.ErrNoAddressAvailable))
+
+		allocator = netfns.NewNetdevAllocator(newManager("net_tap3"))
+		Expect(allocator.Get("foo")).To(HaveField("Name", "net_tap3"))
+		Expect(allocator.Release("foo")).To(Succeed())
+		_, err = allocator.Get("foo")
+		Expect(err).To(MatchError(netfns.ErrClaimNotFound))
+	})
+
+	It("should read netdev claims stored in the pci address format", func() {
+		Expect(os.WriteFile(filepath.Join(claimsDir, "foo"), []byte("::net_tap3."), 0666)).To(Succeed())
+
+		allocator := netfns.NewNetdevAllocator(newManager("net_tap3", "net_tap4"))
+		Expect(allocator.Get("foo")).To(HaveField("Name", "net_tap3"))
+		Expect(allocator.GetOrClaim("bar")).To(HaveField("Name", "net_tap4"))
+	})
+
+	It("should hand out the configured devices", func() {
+		devices, err := netfns.ParseDeviceConfigs([]byte(`
+- name: 0000:3b:00.0_representor_vf0
+  pciAddress: 0000:3b:00.2
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		allocator := netfns.NewStaticAllocator(newManager(netfns.DeviceNames(devices)...), sysFS, devices)
+		device, err := allocator.GetOrClaim("foo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device).To(Equal(&netfns.Device{
+			Name:       "0000:3b:00.0_representor_vf0",
+			PCIAddress: *ghw.PCIAddressFromString("0000:3b:00.2"),
+		}))
+		Expect(allocator.Ready(device)).To(Succeed())
+	})
+
+	It("should report virtual functions ready once a driver is bound", func() {
+		allocator := netfns.NewPCIAllocator(newManager(), sysFS, 2)
+		device := &netfns.Device{
+			Name:       "0000:3b:00.0_representor_vf0",
+			PCIAddress: *ghw.PCIAddressFromString("0000:3b:00.2"),
+		}
+
+		By("checking a virtual function unknown to sysfs")
+		Expect(allocator.Ready(device)).To(Succeed())
+
+		By("checking a virtual function without driver")
+		Expect(os.MkdirAll(sysFS.PCIDevicePath("0000:3b:00.2"), 0777)).To(Succeed())
+		Expect(allocator.Ready(device)).To(MatchError(netfns.ErrDeviceNotReady))
+
+		By("binding a driver to the virtual function")
+		Expect(os.MkdirAll(sysFS.Path("bus", "pci", "drivers", "mlx5_core"), 0777)).To(Succeed())
+		Expect(os.Symlink(sysFS.Path("bus", "pci", "drivers", "mlx5_core"), sysFS.PCIDevicePath("0000:3b:00.2", "driver"))).To(Succeed())
+		Expect(allocator.Ready(device)).To(Succeed())
+
+		Expect(netfns.NewNetdevAllocator(newManager("net_tap3")).Ready(&netfns.Device{Name: "net_tap3"})).To(Succeed())
+	})
+
+	It("should describe virtual functions known to sysfs", func() {
+		Expect(os.MkdirAll(sysFS.PCIDevicePath("0000:3b:00.0"), 0777)).To(Succeed())
+		for name, value := range map[string]string{"sriov_numvfs": "2", "sriov_totalvfs": "8", "sriov_offset": "2", "sriov_stride": "1"} {
+			Expect(os.WriteFile(sysFS.PCIDevicePath("0000:3b:00.0", name), []byte(value+"\n"), 0666)).To(Succeed())
+		}
+		Expect(os.MkdirAll(sysFS.PCIDevicePath("0000:3b:00.3"), 0777)).To(Succeed())
+		Expect(os.Symlink(sysFS.PCIDevicePath("0000:3b:00.0"), sysFS.PCIDevicePath("0000:3b:00.3", "physfn"))).To(Succeed())
+		Expect(os.Symlink(sysFS.PCIDevicePath("0000:3b:00.3"), sysFS.PCIDevicePath("0000:3b:00.0", "virtfn1"))).To(Succeed())
+		Expect(os.WriteFile(sysFS.PCIDevicePath("0000:3b:00.3", "numa_node"), []byte("1\n"), 0666)).To(Succeed())
+		Expect(os.MkdirAll(sysFS.Path("bus", "pci", "drivers", "vfio-pci"), 0777)).To(Succeed())
+		Expect(os.Symlink(sysFS.Path("bus", "pci", "drivers", "vfio-pci"), sysFS.PCIDevicePath("0000:3b:00.3", "driver"))).To(Succeed())
+
+		store, err := netfns.NewFileClaimStore(claimsDir, false)
+		Expect(err).NotTo(HaveOccurred())
+		manager, err := netfns.NewManager(store, []ghw.PCIAddress{*ghw.PCIAddressFromString("0000:3b:00.3")})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("claiming the virtual function from the pci allocator")
+		allocator := netfns.NewPCIAllocator(manager, sysFS, 2)
+		device, err := allocator.GetOrClaim("foo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device).To(Equal(&netfns.Device{
+			Name:       "0000:3b:00.0_representor_vf1",
+			PCIAddress: *ghw.PCIAddressFromString("0000:3b:00.3"),
+			VFIndex:    ptr.To(1),
+			Driver:     "vfio-pci",
+			NUMANode:   ptr.To(1),
+		}))
+		Expect(allocator.Release("foo")).To(Succeed())
+
+		By("getting the virtual function from the static allocator")
+		devices, err := netfns.ParseDeviceConfigs([]byte(`
+- name: 0000:3b:00.0_representor_vf1
+  pciAddress: 0000:3b:00.3
+`))
+		Expect(err).NotTo(HaveOccurred())
+		device, err = netfns.NewStaticAllocator(newManager(netfns.DeviceNames(devices)...), sysFS, devices).GetOrClaim("bar")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device).To(HaveField("VFIndex", ptr.To(1)))
+	})
+
+	It("should reject invalid device configs", func() {
+		_, err := netfns.ParseDeviceConfigs([]byte(`
+- name: foo
+- name: foo
+`))
+		Expect(err).To(HaveOccurred())
+
+		_, err = netfns.ParseDeviceConfigs([]byte(`
+- name: foo
+  pciAddress: bar
+`))
+		Expect(err).To(HaveOccurred())
+	})
+})
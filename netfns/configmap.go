@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaypipes/ghw"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// DeviceConfig is an explicitly configured device of a node.
+type DeviceConfig struct {
+	// Name is the name dpservice knows the device by.
+	Name string `json:"name"`
+	// PCIAddress is the address the device is exposed at to the host, if any.
+	PCIAddress string `json:"pciAddress,omitempty"`
+}
+
+// LoadConfigMapDevices reads the devices of the given node from a ConfigMap. The ConfigMap has one key per
+// node, holding the list of DeviceConfigs of the node as YAML.
+func LoadConfigMapDevices(ctx context.Context, c client.Reader, key client.ObjectKey, nodeName string) ([]Device, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, configMap); err != nil {
+		return nil, fmt.Errorf("error getting config map %s: %w", key, err)
+	}
+
+	data, ok := configMap.Data[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("config map %s has no devices for node %s", key, nodeName)
+	}
+	return ParseDeviceConfigs([]byte(data))
+}
+
+// ParseDeviceConfigs parses a YAML list of DeviceConfigs.
+func ParseDeviceConfigs(data []byte) ([]Device, error) {
+	var configs []DeviceConfig
+	if err := yaml.UnmarshalStrict(data, &configs); err != nil {
+		return nil, fmt.Errorf("error parsing device configs: %w", err)
+	}
+
+	devices := make([]Device, 0, len(configs))
+	names := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		if config.Name == "" {
+			return nil, fmt.Errorf("device name must not be empty")
+		}
+		if _, ok := names[config.Name]; ok {
+			return nil, fmt.Errorf("duplicate device %s", config.Name)
+		}
+		names[config.Name] = struct{}{}
+
+		device := Device{Name: config.Name, PCIAddress: ghw.PCIAddress{Device: config.Name}}
+		if config.PCIAddress != "" {
+			addr := ghw.PCIAddressFromString(config.PCIAddress)
+			if addr == nil {
+				return nil, fmt.Errorf("device %s has invalid pci address %q", config.Name, config.PCIAddress)
+			}
+			device.PCIAddress = *addr
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// DeviceNames returns the names of the given devices.
+func DeviceNames(devices []Device) []string {
+	names := make([]string, len(devices))
+	for i, device := range devices {
+		names[i] = device.Name
+	}
+	return names
+}
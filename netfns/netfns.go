@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/ironcore-dev/metalnet/sysfs"
 	"github.com/jaypipes/ghw"
@@ -44,10 +46,24 @@ type fileClaimStore struct {
 	isTAPStore bool
 }
 
+// claimTempFilePrefix is the prefix of the files claims are written to before being linked into place.
+const claimTempFilePrefix = ".claim-"
+
 func NewFileClaimStore(rootDir string, isTAPStore bool) (ClaimStore, error) {
 	if err := os.MkdirAll(rootDir, perm); err != nil {
 		return nil, fmt.Errorf("error creating directory at %s: %w", rootDir, err)
 	}
+
+	// Remove the leftovers of claims interrupted while being written.
+	tempFiles, err := filepath.Glob(filepath.Join(rootDir, claimTempFilePrefix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("error looking up temporary claim files: %w", err)
+	}
+	for _, tempFile := range tempFiles {
+		if err := os.Remove(tempFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error removing temporary claim file %s: %w", tempFile, err)
+		}
+	}
 	return &fileClaimStore{rootDir, isTAPStore}, nil
 }
 
@@ -55,18 +71,38 @@ func (s *fileClaimStore) claimFile(uid types.UID) string {
 	return filepath.Join(s.rootDir, string(uid))
 }
 
+// Create writes the claim to a temporary file first and links it into place afterwards, so a claim file
+// is either complete or missing, even if metalnet crashes while writing it, and an existing claim is
+// never overwritten.
 func (s *fileClaimStore) Create(uid types.UID, addr ghw.PCIAddress) error {
-	filename := s.claimFile(uid)
-	_, err := os.Stat(filename)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("error stat-ing %s: %w", filename, err)
+	tempFile, err := os.CreateTemp(s.rootDir, claimTempFilePrefix+"*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary claim file: %w", err)
 	}
-	if err == nil {
-		return ErrClaimAlreadyExists
+	defer func() { _ = os.Remove(tempFile.Name()) }()
+
+	if _, err := tempFile.WriteString(formatClaimAddress(addr, s.isTAPStore)); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("error writing temporary claim file: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("error syncing temporary claim file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("error closing temporary claim file: %w", err)
+	}
+	if err := os.Chmod(tempFile.Name(), filePerm); err != nil {
+		return fmt.Errorf("error setting permissions of temporary claim file: %w", err)
 	}
 
-	data := []byte(addr.String())
-	return os.WriteFile(filename, data, filePerm)
+	if err := os.Link(tempFile.Name(), s.claimFile(uid)); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrClaimAlreadyExists
+		}
+		return fmt.Errorf("error linking claim file: %w", err)
+	}
+	return nil
 }
 
 func (s *fileClaimStore) Get(uid types.UID) (*ghw.PCIAddress, error) {
@@ -77,17 +113,30 @@ func (s *fileClaimStore) Get(uid types.UID) (*ghw.PCIAddress, error) {
 		}
 		return nil, ErrClaimNotFound
 	}
+	return parseClaimAddress(string(data), s.isTAPStore)
+}
 
+// formatClaimAddress formats the address of a claim. TAP devices are stored by their name.
+func formatClaimAddress(addr ghw.PCIAddress, isTAPStore bool) string {
+	if isTAPStore {
+		return addr.Device
+	}
+	return addr.String()
+}
+
+// parseClaimAddress parses an address formatted by formatClaimAddress.
+func parseClaimAddress(data string, isTAPStore bool) (*ghw.PCIAddress, error) {
 	var addr *ghw.PCIAddress
-	if !s.isTAPStore {
-		addr = ghw.PCIAddressFromString(string(data))
+	if !isTAPStore {
+		addr = ghw.PCIAddressFromString(data)
 	} else {
+		// Older claims stored the name in the PCI address format (::<name>.).
 		addr = &ghw.PCIAddress{
-			Device: string(data),
+			Device: strings.TrimSuffix(strings.TrimPrefix(data, "::"), "."),
 		}
 	}
 	if addr == nil {
-		return nil, fmt.Errorf("invalid pci address %q", string(data))
+		return nil, fmt.Errorf("invalid pci address %q", data)
 	}
 
 	return addr, nil
@@ -128,6 +177,10 @@ func (s *fileClaimStore) List() ([]Claim, error) {
 
 	var claims []Claim
 	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), claimTempFilePrefix) {
+			continue
+		}
+
 		uid := types.UID(entry.Name())
 		addr, err := s.Get(uid)
 		if err != nil {
@@ -143,7 +196,9 @@ var (
 	ErrNoAddressAvailable = errors.New("no address available")
 )
 
+// Manager hands out the addresses of a ClaimStore. It is safe for concurrent use.
 type Manager struct {
+	mu        sync.Mutex
 	store     ClaimStore
 	available sets.Set[ghw.PCIAddress]
 }
@@ -155,12 +210,17 @@ func NewManager(store ClaimStore, initAvailable []ghw.PCIAddress) (*Manager, err
 	}
 
 	available := sets.New(initAvailable...)
+	claimedBy := make(map[ghw.PCIAddress]types.UID, len(claims))
 	for _, claim := range claims {
+		if other, ok := claimedBy[claim.Address]; ok {
+			return nil, fmt.Errorf("claims %s and %s both claim address %s", other, claim.UID, &claim.Address)
+		}
 		if !available.Has(claim.Address) {
 			return nil, fmt.Errorf("claim %s cannot claim non-existent address %s", claim.UID, &claim.Address)
 		}
 
 		available.Delete(claim.Address)
+		claimedBy[claim.Address] = claim.UID
 	}
 
 	return &Manager{
@@ -170,6 +230,9 @@ func NewManager(store ClaimStore, initAvailable []ghw.PCIAddress) (*Manager, err
 }
 
 func (m *Manager) GetOrClaim(uid types.UID) (*ghw.PCIAddress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	addr, err := m.store.Get(uid)
 	if err != nil && !errors.Is(err, ErrClaimNotFound) {
 		return nil, fmt.Errorf("error getting claim: %w", err)
@@ -194,6 +257,9 @@ func (m *Manager) Get(uid types.UID) (*ghw.PCIAddress, error) {
 }
 
 func (m *Manager) Release(uid types.UID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	addr, err := m.store.Delete(uid)
 	if err != nil {
 		return err
@@ -204,6 +270,9 @@ func (m *Manager) Release(uid types.UID) error {
 }
 
 func (m *Manager) ReleaseAll() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if err := m.store.DeleteAll(); err != nil {
 		return err
 	}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ironcore-dev/metalnet/sysfs"
+	"github.com/jaypipes/ghw"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// DefaultPodResourcesSocket is the socket the kubelet serves the pod resources API on.
+const DefaultPodResourcesSocket = "/var/lib/kubelet/pod-resources/kubelet.sock"
+
+// DeviceClaim identifies a device a device plugin allocated to a pod.
+type DeviceClaim struct {
+	Namespace string
+	Pod       string
+	// Container is the container of the pod the device is allocated to. If empty, the devices of all
+	// containers are considered.
+	Container string
+	// Resource is the extended resource of the device plugin.
+	Resource string
+	// Index selects one of several devices of the resource, ordered by their PCI address.
+	Index int
+}
+
+// PodResourcesResolver resolves device claims to the devices device plugins allocated to pods, as reported by
+// the pod resources API of the kubelet. The kubelet owns these devices, so they are not part of the device pool
+// of a DeviceAllocator and are neither claimed nor released. The device IDs of the resources have to be the PCI
+// addresses of virtual functions, see NewPCIAllocator.
+type PodResourcesResolver struct {
+	client       podresourcesv1.PodResourcesListerClient
+	fs           sysfs.FS
+	pfToVfOffset int
+}
+
+func NewPodResourcesResolver(client podresourcesv1.PodResourcesListerClient, fs sysfs.FS, pfToVfOffset int) *PodResourcesResolver {
+	return &PodResourcesResolver{
+		client:       client,
+		fs:           fs,
+		pfToVfOffset: pfToVfOffset,
+	}
+}
+
+// DialPodResources connects to the pod resources API of the kubelet at the given socket.
+func DialPodResources(socket string) (podresourcesv1.PodResourcesListerClient, error) {
+	conn, err := grpc.Dial("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to pod resources api at %s: %w", socket, err)
+	}
+	return podresourcesv1.NewPodResourcesListerClient(conn), nil
+}
+
+// Resolve returns the device of the given claim. It returns an error wrapping ErrDeviceNotReady if the pod
+// is not admitted yet or the device is not allocated to it.
+func (r *PodResourcesResolver) Resolve(ctx context.Context, claim DeviceClaim) (*Device, error) {
+	res, err := r.client.List(ctx, &podresourcesv1.ListPodResourcesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pod resources: %w", err)
+	}
+
+	var addrs []string
+	found := false
+	for _, pod := range res.GetPodResources() {
+		if pod.GetNamespace() != claim.Namespace || pod.GetName() != claim.Pod {
+			continue
+		}
+		found = true
+		for _, container := range pod.GetContainers() {
+			if claim.Container != "" && container.GetName() != claim.Container {
+				continue
+			}
+			for _, devices := range container.GetDevices() {
+				if devices.GetResourceName() == claim.Resource {
+					addrs = append(addrs, devices.GetDeviceIds()...)
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: pod %s/%s is not admitted on the node", ErrDeviceNotReady, claim.Namespace, claim.Pod)
+	}
+	slices.Sort(addrs)
+	addrs = slices.Compact(addrs)
+	if claim.Index >= len(addrs) {
+		return nil, fmt.Errorf("%w: %d devices of %s are allocated to pod %s/%s, device %d is claimed",
+			ErrDeviceNotReady, len(addrs), claim.Resource, claim.Namespace, claim.Pod, claim.Index)
+	}
+
+	addr := ghw.PCIAddressFromString(addrs[claim.Index])
+	if addr == nil {
+		return nil, fmt.Errorf("device %q of %s is no pci address", addrs[claim.Index], claim.Resource)
+	}
+	name, vfIndex, err := representorName(r.fs, r.pfToVfOffset, *addr)
+	if err != nil {
+		return nil, fmt.Errorf("error getting representor of %s: %w", addr, err)
+	}
+	device := &Device{Name: name, PCIAddress: *addr, VFIndex: &vfIndex}
+	if err := describePCIDevice(r.fs, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// Ready returns an error wrapping ErrDeviceNotReady if no driver is bound to the given device yet.
+func (r *PodResourcesResolver) Ready(device *Device) error {
+	return pciDeviceReady(r.fs, device.PCIAddress)
+}
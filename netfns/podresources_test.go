@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package netfns_test
+
+import (
+	"context"
+
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/sysfs"
+	"github.com/jaypipes/ghw"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// fakePodResources lists the given pod resources. Calling any other method panics.
+type fakePodResources struct {
+	podresourcesv1.PodResourcesListerClient
+	pods []*podresourcesv1.PodResources
+}
+
+func (f *fakePodResources) List(context.Context, *podresourcesv1.ListPodResourcesRequest, ...grpc.CallOption) (*podresourcesv1.ListPodResourcesResponse, error) {
+	return &podresourcesv1.ListPodResourcesResponse{PodResources: f.pods}, nil
+}
+
+var _ = Describe("PodResourcesResolver", func() {
+	var resolver *netfns.PodResourcesResolver
+
+	BeforeEach(func() {
+		sysFS, err := sysfs.NewFS(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		resolver = netfns.NewPodResourcesResolver(&fakePodResources{pods: []*podresourcesv1.PodResources{{
+			Namespace: "default",
+			Name:      "vm-1",
+			Containers: []*podresourcesv1.ContainerResources{
+				{
+					Name: "vm",
+					Devices: []*podresourcesv1.ContainerDevices{
+						{ResourceName: "example.com/sriov-vf", DeviceIds: []string{"0000:3b:00.6", "0000:3b:00.5"}},
+						{ResourceName: "example.com/gpu", DeviceIds: []string{"gpu-0"}},
+					},
+				},
+				{Name: "sidecar"},
+			},
+		}}}, sysFS, 3)
+	})
+
+	It("should resolve the devices allocated to a pod in the order of their addresses", func(ctx SpecContext) {
+		device, err := resolver.Resolve(ctx, netfns.DeviceClaim{Namespace: "default", Pod: "vm-1", Resource: "example.com/sriov-vf"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device.Name).To(Equal("0000:3b:00.0_representor_vf2"))
+		Expect(device.PCIAddress).To(Equal(*ghw.PCIAddressFromString("0000:3b:00.5")))
+
+		device, err = resolver.Resolve(ctx, netfns.DeviceClaim{Namespace: "default", Pod: "vm-1", Container: "vm", Resource: "example.com/sriov-vf", Index: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device.Name).To(Equal("0000:3b:00.0_representor_vf3"))
+	})
+
+	It("should report devices not allocated yet as not ready", func(ctx SpecContext) {
+		_, err := resolver.Resolve(ctx, netfns.DeviceClaim{Namespace: "default", Pod: "vm-2", Resource: "example.com/sriov-vf"})
+		Expect(err).To(MatchError(netfns.ErrDeviceNotReady))
+
+		_, err = resolver.Resolve(ctx, netfns.DeviceClaim{Namespace: "default", Pod: "vm-1", Container: "sidecar", Resource: "example.com/sriov-vf"})
+		Expect(err).To(MatchError(netfns.ErrDeviceNotReady))
+
+		_, err = resolver.Resolve(ctx, netfns.DeviceClaim{Namespace: "default", Pod: "vm-1", Resource: "example.com/sriov-vf", Index: 2})
+		Expect(err).To(MatchError(netfns.ErrDeviceNotReady))
+	})
+
+	It("should reject devices without pci address", func(ctx SpecContext) {
+		_, err := resolver.Resolve(ctx, netfns.DeviceClaim{Namespace: "default", Pod: "vm-1", Resource: "example.com/gpu"})
+		Expect(err).To(MatchError(`device "gpu-0" of example.com/gpu is no pci address`))
+	})
+})
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package standalone
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// LoadDir reads the metalnet objects of all .yaml, .yml and .json files in the given directory.
+//
+// A file may contain several YAML documents of v1alpha1 objects. Namespaced objects without a namespace
+// are put into the default namespace.
+func LoadDir(scheme *runtime.Scheme, dir string) ([]client.Object, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spec directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var objs []client.Object
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("error reading spec file %s: %w", name, err)
+		}
+		fileObjs, err := Decode(scheme, data)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding spec file %s: %w", name, err)
+		}
+		objs = append(objs, fileObjs...)
+	}
+	return objs, nil
+}
+
+// Decode decodes the metalnet objects of the given YAML or JSON documents, see LoadDir.
+func Decode(scheme *runtime.Scheme, data []byte) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+
+	var objs []client.Object
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 || strings.TrimSpace(string(doc)) == "---" {
+			continue
+		}
+
+		decoded, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		obj, err := toObject(scheme, decoded)
+		if err != nil {
+			return nil, err
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("%T without name", obj)
+		}
+		if _, ok := obj.(*metalnetv1alpha1.LoadBalancerIPPool); !ok && obj.GetNamespace() == "" {
+			obj.SetNamespace(corev1.NamespaceDefault)
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func toObject(scheme *runtime.Scheme, obj runtime.Object) (client.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if gvk.GroupVersion() != metalnetv1alpha1.GroupVersion {
+		return nil, fmt.Errorf("unsupported kind %s", gvk)
+	}
+	res, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an object", gvk)
+	}
+	return res, nil
+}
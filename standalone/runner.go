@@ -0,0 +1,529 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package standalone runs the metalnet reconcilers against specs read from a directory instead of the
+// Kubernetes API, for deployments without an apiserver.
+package standalone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+type Options struct {
+	// Dir is the directory the specs are read from, see LoadDir.
+	Dir string
+	// StatusDir is the directory the reconciled objects including their status are written to.
+	// Nothing is written if empty.
+	StatusDir string
+	// PollInterval is the interval the spec directory is checked for changes at. Defaults to 5 seconds.
+	PollInterval time.Duration
+	// ResyncPeriod is the period all objects are reconciled at, even if their specs did not change.
+	// Defaults to 1 minute.
+	ResyncPeriod time.Duration
+	// MetricsBindAddress is the address the metrics are served at. Not served if empty.
+	MetricsBindAddress string
+	// MetricsExtraHandlers are served next to the metrics, by path.
+	MetricsExtraHandlers map[string]http.Handler
+	// HealthProbeBindAddress is the address the health and readiness probes are served at. Not served if empty.
+	HealthProbeBindAddress string
+}
+
+type registration struct {
+	gvk        schema.GroupVersionKind
+	reconciler reconcile.Reconciler
+}
+
+type request struct {
+	registration int
+	key          client.ObjectKey
+}
+
+// Runner keeps the objects read from the spec directory in an in-memory client and drives the registered
+// reconcilers with them, like a controller manager does with the objects of the Kubernetes API.
+//
+// The objects of the spec directory replace the objects of the in-memory client whenever the directory changes:
+// new objects are created, changed specs are updated and missing objects are deleted, which lets the reconcilers
+// run their finalizers. All objects are reconciled after every change and every resync period, so reconcilers
+// relying on watches of other kinds see the changes of these as well.
+type Runner struct {
+	scheme *runtime.Scheme
+	client client.WithWatch
+	opts   Options
+	log    logr.Logger
+
+	registrations []registration
+	runnables     []manager.Runnable
+	healthChecks  map[string]healthz.Checker
+	readyChecks   map[string]healthz.Checker
+	queue         workqueue.RateLimitingInterface
+}
+
+func NewRunner(scheme *runtime.Scheme, opts Options) (*Runner, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.ResyncPeriod <= 0 {
+		opts.ResyncPeriod = time.Minute
+	}
+
+	b := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(
+		&metalnetv1alpha1.Network{},
+		&metalnetv1alpha1.ClusterNetwork{},
+		&metalnetv1alpha1.NetworkInterface{},
+		&metalnetv1alpha1.LoadBalancer{},
+		&metalnetv1alpha1.LoadBalancerIPPool{},
+		&metalnetv1alpha1.NetworkIPPool{},
+		&metalnetv1alpha1.InternetGateway{},
+		&metalnetv1alpha1.ServiceChain{},
+	)
+	indexer := &builderIndexer{b}
+	for _, setup := range []func(context.Context, client.FieldIndexer) error{
+		metalnetclient.SetupNetworkInterfaceNetworkRefNameFieldIndexer,
+		metalnetclient.SetupNetworkInterfaceClusterNetworkRefNameFieldIndexer,
+		metalnetclient.SetupNetworkInterfaceInternetGatewayRefNameFieldIndexer,
+		metalnetclient.SetupLoadBalancerNetworkRefNameFieldIndexer,
+	} {
+		if err := setup(context.TODO(), indexer); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Runner{
+		scheme:       scheme,
+		client:       b.Build(),
+		opts:         opts,
+		log:          ctrl.Log.WithName("standalone"),
+		healthChecks: make(map[string]healthz.Checker),
+		readyChecks:  make(map[string]healthz.Checker),
+		queue:        workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{Name: "standalone"}),
+	}, nil
+}
+
+// builderIndexer registers field indexes with a fake client builder.
+type builderIndexer struct {
+	b *fake.ClientBuilder
+}
+
+func (i *builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.b.WithIndex(obj, field, extractValue)
+	return nil
+}
+
+// GetClient returns the in-memory client holding the objects of the spec directory.
+func (r *Runner) GetClient() client.Client {
+	return r.client
+}
+
+// GetEventRecorderFor returns a recorder logging the events.
+func (r *Runner) GetEventRecorderFor(name string) record.EventRecorder {
+	return &eventLogger{log: r.log.WithName("events").WithValues("Source", name)}
+}
+
+// Register reconciles the objects of the kind of the given object with the given reconciler.
+func (r *Runner) Register(obj client.Object, reconciler reconcile.Reconciler) error {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	r.registrations = append(r.registrations, registration{gvk: gvk, reconciler: reconciler})
+	return nil
+}
+
+// Add runs the given runnable while the runner is running.
+func (r *Runner) Add(runnable manager.Runnable) error {
+	r.runnables = append(r.runnables, runnable)
+	return nil
+}
+
+func (r *Runner) AddHealthzCheck(name string, check healthz.Checker) error {
+	r.healthChecks[name] = check
+	return nil
+}
+
+func (r *Runner) AddReadyzCheck(name string, check healthz.Checker) error {
+	r.readyChecks[name] = check
+	return nil
+}
+
+// Start applies the specs of the spec directory and reconciles the objects until the context is done.
+func (r *Runner) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var servers []*http.Server
+	if r.opts.MetricsBindAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+		for path, handler := range r.opts.MetricsExtraHandlers {
+			mux.Handle(path, handler)
+		}
+		servers = append(servers, &http.Server{Addr: r.opts.MetricsBindAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second})
+	}
+	if r.opts.HealthProbeBindAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/healthz", http.StripPrefix("/healthz", &healthz.Handler{Checks: r.healthChecks}))
+		mux.Handle("/healthz/", http.StripPrefix("/healthz", &healthz.Handler{Checks: r.healthChecks}))
+		mux.Handle("/readyz", http.StripPrefix("/readyz", &healthz.Handler{Checks: r.readyChecks}))
+		mux.Handle("/readyz/", http.StripPrefix("/readyz", &healthz.Handler{Checks: r.readyChecks}))
+		servers = append(servers, &http.Server{Addr: r.opts.HealthProbeBindAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second})
+	}
+	for _, srv := range servers {
+		srv := srv
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				r.log.Error(err, "Error serving", "Address", srv.Addr)
+			}
+		}()
+		defer func() {
+			if err := srv.Shutdown(context.Background()); err != nil {
+				r.log.Error(err, "Error shutting down server", "Address", srv.Addr)
+			}
+		}()
+	}
+
+	if err := r.Apply(ctx); err != nil {
+		return err
+	}
+
+	for _, runnable := range r.runnables {
+		runnable := runnable
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runnable.Start(ctx); err != nil {
+				r.log.Error(err, "Error running runnable")
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for r.processNext(ctx) {
+		}
+	}()
+	defer r.queue.ShutDown()
+
+	poll := time.NewTicker(r.opts.PollInterval)
+	defer poll.Stop()
+	resync := time.NewTicker(r.opts.ResyncPeriod)
+	defer resync.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+			if err := r.Apply(ctx); err != nil {
+				r.log.Error(err, "Error applying specs")
+			}
+		case <-resync.C:
+			if err := r.enqueueAll(ctx); err != nil {
+				r.log.Error(err, "Error resyncing objects")
+			}
+		}
+	}
+}
+
+// Apply replaces the objects of the in-memory client with the objects of the spec directory and
+// enqueues all objects if anything changed.
+func (r *Runner) Apply(ctx context.Context) error {
+	objs, err := LoadDir(r.scheme, r.opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	changed, err := r.sync(ctx, objs)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	return r.enqueueAll(ctx)
+}
+
+type objectKey struct {
+	gvk schema.GroupVersionKind
+	key client.ObjectKey
+}
+
+func (r *Runner) sync(ctx context.Context, objs []client.Object) (bool, error) {
+	var changed bool
+	desired := make(map[objectKey]struct{}, len(objs))
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, r.scheme)
+		if err != nil {
+			return changed, err
+		}
+		key := objectKey{gvk, client.ObjectKeyFromObject(obj)}
+		if _, ok := desired[key]; ok {
+			return changed, fmt.Errorf("duplicate %s %s", gvk.Kind, key.key)
+		}
+		desired[key] = struct{}{}
+
+		objChanged, err := r.apply(ctx, gvk, obj)
+		if err != nil {
+			return changed, fmt.Errorf("error applying %s %s: %w", gvk.Kind, key.key, err)
+		}
+		changed = changed || objChanged
+	}
+
+	for _, gvk := range r.kinds() {
+		existing, err := r.list(ctx, gvk)
+		if err != nil {
+			return changed, err
+		}
+		for _, obj := range existing {
+			key := objectKey{gvk, client.ObjectKeyFromObject(obj)}
+			if _, ok := desired[key]; ok || !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			r.log.Info("Deleting object", "Kind", gvk.Kind, "Key", key.key)
+			if err := r.client.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return changed, fmt.Errorf("error deleting %s %s: %w", gvk.Kind, key.key, err)
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func (r *Runner) apply(ctx context.Context, gvk schema.GroupVersionKind, obj client.Object) (bool, error) {
+	existing, err := r.scheme.New(gvk)
+	if err != nil {
+		return false, err
+	}
+	current := existing.(client.Object)
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+
+		r.log.Info("Creating object", "Kind", gvk.Kind, "Key", client.ObjectKeyFromObject(obj))
+		obj.SetUID(objectUID(gvk, client.ObjectKeyFromObject(obj)))
+		obj.SetCreationTimestamp(metav1.Now())
+		obj.SetGeneration(1)
+		obj.SetResourceVersion("")
+		return true, r.client.Create(ctx, obj)
+	}
+
+	if !current.GetDeletionTimestamp().IsZero() {
+		// The object is recreated once its finalizers ran.
+		return false, nil
+	}
+
+	specChanged, err := specDiffers(current, obj)
+	if err != nil || !specChanged {
+		return false, err
+	}
+
+	r.log.Info("Updating object", "Kind", gvk.Kind, "Key", client.ObjectKeyFromObject(obj))
+	obj.SetUID(current.GetUID())
+	obj.SetCreationTimestamp(current.GetCreationTimestamp())
+	obj.SetGeneration(current.GetGeneration() + 1)
+	obj.SetResourceVersion(current.GetResourceVersion())
+	obj.SetFinalizers(current.GetFinalizers())
+	return true, r.client.Update(ctx, obj)
+}
+
+// objectUID derives the uid of an object from its kind and key, so the objects keep their uids, and thereby
+// their dpservice state, across restarts.
+func objectUID(gvk schema.GroupVersionKind, key client.ObjectKey) types.UID {
+	return types.UID(uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("metalnet-standalone:%s/%s", gvk.Kind, key))).String())
+}
+
+// specDiffers reports whether the objects differ in anything but their status and server-set metadata.
+func specDiffers(current, desired client.Object) (bool, error) {
+	a, err := specOf(current)
+	if err != nil {
+		return false, err
+	}
+	b, err := specOf(desired)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(a, b), nil
+}
+
+func specOf(obj client.Object) (map[string]interface{}, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(u, "apiVersion")
+	delete(u, "kind")
+	delete(u, "status")
+	u["metadata"] = map[string]interface{}{
+		"labels":      obj.GetLabels(),
+		"annotations": obj.GetAnnotations(),
+	}
+	return u, nil
+}
+
+func (r *Runner) kinds() []schema.GroupVersionKind {
+	var kinds []schema.GroupVersionKind
+	seen := make(map[schema.GroupVersionKind]struct{})
+	for _, reg := range r.registrations {
+		if _, ok := seen[reg.gvk]; !ok {
+			seen[reg.gvk] = struct{}{}
+			kinds = append(kinds, reg.gvk)
+		}
+	}
+	return kinds
+}
+
+func (r *Runner) list(ctx context.Context, gvk schema.GroupVersionKind) ([]client.Object, error) {
+	obj, err := r.scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	list := obj.(client.ObjectList)
+	if err := r.client.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("error listing %s: %w", gvk.Kind, err)
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, len(items))
+	for i, item := range items {
+		objs[i] = item.(client.Object)
+	}
+	return objs, nil
+}
+
+func (r *Runner) enqueueAll(ctx context.Context) error {
+	for i, reg := range r.registrations {
+		objs, err := r.list(ctx, reg.gvk)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			r.queue.Add(request{registration: i, key: client.ObjectKeyFromObject(obj)})
+		}
+	}
+	return nil
+}
+
+func (r *Runner) processNext(ctx context.Context) bool {
+	item, shutdown := r.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer r.queue.Done(item)
+
+	req := item.(request)
+	reg := r.registrations[req.registration]
+	log := r.log.WithValues("Kind", reg.gvk.Kind, "Key", req.key)
+	res, err := reg.reconciler.Reconcile(ctrl.LoggerInto(ctx, log), reconcile.Request{NamespacedName: req.key})
+	switch {
+	case errors.Is(err, reconcile.TerminalError(nil)):
+		log.Error(err, "Reconciler error, not retrying")
+		r.queue.Forget(req)
+	case err != nil:
+		log.Error(err, "Reconciler error")
+		r.queue.AddRateLimited(req)
+	case res.RequeueAfter > 0:
+		r.queue.Forget(req)
+		r.queue.AddAfter(req, res.RequeueAfter)
+	case res.Requeue:
+		r.queue.AddRateLimited(req)
+	default:
+		r.queue.Forget(req)
+	}
+
+	if err := r.writeStatus(ctx, reg.gvk, req.key); err != nil {
+		log.Error(err, "Error writing status")
+	}
+	return true
+}
+
+// writeStatus writes the given object including its status to the status directory,
+// or removes its file if the object is gone.
+func (r *Runner) writeStatus(ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey) error {
+	if r.opts.StatusDir == "" {
+		return nil
+	}
+
+	filename := filepath.Join(r.opts.StatusDir, fmt.Sprintf("%s_%s_%s.yaml", gvk.Kind, key.Namespace, key.Name))
+	obj, err := r.scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	if err := r.client.Get(ctx, key, obj.(client.Object)); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.opts.StatusDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// eventLogger is an event recorder logging the events.
+type eventLogger struct {
+	log logr.Logger
+}
+
+func (e *eventLogger) Event(object runtime.Object, eventtype, reason, message string) {
+	var key client.ObjectKey
+	if obj, ok := object.(client.Object); ok {
+		key = client.ObjectKeyFromObject(obj)
+	}
+	e.log.Info(message, "Object", key, "Type", eventtype, "Reason", reason)
+}
+
+func (e *eventLogger) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (e *eventLogger) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.Eventf(object, eventtype, reason, messageFmt, args...)
+}
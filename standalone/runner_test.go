@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package standalone_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/standalone"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const finalizer = "test.metalnet.ironcore.dev/finalizer"
+
+// internetGatewayReconciler adds a finalizer to internet gateways and reports the number of their ips
+// as capacity, and removes the finalizer once they are deleted.
+type internetGatewayReconciler struct {
+	client.Client
+}
+
+func (r *internetGatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	igw := &metalnetv1alpha1.InternetGateway{}
+	if err := r.Get(ctx, req.NamespacedName, igw); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !igw.DeletionTimestamp.IsZero() {
+		controllerutil.RemoveFinalizer(igw, finalizer)
+		return ctrl.Result{}, r.Update(ctx, igw)
+	}
+	if controllerutil.AddFinalizer(igw, finalizer) {
+		return ctrl.Result{Requeue: true}, r.Update(ctx, igw)
+	}
+
+	base := igw.DeepCopy()
+	igw.Status.Capacity = int32(len(igw.Spec.IPs))
+	return ctrl.Result{}, r.Status().Patch(ctx, igw, client.MergeFrom(base))
+}
+
+var _ = Describe("Runner", func() {
+	var (
+		scheme    *runtime.Scheme
+		dir       string
+		statusDir string
+		runner    *standalone.Runner
+	)
+
+	writeSpec := func(name, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+	}
+
+	getInternetGateway := func(name string) func() (*metalnetv1alpha1.InternetGateway, error) {
+		return func() (*metalnetv1alpha1.InternetGateway, error) {
+			igw := &metalnetv1alpha1.InternetGateway{}
+			err := runner.GetClient().Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, igw)
+			return igw, err
+		}
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(scheme)).To(Succeed())
+		dir = GinkgoT().TempDir()
+		statusDir = GinkgoT().TempDir()
+
+		var err error
+		runner, err = standalone.NewRunner(scheme, standalone.Options{
+			Dir:          dir,
+			StatusDir:    statusDir,
+			PollInterval: 10 * time.Millisecond,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runner.Register(&metalnetv1alpha1.InternetGateway{}, &internetGatewayReconciler{runner.GetClient()})).To(Succeed())
+	})
+
+	It("should reconcile the objects of the spec directory", func(ctx SpecContext) {
+		writeSpec("internetgateways.yaml", `
+apiVersion: networking.metalnet.ironcore.dev/v1alpha1
+kind: InternetGateway
+metadata:
+  name: igw-1
+spec:
+  ips:
+  - 10.0.0.1
+---
+apiVersion: networking.metalnet.ironcore.dev/v1alpha1
+kind: InternetGateway
+metadata:
+  name: igw-2
+spec:
+  ips:
+  - 10.0.0.2
+  - 10.0.0.3
+`)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(runner.Start(runCtx)).To(Succeed())
+		}()
+
+		Eventually(getInternetGateway("igw-1")).Should(And(
+			HaveField("Finalizers", ContainElement(finalizer)),
+			HaveField("Status.Capacity", int32(1)),
+		))
+		Eventually(getInternetGateway("igw-2")).Should(HaveField("Status.Capacity", int32(2)))
+		igw, err := getInternetGateway("igw-1")()
+		Expect(err).NotTo(HaveOccurred())
+		uid := igw.UID
+		Expect(uid).NotTo(BeEmpty())
+		Eventually(filepath.Join(statusDir, "InternetGateway_default_igw-1.yaml")).Should(BeAnExistingFile())
+
+		By("changing the spec")
+		writeSpec("internetgateways.yaml", `
+apiVersion: networking.metalnet.ironcore.dev/v1alpha1
+kind: InternetGateway
+metadata:
+  name: igw-1
+spec:
+  ips:
+  - 10.0.0.1
+  - 10.0.0.4
+`)
+		Eventually(getInternetGateway("igw-1")).Should(And(
+			HaveField("UID", uid),
+			HaveField("Generation", int64(2)),
+			HaveField("Status.Capacity", int32(2)),
+		))
+
+		By("deleting the removed objects once their finalizers ran")
+		Eventually(func() error {
+			_, err := getInternetGateway("igw-2")()
+			return err
+		}).Should(MatchError(ContainSubstring("not found")))
+		Eventually(filepath.Join(statusDir, "InternetGateway_default_igw-2.yaml")).ShouldNot(BeAnExistingFile())
+	})
+
+	It("should reject specs of unknown kinds", func() {
+		_, err := standalone.Decode(scheme, []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`))
+		Expect(err).To(HaveOccurred())
+	})
+})
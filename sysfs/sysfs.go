@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/ironcore-dev/metalnet/encoding/sysfs"
 	"github.com/jaypipes/ghw"
@@ -134,3 +136,26 @@ func (p PCIDevice) Address() (*ghw.PCIAddress, error) {
 	}
 	return res, nil
 }
+
+// Driver returns the name of the driver bound to the device or an error satisfying os.ErrNotExist
+// if no driver is bound.
+func (p PCIDevice) Driver() (string, error) {
+	path, err := filepath.EvalSymlinks(filepath.Join(string(p), "driver"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(path), nil
+}
+
+// NUMANode returns the NUMA node of the device or -1 if the device is not associated with a NUMA node.
+func (p PCIDevice) NUMANode() (int, error) {
+	data, err := os.ReadFile(filepath.Join(string(p), "numa_node"))
+	if err != nil {
+		return 0, err
+	}
+	node, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing numa node: %w", err)
+	}
+	return node, nil
+}
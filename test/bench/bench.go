@@ -0,0 +1,556 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bench drives the network interface reconciler and the metalbond route ingestion against the
+// dpservice simulator at scale. It measures the reconcile throughput, the memory allocated while
+// reconciling and the latency of ingesting routes, so regressions show up before reaching a node.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/controllers"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	namespace   = "default"
+	networkName = "bench"
+	networkVNI  = 100
+	publicVNI   = 0x100000
+	nodeName    = "bench-node"
+)
+
+var (
+	interfacePrefix = netip.MustParsePrefix("10.0.0.0/8")
+	virtualIPPrefix = netip.MustParsePrefix("100.64.0.0/10")
+	routePrefix     = netip.MustParsePrefix("172.16.0.0/12")
+	nextHopPrefix   = netip.MustParsePrefix("fc00:1::/64")
+)
+
+type Options struct {
+	// NetworkInterfaces is the number of network interfaces created. Defaults to 1000.
+	NetworkInterfaces int
+	// VirtualIPs is the number of network interfaces that get a virtual ip. Defaults to none.
+	VirtualIPs int
+	// Routes is the number of metalbond routes ingested into the network of the interfaces. Defaults to none.
+	Routes int
+	// Workers is the number of network interfaces reconciled in parallel. Defaults to 1.
+	Workers int
+	// RouteWorkers is the number of route ingestion workers. Defaults to 1.
+	RouteWorkers int
+	// Timeout is the maximum time to wait for the routes to be ingested. Defaults to 5 minutes.
+	Timeout time.Duration
+}
+
+func setOptionsDefaults(o *Options) {
+	if o.NetworkInterfaces <= 0 {
+		o.NetworkInterfaces = 1000
+	}
+	if o.VirtualIPs > o.NetworkInterfaces {
+		o.VirtualIPs = o.NetworkInterfaces
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.RouteWorkers <= 0 {
+		o.RouteWorkers = 1
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Minute
+	}
+}
+
+// Result holds the measurements of a run. Durations are reported in seconds.
+type Result struct {
+	NetworkInterfaces int `json:"networkInterfaces"`
+	VirtualIPs        int `json:"virtualIPs"`
+	Routes            int `json:"routes"`
+	Workers           int `json:"workers"`
+	RouteWorkers      int `json:"routeWorkers"`
+
+	// Reconciles is the number of reconciles needed until no network interface was requeued anymore.
+	Reconciles int `json:"reconciles"`
+	// ReadyNetworkInterfaces is the number of network interfaces in state Ready afterwards.
+	ReadyNetworkInterfaces int     `json:"readyNetworkInterfaces"`
+	ReconcileSeconds       float64 `json:"reconcileSeconds"`
+	// NetworkInterfacesPerSecond is the number of network interfaces programmed per second.
+	NetworkInterfacesPerSecond float64 `json:"networkInterfacesPerSecond"`
+	// AllocatedBytes and Allocations are the memory allocated while reconciling.
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+	Allocations    uint64 `json:"allocations"`
+	// HeapInUseBytes is the heap in use after reconciling, including the state of the simulator.
+	HeapInUseBytes uint64 `json:"heapInUseBytes"`
+
+	// RouteErrors is the number of routes that could not be installed.
+	RouteErrors            int     `json:"routeErrors"`
+	RouteIngestionSeconds  float64 `json:"routeIngestionSeconds"`
+	RoutesPerSecond        float64 `json:"routesPerSecond"`
+	RouteLatencyP50Seconds float64 `json:"routeLatencyP50Seconds"`
+	RouteLatencyP99Seconds float64 `json:"routeLatencyP99Seconds"`
+	RouteLatencyMaxSeconds float64 `json:"routeLatencyMaxSeconds"`
+}
+
+// Run sets up a fresh simulator and in-memory cluster, reconciles the network interfaces and ingests the
+// routes afterwards, as routes are only accepted for networks with interfaces on the node.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	setOptionsDefaults(&opts)
+	ctx = ctrl.LoggerInto(ctx, logr.Discard())
+
+	env, err := newEnvironment(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Close()
+
+	res := &Result{
+		NetworkInterfaces: opts.NetworkInterfaces,
+		VirtualIPs:        opts.VirtualIPs,
+		Routes:            opts.Routes,
+		Workers:           opts.Workers,
+		RouteWorkers:      opts.RouteWorkers,
+	}
+	if err := env.reconcileNetworkInterfaces(ctx, res); err != nil {
+		return nil, err
+	}
+	if opts.Routes > 0 {
+		if err := env.ingestRoutes(ctx, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+type environment struct {
+	opts Options
+
+	client     client.Client
+	dpdk       dpdkclient.Client
+	reconciler *controllers.NetworkInterfaceReconciler
+
+	server    *grpc.Server
+	conn      *grpc.ClientConn
+	claimsDir string
+}
+
+func newEnvironment(ctx context.Context, opts Options) (*environment, error) {
+	env := &environment{opts: opts}
+	ok := false
+	defer func() {
+		if !ok {
+			env.Close()
+		}
+	}()
+
+	lis := bufconn.Listen(1 << 20)
+	env.server = dpservice.NewServer(dpservice.Options{}).Start(lis)
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to simulator: %w", err)
+	}
+	env.conn = conn
+	env.dpdk = dpdkclient.NewClient(dpdkproto.NewDPDKironcoreClient(conn))
+
+	scheme := k8sruntime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := metalnetv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	objs, err := newObjects(opts)
+	if err != nil {
+		return nil, err
+	}
+	b := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&metalnetv1alpha1.Network{}, &metalnetv1alpha1.NetworkInterface{}).
+		WithObjects(objs...)
+	for _, setup := range []func(context.Context, client.FieldIndexer) error{
+		metalnetclient.SetupNetworkInterfaceNetworkRefNameFieldIndexer,
+		metalnetclient.SetupNetworkInterfaceClusterNetworkRefNameFieldIndexer,
+		metalnetclient.SetupNetworkInterfaceInternetGatewayRefNameFieldIndexer,
+		metalnetclient.SetupLoadBalancerNetworkRefNameFieldIndexer,
+	} {
+		if err := setup(ctx, &builderIndexer{b}); err != nil {
+			return nil, err
+		}
+	}
+	env.client = b.Build()
+
+	env.claimsDir, err = os.MkdirTemp("", "metalnet-bench-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating claims directory: %w", err)
+	}
+	claimStore, err := netfns.NewFileClaimStore(env.claimsDir, true)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]string, opts.NetworkInterfaces)
+	for i := range devices {
+		devices[i] = fmt.Sprintf("net_tap%d", i)
+	}
+	initAvailable, err := netfns.CollectTAPFunctions(devices)
+	if err != nil {
+		return nil, err
+	}
+	netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+	if err != nil {
+		return nil, err
+	}
+
+	routeUtil := noopRouteUtil{}
+	env.reconciler = &controllers.NetworkInterfaceReconciler{
+		Client:               env.client,
+		EventRecorder:        &record.FakeRecorder{},
+		Scheme:               scheme,
+		DPDK:                 env.dpdk,
+		RouteUtil:            routeUtil,
+		AliasPrefixAnnouncer: metalbond.NewAliasPrefixAnnouncer(routeUtil),
+		DeviceAllocator:      netfns.NewNetdevAllocator(netFnsManager),
+		NodeName:             nodeName,
+		PublicVNI:            publicVNI,
+	}
+	ok = true
+	return env, nil
+}
+
+func (e *environment) Close() {
+	if e.conn != nil {
+		_ = e.conn.Close()
+	}
+	if e.server != nil {
+		e.server.Stop()
+	}
+	if e.claimsDir != "" {
+		_ = os.RemoveAll(e.claimsDir)
+	}
+}
+
+// builderIndexer registers field indexes with a fake client builder.
+type builderIndexer struct {
+	b *fake.ClientBuilder
+}
+
+func (i *builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.b.WithIndex(obj, field, extractValue)
+	return nil
+}
+
+func newObjects(opts Options) ([]client.Object, error) {
+	objs := []client.Object{
+		&metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: networkName},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: networkVNI},
+		},
+	}
+
+	node := nodeName
+	addr := interfacePrefix.Addr()
+	vip := virtualIPPrefix.Addr()
+	for i := 0; i < opts.NetworkInterfaces; i++ {
+		addr = addr.Next()
+		if !interfacePrefix.Contains(addr) {
+			return nil, fmt.Errorf("too many network interfaces, prefix %s is exhausted", interfacePrefix)
+		}
+
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      fmt.Sprintf("nic-%d", i),
+				UID:       types.UID(fmt.Sprintf("bench-nic-%d", i)),
+			},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: networkName},
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.NewIP(addr)},
+				NodeName:   &node,
+			},
+		}
+		if i < opts.VirtualIPs {
+			vip = vip.Next()
+			if !virtualIPPrefix.Contains(vip) {
+				return nil, fmt.Errorf("too many virtual ips, prefix %s is exhausted", virtualIPPrefix)
+			}
+			nic.Spec.VirtualIP = metalnetv1alpha1.NewIPPtr(vip)
+		}
+		objs = append(objs, nic)
+	}
+	return objs, nil
+}
+
+func (e *environment) reconcileNetworkInterfaces(ctx context.Context, res *Result) error {
+	keys := make(chan client.ObjectKey)
+	errs := make(chan error, e.opts.Workers)
+	counts := make([]int, e.opts.Workers)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for w := 0; w < e.opts.Workers; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				n, err := e.reconcile(ctx, key)
+				counts[w] += n
+				if err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < e.opts.NetworkInterfaces; i++ {
+		select {
+		case keys <- client.ObjectKey{Namespace: namespace, Name: fmt.Sprintf("nic-%d", i)}:
+		case <-ctx.Done():
+		}
+	}
+	close(keys)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	elapsed := time.Since(start)
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	res.ReconcileSeconds = elapsed.Seconds()
+	res.NetworkInterfacesPerSecond = float64(e.opts.NetworkInterfaces) / elapsed.Seconds()
+	res.AllocatedBytes = after.TotalAlloc - before.TotalAlloc
+	res.Allocations = after.Mallocs - before.Mallocs
+	for _, n := range counts {
+		res.Reconciles += n
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	res.HeapInUseBytes = after.HeapInuse
+
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := e.client.List(ctx, nicList, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("error listing network interfaces: %w", err)
+	}
+	for _, nic := range nicList.Items {
+		if nic.Status.State == metalnetv1alpha1.NetworkInterfaceStateReady {
+			res.ReadyNetworkInterfaces++
+		}
+	}
+	return nil
+}
+
+// reconcile reconciles the network interface of the given key until it is not requeued anymore and
+// returns the number of reconciles.
+func (e *environment) reconcile(ctx context.Context, key client.ObjectKey) (int, error) {
+	for n := 1; ; n++ {
+		res, err := e.reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		if err != nil {
+			return n, fmt.Errorf("error reconciling network interface %s: %w", key, err)
+		}
+		if !res.Requeue && res.RequeueAfter == 0 {
+			return n, nil
+		}
+	}
+}
+
+func (e *environment) ingestRoutes(ctx context.Context, res *Result) error {
+	log := logr.Discard()
+	routerAddr := &metalbond.DefaultRouterAddress{PublicVNI: publicVNI}
+	mbClient := metalbond.NewMetalnetClient(&log, e.dpdk, internal.NewMetalnetCache(&log), routerAddr, metalbond.ClientOptions{})
+
+	recorder := newLatencyRecorder(mbClient, e.opts.Routes)
+	ingester := metalbond.NewRouteIngester(&log, recorder, metalbond.RouteIngesterOptions{Workers: e.opts.RouteWorkers})
+
+	ctx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	defer cancel()
+	ingesterCtx, stopIngester := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = ingester.Start(ingesterCtx)
+	}()
+	defer func() {
+		stopIngester()
+		<-done
+	}()
+
+	start := time.Now()
+	prefix := routePrefix.Addr()
+	nextHop := nextHopPrefix.Addr()
+	for i := 0; i < e.opts.Routes; i++ {
+		prefix, nextHop = prefix.Next(), nextHop.Next()
+		if !routePrefix.Contains(prefix) {
+			return fmt.Errorf("too many routes, prefix %s is exhausted", routePrefix)
+		}
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.PrefixFrom(prefix, 32)}
+		recorder.Start(dest.Prefix)
+		if err := ingester.AddRoute(networkVNI, dest, mb.NextHop{TargetVNI: networkVNI, TargetAddress: nextHop, Type: pb.NextHopType_STANDARD}); err != nil {
+			return fmt.Errorf("error adding route %s: %w", dest.Prefix, err)
+		}
+	}
+
+	select {
+	case <-recorder.Done():
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for routes to be ingested: %w", ctx.Err())
+	}
+	elapsed := time.Since(start)
+
+	latencies, errs := recorder.Results()
+	res.RouteErrors = errs
+	res.RouteIngestionSeconds = elapsed.Seconds()
+	res.RoutesPerSecond = float64(e.opts.Routes) / elapsed.Seconds()
+	res.RouteLatencyP50Seconds = quantile(latencies, 0.5).Seconds()
+	res.RouteLatencyP99Seconds = quantile(latencies, 0.99).Seconds()
+	res.RouteLatencyMaxSeconds = quantile(latencies, 1).Seconds()
+	return nil
+}
+
+// latencyRecorder is a metalbond client recording the time from queueing a route until the wrapped
+// client installed it.
+type latencyRecorder struct {
+	client mb.Client
+
+	mu        sync.Mutex
+	started   map[netip.Prefix]time.Time
+	latencies []time.Duration
+	errs      int
+	remaining int
+	done      chan struct{}
+}
+
+func newLatencyRecorder(client mb.Client, routes int) *latencyRecorder {
+	return &latencyRecorder{
+		client:    client,
+		started:   make(map[netip.Prefix]time.Time, routes),
+		latencies: make([]time.Duration, 0, routes),
+		remaining: routes,
+		done:      make(chan struct{}),
+	}
+}
+
+func (r *latencyRecorder) Start(prefix netip.Prefix) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started[prefix] = time.Now()
+}
+
+func (r *latencyRecorder) AddRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	err := r.client.AddRoute(vni, dest, hop)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if start, ok := r.started[dest.Prefix]; ok {
+		delete(r.started, dest.Prefix)
+		r.latencies = append(r.latencies, time.Since(start))
+		if err != nil {
+			r.errs++
+		}
+		r.remaining--
+		if r.remaining == 0 {
+			close(r.done)
+		}
+	}
+	return err
+}
+
+func (r *latencyRecorder) RemoveRoute(vni mb.VNI, dest mb.Destination, hop mb.NextHop) error {
+	return r.client.RemoveRoute(vni, dest, hop)
+}
+
+func (r *latencyRecorder) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *latencyRecorder) Results() ([]time.Duration, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Duration(nil), r.latencies...), r.errs
+}
+
+// quantile returns the q-quantile of the given durations using the nearest-rank method.
+func quantile(durations []time.Duration, q float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	idx := int(q*float64(len(durations))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(durations) {
+		idx = len(durations) - 1
+	}
+	return durations[idx]
+}
+
+// noopRouteUtil discards all announcements, as the benchmark has no metalbond peers.
+type noopRouteUtil struct{}
+
+func (noopRouteUtil) AnnounceRoute(context.Context, metalbond.VNI, metalbond.Destination, metalbond.NextHop) error {
+	return nil
+}
+
+func (noopRouteUtil) WithdrawRoute(context.Context, metalbond.VNI, metalbond.Destination, metalbond.NextHop) error {
+	return nil
+}
+
+func (noopRouteUtil) Subscribe(context.Context, metalbond.VNI) error {
+	return nil
+}
+
+func (noopRouteUtil) Unsubscribe(context.Context, metalbond.VNI) error {
+	return nil
+}
+
+func (noopRouteUtil) IsSubscribed(context.Context, metalbond.VNI) bool {
+	return true
+}
+
+func (noopRouteUtil) GetRoutesForVni(context.Context, metalbond.VNI) error {
+	return nil
+}
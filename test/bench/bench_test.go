@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bench_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ironcore-dev/metalnet/test/bench"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Run", func() {
+	It("should program all network interfaces and ingest all routes", func(ctx SpecContext) {
+		res, err := bench.Run(ctx, bench.Options{
+			NetworkInterfaces: 20,
+			VirtualIPs:        10,
+			Routes:            100,
+			Workers:           4,
+			RouteWorkers:      4,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.ReadyNetworkInterfaces).To(Equal(20))
+		Expect(res.Reconciles).To(BeNumerically(">=", 20))
+		Expect(res.AllocatedBytes).NotTo(BeZero())
+		Expect(res.RouteErrors).To(BeZero())
+		Expect(res.RouteLatencyP99Seconds).To(BeNumerically(">=", res.RouteLatencyP50Seconds))
+		Expect(res.RouteLatencyMaxSeconds).To(BeNumerically(">=", res.RouteLatencyP99Seconds))
+	})
+})
+
+func BenchmarkReconcileNetworkInterfaces(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("interfaces=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				res, err := bench.Run(context.Background(), bench.Options{NetworkInterfaces: n, VirtualIPs: n})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ReportMetric(res.NetworkInterfacesPerSecond, "interfaces/s")
+				b.ReportMetric(float64(res.AllocatedBytes)/float64(n), "B/interface")
+			}
+		})
+	}
+}
+
+func BenchmarkIngestRoutes(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("routes=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				res, err := bench.Run(context.Background(), bench.Options{NetworkInterfaces: 1, Routes: n, RouteWorkers: 4})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ReportMetric(res.RoutesPerSecond, "routes/s")
+				b.ReportMetric(res.RouteLatencyP99Seconds, "p99-s")
+			}
+		})
+	}
+}
@@ -0,0 +1,829 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dpservice provides an in-memory simulator of the dpservice gRPC API.
+// It keeps the same tables and reports the same status codes metalnet relies on,
+// so reconciliation flows can be tested without a DPDK capable host.
+package dpservice
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"sync"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	DefaultServiceVersion = "v0.3.2-sim"
+)
+
+var (
+	DefaultUnderlayPrefix = netip.MustParsePrefix("fc00::/64")
+)
+
+type Options struct {
+	// UnderlayPrefix is the prefix underlay routes are allocated from.
+	UnderlayPrefix netip.Prefix
+	// ServiceVersion is the dpservice version reported by GetVersion.
+	ServiceVersion string
+}
+
+func setOptionsDefaults(o *Options) {
+	if !o.UnderlayPrefix.IsValid() {
+		o.UnderlayPrefix = DefaultUnderlayPrefix
+	}
+	if o.ServiceVersion == "" {
+		o.ServiceVersion = DefaultServiceVersion
+	}
+}
+
+type addrEntry struct {
+	ip       *dpdkproto.IpAddress
+	underlay netip.Addr
+}
+
+type natEntry struct {
+	ip       *dpdkproto.IpAddress
+	minPort  uint32
+	maxPort  uint32
+	underlay netip.Addr
+}
+
+type neighborNatKey struct {
+	ip      string
+	vni     uint32
+	minPort uint32
+	maxPort uint32
+}
+
+type interfaceEntry struct {
+	vni        uint32
+	ipv4       string
+	ipv6       string
+	device     string
+	underlay   netip.Addr
+	metering   *dpdkproto.MeteringParams
+	vip        *addrEntry
+	nat        *natEntry
+	prefixes   map[netip.Prefix]netip.Addr
+	lbPrefixes map[netip.Prefix]netip.Addr
+	fwRules    map[string]*dpdkproto.FirewallRule
+}
+
+type loadBalancerEntry struct {
+	ip       *dpdkproto.IpAddress
+	vni      uint32
+	ports    []*dpdkproto.LbPort
+	underlay netip.Addr
+	targets  map[string]*dpdkproto.IpAddress
+}
+
+// Server is an in-memory implementation of the dpservice gRPC API.
+type Server struct {
+	dpdkproto.UnimplementedDPDKironcoreServer
+
+	mu sync.Mutex
+
+	opts         Options
+	uuid         string
+	lastUnderlay netip.Addr
+
+	interfaces    map[string]*interfaceEntry
+	loadBalancers map[string]*loadBalancerEntry
+	routes        map[uint32]map[netip.Prefix]*dpdkproto.Route
+	neighborNats  map[neighborNatKey]netip.Addr
+}
+
+func NewServer(opts Options) *Server {
+	setOptionsDefaults(&opts)
+	return &Server{
+		opts:          opts,
+		lastUnderlay:  opts.UnderlayPrefix.Addr(),
+		interfaces:    make(map[string]*interfaceEntry),
+		loadBalancers: make(map[string]*loadBalancerEntry),
+		routes:        make(map[uint32]map[netip.Prefix]*dpdkproto.Route),
+		neighborNats:  make(map[neighborNatKey]netip.Addr),
+	}
+}
+
+// Start serves the simulator on the given listener in the background.
+// The returned gRPC server has to be stopped by the caller.
+func (s *Server) Start(lis net.Listener) *grpc.Server {
+	srv := grpc.NewServer()
+	dpdkproto.RegisterDPDKironcoreServer(srv, s)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	return srv
+}
+
+func statusOK() *dpdkproto.Status {
+	return &dpdkproto.Status{}
+}
+
+func errStatus(code uint32, format string, args ...any) *dpdkproto.Status {
+	return &dpdkproto.Status{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) nextUnderlay() netip.Addr {
+	s.lastUnderlay = s.lastUnderlay.Next()
+	return s.lastUnderlay
+}
+
+func underlayBytes(addr netip.Addr) []byte {
+	return []byte(addr.String())
+}
+
+func protoPrefixToPrefix(p *dpdkproto.Prefix) (netip.Prefix, error) {
+	addr, err := netip.ParseAddr(string(p.GetIp().GetAddress()))
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, int(p.GetLength())).Masked(), nil
+}
+
+func prefixToProtoPrefix(prefix netip.Prefix, underlay netip.Addr) *dpdkproto.Prefix {
+	addr := prefix.Addr()
+	p := &dpdkproto.Prefix{
+		Ip:     dpdk.NetIPAddrToProtoIpAddress(&addr),
+		Length: uint32(prefix.Bits()),
+	}
+	if underlay.IsValid() {
+		p.UnderlayRoute = underlayBytes(underlay)
+	}
+	return p
+}
+
+func sortedPrefixes[V any](m map[netip.Prefix]V) []netip.Prefix {
+	res := make([]netip.Prefix, 0, len(m))
+	for prefix := range m {
+		res = append(res, prefix)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].String() < res[j].String() })
+	return res
+}
+
+func (s *Server) vniInUse(vni uint32) bool {
+	for _, iface := range s.interfaces {
+		if iface.vni == vni {
+			return true
+		}
+	}
+	for _, lb := range s.loadBalancers {
+		if lb.vni == vni {
+			return true
+		}
+	}
+	return false
+}
+
+// freeVNIIfUnused drops the routing table of a VNI once nothing uses it anymore, like dpservice does.
+func (s *Server) freeVNIIfUnused(vni uint32) {
+	if !s.vniInUse(vni) {
+		delete(s.routes, vni)
+	}
+}
+
+func (s *Server) CheckInitialized(_ context.Context, _ *dpdkproto.CheckInitializedRequest) (*dpdkproto.CheckInitializedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uuid == "" {
+		return nil, status.Error(codes.FailedPrecondition, "dpservice is not initialized")
+	}
+	return &dpdkproto.CheckInitializedResponse{Status: statusOK(), Uuid: s.uuid}, nil
+}
+
+func (s *Server) Initialize(_ context.Context, _ *dpdkproto.InitializeRequest) (*dpdkproto.InitializeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uuid == "" {
+		s.uuid = string(uuid.NewUUID())
+	}
+	return &dpdkproto.InitializeResponse{Status: statusOK(), Uuid: s.uuid}, nil
+}
+
+func (s *Server) GetVersion(_ context.Context, req *dpdkproto.GetVersionRequest) (*dpdkproto.GetVersionResponse, error) {
+	return &dpdkproto.GetVersionResponse{
+		Status:          statusOK(),
+		ServiceProtocol: req.GetClientProtocol(),
+		ServiceVersion:  s.opts.ServiceVersion,
+	}, nil
+}
+
+func (s *Server) protoInterface(id string, iface *interfaceEntry) *dpdkproto.Interface {
+	return &dpdkproto.Interface{
+		Id:             []byte(id),
+		Vni:            iface.vni,
+		PrimaryIpv4:    []byte(iface.ipv4),
+		PrimaryIpv6:    []byte(iface.ipv6),
+		UnderlayRoute:  underlayBytes(iface.underlay),
+		PciName:        iface.device,
+		MeteringParams: iface.metering,
+	}
+}
+
+func (s *Server) ListInterfaces(_ context.Context, _ *dpdkproto.ListInterfacesRequest) (*dpdkproto.ListInterfacesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.interfaces))
+	for id := range s.interfaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	res := &dpdkproto.ListInterfacesResponse{Status: statusOK()}
+	for _, id := range ids {
+		res.Interfaces = append(res.Interfaces, s.protoInterface(id, s.interfaces[id]))
+	}
+	return res, nil
+}
+
+func (s *Server) GetInterface(_ context.Context, req *dpdkproto.GetInterfaceRequest) (*dpdkproto.GetInterfaceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.GetInterfaceResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "interface %s not found", id)}, nil
+	}
+	return &dpdkproto.GetInterfaceResponse{Status: statusOK(), Interface: s.protoInterface(id, iface)}, nil
+}
+
+func (s *Server) CreateInterface(_ context.Context, req *dpdkproto.CreateInterfaceRequest) (*dpdkproto.CreateInterfaceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	if _, ok := s.interfaces[id]; ok {
+		return &dpdkproto.CreateInterfaceResponse{Status: errStatus(dpdkerrors.ALREADY_EXISTS, "interface %s already exists", id)}, nil
+	}
+	for otherID, other := range s.interfaces {
+		if req.GetDeviceName() != "" && other.device == req.GetDeviceName() {
+			return &dpdkproto.CreateInterfaceResponse{Status: errStatus(dpdkerrors.ALREADY_EXISTS, "device %s is used by interface %s", other.device, otherID)}, nil
+		}
+	}
+
+	ipv4, ipv6 := "0.0.0.0", "::"
+	if addr := req.GetIpv4Config().GetPrimaryAddress(); len(addr) > 0 {
+		ipv4 = string(addr)
+	}
+	if addr := req.GetIpv6Config().GetPrimaryAddress(); len(addr) > 0 {
+		ipv6 = string(addr)
+	}
+
+	iface := &interfaceEntry{
+		vni:        req.GetVni(),
+		ipv4:       ipv4,
+		ipv6:       ipv6,
+		device:     req.GetDeviceName(),
+		underlay:   s.nextUnderlay(),
+		metering:   req.GetMeteringParameters(),
+		prefixes:   make(map[netip.Prefix]netip.Addr),
+		lbPrefixes: make(map[netip.Prefix]netip.Addr),
+		fwRules:    make(map[string]*dpdkproto.FirewallRule),
+	}
+	s.interfaces[id] = iface
+	return &dpdkproto.CreateInterfaceResponse{
+		Status:        statusOK(),
+		UnderlayRoute: underlayBytes(iface.underlay),
+		Vf:            &dpdkproto.VirtualFunction{Name: iface.device},
+	}, nil
+}
+
+func (s *Server) DeleteInterface(_ context.Context, req *dpdkproto.DeleteInterfaceRequest) (*dpdkproto.DeleteInterfaceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.DeleteInterfaceResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "interface %s not found", id)}, nil
+	}
+	delete(s.interfaces, id)
+	s.freeVNIIfUnused(iface.vni)
+	return &dpdkproto.DeleteInterfaceResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) listPrefixes(id string, selectPrefixes func(*interfaceEntry) map[netip.Prefix]netip.Addr) ([]*dpdkproto.Prefix, *dpdkproto.Status) {
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return nil, errStatus(dpdkerrors.NO_VM, "interface %s not found", id)
+	}
+	prefixes := selectPrefixes(iface)
+	var res []*dpdkproto.Prefix
+	for _, prefix := range sortedPrefixes(prefixes) {
+		res = append(res, prefixToProtoPrefix(prefix, prefixes[prefix]))
+	}
+	return res, statusOK()
+}
+
+func (s *Server) createPrefix(id string, p *dpdkproto.Prefix, selectPrefixes func(*interfaceEntry) map[netip.Prefix]netip.Addr) ([]byte, *dpdkproto.Status) {
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return nil, errStatus(dpdkerrors.NO_VM, "interface %s not found", id)
+	}
+	prefix, err := protoPrefixToPrefix(p)
+	if err != nil {
+		return nil, errStatus(dpdkerrors.BAD_REQUEST, "invalid prefix: %v", err)
+	}
+	prefixes := selectPrefixes(iface)
+	if _, ok := prefixes[prefix]; ok {
+		return nil, errStatus(dpdkerrors.ROUTE_EXISTS, "prefix %s already exists", prefix)
+	}
+	underlay := s.nextUnderlay()
+	prefixes[prefix] = underlay
+	return underlayBytes(underlay), statusOK()
+}
+
+func (s *Server) deletePrefix(id string, p *dpdkproto.Prefix, selectPrefixes func(*interfaceEntry) map[netip.Prefix]netip.Addr) *dpdkproto.Status {
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return errStatus(dpdkerrors.NO_VM, "interface %s not found", id)
+	}
+	prefix, err := protoPrefixToPrefix(p)
+	if err != nil {
+		return errStatus(dpdkerrors.BAD_REQUEST, "invalid prefix: %v", err)
+	}
+	prefixes := selectPrefixes(iface)
+	if _, ok := prefixes[prefix]; !ok {
+		return errStatus(dpdkerrors.ROUTE_NOT_FOUND, "prefix %s not found", prefix)
+	}
+	delete(prefixes, prefix)
+	return statusOK()
+}
+
+func aliasPrefixes(iface *interfaceEntry) map[netip.Prefix]netip.Addr { return iface.prefixes }
+
+func lbPrefixes(iface *interfaceEntry) map[netip.Prefix]netip.Addr { return iface.lbPrefixes }
+
+func (s *Server) ListPrefixes(_ context.Context, req *dpdkproto.ListPrefixesRequest) (*dpdkproto.ListPrefixesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefixes, st := s.listPrefixes(string(req.GetInterfaceId()), aliasPrefixes)
+	return &dpdkproto.ListPrefixesResponse{Status: st, Prefixes: prefixes}, nil
+}
+
+func (s *Server) CreatePrefix(_ context.Context, req *dpdkproto.CreatePrefixRequest) (*dpdkproto.CreatePrefixResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	underlay, st := s.createPrefix(string(req.GetInterfaceId()), req.GetPrefix(), aliasPrefixes)
+	return &dpdkproto.CreatePrefixResponse{Status: st, UnderlayRoute: underlay}, nil
+}
+
+func (s *Server) DeletePrefix(_ context.Context, req *dpdkproto.DeletePrefixRequest) (*dpdkproto.DeletePrefixResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &dpdkproto.DeletePrefixResponse{Status: s.deletePrefix(string(req.GetInterfaceId()), req.GetPrefix(), aliasPrefixes)}, nil
+}
+
+func (s *Server) ListLoadBalancerPrefixes(_ context.Context, req *dpdkproto.ListLoadBalancerPrefixesRequest) (*dpdkproto.ListLoadBalancerPrefixesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefixes, st := s.listPrefixes(string(req.GetInterfaceId()), lbPrefixes)
+	return &dpdkproto.ListLoadBalancerPrefixesResponse{Status: st, Prefixes: prefixes}, nil
+}
+
+func (s *Server) CreateLoadBalancerPrefix(_ context.Context, req *dpdkproto.CreateLoadBalancerPrefixRequest) (*dpdkproto.CreateLoadBalancerPrefixResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	underlay, st := s.createPrefix(string(req.GetInterfaceId()), req.GetPrefix(), lbPrefixes)
+	return &dpdkproto.CreateLoadBalancerPrefixResponse{Status: st, UnderlayRoute: underlay}, nil
+}
+
+func (s *Server) DeleteLoadBalancerPrefix(_ context.Context, req *dpdkproto.DeleteLoadBalancerPrefixRequest) (*dpdkproto.DeleteLoadBalancerPrefixResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &dpdkproto.DeleteLoadBalancerPrefixResponse{Status: s.deletePrefix(string(req.GetInterfaceId()), req.GetPrefix(), lbPrefixes)}, nil
+}
+
+func (s *Server) CreateVip(_ context.Context, req *dpdkproto.CreateVipRequest) (*dpdkproto.CreateVipResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.CreateVipResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	if iface.vip != nil {
+		return &dpdkproto.CreateVipResponse{Status: errStatus(dpdkerrors.SNAT_EXISTS, "interface %s already has a virtual ip", id)}, nil
+	}
+	iface.vip = &addrEntry{ip: req.GetVipIp(), underlay: s.nextUnderlay()}
+	return &dpdkproto.CreateVipResponse{Status: statusOK(), UnderlayRoute: underlayBytes(iface.vip.underlay)}, nil
+}
+
+func (s *Server) GetVip(_ context.Context, req *dpdkproto.GetVipRequest) (*dpdkproto.GetVipResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.GetVipResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	if iface.vip == nil {
+		return &dpdkproto.GetVipResponse{Status: errStatus(dpdkerrors.SNAT_NO_DATA, "interface %s has no virtual ip", id)}, nil
+	}
+	return &dpdkproto.GetVipResponse{Status: statusOK(), VipIp: iface.vip.ip, UnderlayRoute: underlayBytes(iface.vip.underlay)}, nil
+}
+
+func (s *Server) DeleteVip(_ context.Context, req *dpdkproto.DeleteVipRequest) (*dpdkproto.DeleteVipResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.DeleteVipResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	if iface.vip == nil {
+		return &dpdkproto.DeleteVipResponse{Status: errStatus(dpdkerrors.SNAT_NO_DATA, "interface %s has no virtual ip", id)}, nil
+	}
+	iface.vip = nil
+	return &dpdkproto.DeleteVipResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) CreateLoadBalancer(_ context.Context, req *dpdkproto.CreateLoadBalancerRequest) (*dpdkproto.CreateLoadBalancerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetLoadbalancerId())
+	if _, ok := s.loadBalancers[id]; ok {
+		return &dpdkproto.CreateLoadBalancerResponse{Status: errStatus(dpdkerrors.ALREADY_EXISTS, "load balancer %s already exists", id)}, nil
+	}
+	lb := &loadBalancerEntry{
+		ip:       req.GetLoadbalancedIp(),
+		vni:      req.GetVni(),
+		ports:    req.GetLoadbalancedPorts(),
+		underlay: s.nextUnderlay(),
+		targets:  make(map[string]*dpdkproto.IpAddress),
+	}
+	s.loadBalancers[id] = lb
+	return &dpdkproto.CreateLoadBalancerResponse{Status: statusOK(), UnderlayRoute: underlayBytes(lb.underlay)}, nil
+}
+
+func (s *Server) GetLoadBalancer(_ context.Context, req *dpdkproto.GetLoadBalancerRequest) (*dpdkproto.GetLoadBalancerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetLoadbalancerId())
+	lb, ok := s.loadBalancers[id]
+	if !ok {
+		return &dpdkproto.GetLoadBalancerResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "load balancer %s not found", id)}, nil
+	}
+	return &dpdkproto.GetLoadBalancerResponse{
+		Status:            statusOK(),
+		LoadbalancedIp:    lb.ip,
+		Vni:               lb.vni,
+		LoadbalancedPorts: lb.ports,
+		UnderlayRoute:     underlayBytes(lb.underlay),
+	}, nil
+}
+
+func (s *Server) DeleteLoadBalancer(_ context.Context, req *dpdkproto.DeleteLoadBalancerRequest) (*dpdkproto.DeleteLoadBalancerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetLoadbalancerId())
+	lb, ok := s.loadBalancers[id]
+	if !ok {
+		return &dpdkproto.DeleteLoadBalancerResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "load balancer %s not found", id)}, nil
+	}
+	delete(s.loadBalancers, id)
+	s.freeVNIIfUnused(lb.vni)
+	return &dpdkproto.DeleteLoadBalancerResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) CreateLoadBalancerTarget(_ context.Context, req *dpdkproto.CreateLoadBalancerTargetRequest) (*dpdkproto.CreateLoadBalancerTargetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetLoadbalancerId())
+	lb, ok := s.loadBalancers[id]
+	if !ok {
+		return &dpdkproto.CreateLoadBalancerTargetResponse{Status: errStatus(dpdkerrors.NO_LB, "load balancer %s not found", id)}, nil
+	}
+	target := string(req.GetTargetIp().GetAddress())
+	if _, ok := lb.targets[target]; ok {
+		return &dpdkproto.CreateLoadBalancerTargetResponse{Status: errStatus(dpdkerrors.ALREADY_EXISTS, "target %s already exists", target)}, nil
+	}
+	lb.targets[target] = req.GetTargetIp()
+	return &dpdkproto.CreateLoadBalancerTargetResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) ListLoadBalancerTargets(_ context.Context, req *dpdkproto.ListLoadBalancerTargetsRequest) (*dpdkproto.ListLoadBalancerTargetsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetLoadbalancerId())
+	lb, ok := s.loadBalancers[id]
+	if !ok {
+		return &dpdkproto.ListLoadBalancerTargetsResponse{Status: errStatus(dpdkerrors.NO_LB, "load balancer %s not found", id)}, nil
+	}
+	targets := make([]string, 0, len(lb.targets))
+	for target := range lb.targets {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	res := &dpdkproto.ListLoadBalancerTargetsResponse{Status: statusOK()}
+	for _, target := range targets {
+		res.TargetIps = append(res.TargetIps, lb.targets[target])
+	}
+	return res, nil
+}
+
+func (s *Server) DeleteLoadBalancerTarget(_ context.Context, req *dpdkproto.DeleteLoadBalancerTargetRequest) (*dpdkproto.DeleteLoadBalancerTargetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetLoadbalancerId())
+	lb, ok := s.loadBalancers[id]
+	if !ok {
+		return &dpdkproto.DeleteLoadBalancerTargetResponse{Status: errStatus(dpdkerrors.NO_LB, "load balancer %s not found", id)}, nil
+	}
+	target := string(req.GetTargetIp().GetAddress())
+	if _, ok := lb.targets[target]; !ok {
+		return &dpdkproto.DeleteLoadBalancerTargetResponse{Status: errStatus(dpdkerrors.NO_BACKIP, "target %s not found", target)}, nil
+	}
+	delete(lb.targets, target)
+	return &dpdkproto.DeleteLoadBalancerTargetResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) CreateNat(_ context.Context, req *dpdkproto.CreateNatRequest) (*dpdkproto.CreateNatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.CreateNatResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	if iface.nat != nil {
+		return &dpdkproto.CreateNatResponse{Status: errStatus(dpdkerrors.SNAT_EXISTS, "interface %s already has a nat", id)}, nil
+	}
+	iface.nat = &natEntry{
+		ip:       req.GetNatIp(),
+		minPort:  req.GetMinPort(),
+		maxPort:  req.GetMaxPort(),
+		underlay: s.nextUnderlay(),
+	}
+	return &dpdkproto.CreateNatResponse{Status: statusOK(), UnderlayRoute: underlayBytes(iface.nat.underlay)}, nil
+}
+
+func (s *Server) GetNat(_ context.Context, req *dpdkproto.GetNatRequest) (*dpdkproto.GetNatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.GetNatResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	if iface.nat == nil {
+		return &dpdkproto.GetNatResponse{Status: errStatus(dpdkerrors.SNAT_NO_DATA, "interface %s has no nat", id)}, nil
+	}
+	return &dpdkproto.GetNatResponse{
+		Status:        statusOK(),
+		NatIp:         iface.nat.ip,
+		MinPort:       iface.nat.minPort,
+		MaxPort:       iface.nat.maxPort,
+		UnderlayRoute: underlayBytes(iface.nat.underlay),
+	}, nil
+}
+
+func (s *Server) DeleteNat(_ context.Context, req *dpdkproto.DeleteNatRequest) (*dpdkproto.DeleteNatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.DeleteNatResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	if iface.nat == nil {
+		return &dpdkproto.DeleteNatResponse{Status: errStatus(dpdkerrors.SNAT_NO_DATA, "interface %s has no nat", id)}, nil
+	}
+	iface.nat = nil
+	return &dpdkproto.DeleteNatResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) ListLocalNats(_ context.Context, req *dpdkproto.ListLocalNatsRequest) (*dpdkproto.ListLocalNatsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	natIP := string(req.GetNatIp().GetAddress())
+	ids := make([]string, 0, len(s.interfaces))
+	for id, iface := range s.interfaces {
+		if iface.nat != nil && string(iface.nat.ip.GetAddress()) == natIP {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	res := &dpdkproto.ListLocalNatsResponse{Status: statusOK()}
+	for _, id := range ids {
+		iface := s.interfaces[id]
+		addr, err := netip.ParseAddr(iface.ipv4)
+		if err != nil {
+			return &dpdkproto.ListLocalNatsResponse{Status: errStatus(dpdkerrors.BAD_REQUEST, "invalid interface ip: %v", err)}, nil
+		}
+		res.NatEntries = append(res.NatEntries, &dpdkproto.NatEntry{
+			NatIp:   dpdk.NetIPAddrToProtoIpAddress(&addr),
+			MinPort: iface.nat.minPort,
+			MaxPort: iface.nat.maxPort,
+			Vni:     iface.vni,
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) CreateNeighborNat(_ context.Context, req *dpdkproto.CreateNeighborNatRequest) (*dpdkproto.CreateNeighborNatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	underlay, err := netip.ParseAddr(string(req.GetUnderlayRoute()))
+	if err != nil {
+		return &dpdkproto.CreateNeighborNatResponse{Status: errStatus(dpdkerrors.BAD_REQUEST, "invalid underlay route: %v", err)}, nil
+	}
+	key := neighborNatKey{
+		ip:      string(req.GetNatIp().GetAddress()),
+		vni:     req.GetVni(),
+		minPort: req.GetMinPort(),
+		maxPort: req.GetMaxPort(),
+	}
+	if _, ok := s.neighborNats[key]; ok {
+		return &dpdkproto.CreateNeighborNatResponse{Status: errStatus(dpdkerrors.ALREADY_EXISTS, "neighbor nat already exists")}, nil
+	}
+	s.neighborNats[key] = underlay
+	return &dpdkproto.CreateNeighborNatResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) DeleteNeighborNat(_ context.Context, req *dpdkproto.DeleteNeighborNatRequest) (*dpdkproto.DeleteNeighborNatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := neighborNatKey{
+		ip:      string(req.GetNatIp().GetAddress()),
+		vni:     req.GetVni(),
+		minPort: req.GetMinPort(),
+		maxPort: req.GetMaxPort(),
+	}
+	if _, ok := s.neighborNats[key]; !ok {
+		return &dpdkproto.DeleteNeighborNatResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "neighbor nat not found")}, nil
+	}
+	delete(s.neighborNats, key)
+	return &dpdkproto.DeleteNeighborNatResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) ListNeighborNats(_ context.Context, req *dpdkproto.ListNeighborNatsRequest) (*dpdkproto.ListNeighborNatsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	natIP := string(req.GetNatIp().GetAddress())
+	var keys []neighborNatKey
+	for key := range s.neighborNats {
+		if key.ip == natIP {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].vni != keys[j].vni {
+			return keys[i].vni < keys[j].vni
+		}
+		return keys[i].minPort < keys[j].minPort
+	})
+	res := &dpdkproto.ListNeighborNatsResponse{Status: statusOK()}
+	for _, key := range keys {
+		res.NatEntries = append(res.NatEntries, &dpdkproto.NatEntry{
+			MinPort:       key.minPort,
+			MaxPort:       key.maxPort,
+			Vni:           key.vni,
+			UnderlayRoute: underlayBytes(s.neighborNats[key]),
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) ListRoutes(_ context.Context, req *dpdkproto.ListRoutesRequest) (*dpdkproto.ListRoutesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	routes := s.routes[req.GetVni()]
+	res := &dpdkproto.ListRoutesResponse{Status: statusOK()}
+	for _, prefix := range sortedPrefixes(routes) {
+		res.Routes = append(res.Routes, routes[prefix])
+	}
+	return res, nil
+}
+
+func (s *Server) CreateRoute(_ context.Context, req *dpdkproto.CreateRouteRequest) (*dpdkproto.CreateRouteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vni := req.GetVni()
+	if !s.vniInUse(vni) {
+		return &dpdkproto.CreateRouteResponse{Status: errStatus(dpdkerrors.NO_VNI, "vni %d is not in use", vni)}, nil
+	}
+	prefix, err := protoPrefixToPrefix(req.GetRoute().GetPrefix())
+	if err != nil {
+		return &dpdkproto.CreateRouteResponse{Status: errStatus(dpdkerrors.BAD_REQUEST, "invalid prefix: %v", err)}, nil
+	}
+	routes, ok := s.routes[vni]
+	if !ok {
+		routes = make(map[netip.Prefix]*dpdkproto.Route)
+		s.routes[vni] = routes
+	}
+	if _, ok := routes[prefix]; ok {
+		return &dpdkproto.CreateRouteResponse{Status: errStatus(dpdkerrors.ROUTE_EXISTS, "route %s already exists in vni %d", prefix, vni)}, nil
+	}
+	routes[prefix] = req.GetRoute()
+	return &dpdkproto.CreateRouteResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) DeleteRoute(_ context.Context, req *dpdkproto.DeleteRouteRequest) (*dpdkproto.DeleteRouteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vni := req.GetVni()
+	routes, ok := s.routes[vni]
+	if !ok {
+		return &dpdkproto.DeleteRouteResponse{Status: errStatus(dpdkerrors.NO_VNI, "vni %d has no routes", vni)}, nil
+	}
+	prefix, err := protoPrefixToPrefix(req.GetRoute().GetPrefix())
+	if err != nil {
+		return &dpdkproto.DeleteRouteResponse{Status: errStatus(dpdkerrors.BAD_REQUEST, "invalid prefix: %v", err)}, nil
+	}
+	if _, ok := routes[prefix]; !ok {
+		return &dpdkproto.DeleteRouteResponse{Status: errStatus(dpdkerrors.ROUTE_NOT_FOUND, "route %s not found in vni %d", prefix, vni)}, nil
+	}
+	delete(routes, prefix)
+	return &dpdkproto.DeleteRouteResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) CheckVniInUse(_ context.Context, req *dpdkproto.CheckVniInUseRequest) (*dpdkproto.CheckVniInUseResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &dpdkproto.CheckVniInUseResponse{Status: statusOK(), InUse: s.vniInUse(req.GetVni())}, nil
+}
+
+func (s *Server) ResetVni(_ context.Context, req *dpdkproto.ResetVniRequest) (*dpdkproto.ResetVniResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.routes, req.GetVni())
+	return &dpdkproto.ResetVniResponse{Status: statusOK()}, nil
+}
+
+func (s *Server) ListFirewallRules(_ context.Context, req *dpdkproto.ListFirewallRulesRequest) (*dpdkproto.ListFirewallRulesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.ListFirewallRulesResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	ruleIDs := make([]string, 0, len(iface.fwRules))
+	for ruleID := range iface.fwRules {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+	res := &dpdkproto.ListFirewallRulesResponse{Status: statusOK()}
+	for _, ruleID := range ruleIDs {
+		res.Rules = append(res.Rules, iface.fwRules[ruleID])
+	}
+	return res, nil
+}
+
+func (s *Server) CreateFirewallRule(_ context.Context, req *dpdkproto.CreateFirewallRuleRequest) (*dpdkproto.CreateFirewallRuleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.CreateFirewallRuleResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	ruleID := string(req.GetRule().GetId())
+	if _, ok := iface.fwRules[ruleID]; ok {
+		return &dpdkproto.CreateFirewallRuleResponse{Status: errStatus(dpdkerrors.ALREADY_EXISTS, "firewall rule %s already exists", ruleID)}, nil
+	}
+	iface.fwRules[ruleID] = req.GetRule()
+	return &dpdkproto.CreateFirewallRuleResponse{Status: statusOK(), RuleId: []byte(ruleID)}, nil
+}
+
+func (s *Server) GetFirewallRule(_ context.Context, req *dpdkproto.GetFirewallRuleRequest) (*dpdkproto.GetFirewallRuleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.GetFirewallRuleResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	ruleID := string(req.GetRuleId())
+	rule, ok := iface.fwRules[ruleID]
+	if !ok {
+		return &dpdkproto.GetFirewallRuleResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "firewall rule %s not found", ruleID)}, nil
+	}
+	return &dpdkproto.GetFirewallRuleResponse{Status: statusOK(), Rule: rule}, nil
+}
+
+func (s *Server) DeleteFirewallRule(_ context.Context, req *dpdkproto.DeleteFirewallRuleRequest) (*dpdkproto.DeleteFirewallRuleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := string(req.GetInterfaceId())
+	iface, ok := s.interfaces[id]
+	if !ok {
+		return &dpdkproto.DeleteFirewallRuleResponse{Status: errStatus(dpdkerrors.NO_VM, "interface %s not found", id)}, nil
+	}
+	ruleID := string(req.GetRuleId())
+	if _, ok := iface.fwRules[ruleID]; !ok {
+		return &dpdkproto.DeleteFirewallRuleResponse{Status: errStatus(dpdkerrors.NOT_FOUND, "firewall rule %s not found", ruleID)}, nil
+	}
+	delete(iface.fwRules, ruleID)
+	return &dpdkproto.DeleteFirewallRuleResponse{Status: statusOK()}, nil
+}
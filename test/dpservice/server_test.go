@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package dpservice_test
+
+import (
+	"net/netip"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func addrPtr(s string) *netip.Addr {
+	addr := netip.MustParseAddr(s)
+	return &addr
+}
+
+func newInterface(id string, vni uint32, ip string) *dpdk.Interface {
+	return &dpdk.Interface{
+		InterfaceMeta: dpdk.InterfaceMeta{ID: id},
+		Spec: dpdk.InterfaceSpec{
+			VNI:    vni,
+			Device: "net_tap_" + id,
+			IPv4:   addrPtr(ip),
+		},
+	}
+}
+
+var _ = Describe("Server", func() {
+	client := SetupClient()
+
+	It("should initialize once", func(ctx SpecContext) {
+		_, err := (*client).CheckInitialized(ctx)
+		Expect(err).To(HaveOccurred())
+
+		init, err := (*client).Initialize(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		check, err := (*client).CheckInitialized(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(check.Spec.UUID).To(Equal(init.Spec.UUID))
+	})
+
+	It("should manage the interface lifecycle", func(ctx SpecContext) {
+		created, err := (*client).CreateInterface(ctx, newInterface("a", 100, "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Spec.UnderlayRoute.IsValid()).To(BeTrue())
+		Expect(created.Spec.VirtualFunction.Name).To(Equal("net_tap_a"))
+
+		_, err = (*client).CreateInterface(ctx, newInterface("a", 100, "10.0.0.1"))
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.ALREADY_EXISTS)).To(BeTrue())
+
+		iface, err := (*client).GetInterface(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(100)))
+		Expect(*iface.Spec.IPv4).To(Equal(netip.MustParseAddr("10.0.0.1")))
+		Expect(iface.Spec.IPv6.IsUnspecified()).To(BeTrue())
+		Expect(*iface.Spec.UnderlayRoute).To(Equal(*created.Spec.UnderlayRoute))
+
+		vni, err := (*client).GetVni(ctx, 100, uint8(dpdkproto.VniType_VNI_IPV4))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni.Spec.InUse).To(BeTrue())
+
+		_, err = (*client).DeleteInterface(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = (*client).GetInterface(ctx, "a")
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)).To(BeTrue())
+
+		vni, err = (*client).GetVni(ctx, 100, uint8(dpdkproto.VniType_VNI_IPV4))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vni.Spec.InUse).To(BeFalse())
+	})
+
+	It("should report virtual ip status codes like dpservice", func(ctx SpecContext) {
+		_, err := (*client).GetVirtualIP(ctx, "a")
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_VM)).To(BeTrue())
+
+		_, err = (*client).CreateInterface(ctx, newInterface("a", 100, "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = (*client).GetVirtualIP(ctx, "a")
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.SNAT_NO_DATA)).To(BeTrue())
+
+		created, err := (*client).CreateVirtualIP(ctx, &dpdk.VirtualIP{
+			VirtualIPMeta: dpdk.VirtualIPMeta{InterfaceID: "a"},
+			Spec:          dpdk.VirtualIPSpec{IP: addrPtr("20.0.0.1")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		vip, err := (*client).GetVirtualIP(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*vip.Spec.IP).To(Equal(netip.MustParseAddr("20.0.0.1")))
+		Expect(*vip.Spec.UnderlayRoute).To(Equal(*created.Spec.UnderlayRoute))
+
+		_, err = (*client).DeleteVirtualIP(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = (*client).DeleteVirtualIP(ctx, "a")
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.SNAT_NO_DATA)).To(BeTrue())
+	})
+
+	It("should only accept routes for vnis in use", func(ctx SpecContext) {
+		prefix := netip.MustParsePrefix("10.1.0.0/24")
+		route := &dpdk.Route{
+			RouteMeta: dpdk.RouteMeta{VNI: 100},
+			Spec: dpdk.RouteSpec{
+				Prefix:  &prefix,
+				NextHop: &dpdk.RouteNextHop{VNI: 100, IP: addrPtr("fc00::100")},
+			},
+		}
+
+		_, err := (*client).CreateRoute(ctx, route)
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_VNI)).To(BeTrue())
+
+		_, err = (*client).CreateInterface(ctx, newInterface("a", 100, "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = (*client).CreateRoute(ctx, route)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = (*client).CreateRoute(ctx, route)
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.ROUTE_EXISTS)).To(BeTrue())
+
+		routes, err := (*client).ListRoutes(ctx, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(routes.Items).To(HaveLen(1))
+		Expect(*routes.Items[0].Spec.Prefix).To(Equal(prefix))
+
+		By("freeing the routes together with the last interface of the vni")
+		_, err = (*client).DeleteInterface(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+
+		routes, err = (*client).ListRoutes(ctx, 100)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(routes.Items).To(BeEmpty())
+	})
+
+	It("should manage load balancers and their targets", func(ctx SpecContext) {
+		_, err := (*client).CreateLoadBalancerTarget(ctx, &dpdk.LoadBalancerTarget{
+			LoadBalancerTargetMeta: dpdk.LoadBalancerTargetMeta{LoadbalancerID: "lb"},
+			Spec:                   dpdk.LoadBalancerTargetSpec{TargetIP: addrPtr("fc00::100")},
+		})
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_LB)).To(BeTrue())
+
+		_, err = (*client).CreateLoadBalancer(ctx, &dpdk.LoadBalancer{
+			LoadBalancerMeta: dpdk.LoadBalancerMeta{ID: "lb"},
+			Spec: dpdk.LoadBalancerSpec{
+				VNI:     100,
+				LbVipIP: addrPtr("10.0.0.100"),
+				Lbports: []dpdk.LBPort{{Protocol: uint32(dpdkproto.Protocol_TCP), Port: 80}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = (*client).CreateLoadBalancerTarget(ctx, &dpdk.LoadBalancerTarget{
+			LoadBalancerTargetMeta: dpdk.LoadBalancerTargetMeta{LoadbalancerID: "lb"},
+			Spec:                   dpdk.LoadBalancerTargetSpec{TargetIP: addrPtr("fc00::100")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		targets, err := (*client).ListLoadBalancerTargets(ctx, "lb")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targets.Items).To(HaveLen(1))
+		Expect(*targets.Items[0].Spec.TargetIP).To(Equal(netip.MustParseAddr("fc00::100")))
+
+		_, err = (*client).DeleteLoadBalancerTarget(ctx, "lb", addrPtr("fc00::200"))
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NO_BACKIP)).To(BeTrue())
+
+		lb, err := (*client).GetLoadBalancer(ctx, "lb")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lb.Spec.Lbports).To(ConsistOf(dpdk.LBPort{Protocol: uint32(dpdkproto.Protocol_TCP), Port: 80}))
+	})
+
+	It("should manage alias prefixes per interface", func(ctx SpecContext) {
+		_, err := (*client).CreateInterface(ctx, newInterface("a", 100, "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+
+		prefix := netip.MustParsePrefix("10.2.0.0/24")
+		created, err := (*client).CreatePrefix(ctx, &dpdk.Prefix{
+			PrefixMeta: dpdk.PrefixMeta{InterfaceID: "a"},
+			Spec:       dpdk.PrefixSpec{Prefix: prefix},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		prefixes, err := (*client).ListPrefixes(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(prefixes.Items).To(HaveLen(1))
+		Expect(prefixes.Items[0].Spec.Prefix).To(Equal(prefix))
+		Expect(*prefixes.Items[0].Spec.UnderlayRoute).To(Equal(*created.Spec.UnderlayRoute))
+
+		_, err = (*client).DeletePrefix(ctx, "a", &prefix)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = (*client).DeletePrefix(ctx, "a", &prefix)
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.ROUTE_NOT_FOUND)).To(BeTrue())
+	})
+})
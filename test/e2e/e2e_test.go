@@ -0,0 +1,385 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/netip"
+
+	dpdk "github.com/ironcore-dev/dpservice-go/api"
+	dpdkerrors "github.com/ironcore-dev/dpservice-go/errors"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	mb "github.com/ironcore-dev/metalbond"
+	"github.com/ironcore-dev/metalbond/pb"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Reconciliation", func() {
+	ctx := context.Background()
+	ns := SetupTest(ctx)
+
+	It("should program a network interface and its virtual ip", func() {
+		By("creating the network and the network interface")
+		network := createNetwork(ctx, ns.Name, 1001)
+		nic := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.1"), &metalnetv1alpha1.IP{
+			Addr: netip.MustParseAddr("45.0.0.1"),
+		})
+
+		By("waiting for the network interface to become ready")
+		Eventually(object(ctx, nic)).Should(SatisfyAll(
+			HaveField("Status.State", metalnetv1alpha1.NetworkInterfaceStateReady),
+			HaveField("Status.PCIAddress", Not(BeNil())),
+			HaveField("Status.Device.Name", Not(BeEmpty())),
+		))
+
+		iface, err := dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(iface.Spec.VNI).To(Equal(uint32(1001)))
+		Expect(iface.Spec.IPv4).To(HaveValue(Equal(netip.MustParseAddr("10.0.0.1"))))
+
+		By("checking the default route of the network")
+		Eventually(routes(ctx, 1001)).Should(ContainElement(SatisfyAll(
+			HaveField("Spec.Prefix", HaveValue(Equal(netip.MustParsePrefix("0.0.0.0/0")))),
+			HaveField("Spec.NextHop.VNI", uint32(1001)),
+		)))
+
+		By("waiting for the virtual ip to be announced")
+		Eventually(object(ctx, nic)).Should(HaveField("Status.Conditions", ContainElement(SatisfyAll(
+			HaveField("Type", metalnetv1alpha1.NetworkInterfaceVirtualIPReady),
+			HaveField("Status", metav1.ConditionTrue),
+		))))
+		vip, err := dpdkClient.GetVirtualIP(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vip.Spec.IP).To(HaveValue(Equal(netip.MustParseAddr("45.0.0.1"))))
+
+		By("removing the virtual ip")
+		base := nic.DeepCopy()
+		nic.Spec.VirtualIP = nil
+		Expect(k8sClient.Patch(ctx, nic, client.MergeFrom(base))).To(Succeed())
+
+		Eventually(func() bool {
+			_, err := dpdkClient.GetVirtualIP(ctx, string(nic.UID))
+			return dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)
+		}).Should(BeTrue())
+		Eventually(object(ctx, nic)).Should(SatisfyAll(
+			HaveField("Status.VirtualIP", BeNil()),
+			WithTransform(func(nic *metalnetv1alpha1.NetworkInterface) *metav1.Condition {
+				return meta.FindStatusCondition(nic.Status.Conditions, metalnetv1alpha1.NetworkInterfaceVirtualIPReady)
+			}, BeNil()),
+		))
+
+		By("deleting the network interface")
+		deleteAndWait(ctx, nic)
+		_, err = dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)).To(BeTrue())
+
+		deleteAndWait(ctx, network)
+	})
+
+	It("should not announce private virtual ips into the public vni", func() {
+		network := createNetwork(ctx, ns.Name, 1008)
+		base := network.DeepCopy()
+		network.Spec.VirtualIPAnnouncementScope = metalnetv1alpha1.AnnouncementScopePrivate
+		Expect(k8sClient.Patch(ctx, network, client.MergeFrom(base))).To(Succeed())
+
+		nic := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.9"), &metalnetv1alpha1.IP{
+			Addr: netip.MustParseAddr("45.0.0.9"),
+		})
+		Eventually(object(ctx, nic)).Should(HaveField("Status.Conditions", ContainElement(SatisfyAll(
+			HaveField("Type", metalnetv1alpha1.NetworkInterfaceVirtualIPReady),
+			HaveField("Status", metav1.ConditionTrue),
+			HaveField("Reason", metalnetv1alpha1.VirtualIPReasonPrivate),
+		))))
+		vip, err := dpdkClient.GetVirtualIP(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vip.Spec.IP).To(HaveValue(Equal(netip.MustParseAddr("45.0.0.9"))))
+
+		By("overriding the scope on the network interface")
+		nicBase := nic.DeepCopy()
+		scope := metalnetv1alpha1.AnnouncementScopePublic
+		nic.Spec.VirtualIPAnnouncementScope = &scope
+		Expect(k8sClient.Patch(ctx, nic, client.MergeFrom(nicBase))).To(Succeed())
+		Eventually(object(ctx, nic)).Should(HaveField("Status.Conditions", ContainElement(SatisfyAll(
+			HaveField("Type", metalnetv1alpha1.NetworkInterfaceVirtualIPReady),
+			HaveField("Reason", metalnetv1alpha1.VirtualIPReasonAnnounced),
+		))))
+
+		deleteAndWait(ctx, nic)
+		deleteAndWait(ctx, network)
+	})
+
+	It("should egress through the port block allocated by an internet gateway", func() {
+		By("creating the network, the internet gateway and the network interface")
+		network := createNetwork(ctx, ns.Name, 1006)
+		internetGateway := &metalnetv1alpha1.InternetGateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    ns.Name,
+				GenerateName: "igw-",
+			},
+			Spec: metalnetv1alpha1.InternetGatewaySpec{
+				IPs:                      []metalnetv1alpha1.IP{{Addr: netip.MustParseAddr("46.0.0.1")}},
+				PortsPerNetworkInterface: 32768,
+			},
+		}
+		Expect(k8sClient.Create(ctx, internetGateway)).To(Succeed())
+
+		nic := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.6"), nil)
+		base := nic.DeepCopy()
+		nic.Spec.InternetGatewayRef = &corev1.LocalObjectReference{Name: internetGateway.Name}
+		Expect(k8sClient.Patch(ctx, nic, client.MergeFrom(base))).To(Succeed())
+
+		By("waiting for the port block to be allocated and programmed")
+		Eventually(object(ctx, internetGateway)).Should(SatisfyAll(
+			HaveField("Status.Capacity", int32(1)),
+			HaveField("Status.Allocations", ConsistOf(metalnetv1alpha1.InternetGatewayAllocation{
+				NetworkInterfaceName: nic.Name,
+				IP:                   metalnetv1alpha1.IP{Addr: netip.MustParseAddr("46.0.0.1")},
+				Port:                 1024,
+				EndPort:              33791,
+			})),
+		))
+		Eventually(func() (*dpdk.Nat, error) {
+			return dpdkClient.GetNat(ctx, string(nic.UID))
+		}).Should(SatisfyAll(
+			HaveField("Spec.NatIP", HaveValue(Equal(netip.MustParseAddr("46.0.0.1")))),
+			HaveField("Spec.MinPort", uint32(1024)),
+			HaveField("Spec.MaxPort", uint32(33791)),
+		))
+		Eventually(object(ctx, nic)).Should(HaveField("Status.NatIP", Not(BeNil())))
+
+		By("exhausting the nat pool")
+		otherNIC := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.7"), nil)
+		base = otherNIC.DeepCopy()
+		otherNIC.Spec.InternetGatewayRef = &corev1.LocalObjectReference{Name: internetGateway.Name}
+		Expect(k8sClient.Patch(ctx, otherNIC, client.MergeFrom(base))).To(Succeed())
+		Eventually(object(ctx, internetGateway)).Should(HaveField("Status.Pending", int32(1)))
+
+		By("releasing the port block when the network interface gets a virtual ip")
+		base = nic.DeepCopy()
+		nic.Spec.VirtualIP = &metalnetv1alpha1.IP{Addr: netip.MustParseAddr("45.0.0.6")}
+		Expect(k8sClient.Patch(ctx, nic, client.MergeFrom(base))).To(Succeed())
+		Eventually(func() bool {
+			_, err := dpdkClient.GetNat(ctx, string(nic.UID))
+			return dpdkerrors.IsStatusErrorCode(err, dpdkerrors.SNAT_NO_DATA)
+		}).Should(BeTrue())
+		Eventually(object(ctx, internetGateway)).Should(SatisfyAll(
+			HaveField("Status.Pending", int32(0)),
+			HaveField("Status.Allocations", ConsistOf(HaveField("NetworkInterfaceName", otherNIC.Name))),
+		))
+
+		deleteAndWait(ctx, otherNIC)
+		deleteAndWait(ctx, nic)
+		deleteAndWait(ctx, internetGateway)
+		deleteAndWait(ctx, network)
+	})
+
+	It("should withdraw the announcements of a node in maintenance", func() {
+		network := createNetwork(ctx, ns.Name, 1007)
+		nic := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.8"), nil)
+		Eventually(object(ctx, nic)).Should(HaveField("Status.State", metalnetv1alpha1.NetworkInterfaceStateReady))
+
+		By("annotating the node for maintenance")
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        nodeName,
+				Annotations: map[string]string{metalnetv1alpha1.MaintenanceAnnotation: "true"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, node)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, node)
+
+		Eventually(object(ctx, nic)).Should(HaveField("Status.Conditions", ContainElement(SatisfyAll(
+			HaveField("Type", metalnetv1alpha1.NodeInMaintenance),
+			HaveField("Status", metav1.ConditionTrue),
+		))))
+
+		By("checking the dpservice state is kept")
+		_, err := dpdkClient.GetInterface(ctx, string(nic.UID))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("removing the maintenance annotation")
+		base := node.DeepCopy()
+		delete(node.Annotations, metalnetv1alpha1.MaintenanceAnnotation)
+		Expect(k8sClient.Patch(ctx, node, client.MergeFrom(base))).To(Succeed())
+
+		Eventually(object(ctx, nic)).Should(HaveField("Status.Conditions", Not(ContainElement(
+			HaveField("Type", metalnetv1alpha1.NodeInMaintenance),
+		))))
+
+		deleteAndWait(ctx, nic)
+		deleteAndWait(ctx, network)
+	})
+
+	It("should program a load balancer and its remote targets", func() {
+		By("creating the network, a network interface and the load balancer")
+		network := createNetwork(ctx, ns.Name, 1002)
+		nic := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.2"), nil)
+		Eventually(object(ctx, nic)).Should(HaveField("Status.State", metalnetv1alpha1.NetworkInterfaceStateReady))
+
+		lbIP := netip.MustParseAddr("11.0.0.2")
+		lb := &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    ns.Name,
+				GenerateName: "lb-",
+			},
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: network.Name},
+				LBtype:     metalnetv1alpha1.LoadBalancerTypePublic,
+				IPFamily:   corev1.IPv4Protocol,
+				IP:         metalnetv1alpha1.IP{Addr: lbIP},
+				Ports: []metalnetv1alpha1.LBPort{
+					{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 80},
+					{Protocol: metalnetv1alpha1.LBPortProtocolUDP, Port: 53},
+					{Protocol: metalnetv1alpha1.LBPortProtocolSCTP, Port: 3868},
+				},
+				NodeName: &nodeName,
+			},
+		}
+		Expect(k8sClient.Create(ctx, lb)).To(Succeed())
+
+		By("waiting for the load balancer to become ready")
+		Eventually(object(ctx, lb)).Should(HaveField("Status.State", metalnetv1alpha1.LoadBalancerStateReady))
+		dpdkLB, err := dpdkClient.GetLoadBalancer(ctx, string(lb.UID))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dpdkLB.Spec.VNI).To(Equal(uint32(1002)))
+		Expect(dpdkLB.Spec.LbVipIP).To(HaveValue(Equal(lbIP)))
+		Expect(dpdkLB.Spec.Lbports).To(ConsistOf(
+			dpdk.LBPort{Protocol: uint32(dpdkproto.Protocol_TCP), Port: 80},
+			dpdk.LBPort{Protocol: uint32(dpdkproto.Protocol_UDP), Port: 53},
+			dpdk.LBPort{Protocol: uint32(dpdkproto.Protocol_SCTP), Port: 3868},
+		))
+
+		By("announcing a load balancer target from the remote node")
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.PrefixFrom(lbIP, 32)}
+		hop := mb.NextHop{TargetAddress: remoteUnderlay, Type: pb.NextHopType_LOADBALANCER_TARGET}
+		Expect(remoteNode.AnnounceRoute(1002, dest, hop)).To(Succeed())
+
+		Eventually(lbTargets(ctx, string(lb.UID))).Should(ContainElement(
+			HaveField("Spec.TargetIP", HaveValue(Equal(remoteUnderlay))),
+		))
+
+		By("withdrawing the load balancer target from the remote node")
+		Expect(remoteNode.WithdrawRoute(1002, dest, hop)).To(Succeed())
+		Eventually(lbTargets(ctx, string(lb.UID))).Should(BeEmpty())
+
+		By("deleting the load balancer")
+		deleteAndWait(ctx, lb)
+		_, err = dpdkClient.GetLoadBalancer(ctx, string(lb.UID))
+		Expect(dpdkerrors.IsStatusErrorCode(err, dpdkerrors.NOT_FOUND)).To(BeTrue())
+
+		deleteAndWait(ctx, nic)
+		deleteAndWait(ctx, network)
+	})
+
+	It("should install the routes of peered networks", func() {
+		By("creating two networks peered with each other")
+		network := createNetwork(ctx, ns.Name, 1003, 1004)
+		peeredNetwork := createNetwork(ctx, ns.Name, 1004, 1003)
+		nic := createNetworkInterface(ctx, ns.Name, network.Name, netip.MustParseAddr("10.0.0.3"), nil)
+		peeredNIC := createNetworkInterface(ctx, ns.Name, peeredNetwork.Name, netip.MustParseAddr("10.0.0.4"), nil)
+		Eventually(object(ctx, nic)).Should(HaveField("Status.State", metalnetv1alpha1.NetworkInterfaceStateReady))
+		Eventually(object(ctx, peeredNIC)).Should(HaveField("Status.State", metalnetv1alpha1.NetworkInterfaceStateReady))
+
+		By("announcing a route in the peered network from the remote node")
+		dest := mb.Destination{IPVersion: mb.IPV4, Prefix: netip.MustParsePrefix("10.4.0.5/32")}
+		hop := mb.NextHop{TargetAddress: remoteUnderlay, TargetVNI: 1004, Type: pb.NextHopType_STANDARD}
+		Expect(remoteNode.AnnounceRoute(1004, dest, hop)).To(Succeed())
+
+		Eventually(routes(ctx, 1003)).Should(ContainElement(SatisfyAll(
+			HaveField("Spec.Prefix", HaveValue(Equal(dest.Prefix))),
+			HaveField("Spec.NextHop.VNI", uint32(1004)),
+			HaveField("Spec.NextHop.IP", HaveValue(Equal(remoteUnderlay))),
+		)))
+
+		By("withdrawing the route from the remote node")
+		Expect(remoteNode.WithdrawRoute(1004, dest, hop)).To(Succeed())
+		Eventually(routes(ctx, 1003)).ShouldNot(ContainElement(
+			HaveField("Spec.Prefix", HaveValue(Equal(dest.Prefix))),
+		))
+
+		deleteAndWait(ctx, nic)
+		deleteAndWait(ctx, peeredNIC)
+		deleteAndWait(ctx, network)
+		deleteAndWait(ctx, peeredNetwork)
+	})
+})
+
+func createNetwork(ctx context.Context, namespace string, id int32, peeredIDs ...int32) *metalnetv1alpha1.Network {
+	network := &metalnetv1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			GenerateName: "network-",
+		},
+		Spec: metalnetv1alpha1.NetworkSpec{
+			ID:        id,
+			PeeredIDs: peeredIDs,
+		},
+	}
+	Expect(k8sClient.Create(ctx, network)).To(Succeed())
+	return network
+}
+
+func createNetworkInterface(ctx context.Context, namespace, networkName string, ip netip.Addr, virtualIP *metalnetv1alpha1.IP) *metalnetv1alpha1.NetworkInterface {
+	nic := &metalnetv1alpha1.NetworkInterface{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			GenerateName: "nic-",
+		},
+		Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+			NetworkRef: corev1.LocalObjectReference{Name: networkName},
+			IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			IPs:        []metalnetv1alpha1.IP{{Addr: ip}},
+			VirtualIP:  virtualIP,
+			NodeName:   &nodeName,
+		},
+	}
+	Expect(k8sClient.Create(ctx, nic)).To(Succeed())
+	return nic
+}
+
+// object returns a function polling the latest state of obj for use with Eventually.
+func object[T client.Object](ctx context.Context, obj T) func() (T, error) {
+	return func() (T, error) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		return obj, err
+	}
+}
+
+func routes(ctx context.Context, vni uint32) func() ([]dpdk.Route, error) {
+	return func() ([]dpdk.Route, error) {
+		list, err := dpdkClient.ListRoutes(ctx, vni)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+}
+
+func lbTargets(ctx context.Context, id string) func() ([]dpdk.LoadBalancerTarget, error) {
+	return func() ([]dpdk.LoadBalancerTarget, error) {
+		list, err := dpdkClient.ListLoadBalancerTargets(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+}
+
+// deleteAndWait deletes obj and waits until its finalizers are processed and it is gone.
+func deleteAndWait(ctx context.Context, obj client.Object) {
+	Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+	Eventually(func() bool {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		return apierrors.IsNotFound(err)
+	}).Should(BeTrue())
+}
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dpdkclient "github.com/ironcore-dev/dpservice-go/client"
+	dpdkproto "github.com/ironcore-dev/dpservice-go/proto"
+	mb "github.com/ironcore-dev/metalbond"
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	metalnetclient "github.com/ironcore-dev/metalnet/client"
+	"github.com/ironcore-dev/metalnet/controllers"
+	metalnetdpdk "github.com/ironcore-dev/metalnet/dpdk"
+	"github.com/ironcore-dev/metalnet/internal"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/netfns"
+	"github.com/ironcore-dev/metalnet/test/dpservice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+const (
+	pollingInterval      = 100 * time.Millisecond
+	eventuallyTimeout    = 10 * time.Second
+	consistentlyDuration = 1 * time.Second
+
+	publicVNI = 100
+)
+
+var (
+	testEnv   *envtest.Environment
+	k8sClient client.Client
+
+	// dpdkClient talks to the dpservice simulator the controllers are programming.
+	dpdkClient dpdkclient.Client
+
+	// remoteNode is a second metalbond speaker that plays the role of another metalnet node.
+	remoteNode     *mb.MetalBond
+	remoteUnderlay = netip.MustParseAddr("fc00:dead::1")
+
+	nodeName = "e2e-node"
+)
+
+// The e2e suite runs the metalnet controllers against envtest, an in-memory dpservice simulator
+// and an in-process metalbond server, so it does not need any dpservice or metalbond installation.
+
+func TestE2E(t *testing.T) {
+	SetDefaultEventuallyPollingInterval(pollingInterval)
+	SetDefaultEventuallyTimeout(eventuallyTimeout)
+	SetDefaultConsistentlyDuration(consistentlyDuration)
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "E2E Suite")
+}
+
+var _ = BeforeSuite(func() {
+	logger := zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true))
+	logf.SetLogger(logger)
+
+	By("bootstrapping test environment")
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(testEnv.Stop)
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(metalnetv1alpha1.AddToScheme(scheme))
+
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
+	Expect(err).NotTo(HaveOccurred())
+
+	By("starting the dpservice simulator")
+	dpserviceLis, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	dpserviceSrv := dpservice.NewServer(dpservice.Options{}).Start(dpserviceLis)
+	DeferCleanup(dpserviceSrv.Stop)
+
+	conn, err := grpc.Dial(dpserviceLis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(conn.Close)
+
+	dpdkProtoClient := dpdkproto.NewDPDKironcoreClient(conn)
+	dpdkClient = dpdkclient.NewClient(dpdkProtoClient)
+	_, err = dpdkClient.Initialize(context.TODO())
+	Expect(err).NotTo(HaveOccurred())
+
+	By("starting the metalbond server")
+	mbServerAddr := freeLocalAddr()
+	mbServer := mb.NewMetalBond(mb.Config{KeepaliveInterval: 3}, mb.NewDummyClient())
+	Expect(mbServer.StartServer(mbServerAddr)).To(Succeed())
+	DeferCleanup(mbServer.Shutdown)
+
+	By("connecting metalnet and the remote node to the metalbond server")
+	defaultRouterAddr := &metalbond.DefaultRouterAddress{
+		RouterAddress: netip.MustParseAddr("fc00:ffff::1"),
+		PublicVNI:     publicVNI,
+	}
+	metalnetCache := internal.NewMetalnetCache(&logger)
+	metalnetMBClient := metalbond.NewMetalnetClient(&logger, dpdkClient, metalnetCache, defaultRouterAddr, metalbond.ClientOptions{
+		IPv4Only: true,
+	})
+	routeIngester := metalbond.NewRouteIngester(&logger, metalnetMBClient, metalbond.RouteIngesterOptions{Workers: 4})
+	ingesterCtx, cancelIngester := context.WithCancel(context.Background())
+	DeferCleanup(cancelIngester)
+	go func() {
+		defer GinkgoRecover()
+		Expect(routeIngester.Start(ingesterCtx)).To(Succeed())
+	}()
+
+	mbInstance := mb.NewMetalBond(mb.Config{KeepaliveInterval: 3}, routeIngester)
+	metalnetMBClient.SetMetalBond(mbInstance)
+	Expect(mbInstance.AddPeer(mbServerAddr, "")).To(Succeed())
+	DeferCleanup(mbInstance.Shutdown)
+	metalbondRouteUtil := metalbond.NewMaintenanceRouteUtil(metalbond.NewMBRouteUtil(mbInstance))
+
+	remoteNode = mb.NewMetalBond(mb.Config{KeepaliveInterval: 3}, mb.NewDummyClient())
+	Expect(remoteNode.AddPeer(mbServerAddr, "")).To(Succeed())
+	DeferCleanup(remoteNode.Shutdown)
+
+	for _, instance := range []*mb.MetalBond{mbInstance, remoteNode} {
+		Eventually(func() (mb.ConnectionState, error) {
+			return instance.PeerState(mbServerAddr)
+		}).Should(Equal(mb.ESTABLISHED))
+	}
+	subscriptions := metalbond.NewSubscriptions(metalbondRouteUtil)
+	Expect(subscriptions.Acquire(context.TODO(), "public VNI", publicVNI)).To(Succeed())
+
+	By("setting up the network functions")
+	claimStore, err := netfns.NewFileClaimStore(filepath.Join(GinkgoT().TempDir(), "netfns", "claims"), true)
+	Expect(err).NotTo(HaveOccurred())
+	initAvailable, err := netfns.CollectTAPFunctions([]string{"net_tap2", "net_tap3", "net_tap4", "net_tap5"})
+	Expect(err).NotTo(HaveOccurred())
+	netFnsManager, err := netfns.NewManager(claimStore, initAvailable)
+	Expect(err).NotTo(HaveOccurred())
+
+	By("starting the controller manager")
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme: scheme,
+		Metrics: metricsserver.Options{
+			BindAddress: "0",
+		},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(metalnetclient.SetupNetworkInterfaceNetworkRefNameFieldIndexer(context.TODO(), mgr.GetFieldIndexer())).To(Succeed())
+	Expect(metalnetclient.SetupNetworkInterfaceInternetGatewayRefNameFieldIndexer(context.TODO(), mgr.GetFieldIndexer())).To(Succeed())
+	Expect(metalnetclient.SetupLoadBalancerNetworkRefNameFieldIndexer(context.TODO(), mgr.GetFieldIndexer())).To(Succeed())
+
+	Expect((&controllers.NetworkReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		DPDK:              dpdkClient,
+		RouteUtil:         metalbondRouteUtil,
+		Subscriptions:     subscriptions,
+		MetalnetCache:     metalnetCache,
+		MetalnetMBClient:  metalnetMBClient,
+		DefaultRouterAddr: defaultRouterAddr,
+		NodeName:          nodeName,
+	}).SetupWithManager(mgr, mgr.GetCache())).To(Succeed())
+
+	reconcilerDPDK := metalnetdpdk.NewCachingClient(metalnetdpdk.NewIdempotentClient(dpdkClient), metalnetdpdk.CachingClientOptions{
+		TTL: time.Minute,
+	})
+
+	Expect((&controllers.NetworkInterfaceReconciler{
+		Client:                   mgr.GetClient(),
+		EventRecorder:            mgr.GetEventRecorderFor("networkinterface"),
+		Scheme:                   mgr.GetScheme(),
+		DPDK:                     reconcilerDPDK,
+		RouteUtil:                metalbondRouteUtil,
+		AliasPrefixAnnouncer:     metalbond.NewAliasPrefixAnnouncer(metalbondRouteUtil),
+		DeviceAllocator:          netfns.NewNetdevAllocator(netFnsManager),
+		NodeName:                 nodeName,
+		PublicVNI:                publicVNI,
+		VirtualIPHandoverTimeout: 30 * time.Second,
+	}).SetupWithManager(mgr, mgr.GetCache())).To(Succeed())
+
+	Expect((&controllers.LoadBalancerReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("loadbalancer"),
+		DPDK:          reconcilerDPDK,
+		RouteUtil:     metalbondRouteUtil,
+		MetalnetCache: metalnetCache,
+		NodeName:      nodeName,
+		PublicVNI:     publicVNI,
+	}).SetupWithManager(mgr, mgr.GetCache())).To(Succeed())
+
+	Expect((&controllers.InternetGatewayReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	Expect((&controllers.MaintenanceReconciler{
+		Client:    mgr.GetClient(),
+		RouteUtil: metalbondRouteUtil,
+		NodeName:  nodeName,
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	mgrCtx, cancel := context.WithCancel(context.Background())
+	DeferCleanup(cancel)
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(mgrCtx)).To(Succeed(), "failed to start manager")
+	}()
+})
+
+// freeLocalAddr returns a loopback address with a port that is free at the time of calling.
+func freeLocalAddr() string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	defer func() { _ = lis.Close() }()
+	return lis.Addr().String()
+}
+
+// SetupTest returns a namespace which will be created before each ginkgo Container Node and deleted at the end of their Closures
+// so that each test case can run in an independent way
+func SetupTest(ctx context.Context) *corev1.Namespace {
+	ns := &corev1.Namespace{}
+
+	BeforeEach(func() {
+		*ns = corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "e2e-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed(), "failed to create test namespace")
+		DeferCleanup(k8sClient.Delete, ctx, ns)
+	})
+
+	return ns
+}
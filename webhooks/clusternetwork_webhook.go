@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-clusternetwork,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=clusternetworks,verbs=create;update,versions=v1alpha1,name=mclusternetwork.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// ClusterNetworkDefaulter defaults ClusterNetworks like the NetworkDefaulter does Networks.
+type ClusterNetworkDefaulter struct{}
+
+func (d *ClusterNetworkDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	network, ok := obj.(*metalnetv1alpha1.ClusterNetwork)
+	if !ok {
+		return fmt.Errorf("expected a ClusterNetwork but got a %T", obj)
+	}
+
+	defaultNetworkSpec(&network.Spec)
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-networking-metalnet-ironcore-dev-v1alpha1-clusternetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=clusternetworks,verbs=create;update,versions=v1alpha1,name=vclusternetwork.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// ClusterNetworkValidator validates ClusterNetworks like the NetworkValidator does Networks.
+type ClusterNetworkValidator struct {
+	// VNIRange is the range the ID and the peered IDs have to be in. The zero VNIRange allows all VNIs.
+	VNIRange metalbond.VNIRange
+}
+
+func (v *ClusterNetworkValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	network, ok := obj.(*metalnetv1alpha1.ClusterNetwork)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterNetwork but got a %T", obj)
+	}
+	return nil, v.validateClusterNetwork(network)
+}
+
+func (v *ClusterNetworkValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	network, ok := newObj.(*metalnetv1alpha1.ClusterNetwork)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterNetwork but got a %T", newObj)
+	}
+	if !network.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, v.validateClusterNetwork(network)
+}
+
+func (v *ClusterNetworkValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ClusterNetworkValidator) validateClusterNetwork(network *metalnetv1alpha1.ClusterNetwork) error {
+	if allErrs := validateNetworkSpec(&network.Spec, v.VNIRange); len(allErrs) > 0 {
+		return apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("ClusterNetwork").GroupKind(), network.Name, allErrs)
+	}
+	return nil
+}
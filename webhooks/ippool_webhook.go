@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-loadbalancerippool,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=loadbalancerippools,verbs=create;update,versions=v1alpha1,name=mloadbalancerippool.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// LoadBalancerIPPoolDefaulter normalizes the CIDRs of LoadBalancerIPPools.
+type LoadBalancerIPPoolDefaulter struct{}
+
+func (d *LoadBalancerIPPoolDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	pool, ok := obj.(*metalnetv1alpha1.LoadBalancerIPPool)
+	if !ok {
+		return fmt.Errorf("expected a LoadBalancerIPPool but got a %T", obj)
+	}
+
+	normalizePrefixes(pool.Spec.CIDRs)
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-networkippool,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=networkippools,verbs=create;update,versions=v1alpha1,name=mnetworkippool.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// NetworkIPPoolDefaulter normalizes the CIDRs of NetworkIPPools.
+type NetworkIPPoolDefaulter struct{}
+
+func (d *NetworkIPPoolDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	pool, ok := obj.(*metalnetv1alpha1.NetworkIPPool)
+	if !ok {
+		return fmt.Errorf("expected a NetworkIPPool but got a %T", obj)
+	}
+
+	normalizePrefixes(pool.Spec.CIDRs)
+	return nil
+}
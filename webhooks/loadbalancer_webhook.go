@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-loadbalancer,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=loadbalancers,verbs=create;update,versions=v1alpha1,name=mloadbalancer.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// LoadBalancerDefaulter defaults the IP family and the node name of LoadBalancers without node selector and
+// upper cases the protocols of their ports.
+type LoadBalancerDefaulter struct{}
+
+func (d *LoadBalancerDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	lb, ok := obj.(*metalnetv1alpha1.LoadBalancer)
+	if !ok {
+		return fmt.Errorf("expected a LoadBalancer but got a %T", obj)
+	}
+
+	if lb.Spec.IPFamily == "" {
+		lb.Spec.IPFamily = lb.Spec.IP.Family()
+	}
+	if lb.Spec.NodeSelector == nil {
+		defaultNodeName(&lb.Spec.NodeName, lb.Labels)
+	}
+	for i := range lb.Spec.Ports {
+		lb.Spec.Ports[i].Protocol = strings.ToUpper(lb.Spec.Ports[i].Protocol)
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-networking-metalnet-ironcore-dev-v1alpha1-loadbalancer,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=loadbalancers,verbs=create;update,versions=v1alpha1,name=vloadbalancer.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// LoadBalancerValidator rejects LoadBalancers with ports dpservice cannot program and changes of the node of
+// LoadBalancers or of whether they are active-active.
+type LoadBalancerValidator struct{}
+
+func (v *LoadBalancerValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	lb, ok := obj.(*metalnetv1alpha1.LoadBalancer)
+	if !ok {
+		return nil, fmt.Errorf("expected a LoadBalancer but got a %T", obj)
+	}
+	return nil, validateLoadBalancer(lb)
+}
+
+func (v *LoadBalancerValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	lb, ok := newObj.(*metalnetv1alpha1.LoadBalancer)
+	if !ok {
+		return nil, fmt.Errorf("expected a LoadBalancer but got a %T", newObj)
+	}
+	oldLB, ok := oldObj.(*metalnetv1alpha1.LoadBalancer)
+	if !ok {
+		return nil, fmt.Errorf("expected a LoadBalancer but got a %T", oldObj)
+	}
+	allErrs := validateNodeNameUpdate(lb.Spec.NodeName, oldLB.Spec.NodeName)
+	if (lb.Spec.NodeSelector == nil) != (oldLB.Spec.NodeSelector == nil) {
+		// Switching between a single node and active-active would leave the finalizers of the nodes behind.
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "nodeSelector"), "cannot be added or removed"))
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("LoadBalancer").GroupKind(), lb.Name, allErrs)
+	}
+	if !lb.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, validateLoadBalancer(lb)
+}
+
+func (v *LoadBalancerValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateLoadBalancer(lb *metalnetv1alpha1.LoadBalancer) error {
+	allErrs := validateLBPorts(lb.Spec.Ports, field.NewPath("spec", "ports"))
+	if lb.Spec.IP.IsValid() && lb.Spec.IPFamily != "" && lb.Spec.IP.Family() != lb.Spec.IPFamily {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "ip"), lb.Spec.IP.String(),
+			fmt.Sprintf("must be of the ip family %s of the load balancer", lb.Spec.IPFamily)))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("LoadBalancer").GroupKind(), lb.Name, allErrs)
+}
+
+var supportedLBPortProtocols = []string{
+	metalnetv1alpha1.LBPortProtocolTCP,
+	metalnetv1alpha1.LBPortProtocolUDP,
+	metalnetv1alpha1.LBPortProtocolSCTP,
+}
+
+func validateLBPorts(ports []metalnetv1alpha1.LBPort, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := make(map[metalnetv1alpha1.LBPort]struct{})
+	for i, port := range ports {
+		idxPath := fldPath.Index(i)
+		if !slices.Contains(supportedLBPortProtocols, port.Protocol) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("protocol"), port.Protocol, supportedLBPortProtocols))
+		}
+		if port.Port < 1 || port.Port > 65535 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), port.Port, "must be between 1 and 65535"))
+		}
+		if _, ok := seen[port]; ok {
+			allErrs = append(allErrs, field.Duplicate(idxPath, port))
+		}
+		seen[port] = struct{}{}
+	}
+	return allErrs
+}
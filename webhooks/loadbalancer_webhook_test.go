@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/webhooks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("LoadBalancer validation", func() {
+	newLB := func(ports ...metalnetv1alpha1.LBPort) *metalnetv1alpha1.LoadBalancer {
+		return &metalnetv1alpha1.LoadBalancer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb"},
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				IP:    metalnetv1alpha1.MustParseIP("11.0.0.1"),
+				Ports: ports,
+			},
+		}
+	}
+
+	It("should upper case the protocols of the ports", func() {
+		lb := newLB(metalnetv1alpha1.LBPort{Protocol: "udp", Port: 53}, metalnetv1alpha1.LBPort{Protocol: "Sctp", Port: 3868})
+		Expect((&webhooks.LoadBalancerDefaulter{}).Default(context.TODO(), lb)).To(Succeed())
+		Expect(lb.Spec.Ports).To(Equal([]metalnetv1alpha1.LBPort{
+			{Protocol: metalnetv1alpha1.LBPortProtocolUDP, Port: 53},
+			{Protocol: metalnetv1alpha1.LBPortProtocolSCTP, Port: 3868},
+		}))
+	})
+
+	It("should accept TCP, UDP and SCTP ports", func() {
+		_, err := (&webhooks.LoadBalancerValidator{}).ValidateCreate(context.TODO(), newLB(
+			metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 80},
+			metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolUDP, Port: 80},
+			metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolSCTP, Port: 3868},
+		))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject unsupported protocols, invalid and duplicate ports", func() {
+		v := &webhooks.LoadBalancerValidator{}
+
+		_, err := v.ValidateCreate(context.TODO(), newLB(metalnetv1alpha1.LBPort{Protocol: "ICMP", Port: 80}))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.ports[0].protocol")))
+
+		_, err = v.ValidateCreate(context.TODO(), newLB(metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolUDP, Port: 0}))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.ports[0].port")))
+
+		_, err = v.ValidateUpdate(context.TODO(), newLB(), newLB(
+			metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 443},
+			metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 443},
+		))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.ports[1]")))
+	})
+
+	It("should reject ips not of the ip family of the load balancer", func() {
+		v := &webhooks.LoadBalancerValidator{}
+		lb := newLB(metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 80})
+
+		lb.Spec.IPFamily = corev1.IPv6Protocol
+		_, err := v.ValidateCreate(context.TODO(), lb)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.ip")))
+		Expect(err).To(MatchError(ContainSubstring("must be of the ip family IPv6 of the load balancer")))
+
+		lb.Spec.IPFamily = corev1.IPv4Protocol
+		_, err = v.ValidateCreate(context.TODO(), lb)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject changing the node name once set", func() {
+		v := &webhooks.LoadBalancerValidator{}
+		oldLB := newLB(metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 80})
+		lb := oldLB.DeepCopy()
+		lb.Spec.NodeName = ptr.To("node-1")
+
+		_, err := v.ValidateUpdate(context.TODO(), oldLB, lb)
+		Expect(err).NotTo(HaveOccurred())
+
+		oldLB = lb.DeepCopy()
+		lb.Spec.NodeName = ptr.To("node-2")
+		_, err = v.ValidateUpdate(context.TODO(), oldLB, lb)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.nodeName")))
+
+		lb.Spec.NodeName = nil
+		_, err = v.ValidateUpdate(context.TODO(), oldLB, lb)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should reject switching between a single node and active-active", func() {
+		v := &webhooks.LoadBalancerValidator{}
+		oldLB := newLB(metalnetv1alpha1.LBPort{Protocol: metalnetv1alpha1.LBPortProtocolTCP, Port: 80})
+		oldLB.Spec.NodeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"role": "gateway"}}
+
+		lb := oldLB.DeepCopy()
+		lb.Spec.NodeSelector.MatchLabels["zone"] = "a"
+		_, err := v.ValidateUpdate(context.TODO(), oldLB, lb)
+		Expect(err).NotTo(HaveOccurred())
+
+		lb.Spec.NodeSelector = nil
+		_, err = v.ValidateUpdate(context.TODO(), oldLB, lb)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.nodeSelector")))
+
+		By("not defaulting the node name of active-active load balancers")
+		oldLB.Labels = map[string]string{"kubernetes.io/hostname": "node-1"}
+		Expect((&webhooks.LoadBalancerDefaulter{}).Default(context.TODO(), oldLB)).To(Succeed())
+		Expect(oldLB.Spec.NodeName).To(BeNil())
+	})
+
+})
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-network,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=networks,verbs=create;update,versions=v1alpha1,name=mnetwork.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// NetworkDefaulter normalizes the peered prefixes and default firewall rules of Networks.
+type NetworkDefaulter struct{}
+
+func (d *NetworkDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	network, ok := obj.(*metalnetv1alpha1.Network)
+	if !ok {
+		return fmt.Errorf("expected a Network but got a %T", obj)
+	}
+
+	defaultNetworkSpec(&network.Spec)
+	return nil
+}
+
+func defaultNetworkSpec(spec *metalnetv1alpha1.NetworkSpec) {
+	for i := range spec.PeeredPrefixes {
+		normalizePrefixes(spec.PeeredPrefixes[i].Prefixes)
+		normalizePrefixes(spec.PeeredPrefixes[i].ExportPrefixes)
+		normalizePrefixes(spec.PeeredPrefixes[i].ImportPrefixes)
+	}
+	defaultFirewallRules(spec.DefaultFirewallRules)
+}
+
+//+kubebuilder:webhook:path=/validate-networking-metalnet-ironcore-dev-v1alpha1-network,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=networks,verbs=create;update,versions=v1alpha1,name=vnetwork.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// NetworkValidator rejects Networks whose VNIs are outside of the VNI range of the deployment or whose peered
+// prefixes are IPv4-mapped IPv6 prefixes.
+type NetworkValidator struct {
+	// VNIRange is the range the ID and the peered IDs have to be in. The zero VNIRange allows all VNIs.
+	VNIRange metalbond.VNIRange
+}
+
+func (v *NetworkValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	network, ok := obj.(*metalnetv1alpha1.Network)
+	if !ok {
+		return nil, fmt.Errorf("expected a Network but got a %T", obj)
+	}
+	return nil, v.validateNetwork(network)
+}
+
+func (v *NetworkValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	network, ok := newObj.(*metalnetv1alpha1.Network)
+	if !ok {
+		return nil, fmt.Errorf("expected a Network but got a %T", newObj)
+	}
+	if !network.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, v.validateNetwork(network)
+}
+
+func (v *NetworkValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NetworkValidator) validateNetwork(network *metalnetv1alpha1.Network) error {
+	if allErrs := validateNetworkSpec(&network.Spec, v.VNIRange); len(allErrs) > 0 {
+		return apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("Network").GroupKind(), network.Name, allErrs)
+	}
+	return nil
+}
+
+func validateNetworkSpec(spec *metalnetv1alpha1.NetworkSpec, vniRange metalbond.VNIRange) field.ErrorList {
+	var allErrs field.ErrorList
+	if !vniRange.Contains(metalbond.VNI(spec.ID)) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "id"), spec.ID,
+			fmt.Sprintf("must be within the VNI range %s", vniRange)))
+	}
+	for i, id := range spec.PeeredIDs {
+		if !vniRange.Contains(metalbond.VNI(id)) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "peeredIDs").Index(i), id,
+				fmt.Sprintf("must be within the VNI range %s", vniRange)))
+		}
+	}
+	for i, peeredPrefix := range spec.PeeredPrefixes {
+		fldPath := field.NewPath("spec", "peeredPrefixes").Index(i)
+		allErrs = append(allErrs, validatePrefixes(fldPath.Child("prefixes"), peeredPrefix.Prefixes)...)
+		allErrs = append(allErrs, validatePrefixes(fldPath.Child("exportPrefixes"), peeredPrefix.ExportPrefixes)...)
+		allErrs = append(allErrs, validatePrefixes(fldPath.Child("importPrefixes"), peeredPrefix.ImportPrefixes)...)
+	}
+	return allErrs
+}
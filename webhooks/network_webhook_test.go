@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	"github.com/ironcore-dev/metalnet/webhooks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Network validation", func() {
+	newNetwork := func() *metalnetv1alpha1.Network {
+		return &metalnetv1alpha1.Network{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network"},
+			Spec:       metalnetv1alpha1.NetworkSpec{ID: 123},
+		}
+	}
+
+	It("should reject networks with VNIs outside of the VNI range", func() {
+		v := &webhooks.NetworkValidator{VNIRange: metalbond.VNIRange{Min: 100, Max: 199}}
+		_, err := v.ValidateCreate(context.TODO(), newNetwork())
+		Expect(err).NotTo(HaveOccurred())
+
+		network := newNetwork()
+		network.Spec.ID = 1000
+		network.Spec.PeeredIDs = []int32{150, 99}
+		_, err = v.ValidateCreate(context.TODO(), network)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(And(ContainSubstring("spec.id"), ContainSubstring("spec.peeredIDs[1]"),
+			Not(ContainSubstring("spec.peeredIDs[0]")))))
+	})
+
+	It("should reject IPv4-mapped IPv6 prefixes", func() {
+		network := newNetwork()
+		network.Spec.PeeredPrefixes = []metalnetv1alpha1.PeeredPrefix{{
+			ID: 2,
+			Prefixes: []metalnetv1alpha1.IPPrefix{
+				metalnetv1alpha1.MustParseIPPrefix("fd00::/64"),
+				metalnetv1alpha1.MustParseIPPrefix("::ffff:10.0.0.0/104"),
+			},
+		}}
+		_, err := (&webhooks.NetworkValidator{}).ValidateCreate(context.TODO(), network)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(And(ContainSubstring("spec.peeredPrefixes[0].prefixes[1]"), ContainSubstring("10.0.0.0/8"))))
+	})
+
+	It("should validate the export and import prefixes of peerings", func() {
+		network := newNetwork()
+		network.Spec.PeeredPrefixes = []metalnetv1alpha1.PeeredPrefix{{
+			ID:             2,
+			ExportPrefixes: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.1.0/24")},
+			ImportPrefixes: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("::ffff:10.0.0.0/104")},
+		}}
+		_, err := (&webhooks.NetworkValidator{}).ValidateCreate(context.TODO(), network)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.peeredPrefixes[0].importPrefixes[0]")))
+
+		network.Spec.PeeredPrefixes[0].ImportPrefixes = nil
+		_, err = (&webhooks.NetworkValidator{}).ValidateCreate(context.TODO(), network)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,341 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/internal"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-networkinterface,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=create;update,versions=v1alpha1,name=mnetworkinterface.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// NetworkInterfaceDefaulter defaults the IP families and the node name of NetworkInterfaces and
+// normalizes their prefixes.
+type NetworkInterfaceDefaulter struct{}
+
+func (d *NetworkInterfaceDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	nic, ok := obj.(*metalnetv1alpha1.NetworkInterface)
+	if !ok {
+		return fmt.Errorf("expected a NetworkInterface but got a %T", obj)
+	}
+
+	if len(nic.Spec.IPFamilies) == 0 {
+		nic.Spec.IPFamilies = ipFamilies(nic.Spec.IPs)
+	}
+	defaultNodeName(&nic.Spec.NodeName, nic.Labels)
+
+	normalizePrefixes(nic.Spec.Prefixes)
+	normalizePrefixes(nic.Spec.LoadBalancerTargets)
+	defaultFirewallRules(nic.Spec.FirewallRules)
+	return nil
+}
+
+// defaultFirewallRules normalizes the prefixes of the given firewall rules and derives their IP families.
+func defaultFirewallRules(rules []metalnetv1alpha1.FirewallRule) {
+	for i := range rules {
+		rule := &rules[i]
+		normalizePrefix(rule.SourcePrefix)
+		normalizePrefix(rule.DestinationPrefix)
+		if rule.IpFamily == "" {
+			rule.IpFamily = firewallRuleIPFamily(rule)
+		}
+	}
+}
+
+// ipFamilies returns the distinct families of the given ips in order.
+func ipFamilies(ips []metalnetv1alpha1.IP) []corev1.IPFamily {
+	var families []corev1.IPFamily
+	seen := make(map[corev1.IPFamily]struct{})
+	for _, ip := range ips {
+		family := ip.Family()
+		if family == "" {
+			continue
+		}
+		if _, ok := seen[family]; ok {
+			continue
+		}
+		seen[family] = struct{}{}
+		families = append(families, family)
+	}
+	return families
+}
+
+func firewallRuleIPFamily(rule *metalnetv1alpha1.FirewallRule) corev1.IPFamily {
+	for _, prefix := range []*metalnetv1alpha1.IPPrefix{rule.SourcePrefix, rule.DestinationPrefix} {
+		if prefix != nil && prefix.IsValid() {
+			return prefix.IP().Family()
+		}
+	}
+	return ""
+}
+
+//+kubebuilder:webhook:path=/validate-networking-metalnet-ironcore-dev-v1alpha1-networkinterface,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=networkinterfaces,verbs=create;update;delete,versions=v1alpha1,name=vnetworkinterface.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// NetworkInterfaceValidator rejects NetworkInterfaces whose ips or prefixes overlap with those of another
+// NetworkInterface in the same Network on the same node, IPv4-mapped IPv6 prefixes and changes of the node of
+// NetworkInterfaces.
+// NetworkInterfaces may only be connected to a ClusterNetwork by users allowed to use it.
+type NetworkInterfaceValidator struct {
+	Client client.Reader
+	// Reviewer creates the SubjectAccessReviews checking whether the user creating or updating a
+	// NetworkInterface may use its ClusterNetwork. If nil, NetworkInterfaces cannot be connected to
+	// ClusterNetworks.
+	Reviewer client.Writer
+	// BlockAttachedDeletion rejects deleting NetworkInterfaces carrying the AttachedFinalizer. Otherwise
+	// their deletion is accepted and their teardown waits for the finalizer to be removed.
+	BlockAttachedDeletion bool
+}
+
+func (v *NetworkInterfaceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nic, ok := obj.(*metalnetv1alpha1.NetworkInterface)
+	if !ok {
+		return nil, fmt.Errorf("expected a NetworkInterface but got a %T", obj)
+	}
+	if allErrs := validateNetworkInterfaceSpec(&nic.Spec); len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("NetworkInterface").GroupKind(), nic.Name, allErrs)
+	}
+	if err := v.validateClusterNetworkUse(ctx, nic); err != nil {
+		return nil, err
+	}
+	return nil, v.validatePrefixConflicts(ctx, nic)
+}
+
+func (v *NetworkInterfaceValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	nic, ok := newObj.(*metalnetv1alpha1.NetworkInterface)
+	if !ok {
+		return nil, fmt.Errorf("expected a NetworkInterface but got a %T", newObj)
+	}
+	oldNIC, ok := oldObj.(*metalnetv1alpha1.NetworkInterface)
+	if !ok {
+		return nil, fmt.Errorf("expected a NetworkInterface but got a %T", oldObj)
+	}
+	allErrs := validateNodeNameUpdate(nic.Spec.NodeName, oldNIC.Spec.NodeName)
+	allErrs = append(allErrs, validateDeviceClaimUpdate(nic.Spec.DeviceClaim, oldNIC.Spec.DeviceClaim)...)
+	if nic.Spec.HostInterface != oldNIC.Spec.HostInterface {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "hostInterface"), nic.Spec.HostInterface, "is immutable"))
+	}
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("NetworkInterface").GroupKind(), nic.Name, allErrs)
+	}
+	if !nic.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	if allErrs := validateNetworkInterfaceSpec(&nic.Spec); len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("NetworkInterface").GroupKind(), nic.Name, allErrs)
+	}
+	if !equality.Semantic.DeepEqual(nic.Spec.ClusterNetworkRef, oldNIC.Spec.ClusterNetworkRef) {
+		if err := v.validateClusterNetworkUse(ctx, nic); err != nil {
+			return nil, err
+		}
+	}
+	return nil, v.validatePrefixConflicts(ctx, nic)
+}
+
+func (v *NetworkInterfaceValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nic, ok := obj.(*metalnetv1alpha1.NetworkInterface)
+	if !ok {
+		return nil, fmt.Errorf("expected a NetworkInterface but got a %T", obj)
+	}
+	if !v.BlockAttachedDeletion || !controllerutil.ContainsFinalizer(nic, metalnetv1alpha1.AttachedFinalizer) {
+		return nil, nil
+	}
+	return nil, apierrors.NewForbidden(metalnetv1alpha1.GroupVersion.WithResource("networkinterfaces").GroupResource(), nic.Name,
+		fmt.Errorf("network interface is attached to a machine, detach it first by removing the %s finalizer", metalnetv1alpha1.AttachedFinalizer))
+}
+
+// validateDeviceClaimUpdate rejects changing the device claim of a NetworkInterface, as its interface would have to be
+// moved to another device.
+func validateDeviceClaimUpdate(newClaim, oldClaim *metalnetv1alpha1.DeviceClaimReference) field.ErrorList {
+	if equality.Semantic.DeepEqual(newClaim, oldClaim) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("spec", "deviceClaim"), newClaim, "is immutable")}
+}
+
+func validateNetworkInterfaceSpec(spec *metalnetv1alpha1.NetworkInterfaceSpec) field.ErrorList {
+	fldPath := field.NewPath("spec")
+	allErrs := validatePrefixes(fldPath.Child("prefixes"), spec.Prefixes)
+	allErrs = append(allErrs, validatePrefixes(fldPath.Child("loadBalancerTargets"), spec.LoadBalancerTargets)...)
+	allErrs = append(allErrs, validateIPFamilies(spec, fldPath)...)
+	if spec.HostInterface {
+		allErrs = append(allErrs, validateHostInterface(spec, fldPath)...)
+	}
+	return allErrs
+}
+
+// validateHostInterface rejects host interfaces asking for another device, as they are programmed on the host port.
+func validateHostInterface(spec *metalnetv1alpha1.NetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.DeviceClaim != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("deviceClaim"), "must not be set for host interfaces"))
+	}
+	return allErrs
+}
+
+// validateIPFamilies rejects ips, virtual ips and prefixes not of the IP families of the NetworkInterface, which
+// dpservice would refuse to program.
+func validateIPFamilies(spec *metalnetv1alpha1.NetworkInterfaceSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	families := spec.IPFamilies
+	seenFamilies := make(map[corev1.IPFamily]struct{})
+	for i, family := range families {
+		if _, ok := seenFamilies[family]; ok {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("ipFamilies").Index(i), family))
+		}
+		seenFamilies[family] = struct{}{}
+	}
+	if len(families) == 0 {
+		families = ipFamilies(spec.IPs)
+	}
+	notOfFamilies := fmt.Sprintf("must be of the ip families %v of the network interface", families)
+
+	seenIPFamilies := make(map[corev1.IPFamily]struct{})
+	for i, ip := range spec.IPs {
+		family := ip.Family()
+		if !slices.Contains(families, family) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ips").Index(i), ip.String(), notOfFamilies))
+			continue
+		}
+		if _, ok := seenIPFamilies[family]; ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ips").Index(i), ip.String(),
+				fmt.Sprintf("only one %s ip is supported", family)))
+		}
+		seenIPFamilies[family] = struct{}{}
+	}
+	if vip := spec.VirtualIP; vip != nil && vip.IsValid() && !slices.Contains(families, vip.Family()) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("virtualIP"), vip.String(), notOfFamilies))
+	}
+	for i, prefix := range spec.Prefixes {
+		if prefix.IsValid() && !slices.Contains(families, prefix.IP().Family()) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prefixes").Index(i), prefix.String(), notOfFamilies))
+		}
+	}
+	for i, prefix := range spec.LoadBalancerTargets {
+		if prefix.IsValid() && !slices.Contains(families, prefix.IP().Family()) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("loadBalancerTargets").Index(i), prefix.String(), notOfFamilies))
+		}
+	}
+	return allErrs
+}
+
+func (v *NetworkInterfaceValidator) validatePrefixConflicts(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) error {
+	if nic.Spec.NodeName == nil {
+		return nil
+	}
+
+	var opts []client.ListOption
+	if nic.Spec.ClusterNetworkRef == nil {
+		opts = append(opts, client.InNamespace(nic.Namespace))
+	}
+	nicList := &metalnetv1alpha1.NetworkInterfaceList{}
+	if err := v.Client.List(ctx, nicList, opts...); err != nil {
+		return fmt.Errorf("error listing network interfaces: %w", err)
+	}
+
+	addrs := networkInterfaceAddresses(nic)
+	var allErrs field.ErrorList
+	for i := range nicList.Items {
+		other := &nicList.Items[i]
+		if (other.Namespace == nic.Namespace && other.Name == nic.Name) || !other.DeletionTimestamp.IsZero() ||
+			!inSameNetwork(other, nic) ||
+			other.Spec.NodeName == nil || *other.Spec.NodeName != *nic.Spec.NodeName {
+			continue
+		}
+		name := other.Name
+		if other.Namespace != nic.Namespace {
+			name = client.ObjectKeyFromObject(other).String()
+		}
+		if err := internal.FindAddressConflict(addrs, networkInterfaceAddresses(other)); err != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"),
+				fmt.Sprintf("conflicts with network interface %s: %v", name, err)))
+		}
+	}
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("NetworkInterface").GroupKind(), nic.Name, allErrs)
+	}
+	return nil
+}
+
+// inSameNetwork reports whether the given NetworkInterfaces are connected to the same Network or ClusterNetwork.
+func inSameNetwork(a, b *metalnetv1alpha1.NetworkInterface) bool {
+	if a.Spec.ClusterNetworkRef != nil || b.Spec.ClusterNetworkRef != nil {
+		return a.Spec.ClusterNetworkRef != nil && b.Spec.ClusterNetworkRef != nil &&
+			a.Spec.ClusterNetworkRef.Name == b.Spec.ClusterNetworkRef.Name
+	}
+	return a.Namespace == b.Namespace && a.Spec.NetworkRef.Name == b.Spec.NetworkRef.Name
+}
+
+// validateClusterNetworkUse rejects connecting a NetworkInterface to a ClusterNetwork the requesting user
+// is not allowed to use, i.e. does not have the use verb on.
+func (v *NetworkInterfaceValidator) validateClusterNetworkUse(ctx context.Context, nic *metalnetv1alpha1.NetworkInterface) error {
+	ref := nic.Spec.ClusterNetworkRef
+	if ref == nil {
+		return nil
+	}
+
+	gr := metalnetv1alpha1.GroupVersion.WithResource("clusternetworks").GroupResource()
+	forbidden := func(reason string) error {
+		return apierrors.NewForbidden(metalnetv1alpha1.GroupVersion.WithResource("networkinterfaces").GroupResource(), nic.Name,
+			fmt.Errorf("cannot use %s %s: %s", gr, ref.Name, reason))
+	}
+	if v.Reviewer == nil {
+		return forbidden("references to cluster networks are disabled")
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting admission request: %w", err)
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for key, values := range req.UserInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(values)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:    gr.Group,
+				Resource: gr.Resource,
+				Name:     ref.Name,
+				Verb:     metalnetv1alpha1.ClusterNetworkUseVerb,
+			},
+			User:   req.UserInfo.Username,
+			Groups: req.UserInfo.Groups,
+			UID:    req.UserInfo.UID,
+			Extra:  extra,
+		},
+	}
+	if err := v.Reviewer.Create(ctx, review); err != nil {
+		return fmt.Errorf("error reviewing access to %s %s: %w", gr, ref.Name, err)
+	}
+	if !review.Status.Allowed {
+		return forbidden(fmt.Sprintf("user %q does not have the %s permission", req.UserInfo.Username, metalnetv1alpha1.ClusterNetworkUseVerb))
+	}
+	return nil
+}
+
+func networkInterfaceAddresses(nic *metalnetv1alpha1.NetworkInterface) internal.InterfaceAddresses {
+	var addrs internal.InterfaceAddresses
+	for _, ip := range nic.Spec.IPs {
+		addrs.IPs = append(addrs.IPs, ip.Addr)
+	}
+	for _, prefix := range nic.Spec.Prefixes {
+		addrs.Prefixes = append(addrs.Prefixes, prefix.Prefix)
+	}
+	return addrs
+}
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/webhooks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("NetworkInterface validation", func() {
+	newNIC := func(name, network, node, ip string, prefixes ...string) *metalnetv1alpha1.NetworkInterface {
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: network},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP(ip)},
+				NodeName:   &node,
+			},
+		}
+		for _, prefix := range prefixes {
+			nic.Spec.Prefixes = append(nic.Spec.Prefixes, metalnetv1alpha1.MustParseIPPrefix(prefix))
+		}
+		return nic
+	}
+
+	newValidator := func(nics ...*metalnetv1alpha1.NetworkInterface) *webhooks.NetworkInterfaceValidator {
+		s := runtime.NewScheme()
+		Expect(metalnetv1alpha1.AddToScheme(s)).To(Succeed())
+		b := fake.NewClientBuilder().WithScheme(s)
+		for _, nic := range nics {
+			b = b.WithObjects(nic)
+		}
+		return &webhooks.NetworkInterfaceValidator{Client: b.Build()}
+	}
+
+	It("should reject network interfaces overlapping with another one in the same network on the same node", func() {
+		v := newValidator(newNIC("existing", "net-1", "node-1", "10.0.0.1", "10.0.1.0/24"))
+
+		_, err := v.ValidateCreate(context.TODO(), newNIC("new", "net-1", "node-1", "10.0.1.5"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("prefix 10.0.1.0/24")))
+
+		_, err = v.ValidateCreate(context.TODO(), newNIC("new", "net-1", "node-1", "10.0.2.1", "10.0.0.0/16"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("should allow overlaps in other networks or on other nodes", func() {
+		v := newValidator(newNIC("existing", "net-1", "node-1", "10.0.0.1", "10.0.1.0/24"))
+
+		_, err := v.ValidateCreate(context.TODO(), newNIC("other-network", "net-2", "node-1", "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = v.ValidateCreate(context.TODO(), newNIC("other-node", "net-1", "node-2", "10.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = v.ValidateUpdate(context.TODO(), newNIC("existing", "net-1", "node-1", "10.0.0.1", "10.0.1.0/24"),
+			newNIC("existing", "net-1", "node-1", "10.0.0.1", "10.0.0.0/16"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject moving network interfaces to another node", func() {
+		existing := newNIC("existing", "net-1", "node-1", "10.0.0.1")
+		v := newValidator(existing)
+
+		_, err := v.ValidateUpdate(context.TODO(), existing, newNIC("existing", "net-1", "node-2", "10.0.0.1"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.nodeName")))
+
+		unscheduled := existing.DeepCopy()
+		unscheduled.Spec.NodeName = nil
+		_, err = v.ValidateUpdate(context.TODO(), unscheduled, existing)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject changing the device claim of network interfaces", func() {
+		existing := newNIC("existing", "net-1", "node-1", "10.0.0.1")
+		existing.Spec.DeviceClaim = &metalnetv1alpha1.DeviceClaimReference{
+			PodRef:       corev1.LocalObjectReference{Name: "vm-1"},
+			ResourceName: "example.com/sriov-vf",
+		}
+		v := newValidator(existing)
+
+		updated := existing.DeepCopy()
+		updated.Spec.DeviceClaim.Index = 1
+		_, err := v.ValidateUpdate(context.TODO(), existing, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.deviceClaim")))
+
+		_, err = v.ValidateUpdate(context.TODO(), existing, existing.DeepCopy())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject host interfaces with another device and changing whether a network interface is one", func() {
+		v := newValidator()
+
+		nic := newNIC("nic", "net-1", "node-1", "10.0.0.1")
+		nic.Spec.HostInterface = true
+		_, err := v.ValidateCreate(context.TODO(), nic)
+		Expect(err).NotTo(HaveOccurred())
+
+		claimed := nic.DeepCopy()
+		claimed.Spec.DeviceClaim = &metalnetv1alpha1.DeviceClaimReference{
+			PodRef:       corev1.LocalObjectReference{Name: "vm-1"},
+			ResourceName: "example.com/sriov-vf",
+		}
+		_, err = v.ValidateCreate(context.TODO(), claimed)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.deviceClaim")))
+
+		updated := nic.DeepCopy()
+		updated.Spec.HostInterface = false
+		_, err = v.ValidateUpdate(context.TODO(), nic, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.hostInterface")))
+	})
+
+	It("should reject ips, virtual ips and prefixes not of the ip families of the network interface", func() {
+		v := newValidator()
+
+		nic := newNIC("nic", "net-1", "node-1", "10.0.0.1", "10.0.1.0/24")
+		nic.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+		virtualIP := metalnetv1alpha1.MustParseIP("2001:db8::1")
+		nic.Spec.VirtualIP = &virtualIP
+		nic.Spec.LoadBalancerTargets = []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("2001:db8:1::/64")}
+		_, err := v.ValidateCreate(context.TODO(), nic)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.virtualIP")))
+		Expect(err).To(MatchError(ContainSubstring("spec.loadBalancerTargets[0]")))
+		Expect(err).To(MatchError(ContainSubstring("must be of the ip families [IPv4] of the network interface")))
+
+		nic.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+		_, err = v.ValidateCreate(context.TODO(), nic)
+		Expect(err).NotTo(HaveOccurred())
+
+		nic.Spec.IPs = append(nic.Spec.IPs, metalnetv1alpha1.MustParseIP("10.0.0.2"))
+		_, err = v.ValidateCreate(context.TODO(), nic)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.ips[1]")))
+
+		nic = newNIC("nic", "net-1", "node-1", "2001:db8::2", "10.0.1.0/24")
+		_, err = v.ValidateCreate(context.TODO(), nic)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.prefixes[0]")))
+	})
+
+	It("should only connect network interfaces to cluster networks the user may use", func() {
+		v := newValidator()
+		var reviews []*authorizationv1.SubjectAccessReview
+		v.Reviewer = fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+				review := obj.(*authorizationv1.SubjectAccessReview)
+				reviews = append(reviews, review)
+				review.Status.Allowed = review.Spec.User == "storage-admin"
+				return nil
+			},
+		}).Build()
+		requestBy := func(user string) context.Context {
+			return admission.NewContextWithRequest(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: user},
+			}})
+		}
+
+		nic := newNIC("nic", "", "node-1", "10.0.0.1")
+		nic.Spec.ClusterNetworkRef = &corev1.LocalObjectReference{Name: "storage"}
+		_, err := v.ValidateCreate(requestBy("storage-admin"), nic)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviews).To(HaveLen(1))
+		Expect(reviews[0].Spec.ResourceAttributes).To(Equal(&authorizationv1.ResourceAttributes{
+			Group:    metalnetv1alpha1.GroupVersion.Group,
+			Resource: "clusternetworks",
+			Name:     "storage",
+			Verb:     metalnetv1alpha1.ClusterNetworkUseVerb,
+		}))
+
+		_, err = v.ValidateCreate(requestBy("tenant"), nic)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+		By("not rechecking updates keeping the cluster network")
+		_, err = v.ValidateUpdate(requestBy("tenant"), nic, nic.DeepCopy())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reviews).To(HaveLen(2))
+
+		By("rejecting references without reviewer")
+		v.Reviewer = nil
+		_, err = v.ValidateCreate(requestBy("storage-admin"), nic)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+
+	It("should reject overlaps with network interfaces of other namespaces in the same cluster network", func() {
+		inClusterNetwork := func(nic *metalnetv1alpha1.NetworkInterface, namespace string) *metalnetv1alpha1.NetworkInterface {
+			nic.Namespace = namespace
+			nic.Spec.ClusterNetworkRef = &corev1.LocalObjectReference{Name: "storage"}
+			return nic
+		}
+		v := newValidator(
+			inClusterNetwork(newNIC("existing", "", "node-1", "10.0.0.1"), "other"),
+			newNIC("namespaced", "storage", "node-1", "10.0.0.2"),
+		)
+		v.Reviewer = fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+				obj.(*authorizationv1.SubjectAccessReview).Status.Allowed = true
+				return nil
+			},
+		}).Build()
+		ctx := admission.NewContextWithRequest(context.TODO(), admission.Request{})
+
+		_, err := v.ValidateCreate(ctx, inClusterNetwork(newNIC("new", "", "node-1", "10.0.0.1"), "default"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("other/existing")))
+
+		_, err = v.ValidateCreate(ctx, inClusterNetwork(newNIC("new", "", "node-1", "10.0.0.2"), "default"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject deleting attached network interfaces if configured", func() {
+		attached := newNIC("attached", "net-1", "node-1", "10.0.0.1")
+		attached.Finalizers = []string{metalnetv1alpha1.AttachedFinalizer}
+
+		v := newValidator()
+		_, err := v.ValidateDelete(context.TODO(), attached)
+		Expect(err).NotTo(HaveOccurred())
+
+		v.BlockAttachedDeletion = true
+		_, err = v.ValidateDelete(context.TODO(), attached)
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		_, err = v.ValidateDelete(context.TODO(), newNIC("detached", "net-1", "node-1", "10.0.0.2"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
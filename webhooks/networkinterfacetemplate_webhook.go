@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-networkinterfacetemplate,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=networkinterfacetemplates,verbs=create;update,versions=v1alpha1,name=mnetworkinterfacetemplate.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// NetworkInterfaceTemplateDefaulter normalizes the prefixes of the template and the ip pool of
+// NetworkInterfaceTemplates, like the NetworkInterfaceDefaulter does those of NetworkInterfaces.
+type NetworkInterfaceTemplateDefaulter struct{}
+
+func (d *NetworkInterfaceTemplateDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	template, ok := obj.(*metalnetv1alpha1.NetworkInterfaceTemplate)
+	if !ok {
+		return fmt.Errorf("expected a NetworkInterfaceTemplate but got a %T", obj)
+	}
+
+	spec := &template.Spec.Template.Spec
+	normalizePrefixes(spec.LoadBalancerTargets)
+	defaultFirewallRules(spec.FirewallRules)
+	if template.Spec.IPPool != nil {
+		normalizePrefixes(template.Spec.IPPool.CIDRs)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-metalnet-ironcore-dev-v1alpha1-servicechain,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=servicechains,verbs=create;update,versions=v1alpha1,name=mservicechain.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// ServiceChainDefaulter normalizes the prefixes of ServiceChains.
+type ServiceChainDefaulter struct{}
+
+func (d *ServiceChainDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	chain, ok := obj.(*metalnetv1alpha1.ServiceChain)
+	if !ok {
+		return fmt.Errorf("expected a ServiceChain but got a %T", obj)
+	}
+	normalizePrefixes(chain.Spec.Prefixes)
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-networking-metalnet-ironcore-dev-v1alpha1-servicechain,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.metalnet.ironcore.dev,resources=servicechains,verbs=create;update,versions=v1alpha1,name=vservicechain.metalnet.ironcore.dev,admissionReviewVersions=v1
+
+// ServiceChainValidator rejects ServiceChains with duplicate prefixes, hops sharing a VNI, as they would route
+// the prefixes in the same VNI twice, and hops without underlay address.
+type ServiceChainValidator struct{}
+
+func (v *ServiceChainValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	chain, ok := obj.(*metalnetv1alpha1.ServiceChain)
+	if !ok {
+		return nil, fmt.Errorf("expected a ServiceChain but got a %T", obj)
+	}
+	return nil, validateServiceChain(chain)
+}
+
+func (v *ServiceChainValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	chain, ok := newObj.(*metalnetv1alpha1.ServiceChain)
+	if !ok {
+		return nil, fmt.Errorf("expected a ServiceChain but got a %T", newObj)
+	}
+	if !chain.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, validateServiceChain(chain)
+}
+
+func (v *ServiceChainValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateServiceChain(chain *metalnetv1alpha1.ServiceChain) error {
+	prefixesPath := field.NewPath("spec", "prefixes")
+	allErrs := validatePrefixes(prefixesPath, chain.Spec.Prefixes)
+	seenPrefixes := make(map[metalnetv1alpha1.IPPrefix]struct{})
+	for i, prefix := range chain.Spec.Prefixes {
+		if _, ok := seenPrefixes[prefix]; ok {
+			allErrs = append(allErrs, field.Duplicate(prefixesPath.Index(i), prefix.String()))
+		}
+		seenPrefixes[prefix] = struct{}{}
+	}
+
+	hopsPath := field.NewPath("spec", "hops")
+	seenVNIs := make(map[int32]struct{})
+	for i, hop := range chain.Spec.Hops {
+		idxPath := hopsPath.Index(i)
+		if _, ok := seenVNIs[hop.VNI]; ok {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("vni"), hop.VNI))
+		}
+		seenVNIs[hop.VNI] = struct{}{}
+		if !metalbond.IsUnderlayAddress(hop.UnderlayAddress.Addr) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("underlayAddress"), hop.UnderlayAddress.String(), "must be an IPv6 underlay address"))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(metalnetv1alpha1.GroupVersion.WithKind("ServiceChain").GroupKind(), chain.Name, allErrs)
+}
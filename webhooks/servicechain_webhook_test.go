@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/webhooks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ServiceChain validation", func() {
+	newChain := func(hops ...metalnetv1alpha1.ServiceChainHop) *metalnetv1alpha1.ServiceChain {
+		return &metalnetv1alpha1.ServiceChain{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "chain"},
+			Spec: metalnetv1alpha1.ServiceChainSpec{
+				NetworkRef: corev1.LocalObjectReference{Name: "net"},
+				Prefixes:   []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.10.0/24")},
+				Hops:       hops,
+			},
+		}
+	}
+
+	It("should accept chains of distinct VNIs", func() {
+		_, err := (&webhooks.ServiceChainValidator{}).ValidateCreate(context.TODO(), newChain(
+			metalnetv1alpha1.ServiceChainHop{VNI: 300, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:1::1")},
+			metalnetv1alpha1.ServiceChainHop{VNI: 301, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:2::1")},
+		))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject hops sharing a VNI and hops without underlay address", func() {
+		v := &webhooks.ServiceChainValidator{}
+
+		_, err := v.ValidateCreate(context.TODO(), newChain(
+			metalnetv1alpha1.ServiceChainHop{VNI: 300, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:1::1")},
+			metalnetv1alpha1.ServiceChainHop{VNI: 300, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:2::1")},
+		))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.hops[1].vni")))
+
+		_, err = v.ValidateUpdate(context.TODO(), newChain(), newChain(
+			metalnetv1alpha1.ServiceChainHop{VNI: 300, UnderlayAddress: metalnetv1alpha1.MustParseIP("10.0.0.1")},
+		))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.hops[0].underlayAddress")))
+	})
+
+	It("should normalize and reject duplicate prefixes", func() {
+		chain := newChain(metalnetv1alpha1.ServiceChainHop{VNI: 300, UnderlayAddress: metalnetv1alpha1.MustParseIP("fc00:1::1")})
+		chain.Spec.Prefixes = append(chain.Spec.Prefixes, metalnetv1alpha1.MustParseIPPrefix("10.0.10.1/24"))
+		Expect((&webhooks.ServiceChainDefaulter{}).Default(context.TODO(), chain)).To(Succeed())
+		Expect(chain.Spec.Prefixes[1]).To(Equal(metalnetv1alpha1.MustParseIPPrefix("10.0.10.0/24")))
+
+		_, err := (&webhooks.ServiceChainValidator{}).ValidateCreate(context.TODO(), chain)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.prefixes[1]")))
+	})
+})
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhooks contains the defaulting and validating webhooks of the metalnet API.
+package webhooks
+
+import (
+	"fmt"
+	"net/netip"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/metalbond"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type Options struct {
+	// BlockAttachedNetworkInterfaceDeletion rejects deleting NetworkInterfaces that are attached to a machine.
+	BlockAttachedNetworkInterfaceDeletion bool
+	// VNIRange rejects Networks with VNIs outside of it. The zero VNIRange allows all VNIs.
+	VNIRange metalbond.VNIRange
+}
+
+// SetupWithManager registers the defaulting and validating webhooks of the metalnet API.
+func SetupWithManager(mgr ctrl.Manager, opts Options) error {
+	for _, wh := range []struct {
+		obj       runtime.Object
+		defaulter admission.CustomDefaulter
+		validator admission.CustomValidator
+	}{
+		{&metalnetv1alpha1.Network{}, &NetworkDefaulter{}, &NetworkValidator{VNIRange: opts.VNIRange}},
+		{&metalnetv1alpha1.ClusterNetwork{}, &ClusterNetworkDefaulter{}, &ClusterNetworkValidator{VNIRange: opts.VNIRange}},
+		{&metalnetv1alpha1.NetworkInterface{}, &NetworkInterfaceDefaulter{}, &NetworkInterfaceValidator{
+			Client:                mgr.GetAPIReader(),
+			Reviewer:              mgr.GetClient(),
+			BlockAttachedDeletion: opts.BlockAttachedNetworkInterfaceDeletion,
+		}},
+		{&metalnetv1alpha1.LoadBalancer{}, &LoadBalancerDefaulter{}, &LoadBalancerValidator{}},
+		{&metalnetv1alpha1.LoadBalancerIPPool{}, &LoadBalancerIPPoolDefaulter{}, nil},
+		{&metalnetv1alpha1.NetworkIPPool{}, &NetworkIPPoolDefaulter{}, nil},
+		{&metalnetv1alpha1.NetworkInterfaceTemplate{}, &NetworkInterfaceTemplateDefaulter{}, nil},
+		{&metalnetv1alpha1.ServiceChain{}, &ServiceChainDefaulter{}, &ServiceChainValidator{}},
+	} {
+		b := ctrl.NewWebhookManagedBy(mgr).For(wh.obj)
+		if wh.defaulter != nil {
+			b = b.WithDefaulter(wh.defaulter)
+		}
+		if wh.validator != nil {
+			b = b.WithValidator(wh.validator)
+		}
+		if err := b.Complete(); err != nil {
+			return fmt.Errorf("error setting up webhooks for %T: %w", wh.obj, err)
+		}
+	}
+	return nil
+}
+
+// defaultNodeName defaults an unset node name to the node the object is labeled with.
+func defaultNodeName(nodeName **string, labels map[string]string) {
+	if *nodeName != nil {
+		return
+	}
+	if hostname, ok := labels[corev1.LabelHostname]; ok && hostname != "" {
+		*nodeName = &hostname
+	}
+}
+
+// validateNodeNameUpdate rejects changing the node name of an object once it is set, as moving an object to
+// another node would leave its dataplane state stranded on the old node.
+func validateNodeNameUpdate(newNodeName, oldNodeName *string) field.ErrorList {
+	if oldNodeName == nil || (newNodeName != nil && *newNodeName == *oldNodeName) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("spec", "nodeName"), newNodeName, "is immutable once set")}
+}
+
+// normalizePrefix clears the host bits of the given prefix. Decoded prefixes already are in canonical form, the
+// mutating webhooks then patch the stored objects to it.
+func normalizePrefix(prefix *metalnetv1alpha1.IPPrefix) {
+	if prefix != nil && prefix.IsValid() {
+		prefix.Prefix = prefix.Masked()
+	}
+}
+
+func normalizePrefixes(prefixes []metalnetv1alpha1.IPPrefix) {
+	for i := range prefixes {
+		normalizePrefix(&prefixes[i])
+	}
+}
+
+// validatePrefixes rejects IPv4-mapped IPv6 prefixes, which dpservice and metalbond would treat as IPv6 prefixes
+// not matching the IPv4 traffic they are meant for.
+func validatePrefixes(fldPath *field.Path, prefixes []metalnetv1alpha1.IPPrefix) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, prefix := range prefixes {
+		if prefix.IsValid() && prefix.Addr().Is4In6() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), prefix.String(),
+				fmt.Sprintf("must not be an IPv4-mapped IPv6 prefix, use %s instead", unmapPrefix(prefix.Prefix))))
+		}
+	}
+	return allErrs
+}
+
+// unmapPrefix returns the IPv4 prefix of the given IPv4-mapped IPv6 prefix.
+func unmapPrefix(prefix netip.Prefix) netip.Prefix {
+	return netip.PrefixFrom(prefix.Addr().Unmap(), max(prefix.Bits()-96, 0)).Masked()
+}
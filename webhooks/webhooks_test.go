@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"context"
+
+	metalnetv1alpha1 "github.com/ironcore-dev/metalnet/api/v1alpha1"
+	"github.com/ironcore-dev/metalnet/webhooks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Defaulting", func() {
+	It("should default the ip families and node name of network interfaces and normalize their prefixes", func() {
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{corev1.LabelHostname: "node-1"},
+			},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				IPs: []metalnetv1alpha1.IP{
+					metalnetv1alpha1.MustParseIP("10.0.0.1"),
+					metalnetv1alpha1.MustParseIP("fd00::1"),
+				},
+				Prefixes:            []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.1.1/24")},
+				LoadBalancerTargets: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("fd00::1:1/112")},
+				FirewallRules: []metalnetv1alpha1.FirewallRule{{
+					SourcePrefix: metalnetv1alpha1.MustParseNewIPPrefix("192.168.1.1/16"),
+				}},
+			},
+		}
+		Expect((&webhooks.NetworkInterfaceDefaulter{}).Default(context.TODO(), nic)).To(Succeed())
+
+		Expect(nic.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}))
+		Expect(nic.Spec.NodeName).To(HaveValue(Equal("node-1")))
+		Expect(nic.Spec.Prefixes).To(Equal([]metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.1.0/24")}))
+		Expect(nic.Spec.LoadBalancerTargets).To(Equal([]metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("fd00::1:0/112")}))
+		Expect(nic.Spec.FirewallRules[0].SourcePrefix).To(Equal(metalnetv1alpha1.MustParseNewIPPrefix("192.168.0.0/16")))
+		Expect(nic.Spec.FirewallRules[0].IpFamily).To(Equal(corev1.IPv4Protocol))
+	})
+
+	It("should not override set fields", func() {
+		nodeName := "node-2"
+		nic := &metalnetv1alpha1.NetworkInterface{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{corev1.LabelHostname: "node-1"},
+			},
+			Spec: metalnetv1alpha1.NetworkInterfaceSpec{
+				IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				IPs:        []metalnetv1alpha1.IP{metalnetv1alpha1.MustParseIP("10.0.0.1")},
+				NodeName:   &nodeName,
+			},
+		}
+		Expect((&webhooks.NetworkInterfaceDefaulter{}).Default(context.TODO(), nic)).To(Succeed())
+
+		Expect(nic.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv4Protocol}))
+		Expect(nic.Spec.NodeName).To(HaveValue(Equal("node-2")))
+	})
+
+	It("should default the ip family of load balancers", func() {
+		lb := &metalnetv1alpha1.LoadBalancer{
+			Spec: metalnetv1alpha1.LoadBalancerSpec{
+				IP: metalnetv1alpha1.MustParseIP("fd00::1"),
+			},
+		}
+		Expect((&webhooks.LoadBalancerDefaulter{}).Default(context.TODO(), lb)).To(Succeed())
+
+		Expect(lb.Spec.IPFamily).To(Equal(corev1.IPv6Protocol))
+		Expect(lb.Spec.NodeName).To(BeNil())
+	})
+
+	It("should normalize the peered prefixes of networks", func() {
+		network := &metalnetv1alpha1.Network{
+			Spec: metalnetv1alpha1.NetworkSpec{
+				PeeredPrefixes: []metalnetv1alpha1.PeeredPrefix{{
+					ID:       2,
+					Prefixes: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.1.2.3/8")},
+				}},
+			},
+		}
+		Expect((&webhooks.NetworkDefaulter{}).Default(context.TODO(), network)).To(Succeed())
+
+		Expect(network.Spec.PeeredPrefixes[0].Prefixes).To(Equal([]metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.0.0/8")}))
+	})
+
+	It("should default the default firewall rules of networks", func() {
+		network := &metalnetv1alpha1.Network{
+			Spec: metalnetv1alpha1.NetworkSpec{
+				DefaultFirewallRules: []metalnetv1alpha1.FirewallRule{{
+					FirewallRuleID: "rule",
+					SourcePrefix:   metalnetv1alpha1.MustParseNewIPPrefix("fd00::1/64"),
+				}},
+			},
+		}
+		Expect((&webhooks.NetworkDefaulter{}).Default(context.TODO(), network)).To(Succeed())
+
+		Expect(network.Spec.DefaultFirewallRules[0].SourcePrefix).To(Equal(metalnetv1alpha1.MustParseNewIPPrefix("fd00::/64")))
+		Expect(network.Spec.DefaultFirewallRules[0].IpFamily).To(Equal(corev1.IPv6Protocol))
+	})
+
+	It("should normalize the cidrs of ip pools", func() {
+		lbPool := &metalnetv1alpha1.LoadBalancerIPPool{
+			Spec: metalnetv1alpha1.LoadBalancerIPPoolSpec{
+				CIDRs: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("192.0.2.10/28")},
+			},
+		}
+		Expect((&webhooks.LoadBalancerIPPoolDefaulter{}).Default(context.TODO(), lbPool)).To(Succeed())
+		Expect(lbPool.Spec.CIDRs).To(Equal([]metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("192.0.2.0/28")}))
+
+		networkPool := &metalnetv1alpha1.NetworkIPPool{
+			Spec: metalnetv1alpha1.NetworkIPPoolSpec{
+				CIDRs: []metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.0.1/16")},
+			},
+		}
+		Expect((&webhooks.NetworkIPPoolDefaulter{}).Default(context.TODO(), networkPool)).To(Succeed())
+		Expect(networkPool.Spec.CIDRs).To(Equal([]metalnetv1alpha1.IPPrefix{metalnetv1alpha1.MustParseIPPrefix("10.0.0.0/16")}))
+	})
+})